@@ -195,6 +195,7 @@ Gitea or set your environment appropriately.`, "")
 		PullRequestID:                   prID,
 		DeployKeyID:                     deployKeyID,
 		ActionPerm:                      int(actionPerm),
+		IsGiteaPush:                     os.Getenv("SSH_ORIGINAL_COMMAND") == repo_module.InternalPushCommand,
 	}
 
 	scanner := bufio.NewScanner(os.Stdin)
@@ -42,6 +42,8 @@ type ActionRun struct {
 	Event             webhook_module.HookEventType
 	EventPayload      string `xorm:"LONGTEXT"`
 	Status            Status `xorm:"index"`
+	ConcurrencyGroup  string `xorm:"index"` // evaluated `concurrency.group` of the workflow, only one run of a group can be in progress
+	ConcurrencyCancel bool   // evaluated `concurrency.cancel-in-progress` of the workflow
 	Started           timeutil.TimeStamp
 	Stopped           timeutil.TimeStamp
 	Created           timeutil.TimeStamp `xorm:"created"`
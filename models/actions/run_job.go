@@ -32,6 +32,8 @@ type ActionRunJob struct {
 	RunsOn            []string `xorm:"JSON TEXT"`
 	TaskID            int64    // the latest task of the job
 	Status            Status   `xorm:"index"`
+	ConcurrencyGroup  string   `xorm:"index"` // evaluated `concurrency.group` of the job, only one job of a group can be in progress
+	ConcurrencyCancel bool     // evaluated `concurrency.cancel-in-progress` of the job
 	Started           timeutil.TimeStamp
 	Stopped           timeutil.TimeStamp
 	Created           timeutil.TimeStamp `xorm:"created"`
@@ -135,6 +137,31 @@ func UpdateRunJob(ctx context.Context, job *ActionRunJob, cond builder.Cond, col
 	return affected, UpdateRun(ctx, run)
 }
 
+// CancelJobs cancels the unfinished jobs, the tasks of the running ones are stopped
+func CancelJobs(ctx context.Context, jobs []*ActionRunJob) error {
+	for _, job := range jobs {
+		if job.Status.IsDone() {
+			continue
+		}
+		if job.TaskID == 0 {
+			job.Status = StatusCancelled
+			job.Stopped = timeutil.TimeStampNow()
+			n, err := UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
+			if err != nil {
+				return err
+			}
+			if n == 0 {
+				return fmt.Errorf("job has changed, try again")
+			}
+			continue
+		}
+		if err := StopTask(ctx, job.TaskID, StatusCancelled); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func aggregateJobStatus(jobs []*ActionRunJob) Status {
 	allDone := true
 	allWaiting := true
@@ -0,0 +1,103 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ActionSchedule represents a workflow of the default branch which is triggered by its schedule event
+type ActionSchedule struct {
+	ID            int64
+	Title         string
+	Specs         []string
+	RepoID        int64                  `xorm:"index"`
+	Repo          *repo_model.Repository `xorm:"-"`
+	OwnerID       int64                  `xorm:"index"`
+	WorkflowID    string                 // the name of workflow file
+	TriggerUserID int64                  // the user who pushed the commit of the workflow
+	TriggerUser   *user_model.User       `xorm:"-"`
+	Ref           string
+	CommitSHA     string
+	Content       []byte
+	Created       timeutil.TimeStamp `xorm:"created"`
+	Updated       timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionSchedule))
+	db.RegisterModel(new(ActionScheduleSpec))
+}
+
+// CreateScheduleTask inserts the schedules and the specs of their cron expressions,
+// the next run time of every spec is calculated from now in UTC
+func CreateScheduleTask(ctx context.Context, rows []*ActionSchedule) error {
+	if len(rows) == 0 {
+		return nil
+	}
+
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		now := time.Now().UTC()
+		for _, row := range rows {
+			if err := db.Insert(ctx, row); err != nil {
+				return err
+			}
+
+			specs := make([]*ActionScheduleSpec, 0, len(row.Specs))
+			for _, spec := range row.Specs {
+				s := &ActionScheduleSpec{
+					RepoID:     row.RepoID,
+					ScheduleID: row.ID,
+					Spec:       spec,
+				}
+				schedule, err := s.Parse()
+				if err != nil {
+					return err
+				}
+				next := schedule.Next(now)
+				if next.IsZero() {
+					// such a spec would always be due and be triggered again and again
+					return util.NewInvalidArgumentErrorf("cron expression %q never fires", spec)
+				}
+				s.Next = timeutil.TimeStamp(next.Unix())
+				specs = append(specs, s)
+			}
+			if err := db.Insert(ctx, specs); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteScheduleTaskByRepo deletes all the schedules and specs of a repository
+func DeleteScheduleTaskByRepo(ctx context.Context, repoID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Delete(&ActionSchedule{RepoID: repoID}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).Delete(&ActionScheduleSpec{RepoID: repoID})
+		return err
+	})
+}
+
+// GetScheduleByID returns the schedule with the given id
+func GetScheduleByID(ctx context.Context, id int64) (*ActionSchedule, error) {
+	var schedule ActionSchedule
+	has, err := db.GetEngine(ctx).Where("id=?", id).Get(&schedule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, fmt.Errorf("schedule with id %d: %w", id, util.ErrNotExist)
+	}
+	return &schedule, nil
+}
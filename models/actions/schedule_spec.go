@@ -0,0 +1,98 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gogs/cron"
+	"xorm.io/builder"
+)
+
+// ActionScheduleSpec represents a cron expression of a schedule and the times it has been and will be triggered
+type ActionScheduleSpec struct {
+	ID         int64
+	RepoID     int64           `xorm:"index"`
+	ScheduleID int64           `xorm:"index"`
+	Schedule   *ActionSchedule `xorm:"-"`
+	Spec       string
+	Next       timeutil.TimeStamp `xorm:"index"`
+	Prev       timeutil.TimeStamp
+	Created    timeutil.TimeStamp `xorm:"created"`
+	Updated    timeutil.TimeStamp `xorm:"updated"`
+}
+
+// Parse parses the cron expression of the spec, it uses the standard format with five fields like GitHub does
+func (s *ActionScheduleSpec) Parse() (cron.Schedule, error) {
+	return cron.ParseStandard(s.Spec)
+}
+
+type SpecList []*ActionScheduleSpec
+
+// GetScheduleIDs returns a slice of schedule's id
+func (specs SpecList) GetScheduleIDs() []int64 {
+	ids := make(container.Set[int64], len(specs))
+	for _, spec := range specs {
+		ids.Add(spec.ScheduleID)
+	}
+	return ids.Values()
+}
+
+func (specs SpecList) LoadSchedules(ctx context.Context) error {
+	scheduleIDs := specs.GetScheduleIDs()
+	schedules := make(map[int64]*ActionSchedule, len(scheduleIDs))
+	if err := db.GetEngine(ctx).In("id", scheduleIDs).Find(&schedules); err != nil {
+		return err
+	}
+	for _, spec := range specs {
+		spec.Schedule = schedules[spec.ScheduleID]
+	}
+	return nil
+}
+
+type FindSpecOptions struct {
+	db.ListOptions
+	RepoID int64
+	Next   int64 // find the specs which should be triggered before or at this unix time
+}
+
+func (opts FindSpecOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.Next > 0 {
+		cond = cond.And(builder.Lte{"next": opts.Next})
+	}
+	return cond
+}
+
+func FindSpecs(ctx context.Context, opts FindSpecOptions) (SpecList, int64, error) {
+	e := db.GetEngine(ctx).Where(opts.toConds())
+	if opts.PageSize > 0 && opts.Page >= 1 {
+		e.Limit(opts.PageSize, (opts.Page-1)*opts.PageSize)
+	}
+	var specs SpecList
+	total, err := e.Asc("id").FindAndCount(&specs)
+	if err != nil {
+		return nil, 0, err
+	}
+	if err := specs.LoadSchedules(ctx); err != nil {
+		return nil, 0, err
+	}
+	return specs, total, nil
+}
+
+func UpdateScheduleSpec(ctx context.Context, spec *ActionScheduleSpec, cols ...string) error {
+	sess := db.GetEngine(ctx).ID(spec.ID)
+	if len(cols) > 0 {
+		sess.Cols(cols...)
+	}
+	_, err := sess.Update(spec)
+	return err
+}
@@ -0,0 +1,39 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionScheduleSpec_Parse(t *testing.T) {
+	now := time.Date(2023, 7, 1, 10, 20, 30, 0, time.UTC)
+
+	tests := []struct {
+		spec    string
+		want    time.Time
+		wantErr bool
+	}{
+		{spec: "*/15 * * * *", want: time.Date(2023, 7, 1, 10, 30, 0, 0, time.UTC)},
+		{spec: "30 5 * * 1,3", want: time.Date(2023, 7, 3, 5, 30, 0, 0, time.UTC)},
+		{spec: "0 0 1 * *", want: time.Date(2023, 8, 1, 0, 0, 0, 0, time.UTC)},
+		{spec: "0 0 30 2 *", want: time.Time{}}, // valid but never fires
+		{spec: "0 0 * * * *", wantErr: true},
+		{spec: "invalid", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.spec, func(t *testing.T) {
+			schedule, err := (&ActionScheduleSpec{Spec: tt.spec}).Parse()
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, schedule.Next(now))
+		})
+	}
+}
@@ -234,6 +234,16 @@ func CreateTaskForRunner(ctx context.Context, runner *ActionRunner) (*ActionTask
 		jobCond = builder.In("run_id", builder.Select("id").From("action_run").Where(jobCond))
 	}
 
+	// a job can't be picked while another run or job of its concurrency group is in progress
+	jobCond = jobCond.And(
+		builder.NotIn("run_id", builder.Select("action_run.id").From("action_run").
+			Join("INNER", "action_run AS other", "other.repo_id = action_run.repo_id AND other.concurrency_group = action_run.concurrency_group AND other.id <> action_run.id").
+			Where(builder.Neq{"action_run.concurrency_group": ""}.And(builder.Eq{"other.status": StatusRunning}))),
+		builder.NotIn("id", builder.Select("action_run_job.id").From("action_run_job").
+			Join("INNER", "action_run_job AS other", "other.repo_id = action_run_job.repo_id AND other.concurrency_group = action_run_job.concurrency_group AND other.id <> action_run_job.id").
+			Where(builder.Neq{"action_run_job.concurrency_group": ""}.And(builder.Eq{"other.status": StatusRunning}))),
+	)
+
 	var jobs []*ActionRunJob
 	if err := e.Where("task_id=? AND status=?", 0, StatusWaiting).And(jobCond).Asc("id").Find(&jobs); err != nil {
 		return nil, false, err
@@ -0,0 +1,171 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// ActionVariable represents a non-secret configuration value of actions, available as `vars.<name>` in the workflows.
+// Either OwnerID (user or organization level) or RepoID (repository level) is set.
+type ActionVariable struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE(owner_repo_name)"`
+	RepoID      int64              `xorm:"INDEX UNIQUE(owner_repo_name)"`
+	Name        string             `xorm:"UNIQUE(owner_repo_name) NOT NULL"`
+	Data        string             `xorm:"LONGTEXT NOT NULL"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionVariable))
+}
+
+var (
+	variableNameReg            = regexp.MustCompile("^[A-Z_][A-Z0-9_]*$")
+	forbiddenVariablePrefixReg = regexp.MustCompile("^GIT(EA|HUB)_")
+)
+
+// Validate validates the name and the data of the variable
+func (v *ActionVariable) Validate() error {
+	switch {
+	case len(v.Name) == 0 || len(v.Name) > 255:
+		return util.NewInvalidArgumentErrorf("invalid variable name length %d", len(v.Name))
+	case !variableNameReg.MatchString(v.Name) || forbiddenVariablePrefixReg.MatchString(v.Name):
+		return util.NewInvalidArgumentErrorf("invalid variable name %q", v.Name)
+	case len(v.Data) == 0:
+		return util.NewInvalidArgumentErrorf("empty data of variable %q", v.Name)
+	default:
+		return nil
+	}
+}
+
+// InsertVariable validates and inserts a new variable, the name is converted to upper case
+func InsertVariable(ctx context.Context, ownerID, repoID int64, name, data string) (*ActionVariable, error) {
+	if ownerID != 0 && repoID != 0 {
+		// a variable belongs either to a user/organization or to a repository, never to both
+		ownerID = 0
+	}
+
+	variable := &ActionVariable{
+		OwnerID: ownerID,
+		RepoID:  repoID,
+		Name:    strings.ToUpper(name),
+		Data:    data,
+	}
+	if err := variable.Validate(); err != nil {
+		return variable, err
+	}
+
+	if exist, err := db.GetEngine(ctx).Exist(&ActionVariable{OwnerID: ownerID, RepoID: repoID, Name: variable.Name}); err != nil {
+		return nil, err
+	} else if exist {
+		return nil, util.NewAlreadyExistErrorf("variable %q already exists", variable.Name)
+	}
+
+	return variable, db.Insert(ctx, variable)
+}
+
+// FindVariablesOpts represents the options to find variables
+type FindVariablesOpts struct {
+	db.ListOptions
+	OwnerID int64
+	RepoID  int64
+	Name    string
+}
+
+func (opts FindVariablesOpts) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OwnerID > 0 {
+		cond = cond.And(builder.Eq{"owner_id": opts.OwnerID})
+	}
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.Name != "" {
+		cond = cond.And(builder.Eq{"name": strings.ToUpper(opts.Name)})
+	}
+	return cond
+}
+
+// FindVariables returns the variables matching the options ordered by name
+func FindVariables(ctx context.Context, opts FindVariablesOpts) ([]*ActionVariable, error) {
+	var variables []*ActionVariable
+	sess := db.GetEngine(ctx)
+	if opts.PageSize != 0 {
+		sess = db.SetSessionPagination(sess, &opts.ListOptions)
+	}
+	return variables, sess.Where(opts.toConds()).Asc("name").Find(&variables)
+}
+
+// CountVariables returns the number of variables matching the options
+func CountVariables(ctx context.Context, opts FindVariablesOpts) (int64, error) {
+	return db.GetEngine(ctx).Where(opts.toConds()).Count(new(ActionVariable))
+}
+
+// GetVariable returns the variable with the given name of a user/organization (ownerID) or a repository (repoID)
+func GetVariable(ctx context.Context, ownerID, repoID int64, name string) (*ActionVariable, error) {
+	var variable ActionVariable
+	has, err := db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ? AND name = ?", ownerID, repoID, strings.ToUpper(name)).
+		Get(&variable)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, util.NewNotExistErrorf("variable %q does not exist", name)
+	}
+	return &variable, nil
+}
+
+// GetVariableByID returns the variable with the given id which has to belong to the user/organization (ownerID) or the repository (repoID)
+func GetVariableByID(ctx context.Context, ownerID, repoID, id int64) (*ActionVariable, error) {
+	var variable ActionVariable
+	has, err := db.GetEngine(ctx).
+		Where("id = ? AND owner_id = ? AND repo_id = ?", id, ownerID, repoID).
+		Get(&variable)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, util.NewNotExistErrorf("variable with id %d does not exist", id)
+	}
+	return &variable, nil
+}
+
+// UpdateVariable validates and updates the name and the data of a variable
+func UpdateVariable(ctx context.Context, variable *ActionVariable) error {
+	variable.Name = strings.ToUpper(variable.Name)
+	if err := variable.Validate(); err != nil {
+		return err
+	}
+
+	if exist, err := db.GetEngine(ctx).Where("owner_id = ? AND repo_id = ? AND name = ? AND id <> ?",
+		variable.OwnerID, variable.RepoID, variable.Name, variable.ID).Exist(new(ActionVariable)); err != nil {
+		return err
+	} else if exist {
+		return util.NewAlreadyExistErrorf("variable %q already exists", variable.Name)
+	}
+
+	_, err := db.GetEngine(ctx).ID(variable.ID).Cols("name", "data").Update(variable)
+	return err
+}
+
+// DeleteVariable deletes the variable with the given id of a user/organization (ownerID) or a repository (repoID)
+func DeleteVariable(ctx context.Context, ownerID, repoID, id int64) error {
+	n, err := db.GetEngine(ctx).Where("id = ? AND owner_id = ? AND repo_id = ?", id, ownerID, repoID).Delete(new(ActionVariable))
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return util.NewNotExistErrorf("variable with id %d does not exist", id)
+	}
+	return nil
+}
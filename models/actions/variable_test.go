@@ -0,0 +1,120 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions_test
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestActionVariable_Validate(t *testing.T) {
+	for name, valid := range map[string]bool{
+		"FOO":          true,
+		"_FOO_BAR_1":   true,
+		"":             false,
+		"1FOO":         false,
+		"FOO-BAR":      false,
+		"foo":          false,
+		"GITEA_FOO":    false,
+		"GITHUB_TOKEN": false,
+	} {
+		err := (&actions_model.ActionVariable{Name: name, Data: "value"}).Validate()
+		if valid {
+			assert.NoError(t, err, name)
+		} else {
+			assert.ErrorIs(t, err, util.ErrInvalidArgument, name)
+		}
+	}
+
+	assert.ErrorIs(t, (&actions_model.ActionVariable{Name: "FOO"}).Validate(), util.ErrInvalidArgument)
+}
+
+func TestInsertVariable(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	v, err := actions_model.InsertVariable(db.DefaultContext, 2, 0, "foo", "user")
+	assert.NoError(t, err)
+	assert.Equal(t, "FOO", v.Name)
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionVariable{ID: v.ID, OwnerID: 2, Name: "FOO", Data: "user"})
+
+	// the names are unique per user/organization or repository, whatever their case is
+	_, err = actions_model.InsertVariable(db.DefaultContext, 2, 0, "Foo", "again")
+	assert.ErrorIs(t, err, util.ErrAlreadyExist)
+	_, err = actions_model.InsertVariable(db.DefaultContext, 3, 0, "FOO", "org")
+	assert.NoError(t, err)
+	_, err = actions_model.InsertVariable(db.DefaultContext, 0, 1, "FOO", "repo")
+	assert.NoError(t, err)
+
+	// a repository variable doesn't belong to the owner of the repository
+	v, err = actions_model.InsertVariable(db.DefaultContext, 2, 1, "BAR", "repo")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, v.OwnerID)
+	assert.EqualValues(t, 1, v.RepoID)
+
+	_, err = actions_model.InsertVariable(db.DefaultContext, 2, 0, "GITEA_FOO", "value")
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+	_, err = actions_model.InsertVariable(db.DefaultContext, 2, 0, "EMPTY", "")
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+
+	variables, err := actions_model.FindVariables(db.DefaultContext, actions_model.FindVariablesOpts{RepoID: 1})
+	assert.NoError(t, err)
+	if assert.Len(t, variables, 2) {
+		assert.Equal(t, "BAR", variables[0].Name)
+		assert.Equal(t, "FOO", variables[1].Name)
+	}
+	count, err := actions_model.CountVariables(db.DefaultContext, actions_model.FindVariablesOpts{OwnerID: 2, Name: "foo"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
+
+func TestUpdateVariable(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	foo, err := actions_model.InsertVariable(db.DefaultContext, 2, 0, "FOO", "foo")
+	assert.NoError(t, err)
+	_, err = actions_model.InsertVariable(db.DefaultContext, 2, 0, "BAR", "bar")
+	assert.NoError(t, err)
+	_, err = actions_model.InsertVariable(db.DefaultContext, 0, 1, "BAZ", "baz")
+	assert.NoError(t, err)
+
+	foo.Name = "bar"
+	assert.ErrorIs(t, actions_model.UpdateVariable(db.DefaultContext, foo), util.ErrAlreadyExist)
+	foo.Name = "1BAR"
+	assert.ErrorIs(t, actions_model.UpdateVariable(db.DefaultContext, foo), util.ErrInvalidArgument)
+
+	// the name of a variable of another owner is free
+	foo.Name = "baz"
+	foo.Data = "updated"
+	assert.NoError(t, actions_model.UpdateVariable(db.DefaultContext, foo))
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionVariable{ID: foo.ID, OwnerID: 2, Name: "BAZ", Data: "updated"})
+
+	v, err := actions_model.GetVariable(db.DefaultContext, 2, 0, "baz")
+	assert.NoError(t, err)
+	assert.Equal(t, foo.ID, v.ID)
+	_, err = actions_model.GetVariable(db.DefaultContext, 2, 0, "foo")
+	assert.ErrorIs(t, err, util.ErrNotExist)
+}
+
+func TestDeleteVariable(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	v, err := actions_model.InsertVariable(db.DefaultContext, 0, 1, "FOO", "foo")
+	assert.NoError(t, err)
+
+	// a variable can only be deleted through the repository or the user/organization it belongs to
+	assert.ErrorIs(t, actions_model.DeleteVariable(db.DefaultContext, 0, 2, v.ID), util.ErrNotExist)
+	assert.ErrorIs(t, actions_model.DeleteVariable(db.DefaultContext, 2, 0, v.ID), util.ErrNotExist)
+	_, err = actions_model.GetVariableByID(db.DefaultContext, 0, 2, v.ID)
+	assert.ErrorIs(t, err, util.ErrNotExist)
+
+	assert.NoError(t, actions_model.DeleteVariable(db.DefaultContext, 0, 1, v.ID))
+	unittest.AssertNotExistsBean(t, &actions_model.ActionVariable{ID: v.ID})
+	assert.ErrorIs(t, actions_model.DeleteVariable(db.DefaultContext, 0, 1, v.ID), util.ErrNotExist)
+}
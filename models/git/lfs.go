@@ -335,6 +335,18 @@ func GetRepoLFSSize(ctx context.Context, repoID int64) (int64, error) {
 	return lfsSize, nil
 }
 
+// GetOwnerLFSSize returns the size of all lfs files of the repositories of the owner
+func GetOwnerLFSSize(ctx context.Context, ownerID int64) (int64, error) {
+	lfsSize, err := db.GetEngine(ctx).
+		Join("INNER", "repository", "repository.id = lfs_meta_object.repository_id").
+		Where("repository.owner_id = ?", ownerID).
+		SumInt(new(LFSMetaObject), "lfs_meta_object.size")
+	if err != nil {
+		return 0, fmt.Errorf("GetOwnerLFSSize: %w", err)
+	}
+	return lfsSize, nil
+}
+
 // IterateRepositoryIDsWithLFSMetaObjects iterates across the repositories that have LFSMetaObjects
 func IterateRepositoryIDsWithLFSMetaObjects(ctx context.Context, f func(ctx context.Context, repoID, count int64) error) error {
 	batchSize := setting.Database.IterateBufferSize
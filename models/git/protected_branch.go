@@ -56,6 +56,10 @@ type ProtectedBranch struct {
 	RequireSignedCommits          bool     `xorm:"NOT NULL DEFAULT false"`
 	ProtectedFilePatterns         string   `xorm:"TEXT"`
 	UnprotectedFilePatterns       string   `xorm:"TEXT"`
+	EnableMergeQueue              bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireCodeOwnerApproval      bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireLinearHistory          bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireConversationResolution bool     `xorm:"NOT NULL DEFAULT false"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
@@ -227,6 +231,14 @@ func (protectBranch *ProtectedBranch) MergeBlockedByProtectedFiles(changedProtec
 	return len(changedProtectedFiles) > 0
 }
 
+// IsMergeStyleAllowed returns false if the merge style would create a merge commit on a branch requiring linear history
+func (protectBranch *ProtectedBranch) IsMergeStyleAllowed(mergeStyle repo_model.MergeStyle) bool {
+	if !protectBranch.RequireLinearHistory {
+		return true
+	}
+	return mergeStyle != repo_model.MergeStyleMerge && mergeStyle != repo_model.MergeStyleRebaseMerge
+}
+
 // IsProtectedFile return if path is protected
 func (protectBranch *ProtectedBranch) IsProtectedFile(patterns []glob.Glob, path string) bool {
 	if len(patterns) == 0 {
@@ -0,0 +1,210 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git
+
+import (
+	"context"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+	"xorm.io/builder"
+)
+
+// PushRule represents the rules the commits pushed to a repository have to follow.
+// Either OwnerID (organization level, applied to all the repositories of the organization) or RepoID (repository level) is set.
+type PushRule struct {
+	ID                    int64  `xorm:"pk autoincr"`
+	OwnerID               int64  `xorm:"UNIQUE(owner_repo)"`
+	RepoID                int64  `xorm:"INDEX UNIQUE(owner_repo)"`
+	CommitMessagePattern  string `xorm:"TEXT"`
+	AllowedEmailDomains   string `xorm:"TEXT"`
+	MaxFileSize           int64  `xorm:"NOT NULL DEFAULT 0"` // in bytes, 0 means no limit
+	ForbiddenFilePatterns string `xorm:"TEXT"`
+
+	commitMessageRegexp *regexp.Regexp `xorm:"-"`
+	emailDomainGlobs    []glob.Glob    `xorm:"-"`
+	forbiddenFileGlobs  []glob.Glob    `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(PushRule))
+}
+
+// IsEmpty returns true if the rule doesn't restrict anything
+func (rule *PushRule) IsEmpty() bool {
+	return rule.CommitMessagePattern == "" && rule.AllowedEmailDomains == "" && rule.MaxFileSize <= 0 && rule.ForbiddenFilePatterns == ""
+}
+
+// Validate checks the commit message pattern is a valid regular expression and the globs are valid
+func (rule *PushRule) Validate() error {
+	if rule.CommitMessagePattern != "" {
+		if _, err := regexp.Compile(rule.CommitMessagePattern); err != nil {
+			return util.NewInvalidArgumentErrorf("invalid commit message pattern: %v", err)
+		}
+	}
+	for _, domain := range splitEmailDomains(rule.AllowedEmailDomains) {
+		if _, err := glob.Compile(domain); err != nil {
+			return util.NewInvalidArgumentErrorf("invalid email domain %q: %v", domain, err)
+		}
+	}
+	for _, expr := range strings.Split(rule.ForbiddenFilePatterns, ";") {
+		if expr = strings.TrimSpace(expr); expr == "" {
+			continue
+		}
+		if _, err := glob.Compile(strings.ToLower(expr), '.', '/'); err != nil {
+			return util.NewInvalidArgumentErrorf("invalid file pattern %q: %v", expr, err)
+		}
+	}
+	if rule.MaxFileSize < 0 {
+		return util.NewInvalidArgumentErrorf("invalid max file size %d", rule.MaxFileSize)
+	}
+	return nil
+}
+
+func splitEmailDomains(domains string) []string {
+	var ret []string
+	for _, domain := range strings.FieldsFunc(strings.ToLower(domains), func(r rune) bool {
+		return r == ',' || r == '\n' || r == ' ' || r == '\r'
+	}) {
+		ret = append(ret, strings.TrimPrefix(domain, "@"))
+	}
+	return ret
+}
+
+func (rule *PushRule) loadPatterns() {
+	if rule.CommitMessagePattern != "" && rule.commitMessageRegexp == nil {
+		var err error
+		if rule.commitMessageRegexp, err = regexp.Compile(rule.CommitMessagePattern); err != nil {
+			log.Info("Invalid commit message pattern '%s' of push rule %d: %v", rule.CommitMessagePattern, rule.ID, err)
+			// nothing could match an invalid pattern
+			rule.commitMessageRegexp = regexp.MustCompile(`[^\s\S]`)
+		}
+	}
+	if rule.AllowedEmailDomains != "" && rule.emailDomainGlobs == nil {
+		rule.emailDomainGlobs = make([]glob.Glob, 0, 5)
+		for _, domain := range splitEmailDomains(rule.AllowedEmailDomains) {
+			if g, err := glob.Compile(domain); err != nil {
+				log.Info("Invalid email domain '%s' of push rule %d (skipped): %v", domain, rule.ID, err)
+			} else {
+				rule.emailDomainGlobs = append(rule.emailDomainGlobs, g)
+			}
+		}
+	}
+	if rule.ForbiddenFilePatterns != "" && rule.forbiddenFileGlobs == nil {
+		rule.forbiddenFileGlobs = getFilePatterns(rule.ForbiddenFilePatterns)
+	}
+}
+
+// IsCommitMessageAllowed returns true if the commit message matches the commit message pattern
+func (rule *PushRule) IsCommitMessageAllowed(message string) bool {
+	if rule.CommitMessagePattern == "" {
+		return true
+	}
+	rule.loadPatterns()
+	return rule.commitMessageRegexp.MatchString(message)
+}
+
+// IsEmailAllowed returns true if the domain of the email is one of the allowed domains
+func (rule *PushRule) IsEmailAllowed(email string) bool {
+	if rule.AllowedEmailDomains == "" {
+		return true
+	}
+	rule.loadPatterns()
+
+	n := strings.LastIndex(email, "@")
+	if n <= 0 {
+		return false
+	}
+	domain := strings.ToLower(email[n+1:])
+	for _, g := range rule.emailDomainGlobs {
+		if g.Match(domain) {
+			return true
+		}
+	}
+	return false
+}
+
+// IsFileSizeAllowed returns true if a file of the size could be pushed
+func (rule *PushRule) IsFileSizeAllowed(size int64) bool {
+	return rule.MaxFileSize <= 0 || size <= rule.MaxFileSize
+}
+
+// IsForbiddenFile returns true if the path matches one of the forbidden file patterns
+func (rule *PushRule) IsForbiddenFile(path string) bool {
+	if rule.ForbiddenFilePatterns == "" {
+		return false
+	}
+	rule.loadPatterns()
+
+	path = strings.ToLower(strings.TrimSpace(path))
+	for _, g := range rule.forbiddenFileGlobs {
+		if g.Match(path) {
+			return true
+		}
+	}
+	return false
+}
+
+// GetPushRule returns the push rule of the organization or of the repository, an empty rule is returned if none has been set
+func GetPushRule(ctx context.Context, ownerID, repoID int64) (*PushRule, error) {
+	if ownerID != 0 && repoID != 0 {
+		// a push rule belongs either to an organization or to a repository, never to both
+		ownerID = 0
+	}
+
+	rule := &PushRule{}
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"owner_id": ownerID, "repo_id": repoID}).Get(rule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return &PushRule{OwnerID: ownerID, RepoID: repoID}, nil
+	}
+	return rule, nil
+}
+
+// SavePushRule validates and creates or updates the push rule, an empty rule is deleted
+func SavePushRule(ctx context.Context, rule *PushRule) error {
+	if rule.OwnerID != 0 && rule.RepoID != 0 {
+		rule.OwnerID = 0
+	}
+	if err := rule.Validate(); err != nil {
+		return err
+	}
+
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where(builder.Eq{"owner_id": rule.OwnerID, "repo_id": rule.RepoID}).Delete(&PushRule{}); err != nil {
+			return err
+		}
+		if rule.IsEmpty() {
+			return nil
+		}
+		rule.ID = 0
+		return db.Insert(ctx, rule)
+	})
+}
+
+// GetPushRulesForRepo returns the push rules applied to the repository, the ones of its owner and of itself
+func GetPushRulesForRepo(ctx context.Context, repo *repo_model.Repository) ([]*PushRule, error) {
+	rules := make([]*PushRule, 0, 2)
+	if err := db.GetEngine(ctx).
+		Where(builder.Or(
+			builder.Eq{"owner_id": repo.OwnerID, "repo_id": 0},
+			builder.Eq{"owner_id": 0, "repo_id": repo.ID},
+		)).
+		Asc("repo_id").
+		Find(&rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
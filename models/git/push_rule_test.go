@@ -0,0 +1,96 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package git_test
+
+import (
+	"errors"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPushRuleMatch(t *testing.T) {
+	rule := &git_model.PushRule{
+		CommitMessagePattern:  `^[A-Z]+-[0-9]+ `,
+		AllowedEmailDomains:   "example.com, *.example.org",
+		MaxFileSize:           1024,
+		ForbiddenFilePatterns: "**.exe;secrets/**",
+	}
+	assert.NoError(t, rule.Validate())
+
+	assert.True(t, rule.IsCommitMessageAllowed("GITEA-123 fix the bug"))
+	assert.False(t, rule.IsCommitMessageAllowed("fix the bug"))
+
+	assert.True(t, rule.IsEmailAllowed("user@example.com"))
+	assert.True(t, rule.IsEmailAllowed("user@Mail.Example.org"))
+	assert.False(t, rule.IsEmailAllowed("user@example.org"))
+	assert.False(t, rule.IsEmailAllowed("user@example.net"))
+	assert.False(t, rule.IsEmailAllowed("example.com"))
+
+	assert.True(t, rule.IsFileSizeAllowed(1024))
+	assert.False(t, rule.IsFileSizeAllowed(1025))
+
+	assert.True(t, rule.IsForbiddenFile("bin/tool.EXE"))
+	assert.True(t, rule.IsForbiddenFile("secrets/prod/key.pem"))
+	assert.False(t, rule.IsForbiddenFile("docs/secrets.md"))
+
+	empty := &git_model.PushRule{}
+	assert.True(t, empty.IsEmpty())
+	assert.True(t, empty.IsCommitMessageAllowed(""))
+	assert.True(t, empty.IsEmailAllowed("user@example.net"))
+	assert.True(t, empty.IsFileSizeAllowed(1<<40))
+	assert.False(t, empty.IsForbiddenFile("tool.exe"))
+
+	assert.True(t, errors.Is((&git_model.PushRule{CommitMessagePattern: "(["}).Validate(), util.ErrInvalidArgument))
+	assert.True(t, errors.Is((&git_model.PushRule{ForbiddenFilePatterns: "[a-"}).Validate(), util.ErrInvalidArgument))
+}
+
+func TestSavePushRule(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// repository 3 is owned by the organization 3
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3})
+
+	rules, err := git_model.GetPushRulesForRepo(db.DefaultContext, repo)
+	assert.NoError(t, err)
+	assert.Empty(t, rules)
+
+	assert.NoError(t, git_model.SavePushRule(db.DefaultContext, &git_model.PushRule{OwnerID: repo.OwnerID, MaxFileSize: 1024}))
+	assert.NoError(t, git_model.SavePushRule(db.DefaultContext, &git_model.PushRule{RepoID: repo.ID, CommitMessagePattern: "^fix"}))
+	// another repository
+	assert.NoError(t, git_model.SavePushRule(db.DefaultContext, &git_model.PushRule{RepoID: 32, CommitMessagePattern: "^feat"}))
+
+	rules, err = git_model.GetPushRulesForRepo(db.DefaultContext, repo)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.EqualValues(t, 1024, rules[0].MaxFileSize)
+		assert.Equal(t, "^fix", rules[1].CommitMessagePattern)
+	}
+
+	// updating replaces the rule
+	assert.NoError(t, git_model.SavePushRule(db.DefaultContext, &git_model.PushRule{RepoID: repo.ID, AllowedEmailDomains: "example.com"}))
+	rule, err := git_model.GetPushRule(db.DefaultContext, 0, repo.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, rule.CommitMessagePattern)
+	assert.Equal(t, "example.com", rule.AllowedEmailDomains)
+
+	// an invalid rule isn't saved
+	err = git_model.SavePushRule(db.DefaultContext, &git_model.PushRule{RepoID: repo.ID, CommitMessagePattern: "(["})
+	assert.True(t, errors.Is(err, util.ErrInvalidArgument))
+
+	// saving an empty rule deletes it
+	assert.NoError(t, git_model.SavePushRule(db.DefaultContext, &git_model.PushRule{OwnerID: repo.OwnerID}))
+	rules, err = git_model.GetPushRulesForRepo(db.DefaultContext, repo)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 1) {
+		assert.Equal(t, "example.com", rules[0].AllowedEmailDomains)
+	}
+	unittest.AssertExistsAndLoadBean(t, &git_model.PushRule{RepoID: 32})
+}
@@ -109,6 +109,9 @@ const (
 
 	CommentTypePin   // 36 pin Issue
 	CommentTypeUnpin // 37 unpin Issue
+
+	CommentTypePRAddedToMergeQueue     // 38 pr was added to the merge queue of its base branch
+	CommentTypePRRemovedFromMergeQueue // 39 pr was removed from the merge queue, the content holds the reason
 )
 
 var commentStrings = []string{
@@ -150,6 +153,8 @@ var commentStrings = []string{
 	"pull_cancel_scheduled_merge",
 	"pin",
 	"unpin",
+	"pull_add_to_merge_queue",
+	"pull_remove_from_merge_queue",
 }
 
 func (t CommentType) String() string {
@@ -257,6 +262,7 @@ type Comment struct {
 
 	CommitID        int64
 	Line            int64 // - previous line / + proposed line
+	StartLine       int64 // the first line of a multi-line code comment, 0 for a single line one, the same side as Line
 	TreePath        string
 	Content         string `xorm:"LONGTEXT"`
 	RenderedContent string `xorm:"-"`
@@ -724,6 +730,22 @@ func (c *Comment) UnsignedLine() uint64 {
 	return uint64(c.Line)
 }
 
+// UnsignedStartLine returns the first LOC of a multi-line code comment without + or -, it's the same as UnsignedLine for a single line one
+func (c *Comment) UnsignedStartLine() uint64 {
+	if c.StartLine == 0 {
+		return c.UnsignedLine()
+	}
+	if c.StartLine < 0 {
+		return uint64(c.StartLine * -1)
+	}
+	return uint64(c.StartLine)
+}
+
+// IsMultiLine returns true if the code comment is on a range of lines
+func (c *Comment) IsMultiLine() bool {
+	return c.StartLine != 0 && c.StartLine != c.Line
+}
+
 // CodeCommentLink returns the url to a comment in code
 func (c *Comment) CodeCommentLink() string {
 	err := c.LoadIssue(db.DefaultContext)
@@ -800,6 +822,7 @@ func CreateComment(ctx context.Context, opts *CreateCommentOptions) (_ *Comment,
 		CommitID:         opts.CommitID,
 		CommitSHA:        opts.CommitSHA,
 		Line:             opts.LineNum,
+		StartLine:        opts.StartLineNum,
 		Content:          opts.Content,
 		OldTitle:         opts.OldTitle,
 		NewTitle:         opts.NewTitle,
@@ -976,6 +999,7 @@ type CreateCommentOptions struct {
 	CommitSHA        string
 	Patch            string
 	LineNum          int64
+	StartLineNum     int64
 	TreePath         string
 	ReviewID         int64
 	Content          string
@@ -1186,6 +1210,29 @@ func CreateAutoMergeComment(ctx context.Context, typ CommentType, pr *PullReques
 	return comment, err
 }
 
+// CreateMergeQueueComment is a internal function, only use it for CommentTypePRAddedToMergeQueue and CommentTypePRRemovedFromMergeQueue CommentTypes
+func CreateMergeQueueComment(ctx context.Context, typ CommentType, pr *PullRequest, doer *user_model.User, reason string) (comment *Comment, err error) {
+	if typ != CommentTypePRAddedToMergeQueue && typ != CommentTypePRRemovedFromMergeQueue {
+		return nil, fmt.Errorf("comment type %d cannot be used to create a merge queue comment", typ)
+	}
+	if err = pr.LoadIssue(ctx); err != nil {
+		return
+	}
+
+	if err = pr.LoadBaseRepo(ctx); err != nil {
+		return
+	}
+
+	comment, err = CreateComment(ctx, &CreateCommentOptions{
+		Type:    typ,
+		Doer:    doer,
+		Repo:    pr.BaseRepo,
+		Issue:   pr.Issue,
+		Content: reason,
+	})
+	return comment, err
+}
+
 // RemapExternalUser ExternalUserRemappable interface
 func (c *Comment) RemapExternalUser(externalName string, externalID, userID int64) error {
 	c.OriginalAuthor = externalName
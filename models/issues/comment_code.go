@@ -5,6 +5,8 @@ package issues
 
 import (
 	"context"
+	"regexp"
+	"strings"
 
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -127,3 +129,36 @@ func FetchCodeCommentsByLine(ctx context.Context, issue *Issue, currentUser *use
 	}
 	return findCodeComments(ctx, opts, issue, currentUser, nil)
 }
+
+var suggestionFenceRegexp = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})[ \t]*suggestion[ \t]*$")
+
+// Suggestion returns the lines of the first "suggestion" block of the code comment,
+// which are proposed to replace the commented lines, no lines means removing them
+func (c *Comment) Suggestion() ([]string, bool) {
+	if c.Type != CommentTypeCode {
+		return nil, false
+	}
+	lines := strings.Split(strings.ReplaceAll(c.Content, "\r\n", "\n"), "\n")
+	for i, line := range lines {
+		m := suggestionFenceRegexp.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+		fence := m[1]
+		for j := i + 1; j < len(lines); j++ {
+			closing := strings.TrimSpace(lines[j])
+			if strings.HasPrefix(closing, fence) && strings.Trim(closing, fence[:1]) == "" {
+				return lines[i+1 : j], true
+			}
+		}
+		// an unclosed fenced block runs to the end of the content
+		return lines[i+1:], true
+	}
+	return nil, false
+}
+
+// HasSuggestion returns true if the code comment contains a "suggestion" block
+func (c *Comment) HasSuggestion() bool {
+	_, ok := c.Suggestion()
+	return ok
+}
@@ -70,3 +70,28 @@ func TestAsCommentType(t *testing.T) {
 	assert.Equal(t, issues_model.CommentTypeComment, issues_model.AsCommentType("comment"))
 	assert.Equal(t, issues_model.CommentTypePRUnScheduledToAutoMerge, issues_model.AsCommentType("pull_cancel_scheduled_merge"))
 }
+
+func TestCommentSuggestion(t *testing.T) {
+	kases := []struct {
+		content    string
+		suggestion []string
+		has        bool
+	}{
+		{"no suggestion", nil, false},
+		{"```go\nfmt.Println()\n```", nil, false},
+		{"replace it:\n```suggestion\nfoo()\nbar()\n```\nthanks", []string{"foo()", "bar()"}, true},
+		{"~~~~ suggestion\r\nfoo()\r\n```\r\n~~~~", []string{"foo()", "```"}, true},
+		{"remove them\n```suggestion\n```", []string{}, true},
+		{"```suggestion\n\n```", []string{""}, true},
+		{"```suggestion\nfoo()", []string{"foo()"}, true},
+	}
+	for _, kase := range kases {
+		comment := &issues_model.Comment{Type: issues_model.CommentTypeCode, Content: kase.content}
+		suggestion, has := comment.Suggestion()
+		assert.Equal(t, kase.has, has, kase.content)
+		assert.Equal(t, kase.suggestion, suggestion, kase.content)
+	}
+
+	comment := &issues_model.Comment{Type: issues_model.CommentTypeComment, Content: "```suggestion\nfoo()\n```"}
+	assert.False(t, comment.HasSuggestion())
+}
@@ -0,0 +1,377 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// IssueFilter represents structured conditions on issues, like the qualifiers of an issue search query.
+// Users, repositories, labels and milestones are referenced by their names.
+type IssueFilter struct {
+	IsClosed util.OptionalBool
+	IsPull   util.OptionalBool
+
+	LabelNames         [][]string // the issues must have a label of each group
+	ExcludedLabelNames []string
+	NoLabel            bool
+
+	PosterNames         []string // the issues must be created by one of the users
+	ExcludedPosterNames []string
+
+	AssigneeNames         []string // the issues must be assigned to one of the users
+	ExcludedAssigneeNames []string
+	NoAssignee            bool
+
+	MentionedNames []string // the issues must mention one of the users
+
+	MilestoneNames         []string // the issues must be in one of the milestones
+	ExcludedMilestoneNames []string
+	NoMilestone            bool
+
+	RepoNames         []string // "owner/name" or "name" of the repositories of the issues, "*" matches any characters
+	ExcludedRepoNames []string
+
+	CreatedAfterUnix  int64
+	CreatedBeforeUnix int64
+	UpdatedAfterUnix  int64
+	UpdatedBeforeUnix int64
+}
+
+// likeEscaper escapes the LIKE wildcards of a name, "!" is the escape character as it is supported the same way by all databases
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+func repoNameCond(column, pattern string) builder.Cond {
+	if !strings.Contains(pattern, "*") {
+		return builder.Eq{column: pattern}
+	}
+	return builder.Expr(column+" LIKE ? ESCAPE '!'", strings.ReplaceAll(likeEscaper.Replace(pattern), "*", "%"))
+}
+
+// repoNamesCond returns the condition on the repository table matching the repositories of any pattern
+func repoNamesCond(patterns []string) builder.Cond {
+	cond := builder.NewCond()
+	for _, pattern := range patterns {
+		pattern = strings.ToLower(pattern)
+		if owner, name, ok := strings.Cut(pattern, "/"); ok {
+			cond = cond.Or(builder.And(repoNameCond("LOWER(owner_name)", owner), repoNameCond("lower_name", name)))
+		} else {
+			cond = cond.Or(repoNameCond("lower_name", pattern))
+		}
+	}
+	return cond
+}
+
+func userIDsCond(ctx context.Context, names []string, cond func(ids []int64) builder.Cond) (builder.Cond, error) {
+	// the users which don't exist can't match any issue
+	ids, err := user_model.GetUserIDsByNames(ctx, names, true)
+	if err != nil {
+		return nil, err
+	}
+	return cond(ids), nil
+}
+
+// Cond returns the condition of the filter on the issue table
+func (f *IssueFilter) Cond(ctx context.Context) (builder.Cond, error) {
+	cond := builder.NewCond()
+
+	if !f.IsClosed.IsNone() {
+		cond = cond.And(builder.Eq{"issue.is_closed": f.IsClosed.IsTrue()})
+	}
+	if !f.IsPull.IsNone() {
+		cond = cond.And(builder.Eq{"issue.is_pull": f.IsPull.IsTrue()})
+	}
+
+	for _, names := range f.LabelNames {
+		cond = cond.And(builder.In("issue.id", BuildLabelNamesIssueIDsCondition(names)))
+	}
+	if len(f.ExcludedLabelNames) > 0 {
+		cond = cond.And(builder.NotIn("issue.id", BuildLabelNamesIssueIDsCondition(f.ExcludedLabelNames)))
+	}
+	if f.NoLabel {
+		cond = cond.And(builder.NotIn("issue.id", builder.Select("issue_id").From("issue_label")))
+	}
+
+	assigneeCond := func(ids []int64) *builder.Builder {
+		return builder.Select("issue_id").From("issue_assignees").Where(builder.In("assignee_id", ids))
+	}
+	userConds := []struct {
+		names []string
+		cond  func(ids []int64) builder.Cond
+	}{
+		{f.PosterNames, func(ids []int64) builder.Cond { return builder.In("issue.poster_id", ids) }},
+		{f.ExcludedPosterNames, func(ids []int64) builder.Cond { return builder.NotIn("issue.poster_id", ids) }},
+		{f.AssigneeNames, func(ids []int64) builder.Cond { return builder.In("issue.id", assigneeCond(ids)) }},
+		{f.ExcludedAssigneeNames, func(ids []int64) builder.Cond { return builder.NotIn("issue.id", assigneeCond(ids)) }},
+		{f.MentionedNames, func(ids []int64) builder.Cond {
+			return builder.In("issue.id", builder.Select("issue_id").From("issue_user").
+				Where(builder.Eq{"is_mentioned": true}.And(builder.In("uid", ids))))
+		}},
+	}
+	for _, userCond := range userConds {
+		if len(userCond.names) == 0 {
+			continue
+		}
+		c, err := userIDsCond(ctx, userCond.names, userCond.cond)
+		if err != nil {
+			return nil, err
+		}
+		cond = cond.And(c)
+	}
+	if f.NoAssignee {
+		cond = cond.And(builder.NotIn("issue.id", builder.Select("issue_id").From("issue_assignees")))
+	}
+
+	if len(f.MilestoneNames) > 0 {
+		cond = cond.And(builder.In("issue.milestone_id",
+			builder.Select("id").From("milestone").Where(builder.In("name", f.MilestoneNames))))
+	}
+	if len(f.ExcludedMilestoneNames) > 0 {
+		cond = cond.And(builder.NotIn("issue.milestone_id",
+			builder.Select("id").From("milestone").Where(builder.In("name", f.ExcludedMilestoneNames))))
+	}
+	if f.NoMilestone {
+		cond = cond.And(builder.Eq{"issue.milestone_id": 0})
+	}
+
+	if len(f.RepoNames) > 0 {
+		cond = cond.And(builder.In("issue.repo_id", builder.Select("id").From("repository").Where(repoNamesCond(f.RepoNames))))
+	}
+	if len(f.ExcludedRepoNames) > 0 {
+		cond = cond.And(builder.NotIn("issue.repo_id", builder.Select("id").From("repository").Where(repoNamesCond(f.ExcludedRepoNames))))
+	}
+
+	if f.CreatedAfterUnix != 0 {
+		cond = cond.And(builder.Gte{"issue.created_unix": f.CreatedAfterUnix})
+	}
+	if f.CreatedBeforeUnix != 0 {
+		cond = cond.And(builder.Lte{"issue.created_unix": f.CreatedBeforeUnix})
+	}
+	if f.UpdatedAfterUnix != 0 {
+		cond = cond.And(builder.Gte{"issue.updated_unix": f.UpdatedAfterUnix})
+	}
+	if f.UpdatedBeforeUnix != 0 {
+		cond = cond.And(builder.Lte{"issue.updated_unix": f.UpdatedBeforeUnix})
+	}
+
+	return cond, nil
+}
+
+// ResolvedIssueFilter is an IssueFilter whose users, repositories, labels and milestones have been resolved to their IDs,
+// it is used by the issue indexers which don't know the names.
+type ResolvedIssueFilter struct {
+	RepoIDs []int64 // the issues must be in one of the repositories
+
+	IsClosed util.OptionalBool
+	IsPull   util.OptionalBool
+
+	LabelIDs         [][]int64 // the issues must have a label of each group
+	ExcludedLabelIDs []int64
+	NoLabel          bool
+
+	PosterIDs         []int64 // the issues must be created by one of the users
+	ExcludedPosterIDs []int64
+
+	AssigneeIDs         []int64 // the issues must be assigned to one of the users
+	ExcludedAssigneeIDs []int64
+	NoAssignee          bool
+
+	MentionedIDs []int64 // the issues must mention one of the users
+
+	MilestoneIDs         []int64 // the issues must be in one of the milestones
+	ExcludedMilestoneIDs []int64
+	NoMilestone          bool
+
+	CreatedAfterUnix  int64
+	CreatedBeforeUnix int64
+	UpdatedAfterUnix  int64
+	UpdatedBeforeUnix int64
+}
+
+func findIDs(ctx context.Context, table string, cond builder.Cond) ([]int64, error) {
+	var ids []int64
+	return ids, db.GetEngine(ctx).Table(table).Where(cond).Cols("id").Find(&ids)
+}
+
+// Resolve resolves the names of the filter in the given repositories.
+// It returns nil if the filter can't match any issue of the repositories.
+func (f *IssueFilter) Resolve(ctx context.Context, repoIDs []int64) (*ResolvedIssueFilter, error) {
+	res := &ResolvedIssueFilter{
+		RepoIDs:           repoIDs,
+		IsClosed:          f.IsClosed,
+		IsPull:            f.IsPull,
+		NoLabel:           f.NoLabel,
+		NoAssignee:        f.NoAssignee,
+		NoMilestone:       f.NoMilestone,
+		CreatedAfterUnix:  f.CreatedAfterUnix,
+		CreatedBeforeUnix: f.CreatedBeforeUnix,
+		UpdatedAfterUnix:  f.UpdatedAfterUnix,
+		UpdatedBeforeUnix: f.UpdatedBeforeUnix,
+	}
+
+	var err error
+	if len(f.RepoNames) > 0 || len(f.ExcludedRepoNames) > 0 {
+		repoCond := builder.In("id", repoIDs)
+		if len(f.RepoNames) > 0 {
+			repoCond = repoCond.And(repoNamesCond(f.RepoNames))
+		}
+		if len(f.ExcludedRepoNames) > 0 {
+			repoCond = repoCond.And(builder.Not{repoNamesCond(f.ExcludedRepoNames)})
+		}
+		if res.RepoIDs, err = findIDs(ctx, "repository", repoCond); err != nil {
+			return nil, err
+		}
+	}
+	if len(res.RepoIDs) == 0 {
+		return nil, nil
+	}
+
+	// the labels of the repositories and of their organizations
+	labelsCond := func(names []string) builder.Cond {
+		return builder.In("name", names).And(builder.Or(
+			builder.In("repo_id", res.RepoIDs),
+			builder.In("org_id", builder.Select("owner_id").From("repository").Where(builder.In("id", res.RepoIDs))),
+		))
+	}
+	for _, names := range f.LabelNames {
+		ids, err := findIDs(ctx, "label", labelsCond(names))
+		if err != nil {
+			return nil, err
+		}
+		if len(ids) == 0 {
+			return nil, nil
+		}
+		res.LabelIDs = append(res.LabelIDs, ids)
+	}
+	if len(f.ExcludedLabelNames) > 0 {
+		if res.ExcludedLabelIDs, err = findIDs(ctx, "label", labelsCond(f.ExcludedLabelNames)); err != nil {
+			return nil, err
+		}
+	}
+
+	// the users which don't exist can't match any issue
+	userIDs := []struct {
+		names    []string
+		ids      *[]int64
+		required bool
+	}{
+		{f.PosterNames, &res.PosterIDs, true},
+		{f.ExcludedPosterNames, &res.ExcludedPosterIDs, false},
+		{f.AssigneeNames, &res.AssigneeIDs, true},
+		{f.ExcludedAssigneeNames, &res.ExcludedAssigneeIDs, false},
+		{f.MentionedNames, &res.MentionedIDs, true},
+	}
+	for _, users := range userIDs {
+		if len(users.names) == 0 {
+			continue
+		}
+		if *users.ids, err = user_model.GetUserIDsByNames(ctx, users.names, true); err != nil {
+			return nil, err
+		}
+		if users.required && len(*users.ids) == 0 {
+			return nil, nil
+		}
+	}
+
+	milestonesCond := func(names []string) builder.Cond {
+		return builder.In("name", names).And(builder.In("repo_id", res.RepoIDs))
+	}
+	if len(f.MilestoneNames) > 0 {
+		if res.MilestoneIDs, err = findIDs(ctx, "milestone", milestonesCond(f.MilestoneNames)); err != nil {
+			return nil, err
+		}
+		if len(res.MilestoneIDs) == 0 {
+			return nil, nil
+		}
+	}
+	if len(f.ExcludedMilestoneNames) > 0 {
+		if res.ExcludedMilestoneIDs, err = findIDs(ctx, "milestone", milestonesCond(f.ExcludedMilestoneNames)); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// Cond returns the condition of the filter on the issue table
+func (f *ResolvedIssueFilter) Cond() builder.Cond {
+	cond := builder.NewCond().And(builder.In("issue.repo_id", f.RepoIDs))
+
+	if !f.IsClosed.IsNone() {
+		cond = cond.And(builder.Eq{"issue.is_closed": f.IsClosed.IsTrue()})
+	}
+	if !f.IsPull.IsNone() {
+		cond = cond.And(builder.Eq{"issue.is_pull": f.IsPull.IsTrue()})
+	}
+
+	issueLabelsCond := func(ids []int64) *builder.Builder {
+		return builder.Select("issue_id").From("issue_label").Where(builder.In("label_id", ids))
+	}
+	for _, ids := range f.LabelIDs {
+		cond = cond.And(builder.In("issue.id", issueLabelsCond(ids)))
+	}
+	if len(f.ExcludedLabelIDs) > 0 {
+		cond = cond.And(builder.NotIn("issue.id", issueLabelsCond(f.ExcludedLabelIDs)))
+	}
+	if f.NoLabel {
+		cond = cond.And(builder.NotIn("issue.id", builder.Select("issue_id").From("issue_label")))
+	}
+
+	if len(f.PosterIDs) > 0 {
+		cond = cond.And(builder.In("issue.poster_id", f.PosterIDs))
+	}
+	if len(f.ExcludedPosterIDs) > 0 {
+		cond = cond.And(builder.NotIn("issue.poster_id", f.ExcludedPosterIDs))
+	}
+
+	assigneesCond := func(ids []int64) *builder.Builder {
+		return builder.Select("issue_id").From("issue_assignees").Where(builder.In("assignee_id", ids))
+	}
+	if len(f.AssigneeIDs) > 0 {
+		cond = cond.And(builder.In("issue.id", assigneesCond(f.AssigneeIDs)))
+	}
+	if len(f.ExcludedAssigneeIDs) > 0 {
+		cond = cond.And(builder.NotIn("issue.id", assigneesCond(f.ExcludedAssigneeIDs)))
+	}
+	if f.NoAssignee {
+		cond = cond.And(builder.NotIn("issue.id", builder.Select("issue_id").From("issue_assignees")))
+	}
+
+	if len(f.MentionedIDs) > 0 {
+		cond = cond.And(builder.In("issue.id", builder.Select("issue_id").From("issue_user").
+			Where(builder.Eq{"is_mentioned": true}.And(builder.In("uid", f.MentionedIDs)))))
+	}
+
+	if len(f.MilestoneIDs) > 0 {
+		cond = cond.And(builder.In("issue.milestone_id", f.MilestoneIDs))
+	}
+	if len(f.ExcludedMilestoneIDs) > 0 {
+		cond = cond.And(builder.NotIn("issue.milestone_id", f.ExcludedMilestoneIDs))
+	}
+	if f.NoMilestone {
+		cond = cond.And(builder.Eq{"issue.milestone_id": 0})
+	}
+
+	if f.CreatedAfterUnix != 0 {
+		cond = cond.And(builder.Gte{"issue.created_unix": f.CreatedAfterUnix})
+	}
+	if f.CreatedBeforeUnix != 0 {
+		cond = cond.And(builder.Lte{"issue.created_unix": f.CreatedBeforeUnix})
+	}
+	if f.UpdatedAfterUnix != 0 {
+		cond = cond.And(builder.Gte{"issue.updated_unix": f.UpdatedAfterUnix})
+	}
+	if f.UpdatedBeforeUnix != 0 {
+		cond = cond.And(builder.Lte{"issue.updated_unix": f.UpdatedBeforeUnix})
+	}
+
+	return cond
+}
@@ -0,0 +1,111 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues_test
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueFilter(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	var repoIDs []int64
+	assert.NoError(t, db.GetEngine(db.DefaultContext).Table("repository").Cols("id").Find(&repoIDs))
+	for _, test := range []struct {
+		Filter           issues_model.IssueFilter
+		ExpectedIssueIDs []int64
+	}{
+		{
+			issues_model.IssueFilter{RepoNames: []string{"user2/repo1"}},
+			[]int64{1, 2, 3, 5, 11},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"USER2/repo1"}, IsClosed: util.OptionalBoolFalse, IsPull: util.OptionalBoolFalse},
+			[]int64{1},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"user2/repo1*"}, ExcludedRepoNames: []string{"*15", "user2/*16"}},
+			[]int64{1, 2, 3, 5, 11},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"user2/rep_*"}},
+			[]int64{},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, LabelNames: [][]string{{"label1"}, {"label1", "unknown"}}},
+			[]int64{1, 2},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, ExcludedLabelNames: []string{"label1"}},
+			[]int64{3, 5, 11},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, NoLabel: true, NoMilestone: true},
+			[]int64{11},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, PosterNames: []string{"user2", "unknown"}},
+			[]int64{5},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, ExcludedPosterNames: []string{"user2"}},
+			[]int64{1, 2, 3, 11},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, AssigneeNames: []string{"user1"}},
+			[]int64{1},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, MentionedNames: []string{"user4"}},
+			[]int64{1},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, MilestoneNames: []string{"milestone1", "milestone3"}},
+			[]int64{2, 3},
+		},
+		{
+			issues_model.IssueFilter{RepoNames: []string{"repo1"}, CreatedAfterUnix: 946684810, CreatedBeforeUnix: 946684840},
+			[]int64{2, 3, 5},
+		},
+		{
+			issues_model.IssueFilter{PosterNames: []string{"unknown"}},
+			[]int64{},
+		},
+	} {
+		cond, err := test.Filter.Cond(db.DefaultContext)
+		assert.NoError(t, err)
+		issues, err := issues_model.Issues(db.DefaultContext, &issues_model.IssuesOptions{
+			FilterCond: cond,
+			SortType:   "oldest",
+		})
+		if assert.NoError(t, err) {
+			ids := make([]int64, 0, len(issues))
+			for _, issue := range issues {
+				ids = append(ids, issue.ID)
+			}
+			assert.Equal(t, test.ExpectedIssueIDs, ids, "filter: %+v", test.Filter)
+		}
+
+		// the filter resolved for the indexers matches the same issues
+		resolved, err := test.Filter.Resolve(db.DefaultContext, repoIDs)
+		assert.NoError(t, err)
+		ids := []int64{}
+		if resolved != nil {
+			issues, err := issues_model.Issues(db.DefaultContext, &issues_model.IssuesOptions{
+				FilterCond: resolved.Cond(),
+			})
+			assert.NoError(t, err)
+			for _, issue := range issues {
+				ids = append(ids, issue.ID)
+			}
+		}
+		assert.ElementsMatch(t, test.ExpectedIssueIDs, ids, "resolved filter: %+v", test.Filter)
+	}
+}
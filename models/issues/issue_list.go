@@ -317,6 +317,40 @@ func (issues IssueList) loadAssignees(ctx context.Context) error {
 	return nil
 }
 
+// LoadLabels loads the labels of the issues
+func (issues IssueList) LoadLabels(ctx context.Context) error {
+	return issues.loadLabels(ctx)
+}
+
+// LoadAssignees loads the assignees of the issues
+func (issues IssueList) LoadAssignees(ctx context.Context) error {
+	return issues.loadAssignees(ctx)
+}
+
+// GetMentionIDs returns the IDs of the users mentioned by the issues, by issue ID
+func (issues IssueList) GetMentionIDs(ctx context.Context) (map[int64][]int64, error) {
+	mentionIDs := make(map[int64][]int64, len(issues))
+	issueIDs := issues.getIssueIDs()
+	for len(issueIDs) > 0 {
+		limit := db.DefaultMaxInSize
+		if len(issueIDs) < limit {
+			limit = len(issueIDs)
+		}
+		var issueUsers []*IssueUser
+		if err := db.GetEngine(ctx).
+			In("issue_id", issueIDs[:limit]).
+			And("is_mentioned=?", true).
+			Find(&issueUsers); err != nil {
+			return nil, err
+		}
+		for _, issueUser := range issueUsers {
+			mentionIDs[issueUser.IssueID] = append(mentionIDs[issueUser.IssueID], issueUser.UID)
+		}
+		issueIDs = issueIDs[limit:]
+	}
+	return mentionIDs, nil
+}
+
 func (issues IssueList) getPullIssueIDs() []int64 {
 	ids := make([]int64, 0, len(issues))
 	for _, issue := range issues {
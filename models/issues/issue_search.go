@@ -24,6 +24,7 @@ type IssuesOptions struct { //nolint
 	db.ListOptions
 	RepoIDs            []int64 // overwrites RepoCond if the length is not 0
 	RepoCond           builder.Cond
+	FilterCond         builder.Cond // additional conditions on the issues, see IssueFilter
 	AssigneeID         int64
 	PosterID           int64
 	MentionedID        int64
@@ -241,6 +242,10 @@ func applyConditions(sess *xorm.Session, opts *IssuesOptions) *xorm.Session {
 
 	applyLabelsCondition(sess, opts)
 
+	if opts.FilterCond != nil {
+		sess.And(opts.FilterCond)
+	}
+
 	if opts.User != nil {
 		sess.And(issuePullAccessibleRepoCond("issue.repo_id", opts.User.ID, opts.Org, opts.Team, opts.IsPull.IsTrue()))
 	}
@@ -449,10 +454,14 @@ func Issues(ctx context.Context, opts *IssuesOptions) ([]*Issue, error) {
 
 // SearchIssueIDsByKeyword search issues on database
 func SearchIssueIDsByKeyword(ctx context.Context, kw string, repoIDs []int64, limit, start int) (int64, []int64, error) {
-	repoCond := builder.In("repo_id", repoIDs)
-	subQuery := builder.Select("id").From("issue").Where(repoCond)
+	return SearchIssueIDsByFilter(ctx, kw, &ResolvedIssueFilter{RepoIDs: repoIDs}, limit, start)
+}
+
+// SearchIssueIDsByFilter search issues matching the keyword and the filter on database
+func SearchIssueIDsByFilter(ctx context.Context, kw string, filter *ResolvedIssueFilter, limit, start int) (int64, []int64, error) {
+	subQuery := builder.Select("id").From("issue").Where(builder.In("repo_id", filter.RepoIDs))
 	cond := builder.And(
-		repoCond,
+		filter.Cond(),
 		builder.Or(
 			db.BuildCaseInsensitiveLike("name", kw),
 			db.BuildCaseInsensitiveLike("content", kw),
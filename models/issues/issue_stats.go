@@ -208,6 +208,9 @@ func GetUserIssueStats(filterMode int, opts IssuesOptions) (*IssueStats, error)
 	if opts.RepoCond != nil {
 		cond = cond.And(opts.RepoCond)
 	}
+	if opts.FilterCond != nil {
+		cond = cond.And(opts.FilterCond)
+	}
 
 	if opts.User != nil {
 		cond = cond.And(issuePullAccessibleRepoCond("issue.repo_id", opts.User.ID, opts.Org, opts.Team, opts.IsPull.IsTrue()))
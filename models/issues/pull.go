@@ -18,7 +18,10 @@ import (
 	pull_model "code.gitea.io/gitea/models/pull"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
@@ -217,6 +220,12 @@ func DeletePullsByBaseRepoID(ctx context.Context, repoID int64) error {
 		return err
 	}
 
+	// Delete merge queue entries
+	if _, err := db.GetEngine(ctx).In("pull_id", deleteCond).
+		Delete(&pull_model.MergeQueueEntry{}); err != nil {
+		return err
+	}
+
 	// Delete review states
 	if _, err := db.GetEngine(ctx).In("pull_id", deleteCond).
 		Delete(&pull_model.ReviewState{}); err != nil {
@@ -890,45 +899,105 @@ func MergeBlockedByOutdatedBranch(protectBranch *git_model.ProtectedBranch, pr *
 	return protectBranch.BlockOnOutdatedBranch && pr.CommitsBehind > 0
 }
 
-func PullRequestCodeOwnersReview(ctx context.Context, pull *Issue, pr *PullRequest) error {
-	files := []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
+// codeOwnersFiles are the paths the CODEOWNERS file is looked up at, in order of precedence
+var codeOwnersFiles = []string{"CODEOWNERS", "docs/CODEOWNERS", ".gitea/CODEOWNERS"}
 
-	if pr.IsWorkInProgress() {
-		return nil
-	}
+// codeOwnersCacheEntry is the cached content of the CODEOWNERS file and the files changed by a pull request
+type codeOwnersCacheEntry struct {
+	Content      string
+	ChangedFiles []string
+}
 
+// getCodeOwnersRulesAndChangedFiles returns the code owner rules of the default branch and the files changed by the pull request.
+// Reading the CODEOWNERS file and diffing the pull request is cached by the commits involved.
+func getCodeOwnersRulesAndChangedFiles(ctx context.Context, pr *PullRequest) ([]*CodeOwnerRule, []string, error) {
 	if err := pr.LoadBaseRepo(ctx); err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	repo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 	defer repo.Close()
 
 	branch, err := repo.GetDefaultBranch()
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
 	commit, err := repo.GetBranchCommit(branch)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+	baseCommitID, err := repo.GetBranchCommitID(pr.BaseBranch)
+	if err != nil {
+		return nil, nil, err
+	}
+	headCommitID, err := repo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		return nil, nil, err
 	}
 
-	var data string
-	for _, file := range files {
-		if blob, err := commit.GetBlobByPath(file); err == nil {
-			data, err = blob.GetBlobContent(setting.UI.MaxDisplayFileSize)
-			if err == nil {
-				break
+	cacheKey := fmt.Sprintf("pull_code_owners_%d_%s_%s_%s", pr.BaseRepoID, commit.ID.String(), baseCommitID, headCommitID)
+	cached, err := cache.GetString(cacheKey, func() (string, error) {
+		entry := &codeOwnersCacheEntry{}
+		for _, file := range codeOwnersFiles {
+			if blob, err := commit.GetBlobByPath(file); err == nil {
+				entry.Content, err = blob.GetBlobContent(setting.UI.MaxDisplayFileSize)
+				if err == nil {
+					break
+				}
 			}
 		}
+
+		if entry.Content != "" {
+			// Only the files changed by the pull request itself are of interest, not the ones changed on the base branch in the meantime
+			mergeBase, _, err := repo.GetMergeBase("", baseCommitID, headCommitID)
+			if err != nil {
+				return "", err
+			}
+			entry.ChangedFiles, err = repo.GetFilesChangedBetween(mergeBase, headCommitID)
+			if err != nil {
+				return "", err
+			}
+		}
+
+		data, err := json.Marshal(entry)
+		return string(data), err
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	entry := &codeOwnersCacheEntry{}
+	if err := json.Unmarshal([]byte(cached), entry); err != nil {
+		return nil, nil, err
+	}
+
+	rules, _ := GetCodeOwnersFromContent(ctx, entry.Content)
+	if len(rules) == 0 {
+		return nil, nil, nil
+	}
+
+	return rules, entry.ChangedFiles, nil
+}
+
+// PullRequestCodeOwnersReview requests reviews from the code owners of the files changed by the pull request.
+// Code owners who already have reviewed or been requested are skipped, so it can be called again after new commits have been pushed.
+func PullRequestCodeOwnersReview(ctx context.Context, pull *Issue, pr *PullRequest) error {
+	if pr.IsWorkInProgress() {
+		return nil
 	}
 
-	rules, _ := GetCodeOwnersFromContent(ctx, data)
-	changedFiles, err := repo.GetFilesChangedBetween(git.BranchPrefix+pr.BaseBranch, pr.GetGitRefName())
+	if err := pull.LoadPoster(ctx); err != nil {
+		return err
+	}
+	if err := pull.LoadRepo(ctx); err != nil {
+		return err
+	}
+
+	rules, changedFiles, err := getCodeOwnersRulesAndChangedFiles(ctx, pr)
 	if err != nil {
 		return err
 	}
@@ -937,7 +1006,7 @@ func PullRequestCodeOwnersReview(ctx context.Context, pull *Issue, pr *PullReque
 	uniqTeams := make(map[string]*org_model.Team)
 	for _, rule := range rules {
 		for _, f := range changedFiles {
-			if (rule.Rule.MatchString(f) && !rule.Negative) || (!rule.Rule.MatchString(f) && rule.Negative) {
+			if rule.Match(f) {
 				for _, u := range rule.Users {
 					uniqUsers[u.ID] = u
 				}
@@ -949,11 +1018,17 @@ func PullRequestCodeOwnersReview(ctx context.Context, pull *Issue, pr *PullReque
 	}
 
 	for _, u := range uniqUsers {
-		if u.ID != pull.Poster.ID {
-			if _, err := AddReviewRequest(pull, u, pull.Poster); err != nil {
-				log.Warn("Failed add assignee user: %s to PR review: %s#%d, error: %s", u.Name, pr.BaseRepo.Name, pr.ID, err)
-				return err
-			}
+		if u.ID == pull.Poster.ID {
+			continue
+		}
+		if _, err := GetReviewByIssueIDAndUserID(ctx, pull.ID, u.ID); err == nil {
+			continue
+		} else if !IsErrReviewNotExist(err) {
+			return err
+		}
+		if _, err := AddReviewRequest(pull, u, pull.Poster); err != nil {
+			log.Warn("Failed add assignee user: %s to PR review: %s#%d, error: %s", u.Name, pr.BaseRepo.Name, pr.ID, err)
+			return err
 		}
 	}
 	for _, t := range uniqTeams {
@@ -966,6 +1041,161 @@ func PullRequestCodeOwnersReview(ctx context.Context, pull *Issue, pr *PullReque
 	return nil
 }
 
+// CodeOwnersApproval represents the code owners of a file changed by a pull request
+// and those of them who approved the pull request
+type CodeOwnersApproval struct {
+	Path       string
+	Users      []*user_model.User
+	Teams      []*org_model.Team
+	ApprovedBy []*user_model.User
+}
+
+// IsApproved returns true if at least one code owner of the file approved the pull request
+func (approval *CodeOwnersApproval) IsApproved() bool {
+	return len(approval.ApprovedBy) > 0
+}
+
+// OwnerNames returns the names of the users and teams owning the file
+func (approval *CodeOwnersApproval) OwnerNames() []string {
+	names := make([]string, 0, len(approval.Users)+len(approval.Teams))
+	for _, u := range approval.Users {
+		names = append(names, u.Name)
+	}
+	for _, t := range approval.Teams {
+		names = append(names, t.Name)
+	}
+	return names
+}
+
+// ApproverNames returns the names of the code owners who approved the pull request
+func (approval *CodeOwnersApproval) ApproverNames() []string {
+	names := make([]string, 0, len(approval.ApprovedBy))
+	for _, u := range approval.ApprovedBy {
+		names = append(names, u.Name)
+	}
+	return names
+}
+
+// GetCodeOwnersApprovals returns the approval state of every file changed by the pull request which has code owners.
+// An approval counts if it is the latest review of a user who is a code owner of the file or a member of an owning team.
+func GetCodeOwnersApprovals(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest) ([]*CodeOwnersApproval, error) {
+	rules, changedFiles, err := getCodeOwnersRulesAndChangedFiles(ctx, pr)
+	if err != nil || len(rules) == 0 {
+		return nil, err
+	}
+
+	reviews, err := FindReviews(ctx, FindReviewOptions{Type: ReviewTypeUnknown, IssueID: pr.IssueID})
+	if err != nil {
+		return nil, err
+	}
+	latestReviews := make(map[int64]*Review)
+	for _, review := range reviews {
+		if review.ReviewerID > 0 && review.OriginalAuthorID == 0 &&
+			(review.Type == ReviewTypeApprove || review.Type == ReviewTypeReject || review.Type == ReviewTypeRequest) {
+			latestReviews[review.ReviewerID] = review
+		}
+	}
+	approvers := make([]*user_model.User, 0, len(latestReviews))
+	for _, review := range reviews {
+		if latestReviews[review.ReviewerID] != review || review.Type != ReviewTypeApprove || review.Dismissed ||
+			(protectBranch.DismissStaleApprovals && review.Stale) {
+			continue
+		}
+		if err := review.LoadReviewer(ctx); err != nil {
+			return nil, err
+		}
+		approvers = append(approvers, review.Reviewer)
+	}
+
+	teamMembers := make(map[string]bool)
+	isTeamMember := func(team *org_model.Team, user *user_model.User) (bool, error) {
+		key := fmt.Sprintf("%d/%d", team.ID, user.ID)
+		if isMember, ok := teamMembers[key]; ok {
+			return isMember, nil
+		}
+		isMember, err := org_model.IsTeamMember(ctx, team.OrgID, team.ID, user.ID)
+		if err != nil {
+			return false, err
+		}
+		teamMembers[key] = isMember
+		return isMember, nil
+	}
+
+	approvals := make([]*CodeOwnersApproval, 0, len(changedFiles))
+	for _, f := range changedFiles {
+		approval := &CodeOwnersApproval{Path: f}
+		uniqUsers := make(container.Set[int64])
+		uniqTeams := make(container.Set[int64])
+		for _, rule := range rules {
+			if !rule.Match(f) {
+				continue
+			}
+			for _, u := range rule.Users {
+				if uniqUsers.Add(u.ID) {
+					approval.Users = append(approval.Users, u)
+				}
+			}
+			for _, t := range rule.Teams {
+				if uniqTeams.Add(t.ID) {
+					approval.Teams = append(approval.Teams, t)
+				}
+			}
+		}
+		if len(approval.Users) == 0 && len(approval.Teams) == 0 {
+			continue
+		}
+
+		for _, approver := range approvers {
+			isOwner := uniqUsers.Contains(approver.ID)
+			for _, t := range approval.Teams {
+				if isOwner {
+					break
+				}
+				if isOwner, err = isTeamMember(t, approver); err != nil {
+					return nil, err
+				}
+			}
+			if isOwner {
+				approval.ApprovedBy = append(approval.ApprovedBy, approver)
+			}
+		}
+		approvals = append(approvals, approval)
+	}
+
+	return approvals, nil
+}
+
+// MergeBlockedByCodeOwners returns true if merge is blocked because a changed file has not been approved by one of its code owners
+func MergeBlockedByCodeOwners(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest) bool {
+	if !protectBranch.RequireCodeOwnerApproval {
+		return false
+	}
+	approvals, err := GetCodeOwnersApprovals(ctx, protectBranch, pr)
+	if err != nil {
+		log.Error("MergeBlockedByCodeOwners: %v", err)
+		return true
+	}
+	for _, approval := range approvals {
+		if !approval.IsApproved() {
+			return true
+		}
+	}
+	return false
+}
+
+// MergeBlockedByUnresolvedConversations returns true if merge is blocked because some code review conversations are not resolved
+func MergeBlockedByUnresolvedConversations(ctx context.Context, protectBranch *git_model.ProtectedBranch, pr *PullRequest) bool {
+	if !protectBranch.RequireConversationResolution {
+		return false
+	}
+	count, err := CountUnresolvedConversations(ctx, pr.IssueID)
+	if err != nil {
+		log.Error("MergeBlockedByUnresolvedConversations: %v", err)
+		return true
+	}
+	return count > 0
+}
+
 // GetCodeOwnersFromContent returns the code owners configuration
 // Return empty slice if files missing
 // Return warning messages on parsing errors
@@ -1009,6 +1239,11 @@ type CodeOwnerRule struct {
 	Teams    []*org_model.Team
 }
 
+// Match returns true if the rule applies to the file
+func (rule *CodeOwnerRule) Match(path string) bool {
+	return rule.Rule.MatchString(path) != rule.Negative
+}
+
 func ParseCodeOwnersLine(ctx context.Context, tokens []string) (*CodeOwnerRule, []string) {
 	var err error
 	rule := &CodeOwnerRule{
@@ -327,6 +327,19 @@ func TestParseCodeOwnersLine(t *testing.T) {
 	}
 }
 
+func TestCodeOwnerRuleMatch(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	rules, warnings := issues_model.GetCodeOwnersFromContent(db.DefaultContext, "docs/.* @user2\n!.*\\.go @user5\n")
+	assert.Empty(t, warnings)
+	assert.Len(t, rules, 2)
+
+	assert.True(t, rules[0].Match("docs/README.md"))
+	assert.False(t, rules[0].Match("README.md"))
+	assert.True(t, rules[1].Match("docs/README.md"))
+	assert.False(t, rules[1].Match("main.go"))
+}
+
 func TestGetApprovers(t *testing.T) {
 	assert.NoError(t, unittest.PrepareTestDatabase())
 	pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: 5})
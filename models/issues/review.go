@@ -973,6 +973,41 @@ func MarkConversation(comment *Comment, doer *user_model.User, isResolve bool) (
 	return nil
 }
 
+// CountUnresolvedConversations returns the number of unresolved conversations on the current code of a pull request,
+// a conversation is made of the code comments on the same line and is resolved by marking its first comment
+func CountUnresolvedConversations(ctx context.Context, issueID int64) (int64, error) {
+	comments := make([]*Comment, 0, 10)
+	if err := db.GetEngine(ctx).
+		Cols("id", "tree_path", "line", "resolve_doer_id").
+		Where(builder.Eq{"issue_id": issueID, "type": CommentTypeCode, "invalidated": false}).
+		And(builder.In("review_id", builder.Select("id").From("review").Where(builder.Neq{"type": ReviewTypePending}))).
+		Asc("created_unix").
+		Asc("id").
+		Find(&comments); err != nil {
+		return 0, err
+	}
+
+	type conversationKey struct {
+		treePath string
+		line     int64
+	}
+	resolved := make(map[conversationKey]bool, len(comments))
+	for _, comment := range comments {
+		key := conversationKey{treePath: comment.TreePath, line: comment.Line}
+		if _, ok := resolved[key]; !ok {
+			resolved[key] = comment.ResolveDoerID != 0
+		}
+	}
+
+	var count int64
+	for _, isResolved := range resolved {
+		if !isResolved {
+			count++
+		}
+	}
+	return count, nil
+}
+
 // CanMarkConversation  Add or remove Conversation mark for a code comment permission check
 // the PR writer , offfcial reviewer and poster can do it
 func CanMarkConversation(issue *Issue, doer *user_model.User) (permResult bool, err error) {
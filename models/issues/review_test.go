@@ -258,3 +258,55 @@ func TestDeleteReview(t *testing.T) {
 	assert.NoError(t, err)
 	assert.True(t, review1.Official)
 }
+
+func TestCountUnresolvedConversations(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: 2})
+	assert.NoError(t, issue.LoadRepo(db.DefaultContext))
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+
+	// the only code comment on the current code belongs to a pending review
+	count, err := issues_model.CountUnresolvedConversations(db.DefaultContext, issue.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	review, err := issues_model.CreateReview(db.DefaultContext, issues_model.CreateReviewOptions{
+		Type:     issues_model.ReviewTypeComment,
+		Issue:    issue,
+		Reviewer: user,
+	})
+	assert.NoError(t, err)
+	createCodeComment := func(line int64) *issues_model.Comment {
+		comment, err := issues_model.CreateComment(db.DefaultContext, &issues_model.CreateCommentOptions{
+			Type:     issues_model.CommentTypeCode,
+			Doer:     user,
+			Repo:     issue.Repo,
+			Issue:    issue,
+			TreePath: "README.md",
+			LineNum:  line,
+			ReviewID: review.ID,
+			Content:  "code comment",
+		})
+		assert.NoError(t, err)
+		return comment
+	}
+	first := createCodeComment(2)
+	reply := createCodeComment(2)
+	createCodeComment(-1)
+
+	count, err = issues_model.CountUnresolvedConversations(db.DefaultContext, issue.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	// resolving a reply doesn't resolve the conversation
+	assert.NoError(t, issues_model.MarkConversation(reply, user, true))
+	count, err = issues_model.CountUnresolvedConversations(db.DefaultContext, issue.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	assert.NoError(t, issues_model.MarkConversation(first, user, true))
+	count, err = issues_model.CountUnresolvedConversations(db.DefaultContext, issue.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+}
@@ -147,6 +147,71 @@ func InsertPullRequests(prs ...*issues_model.PullRequest) error {
 	return committer.Commit()
 }
 
+// UpdateMigratedIssue updates an issue pulled again from the source of a synchronized repository and replaces its labels
+func UpdateMigratedIssue(issue *issues_model.Issue) error {
+	ctx, committer, err := db.TxContext(db.DefaultContext)
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if err := updateMigratedIssue(ctx, issue); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+func updateMigratedIssue(ctx context.Context, issue *issues_model.Issue) error {
+	sess := db.GetEngine(ctx)
+	if _, err := sess.ID(issue.ID).NoAutoTime().
+		Cols("name", "content", "is_closed", "is_locked", "milestone_id", "closed_unix", "updated_unix").
+		Update(issue); err != nil {
+		return err
+	}
+
+	if _, err := sess.Where("issue_id = ?", issue.ID).Delete(new(issues_model.IssueLabel)); err != nil {
+		return err
+	}
+	issueLabels := make([]issues_model.IssueLabel, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		issueLabels = append(issueLabels, issues_model.IssueLabel{
+			IssueID: issue.ID,
+			LabelID: label.ID,
+		})
+	}
+	if len(issueLabels) > 0 {
+		if _, err := sess.Insert(issueLabels); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// UpdateMigratedPullRequest updates a pull request pulled again from the source of a synchronized repository
+func UpdateMigratedPullRequest(pr *issues_model.PullRequest) error {
+	ctx, committer, err := db.TxContext(db.DefaultContext)
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if err := updateMigratedIssue(ctx, pr.Issue); err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).ID(pr.ID).NoAutoTime().
+		Cols("head_branch", "base_branch", "merge_base", "has_merged", "merged_commit_id", "merged_unix").
+		Update(pr); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// UpdateMigratedComment updates the content of a comment pulled again from the source of a synchronized repository
+func UpdateMigratedComment(comment *issues_model.Comment) error {
+	_, err := db.GetEngine(db.DefaultContext).ID(comment.ID).NoAutoTime().Cols("content", "updated_unix").Update(comment)
+	return err
+}
+
 // InsertReleases migrates release
 func InsertReleases(rels ...*repo_model.Release) error {
 	ctx, committer, err := db.TxContext(db.DefaultContext)
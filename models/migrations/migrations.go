@@ -503,6 +503,34 @@ var migrations = []Migration{
 
 	// v260 -> v261
 	NewMigration("Drop custom_labels column of action_runner table", v1_21.DropCustomLabelsColumnOfActionRunner),
+	// v261 -> v262
+	NewMigration("Add merge queue", v1_21.AddMergeQueue),
+	// v262 -> v263
+	NewMigration("Add RequireCodeOwnerApproval to ProtectedBranch", v1_21.AddRequireCodeOwnerApprovalToProtectedBranch),
+	// v263 -> v264
+	NewMigration("Create Action Variable table", v1_21.CreateVariableTable),
+	// v264 -> v265
+	NewMigration("Add Action Schedule table", v1_21.AddActionScheduleTable),
+	// v265 -> v266
+	NewMigration("Add concurrency group to Action Run and Action Run Job tables", v1_21.AddConcurrencyToActionRunAndJob),
+	// v266 -> v267
+	NewMigration("Add start line to comment table for multi-line code comments", v1_21.AddStartLineToComment),
+	// v267 -> v268
+	NewMigration("Add require linear history and require conversation resolution to protected branch", v1_21.AddRequireLinearHistoryAndConversationResolutionToProtectedBranch),
+	// v268 -> v269
+	NewMigration("Create push rule table", v1_21.CreatePushRuleTable),
+	// v269 -> v270
+	NewMigration("Create migration sync tables", v1_21.CreateMigrationSyncTables),
+	// v270 -> v271
+	NewMigration("Add delivery retries to hook task and failure count to webhook", v1_21.AddWebhookDeliveryRetries),
+	// v271 -> v272
+	NewMigration("Add previous secrets and http signature to webhook", v1_21.AddPreviousSecretsAndHTTPSignatureToWebhook),
+	// v272 -> v273
+	NewMigration("Change package_property value to LONGTEXT", v1_21.ChangePackagePropertyValueToLongText),
+	// v273 -> v274
+	NewMigration("Create package remote tables", v1_21.CreatePackageRemoteTables),
+	// v274 -> v275
+	NewMigration("Create package quota table", v1_21.CreatePackageQuotaTable),
 }
 
 // GetCurrentDBVersion returns the current db version
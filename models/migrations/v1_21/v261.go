@@ -0,0 +1,41 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+type MergeQueueEntry struct {
+	ID            int64              `xorm:"pk autoincr"`
+	RepoID        int64              `xorm:"INDEX(repo_branch) NOT NULL"`
+	BaseBranch    string             `xorm:"INDEX(repo_branch) NOT NULL"`
+	PullID        int64              `xorm:"UNIQUE"`
+	DoerID        int64              `xorm:"NOT NULL"`
+	MergeStyle    string             `xorm:"varchar(30)"`
+	Message       string             `xorm:"LONGTEXT"`
+	BaseCommitID  string             `xorm:"VARCHAR(40)"`
+	QueueCommitID string             `xorm:"VARCHAR(40)"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the name of this table
+func (MergeQueueEntry) TableName() string {
+	return "pull_merge_queue"
+}
+
+func AddMergeQueue(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		EnableMergeQueue bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	if err := x.Sync(new(ProtectedBranch)); err != nil {
+		return err
+	}
+
+	return x.Sync(new(MergeQueueEntry))
+}
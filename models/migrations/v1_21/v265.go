@@ -0,0 +1,22 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddConcurrencyToActionRunAndJob(x *xorm.Engine) error {
+	type ActionRun struct {
+		ConcurrencyGroup  string `xorm:"index"`
+		ConcurrencyCancel bool
+	}
+
+	type ActionRunJob struct {
+		ConcurrencyGroup  string `xorm:"index"`
+		ConcurrencyCancel bool
+	}
+
+	return x.Sync(new(ActionRun), new(ActionRunJob))
+}
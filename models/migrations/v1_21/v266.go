@@ -0,0 +1,16 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddStartLineToComment(x *xorm.Engine) error {
+	type Comment struct {
+		StartLine int64
+	}
+
+	return x.Sync(new(Comment))
+}
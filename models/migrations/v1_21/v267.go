@@ -0,0 +1,17 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"xorm.io/xorm"
+)
+
+func AddRequireLinearHistoryAndConversationResolutionToProtectedBranch(x *xorm.Engine) error {
+	type ProtectedBranch struct {
+		RequireLinearHistory          bool `xorm:"NOT NULL DEFAULT false"`
+		RequireConversationResolution bool `xorm:"NOT NULL DEFAULT false"`
+	}
+
+	return x.Sync(new(ProtectedBranch))
+}
@@ -0,0 +1,26 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreatePushRuleTable(x *xorm.Engine) error {
+	type PushRule struct {
+		ID                    int64              `xorm:"pk autoincr"`
+		OwnerID               int64              `xorm:"UNIQUE(owner_repo)"`
+		RepoID                int64              `xorm:"INDEX UNIQUE(owner_repo)"`
+		CommitMessagePattern  string             `xorm:"TEXT"`
+		AllowedEmailDomains   string             `xorm:"TEXT"`
+		MaxFileSize           int64              `xorm:"NOT NULL DEFAULT 0"`
+		ForbiddenFilePatterns string             `xorm:"TEXT"`
+		CreatedUnix           timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix           timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync(new(PushRule))
+}
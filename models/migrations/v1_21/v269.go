@@ -0,0 +1,49 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreateMigrationSyncTables(x *xorm.Engine) error {
+	type MigrationSync struct {
+		ID                    int64  `xorm:"pk autoincr"`
+		RepoID                int64  `xorm:"UNIQUE"`
+		DoerID                int64  `xorm:"NOT NULL DEFAULT 0"`
+		GitServiceType        int    `xorm:"NOT NULL DEFAULT 0"`
+		CloneAddrEncrypted    string `xorm:"TEXT"`
+		AuthUsername          string
+		AuthPasswordEncrypted string `xorm:"TEXT"`
+		AuthTokenEncrypted    string `xorm:"TEXT"`
+
+		Milestones   bool `xorm:"NOT NULL DEFAULT false"`
+		Labels       bool `xorm:"NOT NULL DEFAULT false"`
+		Releases     bool `xorm:"NOT NULL DEFAULT false"`
+		Issues       bool `xorm:"NOT NULL DEFAULT false"`
+		PullRequests bool `xorm:"NOT NULL DEFAULT false"`
+		Comments     bool `xorm:"NOT NULL DEFAULT false"`
+
+		Interval     time.Duration
+		LastSyncUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		NextSyncUnix timeutil.TimeStamp `xorm:"INDEX"`
+		LastError    string             `xorm:"TEXT"`
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type MigrationSyncReference struct {
+		ID        int64  `xorm:"pk autoincr"`
+		RepoID    int64  `xorm:"UNIQUE(s) INDEX"`
+		Type      string `xorm:"VARCHAR(16) UNIQUE(s)"`
+		ForeignID string `xorm:"VARCHAR(255) UNIQUE(s)"`
+		LocalID   int64  `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(MigrationSync), new(MigrationSyncReference))
+}
@@ -0,0 +1,26 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"code.gitea.io/gitea/models/migrations/base"
+
+	"xorm.io/xorm"
+	"xorm.io/xorm/schemas"
+)
+
+func ChangePackagePropertyValueToLongText(x *xorm.Engine) error {
+	// Only the TEXT type of MySQL is limited to 64KB.
+	if x.Dialect().URI().DBType != schemas.MYSQL {
+		return nil
+	}
+
+	return base.ModifyColumn(x, "package_property", &schemas.Column{
+		Name: "value",
+		SQLType: schemas.SQLType{
+			Name: "LONGTEXT",
+		},
+		Nullable: false,
+	})
+}
@@ -0,0 +1,35 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreatePackageRemoteTables(x *xorm.Engine) error {
+	type PackageRemote struct {
+		ID                 int64              `xorm:"pk autoincr"`
+		Enabled            bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+		OwnerID            int64              `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+		Type               string             `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		URL                string             `xorm:"TEXT NOT NULL"`
+		Username           string             `xorm:"NOT NULL DEFAULT ''"`
+		PasswordEncrypted  string             `xorm:"TEXT"`
+		MetadataTTLMinutes int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix        timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+		UpdatedUnix        timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+	}
+
+	type PackageRemoteMetadata struct {
+		ID          int64              `xorm:"pk autoincr"`
+		RemoteID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Name        string             `xorm:"UNIQUE(s) NOT NULL"`
+		Content     string             `xorm:"LONGTEXT NOT NULL"`
+		FetchedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(PackageRemote), new(PackageRemoteMetadata))
+}
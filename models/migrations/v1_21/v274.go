@@ -0,0 +1,23 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package v1_21 //nolint
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func CreatePackageQuotaTable(x *xorm.Engine) error {
+	type PackageQuota struct {
+		ID              int64              `xorm:"pk autoincr"`
+		OwnerID         int64              `xorm:"UNIQUE NOT NULL"`
+		LimitTotalSize  *int64             `xorm:"NULL"`
+		LimitTotalCount *int64             `xorm:"NULL"`
+		CreatedUnix     timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+		UpdatedUnix     timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync(new(PackageQuota))
+}
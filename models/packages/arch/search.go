@@ -0,0 +1,109 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package arch
+
+import (
+	"context"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	arch_module "code.gitea.io/gitea/modules/packages/arch"
+)
+
+// PackageFileDescriptor is a package file with its version and package
+type PackageFileDescriptor struct {
+	*packages_model.PackageFileDescriptor
+	Package *packages_model.Package
+	Version *packages_model.PackageVersion
+}
+
+// SearchLatestPackages gets the latest version of every package which can be installed from the repository on the architecture.
+// The packages of the "any" architecture are included.
+func SearchLatestPackages(ctx context.Context, ownerID int64, repository, architecture string) ([]*PackageFileDescriptor, error) {
+	architectures := []string{architecture}
+	if architecture != arch_module.AnyArchitecture {
+		architectures = append(architectures, arch_module.AnyArchitecture)
+	}
+
+	var pfs []*packages_model.PackageFile
+	for _, architecture := range architectures {
+		files, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+			OwnerID:     ownerID,
+			PackageType: packages_model.TypeArch,
+			Properties: map[string]string{
+				arch_module.PropertyRepository:   repository,
+				arch_module.PropertyArchitecture: architecture,
+			},
+		})
+		if err != nil {
+			return nil, err
+		}
+		pfs = append(pfs, files...)
+	}
+
+	latest := make(map[int64]*PackageFileDescriptor, len(pfs))
+	packageIDs := make([]int64, 0, len(pfs))
+	for _, pf := range pfs {
+		pv, err := packages_model.GetVersionByID(ctx, pf.VersionID)
+		if err != nil {
+			return nil, err
+		}
+
+		other, has := latest[pv.PackageID]
+		if !has {
+			packageIDs = append(packageIDs, pv.PackageID)
+		} else if pv.CreatedUnix < other.Version.CreatedUnix || (pv.CreatedUnix == other.Version.CreatedUnix && pv.ID < other.Version.ID) {
+			continue
+		}
+		latest[pv.PackageID] = &PackageFileDescriptor{
+			PackageFileDescriptor: &packages_model.PackageFileDescriptor{File: pf},
+			Version:               pv,
+		}
+	}
+
+	res := make([]*PackageFileDescriptor, 0, len(packageIDs))
+	for _, packageID := range packageIDs {
+		pfd := latest[packageID]
+
+		p, err := packages_model.GetPackageByID(ctx, packageID)
+		if err != nil {
+			return nil, err
+		}
+		pfd.Package = p
+
+		pfd.PackageFileDescriptor, err = packages_model.GetPackageFileDescriptor(ctx, pfd.File)
+		if err != nil {
+			return nil, err
+		}
+
+		res = append(res, pfd)
+	}
+	return res, nil
+}
+
+// GetRepositories gets all available repositories
+func GetRepositories(ctx context.Context, ownerID int64) ([]string, error) {
+	return packages_model.GetDistinctPropertyValues(
+		ctx,
+		packages_model.TypeArch,
+		ownerID,
+		packages_model.PropertyTypeFile,
+		arch_module.PropertyRepository,
+		nil,
+	)
+}
+
+// GetArchitectures gets all available architectures for the given repository
+func GetArchitectures(ctx context.Context, ownerID int64, repository string) ([]string, error) {
+	return packages_model.GetDistinctPropertyValues(
+		ctx,
+		packages_model.TypeArch,
+		ownerID,
+		packages_model.PropertyTypeFile,
+		arch_module.PropertyArchitecture,
+		&packages_model.DistinctPropertyDependency{
+			Name:  arch_module.PropertyRepository,
+			Value: repository,
+		},
+	)
+}
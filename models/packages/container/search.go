@@ -116,6 +116,40 @@ func GetManifestVersions(ctx context.Context, opts *BlobSearchOptions) ([]*packa
 		Find(&pvs)
 }
 
+// GetReferrerVersions gets all package versions of the image whose manifest references the subject digest.
+// If artifactType is not empty only versions with this artifact type are returned.
+func GetReferrerVersions(ctx context.Context, ownerID int64, image, subject, artifactType string) ([]*packages.PackageVersion, error) {
+	var cond builder.Cond = builder.Eq{
+		"package.type":                packages.TypeContainer,
+		"package.owner_id":            ownerID,
+		"package.lower_name":          strings.ToLower(image),
+		"package_version.is_internal": false,
+	}
+
+	props := map[string]string{
+		container_module.PropertyManifestSubject: subject,
+	}
+	if artifactType != "" {
+		props[container_module.PropertyArtifactType] = artifactType
+	}
+	for name, value := range props {
+		var propsCond builder.Cond = builder.Eq{
+			"package_property.ref_type": packages.PropertyTypeVersion,
+			"package_property.name":     name,
+			"package_property.value":    value,
+		}
+
+		cond = cond.And(builder.In("package_version.id", builder.Select("package_property.ref_id").Where(propsCond).From("package_property")))
+	}
+
+	pvs := make([]*packages.PackageVersion, 0, 10)
+	return pvs, db.GetEngine(ctx).
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where(cond).
+		Asc("package_version.created_unix", "package_version.id").
+		Find(&pvs)
+}
+
 // GetImageTags gets a sorted list of the tags of an image
 // The result is suitable for the api call.
 func GetImageTags(ctx context.Context, ownerID int64, image string, n int, last string) ([]string, error) {
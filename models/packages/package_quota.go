@@ -0,0 +1,157 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package packages
+
+import (
+	"context"
+	"sort"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+var ErrPackageQuotaNotExist = util.NewNotExistErrorf("package quota does not exist")
+
+func init() {
+	db.RegisterModel(new(PackageQuota))
+}
+
+// PackageQuota overrides the global package limits for a single owner.
+// A nil limit falls back to the global setting, -1 means unlimited.
+type PackageQuota struct {
+	ID              int64              `xorm:"pk autoincr"`
+	OwnerID         int64              `xorm:"UNIQUE NOT NULL"`
+	LimitTotalSize  *int64             `xorm:"NULL"`
+	LimitTotalCount *int64             `xorm:"NULL"`
+	CreatedUnix     timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix     timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+}
+
+// GetQuotaByOwner returns the quota of the owner
+func GetQuotaByOwner(ctx context.Context, ownerID int64) (*PackageQuota, error) {
+	pq := &PackageQuota{}
+
+	has, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Get(pq)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageQuotaNotExist
+	}
+	return pq, nil
+}
+
+func GetQuotaByID(ctx context.Context, id int64) (*PackageQuota, error) {
+	pq := &PackageQuota{}
+
+	has, err := db.GetEngine(ctx).ID(id).Get(pq)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageQuotaNotExist
+	}
+	return pq, nil
+}
+
+// GetQuotas returns all owner quotas
+func GetQuotas(ctx context.Context) ([]*PackageQuota, error) {
+	pqs := make([]*PackageQuota, 0, 10)
+	return pqs, db.GetEngine(ctx).OrderBy("owner_id").Find(&pqs)
+}
+
+// SetQuota inserts or updates the quota of the owner
+func SetQuota(ctx context.Context, pq *PackageQuota) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		existing, err := GetQuotaByOwner(ctx, pq.OwnerID)
+		if err != nil && err != ErrPackageQuotaNotExist {
+			return err
+		}
+		if existing == nil {
+			return db.Insert(ctx, pq)
+		}
+
+		pq.ID = existing.ID
+		_, err = db.GetEngine(ctx).ID(pq.ID).Cols("limit_total_size", "limit_total_count").Update(pq)
+		return err
+	})
+}
+
+func DeleteQuotaByID(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&PackageQuota{})
+	return err
+}
+
+func DeleteQuotaByOwner(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(&PackageQuota{})
+	return err
+}
+
+// TypeUsage is the storage used by the packages of a type
+type TypeUsage struct {
+	Type         Type
+	VersionCount int64
+	Size         int64
+}
+
+// GetOwnerUsage returns the storage used by the packages of the owner grouped by package type.
+// Like CalculateFileSize it does NOT respect the deduplication of blobs.
+func GetOwnerUsage(ctx context.Context, ownerID int64) ([]*TypeUsage, error) {
+	type typeCount struct {
+		Type  Type
+		Count int64
+	}
+	counts := make([]*typeCount, 0, len(TypeList))
+	if err := db.GetEngine(ctx).
+		Table("package_version").
+		Select("package.type AS type, COUNT(*) AS count").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where("package.owner_id = ? AND package_version.is_internal = ?", ownerID, false).
+		GroupBy("package.type").
+		Find(&counts); err != nil {
+		return nil, err
+	}
+
+	type typeSize struct {
+		Type Type
+		Size int64
+	}
+	sizes := make([]*typeSize, 0, len(TypeList))
+	if err := db.GetEngine(ctx).
+		Table("package_file").
+		Select("package.type AS type, SUM(package_blob.size) AS size").
+		Join("INNER", "package_blob", "package_blob.id = package_file.blob_id").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where("package.owner_id = ? AND package_version.is_internal = ?", ownerID, false).
+		GroupBy("package.type").
+		Find(&sizes); err != nil {
+		return nil, err
+	}
+
+	usages := make(map[Type]*TypeUsage, len(counts))
+	for _, c := range counts {
+		usages[c.Type] = &TypeUsage{Type: c.Type, VersionCount: c.Count}
+	}
+	for _, s := range sizes {
+		if u, ok := usages[s.Type]; ok {
+			u.Size = s.Size
+		} else {
+			usages[s.Type] = &TypeUsage{Type: s.Type, Size: s.Size}
+		}
+	}
+
+	result := make([]*TypeUsage, 0, len(usages))
+	for _, u := range usages {
+		result = append(result, u)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Size == result[j].Size {
+			return result[i].Type < result[j].Type
+		}
+		return result[i].Size > result[j].Size
+	})
+	return result, nil
+}
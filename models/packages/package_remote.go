@@ -0,0 +1,193 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package packages
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+var (
+	ErrPackageRemoteNotExist         = util.NewNotExistErrorf("package remote does not exist")
+	ErrPackageRemoteMetadataNotExist = util.NewNotExistErrorf("package remote metadata does not exist")
+)
+
+// PropertyRemoteOrigin is the name of the version property which stores the upstream url of a cached package version
+const PropertyRemoteOrigin = "remote.origin"
+
+// RemoteTypeList contains the package types which can be proxied from an upstream registry
+var RemoteTypeList = []Type{
+	TypeContainer,
+	TypeMaven,
+	TypeNpm,
+	TypePyPI,
+}
+
+// IsRemoteSupported returns true if packages of this type can be proxied from an upstream registry
+func (pt Type) IsRemoteSupported() bool {
+	for _, t := range RemoteTypeList {
+		if t == pt {
+			return true
+		}
+	}
+	return false
+}
+
+func init() {
+	db.RegisterModel(new(PackageRemote))
+	db.RegisterModel(new(PackageRemoteMetadata))
+}
+
+// PackageRemote represents an upstream registry from which missing packages of an owner get fetched and cached
+type PackageRemote struct {
+	ID                 int64              `xorm:"pk autoincr"`
+	Enabled            bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	OwnerID            int64              `xorm:"UNIQUE(s) INDEX NOT NULL DEFAULT 0"`
+	Type               Type               `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	URL                string             `xorm:"TEXT NOT NULL"`
+	Username           string             `xorm:"NOT NULL DEFAULT ''"`
+	PasswordEncrypted  string             `xorm:"TEXT"`
+	MetadataTTLMinutes int                `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix        timeutil.TimeStamp `xorm:"created NOT NULL DEFAULT 0"`
+	UpdatedUnix        timeutil.TimeStamp `xorm:"updated NOT NULL DEFAULT 0"`
+}
+
+// SetPassword encrypts and stores the password used to access the upstream registry
+func (pr *PackageRemote) SetPassword(password string) (err error) {
+	pr.PasswordEncrypted, err = secret.EncryptSecret(setting.SecretKey, password)
+	return err
+}
+
+// Password returns the decrypted password used to access the upstream registry
+func (pr *PackageRemote) Password() (string, error) {
+	if pr.PasswordEncrypted == "" {
+		return "", nil
+	}
+	return secret.DecryptSecret(setting.SecretKey, pr.PasswordEncrypted)
+}
+
+// MetadataTTL returns the duration for which fetched upstream metadata is considered fresh
+func (pr *PackageRemote) MetadataTTL() time.Duration {
+	if pr.MetadataTTLMinutes <= 0 {
+		return setting.Packages.RemoteMetadataTTL
+	}
+	return time.Duration(pr.MetadataTTLMinutes) * time.Minute
+}
+
+func InsertRemote(ctx context.Context, pr *PackageRemote) (*PackageRemote, error) {
+	return pr, db.Insert(ctx, pr)
+}
+
+func GetRemoteByID(ctx context.Context, id int64) (*PackageRemote, error) {
+	pr := &PackageRemote{}
+
+	has, err := db.GetEngine(ctx).ID(id).Get(pr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageRemoteNotExist
+	}
+	return pr, nil
+}
+
+// GetEnabledRemoteByOwnerAndType gets the enabled remote of the owner for the package type
+func GetEnabledRemoteByOwnerAndType(ctx context.Context, ownerID int64, packageType Type) (*PackageRemote, error) {
+	pr := &PackageRemote{}
+
+	has, err := db.GetEngine(ctx).
+		Where("owner_id = ? AND type = ? AND enabled = ?", ownerID, packageType, true).
+		Get(pr)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageRemoteNotExist
+	}
+	return pr, nil
+}
+
+func UpdateRemote(ctx context.Context, pr *PackageRemote) error {
+	_, err := db.GetEngine(ctx).ID(pr.ID).AllCols().Update(pr)
+	return err
+}
+
+func GetRemotesByOwner(ctx context.Context, ownerID int64) ([]*PackageRemote, error) {
+	prs := make([]*PackageRemote, 0, len(RemoteTypeList))
+	return prs, db.GetEngine(ctx).Where("owner_id = ?", ownerID).Find(&prs)
+}
+
+// DeleteRemoteByID deletes the remote and its cached metadata. Cached package versions are kept.
+func DeleteRemoteByID(ctx context.Context, remoteID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if err := DeleteRemoteMetadataByRemoteID(ctx, remoteID); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).ID(remoteID).Delete(&PackageRemote{})
+		return err
+	})
+}
+
+func HasOwnerRemoteForPackageType(ctx context.Context, ownerID int64, packageType Type) (bool, error) {
+	return db.GetEngine(ctx).
+		Where("owner_id = ? AND type = ?", ownerID, packageType).
+		Exist(&PackageRemote{})
+}
+
+// PackageRemoteMetadata is upstream metadata (package indexes, tag references, ...) fetched by a remote
+type PackageRemoteMetadata struct {
+	ID          int64              `xorm:"pk autoincr"`
+	RemoteID    int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Name        string             `xorm:"UNIQUE(s) NOT NULL"`
+	Content     string             `xorm:"LONGTEXT NOT NULL"`
+	FetchedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+}
+
+func GetRemoteMetadata(ctx context.Context, remoteID int64, name string) (*PackageRemoteMetadata, error) {
+	prm := &PackageRemoteMetadata{}
+
+	has, err := db.GetEngine(ctx).Where("remote_id = ? AND name = ?", remoteID, name).Get(prm)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrPackageRemoteMetadataNotExist
+	}
+	return prm, nil
+}
+
+// SetRemoteMetadata inserts or updates the metadata stored under the name
+func SetRemoteMetadata(ctx context.Context, remoteID int64, name, content string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		prm, err := GetRemoteMetadata(ctx, remoteID, name)
+		if err != nil && err != ErrPackageRemoteMetadataNotExist {
+			return err
+		}
+		if prm == nil {
+			return db.Insert(ctx, &PackageRemoteMetadata{
+				RemoteID:    remoteID,
+				Name:        name,
+				Content:     content,
+				FetchedUnix: timeutil.TimeStampNow(),
+			})
+		}
+
+		prm.Content = content
+		prm.FetchedUnix = timeutil.TimeStampNow()
+		_, err = db.GetEngine(ctx).ID(prm.ID).Cols("content", "fetched_unix").Update(prm)
+		return err
+	})
+}
+
+// DeleteRemoteMetadataByRemoteID deletes all metadata fetched by the remote
+func DeleteRemoteMetadataByRemoteID(ctx context.Context, remoteID int64) error {
+	_, err := db.GetEngine(ctx).Where("remote_id = ?", remoteID).Delete(&PackageRemoteMetadata{})
+	return err
+}
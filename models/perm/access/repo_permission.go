@@ -401,6 +401,11 @@ func GetRepoWriters(repo *repo_model.Repository) (_ []*user_model.User, err erro
 	return getUsersWithAccessMode(db.DefaultContext, repo, perm_model.AccessModeWrite)
 }
 
+// GetRepoAdmins returns all users that have admin access to the repository.
+func GetRepoAdmins(ctx context.Context, repo *repo_model.Repository) (_ []*user_model.User, err error) {
+	return getUsersWithAccessMode(ctx, repo, perm_model.AccessModeAdmin)
+}
+
 // IsRepoReader returns true if user has explicit read access or higher to the repository.
 func IsRepoReader(ctx context.Context, repo *repo_model.Repository, userID int64) (bool, error) {
 	if repo.OwnerID == userID {
@@ -0,0 +1,145 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pull
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of its base branch.
+// Entries of the same branch are processed in the order of their IDs.
+type MergeQueueEntry struct {
+	ID         int64                 `xorm:"pk autoincr"`
+	RepoID     int64                 `xorm:"INDEX(repo_branch) NOT NULL"`
+	BaseBranch string                `xorm:"INDEX(repo_branch) NOT NULL"`
+	PullID     int64                 `xorm:"UNIQUE"`
+	DoerID     int64                 `xorm:"NOT NULL"`
+	Doer       *user_model.User      `xorm:"-"`
+	MergeStyle repo_model.MergeStyle `xorm:"varchar(30)"`
+	Message    string                `xorm:"LONGTEXT"`
+	// BaseCommitID is the commit the queue commit has been built on:
+	// either the tip of the base branch or the queue commit of the previous entry
+	BaseCommitID string `xorm:"VARCHAR(40)"`
+	// QueueCommitID is the commit of the temporary branch the required status checks are run against
+	QueueCommitID string             `xorm:"VARCHAR(40)"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName return database table name for xorm
+func (MergeQueueEntry) TableName() string {
+	return "pull_merge_queue"
+}
+
+func init() {
+	db.RegisterModel(new(MergeQueueEntry))
+}
+
+// LoadDoer loads the user who added the pull request to the merge queue
+func (entry *MergeQueueEntry) LoadDoer(ctx context.Context) (err error) {
+	if entry.Doer != nil {
+		return nil
+	}
+	entry.Doer, err = user_model.GetUserByID(ctx, entry.DoerID)
+	return err
+}
+
+// ErrAlreadyInMergeQueue represents a "PullRequestAlreadyInMergeQueue"-error
+type ErrAlreadyInMergeQueue struct {
+	PullID int64
+}
+
+func (err ErrAlreadyInMergeQueue) Error() string {
+	return fmt.Sprintf("pull request is already in the merge queue [pull_id: %d]", err.PullID)
+}
+
+// IsErrAlreadyInMergeQueue checks if an error is a ErrAlreadyInMergeQueue.
+func IsErrAlreadyInMergeQueue(err error) bool {
+	_, ok := err.(ErrAlreadyInMergeQueue)
+	return ok
+}
+
+// AddToMergeQueue appends a pull request to the end of the merge queue of its base branch
+func AddToMergeQueue(ctx context.Context, doer *user_model.User, repoID int64, baseBranch string, pullID int64, style repo_model.MergeStyle, message string) (*MergeQueueEntry, error) {
+	if exists, _, err := GetMergeQueueEntryByPullID(ctx, pullID); err != nil {
+		return nil, err
+	} else if exists {
+		return nil, ErrAlreadyInMergeQueue{PullID: pullID}
+	}
+
+	entry := &MergeQueueEntry{
+		RepoID:     repoID,
+		BaseBranch: baseBranch,
+		PullID:     pullID,
+		DoerID:     doer.ID,
+		Doer:       doer,
+		MergeStyle: style,
+		Message:    message,
+	}
+	if _, err := db.GetEngine(ctx).Insert(entry); err != nil {
+		return nil, err
+	}
+	return entry, nil
+}
+
+// GetMergeQueueEntryByPullID gets the merge queue entry of a pull request
+func GetMergeQueueEntryByPullID(ctx context.Context, pullID int64) (bool, *MergeQueueEntry, error) {
+	entry := &MergeQueueEntry{}
+	exists, err := db.GetEngine(ctx).Where("pull_id = ?", pullID).Get(entry)
+	if err != nil || !exists {
+		return false, nil, err
+	}
+	return true, entry, nil
+}
+
+// GetMergeQueue returns all entries of the merge queue of a branch in processing order
+func GetMergeQueue(ctx context.Context, repoID int64, baseBranch string) ([]*MergeQueueEntry, error) {
+	entries := make([]*MergeQueueEntry, 0, 10)
+	return entries, db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ?", repoID, baseBranch).
+		Asc("id").
+		Find(&entries)
+}
+
+// GetMergeQueuePosition returns the 1-based position of the entry in the merge queue of its branch
+func GetMergeQueuePosition(ctx context.Context, entry *MergeQueueEntry) (int64, error) {
+	return db.GetEngine(ctx).
+		Where("repo_id = ? AND base_branch = ? AND id <= ?", entry.RepoID, entry.BaseBranch, entry.ID).
+		Count(new(MergeQueueEntry))
+}
+
+// GetMergeQueueBranchesByCommitID returns the base branches of all merge queues containing an entry
+// whose queue commit is the given commit
+func GetMergeQueueBranchesByCommitID(ctx context.Context, repoID int64, commitID string) ([]string, error) {
+	branches := make([]string, 0, 1)
+	return branches, db.GetEngine(ctx).Table("pull_merge_queue").
+		Where("repo_id = ? AND queue_commit_id = ?", repoID, commitID).
+		Distinct("base_branch").
+		Find(&branches)
+}
+
+// UpdateMergeQueueEntryCols updates the given columns of a merge queue entry
+func UpdateMergeQueueEntryCols(ctx context.Context, entry *MergeQueueEntry, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(entry.ID).Cols(cols...).Update(entry)
+	return err
+}
+
+// DeleteMergeQueueEntry removes a pull request from the merge queue
+func DeleteMergeQueueEntry(ctx context.Context, pullID int64) error {
+	exist, entry, err := GetMergeQueueEntryByPullID(ctx, pullID)
+	if err != nil {
+		return err
+	} else if !exist {
+		return db.ErrNotExist{Resource: "merge_queue", ID: pullID}
+	}
+
+	_, err = db.GetEngine(ctx).ID(entry.ID).Delete(&MergeQueueEntry{})
+	return err
+}
@@ -262,3 +262,11 @@ func DeleteOrphanedAttachments(ctx context.Context) error {
 		Delete(new(Attachment))
 	return err
 }
+
+// GetOwnerAttachmentsSize returns the size of all attachments of the repositories of the owner
+func GetOwnerAttachmentsSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Join("INNER", "repository", "repository.id = attachment.repo_id").
+		Where("repository.owner_id = ?", ownerID).
+		SumInt(new(Attachment), "attachment.size")
+}
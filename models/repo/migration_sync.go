@@ -0,0 +1,194 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"context"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// ErrMigrationSyncNotExist migration sync does not exist error
+var ErrMigrationSyncNotExist = util.NewNotExistErrorf("Migration sync does not exist")
+
+// MigrationSync represents the periodic synchronization of the milestones, labels, releases,
+// issues, pull requests and comments of a migrated repository with the repository it has been migrated from.
+type MigrationSync struct {
+	ID                    int64              `xorm:"pk autoincr"`
+	RepoID                int64              `xorm:"UNIQUE"`
+	Repo                  *Repository        `xorm:"-"`
+	DoerID                int64              `xorm:"NOT NULL DEFAULT 0"`
+	GitServiceType        api.GitServiceType `xorm:"NOT NULL DEFAULT 0"`
+	CloneAddrEncrypted    string             `xorm:"TEXT"`
+	AuthUsername          string
+	AuthPasswordEncrypted string `xorm:"TEXT"`
+	AuthTokenEncrypted    string `xorm:"TEXT"`
+
+	Milestones   bool `xorm:"NOT NULL DEFAULT false"`
+	Labels       bool `xorm:"NOT NULL DEFAULT false"`
+	Releases     bool `xorm:"NOT NULL DEFAULT false"`
+	Issues       bool `xorm:"NOT NULL DEFAULT false"`
+	PullRequests bool `xorm:"NOT NULL DEFAULT false"`
+	Comments     bool `xorm:"NOT NULL DEFAULT false"`
+
+	Interval time.Duration
+	// the objects updated on the source since LastSyncUnix are pulled by the next synchronization
+	LastSyncUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	NextSyncUnix timeutil.TimeStamp `xorm:"INDEX"`
+	LastError    string             `xorm:"TEXT"`
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+}
+
+// MigrationSyncReference maps an object of a synchronized repository to the identifier of the object on the source,
+// the synchronization uses it to update the objects it has already pulled instead of duplicating them.
+type MigrationSyncReference struct {
+	ID        int64  `xorm:"pk autoincr"`
+	RepoID    int64  `xorm:"UNIQUE(s) INDEX"`
+	Type      string `xorm:"VARCHAR(16) UNIQUE(s)"`
+	ForeignID string `xorm:"VARCHAR(255) UNIQUE(s)"`
+	LocalID   int64  `xorm:"NOT NULL DEFAULT 0"`
+}
+
+// The types of the objects referenced by a MigrationSyncReference, the local id is the id of the issue or of the comment
+const (
+	MigrationSyncReferenceIssue   = "issue"
+	MigrationSyncReferencePull    = "pull"
+	MigrationSyncReferenceComment = "comment"
+)
+
+func init() {
+	db.RegisterModel(new(MigrationSync))
+	db.RegisterModel(new(MigrationSyncReference))
+}
+
+// LoadRepo loads the repository of the synchronization
+func (s *MigrationSync) LoadRepo(ctx context.Context) (err error) {
+	if s.Repo == nil {
+		s.Repo, err = GetRepositoryByID(ctx, s.RepoID)
+	}
+	return err
+}
+
+// SetCredentials encrypts and stores the address and the credentials used to access the source
+func (s *MigrationSync) SetCredentials(cloneAddr, authPassword, authToken string) (err error) {
+	if s.CloneAddrEncrypted, err = secret.EncryptSecret(setting.SecretKey, cloneAddr); err != nil {
+		return err
+	}
+	if s.AuthPasswordEncrypted, err = secret.EncryptSecret(setting.SecretKey, authPassword); err != nil {
+		return err
+	}
+	s.AuthTokenEncrypted, err = secret.EncryptSecret(setting.SecretKey, authToken)
+	return err
+}
+
+// Credentials returns the decrypted address and credentials used to access the source
+func (s *MigrationSync) Credentials() (cloneAddr, authPassword, authToken string, err error) {
+	if cloneAddr, err = secret.DecryptSecret(setting.SecretKey, s.CloneAddrEncrypted); err != nil {
+		return "", "", "", err
+	}
+	if authPassword, err = secret.DecryptSecret(setting.SecretKey, s.AuthPasswordEncrypted); err != nil {
+		return "", "", "", err
+	}
+	if authToken, err = secret.DecryptSecret(setting.SecretKey, s.AuthTokenEncrypted); err != nil {
+		return "", "", "", err
+	}
+	return cloneAddr, authPassword, authToken, nil
+}
+
+// ScheduleNextSync calculates and sets the time of the next synchronization, a zero interval disables the synchronization
+func (s *MigrationSync) ScheduleNextSync() {
+	if s.Interval != 0 {
+		s.NextSyncUnix = timeutil.TimeStampNow().AddDuration(s.Interval)
+	} else {
+		s.NextSyncUnix = 0
+	}
+}
+
+// InsertMigrationSync inserts the synchronization of a repository
+func InsertMigrationSync(ctx context.Context, s *MigrationSync) error {
+	return db.Insert(ctx, s)
+}
+
+// GetMigrationSyncByRepoID returns the synchronization of a repository
+func GetMigrationSyncByRepoID(ctx context.Context, repoID int64) (*MigrationSync, error) {
+	s := &MigrationSync{}
+	has, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Get(s)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrMigrationSyncNotExist
+	}
+	return s, nil
+}
+
+// UpdateMigrationSyncCols updates some columns of the synchronization
+func UpdateMigrationSyncCols(ctx context.Context, s *MigrationSync, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(s.ID).Cols(cols...).Update(s)
+	return err
+}
+
+// DeleteMigrationSyncByRepoID stops the synchronization of a repository, the repository keeps the objects it has pulled
+func DeleteMigrationSyncByRepoID(ctx context.Context, repoID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(new(MigrationSync)); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(new(MigrationSyncReference))
+		return err
+	})
+}
+
+// FindDueMigrationSyncs returns the synchronizations which should run now, at most limit if it is positive
+func FindDueMigrationSyncs(ctx context.Context, limit int) ([]*MigrationSync, error) {
+	syncs := make([]*MigrationSync, 0, 10)
+	sess := db.GetEngine(ctx).
+		Where(builder.Gt{"next_sync_unix": 0}.And(builder.Lte{"next_sync_unix": timeutil.TimeStampNow()})).
+		OrderBy("next_sync_unix ASC")
+	if limit > 0 {
+		sess = sess.Limit(limit)
+	}
+	return syncs, sess.Find(&syncs)
+}
+
+// GetMigrationSyncReference returns the local id of an object pulled from the source, or 0 if it hasn't been pulled yet
+func GetMigrationSyncReference(ctx context.Context, repoID int64, tp, foreignID string) (int64, error) {
+	ref := &MigrationSyncReference{}
+	has, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "type": tp, "foreign_id": foreignID}).Get(ref)
+	if err != nil || !has {
+		return 0, err
+	}
+	return ref.LocalID, nil
+}
+
+// SetMigrationSyncReference maps an object pulled from the source to its local id
+func SetMigrationSyncReference(ctx context.Context, repoID int64, tp, foreignID string, localID int64) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where(builder.Eq{"repo_id": repoID, "type": tp, "foreign_id": foreignID}).Delete(new(MigrationSyncReference)); err != nil {
+			return err
+		}
+		return db.Insert(ctx, &MigrationSyncReference{
+			RepoID:    repoID,
+			Type:      tp,
+			ForeignID: foreignID,
+			LocalID:   localID,
+		})
+	})
+}
+
+// InsertMigrationSyncReferences inserts the references of the objects created by the migration of a repository
+func InsertMigrationSyncReferences(ctx context.Context, refs []*MigrationSyncReference) error {
+	if len(refs) == 0 {
+		return nil
+	}
+	return db.Insert(ctx, refs)
+}
@@ -196,6 +196,27 @@ func SetSettingNoVersion(ctx context.Context, key, value string) error {
 	return SetSetting(ctx, s)
 }
 
+// InsertSettingsIfNotExist inserts the settings in one transaction unless one of them already exists,
+// concurrent callers can't interleave their values as the unique keys make all the transactions but one fail
+func InsertSettingsIfNotExist(ctx context.Context, values map[string]string) error {
+	return db.WithTx(ctx, func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		settings := make([]*Setting, 0, len(values))
+		keys := make([]string, 0, len(values))
+		for key, value := range values {
+			key = strings.ToLower(key)
+			settings = append(settings, &Setting{SettingKey: key, SettingValue: value})
+			keys = append(keys, key)
+		}
+		has, err := e.Where(builder.In("setting_key", keys)).Exist(new(Setting))
+		if err != nil || has {
+			return err
+		}
+		_, err = e.Insert(settings)
+		return err
+	})
+}
+
 // SetSetting updates a users' setting for a specific key
 func SetSetting(ctx context.Context, setting *Setting) error {
 	if err := upsertSettingValue(ctx, strings.ToLower(setting.SettingKey), setting.SettingValue, setting.Version); err != nil {
@@ -7,6 +7,8 @@ package system
 const (
 	KeyPictureDisableGravatar       = "picture.disable_gravatar"
 	KeyPictureEnableFederatedAvatar = "picture.enable_federated_avatar"
+	KeyWebhookSigningPrivateKey     = "webhook.signing_private_key"
+	KeyWebhookSigningPublicKey      = "webhook.signing_public_key"
 )
 
 // genSettingCacheKey returns the cache key for some configuration
@@ -55,3 +55,18 @@ func TestSettings(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, settings, 2)
 }
+
+func TestInsertSettingsIfNotExist(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	keys := []string{"test.first", "test.second"}
+	assert.NoError(t, system.InsertSettingsIfNotExist(db.DefaultContext, map[string]string{keys[0]: "1", keys[1]: "1"}))
+	// the values inserted first are kept
+	assert.NoError(t, system.InsertSettingsIfNotExist(db.DefaultContext, map[string]string{keys[0]: "2", keys[1]: "2"}))
+
+	settings, err := system.GetSettings(db.DefaultContext, keys)
+	assert.NoError(t, err)
+	assert.Len(t, settings, 2)
+	assert.Equal(t, "1", settings[keys[0]].SettingValue)
+	assert.Equal(t, "1", settings[keys[1]].SettingValue)
+}
@@ -0,0 +1,115 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package unittest
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// the headers which change on every request and are not recorded
+var mockIgnoredHeaders = []string{"cf-ray", "server", "date", "report-to", "nel", "x-request-id", "set-cookie"}
+
+// NewMockWebServer returns a server which replays the responses recorded in testDataDir. In live mode the requests
+// are forwarded to liveServerBaseURL first and their responses are recorded, so the fixtures could be refreshed.
+// The mentions of liveServerBaseURL in the responses are replaced by the URL of the mock server.
+func NewMockWebServer(t *testing.T, liveServerBaseURL, testDataDir string, liveMode bool) *httptest.Server {
+	mockServerBaseURL := ""
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		path := NormalizedFullPath(r.URL)
+		log.Info("Mock HTTP Server: got request for path %s", path)
+		fixturePath := filepath.Join(testDataDir, r.Method+"_"+url.PathEscape(path))
+		if liveMode {
+			recordMockResponse(t, liveServerBaseURL+path, fixturePath, r)
+		}
+
+		fixture, err := os.ReadFile(fixturePath)
+		if !assert.NoError(t, err, "missing mock HTTP response: %s", fixturePath) {
+			http.NotFound(w, r)
+			return
+		}
+
+		// the fixture is made of the headers of the response, an empty line and the body
+		headers, body := "", string(fixture)
+		if idx := strings.Index("\n"+body, "\n\n"); idx >= 0 {
+			headers, body = body[:idx], body[idx+1:]
+		}
+		for _, line := range strings.Split(headers, "\n") {
+			if name, value, ok := strings.Cut(line, ": "); ok {
+				w.Header().Add(name, value)
+			}
+		}
+		_, err = w.Write([]byte(strings.ReplaceAll(body, liveServerBaseURL, mockServerBaseURL)))
+		assert.NoError(t, err, "writing the body of the HTTP response failed")
+	}))
+	mockServerBaseURL = server.URL
+	t.Cleanup(server.Close)
+	return server
+}
+
+// recordMockResponse forwards the request to the live server and writes the response to the fixture file
+func recordMockResponse(t *testing.T, liveURL, fixturePath string, r *http.Request) {
+	request, err := http.NewRequestWithContext(r.Context(), r.Method, liveURL, nil)
+	if !assert.NoError(t, err, "constructing an HTTP request to %s failed", liveURL) {
+		return
+	}
+	for name, values := range r.Header {
+		// the encoding is handled by the transport of the client
+		if !strings.EqualFold(name, "Accept-Encoding") {
+			request.Header[name] = values
+		}
+	}
+
+	response, err := http.DefaultClient.Do(request)
+	if !assert.NoError(t, err, "HTTP request to %s failed", liveURL) {
+		return
+	}
+	defer response.Body.Close()
+
+	assert.NoError(t, os.MkdirAll(filepath.Dir(fixturePath), os.ModePerm))
+	fixture, err := os.Create(fixturePath)
+	if !assert.NoError(t, err, "failed to open the fixture file %s for writing", fixturePath) {
+		return
+	}
+	defer fixture.Close()
+
+	writer := bufio.NewWriter(fixture)
+	for name, values := range response.Header {
+		if util.SliceContainsString(mockIgnoredHeaders, name, true) {
+			continue
+		}
+		for _, value := range values {
+			_, err := fmt.Fprintf(writer, "%s: %s\n", name, value)
+			assert.NoError(t, err, "writing the headers of the HTTP response to the fixture file failed")
+		}
+	}
+	_, err = writer.WriteString("\n")
+	assert.NoError(t, err, "writing the headers of the HTTP response to the fixture file failed")
+	_, err = io.Copy(writer, response.Body)
+	assert.NoError(t, err, "writing the body of the HTTP response to the fixture file failed")
+	assert.NoError(t, writer.Flush())
+	log.Info("Mock HTTP Server: recorded the response of %s to %s", liveURL, fixturePath)
+}
+
+// NormalizedFullPath returns the escaped path and the sorted query of the URL, it identifies the fixtures of the requests
+func NormalizedFullPath(u *url.URL) string {
+	if len(u.Query()) == 0 {
+		return u.EscapedPath()
+	}
+	return u.EscapedPath() + "?" + u.Query().Encode()
+}
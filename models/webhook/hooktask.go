@@ -16,6 +16,7 @@ import (
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 
 	gouuid "github.com/google/uuid"
+	"xorm.io/builder"
 )
 
 //   ___ ___                __   ___________              __
@@ -50,6 +51,11 @@ type HookTask struct {
 	IsDelivered    bool
 	Delivered      timeutil.TimeStampNano
 
+	// Retry info.
+	Attempt      int                `xorm:"NOT NULL DEFAULT 1"`
+	RetryUnix    timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`     // the task is not delivered before this time
+	IsDeadLetter bool               `xorm:"INDEX NOT NULL DEFAULT false"` // the delivery failed and will not be retried
+
 	// History info.
 	IsSucceed       bool
 	RequestContent  string        `xorm:"LONGTEXT"`
@@ -124,6 +130,9 @@ func CreateHookTask(ctx context.Context, t *HookTask) (*HookTask, error) {
 	if t.Delivered == 0 {
 		t.Delivered = timeutil.TimeStampNanoNow()
 	}
+	if t.Attempt == 0 {
+		t.Attempt = 1
+	}
 	return t, db.Insert(ctx, t)
 }
 
@@ -171,7 +180,8 @@ func ReplayHookTask(ctx context.Context, hookID int64, uuid string) (*HookTask,
 	})
 }
 
-// FindUndeliveredHookTaskIDs will find the next 100 undelivered hook tasks with ID greater than the provided lowerID
+// FindUndeliveredHookTaskIDs will find the next 100 undelivered hook tasks with ID greater than the provided lowerID,
+// retries which are not due yet are excluded
 func FindUndeliveredHookTaskIDs(ctx context.Context, lowerID int64) ([]int64, error) {
 	const batchSize = 100
 
@@ -180,12 +190,76 @@ func FindUndeliveredHookTaskIDs(ctx context.Context, lowerID int64) ([]int64, er
 		Select("id").
 		Table(new(HookTask)).
 		Where("is_delivered=?", false).
+		And("retry_unix <= ?", timeutil.TimeStampNow()).
 		And("id > ?", lowerID).
 		Asc("id").
 		Limit(batchSize).
 		Find(&tasks)
 }
 
+// FindDueRetryHookTaskIDs will find the undelivered retries which became due after the provided time
+func FindDueRetryHookTaskIDs(ctx context.Context, after, now timeutil.TimeStamp) ([]int64, error) {
+	tasks := make([]int64, 0, 10)
+	return tasks, db.GetEngine(ctx).
+		Select("id").
+		Table(new(HookTask)).
+		Where("is_delivered=?", false).
+		And("retry_unix > ? AND retry_unix <= ?", after, now).
+		Asc("id").
+		Find(&tasks)
+}
+
+// FailedHookTasks returns the deliveries of a webhook which failed and will not be retried
+func FailedHookTasks(ctx context.Context, hookID int64, page int) ([]*HookTask, error) {
+	tasks := make([]*HookTask, 0, setting.Webhook.PagingNum)
+	return tasks, db.GetEngine(ctx).
+		Limit(setting.Webhook.PagingNum, (page-1)*setting.Webhook.PagingNum).
+		Where("hook_id=? AND is_dead_letter=?", hookID, true).
+		Desc("id").
+		Find(&tasks)
+}
+
+// CountFailedHookTasks returns the number of deliveries of a webhook which failed and will not be retried
+func CountFailedHookTasks(ctx context.Context, hookID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("hook_id=? AND is_dead_letter=?", hookID, true).Count(new(HookTask))
+}
+
+// RedeliverFailedHookTasks copies the failed deliveries of a webhook to get re-delivered and removes the
+// originals from the failed deliveries. If uuids is empty, all failed deliveries are copied.
+func RedeliverFailedHookTasks(ctx context.Context, hookID int64, uuids []string) ([]*HookTask, error) {
+	ctx, committer, err := db.TxContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	cond := builder.Eq{"hook_id": hookID, "is_dead_letter": true}
+	if len(uuids) > 0 {
+		cond["uuid"] = uuids
+	}
+	failed := make([]*HookTask, 0, 10)
+	if err := db.GetEngine(ctx).Where(cond).Asc("id").Find(&failed); err != nil {
+		return nil, err
+	}
+
+	tasks := make([]*HookTask, 0, len(failed))
+	for _, t := range failed {
+		if _, err := db.GetEngine(ctx).ID(t.ID).Cols("is_dead_letter").Update(&HookTask{IsDeadLetter: false}); err != nil {
+			return nil, err
+		}
+		task, err := CreateHookTask(ctx, &HookTask{
+			HookID:         t.HookID,
+			PayloadContent: t.PayloadContent,
+			EventType:      t.EventType,
+		})
+		if err != nil {
+			return nil, err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, committer.Commit()
+}
+
 func MarkTaskDelivered(ctx context.Context, task *HookTask) (bool, error) {
 	count, err := db.GetEngine(ctx).ID(task.ID).Where("is_delivered = ?", false).Cols("is_delivered").Update(&HookTask{
 		ID:          task.ID,
@@ -128,12 +128,15 @@ type Webhook struct {
 	HTTPMethod                string `xorm:"http_method"`
 	ContentType               HookContentType
 	Secret                    string `xorm:"TEXT"`
+	PreviousSecrets           string `xorm:"TEXT"`                   // rotated secrets still signing deliveries, use ActivePreviousSecrets()
+	HTTPSignature             bool   `xorm:"NOT NULL DEFAULT false"` // sign deliveries with the instance HTTP signature key
 	Events                    string `xorm:"TEXT"`
 	*webhook_module.HookEvent `xorm:"-"`
 	IsActive                  bool                      `xorm:"INDEX"`
 	Type                      webhook_module.HookType   `xorm:"VARCHAR(16) 'type'"`
 	Meta                      string                    `xorm:"TEXT"` // store hook-specific attributes
 	LastStatus                webhook_module.HookStatus // Last delivery status
+	FailureCount              int                       `xorm:"NOT NULL DEFAULT 0"` // Number of consecutive failed deliveries
 
 	// HeaderAuthorizationEncrypted should be accessed using HeaderAuthorization() and SetHeaderAuthorization()
 	HeaderAuthorizationEncrypted string `xorm:"TEXT"`
@@ -482,6 +485,30 @@ func UpdateWebhookLastStatus(w *Webhook) error {
 	return err
 }
 
+// ResetWebhookFailureCount resets the number of consecutive failed deliveries of a webhook
+func ResetWebhookFailureCount(ctx context.Context, w *Webhook) error {
+	w.FailureCount = 0
+	_, err := db.GetEngine(ctx).ID(w.ID).Where("failure_count <> 0").Cols("failure_count").NoAutoTime().Update(w)
+	return err
+}
+
+// IncreaseWebhookFailureCount increases the number of consecutive failed deliveries of a webhook
+// and loads the updated count
+func IncreaseWebhookFailureCount(ctx context.Context, w *Webhook) error {
+	if _, err := db.GetEngine(ctx).ID(w.ID).Incr("failure_count").NoAutoTime().Update(new(Webhook)); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).Table("webhook").Select("failure_count").Where("id = ?", w.ID).Get(&w.FailureCount)
+	return err
+}
+
+// DeactivateWebhook deactivates a webhook
+func DeactivateWebhook(ctx context.Context, w *Webhook) error {
+	w.IsActive = false
+	_, err := db.GetEngine(ctx).ID(w.ID).Cols("is_active").Update(w)
+	return err
+}
+
 // deleteWebhook uses argument bean as query condition,
 // ID must be specified and do not assign unnecessary fields.
 func deleteWebhook(bean *Webhook) (err error) {
@@ -0,0 +1,90 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MaxSecretRotationHours is the longest a replaced secret can keep signing the deliveries of a webhook
+const MaxSecretRotationHours = 720
+
+// PreviousSecret is a secret which has been replaced but keeps signing the deliveries of a webhook
+// until it expires, so that receivers can be updated to the new secret without rejecting deliveries
+type PreviousSecret struct {
+	Secret      string             `json:"secret"`
+	ExpiresUnix timeutil.TimeStamp `json:"expires_unix"`
+}
+
+// ActivePreviousSecrets returns the previous secrets of the webhook which did not expire yet
+func (w *Webhook) ActivePreviousSecrets() []*PreviousSecret {
+	if w.PreviousSecrets == "" {
+		return nil
+	}
+
+	var secrets []*PreviousSecret
+	if err := json.Unmarshal([]byte(w.PreviousSecrets), &secrets); err != nil {
+		log.Error("Unmarshal PreviousSecrets[%d]: %v", w.ID, err)
+		return nil
+	}
+
+	now := timeutil.TimeStampNow()
+	active := make([]*PreviousSecret, 0, len(secrets))
+	for _, s := range secrets {
+		if s.ExpiresUnix > now {
+			active = append(active, s)
+		}
+	}
+	return active
+}
+
+// SigningSecrets returns the secret and the active previous secrets of the webhook
+func (w *Webhook) SigningSecrets() []string {
+	var secrets []string
+	if w.Secret != "" {
+		secrets = append(secrets, w.Secret)
+	}
+	for _, s := range w.ActivePreviousSecrets() {
+		if s.Secret != w.Secret {
+			secrets = append(secrets, s.Secret)
+		}
+	}
+	return secrets
+}
+
+// RotateSecret replaces the secret of the webhook, the replaced secret keeps signing the deliveries
+// for the given period. Expired previous secrets are dropped.
+func (w *Webhook) RotateSecret(secret string, keep time.Duration) error {
+	secrets := w.ActivePreviousSecrets()
+	if w.Secret != "" && w.Secret != secret && keep > 0 {
+		secrets = append(secrets, &PreviousSecret{
+			Secret:      w.Secret,
+			ExpiresUnix: timeutil.TimeStamp(time.Now().Add(keep).Unix()),
+		})
+	}
+	w.Secret = secret
+	return w.setPreviousSecrets(secrets)
+}
+
+// RevokePreviousSecrets stops signing the deliveries of the webhook with its previous secrets
+func (w *Webhook) RevokePreviousSecrets() {
+	w.PreviousSecrets = ""
+}
+
+func (w *Webhook) setPreviousSecrets(secrets []*PreviousSecret) error {
+	if len(secrets) == 0 {
+		w.PreviousSecrets = ""
+		return nil
+	}
+	data, err := json.Marshal(secrets)
+	if err != nil {
+		return err
+	}
+	w.PreviousSecrets = string(data)
+	return nil
+}
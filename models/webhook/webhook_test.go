@@ -328,3 +328,27 @@ func TestCleanupHookTaskTable_OlderThan_LeavesTaskEarlierThanAgeToDelete(t *test
 	assert.NoError(t, CleanupHookTaskTable(context.Background(), OlderThan, 168*time.Hour, 0))
 	unittest.AssertExistsAndLoadBean(t, hookTask)
 }
+
+func TestWebhook_RotateSecret(t *testing.T) {
+	w := &Webhook{Secret: "first"}
+	assert.Equal(t, []string{"first"}, w.SigningSecrets())
+
+	assert.NoError(t, w.RotateSecret("second", time.Hour))
+	assert.Equal(t, "second", w.Secret)
+	assert.Equal(t, []string{"second", "first"}, w.SigningSecrets())
+
+	// an unchanged secret is not kept twice
+	assert.NoError(t, w.RotateSecret("second", time.Hour))
+	assert.Len(t, w.ActivePreviousSecrets(), 1)
+
+	assert.NoError(t, w.RotateSecret("third", 0))
+	assert.Equal(t, []string{"third", "first"}, w.SigningSecrets())
+
+	// expired secrets stop signing
+	assert.NoError(t, w.setPreviousSecrets([]*PreviousSecret{{Secret: "expired", ExpiresUnix: timeutil.TimeStampNow() - 1}}))
+	assert.Equal(t, []string{"third"}, w.SigningSecrets())
+
+	assert.NoError(t, w.RotateSecret("fourth", time.Hour))
+	w.RevokePreviousSecrets()
+	assert.Equal(t, []string{"fourth"}, w.SigningSecrets())
+}
@@ -0,0 +1,103 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/nektos/act/pkg/exprparser"
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// Concurrency represents the `concurrency` of a workflow or a job,
+// both the group and cancel-in-progress may contain expressions
+type Concurrency struct {
+	Group            string `yaml:"group"`
+	CancelInProgress string `yaml:"cancel-in-progress"`
+}
+
+// UnmarshalYAML supports both the short syntax `concurrency: <group>` and the mapping syntax
+func (c *Concurrency) UnmarshalYAML(node *yaml.Node) error {
+	if node.Kind == yaml.ScalarNode {
+		c.Group = node.Value
+		return nil
+	}
+
+	type concurrency Concurrency
+	return node.Decode((*concurrency)(c))
+}
+
+// GetConcurrencyFromContent returns the workflow level concurrency and the job level ones keyed by the job id,
+// the workflow level one is nil if it's not set
+func GetConcurrencyFromContent(content []byte) (*Concurrency, map[string]*Concurrency, error) {
+	var workflow struct {
+		Concurrency *Concurrency `yaml:"concurrency"`
+		Jobs        map[string]struct {
+			Concurrency *Concurrency `yaml:"concurrency"`
+		} `yaml:"jobs"`
+	}
+	if err := yaml.Unmarshal(content, &workflow); err != nil {
+		return nil, nil, err
+	}
+
+	jobs := make(map[string]*Concurrency, len(workflow.Jobs))
+	for id, job := range workflow.Jobs {
+		if job.Concurrency != nil && job.Concurrency.Group != "" {
+			jobs[id] = job.Concurrency
+		}
+	}
+	if workflow.Concurrency != nil && workflow.Concurrency.Group == "" {
+		workflow.Concurrency = nil
+	}
+	return workflow.Concurrency, jobs, nil
+}
+
+// Evaluate evaluates the expressions of the concurrency, only the github, inputs and matrix contexts are available
+func (c *Concurrency) Evaluate(gitCtx *model.GithubContext, inputs, matrix map[string]interface{}) (group string, cancelInProgress bool, err error) {
+	interpreter := exprparser.NewInterpeter(&exprparser.EvaluationEnvironment{
+		Github: gitCtx,
+		Inputs: inputs,
+		Matrix: matrix,
+	}, exprparser.Config{
+		Run: &model.Run{
+			Workflow: &model.Workflow{Jobs: map[string]*model.Job{}},
+		},
+		Context: "job",
+	})
+
+	defer func() {
+		// the evaluator panics if an expression doesn't result in a string
+		if r := recover(); r != nil {
+			err = fmt.Errorf("evaluate concurrency group %q: %v", c.Group, r)
+		}
+	}()
+
+	group = jobparser.NewExpressionEvaluator(interpreter).Interpolate(c.Group)
+	if group == "" {
+		return "", false, fmt.Errorf("concurrency group %q is evaluated to an empty string", c.Group)
+	}
+
+	cancel := strings.TrimSpace(c.CancelInProgress)
+	switch {
+	case cancel == "":
+		return group, false, nil
+	case strings.HasPrefix(cancel, "${{") && strings.HasSuffix(cancel, "}}"):
+		expr := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(cancel, "${{"), "}}"))
+		result, err := interpreter.Evaluate(expr, exprparser.DefaultStatusCheckNone)
+		if err != nil {
+			return "", false, fmt.Errorf("evaluate cancel-in-progress %q: %w", c.CancelInProgress, err)
+		}
+		return group, exprparser.IsTruthy(result), nil
+	default:
+		cancelInProgress, err := strconv.ParseBool(cancel)
+		if err != nil {
+			return "", false, fmt.Errorf("invalid cancel-in-progress %q: %w", c.CancelInProgress, err)
+		}
+		return group, cancelInProgress, nil
+	}
+}
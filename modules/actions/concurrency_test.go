@@ -0,0 +1,109 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/nektos/act/pkg/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConcurrencyFromContent(t *testing.T) {
+	workflow, jobs, err := GetConcurrencyFromContent([]byte(`
+on: push
+concurrency:
+  group: ${{ github.workflow }}-${{ github.ref }}
+  cancel-in-progress: ${{ github.ref != 'refs/heads/main' }}
+jobs:
+  deploy:
+    concurrency: deploy-${{ matrix.env }}
+    runs-on: ubuntu-latest
+  release:
+    concurrency:
+      group: release
+      cancel-in-progress: true
+    runs-on: ubuntu-latest
+  test:
+    runs-on: ubuntu-latest
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, &Concurrency{
+		Group:            "${{ github.workflow }}-${{ github.ref }}",
+		CancelInProgress: "${{ github.ref != 'refs/heads/main' }}",
+	}, workflow)
+	assert.Equal(t, map[string]*Concurrency{
+		"deploy":  {Group: "deploy-${{ matrix.env }}"},
+		"release": {Group: "release", CancelInProgress: "true"},
+	}, jobs)
+
+	workflow, jobs, err = GetConcurrencyFromContent([]byte(`
+on: push
+jobs:
+  test:
+    runs-on: ubuntu-latest
+`))
+	assert.NoError(t, err)
+	assert.Nil(t, workflow)
+	assert.Empty(t, jobs)
+}
+
+func TestConcurrency_Evaluate(t *testing.T) {
+	gitCtx := &model.GithubContext{
+		Workflow: "deploy.yml",
+		Ref:      "refs/heads/dev",
+		Event:    map[string]interface{}{"number": 3},
+	}
+
+	tests := []struct {
+		name        string
+		concurrency *Concurrency
+		inputs      map[string]interface{}
+		matrix      map[string]interface{}
+		wantGroup   string
+		wantCancel  bool
+		wantErr     bool
+	}{
+		{
+			name:        "github context",
+			concurrency: &Concurrency{Group: "${{ github.workflow }}-${{ github.ref }}", CancelInProgress: "${{ github.ref != 'refs/heads/main' }}"},
+			wantGroup:   "deploy.yml-refs/heads/dev",
+			wantCancel:  true,
+		},
+		{
+			name:        "event payload",
+			concurrency: &Concurrency{Group: "pr-${{ github.event.number }}", CancelInProgress: "false"},
+			wantGroup:   "pr-3",
+		},
+		{
+			name:        "inputs and matrix",
+			concurrency: &Concurrency{Group: "${{ inputs.target }}-${{ matrix.env }}"},
+			inputs:      map[string]interface{}{"target": "prod"},
+			matrix:      map[string]interface{}{"env": "eu"},
+			wantGroup:   "prod-eu",
+		},
+		{
+			name:        "empty group",
+			concurrency: &Concurrency{Group: "${{ github.head_ref }}"},
+			wantErr:     true,
+		},
+		{
+			name:        "invalid cancel-in-progress",
+			concurrency: &Concurrency{Group: "group", CancelInProgress: "yes"},
+			wantErr:     true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			group, cancel, err := tt.concurrency.Evaluate(gitCtx, tt.inputs, tt.matrix)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tt.wantGroup, group)
+			assert.Equal(t, tt.wantCancel, cancel)
+		})
+	}
+}
@@ -22,6 +22,7 @@ const (
 	githubEventRelease                  = "release"
 	githubEventPullRequestComment       = "pull_request_comment"
 	githubEventGollum                   = "gollum"
+	githubEventWorkflowDispatch         = "workflow_dispatch"
 )
 
 // canGithubEventMatch check if the input Github event can match any Gitea event.
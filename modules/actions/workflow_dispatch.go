@@ -0,0 +1,157 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"bytes"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/nektos/act/pkg/model"
+	"gopkg.in/yaml.v3"
+)
+
+// WorkflowDispatchInput represents an input of a workflow which can be triggered manually,
+// see https://docs.github.com/en/actions/using-workflows/workflow-syntax-for-github-actions#onworkflow_dispatchinputs
+type WorkflowDispatchInput struct {
+	Name        string   `yaml:"-"`
+	Description string   `yaml:"description"`
+	Required    bool     `yaml:"required"`
+	Default     string   `yaml:"default"`
+	Type        string   `yaml:"type"`
+	Options     []string `yaml:"options"`
+}
+
+// WorkflowDispatch represents the `workflow_dispatch` trigger of a workflow
+type WorkflowDispatch struct {
+	// Inputs are kept in the order of their declaration
+	Inputs []*WorkflowDispatchInput
+}
+
+// withoutWorkflowDispatchConfig returns the `on` node without the configuration of `workflow_dispatch`,
+// jobparser doesn't understand the nested inputs and they don't filter the event anyway
+func withoutWorkflowDispatchConfig(rawOn *yaml.Node) *yaml.Node {
+	if rawOn.Kind != yaml.MappingNode {
+		return rawOn
+	}
+
+	node := *rawOn
+	node.Content = make([]*yaml.Node, len(rawOn.Content))
+	copy(node.Content, rawOn.Content)
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == githubEventWorkflowDispatch {
+			node.Content[i+1] = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null"}
+		}
+	}
+	return &node
+}
+
+// GetWorkflowDispatchFromContent returns the `workflow_dispatch` trigger of the workflow,
+// or nil if the workflow can't be triggered manually
+func GetWorkflowDispatchFromContent(content []byte) (*WorkflowDispatch, error) {
+	workflow, err := model.ReadWorkflow(bytes.NewReader(content))
+	if err != nil {
+		return nil, err
+	}
+
+	switch workflow.RawOn.Kind {
+	case yaml.ScalarNode:
+		if workflow.RawOn.Value == githubEventWorkflowDispatch {
+			return &WorkflowDispatch{}, nil
+		}
+	case yaml.SequenceNode:
+		for _, node := range workflow.RawOn.Content {
+			if node.Value == githubEventWorkflowDispatch {
+				return &WorkflowDispatch{}, nil
+			}
+		}
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(workflow.RawOn.Content); i += 2 {
+			if workflow.RawOn.Content[i].Value == githubEventWorkflowDispatch {
+				return parseWorkflowDispatch(workflow.RawOn.Content[i+1])
+			}
+		}
+	}
+	return nil, nil
+}
+
+func parseWorkflowDispatch(node *yaml.Node) (*WorkflowDispatch, error) {
+	dispatch := &WorkflowDispatch{}
+	if node.Kind != yaml.MappingNode {
+		return dispatch, nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value != "inputs" || node.Content[i+1].Kind != yaml.MappingNode {
+			continue
+		}
+		inputs := node.Content[i+1].Content
+		for j := 0; j+1 < len(inputs); j += 2 {
+			input := &WorkflowDispatchInput{}
+			if err := inputs[j+1].Decode(input); err != nil {
+				return nil, err
+			}
+			input.Name = inputs[j].Value
+			if input.Type == "" {
+				input.Type = "string"
+			}
+			dispatch.Inputs = append(dispatch.Inputs, input)
+		}
+	}
+	return dispatch, nil
+}
+
+// ValidateInputs checks the given values against the declared inputs and returns them completed by the default values
+func (wd *WorkflowDispatch) ValidateInputs(values map[string]string) (map[string]string, error) {
+	declared := make(map[string]*WorkflowDispatchInput, len(wd.Inputs))
+	for _, input := range wd.Inputs {
+		declared[input.Name] = input
+	}
+	for name := range values {
+		if _, ok := declared[name]; !ok {
+			return nil, util.NewInvalidArgumentErrorf("unexpected input %q", name)
+		}
+	}
+
+	inputs := make(map[string]string, len(wd.Inputs))
+	for _, input := range wd.Inputs {
+		value, ok := values[input.Name]
+		if !ok || value == "" {
+			if input.Required && input.Default == "" && input.Type != "boolean" {
+				return nil, util.NewInvalidArgumentErrorf("input %q is required", input.Name)
+			}
+			value = input.Default
+		}
+
+		switch input.Type {
+		case "boolean":
+			if value == "" {
+				value = "false"
+			}
+			b, err := strconv.ParseBool(value)
+			if err != nil {
+				return nil, util.NewInvalidArgumentErrorf("input %q must be a boolean", input.Name)
+			}
+			// the runner only treats the exact "true" as true
+			value = strconv.FormatBool(b)
+		case "number":
+			if value == "" {
+				break
+			}
+			if _, err := strconv.ParseFloat(value, 64); err != nil {
+				return nil, util.NewInvalidArgumentErrorf("input %q must be a number", input.Name)
+			}
+		case "choice":
+			if value == "" {
+				break
+			}
+			if !util.SliceContainsString(input.Options, value) {
+				return nil, util.NewInvalidArgumentErrorf("input %q must be one of %v", input.Name, input.Options)
+			}
+		}
+		inputs[input.Name] = value
+	}
+	return inputs, nil
+}
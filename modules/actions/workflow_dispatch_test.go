@@ -0,0 +1,75 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetWorkflowDispatchFromContent(t *testing.T) {
+	dispatch, err := GetWorkflowDispatchFromContent([]byte("on: push"))
+	assert.NoError(t, err)
+	assert.Nil(t, dispatch)
+
+	dispatch, err = GetWorkflowDispatchFromContent([]byte("on: [push, workflow_dispatch]"))
+	assert.NoError(t, err)
+	assert.NotNil(t, dispatch)
+	assert.Empty(t, dispatch.Inputs)
+
+	content := []byte(`on:
+  push:
+    branches: [main]
+  workflow_dispatch:
+    inputs:
+      environment:
+        description: Target environment
+        type: choice
+        required: true
+        options: [staging, production]
+      debug:
+        type: boolean
+        default: true
+      retries:
+        type: number
+      message:
+        default: hello
+`)
+	dispatch, err = GetWorkflowDispatchFromContent(content)
+	assert.NoError(t, err)
+	if assert.Len(t, dispatch.Inputs, 4) {
+		assert.Equal(t, "environment", dispatch.Inputs[0].Name)
+		assert.Equal(t, "choice", dispatch.Inputs[0].Type)
+		assert.True(t, dispatch.Inputs[0].Required)
+		assert.Equal(t, []string{"staging", "production"}, dispatch.Inputs[0].Options)
+		assert.Equal(t, "debug", dispatch.Inputs[1].Name)
+		assert.Equal(t, "true", dispatch.Inputs[1].Default)
+		assert.Equal(t, "retries", dispatch.Inputs[2].Name)
+		assert.Equal(t, "message", dispatch.Inputs[3].Name)
+		assert.Equal(t, "string", dispatch.Inputs[3].Type)
+	}
+
+	// the inputs must not prevent the workflow from being triggered by other events
+	events, err := GetEventsFromContent(content)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	inputs, err := dispatch.ValidateInputs(map[string]string{"environment": "staging", "debug": "0"})
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{"environment": "staging", "debug": "false", "retries": "", "message": "hello"}, inputs)
+
+	for _, values := range []map[string]string{
+		{},
+		{"environment": "qa"},
+		{"environment": "staging", "debug": "maybe"},
+		{"environment": "staging", "retries": "many"},
+		{"environment": "staging", "unknown": "value"},
+	} {
+		_, err := dispatch.ValidateInputs(values)
+		assert.ErrorIs(t, err, util.ErrInvalidArgument, "%v", values)
+	}
+}
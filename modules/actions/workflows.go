@@ -81,7 +81,7 @@ func GetEventsFromContent(content []byte) ([]*jobparser.Event, error) {
 	if err != nil {
 		return nil, err
 	}
-	events, err := jobparser.ParseRawOn(&workflow.RawOn)
+	events, err := jobparser.ParseRawOn(withoutWorkflowDispatchConfig(&workflow.RawOn))
 	if err != nil {
 		return nil, err
 	}
@@ -117,6 +117,49 @@ func DetectWorkflows(commit *git.Commit, triggedEvent webhook_module.HookEventTy
 	return workflows, nil
 }
 
+// ScheduledWorkflow is a workflow which is triggered by the cron expressions of its schedule event
+type ScheduledWorkflow struct {
+	Content []byte
+	Specs   []string
+}
+
+// DetectScheduledWorkflows returns the workflows of the commit which have a schedule trigger, keyed by the workflow file name
+func DetectScheduledWorkflows(commit *git.Commit) (map[string]*ScheduledWorkflow, error) {
+	entries, err := ListWorkflows(commit)
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make(map[string]*ScheduledWorkflow, len(entries))
+	for _, entry := range entries {
+		content, err := GetContentFromEntry(entry)
+		if err != nil {
+			return nil, err
+		}
+		events, err := GetEventsFromContent(content)
+		if err != nil {
+			log.Warn("ignore invalid workflow %q: %v", entry.Name(), err)
+			continue
+		}
+		var specs []string
+		for _, evt := range events {
+			if !evt.IsSchedule() {
+				continue
+			}
+			for _, schedule := range evt.Schedules() {
+				if spec := schedule["cron"]; spec != "" {
+					specs = append(specs, spec)
+				}
+			}
+		}
+		if len(specs) > 0 {
+			workflows[entry.Name()] = &ScheduledWorkflow{Content: content, Specs: specs}
+		}
+	}
+
+	return workflows, nil
+}
+
 func detectMatched(commit *git.Commit, triggedEvent webhook_module.HookEventType, payload api.Payloader, evt *jobparser.Event) bool {
 	if !canGithubEventMatch(evt.Name, triggedEvent) {
 		return false
@@ -326,13 +326,21 @@ func (b *BleveIndexer) Delete(repoID int64) error {
 
 // Search searches for files in the specified repo.
 // Returns the matching file-paths
-func (b *BleveIndexer) Search(ctx context.Context, repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+func (b *BleveIndexer) Search(ctx context.Context, opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
 	var (
 		indexerQuery query.Query
 		keywordQuery query.Query
+
+		repoIDs  = opts.RepoIDs
+		language = opts.Language
+		keyword  = opts.Keyword
 	)
 
-	if isMatch {
+	if opts.Mode != SearchModeFuzzy && opts.Mode != SearchModeMatch {
+		return 0, nil, nil, errUnsupportedSearchMode(opts.Mode)
+	}
+
+	if opts.Mode == SearchModeMatch {
 		prefixQuery := bleve.NewPrefixQuery(keyword)
 		prefixQuery.FieldVal = "Content"
 		keywordQuery = prefixQuery
@@ -370,8 +378,8 @@ func (b *BleveIndexer) Search(ctx context.Context, repoIDs []int64, language, ke
 		)
 	}
 
-	from := (page - 1) * pageSize
-	searchRequest := bleve.NewSearchRequestOptions(indexerQuery, pageSize, from, false)
+	from := (opts.Page - 1) * opts.PageSize
+	searchRequest := bleve.NewSearchRequestOptions(indexerQuery, opts.PageSize, from, false)
 	searchRequest.Fields = []string{"Content", "RepoID", "Language", "CommitID", "UpdatedAt"}
 	searchRequest.IncludeLocations = true
 
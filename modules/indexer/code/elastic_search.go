@@ -383,9 +383,21 @@ func extractAggs(searchResult *elastic.SearchResult) []*SearchResultLanguages {
 }
 
 // Search searches for codes and language stats by given conditions.
-func (b *ElasticSearchIndexer) Search(ctx context.Context, repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+func (b *ElasticSearchIndexer) Search(ctx context.Context, opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	if opts.Mode != SearchModeFuzzy && opts.Mode != SearchModeMatch {
+		return 0, nil, nil, errUnsupportedSearchMode(opts.Mode)
+	}
+
+	var (
+		repoIDs  = opts.RepoIDs
+		language = opts.Language
+		keyword  = opts.Keyword
+		page     = opts.Page
+		pageSize = opts.PageSize
+	)
+
 	searchType := esMultiMatchTypeBestFields
-	if isMatch {
+	if opts.Mode == SearchModeMatch {
 		searchType = esMultiMatchTypePhrasePrefix
 	}
 
@@ -42,15 +42,60 @@ type SearchResultLanguages struct {
 	Count    int
 }
 
+// SearchMode defines how the keyword of a code search is matched
+type SearchMode string
+
+const (
+	// SearchModeFuzzy includes the results which match the keyword closely
+	SearchModeFuzzy SearchMode = ""
+	// SearchModeMatch includes only the results which match the exact keyword
+	SearchModeMatch SearchMode = "match"
+	// SearchModeRegexp includes the results which match the keyword as a regular expression
+	SearchModeRegexp SearchMode = "regexp"
+	// SearchModeSymbol includes the files which define a symbol (function, type, ...) named like the keyword
+	SearchModeSymbol SearchMode = "symbol"
+)
+
+// ParseSearchMode returns the search mode of a query type, unknown types are fuzzy searches
+func ParseSearchMode(queryType string) SearchMode {
+	switch mode := SearchMode(queryType); mode {
+	case SearchModeMatch, SearchModeRegexp, SearchModeSymbol:
+		return mode
+	default:
+		return SearchModeFuzzy
+	}
+}
+
+// SearchOptions represents the options of a code search
+type SearchOptions struct {
+	RepoIDs       []int64
+	Keyword       string
+	Language      string
+	Mode          SearchMode
+	CaseSensitive bool
+	Page          int
+	PageSize      int
+}
+
 // Indexer defines an interface to index and search code contents
 type Indexer interface {
 	Ping() bool
 	Index(ctx context.Context, repo *repo_model.Repository, sha string, changes *repoChanges) error
 	Delete(repoID int64) error
-	Search(ctx context.Context, repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error)
+	Search(ctx context.Context, opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error)
 	Close()
 }
 
+// IsAdvancedSearchSupported returns whether the configured indexer supports regexp, symbol and case-sensitive searches
+func IsAdvancedSearchSupported() bool {
+	return setting.Indexer.RepoType == "trigram"
+}
+
+// errUnsupportedSearchMode is returned by the indexers which can't search with the mode of the options
+func errUnsupportedSearchMode(mode SearchMode) error {
+	return util.NewInvalidArgumentErrorf("%s search is not supported by the %s code indexer", mode, setting.Indexer.RepoType)
+}
+
 func filenameIndexerID(repoID int64, filename string) string {
 	return indexerID(repoID) + "_" + filename
 }
@@ -162,7 +207,7 @@ func Init() {
 
 	// Create the Queue
 	switch setting.Indexer.RepoType {
-	case "bleve", "elasticsearch":
+	case "bleve", "elasticsearch", "trigram":
 		handler := func(items ...*IndexerData) (unhandled []*IndexerData) {
 			idx, err := indexer.get()
 			if idx == nil || err != nil {
@@ -252,6 +297,16 @@ func Init() {
 				close(waitChannel)
 				log.Fatal("PID: %d Unable to initialize the elasticsearch Repository Indexer connstr: %s Error: %v", os.Getpid(), setting.Indexer.RepoConnStr, err)
 			}
+		case "trigram":
+			log.Info("PID: %d Initializing Repository Indexer at: %s", os.Getpid(), setting.Indexer.RepoPath)
+
+			rIndexer, populate, err = NewTrigramIndexer(setting.Indexer.RepoPath)
+			if err != nil {
+				cancel()
+				indexer.Close()
+				close(waitChannel)
+				log.Fatal("PID: %d Unable to initialize the trigram Repository Indexer at path: %s Error: %v", os.Getpid(), setting.Indexer.RepoPath, err)
+			}
 		default:
 			log.Fatal("PID: %d Unknown Indexer type: %s", os.Getpid(), setting.Indexer.RepoType)
 		}
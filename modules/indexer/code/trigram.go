@@ -0,0 +1,817 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package code
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/analyze"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/typesniffer"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/ethantkoenig/rupture"
+	"github.com/go-enry/go-enry/v2"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	trigramIndexerLatestVersion = 3
+	trigramShardExt             = ".shard"
+	trigramContentExt           = ".content"
+	// trigramMaxShards is the number of shards of a repository above which its shards are merged into a single one
+	trigramMaxShards = 8
+	// trigramCachedRepos is the maximum number of repositories whose shards are kept in memory
+	trigramCachedRepos = 1024
+	// trigramMaxCachedSize is the size of the shard files above which the least recently used repositories are
+	// evicted from the memory
+	trigramMaxCachedSize = 256 << 20
+)
+
+// trigram is made of 3 consecutive bytes of a lowercased text
+type trigram uint32
+
+// trigramsOf returns the distinct trigrams of a text
+func trigramsOf(text string) []trigram {
+	text = strings.ToLower(text)
+	if len(text) < 3 {
+		return nil
+	}
+	seen := make(map[trigram]struct{}, len(text))
+	trigrams := make([]trigram, 0, len(text))
+	for i := 0; i+3 <= len(text); i++ {
+		tg := trigram(text[i])<<16 | trigram(text[i+1])<<8 | trigram(text[i+2])
+		if _, ok := seen[tg]; ok {
+			continue
+		}
+		seen[tg] = struct{}{}
+		trigrams = append(trigrams, tg)
+	}
+	return trigrams
+}
+
+// trigramDocument is an indexed file of a repository
+type trigramDocument struct {
+	Filename    string
+	Language    string
+	Symbols     []trigramSymbol
+	UpdatedUnix timeutil.TimeStamp
+	// Offset and Size locate the content of the file in the content file of its shard
+	Offset int64
+	Size   int64
+
+	content string // only set while the shard is built, the contents are read from the disk when needed
+}
+
+// trigramShard indexes some files of the default branch of a repository, it is stored in its own file and the
+// contents of its files in a content file next to it, so that only the postings of the shards are kept in memory.
+// A shard is never modified once it has been written: a push adds a shard holding the files it changed,
+// which hides the older versions of these files, and the shards are merged once there are too many of them.
+type trigramShard struct {
+	Version  int
+	RepoID   int64
+	Seq      int    // the shards of a repository are numbered in the order they have been written
+	CommitID string // the commit the repository was indexed at when the shard was written
+	// Full is set if the shard holds all the indexed files of the repository, the older shards are obsolete
+	Full      bool
+	Documents []*trigramDocument
+	// Hidden lists the files of the older shards which have been changed or removed
+	Hidden []string
+	// Postings lists the (sorted) indexes of the documents which contain each trigram,
+	// they narrow down the documents to match against a search.
+	Postings map[trigram][]uint32
+
+	size int64 // the size of the shard file, which estimates the memory used by the shard
+}
+
+func newTrigramShard(repoID int64, seq int, commitID string, full bool, documents map[string]*trigramDocument, hidden []string) *trigramShard {
+	shard := &trigramShard{
+		Version:   trigramIndexerLatestVersion,
+		RepoID:    repoID,
+		Seq:       seq,
+		CommitID:  commitID,
+		Full:      full,
+		Documents: make([]*trigramDocument, 0, len(documents)),
+		Hidden:    hidden,
+		Postings:  make(map[trigram][]uint32),
+	}
+	for _, doc := range documents {
+		shard.Documents = append(shard.Documents, doc)
+	}
+	sort.Slice(shard.Documents, func(i, j int) bool {
+		return shard.Documents[i].Filename < shard.Documents[j].Filename
+	})
+	var offset int64
+	for i, doc := range shard.Documents {
+		doc.Offset, doc.Size = offset, int64(len(doc.content))
+		offset += doc.Size
+		for _, tg := range trigramsOf(doc.content) {
+			shard.Postings[tg] = append(shard.Postings[tg], uint32(i))
+		}
+	}
+	return shard
+}
+
+// candidates returns the indexes of the documents which contain all the trigrams
+func (s *trigramShard) candidates(trigrams []trigram) []uint32 {
+	if len(trigrams) == 0 {
+		docs := make([]uint32, len(s.Documents))
+		for i := range docs {
+			docs[i] = uint32(i)
+		}
+		return docs
+	}
+
+	postings := make([][]uint32, 0, len(trigrams))
+	for _, tg := range trigrams {
+		docs, ok := s.Postings[tg]
+		if !ok {
+			return nil
+		}
+		postings = append(postings, docs)
+	}
+	// start with the rarest trigram to keep the intersections small
+	sort.Slice(postings, func(i, j int) bool { return len(postings[i]) < len(postings[j]) })
+
+	docs := postings[0]
+	for _, other := range postings[1:] {
+		intersection := make([]uint32, 0, len(docs))
+		for i, j := 0, 0; i < len(docs) && j < len(other); {
+			switch {
+			case docs[i] < other[j]:
+				i++
+			case docs[i] > other[j]:
+				j++
+			default:
+				intersection = append(intersection, docs[i])
+				i++
+				j++
+			}
+		}
+		if len(intersection) == 0 {
+			return nil
+		}
+		docs = intersection
+	}
+	return docs
+}
+
+// trigramRepoIndex is the index of a repository, made of the shards which have been written since the last full one.
+// It is never modified once it has been loaded, as it may be searched concurrently.
+type trigramRepoIndex struct {
+	shards []*trigramShard // from the oldest to the newest
+	size   int64           // the size of the shard files
+}
+
+func (ri *trigramRepoIndex) lastShard() *trigramShard {
+	return ri.shards[len(ri.shards)-1]
+}
+
+// withShard returns the index of the repository once a shard has been added to it
+func (ri *trigramRepoIndex) withShard(shard *trigramShard) *trigramRepoIndex {
+	if shard.Full {
+		return &trigramRepoIndex{shards: []*trigramShard{shard}, size: shard.size}
+	}
+	shards := make([]*trigramShard, 0, len(ri.shards)+1)
+	return &trigramRepoIndex{shards: append(append(shards, ri.shards...), shard), size: ri.size + shard.size}
+}
+
+// iterateDocuments calls f with the shards, from the newest to the oldest, and the indexes of their
+// documents which have not been changed or removed by a newer shard, until f returns an error
+func (ri *trigramRepoIndex) iterateDocuments(docs func(shard *trigramShard) []uint32, f func(shard *trigramShard, i uint32) error) error {
+	hidden := make(map[string]struct{})
+	for i := len(ri.shards) - 1; i >= 0; i-- {
+		shard := ri.shards[i]
+		for _, doc := range docs(shard) {
+			if _, ok := hidden[shard.Documents[doc].Filename]; !ok {
+				if err := f(shard, doc); err != nil {
+					return err
+				}
+			}
+		}
+		if i == 0 {
+			break
+		}
+		for _, doc := range shard.Documents {
+			hidden[doc.Filename] = struct{}{}
+		}
+		for _, filename := range shard.Hidden {
+			hidden[filename] = struct{}{}
+		}
+	}
+	return nil
+}
+
+// trigramContentReader reads the contents of the documents from the content files of their shards
+type trigramContentReader struct {
+	t     *TrigramIndexer
+	files map[*trigramShard]*os.File
+}
+
+func (r *trigramContentReader) content(shard *trigramShard, doc *trigramDocument) (string, error) {
+	f, ok := r.files[shard]
+	if !ok {
+		var err error
+		if f, err = os.Open(r.t.contentPath(shard.RepoID, shard.Seq)); err != nil {
+			return "", err
+		}
+		r.files[shard] = f
+	}
+	content := make([]byte, doc.Size)
+	if _, err := f.ReadAt(content, doc.Offset); err != nil {
+		return "", fmt.Errorf("unable to read the content of %s: %w", doc.Filename, err)
+	}
+	return string(content), nil
+}
+
+func (r *trigramContentReader) Close() {
+	for _, f := range r.files {
+		_ = f.Close()
+	}
+}
+
+var _ Indexer = &TrigramIndexer{}
+
+// TrigramIndexer represents a local indexer implementation which stores a trigram index of each repository in shard files,
+// it supports regexp, symbol and case-sensitive searches.
+// It follows the design of zoekt (immutable shards of trigram postings, contents read from the disk when they are
+// matched) without depending on it, as zoekt is not available as a stable Go module and would bring a large
+// dependency tree for what Gitea needs.
+type TrigramIndexer struct {
+	indexDir string
+	lock     sync.Mutex
+	repos    *lru.Cache // the indexes of the repositories which have been loaded from the index directory
+	// cachedSize is the size of the shards in memory, maxCachedSize bounds it
+	cachedSize    int64
+	maxCachedSize int64
+}
+
+// NewTrigramIndexer creates a new trigram local indexer
+func NewTrigramIndexer(indexDir string) (*TrigramIndexer, bool, error) {
+	indexer := &TrigramIndexer{
+		indexDir:      indexDir,
+		maxCachedSize: trigramMaxCachedSize,
+	}
+	repos, err := lru.NewWithEvict(trigramCachedRepos, func(_, value any) {
+		indexer.cachedSize -= value.(*trigramRepoIndex).size
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	indexer.repos = repos
+	created, err := indexer.init()
+	if err != nil {
+		return nil, false, err
+	}
+	return indexer, created, nil
+}
+
+// init init the indexer, it returns whether the index directory has been (re-)created
+func (t *TrigramIndexer) init() (bool, error) {
+	if _, err := os.Stat(t.indexDir); err == nil {
+		metadata, err := rupture.ReadIndexMetadata(t.indexDir)
+		if err != nil {
+			return false, err
+		}
+		if metadata.Version >= trigramIndexerLatestVersion {
+			return false, nil
+		}
+		// the index is using a previous version, so we should delete it and re-populate
+		if err := util.RemoveAll(t.indexDir); err != nil {
+			return false, err
+		}
+	} else if !os.IsNotExist(err) {
+		return false, err
+	}
+
+	if err := os.MkdirAll(t.indexDir, os.ModePerm); err != nil {
+		return false, err
+	}
+	if err := rupture.WriteIndexMetadata(t.indexDir, &rupture.IndexMetadata{
+		Version: trigramIndexerLatestVersion,
+	}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func (t *TrigramIndexer) repoDir(repoID int64) string {
+	return filepath.Join(t.indexDir, indexerID(repoID))
+}
+
+func (t *TrigramIndexer) shardPath(repoID int64, seq int) string {
+	return filepath.Join(t.repoDir(repoID), fmt.Sprintf("%08d%s", seq, trigramShardExt))
+}
+
+func (t *TrigramIndexer) contentPath(repoID int64, seq int) string {
+	return filepath.Join(t.repoDir(repoID), fmt.Sprintf("%08d%s", seq, trigramContentExt))
+}
+
+func (t *TrigramIndexer) newContentReader() *trigramContentReader {
+	return &trigramContentReader{t: t, files: make(map[*trigramShard]*os.File)}
+}
+
+// cacheRepoIndex keeps the index of a repository in memory, the least recently used repositories are evicted once
+// the shards in memory are too large. The lock must be held.
+func (t *TrigramIndexer) cacheRepoIndex(repoID int64, ri *trigramRepoIndex) {
+	t.repos.Remove(repoID)
+	t.cachedSize += ri.size
+	t.repos.Add(repoID, ri)
+	for t.cachedSize > t.maxCachedSize && t.repos.Len() > 1 {
+		t.repos.RemoveOldest()
+	}
+}
+
+// repoIndex returns the index of a repository, or nil if the repository is not indexed.
+// An error is returned if a shard can't be read or is missing.
+func (t *TrigramIndexer) repoIndex(repoID int64) (*trigramRepoIndex, error) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if ri, ok := t.repos.Get(repoID); ok {
+		return ri.(*trigramRepoIndex), nil
+	}
+
+	entries, err := os.ReadDir(t.repoDir(repoID))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	// the names of the shards are their zero-padded sequence numbers, so they are read in order
+	ri := &trigramRepoIndex{}
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), trigramShardExt) {
+			continue
+		}
+		shard, err := readTrigramShard(filepath.Join(t.repoDir(repoID), entry.Name()))
+		if err != nil {
+			return nil, fmt.Errorf("unable to read the index shard %s of repo %d: %w", entry.Name(), repoID, err)
+		}
+		if shard.Full {
+			// the older shards are left over by an interrupted merge
+			ri = &trigramRepoIndex{}
+		} else if len(ri.shards) == 0 || ri.lastShard().Seq+1 != shard.Seq {
+			return nil, fmt.Errorf("the index of repo %d is missing the shards preceding %s", repoID, entry.Name())
+		}
+		ri = ri.withShard(shard)
+	}
+	if len(ri.shards) == 0 {
+		return nil, nil
+	}
+	t.cacheRepoIndex(repoID, ri)
+	return ri, nil
+}
+
+func readTrigramShard(path string) (*trigramShard, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	shard := &trigramShard{size: info.Size()}
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(shard); err != nil {
+		return nil, err
+	}
+	return shard, nil
+}
+
+// writeTrigramFile writes a file of the index atomically, it returns the size of the file
+func writeTrigramFile(path string, write func(w io.Writer) error) (int64, error) {
+	tmpPath := path + ".tmp"
+	f, err := os.Create(tmpPath)
+	if err != nil {
+		return 0, err
+	}
+	w := bufio.NewWriter(f)
+	if err = write(w); err == nil {
+		err = w.Flush()
+	}
+	var size int64
+	if err == nil {
+		size, err = f.Seek(0, io.SeekCurrent)
+	}
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err == nil {
+		err = os.Rename(tmpPath, path)
+	}
+	if err != nil {
+		_ = util.Remove(tmpPath)
+		return 0, err
+	}
+	return size, nil
+}
+
+// addShard stores a new shard of a repository, the older shards are removed if the new one is full
+func (t *TrigramIndexer) addShard(ri *trigramRepoIndex, shard *trigramShard) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	if err := os.MkdirAll(t.repoDir(shard.RepoID), os.ModePerm); err != nil {
+		return err
+	}
+	// the contents are written first, as a shard is only read once its contents exist
+	if _, err := writeTrigramFile(t.contentPath(shard.RepoID, shard.Seq), func(w io.Writer) error {
+		for _, doc := range shard.Documents {
+			if _, err := io.WriteString(w, doc.content); err != nil {
+				return err
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+	size, err := writeTrigramFile(t.shardPath(shard.RepoID, shard.Seq), func(w io.Writer) error {
+		return gob.NewEncoder(w).Encode(shard)
+	})
+	if err != nil {
+		return err
+	}
+	shard.size = size
+	for _, doc := range shard.Documents {
+		doc.content = ""
+	}
+
+	if shard.Full {
+		for _, old := range ri.shards {
+			for _, path := range []string{t.shardPath(old.RepoID, old.Seq), t.contentPath(old.RepoID, old.Seq)} {
+				if err := util.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+					log.Warn("Unable to remove the obsolete index file %s of repo %d: %v", path, old.RepoID, err)
+				}
+			}
+		}
+	}
+	t.cacheRepoIndex(shard.RepoID, ri.withShard(shard))
+	return nil
+}
+
+// indexedRepoIDs returns the IDs of all the repositories which have a shard
+func (t *TrigramIndexer) indexedRepoIDs() ([]int64, error) {
+	entries, err := os.ReadDir(t.indexDir)
+	if err != nil {
+		return nil, err
+	}
+	repoIDs := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		repoID, err := strconv.ParseInt(entry.Name(), 36, 64)
+		if err != nil {
+			log.Warn("Unexpected file in the trigram repo indexer: %s", entry.Name())
+			continue
+		}
+		repoIDs = append(repoIDs, repoID)
+	}
+	return repoIDs, nil
+}
+
+// readDocument reads a file to index, it returns nil if the file must not be indexed
+func (t *TrigramIndexer) readDocument(ctx context.Context, batchWriter git.WriteCloserError, batchReader *bufio.Reader, update fileUpdate, repo *repo_model.Repository) (*trigramDocument, error) {
+	// Ignore vendored files in code search
+	if setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
+		return nil, nil
+	}
+
+	size := update.Size
+
+	var err error
+	if !update.Sized {
+		var stdout string
+		stdout, _, err = git.NewCommand(ctx, "cat-file", "-s").AddDynamicArguments(update.BlobSha).RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+		if err != nil {
+			return nil, err
+		}
+		if size, err = strconv.ParseInt(strings.TrimSpace(stdout), 10, 64); err != nil {
+			return nil, fmt.Errorf("Misformatted git cat-file output: %w", err)
+		}
+	}
+
+	if size > setting.Indexer.MaxIndexerFileSize {
+		return nil, nil
+	}
+
+	if _, err := batchWriter.Write([]byte(update.BlobSha + "\n")); err != nil {
+		return nil, err
+	}
+
+	_, _, size, err = git.ReadBatchLine(batchReader)
+	if err != nil {
+		return nil, err
+	}
+
+	fileContents, err := io.ReadAll(io.LimitReader(batchReader, size))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = batchReader.Discard(1); err != nil {
+		return nil, err
+	}
+	if !typesniffer.DetectContentType(fileContents).IsText() {
+		// FIXME: UTF-16 files will probably fail here
+		return nil, nil
+	}
+
+	content := string(charset.ToUTF8DropErrors(fileContents))
+	language := analyze.GetCodeLanguage(update.Filename, fileContents)
+	return &trigramDocument{
+		Filename:    update.Filename,
+		content:     content,
+		Language:    language,
+		Symbols:     extractSymbols(language, content),
+		UpdatedUnix: timeutil.TimeStampNow(),
+	}, nil
+}
+
+// Index indexes the data
+func (t *TrigramIndexer) Index(ctx context.Context, repo *repo_model.Repository, sha string, changes *repoChanges) error {
+	ri, err := t.repoIndex(repo.ID)
+	if err != nil {
+		log.Warn("Reindexing %-v as its index can't be used: %v", repo, err)
+		if err := t.Delete(repo.ID); err != nil {
+			return err
+		}
+	}
+	full := ri == nil
+	if full {
+		// the changes are relative to the commit which has been indexed last, they are not enough to rebuild a missing index
+		if changes, err = genesisChanges(ctx, repo, sha); err != nil {
+			return err
+		}
+	}
+
+	if !full && len(changes.Updates) == 0 && len(changes.RemovedFilenames) == 0 && ri.lastShard().CommitID == sha {
+		return nil
+	}
+
+	documents := make(map[string]*trigramDocument, len(changes.Updates))
+	if len(changes.Updates) > 0 {
+		// Now because of some insanity with git cat-file not immediately failing if not run in a valid git directory we need to run git rev-parse first!
+		if err := git.EnsureValidGitRepository(ctx, repo.RepoPath()); err != nil {
+			log.Error("Unable to open git repo: %s for %-v: %v", repo.RepoPath(), repo, err)
+			return err
+		}
+
+		batchWriter, batchReader, cancel := git.CatFileBatch(ctx, repo.RepoPath())
+		defer cancel()
+
+		for _, update := range changes.Updates {
+			doc, err := t.readDocument(ctx, batchWriter, batchReader, update, repo)
+			if err != nil {
+				return err
+			}
+			if doc != nil {
+				documents[update.Filename] = doc
+			}
+		}
+		cancel()
+	}
+
+	if full {
+		return t.addShard(&trigramRepoIndex{}, newTrigramShard(repo.ID, 1, sha, true, documents, nil))
+	}
+
+	hidden := make([]string, 0, len(changes.Updates)+len(changes.RemovedFilenames))
+	for _, update := range changes.Updates {
+		hidden = append(hidden, update.Filename)
+	}
+	hidden = append(hidden, changes.RemovedFilenames...)
+
+	seq := ri.lastShard().Seq + 1
+	if len(ri.shards) < trigramMaxShards {
+		return t.addShard(ri, newTrigramShard(repo.ID, seq, sha, false, documents, hidden))
+	}
+
+	// merge the shards, the changed files are replaced by the documents read above
+	changed := container.SetOf(hidden...)
+	reader := t.newContentReader()
+	defer reader.Close()
+	if err := ri.iterateDocuments(func(shard *trigramShard) []uint32 { return shard.candidates(nil) }, func(shard *trigramShard, i uint32) error {
+		doc := shard.Documents[i]
+		if changed.Contains(doc.Filename) {
+			return nil
+		}
+		content, err := reader.content(shard, doc)
+		if err != nil {
+			return err
+		}
+		// the document of the older shard may be searched concurrently, so it is copied
+		merged := *doc
+		merged.content = content
+		documents[doc.Filename] = &merged
+		return nil
+	}); err != nil {
+		log.Warn("Reindexing %-v as its shards can't be merged: %v", repo, err)
+		if err := t.Delete(repo.ID); err != nil {
+			return err
+		}
+		return t.Index(ctx, repo, sha, changes)
+	}
+	return t.addShard(ri, newTrigramShard(repo.ID, seq, sha, true, documents, nil))
+}
+
+// Delete deletes indexes by ids
+func (t *TrigramIndexer) Delete(repoID int64) error {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+
+	t.repos.Remove(repoID)
+	return util.RemoveAll(t.repoDir(repoID))
+}
+
+type trigramMatch struct {
+	repoID     int64
+	commitID   string
+	shard      *trigramShard
+	doc        *trigramDocument
+	startIndex int
+	endIndex   int
+}
+
+// dropRepoIndex removes the index of a repository which can't be searched and queues the repository to be indexed
+// from scratch, the other repositories are still searched
+func (t *TrigramIndexer) dropRepoIndex(repoID int64, err error) {
+	log.Warn("Reindexing repo %d as its index can't be searched: %v", repoID, err)
+	if err := t.Delete(repoID); err != nil {
+		log.Error("Unable to delete the index of repo %d: %v", repoID, err)
+	}
+	if indexerQueue == nil {
+		return
+	}
+	if err := indexerQueue.Push(&IndexerData{RepoID: repoID}); err != nil && !errors.Is(err, queue.ErrAlreadyInQueue) {
+		log.Error("Unable to queue repo %d to be reindexed: %v", repoID, err)
+	}
+}
+
+// searchRepo matches the documents of a repository, it returns the matches of the searched language
+// and the number of matches of every language
+func (t *TrigramIndexer) searchRepo(repoID int64, ri *trigramRepoIndex, matcher *trigramMatcher, language string) ([]*trigramMatch, map[string]int, error) {
+	reader := t.newContentReader()
+	defer reader.Close()
+
+	commitID := ri.lastShard().CommitID
+	matches := make([]*trigramMatch, 0, 10)
+	languageCounts := make(map[string]int)
+	err := ri.iterateDocuments(func(shard *trigramShard) []uint32 { return shard.candidates(matcher.trigrams) }, func(shard *trigramShard, i uint32) error {
+		doc := shard.Documents[i]
+		var content string
+		if !matcher.symbol {
+			var err error
+			if content, err = reader.content(shard, doc); err != nil {
+				return err
+			}
+		}
+		startIndex, endIndex, ok := matcher.match(doc, content)
+		if !ok {
+			return nil
+		}
+		languageCounts[doc.Language]++
+		if language != "" && doc.Language != language {
+			return nil
+		}
+		matches = append(matches, &trigramMatch{
+			repoID:     repoID,
+			commitID:   commitID,
+			shard:      shard,
+			doc:        doc,
+			startIndex: startIndex,
+			endIndex:   endIndex,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.Slice(matches, func(i, j int) bool { return matches[i].doc.Filename < matches[j].doc.Filename })
+	return matches, languageCounts, nil
+}
+
+// Search searches for files in the specified repo.
+// Returns the matching file-paths
+func (t *TrigramIndexer) Search(ctx context.Context, opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	matcher, err := newTrigramMatcher(opts)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	repoIDs := opts.RepoIDs
+	if len(repoIDs) == 0 {
+		if repoIDs, err = t.indexedRepoIDs(); err != nil {
+			return 0, nil, nil, err
+		}
+	} else {
+		repoIDs = append(make([]int64, 0, len(repoIDs)), repoIDs...)
+	}
+	sort.Slice(repoIDs, func(i, j int) bool { return repoIDs[i] < repoIDs[j] })
+
+	var matches []*trigramMatch
+	languageCounts := make(map[string]int)
+	for _, repoID := range repoIDs {
+		if err := ctx.Err(); err != nil {
+			return 0, nil, nil, err
+		}
+		ri, err := t.repoIndex(repoID)
+		if err != nil {
+			t.dropRepoIndex(repoID, err)
+			continue
+		} else if ri == nil {
+			continue
+		}
+		repoMatches, repoLanguageCounts, err := t.searchRepo(repoID, ri, matcher, opts.Language)
+		if err != nil {
+			t.dropRepoIndex(repoID, err)
+			continue
+		}
+		matches = append(matches, repoMatches...)
+		for language, count := range repoLanguageCounts {
+			languageCounts[language] += count
+		}
+	}
+
+	total := int64(len(matches))
+	matches = util.PaginateSlice(matches, opts.Page, opts.PageSize).([]*trigramMatch)
+	reader := t.newContentReader()
+	defer reader.Close()
+	searchResults := make([]*SearchResult, 0, len(matches))
+	for _, match := range matches {
+		content, err := reader.content(match.shard, match.doc)
+		if err != nil {
+			// the shard has been merged since it has been searched
+			log.Warn("Unable to read the content of %s in repo %d: %v", match.doc.Filename, match.repoID, err)
+			continue
+		}
+		searchResults = append(searchResults, &SearchResult{
+			RepoID:      match.repoID,
+			StartIndex:  match.startIndex,
+			EndIndex:    match.endIndex,
+			Filename:    match.doc.Filename,
+			Content:     content,
+			CommitID:    match.commitID,
+			UpdatedUnix: match.doc.UpdatedUnix,
+			Language:    match.doc.Language,
+			Color:       enry.GetColor(match.doc.Language),
+		})
+	}
+
+	searchResultLanguages := make([]*SearchResultLanguages, 0, len(languageCounts))
+	for language, count := range languageCounts {
+		if len(language) == 0 {
+			continue
+		}
+		searchResultLanguages = append(searchResultLanguages, &SearchResultLanguages{
+			Language: language,
+			Color:    enry.GetColor(language),
+			Count:    count,
+		})
+	}
+	sort.Slice(searchResultLanguages, func(i, j int) bool {
+		if searchResultLanguages[i].Count != searchResultLanguages[j].Count {
+			return searchResultLanguages[i].Count > searchResultLanguages[j].Count
+		}
+		return searchResultLanguages[i].Language < searchResultLanguages[j].Language
+	})
+	if len(searchResultLanguages) > 10 {
+		searchResultLanguages = searchResultLanguages[:10]
+	}
+
+	return total, searchResults, searchResultLanguages, nil
+}
+
+// Ping does nothing
+func (t *TrigramIndexer) Ping() bool {
+	return true
+}
+
+// Close close the indexer
+func (t *TrigramIndexer) Close() {
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	t.repos.Purge()
+	log.Info("PID: %d Repository Indexer closed", os.Getpid())
+}
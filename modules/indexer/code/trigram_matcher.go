@@ -0,0 +1,159 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package code
+
+import (
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-enry/go-enry/v2"
+)
+
+// trigramMatcher matches the documents of a trigram index against the keyword of a search
+type trigramMatcher struct {
+	re       *regexp.Regexp
+	symbol   bool      // match the symbols defined in the documents instead of their contents
+	trigrams []trigram // the trigrams which are in every matching document, empty if it can't be known
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func newTrigramMatcher(opts *SearchOptions) (*trigramMatcher, error) {
+	var expr string
+	switch opts.Mode {
+	case SearchModeRegexp:
+		expr = opts.Keyword
+	case SearchModeMatch:
+		// the keyword must not be a part of a longer word
+		expr = regexp.QuoteMeta(opts.Keyword)
+		if first, _ := utf8.DecodeRuneInString(opts.Keyword); isWordRune(first) {
+			expr = `\b` + expr
+		}
+		if last, _ := utf8.DecodeLastRuneInString(opts.Keyword); isWordRune(last) {
+			expr += `\b`
+		}
+	case SearchModeSymbol:
+		expr = regexp.QuoteMeta(opts.Keyword)
+	default:
+		// the words of the keyword may be separated by any spaces
+		words := strings.Fields(opts.Keyword)
+		for i, word := range words {
+			words[i] = regexp.QuoteMeta(word)
+		}
+		expr = strings.Join(words, `\s+`)
+	}
+	if !opts.CaseSensitive {
+		expr = "(?i)" + expr
+	}
+
+	parsed, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid regular expression: %v", err)
+	}
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid regular expression: %v", err)
+	}
+
+	matcher := &trigramMatcher{
+		re:     re,
+		symbol: opts.Mode == SearchModeSymbol,
+	}
+	for _, literal := range requiredLiterals(parsed.Simplify()) {
+		matcher.trigrams = append(matcher.trigrams, trigramsOf(literal)...)
+	}
+	return matcher, nil
+}
+
+// requiredLiterals returns strings which are in every text matched by a regular expression, ignoring their case
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var literals []string
+		var run []rune
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				run = append(run, sub.Rune...)
+				continue
+			}
+			if len(run) > 0 {
+				literals = append(literals, string(run))
+				run = nil
+			}
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+		if len(run) > 0 {
+			literals = append(literals, string(run))
+		}
+		return literals
+	}
+	return nil
+}
+
+// match returns the position of the first match in a document, the content isn't needed to match the symbols
+func (m *trigramMatcher) match(doc *trigramDocument, content string) (int, int, bool) {
+	if m.symbol {
+		for _, symbol := range doc.Symbols {
+			if m.re.MatchString(symbol.Name) {
+				return symbol.StartIndex, symbol.StartIndex + len(symbol.Name), true
+			}
+		}
+		return 0, 0, false
+	}
+	loc := m.re.FindStringIndex(content)
+	if loc == nil {
+		return 0, 0, false
+	}
+	return loc[0], loc[1], true
+}
+
+// trigramSymbol is a symbol (function, type, ...) defined in a document
+type trigramSymbol struct {
+	Name       string
+	StartIndex int
+}
+
+var (
+	// symbolDefinitionPattern matches the definitions of functions, types, classes ... in most programming languages,
+	// including the methods of Go
+	symbolDefinitionPattern = regexp.MustCompile(`(?m)^[ \t]*(?:(?:export|default|pub(?:\([a-z]+\))?|public|private|protected|internal|static|abstract|final|async|unsafe|extern|inline|virtual|override|open|sealed|data)[ \t]+)*(?:func|fn|def|function|class|struct|enum|interface|trait|type|module|impl|object|protocol|union|namespace|record)[ \t]+(?:\([^)\n]*\)[ \t]*)?([A-Za-z_$][\w$]*)`)
+	// symbolVariablePattern matches the top level variables and constants, the local ones are not symbols
+	symbolVariablePattern = regexp.MustCompile(`(?m)^(?:export[ \t]+)?(?:const|let|var|static)[ \t]+(?:mut[ \t]+)?([A-Za-z_$][\w$]*)`)
+)
+
+// extractSymbols finds the symbols defined in the content of a file.
+// It is far less accurate than a parser (or ctags), but it doesn't need any external tool.
+func extractSymbols(language, content string) []trigramSymbol {
+	if language == "" || enry.GetLanguageType(language) != enry.Programming {
+		return nil
+	}
+
+	var symbols []trigramSymbol
+	for _, pattern := range []*regexp.Regexp{symbolDefinitionPattern, symbolVariablePattern} {
+		for _, loc := range pattern.FindAllStringSubmatchIndex(content, -1) {
+			symbols = append(symbols, trigramSymbol{
+				Name:       content[loc[2]:loc[3]],
+				StartIndex: loc[2],
+			})
+		}
+	}
+	sort.Slice(symbols, func(i, j int) bool { return symbols[i].StartIndex < symbols[j].StartIndex })
+	return symbols
+}
@@ -0,0 +1,268 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package code
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigramIndexAndSearch(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	dir := t.TempDir()
+
+	idx, created, err := NewTrigramIndexer(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, created)
+	defer idx.Close()
+
+	testIndexer("trigram", t, idx)
+
+	assert.NoError(t, index(git.DefaultContext, idx, 1))
+
+	// the shards are kept when the indexer is reopened
+	idx.Close()
+	idx, created, err = NewTrigramIndexer(dir)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.False(t, created)
+	defer idx.Close()
+
+	cases := []struct {
+		Keyword       string
+		Mode          SearchMode
+		CaseSensitive bool
+		Total         int64
+	}{
+		{Keyword: "Descr", Mode: SearchModeFuzzy, Total: 1},
+		{Keyword: "description  FOR", Mode: SearchModeFuzzy, Total: 1},
+		{Keyword: "Descr", Mode: SearchModeMatch, Total: 0},
+		{Keyword: "description", Mode: SearchModeMatch, Total: 1},
+		{Keyword: "description", Mode: SearchModeMatch, CaseSensitive: true, Total: 0},
+		{Keyword: "Description", Mode: SearchModeMatch, CaseSensitive: true, Total: 1},
+		{Keyword: `^# repo\d$`, Mode: SearchModeRegexp, Total: 0},
+		{Keyword: `(?m)^# repo\d$`, Mode: SearchModeRegexp, Total: 1},
+		{Keyword: `desc\w+ for REPO[0-9]+`, Mode: SearchModeRegexp, Total: 1},
+		{Keyword: `desc\w+ for REPO[0-9]+`, Mode: SearchModeRegexp, CaseSensitive: true, Total: 0},
+		{Keyword: "Description", Mode: SearchModeSymbol, Total: 0},
+	}
+	for _, c := range cases {
+		t.Run(c.Keyword, func(t *testing.T) {
+			total, res, _, err := idx.Search(context.TODO(), &SearchOptions{
+				RepoIDs:       []int64{1},
+				Keyword:       c.Keyword,
+				Mode:          c.Mode,
+				CaseSensitive: c.CaseSensitive,
+				Page:          1,
+				PageSize:      10,
+			})
+			assert.NoError(t, err)
+			assert.EqualValues(t, c.Total, total)
+			assert.Len(t, res, int(c.Total))
+		})
+	}
+
+	_, _, _, err = idx.Search(context.TODO(), &SearchOptions{Keyword: "repo(", Mode: SearchModeRegexp, Page: 1, PageSize: 10})
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+
+	assert.NoError(t, idx.Delete(1))
+	total, _, _, err := idx.Search(context.TODO(), &SearchOptions{Keyword: "repo1", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, total)
+}
+
+func TestTrigramSymbolSearch(t *testing.T) {
+	idx, _, err := NewTrigramIndexer(t.TempDir())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer idx.Close()
+
+	goContent := "package main\n\n// see NewServer\nfunc (s *Server) ListenAndServe() error {\n\tvar local int\n\treturn nil\n}\n\ntype Server struct{}\n\nconst DefaultPort = 3000\n\nfunc NewServer() *Server {\n\treturn &Server{}\n}\n"
+	pyContent := "import os\n\nclass Handler:\n    def handle_request(self):\n        pass\n"
+	assert.NoError(t, idx.addShard(&trigramRepoIndex{}, newTrigramShard(42, 1, "0123456789", true, map[string]*trigramDocument{
+		"server.go":  {Filename: "server.go", content: goContent, Language: "Go", Symbols: extractSymbols("Go", goContent)},
+		"handler.py": {Filename: "handler.py", content: pyContent, Language: "Python", Symbols: extractSymbols("Python", pyContent)},
+		"README.md":  {Filename: "README.md", content: "func NewServer starts the server", Language: "Markdown", Symbols: extractSymbols("Markdown", "func NewServer starts the server")},
+	}, nil)))
+
+	names := func(symbols []trigramSymbol) []string {
+		res := make([]string, 0, len(symbols))
+		for _, symbol := range symbols {
+			res = append(res, symbol.Name)
+		}
+		return res
+	}
+	assert.Equal(t, []string{"ListenAndServe", "Server", "DefaultPort", "NewServer"}, names(extractSymbols("Go", goContent)))
+	assert.Equal(t, []string{"Handler", "handle_request"}, names(extractSymbols("Python", pyContent)))
+	assert.Empty(t, extractSymbols("Markdown", "func NewServer starts the server"))
+
+	search := func(keyword string, caseSensitive bool) (int64, []*SearchResult, []*SearchResultLanguages) {
+		total, res, langs, err := idx.Search(context.TODO(), &SearchOptions{
+			Keyword:       keyword,
+			Mode:          SearchModeSymbol,
+			CaseSensitive: caseSensitive,
+			Page:          1,
+			PageSize:      10,
+		})
+		assert.NoError(t, err)
+		return total, res, langs
+	}
+
+	total, res, langs := search("newserver", false)
+	if assert.EqualValues(t, 1, total) {
+		assert.Equal(t, "server.go", res[0].Filename)
+		assert.Equal(t, "NewServer", res[0].Content[res[0].StartIndex:res[0].EndIndex])
+		assert.EqualValues(t, 42, res[0].RepoID)
+		assert.Equal(t, "0123456789", res[0].CommitID)
+		assert.Len(t, langs, 1)
+	}
+
+	total, _, _ = search("newserver", true)
+	assert.EqualValues(t, 0, total)
+
+	total, res, _ = search("handle", false)
+	if assert.EqualValues(t, 1, total) {
+		assert.Equal(t, "handler.py", res[0].Filename)
+		assert.Equal(t, "Handler", res[0].Content[res[0].StartIndex:res[0].EndIndex])
+	}
+
+	total, _, _ = search("local", false)
+	assert.EqualValues(t, 0, total)
+}
+
+func TestTrigramIncrementalIndex(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	idx, _, err := NewTrigramIndexer(t.TempDir())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer idx.Close()
+
+	ctx := git.DefaultContext
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	sha, err := getDefaultBranchSha(ctx, repo)
+	if !assert.NoError(t, err) {
+		return
+	}
+	search := func() int64 {
+		total, _, _, err := idx.Search(context.TODO(), &SearchOptions{RepoIDs: []int64{1}, Keyword: "Description", Page: 1, PageSize: 10})
+		assert.NoError(t, err)
+		return total
+	}
+	shardNames := func() []string {
+		entries, err := os.ReadDir(idx.repoDir(repo.ID))
+		assert.NoError(t, err)
+		names := make([]string, 0, len(entries))
+		for _, entry := range entries {
+			names = append(names, entry.Name())
+		}
+		return names
+	}
+	removeReadme := func() {
+		assert.NoError(t, idx.Index(ctx, repo, sha, &repoChanges{RemovedFilenames: []string{"README.md"}}))
+	}
+
+	// a repository which isn't indexed yet is indexed from scratch, whatever the changes are
+	assert.NoError(t, idx.Index(ctx, repo, sha, &repoChanges{}))
+	assert.EqualValues(t, 1, search())
+
+	// the changes are added in new shards, until the shards are merged
+	for i := 1; i < trigramMaxShards; i++ {
+		removeReadme()
+	}
+	assert.Len(t, shardNames(), 2*trigramMaxShards)
+	assert.EqualValues(t, 0, search())
+	removeReadme()
+	assert.Equal(t, []string{
+		fmt.Sprintf("%08d%s", trigramMaxShards+1, trigramContentExt),
+		fmt.Sprintf("%08d%s", trigramMaxShards+1, trigramShardExt),
+	}, shardNames())
+	assert.EqualValues(t, 0, search())
+
+	// a corrupted shard makes the repository indexed from scratch
+	assert.NoError(t, os.WriteFile(idx.shardPath(repo.ID, trigramMaxShards+1), []byte("corrupted"), 0o644))
+	idx.repos.Purge()
+	assert.NoError(t, idx.Index(ctx, repo, sha, &repoChanges{}))
+	assert.EqualValues(t, 1, search())
+
+	// so does a missing shard, the search skips the repository and drops its index until it is reindexed
+	removeReadme()
+	removeReadme()
+	assert.NoError(t, os.Remove(idx.shardPath(repo.ID, 2)))
+	idx.repos.Purge()
+	assert.EqualValues(t, 0, search())
+	assert.NoDirExists(t, idx.repoDir(repo.ID))
+	assert.NoError(t, idx.Index(ctx, repo, sha, &repoChanges{}))
+	assert.EqualValues(t, 1, search())
+
+	// and a missing content file
+	assert.NoError(t, os.Remove(idx.contentPath(repo.ID, 1)))
+	assert.EqualValues(t, 0, search())
+	assert.NoError(t, idx.Index(ctx, repo, sha, &repoChanges{}))
+	assert.EqualValues(t, 1, search())
+
+	// the least recently used repositories are evicted once the shards in memory are too large
+	idx.maxCachedSize = 1
+	assert.NoError(t, idx.addShard(&trigramRepoIndex{}, newTrigramShard(42, 1, sha, true, nil, nil)))
+	assert.False(t, idx.repos.Contains(repo.ID))
+	assert.EqualValues(t, 1, search())
+	assert.False(t, idx.repos.Contains(int64(42)))
+	assert.Equal(t, 1, idx.repos.Len())
+}
+
+func TestTrigramSearchSkipsBrokenRepos(t *testing.T) {
+	idx, _, err := NewTrigramIndexer(t.TempDir())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer idx.Close()
+
+	for _, repoID := range []int64{1, 2} {
+		assert.NoError(t, idx.addShard(&trigramRepoIndex{}, newTrigramShard(repoID, 1, "0123456789", true, map[string]*trigramDocument{
+			"README.md": {Filename: "README.md", content: "shared words"},
+		}, nil)))
+	}
+	assert.NoError(t, os.WriteFile(idx.shardPath(1, 1), []byte("corrupted"), 0o644))
+	idx.repos.Purge()
+
+	total, res, _, err := idx.Search(context.TODO(), &SearchOptions{Keyword: "shared", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	if assert.EqualValues(t, 1, total) {
+		assert.EqualValues(t, 2, res[0].RepoID)
+		assert.Equal(t, "shared words", res[0].Content)
+	}
+	assert.NoDirExists(t, idx.repoDir(1))
+}
+
+func TestRequiredLiterals(t *testing.T) {
+	cases := map[string][]trigram{
+		`abc`:             trigramsOf("abc"),
+		`(?i)ABCd`:        trigramsOf("abcd"),
+		`foo\w+bar`:       append(trigramsOf("foo"), trigramsOf("bar")...),
+		`foo.*barbaz`:     append(trigramsOf("foo"), trigramsOf("barbaz")...),
+		`(hello)+ world?`: append(trigramsOf("hello"), trigramsOf(" worl")...),
+		`abc|def`:         nil,
+	}
+	for expr, expected := range cases {
+		matcher, err := newTrigramMatcher(&SearchOptions{Keyword: expr, Mode: SearchModeRegexp, CaseSensitive: true})
+		if assert.NoError(t, err) {
+			assert.ElementsMatch(t, expected, matcher.trigrams, expr)
+		}
+	}
+}
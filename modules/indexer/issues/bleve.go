@@ -28,7 +28,7 @@ import (
 const (
 	issueIndexerAnalyzer      = "issueIndexer"
 	issueIndexerDocType       = "issueIndexerDocType"
-	issueIndexerLatestVersion = 2
+	issueIndexerLatestVersion = 3
 )
 
 // indexerID a bleve-compatible unique identifier for an integer id
@@ -54,6 +54,38 @@ func numericEqualityQuery(value int64, field string) *query.NumericRangeQuery {
 	return q
 }
 
+// numericEqualityQueries a query matching any of the given values of the field
+func numericEqualityQueries(values []int64, field string) *query.DisjunctionQuery {
+	queries := make([]query.Query, 0, len(values))
+	for _, value := range values {
+		queries = append(queries, numericEqualityQuery(value, field))
+	}
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+// numericRangeQuery a numeric range query for the given bounds (included) and field, a zero bound is not set
+func numericRangeQuery(min, max int64, field string) *query.NumericRangeQuery {
+	var minF, maxF *float64
+	if min != 0 {
+		f := float64(min)
+		minF = &f
+	}
+	if max != 0 {
+		f := float64(max)
+		maxF = &f
+	}
+	tru := true
+	q := bleve.NewNumericRangeInclusiveQuery(minF, maxF, &tru, &tru)
+	q.SetField(field)
+	return q
+}
+
+func newBoolFieldQuery(value bool, field string) *query.BoolFieldQuery {
+	q := bleve.NewBoolFieldQuery(value)
+	q.SetField(field)
+	return q
+}
+
 func newMatchPhraseQuery(matchPhrase, field, analyzer string) *query.MatchPhraseQuery {
 	q := bleve.NewMatchPhraseQuery(matchPhrase)
 	q.FieldVal = field
@@ -120,7 +152,15 @@ func createIssueIndexer(path string, latestVersion int) (bleve.Index, error) {
 
 	numericFieldMapping := bleve.NewNumericFieldMapping()
 	numericFieldMapping.IncludeInAll = false
-	docMapping.AddFieldMappingsAt("RepoID", numericFieldMapping)
+	for _, field := range []string{"RepoID", "LabelIDs", "PosterID", "AssigneeIDs", "MentionIDs", "MilestoneID", "CreatedUnix", "UpdatedUnix"} {
+		docMapping.AddFieldMappingsAt(field, numericFieldMapping)
+	}
+
+	boolFieldMapping := bleve.NewBooleanFieldMapping()
+	boolFieldMapping.IncludeInAll = false
+	for _, field := range []string{"IsPull", "IsClosed", "NoLabel", "NoAssignee"} {
+		docMapping.AddFieldMappingsAt(field, boolFieldMapping)
+	}
 
 	textFieldMapping := bleve.NewTextFieldMapping()
 	textFieldMapping.Store = false
@@ -206,15 +246,37 @@ func (b *BleveIndexer) Index(issues []*IndexerData) error {
 	batch := gitea_bleve.NewFlushingBatch(b.indexer, maxBatchSize)
 	for _, issue := range issues {
 		if err := batch.Index(indexerID(issue.ID), struct {
-			RepoID   int64
-			Title    string
-			Content  string
-			Comments []string
+			RepoID      int64
+			Title       string
+			Content     string
+			Comments    []string
+			IsPull      bool
+			IsClosed    bool
+			LabelIDs    []int64
+			NoLabel     bool
+			PosterID    int64
+			AssigneeIDs []int64
+			NoAssignee  bool
+			MentionIDs  []int64
+			MilestoneID int64
+			CreatedUnix int64
+			UpdatedUnix int64
 		}{
-			RepoID:   issue.RepoID,
-			Title:    issue.Title,
-			Content:  issue.Content,
-			Comments: issue.Comments,
+			RepoID:      issue.RepoID,
+			Title:       issue.Title,
+			Content:     issue.Content,
+			Comments:    issue.Comments,
+			IsPull:      issue.IsPull,
+			IsClosed:    issue.IsClosed,
+			LabelIDs:    issue.LabelIDs,
+			NoLabel:     issue.NoLabel,
+			PosterID:    issue.PosterID,
+			AssigneeIDs: issue.AssigneeIDs,
+			NoAssignee:  issue.NoAssignee,
+			MentionIDs:  issue.MentionIDs,
+			MilestoneID: issue.MilestoneID,
+			CreatedUnix: issue.CreatedUnix,
+			UpdatedUnix: issue.UpdatedUnix,
 		}); err != nil {
 			return err
 		}
@@ -235,24 +297,72 @@ func (b *BleveIndexer) Delete(ids ...int64) error {
 
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
-func (b *BleveIndexer) Search(ctx context.Context, keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	var repoQueriesP []*query.NumericRangeQuery
-	for _, repoID := range repoIDs {
-		repoQueriesP = append(repoQueriesP, numericEqualityQuery(repoID, "RepoID"))
+func (b *BleveIndexer) Search(ctx context.Context, req *SearchRequest) (*SearchResult, error) {
+	musts := []query.Query{
+		numericEqualityQueries(req.RepoIDs, "RepoID"),
+		bleve.NewDisjunctionQuery(
+			newMatchPhraseQuery(req.Keyword, "Title", issueIndexerAnalyzer),
+			newMatchPhraseQuery(req.Keyword, "Content", issueIndexerAnalyzer),
+			newMatchPhraseQuery(req.Keyword, "Comments", issueIndexerAnalyzer),
+		),
 	}
-	repoQueries := make([]query.Query, len(repoQueriesP))
-	for i, v := range repoQueriesP {
-		repoQueries[i] = query.Query(v)
+	var mustNots []query.Query
+
+	if !req.IsClosed.IsNone() {
+		musts = append(musts, newBoolFieldQuery(req.IsClosed.IsTrue(), "IsClosed"))
+	}
+	if !req.IsPull.IsNone() {
+		musts = append(musts, newBoolFieldQuery(req.IsPull.IsTrue(), "IsPull"))
+	}
+	for _, labelIDs := range req.LabelIDs {
+		musts = append(musts, numericEqualityQueries(labelIDs, "LabelIDs"))
+	}
+	if len(req.ExcludedLabelIDs) > 0 {
+		mustNots = append(mustNots, numericEqualityQueries(req.ExcludedLabelIDs, "LabelIDs"))
+	}
+	if req.NoLabel {
+		musts = append(musts, newBoolFieldQuery(true, "NoLabel"))
+	}
+	if len(req.PosterIDs) > 0 {
+		musts = append(musts, numericEqualityQueries(req.PosterIDs, "PosterID"))
+	}
+	if len(req.ExcludedPosterIDs) > 0 {
+		mustNots = append(mustNots, numericEqualityQueries(req.ExcludedPosterIDs, "PosterID"))
+	}
+	if len(req.AssigneeIDs) > 0 {
+		musts = append(musts, numericEqualityQueries(req.AssigneeIDs, "AssigneeIDs"))
+	}
+	if len(req.ExcludedAssigneeIDs) > 0 {
+		mustNots = append(mustNots, numericEqualityQueries(req.ExcludedAssigneeIDs, "AssigneeIDs"))
+	}
+	if req.NoAssignee {
+		musts = append(musts, newBoolFieldQuery(true, "NoAssignee"))
+	}
+	if len(req.MentionedIDs) > 0 {
+		musts = append(musts, numericEqualityQueries(req.MentionedIDs, "MentionIDs"))
+	}
+	if len(req.MilestoneIDs) > 0 {
+		musts = append(musts, numericEqualityQueries(req.MilestoneIDs, "MilestoneID"))
+	}
+	if len(req.ExcludedMilestoneIDs) > 0 {
+		mustNots = append(mustNots, numericEqualityQueries(req.ExcludedMilestoneIDs, "MilestoneID"))
+	}
+	if req.NoMilestone {
+		musts = append(musts, numericEqualityQuery(0, "MilestoneID"))
+	}
+	if req.CreatedAfterUnix != 0 || req.CreatedBeforeUnix != 0 {
+		musts = append(musts, numericRangeQuery(req.CreatedAfterUnix, req.CreatedBeforeUnix, "CreatedUnix"))
+	}
+	if req.UpdatedAfterUnix != 0 || req.UpdatedBeforeUnix != 0 {
+		musts = append(musts, numericRangeQuery(req.UpdatedAfterUnix, req.UpdatedBeforeUnix, "UpdatedUnix"))
 	}
 
-	indexerQuery := bleve.NewConjunctionQuery(
-		bleve.NewDisjunctionQuery(repoQueries...),
-		bleve.NewDisjunctionQuery(
-			newMatchPhraseQuery(keyword, "Title", issueIndexerAnalyzer),
-			newMatchPhraseQuery(keyword, "Content", issueIndexerAnalyzer),
-			newMatchPhraseQuery(keyword, "Comments", issueIndexerAnalyzer),
-		))
-	search := bleve.NewSearchRequestOptions(indexerQuery, limit, start, false)
+	indexerQuery := bleve.NewBooleanQuery()
+	indexerQuery.AddMust(musts...)
+	if len(mustNots) > 0 {
+		indexerQuery.AddMustNot(mustNots...)
+	}
+	search := bleve.NewSearchRequestOptions(indexerQuery, req.Limit, req.Start, false)
 	search.SortBy([]string{"-_score"})
 
 	result, err := b.indexer.SearchInContext(ctx, search)
@@ -261,7 +371,8 @@ func (b *BleveIndexer) Search(ctx context.Context, keyword string, repoIDs []int
 	}
 
 	ret := SearchResult{
-		Hits: make([]Match, 0, len(result.Hits)),
+		Total: int64(result.Total),
+		Hits:  make([]Match, 0, len(result.Hits)),
 	}
 	for _, hit := range result.Hits {
 		id, err := idOfIndexerID(hit.ID)
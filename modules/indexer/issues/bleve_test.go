@@ -7,6 +7,9 @@ import (
 	"context"
 	"testing"
 
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/util"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -30,6 +33,10 @@ func TestBleveIndexAndSearch(t *testing.T) {
 				"test1",
 				"test2",
 			},
+			LabelIDs:    []int64{1, 2},
+			PosterID:    1,
+			NoAssignee:  true,
+			CreatedUnix: 1000,
 		},
 		{
 			ID:      2,
@@ -40,6 +47,12 @@ func TestBleveIndexAndSearch(t *testing.T) {
 				"LGTM",
 				"Good idea",
 			},
+			IsPull:      true,
+			NoLabel:     true,
+			PosterID:    2,
+			AssigneeIDs: []int64{1},
+			MilestoneID: 1,
+			CreatedUnix: 2000,
 		},
 	})
 	assert.NoError(t, err)
@@ -75,7 +88,11 @@ func TestBleveIndexAndSearch(t *testing.T) {
 	}
 
 	for _, kw := range keywords {
-		res, err := indexer.Search(context.TODO(), kw.Keyword, []int64{2}, 10, 0)
+		res, err := indexer.Search(context.TODO(), &SearchRequest{
+			Keyword:             kw.Keyword,
+			ResolvedIssueFilter: issues_model.ResolvedIssueFilter{RepoIDs: []int64{2}},
+			Limit:               10,
+		})
 		assert.NoError(t, err)
 
 		ids := make([]int64, 0, len(res.Hits))
@@ -84,4 +101,63 @@ func TestBleveIndexAndSearch(t *testing.T) {
 		}
 		assert.ElementsMatch(t, kw.IDs, ids)
 	}
+
+	filters := []struct {
+		Filter issues_model.ResolvedIssueFilter
+		IDs    []int64
+	}{
+		{
+			Filter: issues_model.ResolvedIssueFilter{RepoIDs: []int64{1}},
+			IDs:    []int64{},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{IsPull: util.OptionalBoolTrue},
+			IDs:    []int64{2},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{LabelIDs: [][]int64{{2, 3}, {1}}},
+			IDs:    []int64{1},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{ExcludedLabelIDs: []int64{1}},
+			IDs:    []int64{2},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{NoLabel: true, AssigneeIDs: []int64{1}},
+			IDs:    []int64{2},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{NoAssignee: true, NoMilestone: true, PosterIDs: []int64{1}},
+			IDs:    []int64{1},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{ExcludedPosterIDs: []int64{1}, MilestoneIDs: []int64{1}},
+			IDs:    []int64{2},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{CreatedAfterUnix: 1500},
+			IDs:    []int64{2},
+		},
+		{
+			Filter: issues_model.ResolvedIssueFilter{CreatedBeforeUnix: 1500},
+			IDs:    []int64{1},
+		},
+	}
+	for _, test := range filters {
+		if test.Filter.RepoIDs == nil {
+			test.Filter.RepoIDs = []int64{2}
+		}
+		res, err := indexer.Search(context.TODO(), &SearchRequest{
+			Keyword:             "support",
+			ResolvedIssueFilter: test.Filter,
+			Limit:               10,
+		})
+		assert.NoError(t, err)
+
+		ids := make([]int64, 0, len(res.Hits))
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.ID)
+		}
+		assert.ElementsMatch(t, test.IDs, ids, "filter: %+v", test.Filter)
+	}
 }
@@ -37,15 +37,15 @@ func (i *DBIndexer) Delete(ids ...int64) error {
 func (i *DBIndexer) Close() {
 }
 
-// Search dummy function
-func (i *DBIndexer) Search(ctx context.Context, kw string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	total, ids, err := issues_model.SearchIssueIDsByKeyword(ctx, kw, repoIDs, limit, start)
+// Search searches for issues by given conditions on database
+func (i *DBIndexer) Search(ctx context.Context, req *SearchRequest) (*SearchResult, error) {
+	total, ids, err := issues_model.SearchIssueIDsByFilter(ctx, req.Keyword, &req.ResolvedIssueFilter, req.Limit, req.Start)
 	if err != nil {
 		return nil, err
 	}
 	result := SearchResult{
 		Total: total,
-		Hits:  make([]Match, 0, limit),
+		Hits:  make([]Match, 0, len(ids)),
 	}
 	for _, id := range ids {
 		result.Hits = append(result.Hits, Match{
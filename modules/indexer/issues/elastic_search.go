@@ -18,6 +18,9 @@ import (
 	"github.com/olivere/elastic/v7"
 )
 
+// esIssueIndexerLatestVersion is part of the index name, a new index is created and populated when the mapping changes
+const esIssueIndexerLatestVersion = 1
+
 var _ Indexer = &ElasticSearchIndexer{}
 
 // ElasticSearchIndexer implements Indexer interface
@@ -50,7 +53,7 @@ func NewElasticSearchIndexer(url, indexerName string) (*ElasticSearchIndexer, er
 
 	indexer := &ElasticSearchIndexer{
 		client:      client,
-		indexerName: indexerName,
+		indexerName: fmt.Sprintf("%s.v%d", indexerName, esIssueIndexerLatestVersion),
 		available:   true,
 		stopTimer:   make(chan struct{}),
 	}
@@ -94,6 +97,50 @@ const (
 				"comments": {
 					"type" : "text",
 					"index": true
+				},
+				"is_pull": {
+					"type": "boolean",
+					"index": true
+				},
+				"is_closed": {
+					"type": "boolean",
+					"index": true
+				},
+				"label_ids": {
+					"type": "long",
+					"index": true
+				},
+				"no_label": {
+					"type": "boolean",
+					"index": true
+				},
+				"poster_id": {
+					"type": "long",
+					"index": true
+				},
+				"assignee_ids": {
+					"type": "long",
+					"index": true
+				},
+				"no_assignee": {
+					"type": "boolean",
+					"index": true
+				},
+				"mention_ids": {
+					"type": "long",
+					"index": true
+				},
+				"milestone_id": {
+					"type": "long",
+					"index": true
+				},
+				"created_unix": {
+					"type": "long",
+					"index": true
+				},
+				"updated_unix": {
+					"type": "long",
+					"index": true
 				}
 			}
 		}
@@ -131,6 +178,27 @@ func (b *ElasticSearchIndexer) Ping() bool {
 	return b.available
 }
 
+func toElasticSearchDoc(issue *IndexerData) map[string]interface{} {
+	return map[string]interface{}{
+		"id":           issue.ID,
+		"repo_id":      issue.RepoID,
+		"title":        issue.Title,
+		"content":      issue.Content,
+		"comments":     issue.Comments,
+		"is_pull":      issue.IsPull,
+		"is_closed":    issue.IsClosed,
+		"label_ids":    issue.LabelIDs,
+		"no_label":     issue.NoLabel,
+		"poster_id":    issue.PosterID,
+		"assignee_ids": issue.AssigneeIDs,
+		"no_assignee":  issue.NoAssignee,
+		"mention_ids":  issue.MentionIDs,
+		"milestone_id": issue.MilestoneID,
+		"created_unix": issue.CreatedUnix,
+		"updated_unix": issue.UpdatedUnix,
+	}
+}
+
 // Index will save the index data
 func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 	if len(issues) == 0 {
@@ -140,13 +208,7 @@ func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 		_, err := b.client.Index().
 			Index(b.indexerName).
 			Id(fmt.Sprintf("%d", issue.ID)).
-			BodyJson(map[string]interface{}{
-				"id":       issue.ID,
-				"repo_id":  issue.RepoID,
-				"title":    issue.Title,
-				"content":  issue.Content,
-				"comments": issue.Comments,
-			}).
+			BodyJson(toElasticSearchDoc(issue)).
 			Do(graceful.GetManager().HammerContext())
 		return b.checkError(err)
 	}
@@ -157,13 +219,7 @@ func (b *ElasticSearchIndexer) Index(issues []*IndexerData) error {
 			elastic.NewBulkIndexRequest().
 				Index(b.indexerName).
 				Id(fmt.Sprintf("%d", issue.ID)).
-				Doc(map[string]interface{}{
-					"id":       issue.ID,
-					"repo_id":  issue.RepoID,
-					"title":    issue.Title,
-					"content":  issue.Content,
-					"comments": issue.Comments,
-				}),
+				Doc(toElasticSearchDoc(issue)),
 		)
 	}
 
@@ -202,31 +258,93 @@ func (b *ElasticSearchIndexer) Delete(ids ...int64) error {
 	return b.checkError(err)
 }
 
+func newElasticSearchTermsQuery(field string, values []int64) *elastic.TermsQuery {
+	terms := make([]interface{}, 0, len(values))
+	for _, value := range values {
+		terms = append(terms, value)
+	}
+	return elastic.NewTermsQuery(field, terms...)
+}
+
+func newElasticSearchRangeQuery(field string, from, to int64) *elastic.RangeQuery {
+	q := elastic.NewRangeQuery(field)
+	if from != 0 {
+		q = q.Gte(from)
+	}
+	if to != 0 {
+		q = q.Lte(to)
+	}
+	return q
+}
+
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
-func (b *ElasticSearchIndexer) Search(ctx context.Context, keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	kwQuery := elastic.NewMultiMatchQuery(keyword, "title", "content", "comments")
+func (b *ElasticSearchIndexer) Search(ctx context.Context, req *SearchRequest) (*SearchResult, error) {
+	kwQuery := elastic.NewMultiMatchQuery(req.Keyword, "title", "content", "comments")
 	query := elastic.NewBoolQuery()
 	query = query.Must(kwQuery)
-	if len(repoIDs) > 0 {
-		repoStrs := make([]interface{}, 0, len(repoIDs))
-		for _, repoID := range repoIDs {
-			repoStrs = append(repoStrs, repoID)
-		}
-		repoQuery := elastic.NewTermsQuery("repo_id", repoStrs...)
-		query = query.Must(repoQuery)
+	if len(req.RepoIDs) > 0 {
+		query = query.Filter(newElasticSearchTermsQuery("repo_id", req.RepoIDs))
+	}
+	if !req.IsClosed.IsNone() {
+		query = query.Filter(elastic.NewTermQuery("is_closed", req.IsClosed.IsTrue()))
+	}
+	if !req.IsPull.IsNone() {
+		query = query.Filter(elastic.NewTermQuery("is_pull", req.IsPull.IsTrue()))
+	}
+	for _, labelIDs := range req.LabelIDs {
+		query = query.Filter(newElasticSearchTermsQuery("label_ids", labelIDs))
+	}
+	if len(req.ExcludedLabelIDs) > 0 {
+		query = query.MustNot(newElasticSearchTermsQuery("label_ids", req.ExcludedLabelIDs))
+	}
+	if req.NoLabel {
+		query = query.Filter(elastic.NewTermQuery("no_label", true))
+	}
+	if len(req.PosterIDs) > 0 {
+		query = query.Filter(newElasticSearchTermsQuery("poster_id", req.PosterIDs))
+	}
+	if len(req.ExcludedPosterIDs) > 0 {
+		query = query.MustNot(newElasticSearchTermsQuery("poster_id", req.ExcludedPosterIDs))
+	}
+	if len(req.AssigneeIDs) > 0 {
+		query = query.Filter(newElasticSearchTermsQuery("assignee_ids", req.AssigneeIDs))
+	}
+	if len(req.ExcludedAssigneeIDs) > 0 {
+		query = query.MustNot(newElasticSearchTermsQuery("assignee_ids", req.ExcludedAssigneeIDs))
+	}
+	if req.NoAssignee {
+		query = query.Filter(elastic.NewTermQuery("no_assignee", true))
+	}
+	if len(req.MentionedIDs) > 0 {
+		query = query.Filter(newElasticSearchTermsQuery("mention_ids", req.MentionedIDs))
+	}
+	if len(req.MilestoneIDs) > 0 {
+		query = query.Filter(newElasticSearchTermsQuery("milestone_id", req.MilestoneIDs))
+	}
+	if len(req.ExcludedMilestoneIDs) > 0 {
+		query = query.MustNot(newElasticSearchTermsQuery("milestone_id", req.ExcludedMilestoneIDs))
+	}
+	if req.NoMilestone {
+		query = query.Filter(elastic.NewTermQuery("milestone_id", 0))
+	}
+	if req.CreatedAfterUnix != 0 || req.CreatedBeforeUnix != 0 {
+		query = query.Filter(newElasticSearchRangeQuery("created_unix", req.CreatedAfterUnix, req.CreatedBeforeUnix))
+	}
+	if req.UpdatedAfterUnix != 0 || req.UpdatedBeforeUnix != 0 {
+		query = query.Filter(newElasticSearchRangeQuery("updated_unix", req.UpdatedAfterUnix, req.UpdatedBeforeUnix))
 	}
 	searchResult, err := b.client.Search().
 		Index(b.indexerName).
 		Query(query).
 		Sort("_score", false).
-		From(start).Size(limit).
+		From(req.Start).Size(req.Limit).
 		Do(ctx)
 	if err != nil {
 		return nil, b.checkError(err)
 	}
 
-	hits := make([]Match, 0, limit)
+	hits := make([]Match, 0, len(searchResult.Hits.Hits))
 	for _, hit := range searchResult.Hits.Hits {
 		id, _ := strconv.ParseInt(hit.Id, 10, 64)
 		hits = append(hits, Match{
@@ -24,13 +24,24 @@ import (
 
 // IndexerData data stored in the issue indexer
 type IndexerData struct {
-	ID       int64    `json:"id"`
-	RepoID   int64    `json:"repo_id"`
-	Title    string   `json:"title"`
-	Content  string   `json:"content"`
-	Comments []string `json:"comments"`
-	IsDelete bool     `json:"is_delete"`
-	IDs      []int64  `json:"ids"`
+	ID          int64    `json:"id"`
+	RepoID      int64    `json:"repo_id"`
+	Title       string   `json:"title"`
+	Content     string   `json:"content"`
+	Comments    []string `json:"comments"`
+	IsPull      bool     `json:"is_pull"`
+	IsClosed    bool     `json:"is_closed"`
+	LabelIDs    []int64  `json:"label_ids"`
+	NoLabel     bool     `json:"no_label"` // true if LabelIDs is empty
+	PosterID    int64    `json:"poster_id"`
+	AssigneeIDs []int64  `json:"assignee_ids"`
+	NoAssignee  bool     `json:"no_assignee"` // true if AssigneeIDs is empty
+	MentionIDs  []int64  `json:"mention_ids"`
+	MilestoneID int64    `json:"milestone_id"`
+	CreatedUnix int64    `json:"created_unix"`
+	UpdatedUnix int64    `json:"updated_unix"`
+	IsDelete    bool     `json:"is_delete"`
+	IDs         []int64  `json:"ids"`
 }
 
 // Match represents on search result
@@ -45,13 +56,21 @@ type SearchResult struct {
 	Hits  []Match
 }
 
+// SearchRequest represents a search of the indexer, the issues must match the keyword and the filter
+type SearchRequest struct {
+	Keyword string
+	issues_model.ResolvedIssueFilter
+	Limit int
+	Start int
+}
+
 // Indexer defines an interface to indexer issues contents
 type Indexer interface {
 	Init() (bool, error)
 	Ping() bool
 	Index(issue []*IndexerData) error
 	Delete(ids ...int64) error
-	Search(ctx context.Context, kw string, repoIDs []int64, limit, start int) (*SearchResult, error)
+	Search(ctx context.Context, req *SearchRequest) (*SearchResult, error)
 	Close()
 }
 
@@ -307,25 +326,67 @@ func UpdateRepoIndexer(ctx context.Context, repo *repo_model.Repository) {
 		log.Error("LoadDiscussComments: %v", err)
 		return
 	}
+	updateIssuesIndexer(ctx, is)
+}
+
+// UpdateIssueIndexer add/update an issue to the issue indexer
+func UpdateIssueIndexer(ctx context.Context, issue *issues_model.Issue) {
+	updateIssuesIndexer(ctx, []*issues_model.Issue{issue})
+}
+
+// updateIssuesIndexer add/update issues whose discussion comments are loaded to the issue indexer,
+// their labels and assignees are reloaded as they may just have changed
+func updateIssuesIndexer(ctx context.Context, is issues_model.IssueList) {
+	if err := is.LoadLabels(ctx); err != nil {
+		log.Error("LoadLabels: %v", err)
+		return
+	}
+	if err := is.LoadAssignees(ctx); err != nil {
+		log.Error("LoadAssignees: %v", err)
+		return
+	}
+	mentionIDs, err := is.GetMentionIDs(ctx)
+	if err != nil {
+		log.Error("GetMentionIDs: %v", err)
+		return
+	}
 	for _, issue := range is {
-		UpdateIssueIndexer(issue)
+		pushIssueIndexerData(issue, mentionIDs[issue.ID])
 	}
 }
 
-// UpdateIssueIndexer add/update an issue to the issue indexer
-func UpdateIssueIndexer(issue *issues_model.Issue) {
+func pushIssueIndexerData(issue *issues_model.Issue, mentionIDs []int64) {
 	var comments []string
 	for _, comment := range issue.Comments {
 		if comment.Type == issues_model.CommentTypeComment {
 			comments = append(comments, comment.Content)
 		}
 	}
+	labelIDs := make([]int64, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labelIDs = append(labelIDs, label.ID)
+	}
+	assigneeIDs := make([]int64, 0, len(issue.Assignees))
+	for _, assignee := range issue.Assignees {
+		assigneeIDs = append(assigneeIDs, assignee.ID)
+	}
 	indexerData := &IndexerData{
-		ID:       issue.ID,
-		RepoID:   issue.RepoID,
-		Title:    issue.Title,
-		Content:  issue.Content,
-		Comments: comments,
+		ID:          issue.ID,
+		RepoID:      issue.RepoID,
+		Title:       issue.Title,
+		Content:     issue.Content,
+		Comments:    comments,
+		IsPull:      issue.IsPull,
+		IsClosed:    issue.IsClosed,
+		LabelIDs:    labelIDs,
+		NoLabel:     len(labelIDs) == 0,
+		PosterID:    issue.PosterID,
+		AssigneeIDs: assigneeIDs,
+		NoAssignee:  len(assigneeIDs) == 0,
+		MentionIDs:  mentionIDs,
+		MilestoneID: issue.MilestoneID,
+		CreatedUnix: int64(issue.CreatedUnix),
+		UpdatedUnix: int64(issue.UpdatedUnix),
 	}
 	log.Debug("Adding to channel: %v", indexerData)
 	if err := issueIndexerQueue.Push(indexerData); err != nil {
@@ -357,21 +418,44 @@ func DeleteRepoIssueIndexer(ctx context.Context, repo *repo_model.Repository) {
 // SearchIssuesByKeyword search issue ids by keywords and repo id
 // WARNNING: You have to ensure user have permission to visit repoIDs' issues
 func SearchIssuesByKeyword(ctx context.Context, repoIDs []int64, keyword string) ([]int64, error) {
+	return SearchIssues(ctx, keyword, &issues_model.ResolvedIssueFilter{RepoIDs: repoIDs})
+}
+
+// searchPageSize is the number of issues requested at once from the indexer
+const searchPageSize = 1000
+
+// SearchIssues search the ids of all the issues matching the keyword and the filter
+// WARNNING: You have to ensure user have permission to visit the issues of the filter's repositories
+func SearchIssues(ctx context.Context, keyword string, filter *issues_model.ResolvedIssueFilter) ([]int64, error) {
 	var issueIDs []int64
-	indexer := holder.get()
+	if len(filter.RepoIDs) == 0 {
+		return issueIDs, nil
+	}
 
+	indexer := holder.get()
 	if indexer == nil {
-		log.Error("SearchIssuesByKeyword(): unable to get indexer!")
+		log.Error("SearchIssues(): unable to get indexer!")
 		return nil, fmt.Errorf("unable to get issue indexer")
 	}
-	res, err := indexer.Search(ctx, keyword, repoIDs, 50, 0)
-	if err != nil {
-		return nil, err
+
+	req := &SearchRequest{
+		Keyword:             keyword,
+		ResolvedIssueFilter: *filter,
+		Limit:               searchPageSize,
 	}
-	for _, r := range res.Hits {
-		issueIDs = append(issueIDs, r.ID)
+	for {
+		res, err := indexer.Search(ctx, req)
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range res.Hits {
+			issueIDs = append(issueIDs, r.ID)
+		}
+		if len(res.Hits) < req.Limit {
+			return issueIDs, nil
+		}
+		req.Start += len(res.Hits)
 	}
-	return issueIDs, nil
 }
 
 // IsAvailable checks if issue indexer is available
@@ -10,8 +10,10 @@ import (
 	"testing"
 	"time"
 
+	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 
 	_ "code.gitea.io/gitea/models"
 
@@ -88,4 +90,12 @@ func TestDBSearchIssues(t *testing.T) {
 	ids, err = SearchIssuesByKeyword(context.TODO(), []int64{1}, "good")
 	assert.NoError(t, err)
 	assert.EqualValues(t, []int64{1}, ids)
+
+	ids, err = SearchIssues(context.TODO(), "for", &issues_model.ResolvedIssueFilter{RepoIDs: []int64{1}, IsPull: util.OptionalBoolTrue})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []int64{2, 3, 11}, ids)
+
+	ids, err = SearchIssues(context.TODO(), "for", &issues_model.ResolvedIssueFilter{RepoIDs: []int64{1}, ExcludedPosterIDs: []int64{1}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, []int64{5}, ids)
 }
@@ -5,6 +5,7 @@ package issues
 
 import (
 	"context"
+	"fmt"
 	"strconv"
 	"strings"
 	"sync"
@@ -13,6 +14,9 @@ import (
 	"github.com/meilisearch/meilisearch-go"
 )
 
+// meiliIssueIndexerLatestVersion is part of the index name, a new index is created and populated when the filterable attributes change
+const meiliIssueIndexerLatestVersion = 1
+
 var _ Indexer = &MeilisearchIndexer{}
 
 // MeilisearchIndexer implements Indexer interface
@@ -33,7 +37,7 @@ func NewMeilisearchIndexer(url, apiKey, indexerName string) (*MeilisearchIndexer
 
 	indexer := &MeilisearchIndexer{
 		client:      client,
-		indexerName: indexerName,
+		indexerName: fmt.Sprintf("%s_v%d", indexerName, meiliIssueIndexerLatestVersion),
 		available:   true,
 		stopTimer:   make(chan struct{}),
 	}
@@ -68,7 +72,20 @@ func (b *MeilisearchIndexer) Init() (bool, error) {
 		return false, b.checkError(err)
 	}
 
-	_, err = b.client.Index(b.indexerName).UpdateFilterableAttributes(&[]string{"repo_id"})
+	_, err = b.client.Index(b.indexerName).UpdateFilterableAttributes(&[]string{
+		"repo_id",
+		"is_pull",
+		"is_closed",
+		"label_ids",
+		"no_label",
+		"poster_id",
+		"assignee_ids",
+		"no_assignee",
+		"mention_ids",
+		"milestone_id",
+		"created_unix",
+		"updated_unix",
+	})
 	return false, b.checkError(err)
 }
 
@@ -110,18 +127,78 @@ func (b *MeilisearchIndexer) Delete(ids ...int64) error {
 	return nil
 }
 
+// meilisearchAnyFilter returns the filter matching any of the values of the field
+func meilisearchAnyFilter(field string, values []int64) string {
+	filters := make([]string, 0, len(values))
+	for _, value := range values {
+		filters = append(filters, field+" = "+strconv.FormatInt(value, 10))
+	}
+	return "(" + strings.Join(filters, " OR ") + ")"
+}
+
 // Search searches for issues by given conditions.
 // Returns the matching issue IDs
-func (b *MeilisearchIndexer) Search(ctx context.Context, keyword string, repoIDs []int64, limit, start int) (*SearchResult, error) {
-	repoFilters := make([]string, 0, len(repoIDs))
-	for _, repoID := range repoIDs {
-		repoFilters = append(repoFilters, "repo_id = "+strconv.FormatInt(repoID, 10))
-	}
-	filter := strings.Join(repoFilters, " OR ")
-	searchRes, err := b.client.Index(b.indexerName).Search(keyword, &meilisearch.SearchRequest{
-		Filter: filter,
-		Limit:  int64(limit),
-		Offset: int64(start),
+func (b *MeilisearchIndexer) Search(ctx context.Context, req *SearchRequest) (*SearchResult, error) {
+	filters := []string{meilisearchAnyFilter("repo_id", req.RepoIDs)}
+	if !req.IsClosed.IsNone() {
+		filters = append(filters, "is_closed = "+strconv.FormatBool(req.IsClosed.IsTrue()))
+	}
+	if !req.IsPull.IsNone() {
+		filters = append(filters, "is_pull = "+strconv.FormatBool(req.IsPull.IsTrue()))
+	}
+	for _, labelIDs := range req.LabelIDs {
+		filters = append(filters, meilisearchAnyFilter("label_ids", labelIDs))
+	}
+	if len(req.ExcludedLabelIDs) > 0 {
+		filters = append(filters, "NOT "+meilisearchAnyFilter("label_ids", req.ExcludedLabelIDs))
+	}
+	if req.NoLabel {
+		filters = append(filters, "no_label = true")
+	}
+	if len(req.PosterIDs) > 0 {
+		filters = append(filters, meilisearchAnyFilter("poster_id", req.PosterIDs))
+	}
+	if len(req.ExcludedPosterIDs) > 0 {
+		filters = append(filters, "NOT "+meilisearchAnyFilter("poster_id", req.ExcludedPosterIDs))
+	}
+	if len(req.AssigneeIDs) > 0 {
+		filters = append(filters, meilisearchAnyFilter("assignee_ids", req.AssigneeIDs))
+	}
+	if len(req.ExcludedAssigneeIDs) > 0 {
+		filters = append(filters, "NOT "+meilisearchAnyFilter("assignee_ids", req.ExcludedAssigneeIDs))
+	}
+	if req.NoAssignee {
+		filters = append(filters, "no_assignee = true")
+	}
+	if len(req.MentionedIDs) > 0 {
+		filters = append(filters, meilisearchAnyFilter("mention_ids", req.MentionedIDs))
+	}
+	if len(req.MilestoneIDs) > 0 {
+		filters = append(filters, meilisearchAnyFilter("milestone_id", req.MilestoneIDs))
+	}
+	if len(req.ExcludedMilestoneIDs) > 0 {
+		filters = append(filters, "NOT "+meilisearchAnyFilter("milestone_id", req.ExcludedMilestoneIDs))
+	}
+	if req.NoMilestone {
+		filters = append(filters, "milestone_id = 0")
+	}
+	if req.CreatedAfterUnix != 0 {
+		filters = append(filters, "created_unix >= "+strconv.FormatInt(req.CreatedAfterUnix, 10))
+	}
+	if req.CreatedBeforeUnix != 0 {
+		filters = append(filters, "created_unix <= "+strconv.FormatInt(req.CreatedBeforeUnix, 10))
+	}
+	if req.UpdatedAfterUnix != 0 {
+		filters = append(filters, "updated_unix >= "+strconv.FormatInt(req.UpdatedAfterUnix, 10))
+	}
+	if req.UpdatedBeforeUnix != 0 {
+		filters = append(filters, "updated_unix <= "+strconv.FormatInt(req.UpdatedBeforeUnix, 10))
+	}
+
+	searchRes, err := b.client.Index(b.indexerName).Search(req.Keyword, &meilisearch.SearchRequest{
+		Filter: strings.Join(filters, " AND "),
+		Limit:  int64(req.Limit),
+		Offset: int64(req.Start),
 	})
 	if err != nil {
 		return nil, b.checkError(err)
@@ -0,0 +1,225 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues
+
+import (
+	"strings"
+	"time"
+	"unicode"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// SearchOptions represents an issue search parsed from a query. With a keyword, the filter is resolved and searched
+// by the issue indexer together with the keyword, without keyword it is applied by the database.
+type SearchOptions struct {
+	Keyword string
+	issues_model.IssueFilter
+}
+
+// queryToken is a word of a query, the quotes have been removed
+type queryToken struct {
+	text   string
+	quoted bool // the token starts with a quote, so it can't be a qualifier
+}
+
+// tokenizeQuery splits a query at the spaces which are not quoted
+func tokenizeQuery(query string) []queryToken {
+	var tokens []queryToken
+	var current strings.Builder
+	inToken, inQuotes, quoted := false, false, false
+	for _, r := range query {
+		switch {
+		case r == '"':
+			if !inToken {
+				quoted = true
+			}
+			inToken = true
+			inQuotes = !inQuotes
+		case unicode.IsSpace(r) && !inQuotes:
+			if inToken {
+				tokens = append(tokens, queryToken{text: current.String(), quoted: quoted})
+				current.Reset()
+			}
+			inToken, quoted = false, false
+		default:
+			inToken = true
+			current.WriteRune(r)
+		}
+	}
+	if inToken {
+		tokens = append(tokens, queryToken{text: current.String(), quoted: quoted})
+	}
+	return tokens
+}
+
+// parseQueryDate parses a date (in the default UI location) or an RFC 3339 time, it returns the first and the last second of it
+func parseQueryDate(value string) (int64, int64, error) {
+	if t, err := time.ParseInLocation("2006-01-02", value, setting.DefaultUILocation); err == nil {
+		return t.Unix(), t.AddDate(0, 0, 1).Unix() - 1, nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return 0, 0, util.NewInvalidArgumentErrorf("invalid date %q, expected YYYY-MM-DD", value)
+	}
+	return t.Unix(), t.Unix(), nil
+}
+
+// parseQueryDateRange parses `>DATE`, `>=DATE`, `<DATE`, `<=DATE`, `DATE`, `DATE..DATE`, `DATE..*` or `*..DATE`,
+// it returns the bounds of the range (included), 0 if a bound is not set
+func parseQueryDateRange(value string) (after, before int64, err error) {
+	var first, last int64
+	switch {
+	case strings.HasPrefix(value, ">="):
+		after, _, err = parseQueryDate(value[2:])
+	case strings.HasPrefix(value, ">"):
+		_, last, err = parseQueryDate(value[1:])
+		after = last + 1
+	case strings.HasPrefix(value, "<="):
+		_, before, err = parseQueryDate(value[2:])
+	case strings.HasPrefix(value, "<"):
+		first, _, err = parseQueryDate(value[1:])
+		before = first - 1
+	case strings.Contains(value, ".."):
+		from, to, _ := strings.Cut(value, "..")
+		if from != "*" {
+			if after, _, err = parseQueryDate(from); err != nil {
+				return 0, 0, err
+			}
+		}
+		if to != "*" {
+			_, before, err = parseQueryDate(to)
+		}
+	default:
+		after, before, err = parseQueryDate(value)
+	}
+	return after, before, err
+}
+
+func splitQueryNames(value string) []string {
+	names := strings.Split(value, ",")
+	res := names[:0]
+	for _, name := range names {
+		if name = strings.TrimSpace(name); name != "" {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// ParseSearchQuery parses a query made of keywords and of qualifiers, e.g.
+// `is:open label:bug author:alice repo:org/* created:>2024-01-01 -label:wontfix crash`.
+// The qualifiers are:
+//   - is:open, is:closed, is:issue, is:pr
+//   - label:NAME (a comma separated list of names matches any of them), author:USER, assignee:USER, milestone:NAME,
+//     repo:OWNER/NAME (`*` matches any characters), they are excluded when prefixed with `-`
+//   - mentions:USER
+//   - no:label, no:assignee, no:milestone
+//   - created:RANGE, updated:RANGE, where RANGE is `>DATE`, `>=DATE`, `<DATE`, `<=DATE`, `DATE`, `DATE..DATE`,
+//     `DATE..*` or `*..DATE` and DATE is YYYY-MM-DD
+//
+// The values containing spaces must be quoted, like `label:"good first issue"`. The unknown qualifiers are keywords.
+func ParseSearchQuery(query string) (*SearchOptions, error) {
+	opts := &SearchOptions{}
+	var keywords []string
+
+	for _, token := range tokenizeQuery(query) {
+		text := token.text
+		negated := !token.quoted && strings.HasPrefix(text, "-")
+		if negated {
+			text = text[1:]
+		}
+		key, value, ok := strings.Cut(text, ":")
+		if token.quoted || !ok || value == "" {
+			keywords = append(keywords, token.text)
+			continue
+		}
+		key = strings.ToLower(key)
+
+		switch key {
+		case "is":
+			switch strings.ToLower(value) {
+			case "open":
+				opts.IsClosed = util.OptionalBoolOf(negated)
+			case "closed":
+				opts.IsClosed = util.OptionalBoolOf(!negated)
+			case "issue":
+				opts.IsPull = util.OptionalBoolOf(negated)
+			case "pr", "pull":
+				opts.IsPull = util.OptionalBoolOf(!negated)
+			default:
+				return nil, util.NewInvalidArgumentErrorf("unknown qualifier value %q, expected open, closed, issue or pr", text)
+			}
+		case "no":
+			if negated {
+				return nil, util.NewInvalidArgumentErrorf("qualifier %q can't be excluded", key)
+			}
+			switch strings.ToLower(value) {
+			case "label":
+				opts.NoLabel = true
+			case "assignee":
+				opts.NoAssignee = true
+			case "milestone":
+				opts.NoMilestone = true
+			default:
+				return nil, util.NewInvalidArgumentErrorf("unknown qualifier value %q, expected label, assignee or milestone", text)
+			}
+		case "label":
+			if negated {
+				opts.ExcludedLabelNames = append(opts.ExcludedLabelNames, splitQueryNames(value)...)
+			} else if names := splitQueryNames(value); len(names) > 0 {
+				opts.LabelNames = append(opts.LabelNames, names)
+			}
+		case "author":
+			if negated {
+				opts.ExcludedPosterNames = append(opts.ExcludedPosterNames, value)
+			} else {
+				opts.PosterNames = append(opts.PosterNames, value)
+			}
+		case "assignee":
+			if negated {
+				opts.ExcludedAssigneeNames = append(opts.ExcludedAssigneeNames, value)
+			} else {
+				opts.AssigneeNames = append(opts.AssigneeNames, value)
+			}
+		case "mentions":
+			if negated {
+				return nil, util.NewInvalidArgumentErrorf("qualifier %q can't be excluded", key)
+			}
+			opts.MentionedNames = append(opts.MentionedNames, value)
+		case "milestone":
+			if negated {
+				opts.ExcludedMilestoneNames = append(opts.ExcludedMilestoneNames, value)
+			} else {
+				opts.MilestoneNames = append(opts.MilestoneNames, value)
+			}
+		case "repo":
+			if negated {
+				opts.ExcludedRepoNames = append(opts.ExcludedRepoNames, value)
+			} else {
+				opts.RepoNames = append(opts.RepoNames, value)
+			}
+		case "created", "updated":
+			if negated {
+				return nil, util.NewInvalidArgumentErrorf("qualifier %q can't be excluded", key)
+			}
+			after, before, err := parseQueryDateRange(value)
+			if err != nil {
+				return nil, err
+			}
+			if key == "created" {
+				opts.CreatedAfterUnix, opts.CreatedBeforeUnix = after, before
+			} else {
+				opts.UpdatedAfterUnix, opts.UpdatedBeforeUnix = after, before
+			}
+		default:
+			keywords = append(keywords, token.text)
+		}
+	}
+
+	opts.Keyword = strings.Join(keywords, " ")
+	return opts, nil
+}
@@ -0,0 +1,91 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package issues
+
+import (
+	"testing"
+	"time"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSearchQuery(t *testing.T) {
+	defer func(loc *time.Location) { setting.DefaultUILocation = loc }(setting.DefaultUILocation)
+	setting.DefaultUILocation = time.UTC
+
+	cases := map[string]*SearchOptions{
+		"":               {},
+		"crash on start": {Keyword: "crash on start"},
+		`is:open is:pr label:bug,regression label:"good first issue" crash`: {
+			Keyword: "crash",
+			IssueFilter: issues_model.IssueFilter{
+				IsClosed:   util.OptionalBoolFalse,
+				IsPull:     util.OptionalBoolTrue,
+				LabelNames: [][]string{{"bug", "regression"}, {"good first issue"}},
+			},
+		},
+		"-is:closed -is:pr no:assignee no:milestone no:label": {
+			IssueFilter: issues_model.IssueFilter{
+				IsClosed:    util.OptionalBoolFalse,
+				IsPull:      util.OptionalBoolFalse,
+				NoAssignee:  true,
+				NoMilestone: true,
+				NoLabel:     true,
+			},
+		},
+		"author:alice -author:bob assignee:carol -assignee:dave mentions:eve milestone:v1.0 -milestone:v2.0 -label:wontfix": {
+			IssueFilter: issues_model.IssueFilter{
+				PosterNames:            []string{"alice"},
+				ExcludedPosterNames:    []string{"bob"},
+				AssigneeNames:          []string{"carol"},
+				ExcludedAssigneeNames:  []string{"dave"},
+				MentionedNames:         []string{"eve"},
+				MilestoneNames:         []string{"v1.0"},
+				ExcludedMilestoneNames: []string{"v2.0"},
+				ExcludedLabelNames:     []string{"wontfix"},
+			},
+		},
+		"repo:org/* -repo:org/archive REPO:repo1": {
+			IssueFilter: issues_model.IssueFilter{
+				RepoNames:         []string{"org/*", "repo1"},
+				ExcludedRepoNames: []string{"org/archive"},
+			},
+		},
+		"created:>=2024-01-01 updated:<2024-01-01": {
+			IssueFilter: issues_model.IssueFilter{
+				CreatedAfterUnix:  1704067200,
+				UpdatedBeforeUnix: 1704067199,
+			},
+		},
+		"created:2024-01-01..* updated:2024-01-01": {
+			IssueFilter: issues_model.IssueFilter{
+				CreatedAfterUnix:  1704067200,
+				UpdatedAfterUnix:  1704067200,
+				UpdatedBeforeUnix: 1704153599,
+			},
+		},
+		"created:>2024-01-01 updated:*..2024-01-01": {
+			IssueFilter: issues_model.IssueFilter{
+				CreatedAfterUnix:  1704153600,
+				UpdatedBeforeUnix: 1704153599,
+			},
+		},
+		`"label:bug" http://example.com -flaky author:`: {Keyword: "label:bug http://example.com -flaky author:"},
+	}
+	for query, expected := range cases {
+		opts, err := ParseSearchQuery(query)
+		if assert.NoError(t, err, query) {
+			assert.Equal(t, expected, opts, query)
+		}
+	}
+
+	for _, query := range []string{"is:unknown", "no:author", "-no:label", "-mentions:alice", "created:yesterday", "updated:>2024-13-01", "-created:2024-01-01"} {
+		_, err := ParseSearchQuery(query)
+		assert.ErrorIs(t, err, util.ErrInvalidArgument, query)
+	}
+}
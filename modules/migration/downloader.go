@@ -6,6 +6,7 @@ package migration
 
 import (
 	"context"
+	"time"
 
 	"code.gitea.io/gitea/modules/structs"
 )
@@ -27,6 +28,13 @@ type Downloader interface {
 	FormatCloneURL(opts MigrateOptions, remoteAddr string) (string, error)
 }
 
+// IncrementalDownloader is implemented by the downloaders which can restrict the issues, pull requests and comments
+// they return to the ones updated since a time, the synchronization of a migrated repository uses it to only pull the changes.
+type IncrementalDownloader interface {
+	Downloader
+	SetUpdatedSince(since time.Time)
+}
+
 // DownloaderFactory defines an interface to match a downloader implementation and create a downloader
 type DownloaderFactory interface {
 	New(ctx context.Context, opts MigrateOptions) (Downloader, error)
@@ -38,4 +38,6 @@ type MigrateOptions struct {
 	ReleaseAssets   bool
 	MigrateToRepoID int64
 	MirrorInterval  string `json:"mirror_interval"`
+	// Sync keeps pulling the objects updated on the source after the migration
+	Sync bool `json:"sync"`
 }
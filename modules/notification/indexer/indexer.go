@@ -42,16 +42,16 @@ func (r *indexerNotifier) NotifyCreateIssueComment(ctx context.Context, doer *us
 			issue.Comments = append(issue.Comments, comment)
 		}
 
-		issue_indexer.UpdateIssueIndexer(issue)
+		issue_indexer.UpdateIssueIndexer(ctx, issue)
 	}
 }
 
 func (r *indexerNotifier) NotifyNewIssue(ctx context.Context, issue *issues_model.Issue, mentions []*user_model.User) {
-	issue_indexer.UpdateIssueIndexer(issue)
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
 }
 
 func (r *indexerNotifier) NotifyNewPullRequest(ctx context.Context, pr *issues_model.PullRequest, mentions []*user_model.User) {
-	issue_indexer.UpdateIssueIndexer(pr.Issue)
+	issue_indexer.UpdateIssueIndexer(ctx, pr.Issue)
 }
 
 func (r *indexerNotifier) NotifyUpdateComment(ctx context.Context, doer *user_model.User, c *issues_model.Comment, oldContent string) {
@@ -74,7 +74,7 @@ func (r *indexerNotifier) NotifyUpdateComment(ctx context.Context, doer *user_mo
 			}
 		}
 
-		issue_indexer.UpdateIssueIndexer(c.Issue)
+		issue_indexer.UpdateIssueIndexer(ctx, c.Issue)
 	}
 }
 
@@ -103,7 +103,7 @@ func (r *indexerNotifier) NotifyDeleteComment(ctx context.Context, doer *user_mo
 			}
 		}
 		// reload comments to delete the old comment
-		issue_indexer.UpdateIssueIndexer(comment.Issue)
+		issue_indexer.UpdateIssueIndexer(ctx, comment.Issue)
 	}
 }
 
@@ -151,13 +151,60 @@ func (r *indexerNotifier) NotifySyncPushCommits(ctx context.Context, pusher *use
 }
 
 func (r *indexerNotifier) NotifyIssueChangeContent(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, oldContent string) {
-	issue_indexer.UpdateIssueIndexer(issue)
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
 }
 
 func (r *indexerNotifier) NotifyIssueChangeTitle(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, oldTitle string) {
-	issue_indexer.UpdateIssueIndexer(issue)
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
 }
 
 func (r *indexerNotifier) NotifyIssueChangeRef(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, oldRef string) {
-	issue_indexer.UpdateIssueIndexer(issue)
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
+}
+
+// updateIssueIndexer reindexes an issue whose state, labels, assignees or milestone changed
+func updateIssueIndexer(ctx context.Context, issue *issues_model.Issue) {
+	if issue.Comments == nil {
+		if err := issue.LoadDiscussComments(ctx); err != nil {
+			log.Error("LoadDiscussComments failed: %v", err)
+			return
+		}
+	}
+	issue_indexer.UpdateIssueIndexer(ctx, issue)
+}
+
+func (r *indexerNotifier) NotifyIssueChangeStatus(ctx context.Context, doer *user_model.User, commitID string, issue *issues_model.Issue, actionComment *issues_model.Comment, closeOrReopen bool) {
+	updateIssueIndexer(ctx, issue)
+}
+
+func (r *indexerNotifier) NotifyIssueChangeMilestone(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, oldMilestoneID int64) {
+	updateIssueIndexer(ctx, issue)
+}
+
+func (r *indexerNotifier) NotifyIssueChangeAssignee(ctx context.Context, doer *user_model.User, issue *issues_model.Issue, assignee *user_model.User, removed bool, comment *issues_model.Comment) {
+	updateIssueIndexer(ctx, issue)
+}
+
+func (r *indexerNotifier) NotifyIssueClearLabels(ctx context.Context, doer *user_model.User, issue *issues_model.Issue) {
+	updateIssueIndexer(ctx, issue)
+}
+
+func (r *indexerNotifier) NotifyIssueChangeLabels(ctx context.Context, doer *user_model.User, issue *issues_model.Issue,
+	addedLabels, removedLabels []*issues_model.Label,
+) {
+	updateIssueIndexer(ctx, issue)
+}
+
+func (r *indexerNotifier) NotifyMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	// the issue of the pull request has been closed by the merge
+	issue, err := issues_model.GetIssueByID(ctx, pr.IssueID)
+	if err != nil {
+		log.Error("GetIssueByID: %v", err)
+		return
+	}
+	updateIssueIndexer(ctx, issue)
+}
+
+func (r *indexerNotifier) NotifyAutoMergePullRequest(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	r.NotifyMergePullRequest(ctx, doer, pr)
 }
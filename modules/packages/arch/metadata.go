@@ -0,0 +1,274 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package arch
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	PropertyRepository   = "arch.repository"
+	PropertyArchitecture = "arch.architecture"
+	PropertyMetadata     = "arch.metadata"
+	PropertyFiles        = "arch.files"
+	PropertySignature    = "arch.signature"
+
+	SettingKeyPrivate = "arch.key.private"
+	SettingKeyPublic  = "arch.key.public"
+
+	RepositoryPackage = "_arch"
+	RepositoryVersion = "_repository"
+
+	// AnyArchitecture is the architecture of the packages which can be installed on every architecture
+	AnyArchitecture = "any"
+)
+
+var (
+	ErrMissingPKGINFOFile     = util.NewInvalidArgumentErrorf(".PKGINFO file is missing")
+	ErrUnsupportedCompression = util.NewInvalidArgumentErrorf("unsupported compression algorithm")
+	ErrInvalidName            = util.NewInvalidArgumentErrorf("package name is invalid")
+	ErrInvalidVersion         = util.NewInvalidArgumentErrorf("package version is invalid")
+	ErrInvalidArchitecture    = util.NewInvalidArgumentErrorf("package architecture is invalid")
+
+	// https://wiki.archlinux.org/title/PKGBUILD#pkgname
+	namePattern = regexp.MustCompile(`\A[a-zA-Z0-9@_+][a-zA-Z0-9@._+-]*\z`)
+	// https://wiki.archlinux.org/title/PKGBUILD#Version, epoch:pkgver-pkgrel
+	versionPattern = regexp.MustCompile(`\A(?:[0-9]+:)?[^:/\-\s]+-[0-9]+(?:\.[0-9]+)?\z`)
+	// https://wiki.archlinux.org/title/PKGBUILD#arch
+	architecturePattern = regexp.MustCompile(`\A[a-zA-Z0-9_]+\z`)
+)
+
+// https://man.archlinux.org/man/PKGBUILD.5
+
+// Package represents an Arch package
+type Package struct {
+	Name            string
+	Version         string
+	VersionMetadata VersionMetadata
+	FileMetadata    FileMetadata
+	// FileExtension is the extension of the package file, it depends on the compression, e.g. .pkg.tar.zst
+	FileExtension string
+	// Files are the paths of the files and directories installed by the package, the directories end with a slash
+	Files []string
+}
+
+// Metadata of an Arch package
+type VersionMetadata struct {
+	Base        string   `json:"base,omitempty"`
+	Description string   `json:"description,omitempty"`
+	ProjectURL  string   `json:"project_url,omitempty"`
+	Licenses    []string `json:"licenses,omitempty"`
+	Groups      []string `json:"groups,omitempty"`
+}
+
+type FileMetadata struct {
+	Architecture  string   `json:"architecture"`
+	InstalledSize int64    `json:"installed_size,omitempty"`
+	BuildDate     int64    `json:"build_date,omitempty"`
+	Packager      string   `json:"packager,omitempty"`
+	Provides      []string `json:"provides,omitempty"`
+	Dependencies  []string `json:"dependencies,omitempty"`
+	OptDepends    []string `json:"opt_depends,omitempty"`
+	MakeDepends   []string `json:"make_depends,omitempty"`
+	CheckDepends  []string `json:"check_depends,omitempty"`
+	Conflicts     []string `json:"conflicts,omitempty"`
+	Replaces      []string `json:"replaces,omitempty"`
+	Backup        []string `json:"backup,omitempty"`
+}
+
+// ParsePackage parses the Arch package file, a tar archive compressed with zstd, xz or gzip
+// https://wiki.archlinux.org/title/Arch_package_guidelines
+func ParsePackage(r io.Reader) (*Package, error) {
+	br := bufio.NewReader(r)
+
+	magic, err := br.Peek(6)
+	if err != nil {
+		return nil, err
+	}
+
+	var inner io.Reader
+	var extension string
+	switch {
+	case bytes.HasPrefix(magic, []byte{0x28, 0xb5, 0x2f, 0xfd}):
+		zr, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer zr.Close()
+
+		inner, extension = zr, ".pkg.tar.zst"
+	case bytes.HasPrefix(magic, []byte{0xfd, '7', 'z', 'X', 'Z', 0x00}):
+		xzr, err := xz.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+
+		inner, extension = xzr, ".pkg.tar.xz"
+	case bytes.HasPrefix(magic, []byte{0x1f, 0x8b}):
+		gzr, err := gzip.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer gzr.Close()
+
+		inner, extension = gzr, ".pkg.tar.gz"
+	default:
+		return nil, ErrUnsupportedCompression
+	}
+
+	var p *Package
+	files := make([]string, 0, 10)
+
+	tr := tar.NewReader(inner)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(hd.Name, "./")
+		if name == ".PKGINFO" {
+			p, err = ParsePackageInfo(tr)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		// .BUILDINFO, .MTREE, .INSTALL ... are not installed
+		if name == "" || strings.HasPrefix(name, ".") {
+			continue
+		}
+		if hd.Typeflag == tar.TypeDir && !strings.HasSuffix(name, "/") {
+			name += "/"
+		}
+		files = append(files, name)
+	}
+
+	if p == nil {
+		return nil, ErrMissingPKGINFOFile
+	}
+
+	p.FileExtension = extension
+	p.Files = files
+
+	return p, nil
+}
+
+// ParsePackageInfo parses a .PKGINFO file to retrieve the metadata of an Arch package
+// https://wiki.archlinux.org/title/Makepkg#.PKGINFO
+func ParsePackageInfo(r io.Reader) (*Package, error) {
+	p := &Package{}
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		key = strings.TrimSpace(key)
+		value = strings.TrimSpace(value)
+
+		switch key {
+		case "pkgname":
+			p.Name = value
+		case "pkgbase":
+			p.VersionMetadata.Base = value
+		case "pkgver":
+			p.Version = value
+		case "pkgdesc":
+			p.VersionMetadata.Description = value
+		case "url":
+			p.VersionMetadata.ProjectURL = value
+		case "builddate":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				p.FileMetadata.BuildDate = n
+			}
+		case "packager":
+			p.FileMetadata.Packager = value
+		case "size":
+			n, err := strconv.ParseInt(value, 10, 64)
+			if err == nil {
+				p.FileMetadata.InstalledSize = n
+			}
+		case "arch":
+			p.FileMetadata.Architecture = value
+		default:
+			if value == "" {
+				continue
+			}
+			switch key {
+			case "license":
+				p.VersionMetadata.Licenses = append(p.VersionMetadata.Licenses, value)
+			case "group":
+				p.VersionMetadata.Groups = append(p.VersionMetadata.Groups, value)
+			case "provides":
+				p.FileMetadata.Provides = append(p.FileMetadata.Provides, value)
+			case "depend":
+				p.FileMetadata.Dependencies = append(p.FileMetadata.Dependencies, value)
+			case "optdepend":
+				p.FileMetadata.OptDepends = append(p.FileMetadata.OptDepends, value)
+			case "makedepend":
+				p.FileMetadata.MakeDepends = append(p.FileMetadata.MakeDepends, value)
+			case "checkdepend":
+				p.FileMetadata.CheckDepends = append(p.FileMetadata.CheckDepends, value)
+			case "conflict":
+				p.FileMetadata.Conflicts = append(p.FileMetadata.Conflicts, value)
+			case "replaces":
+				p.FileMetadata.Replaces = append(p.FileMetadata.Replaces, value)
+			case "backup":
+				p.FileMetadata.Backup = append(p.FileMetadata.Backup, value)
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return nil, ErrInvalidName
+	}
+
+	if !versionPattern.MatchString(p.Version) {
+		return nil, ErrInvalidVersion
+	}
+
+	if !architecturePattern.MatchString(p.FileMetadata.Architecture) {
+		return nil, ErrInvalidArchitecture
+	}
+
+	if p.VersionMetadata.Base == "" {
+		p.VersionMetadata.Base = p.Name
+	}
+
+	if !validation.IsValidURL(p.VersionMetadata.ProjectURL) {
+		p.VersionMetadata.ProjectURL = ""
+	}
+
+	return p, nil
+}
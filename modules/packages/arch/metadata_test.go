@@ -0,0 +1,185 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package arch
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	packageName         = "gitea"
+	packageVersion      = "1:1.0.1-2"
+	packageArchitecture = "x86_64"
+	packager            = "KN4CK3R <kn4ck3r@gitea.io>"
+	description         = "Package description"
+	projectURL          = "https://gitea.io"
+)
+
+func createPKGINFO(name, version, architecture string) string {
+	return `# Generated by makepkg 6.0.2
+pkgname = ` + name + `
+pkgbase = gitea-base
+pkgver = ` + version + `
+pkgdesc = ` + description + `
+url = ` + projectURL + `
+builddate = 1678834800
+packager = ` + packager + `
+size = 1024
+arch = ` + architecture + `
+license = MIT
+license = Apache-2.0
+group = gitea-group
+provides = gitea-server
+depend = git
+depend = glibc>=2.37
+optdepend = openssh: for ssh access
+makedepend = go
+conflict = gitea-git
+replaces = gitea-old
+backup = etc/gitea/app.ini
+`
+}
+
+func createTar(files map[string]string) []byte {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	for _, name := range []string{".PKGINFO", ".MTREE", "usr/", "usr/bin/", "usr/bin/gitea"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(content)),
+		}
+		if strings.HasSuffix(name, "/") {
+			hdr.Typeflag = tar.TypeDir
+			hdr.Size = 0
+		}
+		tw.WriteHeader(hdr)
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	return buf.Bytes()
+}
+
+func TestParsePackage(t *testing.T) {
+	files := map[string]string{
+		".PKGINFO":      createPKGINFO(packageName, packageVersion, packageArchitecture),
+		".MTREE":        "",
+		"usr/":          "",
+		"usr/bin/":      "",
+		"usr/bin/gitea": "binary",
+	}
+	content := createTar(files)
+
+	t.Run("MissingPKGINFOFile", func(t *testing.T) {
+		var buf bytes.Buffer
+		zw, _ := zstd.NewWriter(&buf)
+		zw.Write(createTar(map[string]string{"usr/bin/gitea": "binary"}))
+		zw.Close()
+
+		p, err := ParsePackage(&buf)
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrMissingPKGINFOFile)
+	})
+
+	t.Run("UnsupportedCompression", func(t *testing.T) {
+		p, err := ParsePackage(bytes.NewReader(content))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrUnsupportedCompression)
+	})
+
+	compress := map[string]func(w io.Writer) io.WriteCloser{
+		".pkg.tar.zst": func(w io.Writer) io.WriteCloser {
+			zw, _ := zstd.NewWriter(w)
+			return zw
+		},
+		".pkg.tar.xz": func(w io.Writer) io.WriteCloser {
+			xw, _ := xz.NewWriter(w)
+			return xw
+		},
+		".pkg.tar.gz": func(w io.Writer) io.WriteCloser {
+			return gzip.NewWriter(w)
+		},
+	}
+	for extension, newWriter := range compress {
+		t.Run(extension, func(t *testing.T) {
+			var buf bytes.Buffer
+			w := newWriter(&buf)
+			w.Write(content)
+			w.Close()
+
+			p, err := ParsePackage(&buf)
+			assert.NoError(t, err)
+			assert.NotNil(t, p)
+
+			assert.Equal(t, packageName, p.Name)
+			assert.Equal(t, packageVersion, p.Version)
+			assert.Equal(t, extension, p.FileExtension)
+			assert.Equal(t, []string{"usr/", "usr/bin/", "usr/bin/gitea"}, p.Files)
+		})
+	}
+}
+
+func TestParsePackageInfo(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "-gitea", ".gitea", "gi tea"} {
+			p, err := ParsePackageInfo(strings.NewReader(createPKGINFO(name, packageVersion, packageArchitecture)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "1.0.0", "1.0-0-1", "a:1.0-1", "1.0/1-1"} {
+			p, err := ParsePackageInfo(strings.NewReader(createPKGINFO(packageName, version, packageArchitecture)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("InvalidArchitecture", func(t *testing.T) {
+		for _, architecture := range []string{"", "x86-64", "../any"} {
+			p, err := ParsePackageInfo(strings.NewReader(createPKGINFO(packageName, packageVersion, architecture)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidArchitecture)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		p, err := ParsePackageInfo(strings.NewReader(createPKGINFO(packageName, packageVersion, packageArchitecture)))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, "gitea-base", p.VersionMetadata.Base)
+		assert.Equal(t, description, p.VersionMetadata.Description)
+		assert.Equal(t, projectURL, p.VersionMetadata.ProjectURL)
+		assert.Equal(t, []string{"MIT", "Apache-2.0"}, p.VersionMetadata.Licenses)
+		assert.Equal(t, []string{"gitea-group"}, p.VersionMetadata.Groups)
+		assert.Equal(t, packageArchitecture, p.FileMetadata.Architecture)
+		assert.EqualValues(t, 1024, p.FileMetadata.InstalledSize)
+		assert.EqualValues(t, 1678834800, p.FileMetadata.BuildDate)
+		assert.Equal(t, packager, p.FileMetadata.Packager)
+		assert.Equal(t, []string{"gitea-server"}, p.FileMetadata.Provides)
+		assert.Equal(t, []string{"git", "glibc>=2.37"}, p.FileMetadata.Dependencies)
+		assert.Equal(t, []string{"openssh: for ssh access"}, p.FileMetadata.OptDepends)
+		assert.Equal(t, []string{"go"}, p.FileMetadata.MakeDepends)
+		assert.Equal(t, []string{"gitea-git"}, p.FileMetadata.Conflicts)
+		assert.Equal(t, []string{"gitea-old"}, p.FileMetadata.Replaces)
+		assert.Equal(t, []string{"etc/gitea/app.ini"}, p.FileMetadata.Backup)
+	})
+}
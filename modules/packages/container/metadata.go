@@ -21,6 +21,8 @@ const (
 	PropertyMediaType         = "container.mediatype"
 	PropertyManifestTagged    = "container.manifest.tagged"
 	PropertyManifestReference = "container.manifest.reference"
+	PropertyManifestSubject   = "container.manifest.subject"
+	PropertyArtifactType      = "container.artifacttype"
 
 	DefaultPlatform = "linux/amd64"
 
@@ -39,6 +41,30 @@ const (
 	TypeHelm ImageType = "helm"
 )
 
+type ArtifactKind string
+
+const (
+	ArtifactKindSignature   ArtifactKind = "signature"
+	ArtifactKindAttestation ArtifactKind = "attestation"
+	ArtifactKindSBOM        ArtifactKind = "sbom"
+	ArtifactKindOther       ArtifactKind = "other"
+)
+
+// GetArtifactKind gets the kind of an artifact attached to another manifest from its artifact type
+func GetArtifactKind(artifactType string) ArtifactKind {
+	at := strings.ToLower(artifactType)
+	switch {
+	case strings.Contains(at, "cosign.artifact.sig"), strings.Contains(at, "notary.signature"), strings.Contains(at, "sigstore.bundle"):
+		return ArtifactKindSignature
+	case strings.Contains(at, "sbom"), strings.Contains(at, "spdx"), strings.Contains(at, "cyclonedx"), strings.Contains(at, "syft"):
+		return ArtifactKindSBOM
+	case strings.Contains(at, "in-toto"), strings.Contains(at, "dsse"), strings.Contains(at, "attestation"):
+		return ArtifactKindAttestation
+	default:
+		return ArtifactKindOther
+	}
+}
+
 // Name gets the name of the image type
 func (it ImageType) Name() string {
 	switch it {
@@ -63,6 +89,7 @@ type Metadata struct {
 	Labels           map[string]string `json:"labels,omitempty"`
 	ImageLayers      []string          `json:"layer_creation,omitempty"`
 	Manifests        []*Manifest       `json:"manifests,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
 }
 
 type Manifest struct {
@@ -78,7 +105,18 @@ func ParseImageConfig(mt string, r io.Reader) (*Metadata, error) {
 	}
 
 	// fallback to OCI Image Config
-	return parseOCIImageConfig(r)
+	metadata, err := parseOCIImageConfig(r)
+	if err != nil && !isImageConfigMediaType(mt) {
+		// Artifacts like signatures or SBOMs may use any config (often the empty JSON descriptor)
+		return &Metadata{
+			Type: TypeOCI,
+		}, nil
+	}
+	return metadata, err
+}
+
+func isImageConfigMediaType(mt string) bool {
+	return strings.EqualFold(mt, oci.MediaTypeImageConfig) || strings.EqualFold(mt, "application/vnd.docker.container.image.v1+json")
 }
 
 func parseOCIImageConfig(r io.Reader) (*Metadata, error) {
@@ -59,3 +59,31 @@ func TestParseImageConfig(t *testing.T) {
 	assert.Equal(t, projectURL, metadata.ProjectURL)
 	assert.Equal(t, repositoryURL, metadata.RepositoryURL)
 }
+
+func TestParseImageConfigArtifact(t *testing.T) {
+	metadata, err := ParseImageConfig("application/vnd.dev.example.config.v1", strings.NewReader("not json"))
+	assert.NoError(t, err)
+	assert.Equal(t, TypeOCI, metadata.Type)
+	assert.Empty(t, metadata.Platform)
+
+	_, err = ParseImageConfig(oci.MediaTypeImageConfig, strings.NewReader("not json"))
+	assert.Error(t, err)
+}
+
+func TestGetArtifactKind(t *testing.T) {
+	cases := map[string]ArtifactKind{
+		"application/vnd.dev.cosign.artifact.sig.v1+json":  ArtifactKindSignature,
+		"application/vnd.cncf.notary.signature":            ArtifactKindSignature,
+		"application/vnd.dev.sigstore.bundle.v0.3+json":    ArtifactKindSignature,
+		"application/vnd.dev.cosign.artifact.sbom.v1+json": ArtifactKindSBOM,
+		"application/spdx+json":                            ArtifactKindSBOM,
+		"application/vnd.cyclonedx+json":                   ArtifactKindSBOM,
+		"application/vnd.in-toto+json":                     ArtifactKindAttestation,
+		"application/vnd.dsse.envelope.v1+json":            ArtifactKindAttestation,
+		"application/vnd.example.readme":                   ArtifactKindOther,
+		"":                                                 ArtifactKindOther,
+	}
+	for artifactType, expected := range cases {
+		assert.Equal(t, expected, GetArtifactKind(artifactType), artifactType)
+	}
+}
@@ -193,45 +193,18 @@ func ParsePackage(r io.Reader) (*Package, error) {
 			return nil, ErrInvalidPackageVersion
 		}
 
-		scope := ""
-		name := meta.Name
-		nameParts := strings.SplitN(meta.Name, "/", 2)
-		if len(nameParts) == 2 {
-			scope = nameParts[0]
-			name = nameParts[1]
-		}
-
-		if !validation.IsValidURL(meta.Homepage) {
-			meta.Homepage = ""
-		}
-
 		p := &Package{
 			Name:     meta.Name,
 			Version:  v.String(),
 			DistTags: make([]string, 0, 1),
-			Metadata: Metadata{
-				Scope:                   scope,
-				Name:                    name,
-				Description:             meta.Description,
-				Author:                  meta.Author.Name,
-				License:                 meta.License,
-				ProjectURL:              meta.Homepage,
-				Keywords:                meta.Keywords,
-				Dependencies:            meta.Dependencies,
-				DevelopmentDependencies: meta.DevDependencies,
-				PeerDependencies:        meta.PeerDependencies,
-				OptionalDependencies:    meta.OptionalDependencies,
-				Bin:                     meta.Bin,
-				Readme:                  meta.Readme,
-				Repository:              meta.Repository,
-			},
+			Metadata: NewMetadata(meta),
 		}
 
 		for tag := range upload.DistTags {
 			p.DistTags = append(p.DistTags, tag)
 		}
 
-		p.Filename = strings.ToLower(fmt.Sprintf("%s-%s.tgz", name, p.Version))
+		p.Filename = strings.ToLower(fmt.Sprintf("%s-%s.tgz", p.Metadata.Name, p.Version))
 
 		attachment := func() *PackageAttachment {
 			for _, a := range upload.Attachments {
@@ -276,6 +249,84 @@ func ParsePackage(r io.Reader) (*Package, error) {
 	return nil, ErrInvalidPackage
 }
 
+// NewMetadata creates the metadata of a package version
+func NewMetadata(meta *PackageMetadataVersion) Metadata {
+	scope := ""
+	name := meta.Name
+	nameParts := strings.SplitN(meta.Name, "/", 2)
+	if len(nameParts) == 2 {
+		scope = nameParts[0]
+		name = nameParts[1]
+	}
+
+	projectURL := meta.Homepage
+	if !validation.IsValidURL(projectURL) {
+		projectURL = ""
+	}
+
+	return Metadata{
+		Scope:                   scope,
+		Name:                    name,
+		Description:             meta.Description,
+		Author:                  meta.Author.Name,
+		License:                 meta.License,
+		ProjectURL:              projectURL,
+		Keywords:                meta.Keywords,
+		Dependencies:            meta.Dependencies,
+		DevelopmentDependencies: meta.DevDependencies,
+		PeerDependencies:        meta.PeerDependencies,
+		OptionalDependencies:    meta.OptionalDependencies,
+		Bin:                     meta.Bin,
+		Readme:                  meta.Readme,
+		Repository:              meta.Repository,
+	}
+}
+
+// ParsePackageMetadata parses the package document served by a npm registry.
+// Versions which can't be parsed (for example because of legacy field formats) are skipped.
+func ParsePackageMetadata(r io.Reader) (*PackageMetadata, error) {
+	var doc struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description"`
+		DistTags    map[string]string      `json:"dist-tags"`
+		Versions    map[string]interface{} `json:"versions"`
+	}
+	if err := json.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+
+	if !validateName(doc.Name) {
+		return nil, ErrInvalidPackageName
+	}
+
+	pm := &PackageMetadata{
+		ID:          doc.Name,
+		Name:        doc.Name,
+		Description: doc.Description,
+		DistTags:    doc.DistTags,
+		Versions:    make(map[string]*PackageMetadataVersion, len(doc.Versions)),
+	}
+
+	for _, raw := range doc.Versions {
+		data, err := json.Marshal(raw)
+		if err != nil {
+			continue
+		}
+		var pmv PackageMetadataVersion
+		if err := json.Unmarshal(data, &pmv); err != nil {
+			continue
+		}
+		v, err := version.NewSemver(pmv.Version)
+		if err != nil || pmv.Name != doc.Name {
+			continue
+		}
+		pmv.Version = v.String()
+		pm.Versions[pmv.Version] = &pmv
+	}
+
+	return pm, nil
+}
+
 func validateName(name string) bool {
 	if strings.TrimSpace(name) != name {
 		return false
@@ -0,0 +1,107 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pypi
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// IndexFile is a distribution file listed on the project page of a simple repository
+type IndexFile struct {
+	Filename       string
+	URL            string
+	Version        string
+	SHA256         string
+	RequiresPython string
+}
+
+var sdistExtensions = []string{".tar.gz", ".tar.bz2", ".tar.xz", ".tgz", ".tar", ".zip"}
+
+// ParseSimpleIndex parses the project page of a simple repository
+// https://peps.python.org/pep-0503/
+// Relative links are resolved against the url of the page. Files without a recognizable version are skipped.
+func ParseSimpleIndex(r io.Reader, pageURL *url.URL) ([]*IndexFile, error) {
+	files := make([]*IndexFile, 0, 10)
+
+	z := html.NewTokenizer(r)
+	for {
+		switch z.Next() {
+		case html.ErrorToken:
+			if z.Err() == io.EOF {
+				return files, nil
+			}
+			return nil, z.Err()
+		case html.StartTagToken:
+			t := z.Token()
+			if t.Data != "a" {
+				continue
+			}
+
+			f := &IndexFile{}
+			for _, attr := range t.Attr {
+				switch attr.Key {
+				case "href":
+					f.URL = attr.Val
+				case "data-requires-python":
+					f.RequiresPython = attr.Val
+				}
+			}
+
+			link, err := pageURL.Parse(f.URL)
+			if err != nil {
+				continue
+			}
+			if algorithm, hash, ok := strings.Cut(link.Fragment, "="); ok && algorithm == "sha256" {
+				f.SHA256 = strings.ToLower(hash)
+			}
+			link.Fragment = ""
+			f.URL = link.String()
+
+			f.Filename = link.Path[strings.LastIndex(link.Path, "/")+1:]
+			if unescaped, err := url.PathUnescape(f.Filename); err == nil {
+				f.Filename = unescaped
+			}
+
+			f.Version = ParseFilenameVersion(f.Filename)
+			if f.Version == "" {
+				continue
+			}
+
+			files = append(files, f)
+		}
+	}
+}
+
+// ParseFilenameVersion returns the version of a wheel, egg or source distribution file name or an empty string
+func ParseFilenameVersion(filename string) string {
+	lower := strings.ToLower(filename)
+
+	// {name}-{version}(-{build tag})?-{python tag}-{abi tag}-{platform tag}.whl
+	// {name}-{version}(-{python tag})?.egg
+	if strings.HasSuffix(lower, ".whl") || strings.HasSuffix(lower, ".egg") {
+		parts := strings.Split(filename[:len(filename)-4], "-")
+		if len(parts) < 2 {
+			return ""
+		}
+		return parts[1]
+	}
+
+	// {name}-{version}.tar.gz
+	for _, ext := range sdistExtensions {
+		if strings.HasSuffix(lower, ext) {
+			base := filename[:len(filename)-len(ext)]
+			pos := strings.LastIndex(base, "-")
+			if pos <= 0 {
+				return ""
+			}
+			return base[pos+1:]
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,59 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pypi
+
+import (
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSimpleIndex(t *testing.T) {
+	page := `<!DOCTYPE html>
+<html>
+	<body>
+		<h1>Links for test-package</h1>
+		<a href="../../packages/ab/cd/test_package-1.0.0-py3-none-any.whl#sha256=ABCDEF" data-requires-python="&gt;=3.7">test_package-1.0.0-py3-none-any.whl</a><br>
+		<a href="https://files.example.com/test-package-1.0.1.tar.gz#sha256=123456">test-package-1.0.1.tar.gz</a><br>
+		<a href="https://files.example.com/test-package.exe">test-package.exe</a><br>
+	</body>
+</html>`
+
+	pageURL, _ := url.Parse("https://pypi.example.com/simple/test-package/")
+
+	files, err := ParseSimpleIndex(strings.NewReader(page), pageURL)
+	assert.NoError(t, err)
+	assert.Len(t, files, 2)
+
+	assert.Equal(t, "test_package-1.0.0-py3-none-any.whl", files[0].Filename)
+	assert.Equal(t, "https://pypi.example.com/packages/ab/cd/test_package-1.0.0-py3-none-any.whl", files[0].URL)
+	assert.Equal(t, "1.0.0", files[0].Version)
+	assert.Equal(t, "abcdef", files[0].SHA256)
+	assert.Equal(t, ">=3.7", files[0].RequiresPython)
+
+	assert.Equal(t, "test-package-1.0.1.tar.gz", files[1].Filename)
+	assert.Equal(t, "https://files.example.com/test-package-1.0.1.tar.gz", files[1].URL)
+	assert.Equal(t, "1.0.1", files[1].Version)
+	assert.Equal(t, "123456", files[1].SHA256)
+	assert.Empty(t, files[1].RequiresPython)
+}
+
+func TestParseFilenameVersion(t *testing.T) {
+	cases := map[string]string{
+		"test_package-1.0.0-py3-none-any.whl":        "1.0.0",
+		"test_package-1.0.0-1-py3-none-any.whl":      "1.0.0",
+		"test_package-1.0.0rc1-py2.py3-none-any.whl": "1.0.0rc1",
+		"test_package-2.0-py3.9.egg":                 "2.0",
+		"test-package-1.0.1.tar.gz":                  "1.0.1",
+		"test_package-1.0.post1.zip":                 "1.0.post1",
+		"test.tar.gz":                                "",
+		"test-package-1.0.1.exe":                     "",
+	}
+
+	for filename, version := range cases {
+		assert.Equal(t, version, ParseFilenameVersion(filename), filename)
+	}
+}
@@ -0,0 +1,149 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package terraform
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/hashicorp/go-version"
+)
+
+const (
+	KindModule   = "module"
+	KindProvider = "provider"
+
+	PropertyOS           = "terraform.os"
+	PropertyArchitecture = "terraform.architecture"
+
+	SettingKeyPrivate = "terraform.key.private"
+	SettingKeyPublic  = "terraform.key.public"
+
+	// DefaultProtocol is the plugin protocol version of providers built with the Terraform Plugin SDK v2
+	DefaultProtocol = "5.0"
+
+	maxReadmeFileSize = 512 * 1024
+)
+
+var (
+	ErrInvalidName     = util.NewInvalidArgumentErrorf("package name is invalid")
+	ErrInvalidVersion  = util.NewInvalidArgumentErrorf("package version is invalid")
+	ErrInvalidPlatform = util.NewInvalidArgumentErrorf("platform is invalid")
+	ErrInvalidProtocol = util.NewInvalidArgumentErrorf("protocol version is invalid")
+	ErrInvalidArchive  = util.NewInvalidArgumentErrorf("archive is invalid")
+)
+
+var (
+	moduleNamePattern   = regexp.MustCompile(`\A[0-9A-Za-z](?:[0-9A-Za-z-_]{0,62}[0-9A-Za-z])?\z`)
+	providerTypePattern = regexp.MustCompile(`\A[0-9a-z](?:[0-9a-z-]{0,62}[0-9a-z])?\z`)
+	platformPattern     = regexp.MustCompile(`\A[0-9a-z]+\z`)
+	protocolPattern     = regexp.MustCompile(`\A\d+\.\d+\z`)
+
+	zipMagic = []byte{'P', 'K', 0x03, 0x04}
+)
+
+// Metadata represents the metadata of a Terraform module or provider version
+type Metadata struct {
+	Kind      string   `json:"kind"`
+	Readme    string   `json:"readme,omitempty"`
+	Protocols []string `json:"protocols,omitempty"`
+}
+
+// ModulePackageName gets the package name of a module, modules are identified by their name and target system
+func ModulePackageName(name, system string) (string, error) {
+	if !moduleNamePattern.MatchString(name) || !moduleNamePattern.MatchString(system) {
+		return "", ErrInvalidName
+	}
+	return name + "/" + system, nil
+}
+
+// IsValidProviderType checks if the provider type is valid
+func IsValidProviderType(providerType string) bool {
+	return providerTypePattern.MatchString(providerType)
+}
+
+// IsValidPlatform checks if the operating system and architecture are valid
+func IsValidPlatform(os, arch string) bool {
+	return platformPattern.MatchString(os) && platformPattern.MatchString(arch)
+}
+
+// ParseVersion parses and normalizes a semantic version
+func ParseVersion(v string) (string, error) {
+	sv, err := version.NewSemver(v)
+	if err != nil {
+		return "", ErrInvalidVersion
+	}
+	return sv.String(), nil
+}
+
+// ParseProtocols parses the comma separated plugin protocol versions of a provider
+func ParseProtocols(s string) ([]string, error) {
+	if strings.TrimSpace(s) == "" {
+		return []string{DefaultProtocol}, nil
+	}
+
+	protocols := make([]string, 0, 2)
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if !protocolPattern.MatchString(p) {
+			return nil, ErrInvalidProtocol
+		}
+		protocols = append(protocols, p)
+	}
+	return protocols, nil
+}
+
+// ParseModuleArchive parses the gzipped tarball of a module and extracts the readme
+func ParseModuleArchive(r io.Reader) (*Metadata, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, ErrInvalidArchive
+	}
+	defer gzr.Close()
+
+	m := &Metadata{
+		Kind: KindModule,
+	}
+
+	tr := tar.NewReader(gzr)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, ErrInvalidArchive
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		if strings.EqualFold(path.Clean(hd.Name), "readme.md") {
+			data, err := io.ReadAll(io.LimitReader(tr, maxReadmeFileSize))
+			if err != nil {
+				return nil, err
+			}
+			m.Readme = string(data)
+		}
+	}
+
+	return m, nil
+}
+
+// ValidateProviderArchive checks if the provider archive is a zip file
+func ValidateProviderArchive(r io.Reader) error {
+	magic := make([]byte, len(zipMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || !bytes.Equal(magic, zipMagic) {
+		return ErrInvalidArchive
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package terraform
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const readme = "# Gitea Module"
+
+func createModuleArchive(files map[string]string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		tw.WriteHeader(&tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(content)),
+		})
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestModulePackageName(t *testing.T) {
+	for _, c := range [][2]string{{"", "aws"}, {"-vpc", "aws"}, {"vpc", "a/b"}, {"vp c", "aws"}} {
+		name, err := ModulePackageName(c[0], c[1])
+		assert.Empty(t, name)
+		assert.ErrorIs(t, err, ErrInvalidName)
+	}
+
+	name, err := ModulePackageName("vpc_network", "aws")
+	assert.NoError(t, err)
+	assert.Equal(t, "vpc_network/aws", name)
+}
+
+func TestParseVersion(t *testing.T) {
+	for _, v := range []string{"", "a.b.c", "version"} {
+		_, err := ParseVersion(v)
+		assert.ErrorIs(t, err, ErrInvalidVersion)
+	}
+
+	v, err := ParseVersion("v1.2.3-beta")
+	assert.NoError(t, err)
+	assert.Equal(t, "1.2.3-beta", v)
+}
+
+func TestParseProtocols(t *testing.T) {
+	protocols, err := ParseProtocols("")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{DefaultProtocol}, protocols)
+
+	protocols, err = ParseProtocols("5.0, 6.0")
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"5.0", "6.0"}, protocols)
+
+	for _, s := range []string{"5", "5.0,", "v6.0"} {
+		_, err := ParseProtocols(s)
+		assert.ErrorIs(t, err, ErrInvalidProtocol)
+	}
+}
+
+func TestParseModuleArchive(t *testing.T) {
+	t.Run("InvalidArchive", func(t *testing.T) {
+		m, err := ParseModuleArchive(strings.NewReader("invalid"))
+		assert.Nil(t, m)
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		m, err := ParseModuleArchive(bytes.NewReader(createModuleArchive(map[string]string{
+			"main.tf":     `resource "null_resource" "gitea" {}`,
+			"./README.md": readme,
+		})))
+		assert.NoError(t, err)
+		assert.NotNil(t, m)
+		assert.Equal(t, KindModule, m.Kind)
+		assert.Equal(t, readme, m.Readme)
+	})
+}
+
+func TestValidateProviderArchive(t *testing.T) {
+	assert.ErrorIs(t, ValidateProviderArchive(strings.NewReader("")), ErrInvalidArchive)
+	assert.ErrorIs(t, ValidateProviderArchive(strings.NewReader("invalid")), ErrInvalidArchive)
+	assert.NoError(t, ValidateProviderArchive(bytes.NewReader([]byte{'P', 'K', 0x03, 0x04, 0x14})))
+}
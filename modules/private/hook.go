@@ -56,6 +56,7 @@ type HookOptions struct {
 	DeployKeyID                     int64 // if the pusher is a DeployKey, then UserID is the repo's org user.
 	IsWiki                          bool
 	ActionPerm                      int
+	IsGiteaPush                     bool // the push is made by Gitea itself, e.g. when merging a pull request or committing in the web editor
 }
 
 // SSHLogOption ssh log options
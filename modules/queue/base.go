@@ -19,6 +19,12 @@ type baseQueue interface {
 	RemoveAll(ctx context.Context) error
 }
 
+// baseQueueAcker is implemented by the base queues which keep the popped items until they are acknowledged,
+// the items which are never acknowledged (eg: the instance crashed) are delivered again
+type baseQueueAcker interface {
+	AckItem(ctx context.Context, data []byte) error
+}
+
 func popItemByChan(ctx context.Context, popItemFn func(ctx context.Context) ([]byte, error)) (chanItem chan []byte, chanErr chan error) {
 	chanItem = make(chan []byte)
 	chanErr = make(chan error)
@@ -40,3 +46,12 @@ func popItemByChan(ctx context.Context, popItemFn func(ctx context.Context) ([]b
 	}()
 	return chanItem, chanErr
 }
+
+// baseQueueInspector is implemented by the base queues whose waiting items can be listed and removed without popping them
+type baseQueueInspector interface {
+	// ListItems returns the items which have not been popped yet, in order, skipping the first skip items and returning
+	// at most limit items if limit is positive. It also returns the number of items which have not been popped yet.
+	ListItems(ctx context.Context, skip, limit int) ([][]byte, int, error)
+	// RemoveItem removes the first waiting item whose data matches and returns its data, or nil if no item matches
+	RemoveItem(ctx context.Context, match func(data []byte) bool) ([]byte, error)
+}
@@ -5,6 +5,7 @@ package queue
 
 import (
 	"context"
+	"sync"
 	"sync/atomic"
 
 	"code.gitea.io/gitea/modules/nosql"
@@ -20,9 +21,14 @@ type baseLevelQueue struct {
 	conn string
 	cfg  *BaseConfig
 	db   *leveldb.DB
+
+	mu sync.Mutex // the removed items are overwritten, it must not happen while they are popped
 }
 
-var _ baseQueue = (*baseLevelQueue)(nil)
+var (
+	_ baseQueue          = (*baseLevelQueue)(nil)
+	_ baseQueueInspector = (*baseLevelQueue)(nil)
+)
 
 func newBaseLevelQueueGeneric(cfg *BaseConfig, unique bool) (baseQueue, error) {
 	if unique {
@@ -46,12 +52,12 @@ func newBaseLevelQueueSimple(cfg *BaseConfig) (baseQueue, error) {
 }
 
 func (q *baseLevelQueue) PushItem(ctx context.Context, data []byte) error {
-	c := baseLevelQueueCommon(q.cfg, nil, func() baseLevelQueuePushPoper { return q.internal.Load() })
+	c := baseLevelQueueCommon(q.cfg, &q.mu, func() baseLevelQueuePushPoper { return q.internal.Load() })
 	return c.PushItem(ctx, data)
 }
 
 func (q *baseLevelQueue) PopItem(ctx context.Context) ([]byte, error) {
-	c := baseLevelQueueCommon(q.cfg, nil, func() baseLevelQueuePushPoper { return q.internal.Load() })
+	c := baseLevelQueueCommon(q.cfg, &q.mu, func() baseLevelQueuePushPoper { return q.internal.Load() })
 	return c.PopItem(ctx)
 }
 
@@ -63,6 +69,22 @@ func (q *baseLevelQueue) Len(ctx context.Context) (int, error) {
 	return int(q.internal.Load().Len()), nil
 }
 
+func (q *baseLevelQueue) ListItems(ctx context.Context, skip, limit int) ([][]byte, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return lqinternal.ListLevelQueueItems(q.db, []byte(q.cfg.QueueFullName), skip, limit)
+}
+
+func (q *baseLevelQueue) RemoveItem(ctx context.Context, match func(data []byte) bool) ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	removed, err := removeLevelQueueItem(q.db, []byte(q.cfg.QueueFullName), match)
+	if err != nil || removed == nil {
+		return nil, err
+	}
+	return removed, popRemovedLevelQueueItems(q.internal.Load())
+}
+
 func (q *baseLevelQueue) Close() error {
 	err := q.internal.Load().Close()
 	_ = nosql.GetManager().CloseLevelDB(q.conn)
@@ -5,6 +5,7 @@ package queue
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"path/filepath"
 	"strings"
@@ -12,6 +13,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/modules/nosql"
+	"code.gitea.io/gitea/modules/queue/lqinternal"
 
 	"gitea.com/lunny/levelqueue"
 	"github.com/syndtr/goleveldb/leveldb"
@@ -21,6 +23,7 @@ import (
 type baseLevelQueuePushPoper interface {
 	RPush(data []byte) error
 	LPop() ([]byte, error)
+	LHandle(h func([]byte) error) error
 	Len() int64
 }
 
@@ -56,15 +59,56 @@ func (q *baseLevelQueueCommonImpl) PopItem(ctx context.Context) ([]byte, error)
 			defer q.mu.Unlock()
 		}
 
-		data, err = q.internalFunc().LPop()
-		if err == levelqueue.ErrNotFound {
-			return true, nil, nil
+		for {
+			data, err = q.internalFunc().LPop()
+			if err == levelqueue.ErrNotFound {
+				return true, nil, nil
+			}
+			if err != nil {
+				return false, nil, err
+			}
+			if len(data) != 0 {
+				return false, data, nil
+			}
+			// the item has been removed by removeLevelQueueItem, pop the next one
+		}
+	})
+}
+
+var errLevelQueueItemKept = errors.New("the item is kept")
+
+// popRemovedLevelQueueItems pops the removed items at the head of a level queue, so they are not counted in its length anymore
+func popRemovedLevelQueueItems(lq baseLevelQueuePushPoper) error {
+	for {
+		err := lq.LHandle(func(data []byte) error {
+			if len(data) != 0 {
+				return errLevelQueueItemKept
+			}
+			return nil
+		})
+		if err == errLevelQueueItemKept || err == levelqueue.ErrNotFound {
+			return nil
 		}
 		if err != nil {
-			return false, nil, err
+			return err
 		}
-		return false, data, nil
+	}
+}
+
+// removeLevelQueueItem removes the first item whose data matches from a level queue and returns its data, or nil if no item matches.
+// The items can only be popped in order, so the data of the item is replaced by an empty value which is skipped when it is popped.
+func removeLevelQueueItem(db *leveldb.DB, namePrefix []byte, match func(data []byte) bool) (removed []byte, err error) {
+	err = lqinternal.IterateLevelQueueItems(db, namePrefix, func(key, data []byte) (bool, error) {
+		if !match(data) {
+			return true, nil
+		}
+		removed = data
+		return false, db.Put(key, []byte{}, nil)
 	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
 }
 
 func baseLevelQueueCommon(cfg *BaseConfig, mu *sync.Mutex, internalFunc func() baseLevelQueuePushPoper) *baseLevelQueueCommonImpl {
@@ -25,7 +25,10 @@ type baseLevelQueueUnique struct {
 	mu sync.Mutex // the levelqueue.UniqueQueue is not thread-safe, there is no mutex protecting the underlying queue&set together
 }
 
-var _ baseQueue = (*baseLevelQueueUnique)(nil)
+var (
+	_ baseQueue          = (*baseLevelQueueUnique)(nil)
+	_ baseQueueInspector = (*baseLevelQueueUnique)(nil)
+)
 
 func newBaseLevelQueueUnique(cfg *BaseConfig) (baseQueue, error) {
 	conn, db, err := prepareLevelDB(cfg)
@@ -63,6 +66,26 @@ func (q *baseLevelQueueUnique) Len(ctx context.Context) (int, error) {
 	return int(q.internal.Load().Len()), nil
 }
 
+func (q *baseLevelQueueUnique) ListItems(ctx context.Context, skip, limit int) ([][]byte, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return lqinternal.ListLevelQueueItems(q.db, []byte(q.cfg.QueueFullName), skip, limit)
+}
+
+func (q *baseLevelQueueUnique) RemoveItem(ctx context.Context, match func(data []byte) bool) ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	removed, err := removeLevelQueueItem(q.db, []byte(q.cfg.QueueFullName), match)
+	if err != nil || removed == nil {
+		return nil, err
+	}
+	// the key of the members of the set is "<set name>-<data>"
+	if err := q.db.Delete(append([]byte(q.cfg.SetFullName+"-"), removed...), nil); err != nil {
+		return removed, err
+	}
+	return removed, popRemovedLevelQueueItems(q.internal.Load())
+}
+
 func (q *baseLevelQueueUnique) Close() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
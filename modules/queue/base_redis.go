@@ -15,6 +15,9 @@ import (
 	"github.com/redis/go-redis/v9"
 )
 
+// redisInspectBatchSize is the number of items read at once when the items of a redis queue are searched
+const redisInspectBatchSize = 100
+
 type baseRedis struct {
 	client   redis.UniversalClient
 	isUnique bool
@@ -23,7 +26,10 @@ type baseRedis struct {
 	mu sync.Mutex // the old implementation is not thread-safe, the queue operation and set operation should be protected together
 }
 
-var _ baseQueue = (*baseRedis)(nil)
+var (
+	_ baseQueue          = (*baseRedis)(nil)
+	_ baseQueueInspector = (*baseRedis)(nil)
+)
 
 func newBaseRedisGeneric(cfg *BaseConfig, unique bool) (baseQueue, error) {
 	client := nosql.GetManager().GetRedisClient(cfg.ConnStr)
@@ -114,6 +120,55 @@ func (q *baseRedis) Len(ctx context.Context) (int, error) {
 	return int(cnt), err
 }
 
+func (q *baseRedis) ListItems(ctx context.Context, skip, limit int) ([][]byte, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	total, err := q.client.LLen(ctx, q.cfg.QueueFullName).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	stop := int64(-1)
+	if limit > 0 {
+		stop = int64(skip + limit - 1)
+	}
+	items, err := q.client.LRange(ctx, q.cfg.QueueFullName, int64(skip), stop).Result()
+	if err != nil {
+		return nil, 0, err
+	}
+	res := make([][]byte, 0, len(items))
+	for _, item := range items {
+		res = append(res, []byte(item))
+	}
+	return res, int(total), nil
+}
+
+func (q *baseRedis) RemoveItem(ctx context.Context, match func(data []byte) bool) ([]byte, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for start := int64(0); ; start += redisInspectBatchSize {
+		items, err := q.client.LRange(ctx, q.cfg.QueueFullName, start, start+redisInspectBatchSize-1).Result()
+		if err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			if !match([]byte(item)) {
+				continue
+			}
+			removed, err := q.client.LRem(ctx, q.cfg.QueueFullName, 1, item).Result()
+			if err != nil || removed == 0 {
+				return nil, err
+			}
+			if q.isUnique {
+				_ = q.client.SRem(ctx, q.cfg.SetFullName, item).Err()
+			}
+			return []byte(item), nil
+		}
+		if len(items) < redisInspectBatchSize {
+			return nil, nil
+		}
+	}
+}
+
 func (q *baseRedis) Close() error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
@@ -0,0 +1,429 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package queue
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/nosql"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/redis/go-redis/v9"
+)
+
+const (
+	redisStreamGroup     = "gitea"
+	redisStreamDataField = "data"
+)
+
+var (
+	// redisStreamClaimInterval is the interval at which a consumer refreshes the entries it handles and reclaims the abandoned ones
+	redisStreamClaimInterval = 30 * time.Second
+	// redisStreamClaimMinIdle is the duration after which an entry which has not been refreshed is considered abandoned by a crashed consumer
+	redisStreamClaimMinIdle = 2 * time.Minute
+)
+
+// baseRedisStream is a queue stored in a redis stream read through a consumer group.
+// The popped items stay in the pending entries list of the group until they are acknowledged,
+// so the items of a consumer which died while handling them are reclaimed by the other consumers.
+type baseRedisStream struct {
+	client   redis.UniversalClient
+	isUnique bool
+	cfg      *BaseConfig
+	consumer string
+
+	mu      sync.Mutex
+	pending map[string]string // the popped entries which are not acknowledged yet: id -> data
+	claimed []redis.XMessage  // the entries reclaimed from other consumers, popped before the new ones
+
+	claimCancel context.CancelFunc
+	claimDone   chan struct{}
+}
+
+var (
+	_ baseQueue          = (*baseRedisStream)(nil)
+	_ baseQueueAcker     = (*baseRedisStream)(nil)
+	_ baseQueueInspector = (*baseRedisStream)(nil)
+)
+
+func newBaseRedisStreamGeneric(cfg *BaseConfig, unique bool) (baseQueue, error) {
+	client := nosql.GetManager().GetRedisClient(cfg.ConnStr)
+
+	var err error
+	for i := 0; i < 10; i++ {
+		err = client.Ping(graceful.GetManager().ShutdownContext()).Err()
+		if err == nil {
+			break
+		}
+		log.Warn("Redis is not ready, waiting for 1 second to retry: %v", err)
+		time.Sleep(time.Second)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	hostname, _ := os.Hostname()
+	suffix, err := util.CryptoRandomString(8)
+	if err != nil {
+		return nil, err
+	}
+
+	q := &baseRedisStream{
+		client:    client,
+		isUnique:  unique,
+		cfg:       cfg,
+		consumer:  fmt.Sprintf("%s-%d-%s", hostname, os.Getpid(), suffix),
+		pending:   map[string]string{},
+		claimDone: make(chan struct{}),
+	}
+	if err := q.createGroup(graceful.GetManager().ShutdownContext()); err != nil {
+		return nil, err
+	}
+
+	var ctx context.Context
+	ctx, q.claimCancel = context.WithCancel(graceful.GetManager().ShutdownContext())
+	go q.claimLoop(ctx)
+	return q, nil
+}
+
+func newBaseRedisStreamSimple(cfg *BaseConfig) (baseQueue, error) {
+	return newBaseRedisStreamGeneric(cfg, false)
+}
+
+func newBaseRedisStreamUnique(cfg *BaseConfig) (baseQueue, error) {
+	return newBaseRedisStreamGeneric(cfg, true)
+}
+
+// createGroup creates the stream and its consumer group if they don't exist
+func (q *baseRedisStream) createGroup(ctx context.Context) error {
+	err := q.client.XGroupCreateMkStream(ctx, q.cfg.QueueFullName, redisStreamGroup, "0").Err()
+	if err != nil && !strings.HasPrefix(err.Error(), "BUSYGROUP") {
+		return err
+	}
+	return nil
+}
+
+func (q *baseRedisStream) PushItem(ctx context.Context, data []byte) error {
+	return backoffErr(ctx, backoffBegin, backoffUpper, time.After(pushBlockTime), func() (retry bool, err error) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		cnt, err := q.length(ctx)
+		if err != nil {
+			return false, err
+		}
+		if cnt >= q.cfg.Length {
+			return true, nil
+		}
+
+		if q.isUnique {
+			added, err := q.client.SAdd(ctx, q.cfg.SetFullName, data).Result()
+			if err != nil {
+				return false, err
+			}
+			if added == 0 {
+				return false, ErrAlreadyInQueue
+			}
+		}
+		return false, q.client.XAdd(ctx, &redis.XAddArgs{
+			Stream: q.cfg.QueueFullName,
+			Values: map[string]any{redisStreamDataField: data},
+		}).Err()
+	})
+}
+
+func (q *baseRedisStream) PopItem(ctx context.Context) ([]byte, error) {
+	return backoffRetErr(ctx, backoffBegin, backoffUpper, infiniteTimerC, func() (retry bool, data []byte, err error) {
+		q.mu.Lock()
+		defer q.mu.Unlock()
+
+		for len(q.claimed) > 0 {
+			msg := q.claimed[0]
+			q.claimed = q.claimed[1:]
+			if data, ok := q.popMessage(ctx, msg); ok {
+				return false, data, nil
+			}
+		}
+
+		streams, err := q.client.XReadGroup(ctx, &redis.XReadGroupArgs{
+			Group:    redisStreamGroup,
+			Consumer: q.consumer,
+			Streams:  []string{q.cfg.QueueFullName, ">"},
+			Count:    1,
+			Block:    -1,
+		}).Result()
+		if err == redis.Nil {
+			return true, nil, nil
+		}
+		if err != nil {
+			if strings.HasPrefix(err.Error(), "NOGROUP") {
+				// the stream has been removed
+				if err := q.createGroup(ctx); err != nil {
+					log.Error("Unable to create the consumer group of redis stream %q: %v", q.cfg.QueueFullName, err)
+				}
+			}
+			return true, nil, nil
+		}
+		for _, stream := range streams {
+			for _, msg := range stream.Messages {
+				if data, ok := q.popMessage(ctx, msg); ok {
+					return false, data, nil
+				}
+			}
+		}
+		return true, nil, nil
+	})
+}
+
+// popMessage records a message read from the stream as pending, the message is acknowledged once its item has been handled.
+// The messages without data can't be handled and are acknowledged immediately.
+func (q *baseRedisStream) popMessage(ctx context.Context, msg redis.XMessage) ([]byte, bool) {
+	data, ok := msg.Values[redisStreamDataField].(string)
+	if !ok {
+		if err := q.ackMessage(ctx, msg.ID); err != nil {
+			log.Error("Unable to acknowledge entry %s of redis stream %q: %v", msg.ID, q.cfg.QueueFullName, err)
+		}
+		return nil, false
+	}
+	q.pending[msg.ID] = data
+	if q.isUnique {
+		// the data has been popped, even if there is any error we can't do anything
+		_ = q.client.SRem(ctx, q.cfg.SetFullName, data).Err()
+	}
+	return []byte(data), true
+}
+
+func (q *baseRedisStream) ackMessage(ctx context.Context, id string) error {
+	delete(q.pending, id)
+	if err := q.client.XAck(ctx, q.cfg.QueueFullName, redisStreamGroup, id).Err(); err != nil {
+		return err
+	}
+	// the acknowledged entries are removed, the stream only keeps the items which are not handled yet
+	return q.client.XDel(ctx, q.cfg.QueueFullName, id).Err()
+}
+
+// AckItem acknowledges one of the popped entries holding the data
+func (q *baseRedisStream) AckItem(ctx context.Context, data []byte) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for id, pendingData := range q.pending {
+		if pendingData == string(data) {
+			return q.ackMessage(ctx, id)
+		}
+	}
+	return nil
+}
+
+// claimLoop periodically refreshes the entries handled by this consumer, and claims the entries
+// which have not been refreshed for a while because their consumer died
+func (q *baseRedisStream) claimLoop(ctx context.Context) {
+	defer close(q.claimDone)
+
+	ticker := time.NewTicker(redisStreamClaimInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if err := q.claim(ctx); err != nil && ctx.Err() == nil {
+			log.Error("Unable to claim the pending entries of redis stream %q: %v", q.cfg.QueueFullName, err)
+		}
+	}
+}
+
+func (q *baseRedisStream) claim(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if len(q.pending)+len(q.claimed) > 0 {
+		ids := make([]string, 0, len(q.pending)+len(q.claimed))
+		for id := range q.pending {
+			ids = append(ids, id)
+		}
+		for _, msg := range q.claimed {
+			ids = append(ids, msg.ID)
+		}
+		// claiming its own entries resets their idle time
+		if err := q.client.XClaimJustID(ctx, &redis.XClaimArgs{
+			Stream:   q.cfg.QueueFullName,
+			Group:    redisStreamGroup,
+			Consumer: q.consumer,
+			Messages: ids,
+		}).Err(); err != nil {
+			return err
+		}
+	}
+
+	start := "0-0"
+	for {
+		msgs, next, err := q.client.XAutoClaim(ctx, &redis.XAutoClaimArgs{
+			Stream:   q.cfg.QueueFullName,
+			Group:    redisStreamGroup,
+			Consumer: q.consumer,
+			MinIdle:  redisStreamClaimMinIdle,
+			Start:    start,
+			Count:    100,
+		}).Result()
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if _, ok := q.pending[msg.ID]; ok {
+				continue
+			}
+			log.Debug("Claimed abandoned entry %s of redis stream %q", msg.ID, q.cfg.QueueFullName)
+			q.claimed = append(q.claimed, msg)
+		}
+		if next == "0-0" || next == "" {
+			return nil
+		}
+		start = next
+	}
+}
+
+func (q *baseRedisStream) HasItem(ctx context.Context, data []byte) (bool, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if !q.isUnique {
+		return false, nil
+	}
+	return q.client.SIsMember(ctx, q.cfg.SetFullName, data).Result()
+}
+
+func (q *baseRedisStream) Len(ctx context.Context) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	return q.length(ctx)
+}
+
+// length returns the number of items which have not been popped yet,
+// the stream also contains the entries which have been popped but are not acknowledged yet
+func (q *baseRedisStream) length(ctx context.Context) (int, error) {
+	cnt, err := q.client.XLen(ctx, q.cfg.QueueFullName).Result()
+	if err != nil {
+		return 0, err
+	}
+	pending, err := q.client.XPending(ctx, q.cfg.QueueFullName, redisStreamGroup).Result()
+	if err != nil {
+		return 0, err
+	}
+	return int(cnt - pending.Count), nil
+}
+
+// iterateWaitingMessages calls f with the entries which have not been delivered to any consumer yet, in order, until f returns false
+func (q *baseRedisStream) iterateWaitingMessages(ctx context.Context, f func(msg redis.XMessage) (bool, error)) error {
+	groups, err := q.client.XInfoGroups(ctx, q.cfg.QueueFullName).Result()
+	if err != nil {
+		return err
+	}
+	start := "-"
+	for _, group := range groups {
+		if group.Name == redisStreamGroup && group.LastDeliveredID != "0-0" {
+			start = "(" + group.LastDeliveredID
+		}
+	}
+	for {
+		msgs, err := q.client.XRangeN(ctx, q.cfg.QueueFullName, start, "+", redisInspectBatchSize).Result()
+		if err != nil {
+			return err
+		}
+		for _, msg := range msgs {
+			if cont, err := f(msg); err != nil || !cont {
+				return err
+			}
+		}
+		if len(msgs) < redisInspectBatchSize {
+			return nil
+		}
+		start = "(" + msgs[len(msgs)-1].ID
+	}
+}
+
+func (q *baseRedisStream) ListItems(ctx context.Context, skip, limit int) ([][]byte, int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	total, err := q.length(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+	var items [][]byte
+	n := 0
+	err = q.iterateWaitingMessages(ctx, func(msg redis.XMessage) (bool, error) {
+		data, ok := msg.Values[redisStreamDataField].(string)
+		if !ok {
+			return true, nil
+		}
+		if n >= skip {
+			items = append(items, []byte(data))
+		}
+		n++
+		return limit <= 0 || len(items) < limit, nil
+	})
+	return items, total, err
+}
+
+func (q *baseRedisStream) RemoveItem(ctx context.Context, match func(data []byte) bool) (removed []byte, err error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	err = q.iterateWaitingMessages(ctx, func(msg redis.XMessage) (bool, error) {
+		data, ok := msg.Values[redisStreamDataField].(string)
+		if !ok || !match([]byte(data)) {
+			return true, nil
+		}
+		if err := q.client.XDel(ctx, q.cfg.QueueFullName, msg.ID).Err(); err != nil {
+			return false, err
+		}
+		if q.isUnique {
+			_ = q.client.SRem(ctx, q.cfg.SetFullName, data).Err()
+		}
+		removed = []byte(data)
+		return false, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return removed, nil
+}
+
+func (q *baseRedisStream) Close() error {
+	q.claimCancel()
+	<-q.claimDone
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if len(q.pending) == 0 && len(q.claimed) == 0 {
+		// a consumer without pending entries can be forgotten, otherwise its entries are reclaimed by the other consumers
+		_ = q.client.XGroupDelConsumer(graceful.GetManager().ShutdownContext(), q.cfg.QueueFullName, redisStreamGroup, q.consumer).Err()
+	}
+	return q.client.Close()
+}
+
+func (q *baseRedisStream) RemoveAll(ctx context.Context) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	q.pending = map[string]string{}
+	q.claimed = nil
+
+	c1 := q.client.Del(ctx, q.cfg.QueueFullName)
+	// the "set" must be cleared after the "stream" because there is no transaction.
+	// it's better to have duplicate items than losing items.
+	c2 := q.client.Del(ctx, q.cfg.SetFullName)
+	if c1.Err() != nil {
+		return c1.Err()
+	}
+	if c2.Err() != nil {
+		return c2.Err()
+	}
+	return q.createGroup(ctx)
+}
@@ -0,0 +1,76 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/alicebob/miniredis/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBaseRedisStream(t *testing.T) {
+	connStr := "redis://" + miniredis.RunT(t).Addr() + "/0"
+
+	testQueueBasic(t, newBaseRedisStreamSimple, toBaseConfig("baseRedisStream", setting.QueueSettings{ConnStr: connStr, Length: 10}), false)
+	testQueueBasic(t, newBaseRedisStreamUnique, toBaseConfig("baseRedisStreamUnique", setting.QueueSettings{ConnStr: connStr, Length: 10}), true)
+	testQueueInspect(t, newBaseRedisStreamSimple, toBaseConfig("baseRedisStream", setting.QueueSettings{ConnStr: connStr, Length: 10}), false)
+	testQueueInspect(t, newBaseRedisStreamUnique, toBaseConfig("baseRedisStreamUnique", setting.QueueSettings{ConnStr: connStr, Length: 10}), true)
+}
+
+func TestBaseRedisStreamReclaim(t *testing.T) {
+	connStr := "redis://" + miniredis.RunT(t).Addr() + "/0"
+
+	oldClaimInterval, oldClaimMinIdle := redisStreamClaimInterval, redisStreamClaimMinIdle
+	redisStreamClaimInterval, redisStreamClaimMinIdle = 50*time.Millisecond, 200*time.Millisecond
+	defer func() {
+		redisStreamClaimInterval, redisStreamClaimMinIdle = oldClaimInterval, oldClaimMinIdle
+	}()
+
+	ctx := context.Background()
+	cfg := toBaseConfig("baseRedisStreamReclaim", setting.QueueSettings{ConnStr: connStr, Length: 10})
+
+	q1, err := newBaseRedisStreamSimple(cfg)
+	assert.NoError(t, err)
+	_ = q1.RemoveAll(ctx)
+	assert.NoError(t, q1.PushItem(ctx, []byte("handled")))
+	assert.NoError(t, q1.PushItem(ctx, []byte("lost")))
+
+	// the first consumer handles an item but "crashes" while handling the other one
+	it, err := q1.PopItem(ctx)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "handled", string(it))
+	assert.NoError(t, q1.(baseQueueAcker).AckItem(ctx, it))
+	it, err = q1.PopItem(ctx)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "lost", string(it))
+	q1.(*baseRedisStream).claimCancel()
+
+	cnt, err := q1.Len(ctx)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, cnt)
+
+	// another consumer reclaims the item which has not been acknowledged
+	q2, err := newBaseRedisStreamSimple(cfg)
+	assert.NoError(t, err)
+	defer q2.Close()
+
+	ctxTimed, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+	it, err = q2.PopItem(ctxTimed)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "lost", string(it))
+	assert.NoError(t, q2.(baseQueueAcker).AckItem(ctx, it))
+
+	// once acknowledged, the item is gone
+	ctxTimed, cancel = context.WithTimeout(ctx, 500*time.Millisecond)
+	defer cancel()
+	it, err = q2.PopItem(ctxTimed)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Nil(t, it)
+}
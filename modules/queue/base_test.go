@@ -117,6 +117,79 @@ func testQueueBasic(t *testing.T, newFn func(cfg *BaseConfig) (baseQueue, error)
 	})
 }
 
+func testQueueInspect(t *testing.T, newFn func(cfg *BaseConfig) (baseQueue, error), cfg *BaseConfig, isUnique bool) {
+	t.Run(fmt.Sprintf("testQueueInspect-%s-unique:%v", cfg.ManagedName, isUnique), func(t *testing.T) {
+		q, err := newFn(cfg)
+		assert.NoError(t, err)
+
+		ctx := context.Background()
+		_ = q.RemoveAll(ctx)
+		inspector := q.(baseQueueInspector)
+
+		for _, item := range []string{"foo", "bar", "baz", "bar"} {
+			_ = q.PushItem(ctx, []byte(item))
+		}
+		toStrings := func(items [][]byte) (res []string) {
+			for _, item := range items {
+				res = append(res, string(item))
+			}
+			return res
+		}
+		matchString := func(s string) func(data []byte) bool {
+			return func(data []byte) bool { return string(data) == s }
+		}
+		items, total, err := inspector.ListItems(ctx, 0, 0)
+		assert.NoError(t, err)
+		if isUnique {
+			assert.Equal(t, []string{"foo", "bar", "baz"}, toStrings(items))
+			assert.Equal(t, 3, total)
+		} else {
+			assert.Equal(t, []string{"foo", "bar", "baz", "bar"}, toStrings(items))
+			assert.Equal(t, 4, total)
+		}
+		items, _, err = inspector.ListItems(ctx, 1, 1)
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"bar"}, toStrings(items))
+
+		// remove the first "bar"
+		removed, err := inspector.RemoveItem(ctx, matchString("bar"))
+		assert.NoError(t, err)
+		assert.Equal(t, "bar", string(removed))
+		removed, err = inspector.RemoveItem(ctx, matchString("unknown"))
+		assert.NoError(t, err)
+		assert.Nil(t, removed)
+
+		items, _, err = inspector.ListItems(ctx, 0, 0)
+		assert.NoError(t, err)
+		if isUnique {
+			assert.Equal(t, []string{"foo", "baz"}, toStrings(items))
+			has, err := q.HasItem(ctx, []byte("bar"))
+			assert.NoError(t, err)
+			assert.False(t, has)
+		} else {
+			assert.Equal(t, []string{"foo", "baz", "bar"}, toStrings(items))
+		}
+
+		// the removed item is never popped
+		it, err := q.PopItem(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, "foo", string(it))
+		it, err = q.PopItem(ctx)
+		assert.NoError(t, err)
+		assert.EqualValues(t, "baz", string(it))
+
+		items, _, err = inspector.ListItems(ctx, 0, 0)
+		assert.NoError(t, err)
+		if isUnique {
+			assert.Empty(t, items)
+		} else {
+			assert.Equal(t, []string{"bar"}, toStrings(items))
+		}
+
+		_ = q.RemoveAll(ctx)
+	})
+}
+
 func TestBaseDummy(t *testing.T) {
 	q, err := newBaseDummy(&BaseConfig{}, true)
 	assert.NoError(t, err)
@@ -34,3 +34,11 @@ func toBaseConfig(managedName string, queueSetting setting.QueueSettings) *BaseC
 	}
 	return baseConfig
 }
+
+// toDeadLetterConfig returns the config of the base queue storing the dead letters of a queue
+func toDeadLetterConfig(cfg *BaseConfig) *BaseConfig {
+	deadLetterConfig := *cfg
+	deadLetterConfig.QueueFullName = cfg.QueueFullName + "_dead_letter"
+	deadLetterConfig.SetFullName = deadLetterConfig.QueueFullName + "_unique"
+	return &deadLetterConfig
+}
@@ -46,3 +46,60 @@ func ListLevelQueueKeys(db *leveldb.DB) (res [][]byte) {
 	}
 	return res
 }
+
+func readLevelQueueID(db *leveldb.DB, namePrefix []byte, name string) (int64, error) {
+	key := make([]byte, 0, len(namePrefix)+1+len(name))
+	key = append(append(append(key, namePrefix...), '-'), name...)
+	bs, err := db.Get(key, nil)
+	if err != nil {
+		return 0, err
+	}
+	return binary.ReadVarint(bytes.NewReader(bs))
+}
+
+// IterateLevelQueueItems calls f with the keys and the data of the items waiting in a level queue, in order,
+// until f returns false. The items whose data is empty have been removed and are skipped.
+func IterateLevelQueueItems(db *leveldb.DB, namePrefix []byte, f func(key, data []byte) (bool, error)) error {
+	low, err := readLevelQueueID(db, namePrefix, "low")
+	if err == leveldb.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+	high, err := readLevelQueueID(db, namePrefix, "high")
+	if err == leveldb.ErrNotFound {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for id := low; id <= high; id++ {
+		key := QueueItemKeyBytes(namePrefix, id)
+		data, err := db.Get(key, nil)
+		if err == leveldb.ErrNotFound {
+			continue
+		} else if err != nil {
+			return err
+		}
+		if len(data) == 0 {
+			continue
+		}
+		if cont, err := f(key, data); err != nil || !cont {
+			return err
+		}
+	}
+	return nil
+}
+
+// ListLevelQueueItems returns the data of the items waiting in a level queue, in order, skipping the first skip items
+// and returning at most limit items if limit is positive. It also returns the number of waiting items.
+func ListLevelQueueItems(db *leveldb.DB, namePrefix []byte, skip, limit int) (items [][]byte, total int, err error) {
+	err = IterateLevelQueueItems(db, namePrefix, func(key, data []byte) (bool, error) {
+		if total >= skip && (limit <= 0 || len(items) < limit) {
+			items = append(items, data)
+		}
+		total++
+		return true, nil
+	})
+	return items, total, err
+}
@@ -36,6 +36,18 @@ type ManagedWorkerPoolQueue interface {
 
 	// RemoveAllItems removes all items in the base queue (on-the-fly items are not affected)
 	RemoveAllItems(ctx context.Context) error
+
+	// CanInspectItems returns whether the waiting items can be listed and removed, and whether the failed items can be moved to dead letters
+	CanInspectItems() bool
+	ListItems(ctx context.Context, skip, limit int) ([]*Item, int, error)
+	RemoveItem(ctx context.Context, id string) error
+	MoveItemToDeadLetters(ctx context.Context, id string) error
+
+	GetDeadLetterNumber() int
+	ListDeadLetters(ctx context.Context, skip, limit int) ([]*Item, int, error)
+	RemoveDeadLetter(ctx context.Context, id string) error
+	RequeueDeadLetter(ctx context.Context, id string) error
+	RequeueAllDeadLetters(ctx context.Context) (int, error)
 }
 
 var manager *Manager
@@ -96,6 +96,7 @@ func (q *WorkerPoolQueue[T]) doWorkerHandle(batch []T) {
 	}()
 
 	unhandled := q.safeHandler(batch...)
+	q.doHandledItems(batch, unhandled)
 	// if none of the items were handled, it should back-off for a few seconds
 	// in this case the handler (eg: document indexer) may have encountered some errors/failures
 	if len(unhandled) == len(batch) && unhandledItemRequeueDuration.Load() != 0 {
@@ -106,14 +107,66 @@ func (q *WorkerPoolQueue[T]) doWorkerHandle(batch []T) {
 		}
 	}
 	for _, item := range unhandled {
+		if q.moveFailedItemToDeadLetters(item) {
+			continue
+		}
 		if err := q.Push(item); err != nil {
 			if !q.basePushForShutdown(item) {
 				log.Error("Failed to requeue item for queue %q when calling handler: %v", q.GetName(), err)
 			}
+		} else {
+			q.ackItems(item)
 		}
 	}
 }
 
+// ackItems acknowledges the popped items which have been handled or requeued, if the base queue keeps them until then
+func (q *WorkerPoolQueue[T]) ackItems(items ...T) {
+	acker, ok := q.baseQueue.(baseQueueAcker)
+	if !ok {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), pushBlockTime)
+	defer cancel()
+	for _, item := range items {
+		if err := acker.AckItem(ctx, q.marshal(item)); err != nil {
+			log.Error("Failed to acknowledge item for queue %q: %v", q.GetName(), err)
+		}
+	}
+}
+
+// doHandledItems acknowledges the items of a batch which are not in the unhandled items returned by the handler,
+// and forgets about their previous failures
+func (q *WorkerPoolQueue[T]) doHandledItems(batch, unhandled []T) {
+	_, isAcker := q.baseQueue.(baseQueueAcker)
+	q.failuresMu.Lock()
+	hasFailures := len(q.failures) > 0
+	q.failuresMu.Unlock()
+	if !isAcker && !hasFailures {
+		return
+	}
+
+	unhandledData := make(map[string]int, len(unhandled))
+	for _, item := range unhandled {
+		unhandledData[string(q.marshal(item))]++
+	}
+	handled := make([]T, 0, len(batch))
+	handledData := make([][]byte, 0, len(batch))
+	for _, item := range batch {
+		data := q.marshal(item)
+		if unhandledData[string(data)] > 0 {
+			unhandledData[string(data)]--
+			continue
+		}
+		handled = append(handled, item)
+		handledData = append(handledData, data)
+	}
+	if hasFailures {
+		q.forgetFailures(handledData...)
+	}
+	q.ackItems(handled...)
+}
+
 // basePushForShutdown tries to requeue items into the base queue when the WorkerPoolQueue is shutting down.
 // If the queue is shutting down, it returns true and try to push the items
 // Otherwise it does nothing and returns false
@@ -128,6 +181,8 @@ func (q *WorkerPoolQueue[T]) basePushForShutdown(items ...T) bool {
 		// if there is still any error, the queue can do nothing instead of losing the items
 		if err := q.baseQueue.PushItem(ctxShutdown, q.marshal(item)); err != nil {
 			log.Error("Failed to requeue item for queue %q when shutting down: %v", q.GetName(), err)
+		} else {
+			q.ackItems(item)
 		}
 	}
 	return true
@@ -291,7 +346,8 @@ func (q *WorkerPoolQueue[T]) doRun() {
 			}
 		} else {
 			// if there is no shutdown context, just call the handler to try to handle the items. if the handler fails again, the items are lost
-			q.safeHandler(unhandled...)
+			// (unless the base queue keeps them until they are acknowledged)
+			q.doHandledItems(unhandled, q.safeHandler(unhandled...))
 		}
 
 		close(q.shutdownDone)
@@ -306,6 +362,10 @@ func (q *WorkerPoolQueue[T]) doRun() {
 			}
 			if v, jsonOk := q.unmarshal(data); !jsonOk {
 				testRecorder.Record("pop:corrupted:%s", data) // in rare cases the levelqueue(leveldb) might be corrupted
+				if acker, ok := q.baseQueue.(baseQueueAcker); ok {
+					// the item can never be handled, drop it
+					_ = acker.AckItem(q.ctxRun, data)
+				}
 				continue
 			} else {
 				wg.batchBuffer = append(wg.batchBuffer, v)
@@ -31,6 +31,13 @@ type WorkerPoolQueue[T any] struct {
 	baseQueueType string
 	baseConfig    *BaseConfig
 	baseQueue     baseQueue
+	deadLetters   baseQueue // the items which failed to be handled too many times, only for the base queues which can be inspected
+
+	maxFailures int
+	// failures counts the times the handler failed to handle the items which have been requeued. It is kept in memory,
+	// so it is reset on restart and every instance of a cluster counts the failures of the items it handles on its own.
+	failures   map[string]int
+	failuresMu sync.Mutex
 
 	batchChan chan []T
 	flushChan chan flushType
@@ -200,6 +207,8 @@ func getNewQueueFn(t string) (string, func(cfg *BaseConfig, unique bool) (baseQu
 		return t, newBaseChannelGeneric
 	case "redis":
 		return t, newBaseRedisGeneric
+	case "redis-stream":
+		return t, newBaseRedisStreamGeneric
 	default: // level(leveldb,levelqueue,persistable-channel)
 		return "level", newBaseLevelQueueGeneric
 	}
@@ -224,6 +233,14 @@ func NewWorkerPoolQueueWithContext[T any](ctx context.Context, name string, queu
 	if err != nil {
 		return nil, err
 	}
+	if _, ok := w.baseQueue.(baseQueueInspector); ok {
+		w.deadLetters, err = newQueueFn(toDeadLetterConfig(w.baseConfig), false)
+		if err != nil {
+			return nil, err
+		}
+	}
+	w.maxFailures = queueSetting.MaxFailures
+	w.failures = map[string]int{}
 	log.Trace("Created queue %q of type %q", name, queueType)
 
 	w.ctxRun, _, w.ctxRunCancel = process.GetManager().AddTypedContext(ctx, "Queue: "+w.GetName(), process.SystemProcessType, false)
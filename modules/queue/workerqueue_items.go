@@ -0,0 +1,237 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package queue
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ErrItemNotFound is returned when the item to operate on is not in the queue (anymore)
+var ErrItemNotFound = util.NewNotExistErrorf("item is not in queue")
+
+// Item is an item waiting in a queue or in its dead letters
+type Item struct {
+	ID      string // the hash of the data, it identifies the item
+	Data    string // the data stored in the base queue
+	Content string // the data decoded with the item type of the queue, formatted as indented JSON
+	IsValid bool   // false if the data can't be decoded, the handler will never receive such an item
+}
+
+func itemID(data []byte) string {
+	h := sha256.Sum256(data)
+	return hex.EncodeToString(h[:])
+}
+
+func (q *WorkerPoolQueue[T]) toItem(data []byte) *Item {
+	item := &Item{ID: itemID(data), Data: string(data)}
+	var v T
+	if err := json.Unmarshal(data, &v); err != nil {
+		return item
+	}
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, q.marshal(v), "", "  "); err != nil {
+		return item
+	}
+	item.Content = buf.String()
+	item.IsValid = true
+	return item
+}
+
+// CanInspectItems returns whether the items waiting in the queue can be listed and whether the queue has dead letters
+func (q *WorkerPoolQueue[T]) CanInspectItems() bool {
+	_, ok := q.baseQueue.(baseQueueInspector)
+	return ok
+}
+
+func (q *WorkerPoolQueue[T]) listItems(ctx context.Context, base baseQueue, skip, limit int) ([]*Item, int, error) {
+	inspector, ok := base.(baseQueueInspector)
+	if !ok {
+		return nil, 0, nil
+	}
+	data, total, err := inspector.ListItems(ctx, skip, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	items := make([]*Item, 0, len(data))
+	for _, d := range data {
+		items = append(items, q.toItem(d))
+	}
+	return items, total, nil
+}
+
+// removeItem removes an item from a base queue and returns its data
+func (q *WorkerPoolQueue[T]) removeItem(ctx context.Context, base baseQueue, id string) ([]byte, error) {
+	inspector, ok := base.(baseQueueInspector)
+	if !ok {
+		return nil, ErrItemNotFound
+	}
+	data, err := inspector.RemoveItem(ctx, func(data []byte) bool {
+		return itemID(data) == id
+	})
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		// the item has been popped in the meantime
+		return nil, ErrItemNotFound
+	}
+	return data, nil
+}
+
+// ListItems returns the items waiting in the queue, skipping the first skip ones and returning at most limit ones
+// if limit is positive, and the number of waiting items. The items which are being handled are not listed.
+func (q *WorkerPoolQueue[T]) ListItems(ctx context.Context, skip, limit int) ([]*Item, int, error) {
+	return q.listItems(ctx, q.baseQueue, skip, limit)
+}
+
+// RemoveItem removes an item waiting in the queue
+func (q *WorkerPoolQueue[T]) RemoveItem(ctx context.Context, id string) error {
+	data, err := q.removeItem(ctx, q.baseQueue, id)
+	if err != nil {
+		return err
+	}
+	q.forgetFailures(data)
+	return nil
+}
+
+// MoveItemToDeadLetters moves an item waiting in the queue to the dead letters of the queue, so it is not handled until it is requeued
+func (q *WorkerPoolQueue[T]) MoveItemToDeadLetters(ctx context.Context, id string) error {
+	if q.deadLetters == nil {
+		return ErrItemNotFound
+	}
+	data, err := q.removeItem(ctx, q.baseQueue, id)
+	if err != nil {
+		return err
+	}
+	q.forgetFailures(data)
+	if err := q.deadLetters.PushItem(ctx, data); err != nil {
+		// don't lose the item
+		_ = q.baseQueue.PushItem(ctx, data)
+		return err
+	}
+	return nil
+}
+
+// GetDeadLetterNumber returns the number of items which have been moved to the dead letters of the queue
+func (q *WorkerPoolQueue[T]) GetDeadLetterNumber() int {
+	if q.deadLetters == nil {
+		return 0
+	}
+	// the length of some base queues still counts the removed items which have not been popped (see removeLevelQueueItem),
+	// the dead letters are never popped
+	_, total, err := q.deadLetters.(baseQueueInspector).ListItems(q.ctxRun, 0, 1)
+	if err != nil {
+		log.Error("Failed to get number of dead letters in queue %q: %v", q.GetName(), err)
+	}
+	return total
+}
+
+// ListDeadLetters returns the items which have been moved to the dead letters of the queue, skipping the first skip ones
+// and returning at most limit ones if limit is positive, and the number of dead letters
+func (q *WorkerPoolQueue[T]) ListDeadLetters(ctx context.Context, skip, limit int) ([]*Item, int, error) {
+	if q.deadLetters == nil {
+		return nil, 0, nil
+	}
+	return q.listItems(ctx, q.deadLetters, skip, limit)
+}
+
+// RemoveDeadLetter removes an item from the dead letters of the queue, the item is lost
+func (q *WorkerPoolQueue[T]) RemoveDeadLetter(ctx context.Context, id string) error {
+	if q.deadLetters == nil {
+		return ErrItemNotFound
+	}
+	_, err := q.removeItem(ctx, q.deadLetters, id)
+	return err
+}
+
+// RequeueDeadLetter pushes an item of the dead letters back to the queue
+func (q *WorkerPoolQueue[T]) RequeueDeadLetter(ctx context.Context, id string) error {
+	if q.deadLetters == nil {
+		return ErrItemNotFound
+	}
+	data, err := q.removeItem(ctx, q.deadLetters, id)
+	if err != nil {
+		return err
+	}
+	return q.requeue(ctx, data)
+}
+
+func (q *WorkerPoolQueue[T]) requeue(ctx context.Context, data []byte) error {
+	if err := q.baseQueue.PushItem(ctx, data); err != nil && err != ErrAlreadyInQueue {
+		// don't lose the item
+		_ = q.deadLetters.PushItem(ctx, data)
+		return err
+	}
+	return nil
+}
+
+// RequeueAllDeadLetters pushes all the items of the dead letters back to the queue, it returns the number of requeued items
+func (q *WorkerPoolQueue[T]) RequeueAllDeadLetters(ctx context.Context) (int, error) {
+	if q.deadLetters == nil {
+		return 0, nil
+	}
+	inspector := q.deadLetters.(baseQueueInspector)
+	// the items which fail again while the dead letters are requeued are not requeued twice
+	_, total, err := inspector.ListItems(ctx, 0, 1)
+	if err != nil {
+		return 0, err
+	}
+	requeued := 0
+	for requeued < total {
+		data, err := inspector.RemoveItem(ctx, func([]byte) bool { return true })
+		if err != nil {
+			return requeued, err
+		}
+		if data == nil {
+			break
+		}
+		if err := q.requeue(ctx, data); err != nil {
+			return requeued, err
+		}
+		requeued++
+	}
+	return requeued, nil
+}
+
+// moveFailedItemToDeadLetters counts the failures of an item which hasn't been handled,
+// the item is moved to the dead letters once it failed too many times. It returns whether the item has been moved.
+func (q *WorkerPoolQueue[T]) moveFailedItemToDeadLetters(item T) bool {
+	if q.deadLetters == nil || q.maxFailures <= 0 {
+		return false
+	}
+
+	data := q.marshal(item)
+	q.failuresMu.Lock()
+	q.failures[string(data)]++
+	failures := q.failures[string(data)]
+	if failures < q.maxFailures {
+		q.failuresMu.Unlock()
+		return false
+	}
+	delete(q.failures, string(data))
+	q.failuresMu.Unlock()
+
+	if err := q.deadLetters.PushItem(q.ctxRun, data); err != nil {
+		log.Error("Failed to move item to the dead letters of queue %q: %v", q.GetName(), err)
+		return false
+	}
+	log.Warn("Queue %q failed to handle an item %d times, it has been moved to the dead letters", q.GetName(), failures)
+	q.ackItems(item)
+	return true
+}
+
+func (q *WorkerPoolQueue[T]) forgetFailures(data ...[]byte) {
+	q.failuresMu.Lock()
+	defer q.failuresMu.Unlock()
+	for _, d := range data {
+		delete(q.failures, string(d))
+	}
+}
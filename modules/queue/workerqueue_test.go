@@ -250,3 +250,105 @@ func TestWorkerPoolQueueShutdown(t *testing.T) {
 	q, _ = newWorkerPoolQueueForTest("test-workpoolqueue", qs, handler, false)
 	assert.EqualValues(t, 20, q.GetQueueItemNumber())
 }
+
+func TestWorkerPoolQueueDeadLetters(t *testing.T) {
+	oldUnhandledItemRequeueDuration := unhandledItemRequeueDuration.Load()
+	unhandledItemRequeueDuration.Store(0)
+	defer unhandledItemRequeueDuration.Store(oldUnhandledItemRequeueDuration)
+
+	// the item 3 is poisoned until it is cured
+	var mu sync.Mutex
+	cured := false
+	handled := map[int]int{}
+	handler := func(items ...int) (unhandled []int) {
+		mu.Lock()
+		defer mu.Unlock()
+		for _, item := range items {
+			if item == 3 && !cured {
+				unhandled = append(unhandled, item)
+				continue
+			}
+			handled[item]++
+		}
+		return unhandled
+	}
+
+	qs := setting.QueueSettings{Type: "level", Datadir: t.TempDir() + "/queue", BatchLength: 1, Length: 20, MaxFailures: 3}
+	q, _ := newWorkerPoolQueueForTest("test-workpoolqueue", qs, handler, false)
+	assert.True(t, q.CanInspectItems())
+	stop := runWorkerPoolQueue(q)
+	defer stop()
+
+	for i := 0; i < 5; i++ {
+		assert.NoError(t, q.Push(i))
+	}
+	assert.Eventually(t, func() bool { return q.GetDeadLetterNumber() == 1 }, 5*time.Second, 10*time.Millisecond)
+	assert.NoError(t, q.FlushWithContext(context.Background(), 0))
+
+	mu.Lock()
+	assert.Equal(t, map[int]int{0: 1, 1: 1, 2: 1, 4: 1}, handled)
+	cured = true
+	mu.Unlock()
+
+	items, _, err := q.ListDeadLetters(context.Background(), 0, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, "3", items[0].Data)
+		assert.Equal(t, "3", items[0].Content)
+		assert.True(t, items[0].IsValid)
+	}
+	assert.ErrorIs(t, q.RequeueDeadLetter(context.Background(), "unknown"), ErrItemNotFound)
+
+	requeued, err := q.RequeueAllDeadLetters(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, requeued)
+	assert.NoError(t, q.FlushWithContext(context.Background(), 0))
+	assert.EqualValues(t, 0, q.GetDeadLetterNumber())
+
+	mu.Lock()
+	assert.Equal(t, 1, handled[3])
+	mu.Unlock()
+}
+
+func TestWorkerPoolQueueInspectItems(t *testing.T) {
+	qs := setting.QueueSettings{Type: "level", Datadir: t.TempDir() + "/queue", Length: 20}
+	q, _ := newWorkerPoolQueueForTest("test-workpoolqueue", qs, func(items ...string) []string { return nil }, false)
+
+	// the queue is not running, the items wait in the base queue
+	for _, item := range []string{"foo", "bar", "baz"} {
+		assert.NoError(t, q.Push(item))
+	}
+	ctx := context.Background()
+	items, total, err := q.ListItems(ctx, 0, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	if !assert.Len(t, items, 3) {
+		return
+	}
+	assert.Equal(t, `"bar"`, items[1].Data)
+	page, total, err := q.ListItems(ctx, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, total)
+	if assert.Len(t, page, 1) {
+		assert.Equal(t, items[1].ID, page[0].ID)
+	}
+
+	assert.NoError(t, q.RemoveItem(ctx, items[0].ID))
+	assert.ErrorIs(t, q.RemoveItem(ctx, items[0].ID), ErrItemNotFound)
+	assert.NoError(t, q.MoveItemToDeadLetters(ctx, items[1].ID))
+	assert.EqualValues(t, 1, q.GetQueueItemNumber())
+	assert.EqualValues(t, 1, q.GetDeadLetterNumber())
+
+	items, _, err = q.ListItems(ctx, 0, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, items, 1) {
+		assert.Equal(t, `"baz"`, items[0].Data)
+	}
+	deadLetters, _, err := q.ListDeadLetters(ctx, 0, 0)
+	assert.NoError(t, err)
+	if assert.Len(t, deadLetters, 1) {
+		assert.Equal(t, `"bar"`, deadLetters[0].Data)
+		assert.NoError(t, q.RemoveDeadLetter(ctx, deadLetters[0].ID))
+	}
+	assert.EqualValues(t, 0, q.GetDeadLetterNumber())
+}
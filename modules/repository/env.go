@@ -30,6 +30,9 @@ const (
 	EnvActionPerm   = "GITEA_ACTION_PERM"
 )
 
+// InternalPushCommand is the SSH_ORIGINAL_COMMAND of the pushes made by Gitea itself
+const InternalPushCommand = "gitea-internal"
+
 // InternalPushingEnvironment returns an os environment to switch off hooks on push
 // It is recommended to avoid using this unless you are pushing within a transaction
 // or if you absolutely are sure that post-receive and pre-receive will do nothing
@@ -68,7 +71,7 @@ func FullPushingEnvironment(author, committer *user_model.User, repo *repo_model
 		EnvRepoID+"="+fmt.Sprintf("%d", repo.ID),
 		EnvPRID+"="+fmt.Sprintf("%d", prID),
 		EnvAppURL+"="+setting.AppURL,
-		"SSH_ORIGINAL_COMMAND=gitea-internal",
+		"SSH_ORIGINAL_COMMAND="+InternalPushCommand,
 	)
 
 	if !committer.KeepEmailPrivate {
@@ -3,6 +3,8 @@
 
 package setting
 
+import "time"
+
 // Migrations settings
 var Migrations = struct {
 	MaxAttempts        int
@@ -11,9 +13,13 @@ var Migrations = struct {
 	BlockedDomains     string
 	AllowLocalNetworks bool
 	SkipTLSVerify      bool
+	SyncInterval       time.Duration
+	MinSyncInterval    time.Duration
 }{
-	MaxAttempts:  3,
-	RetryBackoff: 3,
+	MaxAttempts:     3,
+	RetryBackoff:    3,
+	SyncInterval:    time.Hour,
+	MinSyncInterval: 10 * time.Minute,
 }
 
 func loadMigrationsFrom(rootCfg ConfigProvider) {
@@ -25,4 +31,9 @@ func loadMigrationsFrom(rootCfg ConfigProvider) {
 	Migrations.BlockedDomains = sec.Key("BLOCKED_DOMAINS").MustString("")
 	Migrations.AllowLocalNetworks = sec.Key("ALLOW_LOCALNETWORKS").MustBool(false)
 	Migrations.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool(false)
+	Migrations.SyncInterval = sec.Key("SYNC_INTERVAL").MustDuration(Migrations.SyncInterval)
+	Migrations.MinSyncInterval = sec.Key("MIN_SYNC_INTERVAL").MustDuration(Migrations.MinSyncInterval)
+	if Migrations.SyncInterval < Migrations.MinSyncInterval {
+		Migrations.SyncInterval = Migrations.MinSyncInterval
+	}
 }
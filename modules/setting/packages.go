@@ -9,6 +9,7 @@ import (
 	"net/url"
 	"os"
 	"path/filepath"
+	"time"
 
 	"github.com/dustin/go-humanize"
 )
@@ -21,9 +22,13 @@ var (
 		ChunkedUploadPath string
 		RegistryHost      string
 
+		RemoteMetadataTTL     time.Duration
+		RemoteAllowedHostList string
+
 		LimitTotalOwnerCount int64
 		LimitTotalOwnerSize  int64
 		LimitSizeAlpine      int64
+		LimitSizeArch        int64
 		LimitSizeCargo       int64
 		LimitSizeChef        int64
 		LimitSizeComposer    int64
@@ -43,10 +48,13 @@ var (
 		LimitSizeRpm         int64
 		LimitSizeRubyGems    int64
 		LimitSizeSwift       int64
+		LimitSizeTerraform   int64
 		LimitSizeVagrant     int64
 	}{
-		Enabled:              true,
-		LimitTotalOwnerCount: -1,
+		Enabled:               true,
+		LimitTotalOwnerCount:  -1,
+		RemoteMetadataTTL:     30 * time.Minute,
+		RemoteAllowedHostList: "external",
 	}
 )
 
@@ -78,8 +86,12 @@ func loadPackagesFrom(rootCfg ConfigProvider) (err error) {
 		return fmt.Errorf("unable to create chunked upload directory: %s (%v)", Packages.ChunkedUploadPath, err)
 	}
 
+	Packages.RemoteMetadataTTL = sec.Key("REMOTE_METADATA_TTL").MustDuration(Packages.RemoteMetadataTTL)
+	Packages.RemoteAllowedHostList = sec.Key("REMOTE_ALLOWED_HOST_LIST").MustString(Packages.RemoteAllowedHostList)
+
 	Packages.LimitTotalOwnerSize = mustBytes(sec, "LIMIT_TOTAL_OWNER_SIZE")
 	Packages.LimitSizeAlpine = mustBytes(sec, "LIMIT_SIZE_ALPINE")
+	Packages.LimitSizeArch = mustBytes(sec, "LIMIT_SIZE_ARCH")
 	Packages.LimitSizeCargo = mustBytes(sec, "LIMIT_SIZE_CARGO")
 	Packages.LimitSizeChef = mustBytes(sec, "LIMIT_SIZE_CHEF")
 	Packages.LimitSizeComposer = mustBytes(sec, "LIMIT_SIZE_COMPOSER")
@@ -99,6 +111,7 @@ func loadPackagesFrom(rootCfg ConfigProvider) (err error) {
 	Packages.LimitSizeRpm = mustBytes(sec, "LIMIT_SIZE_RPM")
 	Packages.LimitSizeRubyGems = mustBytes(sec, "LIMIT_SIZE_RUBYGEMS")
 	Packages.LimitSizeSwift = mustBytes(sec, "LIMIT_SIZE_SWIFT")
+	Packages.LimitSizeTerraform = mustBytes(sec, "LIMIT_SIZE_TERRAFORM")
 	Packages.LimitSizeVagrant = mustBytes(sec, "LIMIT_SIZE_VAGRANT")
 	return nil
 }
@@ -23,10 +23,11 @@ type QueueSettings struct {
 
 	BatchLength int
 	MaxWorkers  int
+	MaxFailures int // the number of times the handler may fail to handle an item before it is moved to the dead letters, 0 to retry forever
 }
 
 var queueSettingsDefault = QueueSettings{
-	Type:    "level",         // dummy, channel, level, redis
+	Type:    "level",         // dummy, channel, level, redis, redis-stream
 	Datadir: "queues/common", // relative to AppDataPath
 	Length:  100,             // queue length before a channel queue will block
 
@@ -70,7 +71,7 @@ func GetQueueSettings(rootCfg ConfigProvider, name string) (QueueSettings, error
 	}
 	cfg.Datadir = filepath.ToSlash(cfg.Datadir)
 
-	if cfg.Type == "redis" && cfg.ConnStr == "" {
+	if (cfg.Type == "redis" || cfg.Type == "redis-stream") && cfg.ConnStr == "" {
 		cfg.ConnStr = "redis://127.0.0.1:6379/0"
 	}
 
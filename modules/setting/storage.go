@@ -17,11 +17,14 @@ const (
 	LocalStorageType StorageType = "local"
 	// MinioStorageType is the type descriptor for minio storage
 	MinioStorageType StorageType = "minio"
+	// AzureBlobStorageType is the type descriptor for azure blob storage
+	AzureBlobStorageType StorageType = "azureblob"
 )
 
 var storageTypes = []StorageType{
 	LocalStorageType,
 	MinioStorageType,
+	AzureBlobStorageType,
 }
 
 // IsValidStorageType returns true if the given storage type is valid
@@ -48,12 +51,23 @@ type MinioStorageConfig struct {
 	ServeDirect        bool   `ini:"SERVE_DIRECT"`
 }
 
+// AzureBlobStorageConfig represents the configuration for an azure blob storage
+type AzureBlobStorageConfig struct {
+	Endpoint    string `ini:"AZURE_BLOB_ENDPOINT" json:",omitempty"`
+	AccountName string `ini:"AZURE_BLOB_ACCOUNT_NAME" json:",omitempty"`
+	AccountKey  string `ini:"AZURE_BLOB_ACCOUNT_KEY" json:",omitempty"`
+	Container   string `ini:"AZURE_BLOB_CONTAINER" json:",omitempty"`
+	BasePath    string `ini:"AZURE_BLOB_BASE_PATH" json:",omitempty"`
+	ServeDirect bool   `ini:"SERVE_DIRECT"`
+}
+
 // Storage represents configuration of storages
 type Storage struct {
-	Type          StorageType        // local or minio
-	Path          string             `json:",omitempty"` // for local type
-	TemporaryPath string             `json:",omitempty"`
-	MinioConfig   MinioStorageConfig // for minio type
+	Type            StorageType            // local, minio or azureblob
+	Path            string                 `json:",omitempty"` // for local type
+	TemporaryPath   string                 `json:",omitempty"`
+	MinioConfig     MinioStorageConfig     // for minio type
+	AzureBlobConfig AzureBlobStorageConfig // for azureblob type
 }
 
 func (storage *Storage) ToShadowCopy() Storage {
@@ -64,9 +78,18 @@ func (storage *Storage) ToShadowCopy() Storage {
 	if shadowStorage.MinioConfig.SecretAccessKey != "" {
 		shadowStorage.MinioConfig.SecretAccessKey = "******"
 	}
+	if shadowStorage.AzureBlobConfig.AccountKey != "" {
+		shadowStorage.AzureBlobConfig.AccountKey = "******"
+	}
 	return shadowStorage
 }
 
+// ServeDirect returns true if the files of the storage could be served by redirecting to their URLs
+func (storage *Storage) ServeDirect() bool {
+	return (storage.Type == MinioStorageType && storage.MinioConfig.ServeDirect) ||
+		(storage.Type == AzureBlobStorageType && storage.AzureBlobConfig.ServeDirect)
+}
+
 const storageSectionName = "storage"
 
 func getDefaultStorageSection(rootCfg ConfigProvider) ConfigSection {
@@ -81,6 +104,10 @@ func getDefaultStorageSection(rootCfg ConfigProvider) ConfigSection {
 	storageSec.Key("MINIO_USE_SSL").MustBool(false)
 	storageSec.Key("MINIO_INSECURE_SKIP_VERIFY").MustBool(false)
 	storageSec.Key("MINIO_CHECKSUM_ALGORITHM").MustString("default")
+	storageSec.Key("AZURE_BLOB_ENDPOINT").MustString("")
+	storageSec.Key("AZURE_BLOB_ACCOUNT_NAME").MustString("")
+	storageSec.Key("AZURE_BLOB_ACCOUNT_KEY").MustString("")
+	storageSec.Key("AZURE_BLOB_CONTAINER").MustString("gitea")
 	return storageSec
 }
 
@@ -179,6 +206,23 @@ func getStorage(rootCfg ConfigProvider, name, typ string, sec ConfigSection) (*S
 			storage.MinioConfig.BasePath = ConfigSectionKeyString(extraConfigSec, "MINIO_BASE_PATH", storage.MinioConfig.BasePath)
 			storage.MinioConfig.Bucket = ConfigSectionKeyString(extraConfigSec, "MINIO_BUCKET", storage.MinioConfig.Bucket)
 		}
+	case string(AzureBlobStorageType):
+		storage.AzureBlobConfig.BasePath = name + "/"
+
+		if err := targetSec.MapTo(&storage.AzureBlobConfig); err != nil {
+			return nil, fmt.Errorf("map azure blob config failed: %v", err)
+		}
+		// extra config section will be read SERVE_DIRECT, AZURE_BLOB_BASE_PATH, AZURE_BLOB_CONTAINER to override the targetsec
+		extraConfigSec := sec
+		if extraConfigSec == nil {
+			extraConfigSec = storageNameSec
+		}
+
+		if extraConfigSec != nil {
+			storage.AzureBlobConfig.ServeDirect = ConfigSectionKeyBool(extraConfigSec, "SERVE_DIRECT", storage.AzureBlobConfig.ServeDirect)
+			storage.AzureBlobConfig.BasePath = ConfigSectionKeyString(extraConfigSec, "AZURE_BLOB_BASE_PATH", storage.AzureBlobConfig.BasePath)
+			storage.AzureBlobConfig.Container = ConfigSectionKeyString(extraConfigSec, "AZURE_BLOB_CONTAINER", storage.AzureBlobConfig.Container)
+		}
 	}
 
 	return &storage, nil
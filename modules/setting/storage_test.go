@@ -90,3 +90,32 @@ STORAGE_TYPE = minio
 	assert.EqualValues(t, "gitea", RepoAvatar.Storage.MinioConfig.Bucket)
 	assert.EqualValues(t, "repo-avatars/", RepoAvatar.Storage.MinioConfig.BasePath)
 }
+
+func Test_getStorageAzureBlob(t *testing.T) {
+	iniStr := `
+[storage]
+STORAGE_TYPE = azureblob
+AZURE_BLOB_ENDPOINT = http://127.0.0.1:10000/devstoreaccount1
+AZURE_BLOB_ACCOUNT_NAME = devstoreaccount1
+AZURE_BLOB_ACCOUNT_KEY = secret
+
+[attachment]
+SERVE_DIRECT = true
+AZURE_BLOB_CONTAINER = gitea-attachment
+`
+	cfg, err := NewConfigProviderFromData(iniStr)
+	assert.NoError(t, err)
+
+	assert.NoError(t, loadAttachmentFrom(cfg))
+	assert.EqualValues(t, AzureBlobStorageType, Attachment.Storage.Type)
+	assert.EqualValues(t, "http://127.0.0.1:10000/devstoreaccount1", Attachment.Storage.AzureBlobConfig.Endpoint)
+	assert.EqualValues(t, "gitea-attachment", Attachment.Storage.AzureBlobConfig.Container)
+	assert.EqualValues(t, "attachments/", Attachment.Storage.AzureBlobConfig.BasePath)
+	assert.True(t, Attachment.Storage.ServeDirect())
+	assert.EqualValues(t, "******", Attachment.Storage.ToShadowCopy().AzureBlobConfig.AccountKey)
+
+	assert.NoError(t, loadLFSFrom(cfg))
+	assert.EqualValues(t, "gitea", LFS.Storage.AzureBlobConfig.Container)
+	assert.EqualValues(t, "lfs/", LFS.Storage.AzureBlobConfig.BasePath)
+	assert.False(t, LFS.Storage.ServeDirect())
+}
@@ -5,28 +5,36 @@ package setting
 
 import (
 	"net/url"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
 
 // Webhook settings
 var Webhook = struct {
-	QueueLength     int
-	DeliverTimeout  int
-	SkipTLSVerify   bool
-	AllowedHostList string
-	Types           []string
-	PagingNum       int
-	ProxyURL        string
-	ProxyURLFixed   *url.URL
-	ProxyHosts      []string
+	QueueLength              int
+	DeliverTimeout           int
+	SkipTLSVerify            bool
+	AllowedHostList          string
+	Types                    []string
+	PagingNum                int
+	ProxyURL                 string
+	ProxyURLFixed            *url.URL
+	ProxyHosts               []string
+	MaxRetries               int
+	RetryInitialInterval     time.Duration
+	RetryMaxInterval         time.Duration
+	AutoDisableAfterFailures int
 }{
-	QueueLength:    1000,
-	DeliverTimeout: 5,
-	SkipTLSVerify:  false,
-	PagingNum:      10,
-	ProxyURL:       "",
-	ProxyHosts:     []string{},
+	QueueLength:          1000,
+	DeliverTimeout:       5,
+	SkipTLSVerify:        false,
+	PagingNum:            10,
+	ProxyURL:             "",
+	ProxyHosts:           []string{},
+	MaxRetries:           3,
+	RetryInitialInterval: 30 * time.Second,
+	RetryMaxInterval:     time.Hour,
 }
 
 func loadWebhookFrom(rootCfg ConfigProvider) {
@@ -35,7 +43,7 @@ func loadWebhookFrom(rootCfg ConfigProvider) {
 	Webhook.DeliverTimeout = sec.Key("DELIVER_TIMEOUT").MustInt(5)
 	Webhook.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool()
 	Webhook.AllowedHostList = sec.Key("ALLOWED_HOST_LIST").MustString("")
-	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix", "wechatwork", "packagist"}
+	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix", "wechatwork", "packagist", "custom"}
 	Webhook.PagingNum = sec.Key("PAGING_NUM").MustInt(10)
 	Webhook.ProxyURL = sec.Key("PROXY_URL").MustString("")
 	if Webhook.ProxyURL != "" {
@@ -47,4 +55,11 @@ func loadWebhookFrom(rootCfg ConfigProvider) {
 		}
 	}
 	Webhook.ProxyHosts = sec.Key("PROXY_HOSTS").Strings(",")
+	Webhook.MaxRetries = sec.Key("MAX_RETRIES").MustInt(3)
+	Webhook.RetryInitialInterval = sec.Key("RETRY_INITIAL_INTERVAL").MustDuration(30 * time.Second)
+	Webhook.RetryMaxInterval = sec.Key("RETRY_MAX_INTERVAL").MustDuration(time.Hour)
+	if Webhook.RetryMaxInterval < Webhook.RetryInitialInterval {
+		Webhook.RetryMaxInterval = Webhook.RetryInitialInterval
+	}
+	Webhook.AutoDisableAfterFailures = sec.Key("AUTO_DISABLE_AFTER_FAILURES").MustInt(0)
 }
@@ -0,0 +1,330 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/blob"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/bloberror"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/container"
+	"github.com/Azure/azure-sdk-for-go/sdk/storage/azblob/sas"
+)
+
+var _ ObjectStorage = &AzureBlobStorage{}
+
+// azureBlobObject reads the blob from a single download stream which is only reopened at the new offset after seeking,
+// so it could be seeked without downloading the whole blob
+type azureBlobObject struct {
+	ctx        context.Context
+	blobClient *blob.Client
+	name       string
+	size       int64
+	modTime    time.Time
+	offset     int64
+	body       io.ReadCloser
+}
+
+func (a *azureBlobObject) Read(p []byte) (int, error) {
+	if a.offset >= a.size {
+		return 0, io.EOF
+	}
+
+	if a.body == nil {
+		resp, err := a.blobClient.DownloadStream(a.ctx, &blob.DownloadStreamOptions{
+			Range: blob.HTTPRange{
+				Offset: a.offset,
+			},
+		})
+		if err != nil {
+			return 0, convertAzureBlobErr(err)
+		}
+		a.body = resp.Body
+	}
+
+	n, err := a.body.Read(p)
+	a.offset += int64(n)
+	if err == io.EOF && a.offset < a.size {
+		err = io.ErrUnexpectedEOF
+	}
+	return n, err
+}
+
+func (a *azureBlobObject) Close() error {
+	if a.body == nil {
+		return nil
+	}
+	err := a.body.Close()
+	a.body = nil
+	return err
+}
+
+func (a *azureBlobObject) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += a.offset
+	case io.SeekEnd:
+		offset += a.size
+	default:
+		return 0, errors.New("Seek: invalid whence")
+	}
+
+	if offset < 0 {
+		return 0, errors.New("Seek: invalid offset")
+	}
+	if offset != a.offset {
+		// the stream is reopened at the new offset by the next read
+		if err := a.Close(); err != nil {
+			return 0, err
+		}
+		a.offset = offset
+	}
+	return offset, nil
+}
+
+func (a *azureBlobObject) Stat() (os.FileInfo, error) {
+	return &azureBlobFileInfo{
+		name:    a.name,
+		size:    a.size,
+		modTime: a.modTime,
+	}, nil
+}
+
+// AzureBlobStorage returns an azure blob container storage
+type AzureBlobStorage struct {
+	cfg        *setting.AzureBlobStorageConfig
+	ctx        context.Context
+	credential *azblob.SharedKeyCredential
+	client     *azblob.Client
+}
+
+func convertAzureBlobErr(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	// Convert two responses to standard analogues
+	switch {
+	case bloberror.HasCode(err, bloberror.BlobNotFound):
+		return os.ErrNotExist
+	case bloberror.HasCode(err, bloberror.AuthorizationFailure, bloberror.AuthenticationFailed):
+		return os.ErrPermission
+	}
+
+	return err
+}
+
+// NewAzureBlobStorage returns an azure blob storage
+func NewAzureBlobStorage(ctx context.Context, cfg *setting.Storage) (ObjectStorage, error) {
+	config := cfg.AzureBlobConfig
+
+	log.Info("Creating Azure Blob storage at %s:%s with base path %s", config.Endpoint, config.Container, config.BasePath)
+
+	cred, err := azblob.NewSharedKeyCredential(config.AccountName, config.AccountKey)
+	if err != nil {
+		return nil, fmt.Errorf("invalid azure blob credential: %w", err)
+	}
+	client, err := azblob.NewClientWithSharedKeyCredential(config.Endpoint, cred, &azblob.ClientOptions{})
+	if err != nil {
+		return nil, convertAzureBlobErr(err)
+	}
+
+	if _, err := client.CreateContainer(ctx, config.Container, &container.CreateOptions{}); err != nil {
+		// Check to see if we already own this container (which happens if you run this twice)
+		if !bloberror.HasCode(err, bloberror.ContainerAlreadyExists) {
+			return nil, convertAzureBlobErr(err)
+		}
+	}
+
+	return &AzureBlobStorage{
+		cfg:        &config,
+		ctx:        ctx,
+		credential: cred,
+		client:     client,
+	}, nil
+}
+
+func (a *AzureBlobStorage) buildAzureBlobPath(p string) string {
+	p = util.PathJoinRelX(a.cfg.BasePath, p)
+	if p == "." {
+		p = "" // azure blob doesn't use dot as relative path
+	}
+	return p
+}
+
+func (a *AzureBlobStorage) getBlobClient(blobName string) *blob.Client {
+	return a.client.ServiceClient().NewContainerClient(a.cfg.Container).NewBlobClient(blobName)
+}
+
+// Open opens a file
+func (a *AzureBlobStorage) Open(path string) (Object, error) {
+	blobName := a.buildAzureBlobPath(path)
+	blobClient := a.getBlobClient(blobName)
+	res, err := blobClient.GetProperties(a.ctx, &blob.GetPropertiesOptions{})
+	if err != nil {
+		return nil, convertAzureBlobErr(err)
+	}
+	return &azureBlobObject{
+		ctx:        a.ctx,
+		blobClient: blobClient,
+		name:       blobName,
+		size:       *res.ContentLength,
+		modTime:    *res.LastModified,
+	}, nil
+}
+
+// Save saves a file to azure blob storage
+func (a *AzureBlobStorage) Save(path string, r io.Reader, size int64) (int64, error) {
+	rd := &countingReader{r: r}
+	contentType := "application/octet-stream"
+	if _, err := a.client.UploadStream(a.ctx, a.cfg.Container, a.buildAzureBlobPath(path), rd, &azblob.UploadStreamOptions{
+		HTTPHeaders: &blob.HTTPHeaders{BlobContentType: &contentType},
+	}); err != nil {
+		return 0, convertAzureBlobErr(err)
+	}
+	return rd.n, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+type azureBlobFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (a azureBlobFileInfo) Name() string {
+	return path.Base(a.name)
+}
+
+func (a azureBlobFileInfo) Size() int64 {
+	return a.size
+}
+
+func (a azureBlobFileInfo) ModTime() time.Time {
+	return a.modTime
+}
+
+func (a azureBlobFileInfo) IsDir() bool {
+	return strings.HasSuffix(a.name, "/")
+}
+
+func (a azureBlobFileInfo) Mode() os.FileMode {
+	return os.ModePerm
+}
+
+func (a azureBlobFileInfo) Sys() interface{} {
+	return nil
+}
+
+// Stat returns the stat information of the object
+func (a *AzureBlobStorage) Stat(path string) (os.FileInfo, error) {
+	blobName := a.buildAzureBlobPath(path)
+	res, err := a.getBlobClient(blobName).GetProperties(a.ctx, &blob.GetPropertiesOptions{})
+	if err != nil {
+		return nil, convertAzureBlobErr(err)
+	}
+	return &azureBlobFileInfo{
+		name:    blobName,
+		size:    *res.ContentLength,
+		modTime: *res.LastModified,
+	}, nil
+}
+
+// Delete delete a file
+func (a *AzureBlobStorage) Delete(path string) error {
+	_, err := a.client.DeleteBlob(a.ctx, a.cfg.Container, a.buildAzureBlobPath(path), nil)
+	if bloberror.HasCode(err, bloberror.BlobNotFound) {
+		return nil // keep the same behavior as the other storages
+	}
+	return convertAzureBlobErr(err)
+}
+
+// URL gets the redirect URL to a file. The SAS signed link is valid for 5 minutes.
+func (a *AzureBlobStorage) URL(path, name string) (*url.URL, error) {
+	blobName := a.buildAzureBlobPath(path)
+	u, err := url.Parse(a.getBlobClient(blobName).URL())
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	params, err := sas.BlobSignatureValues{
+		Protocol:      sas.ProtocolHTTPSandHTTP,
+		StartTime:     now.Add(-5 * time.Minute), // tolerate the clock skew
+		ExpiryTime:    now.Add(5 * time.Minute),
+		Permissions:   (&sas.BlobPermissions{Read: true}).String(),
+		ContainerName: a.cfg.Container,
+		BlobName:      blobName,
+		// TODO it may be good to embed images with 'inline' like ServeData does, but we don't want to have to read the file, do we?
+		ContentDisposition: "attachment; filename=\"" + quoteEscaper.Replace(name) + "\"",
+	}.SignWithSharedKey(a.credential)
+	if err != nil {
+		return nil, err
+	}
+	u.RawQuery = params.Encode()
+	return u, nil
+}
+
+// IterateObjects iterates across the objects in the azureblobstorage
+func (a *AzureBlobStorage) IterateObjects(dirName string, fn func(path string, obj Object) error) error {
+	prefix := a.cfg.BasePath
+	if dirName != "" {
+		// ending slash is required for avoiding matching like "foo/" and "foobar/" with prefix "foo"
+		// but not for the root directory, blob names never start with a slash
+		if p := a.buildAzureBlobPath(dirName); p != "" {
+			prefix = p + "/"
+		}
+	}
+
+	pager := a.client.NewListBlobsFlatPager(a.cfg.Container, &container.ListBlobsFlatOptions{
+		Prefix: &prefix,
+	})
+	for pager.More() {
+		resp, err := pager.NextPage(a.ctx)
+		if err != nil {
+			return convertAzureBlobErr(err)
+		}
+		for _, item := range resp.Segment.BlobItems {
+			object := &azureBlobObject{
+				ctx:        a.ctx,
+				blobClient: a.getBlobClient(*item.Name),
+				name:       *item.Name,
+				size:       *item.Properties.ContentLength,
+				modTime:    *item.Properties.LastModified,
+			}
+			if err := fn(strings.TrimPrefix(*item.Name, a.cfg.BasePath), object); err != nil {
+				return convertAzureBlobErr(err)
+			}
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterStorageType(setting.AzureBlobStorageType, NewAzureBlobStorage)
+}
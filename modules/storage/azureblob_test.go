@@ -0,0 +1,238 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package storage
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	azuriteAccountName = "devstoreaccount1"
+	// https://learn.microsoft.com/azure/storage/common/storage-use-azurite?tabs=visual-studio-code#well-known-storage-account-and-key
+	azuriteAccountKey = "Eby8vdM02xNOcqFlqUwJPLlmEtlCDXJ1OUzFT50uSRZ6IFsuFq2UVErCz4I6tq/K1SZFPTOtr/KBHBeksoGMGw=="
+)
+
+func TestAzureBlobStorageIterator(t *testing.T) {
+	if os.Getenv("CI") == "" {
+		t.Skip("azureBlobStorage not present outside of CI")
+		return
+	}
+	testStorageIterator(t, setting.AzureBlobStorageType, &setting.Storage{
+		AzureBlobConfig: setting.AzureBlobStorageConfig{
+			// https://learn.microsoft.com/azure/storage/common/storage-use-azurite?tabs=visual-studio-code#ip-style-url
+			Endpoint:    "http://127.0.0.1:10000/devstoreaccount1/",
+			AccountName: azuriteAccountName,
+			AccountKey:  azuriteAccountKey,
+			Container:   "test",
+		},
+	})
+}
+
+// fakeAzureBlobServer implements the subset of the blob service REST API used by AzureBlobStorage
+type fakeAzureBlobServer struct {
+	mu        sync.Mutex
+	blobs     map[string][]byte
+	blocks    map[string][]byte
+	downloads []string // the ranges of the download requests
+}
+
+func newFakeAzureBlobServer(t *testing.T) (*fakeAzureBlobServer, *httptest.Server) {
+	f := &fakeAzureBlobServer{
+		blobs:  map[string][]byte{},
+		blocks: map[string][]byte{},
+	}
+	srv := httptest.NewServer(f)
+	t.Cleanup(srv.Close)
+	return f, srv
+}
+
+func (f *fakeAzureBlobServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	// the path is /{account}/{container}[/{blob}]
+	parts := strings.SplitN(strings.TrimPrefix(r.URL.Path, "/"), "/", 3)
+	query := r.URL.Query()
+	lastModified := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC).Format(http.TimeFormat)
+
+	if len(parts) == 2 {
+		switch {
+		case r.Method == http.MethodPut && query.Get("restype") == "container":
+			w.WriteHeader(http.StatusCreated)
+		case r.Method == http.MethodGet && query.Get("comp") == "list":
+			f.list(w, query.Get("prefix"), lastModified)
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+		return
+	}
+	if len(parts) != 3 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	name := parts[2]
+
+	switch {
+	case r.Method == http.MethodPut && query.Get("comp") == "block":
+		data, _ := io.ReadAll(r.Body)
+		f.blocks[query.Get("blockid")] = data
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut && query.Get("comp") == "blocklist":
+		var list struct {
+			IDs []string `xml:",any"`
+		}
+		if err := xml.NewDecoder(r.Body).Decode(&list); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		var data []byte
+		for _, id := range list.IDs {
+			data = append(data, f.blocks[id]...)
+		}
+		f.blobs[name] = data
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodPut:
+		data, _ := io.ReadAll(r.Body)
+		f.blobs[name] = data
+		w.WriteHeader(http.StatusCreated)
+	case r.Method == http.MethodHead, r.Method == http.MethodGet:
+		data, ok := f.blobs[name]
+		if !ok {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Header().Set("Last-Modified", lastModified)
+		w.Header().Set("x-ms-blob-type", "BlockBlob")
+		if r.Method == http.MethodHead {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			return
+		}
+
+		rng := r.Header.Get("x-ms-range")
+		f.downloads = append(f.downloads, rng)
+		if rng == "" {
+			w.Header().Set("Content-Length", strconv.Itoa(len(data)))
+			_, _ = w.Write(data)
+			return
+		}
+		start, _ := strconv.Atoi(strings.TrimSuffix(strings.TrimPrefix(rng, "bytes="), "-"))
+		w.Header().Set("Content-Length", strconv.Itoa(len(data)-start))
+		w.Header().Set("Content-Range", fmt.Sprintf("bytes %d-%d/%d", start, len(data)-1, len(data)))
+		w.WriteHeader(http.StatusPartialContent)
+		_, _ = w.Write(data[start:])
+	case r.Method == http.MethodDelete:
+		if _, ok := f.blobs[name]; !ok {
+			w.Header().Set("x-ms-error-code", "BlobNotFound")
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		delete(f.blobs, name)
+		w.WriteHeader(http.StatusAccepted)
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+	}
+}
+
+func (f *fakeAzureBlobServer) list(w http.ResponseWriter, prefix, lastModified string) {
+	names := make([]string, 0, len(f.blobs))
+	for name := range f.blobs {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sb strings.Builder
+	sb.WriteString(`<?xml version="1.0" encoding="utf-8"?><EnumerationResults><Blobs>`)
+	for _, name := range names {
+		sb.WriteString("<Blob><Name>")
+		_ = xml.EscapeText(&sb, []byte(name))
+		fmt.Fprintf(&sb, "</Name><Properties><Last-Modified>%s</Last-Modified><Content-Length>%d</Content-Length><BlobType>BlockBlob</BlobType></Properties></Blob>", lastModified, len(f.blobs[name]))
+	}
+	sb.WriteString("</Blobs><NextMarker/></EnumerationResults>")
+	w.Header().Set("Content-Type", "application/xml")
+	_, _ = w.Write([]byte(sb.String()))
+}
+
+func newFakeAzureBlobStorageConfig(srv *httptest.Server) *setting.Storage {
+	return &setting.Storage{
+		AzureBlobConfig: setting.AzureBlobStorageConfig{
+			Endpoint:    srv.URL + "/" + azuriteAccountName + "/",
+			AccountName: azuriteAccountName,
+			AccountKey:  azuriteAccountKey,
+			Container:   "test",
+		},
+	}
+}
+
+func TestAzureBlobStorageIteratorFakeServer(t *testing.T) {
+	_, srv := newFakeAzureBlobServer(t)
+	testStorageIterator(t, setting.AzureBlobStorageType, newFakeAzureBlobStorageConfig(srv))
+}
+
+func TestAzureBlobStorageReadSeek(t *testing.T) {
+	fake, srv := newFakeAzureBlobServer(t)
+	s, err := NewStorage(setting.AzureBlobStorageType, newFakeAzureBlobStorageConfig(srv))
+	assert.NoError(t, err)
+
+	content := "0123456789abcdefghijklmnopqrstuvwxyz"
+	_, err = s.Save("file.txt", strings.NewReader(content), -1)
+	assert.NoError(t, err)
+
+	obj, err := s.Open("file.txt")
+	assert.NoError(t, err)
+	defer obj.Close()
+
+	// small reads share a single download stream
+	buf := make([]byte, 4)
+	for i := 0; i < 3; i++ {
+		n, err := io.ReadFull(obj, buf)
+		assert.NoError(t, err)
+		assert.Equal(t, content[i*4:i*4+n], string(buf[:n]))
+	}
+	assert.Equal(t, []string{""}, fake.downloads)
+
+	// seeking to the current offset keeps the stream
+	offset, err := obj.Seek(0, io.SeekCurrent)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 12, offset)
+	n, err := io.ReadFull(obj, buf)
+	assert.NoError(t, err)
+	assert.Equal(t, "cdef", string(buf[:n]))
+	assert.Len(t, fake.downloads, 1)
+
+	// seeking elsewhere reopens the stream at the new offset
+	_, err = obj.Seek(-6, io.SeekEnd)
+	assert.NoError(t, err)
+	rest, err := io.ReadAll(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "uvwxyz", string(rest))
+	assert.Equal(t, []string{"", "bytes=30-"}, fake.downloads)
+
+	n, err = obj.Read(buf)
+	assert.Equal(t, 0, n)
+	assert.Equal(t, io.EOF, err)
+
+	_, err = s.Open("missing.txt")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	assert.NoError(t, s.Delete("file.txt"))
+	_, err = s.Stat("file.txt")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
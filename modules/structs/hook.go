@@ -40,7 +40,7 @@ type CreateHookOptionConfig map[string]string
 // CreateHookOption options when create a hook
 type CreateHookOption struct {
 	// required: true
-	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu,wechatwork,packagist
+	// enum: dingtalk,discord,gitea,gogs,msteams,slack,telegram,feishu,wechatwork,packagist,custom
 	Type string `json:"type" binding:"Required"`
 	// required: true
 	Config              CreateHookOptionConfig `json:"config" binding:"Required"`
@@ -58,6 +58,10 @@ type EditHookOption struct {
 	BranchFilter        string            `json:"branch_filter" binding:"GlobPattern"`
 	AuthorizationHeader string            `json:"authorization_header"`
 	Active              *bool             `json:"active"`
+	// number of hours the replaced secret keeps signing the deliveries when `config.secret` changes the secret, at most 720
+	SecretRotationHours int `json:"secret_rotation_hours"`
+	// stop signing the deliveries with the secrets which have been replaced
+	RevokePreviousSecrets bool `json:"revoke_previous_secrets"`
 }
 
 // Payloader payload is some part of one hook
@@ -113,6 +117,8 @@ var (
 	_ Payloader = &RepositoryPayload{}
 	_ Payloader = &ReleasePayload{}
 	_ Payloader = &PackagePayload{}
+	_ Payloader = &WorkflowDispatchPayload{}
+	_ Payloader = &SchedulePayload{}
 )
 
 // _________                        __
@@ -493,3 +499,29 @@ type PackagePayload struct {
 func (p *PackagePayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
+
+// WorkflowDispatchPayload represents a payload information of workflow dispatch event.
+type WorkflowDispatchPayload struct {
+	Workflow   string            `json:"workflow"`
+	Ref        string            `json:"ref"`
+	Inputs     map[string]string `json:"inputs"`
+	Repository *Repository       `json:"repository"`
+	Sender     *User             `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *WorkflowDispatchPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+// SchedulePayload represents a payload information of schedule event.
+type SchedulePayload struct {
+	Schedule   string      `json:"schedule"`
+	Repository *Repository `json:"repository"`
+	Sender     *User       `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *SchedulePayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
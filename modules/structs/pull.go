@@ -110,3 +110,18 @@ type ChangedFile struct {
 	ContentsURL      string `json:"contents_url,omitempty"`
 	RawURL           string `json:"raw_url,omitempty"`
 }
+
+// MergeQueueEntry represents a pull request waiting in the merge queue of its base branch
+type MergeQueueEntry struct {
+	// index of the pull request
+	Index      int64  `json:"index"`
+	BaseBranch string `json:"base_branch"`
+	// 1-based position in the merge queue
+	Position   int64  `json:"position"`
+	MergeStyle string `json:"merge_style"`
+	// commit the required status checks are run against, empty while it has not been built yet
+	QueueCommitID string `json:"queue_commit_id"`
+	Doer          *User  `json:"doer"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
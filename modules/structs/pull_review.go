@@ -65,6 +65,9 @@ type PullReviewComment struct {
 	DiffHunk     string `json:"diff_hunk"`
 	LineNum      uint64 `json:"position"`
 	OldLineNum   uint64 `json:"original_position"`
+	// the first line of a multi-line comment, 0 if it's a single line comment
+	StartLineNum    uint64 `json:"start_position"`
+	OldStartLineNum uint64 `json:"original_start_position"`
 
 	HTMLURL     string `json:"html_url"`
 	HTMLPullURL string `json:"pull_request_url"`
@@ -87,6 +90,10 @@ type CreatePullReviewComment struct {
 	OldLineNum int64 `json:"old_position"`
 	// if comment to new file line or 0
 	NewLineNum int64 `json:"new_position"`
+	// the first line of the old file lines to comment on if it's a multi-line comment, or 0
+	OldStartLineNum int64 `json:"old_start_position"`
+	// the first line of the new file lines to comment on if it's a multi-line comment, or 0
+	NewStartLineNum int64 `json:"new_start_position"`
 }
 
 // SubmitPullReviewOptions are options to submit a pending pull review
@@ -0,0 +1,27 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package structs
+
+// Queue represents a queue of background tasks
+type Queue struct {
+	ID                  int64  `json:"id"`
+	Name                string `json:"name"`
+	Type                string `json:"type"`
+	ItemType            string `json:"item_type"`
+	NumberOfWorkers     int    `json:"number_of_workers"`
+	MaxNumberOfWorkers  int    `json:"max_number_of_workers"`
+	NumberOfItems       int    `json:"number_of_items"`
+	NumberOfDeadLetters int    `json:"number_of_dead_letters"`
+	// whether the waiting items and the dead letters can be listed
+	CanInspectItems bool `json:"can_inspect_items"`
+}
+
+// QueueItem represents an item waiting in a queue or in its dead letters
+type QueueItem struct {
+	ID string `json:"id"`
+	// the data stored in the queue
+	Data string `json:"data"`
+	// false if the data can't be decoded with the item type of the queue, such an item is never handled
+	Valid bool `json:"valid"`
+}
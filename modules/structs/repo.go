@@ -275,21 +275,23 @@ type GitServiceType int
 
 // enumerate all GitServiceType
 const (
-	NotMigrated      GitServiceType = iota // 0 not migrated from external sites
-	PlainGitService                        // 1 plain git service
-	GithubService                          // 2 github.com
-	GiteaService                           // 3 gitea service
-	GitlabService                          // 4 gitlab service
-	GogsService                            // 5 gogs service
-	OneDevService                          // 6 onedev service
-	GitBucketService                       // 7 gitbucket service
-	CodebaseService                        // 8 codebase service
+	NotMigrated        GitServiceType = iota // 0 not migrated from external sites
+	PlainGitService                          // 1 plain git service
+	GithubService                            // 2 github.com
+	GiteaService                             // 3 gitea service
+	GitlabService                            // 4 gitlab service
+	GogsService                              // 5 gogs service
+	OneDevService                            // 6 onedev service
+	GitBucketService                         // 7 gitbucket service
+	CodebaseService                          // 8 codebase service
+	BitbucketService                         // 9 bitbucket service
+	AzureDevOpsService                       // 10 azure devops service
 )
 
 // Name represents the service type's name
 // WARNNING: the name have to be equal to that on goth's library
 func (gt GitServiceType) Name() string {
-	return strings.ToLower(gt.Title())
+	return strings.ToLower(strings.ReplaceAll(gt.Title(), " ", ""))
 }
 
 // Title represents the service type's proper title
@@ -309,6 +311,10 @@ func (gt GitServiceType) Title() string {
 		return "GitBucket"
 	case CodebaseService:
 		return "Codebase"
+	case BitbucketService:
+		return "Bitbucket"
+	case AzureDevOpsService:
+		return "Azure DevOps"
 	case PlainGitService:
 		return "Git"
 	}
@@ -345,12 +351,24 @@ type MigrateRepoOptions struct {
 	PullRequests   bool   `json:"pull_requests"`
 	Releases       bool   `json:"releases"`
 	MirrorInterval string `json:"mirror_interval"`
+	// keep pulling the milestones, labels, releases, issues, pull requests and comments updated on the source after the migration,
+	// only supported for GitHub and GitLab
+	Sync bool `json:"sync"`
 }
 
 // TokenAuth represents whether a service type supports token-based auth
 func (gt GitServiceType) TokenAuth() bool {
 	switch gt {
-	case GithubService, GiteaService, GitlabService:
+	case GithubService, GiteaService, GitlabService, AzureDevOpsService:
+		return true
+	}
+	return false
+}
+
+// IncrementalSync represents whether a service type supports the synchronization of the migrated repositories with their source
+func (gt GitServiceType) IncrementalSync() bool {
+	switch gt {
+	case GithubService, GitlabService:
 		return true
 	}
 	return false
@@ -366,6 +384,8 @@ var SupportedFullGitService = []GitServiceType{
 	OneDevService,
 	GitBucketService,
 	CodebaseService,
+	BitbucketService,
+	AzureDevOpsService,
 }
 
 // RepoTransfer represents a pending repo transfer
@@ -46,6 +46,10 @@ type BranchProtection struct {
 	RequireSignedCommits          bool     `json:"require_signed_commits"`
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
+	RequireLinearHistory          bool     `json:"require_linear_history"`
+	RequireConversationResolution bool     `json:"require_conversation_resolution"`
 	// swagger:strfmt date-time
 	Created time.Time `json:"created_at"`
 	// swagger:strfmt date-time
@@ -78,6 +82,10 @@ type CreateBranchProtectionOption struct {
 	RequireSignedCommits          bool     `json:"require_signed_commits"`
 	ProtectedFilePatterns         string   `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       string   `json:"unprotected_file_patterns"`
+	EnableMergeQueue              bool     `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      bool     `json:"require_code_owner_approval"`
+	RequireLinearHistory          bool     `json:"require_linear_history"`
+	RequireConversationResolution bool     `json:"require_conversation_resolution"`
 }
 
 // EditBranchProtectionOption options for editing a branch protection
@@ -103,4 +111,8 @@ type EditBranchProtectionOption struct {
 	RequireSignedCommits          *bool    `json:"require_signed_commits"`
 	ProtectedFilePatterns         *string  `json:"protected_file_patterns"`
 	UnprotectedFilePatterns       *string  `json:"unprotected_file_patterns"`
+	EnableMergeQueue              *bool    `json:"enable_merge_queue"`
+	RequireCodeOwnerApproval      *bool    `json:"require_code_owner_approval"`
+	RequireLinearHistory          *bool    `json:"require_linear_history"`
+	RequireConversationResolution *bool    `json:"require_conversation_resolution"`
 }
@@ -31,6 +31,11 @@ const (
 	HookEventRepository                HookEventType = "repository"
 	HookEventRelease                   HookEventType = "release"
 	HookEventPackage                   HookEventType = "package"
+
+	// HookEventWorkflowDispatch is not a webhook event, it's only triggered manually to run an actions workflow
+	HookEventWorkflowDispatch HookEventType = "workflow_dispatch"
+	// HookEventSchedule is not a webhook event, it's only triggered by the cron expressions of an actions workflow
+	HookEventSchedule HookEventType = "schedule"
 )
 
 // Event returns the HookEventType as an event string
@@ -63,6 +68,10 @@ func (h HookEventType) Event() string {
 		return "repository"
 	case HookEventRelease:
 		return "release"
+	case HookEventWorkflowDispatch:
+		return "workflow_dispatch"
+	case HookEventSchedule:
+		return "schedule"
 	}
 	return ""
 }
@@ -83,6 +92,7 @@ const (
 	MATRIX     HookType = "matrix"
 	WECHATWORK HookType = "wechatwork"
 	PACKAGIST  HookType = "packagist"
+	CUSTOM     HookType = "custom"
 )
 
 // HookStatus is the status of a web hook
@@ -0,0 +1,59 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package runner
+
+import (
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetVariablesOfTask(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	insert := func(ownerID, repoID int64, name, data string) {
+		_, err := actions_model.InsertVariable(db.DefaultContext, ownerID, repoID, name, data)
+		assert.NoError(t, err)
+	}
+	// repo1 is owned by the user user2, repo3 by the organization user3
+	insert(2, 0, "LEVEL", "user")
+	insert(2, 0, "USER_ONLY", "user")
+	insert(0, 1, "LEVEL", "repo1")
+	insert(3, 0, "LEVEL", "org")
+	insert(3, 0, "ORG_ONLY", "org")
+	insert(0, 3, "LEVEL", "repo3")
+	insert(0, 3, "REPO_ONLY", "repo3")
+	insert(0, 2, "OTHER_REPO", "repo2")
+
+	variablesOfRepo := func(repoID int64) map[string]string {
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: repoID})
+		return getVariablesOfTask(db.DefaultContext, &actions_model.ActionTask{
+			Job: &actions_model.ActionRunJob{
+				Run: &actions_model.ActionRun{RepoID: repo.ID, Repo: repo},
+			},
+		})
+	}
+
+	// the variables of a repository override the ones of the user or the organization which owns it
+	assert.Equal(t, map[string]string{
+		"LEVEL":     "repo1",
+		"USER_ONLY": "user",
+	}, variablesOfRepo(1))
+	assert.Equal(t, map[string]string{
+		"LEVEL":     "repo3",
+		"ORG_ONLY":  "org",
+		"REPO_ONLY": "repo3",
+	}, variablesOfRepo(3))
+
+	// without repository variables, the ones of the owner apply
+	assert.Equal(t, map[string]string{
+		"LEVEL":    "org",
+		"ORG_ONLY": "org",
+	}, variablesOfRepo(32))
+}
@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/packages/alpine"
+	"code.gitea.io/gitea/routers/api/packages/arch"
 	"code.gitea.io/gitea/routers/api/packages/cargo"
 	"code.gitea.io/gitea/routers/api/packages/chef"
 	"code.gitea.io/gitea/routers/api/packages/composer"
@@ -34,6 +35,7 @@ import (
 	"code.gitea.io/gitea/routers/api/packages/rpm"
 	"code.gitea.io/gitea/routers/api/packages/rubygems"
 	"code.gitea.io/gitea/routers/api/packages/swift"
+	"code.gitea.io/gitea/routers/api/packages/terraform"
 	"code.gitea.io/gitea/routers/api/packages/vagrant"
 	"code.gitea.io/gitea/services/auth"
 	context_service "code.gitea.io/gitea/services/context"
@@ -108,6 +110,19 @@ func CommonRoutes() *web.Route {
 		&chef.Auth{},
 	})
 
+	// Terraform looks up modules and providers by "hostname/namespace/name", the owner is the namespace
+	// and can't be part of the base path the protocols are served at.
+	r.Group("/-/terraform", func() {
+		r.Group("/modules/v1/{username}/{name}/{system}", func() {
+			r.Get("/versions", terraform.EnumerateModuleVersions)
+			r.Get("/{version}/download", terraform.DownloadModule)
+		})
+		r.Group("/providers/v1/{username}/{provider}", func() {
+			r.Get("/versions", terraform.EnumerateProviderVersions)
+			r.Get("/{version}/download/{os}/{arch}", terraform.DownloadProvider)
+		})
+	}, context_service.UserAssignmentWeb(), context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
+
 	r.Group("/{username}", func() {
 		r.Group("/alpine", func() {
 			r.Get("/key", alpine.GetRepositoryKey)
@@ -122,6 +137,16 @@ func CommonRoutes() *web.Route {
 				})
 			})
 		}, reqPackageAccess(perm.AccessModeRead))
+		r.Group("/arch", func() {
+			r.Get("/repository.key", arch.GetRepositoryKey)
+			r.Group("/{repository}", func() {
+				r.Put("", reqPackageAccess(perm.AccessModeWrite), arch.UploadPackageFile)
+				r.Group("/{architecture}/{filename}", func() {
+					r.Get("", arch.GetPackageOrRepositoryFile)
+					r.Delete("", reqPackageAccess(perm.AccessModeWrite), arch.DeletePackageFile)
+				})
+			})
+		}, reqPackageAccess(perm.AccessModeRead))
 		r.Group("/cargo", func() {
 			r.Group("/api/v1/crates", func() {
 				r.Get("", cargo.SearchPackages)
@@ -568,6 +593,18 @@ func CommonRoutes() *web.Route {
 			})
 			r.Get("/identifiers", swift.CheckAcceptMediaType(swift.AcceptJSON), swift.LookupPackageIdentifiers)
 		}, reqPackageAccess(perm.AccessModeRead))
+		r.Group("/terraform", func() {
+			r.Group("/modules/{name}/{system}/{version}", func() {
+				r.Put("", reqPackageAccess(perm.AccessModeWrite), terraform.UploadModule)
+				r.Delete("", reqPackageAccess(perm.AccessModeWrite), terraform.DeleteModule)
+				r.Get("/{filename}", terraform.DownloadModuleFile)
+			})
+			r.Group("/providers/{provider}/{version}", func() {
+				r.Delete("", reqPackageAccess(perm.AccessModeWrite), terraform.DeleteProvider)
+				r.Get("/{filename}", terraform.DownloadProviderFile)
+				r.Put("/{os}/{arch}", reqPackageAccess(perm.AccessModeWrite), terraform.UploadProvider)
+			})
+		}, reqPackageAccess(perm.AccessModeRead))
 		r.Group("/vagrant", func() {
 			r.Group("/authenticate", func() {
 				r.Get("", vagrant.CheckAuthenticate)
@@ -625,12 +662,14 @@ func ContainerRoutes() *web.Route {
 				r.Delete("", reqPackageAccess(perm.AccessModeWrite), container.DeleteManifest)
 			})
 			r.Get("/tags/list", container.GetTagList)
+			r.Get("/referrers/{digest}", container.GetReferrers)
 		}, container.VerifyImageName)
 
 		var (
 			blobsUploadsPattern = regexp.MustCompile(`\A(.+)/blobs/uploads/([a-zA-Z0-9-_.=]+)\z`)
 			blobsPattern        = regexp.MustCompile(`\A(.+)/blobs/([^/]+)\z`)
 			manifestsPattern    = regexp.MustCompile(`\A(.+)/manifests/([^/]+)\z`)
+			referrersPattern    = regexp.MustCompile(`\A(.+)/referrers/([^/]+)\z`)
 		)
 
 		// Manual mapping of routes because {image} can contain slashes which chi does not support
@@ -745,6 +784,19 @@ func ContainerRoutes() *web.Route {
 				}
 				return
 			}
+			m = referrersPattern.FindStringSubmatch(path)
+			if len(m) == 3 && isGet {
+				ctx.SetParams("image", m[1])
+				container.VerifyImageName(ctx)
+				if ctx.Written() {
+					return
+				}
+
+				ctx.SetParams("digest", m[2])
+
+				container.GetReferrers(ctx)
+				return
+			}
 
 			ctx.Status(http.StatusNotFound)
 		})
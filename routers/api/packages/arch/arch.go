@@ -0,0 +1,324 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package arch
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	arch_module "code.gitea.io/gitea/modules/packages/arch"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	arch_service "code.gitea.io/gitea/services/packages/arch"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+func GetRepositoryKey(ctx *context.Context) {
+	_, pub, err := arch_service.GetOrCreateKeyPair(ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent(strings.NewReader(pub), &context.ServeHeaderOptions{
+		ContentType: "application/pgp-keys",
+		Filename:    "repository.key",
+	})
+}
+
+// GetPackageOrRepositoryFile serves the databases of a repository, the packages and their signatures
+func GetPackageOrRepositoryFile(ctx *context.Context) {
+	repository, architecture, filename := ctx.Params("repository"), ctx.Params("architecture"), ctx.Params("filename")
+
+	// pacman downloads "repository.db" and "repository.files", repo-add creates them as links to the compressed archives
+	switch filename {
+	case repository + ".db", repository + ".db" + arch_service.SignatureSuffix:
+		filename = repository + arch_service.DatabaseExtension + strings.TrimPrefix(filename, repository+".db")
+	case repository + ".files", repository + ".files" + arch_service.SignatureSuffix:
+		filename = repository + arch_service.FilesExtension + strings.TrimPrefix(filename, repository+".files")
+	}
+
+	if strings.HasPrefix(filename, repository+".db.") || strings.HasPrefix(filename, repository+".files.") {
+		getRepositoryFile(ctx, repository, architecture, filename)
+		return
+	}
+
+	if packageFilename, ok := strings.CutSuffix(filename, arch_service.SignatureSuffix); ok {
+		pf := findPackageFile(ctx, repository, architecture, packageFilename)
+		if pf == nil {
+			return
+		}
+
+		pps, err := packages_model.GetPropertiesByName(ctx, packages_model.PropertyTypeFile, pf.ID, arch_module.PropertySignature)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if len(pps) == 0 {
+			apiError(ctx, http.StatusNotFound, nil)
+			return
+		}
+		signature, err := base64.StdEncoding.DecodeString(pps[0].Value)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		ctx.ServeContent(bytes.NewReader(signature), &context.ServeHeaderOptions{
+			Filename:     filename,
+			LastModified: pf.CreatedUnix.AsLocalTime(),
+		})
+		return
+	}
+
+	pf := findPackageFile(ctx, repository, architecture, filename)
+	if pf == nil {
+		return
+	}
+
+	s, pf, err := packages_service.GetPackageFileStream(ctx, pf)
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeContent(s, &context.ServeHeaderOptions{
+		Filename:     pf.Name,
+		LastModified: pf.CreatedUnix.AsLocalTime(),
+	})
+}
+
+func getRepositoryFile(ctx *context.Context, repository, architecture, filename string) {
+	pv, err := arch_service.GetOrCreateRepositoryVersion(ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	// the repositories with only packages of the "any" architecture have no databases for the other architectures
+	var s io.ReadSeekCloser
+	var pf *packages_model.PackageFile
+	for _, architecture := range []string{architecture, arch_module.AnyArchitecture} {
+		s, pf, err = packages_service.GetFileStreamByPackageVersion(
+			ctx,
+			pv,
+			&packages_service.PackageFileInfo{
+				Filename:     filename,
+				CompositeKey: fmt.Sprintf("%s|%s", repository, architecture),
+			},
+		)
+		if !errors.Is(err, util.ErrNotExist) {
+			break
+		}
+	}
+	if err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeContent(s, &context.ServeHeaderOptions{
+		Filename:     pf.Name,
+		LastModified: pf.CreatedUnix.AsLocalTime(),
+	})
+}
+
+// searchPackageFile searches the package file with the name in the repository for the architecture
+func searchPackageFile(ctx *context.Context, repository, architecture, filename string) (*packages_model.PackageFile, error) {
+	pfs, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+		OwnerID:      ctx.Package.Owner.ID,
+		PackageType:  packages_model.TypeArch,
+		Query:        filename,
+		CompositeKey: fmt.Sprintf("%s|%s", repository, architecture),
+	})
+	if err != nil {
+		return nil, err
+	}
+	// the query matches the names containing the filename
+	for _, pf := range pfs {
+		if pf.LowerName == strings.ToLower(filename) {
+			return pf, nil
+		}
+	}
+	return nil, nil
+}
+
+// findPackageFile finds a package file of the repository which can be installed on the architecture,
+// it responds with an error if there is none
+func findPackageFile(ctx *context.Context, repository, architecture, filename string) *packages_model.PackageFile {
+	for _, architecture := range []string{architecture, arch_module.AnyArchitecture} {
+		pf, err := searchPackageFile(ctx, repository, architecture, filename)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return nil
+		}
+		if pf != nil {
+			return pf
+		}
+	}
+
+	apiError(ctx, http.StatusNotFound, nil)
+	return nil
+}
+
+func UploadPackageFile(ctx *context.Context) {
+	repository := strings.TrimSpace(ctx.Params("repository"))
+	if repository == "" {
+		apiError(ctx, http.StatusBadRequest, "invalid repository")
+		return
+	}
+
+	upload, close, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if close {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pck, err := arch_module.ParsePackage(buf)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) || err == io.EOF {
+			apiError(ctx, http.StatusBadRequest, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	signature, err := arch_service.CreateSignature(ctx.Package.Owner.ID, buf)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	fileMetadataRaw, err := json.Marshal(pck.FileMetadata)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	architecture := pck.FileMetadata.Architecture
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeArch,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			Creator:  ctx.Doer,
+			Metadata: pck.VersionMetadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename:     fmt.Sprintf("%s-%s-%s%s", pck.Name, pck.Version, architecture, pck.FileExtension),
+				CompositeKey: fmt.Sprintf("%s|%s", repository, architecture),
+			},
+			Creator: ctx.Doer,
+			Data:    buf,
+			IsLead:  true,
+			Properties: map[string]string{
+				arch_module.PropertyRepository:   repository,
+				arch_module.PropertyArchitecture: architecture,
+				arch_module.PropertyMetadata:     string(fileMetadataRaw),
+				arch_module.PropertyFiles:        strings.Join(pck.Files, "\n"),
+				arch_module.PropertySignature:    arch_service.EncodeSignature(signature),
+			},
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageVersion, packages_model.ErrDuplicatePackageFile:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount:
+			apiError(ctx, http.StatusForbidden, err)
+		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err := arch_service.BuildSpecificRepositoryFiles(ctx, ctx.Package.Owner.ID, repository, architecture); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+func DeletePackageFile(ctx *context.Context) {
+	repository, architecture := ctx.Params("repository"), ctx.Params("architecture")
+
+	pf, err := searchPackageFile(ctx, repository, architecture, ctx.Params("filename"))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if pf == nil {
+		apiError(ctx, http.StatusNotFound, nil)
+		return
+	}
+
+	if err := packages_service.RemovePackageFileAndVersionIfUnreferenced(ctx.Doer, pf); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err := arch_service.BuildSpecificRepositoryFiles(ctx, ctx.Package.Owner.ID, repository, architecture); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -4,6 +4,7 @@
 package container
 
 import (
+	gocontext "context"
 	"errors"
 	"fmt"
 	"io"
@@ -29,6 +30,8 @@ import (
 	container_service "code.gitea.io/gitea/services/packages/container"
 
 	digest "github.com/opencontainers/go-digest"
+	specs "github.com/opencontainers/image-spec/specs-go"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
 )
 
 // maximum size of a container manifest
@@ -48,6 +51,7 @@ type containerHeaders struct {
 	Location      string
 	ContentType   string
 	ContentLength int64
+	Subject       string
 }
 
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#legacy-docker-support-http-headers
@@ -71,6 +75,9 @@ func setResponseHeaders(resp http.ResponseWriter, h *containerHeaders) {
 		resp.Header().Set("Docker-Content-Digest", h.ContentDigest)
 		resp.Header().Set("ETag", fmt.Sprintf(`"%s"`, h.ContentDigest))
 	}
+	if h.Subject != "" {
+		resp.Header().Set("OCI-Subject", h.Subject)
+	}
 	resp.Header().Set("Docker-Distribution-Api-Version", "registry/2.0")
 	resp.WriteHeader(h.Status)
 }
@@ -242,8 +249,10 @@ func InitiateUploadBlob(ctx *context.Context) {
 			},
 		); err != nil {
 			switch err {
-			case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			case packages_service.ErrQuotaTotalCount:
 				apiError(ctx, http.StatusForbidden, err)
+			case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+				apiError(ctx, http.StatusRequestEntityTooLarge, err)
 			default:
 				apiError(ctx, http.StatusInternalServerError, err)
 			}
@@ -387,8 +396,10 @@ func EndUploadBlob(ctx *context.Context) {
 		},
 	); err != nil {
 		switch err {
-		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+		case packages_service.ErrQuotaTotalCount:
 			apiError(ctx, http.StatusForbidden, err)
+		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
 		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -559,8 +570,10 @@ func UploadManifest(ctx *context.Context) {
 			apiErrorDefined(ctx, errBlobUnknown)
 		} else {
 			switch err {
-			case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			case packages_service.ErrQuotaTotalCount:
 				apiError(ctx, http.StatusForbidden, err)
+			case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+				apiError(ctx, http.StatusRequestEntityTooLarge, err)
 			default:
 				apiError(ctx, http.StatusInternalServerError, err)
 			}
@@ -571,6 +584,7 @@ func UploadManifest(ctx *context.Context) {
 	setResponseHeaders(ctx.Resp, &containerHeaders{
 		Location:      fmt.Sprintf("/v2/%s/%s/manifests/%s", ctx.Package.Owner.LowerName, mci.Image, reference),
 		ContentDigest: digest,
+		Subject:       mci.Subject,
 		Status:        http.StatusCreated,
 	})
 }
@@ -601,7 +615,7 @@ func getManifestFromContext(ctx *context.Context) (*packages_model.PackageFileDe
 		return nil, err
 	}
 
-	return workaroundGetContainerBlob(ctx, opts)
+	return getManifestWithRemote(ctx, ctx.Package.Owner, opts)
 }
 
 // https://github.com/opencontainers/distribution-spec/blob/main/spec.md#checking-if-content-exists-in-the-registry
@@ -732,9 +746,49 @@ func GetTagList(ctx *context.Context) {
 	})
 }
 
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers
+func GetReferrers(ctx *context.Context) {
+	subject := ctx.Params("digest")
+	if digest.Digest(subject).Validate() != nil {
+		apiErrorDefined(ctx, errDigestInvalid)
+		return
+	}
+
+	artifactType := ctx.FormTrim("artifactType")
+
+	referrers, err := container_service.GetReferrers(ctx, ctx.Package.Owner.ID, ctx.Params("image"), subject, artifactType)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	index := oci.Index{
+		Versioned: specs.Versioned{
+			SchemaVersion: 2,
+		},
+		MediaType: oci.MediaTypeImageIndex,
+		Manifests: make([]oci.Descriptor, 0, len(referrers)),
+	}
+	for _, r := range referrers {
+		index.Manifests = append(index.Manifests, r.Descriptor)
+	}
+
+	if artifactType != "" {
+		ctx.Resp.Header().Set("OCI-Filters-Applied", "artifactType")
+	}
+
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Status:      http.StatusOK,
+		ContentType: oci.MediaTypeImageIndex,
+	})
+	if err := json.NewEncoder(ctx.Resp).Encode(index); err != nil {
+		log.Error("JSON encode: %v", err)
+	}
+}
+
 // FIXME: Workaround to be removed in v1.20
 // https://github.com/go-gitea/gitea/issues/19586
-func workaroundGetContainerBlob(ctx *context.Context, opts *container_model.BlobSearchOptions) (*packages_model.PackageFileDescriptor, error) {
+func workaroundGetContainerBlob(ctx gocontext.Context, opts *container_model.BlobSearchOptions) (*packages_model.PackageFileDescriptor, error) {
 	blob, err := container_model.GetContainerBlob(ctx, opts)
 	if err != nil {
 		return nil, err
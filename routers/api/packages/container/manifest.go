@@ -41,22 +41,26 @@ func isImageIndexMediaType(mt string) bool {
 
 // manifestCreationInfo describes a manifest to create
 type manifestCreationInfo struct {
-	MediaType  string
-	Owner      *user_model.User
-	Creator    *user_model.User
-	Image      string
-	Reference  string
-	IsTagged   bool
-	Properties map[string]string
+	MediaType    string
+	Owner        *user_model.User
+	Creator      *user_model.User
+	Image        string
+	Reference    string
+	IsTagged     bool
+	Subject      string
+	ArtifactType string
+	Annotations  map[string]string
+	Properties   map[string]string
 }
 
 func processManifest(mci *manifestCreationInfo, buf *packages_module.HashedBuffer) (string, error) {
-	var index oci.Index
-	if err := json.NewDecoder(buf).Decode(&index); err != nil {
+	// image manifests and indexes share all fields needed here
+	var manifest oci.Manifest
+	if err := json.NewDecoder(buf).Decode(&manifest); err != nil {
 		return "", err
 	}
 
-	if index.SchemaVersion != 2 {
+	if manifest.SchemaVersion != 2 {
 		return "", errUnsupported.WithMessage("Schema version is not supported")
 	}
 
@@ -65,12 +69,26 @@ func processManifest(mci *manifestCreationInfo, buf *packages_module.HashedBuffe
 	}
 
 	if !isValidMediaType(mci.MediaType) {
-		mci.MediaType = index.MediaType
+		mci.MediaType = manifest.MediaType
 		if !isValidMediaType(mci.MediaType) {
 			return "", errManifestInvalid.WithMessage("MediaType not recognized")
 		}
 	}
 
+	// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-manifests-with-subject
+	if manifest.Subject != nil {
+		if manifest.Subject.Digest.Validate() != nil {
+			return "", errManifestInvalid.WithMessage("Subject digest is invalid")
+		}
+
+		mci.Subject = string(manifest.Subject.Digest)
+		mci.ArtifactType = manifest.ArtifactType
+		if mci.ArtifactType == "" {
+			mci.ArtifactType = manifest.Config.MediaType
+		}
+	}
+	mci.Annotations = manifest.Annotations
+
 	if isImageManifestMediaType(mci.MediaType) {
 		return processImageManifest(mci, buf)
 	} else if isImageIndexMediaType(mci.MediaType) {
@@ -337,6 +355,7 @@ func createPackageAndVersion(ctx context.Context, mci *manifestCreationInfo, met
 	}
 
 	metadata.IsTagged = mci.IsTagged
+	metadata.Annotations = mci.Annotations
 
 	metadataJSON, err := json.Marshal(metadata)
 	if err != nil {
@@ -386,6 +405,24 @@ func createPackageAndVersion(ctx context.Context, mci *manifestCreationInfo, met
 			return nil, err
 		}
 	}
+	if mci.Subject != "" {
+		if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, container_module.PropertyManifestSubject, mci.Subject); err != nil {
+			log.Error("Error setting package version property: %v", err)
+			return nil, err
+		}
+		if mci.ArtifactType != "" {
+			if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, container_module.PropertyArtifactType, mci.ArtifactType); err != nil {
+				log.Error("Error setting package version property: %v", err)
+				return nil, err
+			}
+		}
+	}
+	for name, value := range mci.Properties {
+		if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, name, value); err != nil {
+			log.Error("Error setting package version property: %v", err)
+			return nil, err
+		}
+	}
 
 	return pv, nil
 }
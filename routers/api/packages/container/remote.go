@@ -0,0 +1,266 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package container
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	container_model "code.gitea.io/gitea/models/packages/container"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	container_module "code.gitea.io/gitea/modules/packages/container"
+	"code.gitea.io/gitea/modules/util"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+
+	digest "github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+var remoteManifestHeader = http.Header{
+	"Accept": []string{
+		oci.MediaTypeImageManifest,
+		oci.MediaTypeImageIndex,
+		"application/vnd.docker.distribution.manifest.v2+json",
+		"application/vnd.docker.distribution.manifest.list.v2+json",
+	},
+}
+
+// getManifestWithRemote returns the manifest matching the search options.
+// If the owner has a container remote, missing manifests get fetched from the upstream registry
+// and tags are refreshed if the upstream registry points them to a different manifest.
+func getManifestWithRemote(ctx context.Context, owner *user_model.User, opts *container_model.BlobSearchOptions) (*packages_model.PackageFileDescriptor, error) {
+	pr, err := remote_service.GetEnabledRemote(ctx, owner.ID, packages_model.TypeContainer)
+	if err != nil {
+		return nil, err
+	}
+
+	pfd, err := workaroundGetContainerBlob(ctx, opts)
+	if err != nil && err != container_model.ErrContainerBlobNotExist {
+		return nil, err
+	}
+	if pr == nil {
+		return pfd, err
+	}
+
+	c, err := remote_service.NewClient(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	reference := opts.Digest
+	if opts.Tag != "" {
+		reference, err = getRemoteTagDigest(ctx, pr, c, opts.Image, opts.Tag)
+		if err != nil {
+			if pfd != nil {
+				if !errors.Is(err, util.ErrNotExist) {
+					log.Warn("Error fetching tag %s:%s from upstream registry: %v", opts.Image, opts.Tag, err)
+				}
+				return pfd, nil
+			}
+			return nil, remoteError(err)
+		}
+		if pfd != nil && pfd.Properties.GetByName(container_module.PropertyDigest) == reference {
+			return pfd, nil
+		}
+	} else if pfd != nil {
+		return pfd, nil
+	}
+
+	if err := cacheRemoteManifest(ctx, pr, c, owner, opts.Image, reference, opts.Tag); err != nil {
+		if pfd != nil {
+			log.Warn("Error caching manifest %s of %s from upstream registry: %v", reference, opts.Image, err)
+			return pfd, nil
+		}
+		return nil, remoteError(err)
+	}
+
+	return workaroundGetContainerBlob(ctx, opts)
+}
+
+func remoteError(err error) error {
+	if errors.Is(err, util.ErrNotExist) {
+		return container_model.ErrContainerBlobNotExist
+	}
+	return err
+}
+
+func remoteManifestURL(c *remote_service.Client, image, reference string) string {
+	return c.URL("v2/" + image + "/manifests/" + reference)
+}
+
+// getRemoteTagDigest returns the digest of the manifest the tag points to in the upstream registry
+func getRemoteTagDigest(ctx context.Context, pr *packages_model.PackageRemote, c *remote_service.Client, image, tag string) (string, error) {
+	content, err := remote_service.GetMetadata(
+		ctx,
+		pr,
+		"container/"+strings.ToLower(image)+":"+tag,
+		func(ctx context.Context) ([]byte, error) {
+			// HEAD requests are not counted against the rate limits of some registries
+			resp, err := c.Do(ctx, http.MethodHead, remoteManifestURL(c, image, tag), remoteManifestHeader)
+			if err != nil {
+				return nil, err
+			}
+			resp.Body.Close()
+
+			if d := resp.Header.Get("Docker-Content-Digest"); digest.Digest(d).Validate() == nil {
+				return []byte(d), nil
+			}
+
+			buf, _, err := downloadRemoteManifest(ctx, c, image, tag)
+			if err != nil {
+				return nil, err
+			}
+			defer buf.Close()
+
+			return []byte(digestFromHashSummer(buf)), nil
+		},
+	)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func downloadRemoteManifest(ctx context.Context, c *remote_service.Client, image, reference string) (*packages_module.HashedBuffer, string, error) {
+	resp, err := c.Do(ctx, http.MethodGet, remoteManifestURL(c, image, reference), remoteManifestHeader)
+	if err != nil {
+		return nil, "", err
+	}
+	defer resp.Body.Close()
+
+	maxSize := maxManifestSize + 1
+	buf, err := packages_module.CreateHashedBufferFromReaderWithSize(&io.LimitedReader{R: resp.Body, N: int64(maxSize)}, maxSize)
+	if err != nil {
+		return nil, "", err
+	}
+	if buf.Size() > maxManifestSize {
+		buf.Close()
+		return nil, "", errManifestInvalid.WithMessage("Manifest exceeds maximum size")
+	}
+
+	return buf, resp.Header.Get("Content-Type"), nil
+}
+
+// cacheRemoteManifest fetches the manifest with the digest from the upstream registry and stores it together
+// with all referenced manifests and blobs. If tag is not empty, the manifest gets stored under the tag.
+func cacheRemoteManifest(ctx context.Context, pr *packages_model.PackageRemote, c *remote_service.Client, owner *user_model.User, image, manifestDigest, tag string) error {
+	buf, mediaType, err := downloadRemoteManifest(ctx, c, image, manifestDigest)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	if digestFromHashSummer(buf) != manifestDigest {
+		return remote_service.ErrUpstreamIntegrity
+	}
+
+	var index oci.Index
+	if err := json.NewDecoder(buf).Decode(&index); err != nil {
+		return err
+	}
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if !isValidMediaType(mediaType) {
+		mediaType = index.MediaType
+	}
+
+	if isImageIndexMediaType(mediaType) {
+		for _, m := range index.Manifests {
+			_, err := workaroundGetContainerBlob(ctx, &container_model.BlobSearchOptions{
+				OwnerID:    owner.ID,
+				Image:      image,
+				Digest:     string(m.Digest),
+				IsManifest: true,
+			})
+			if err == nil {
+				continue
+			}
+			if err != container_model.ErrContainerBlobNotExist {
+				return err
+			}
+			if err := cacheRemoteManifest(ctx, pr, c, owner, image, string(m.Digest), ""); err != nil {
+				return err
+			}
+		}
+	} else if isImageManifestMediaType(mediaType) {
+		var manifest oci.Manifest
+		if err := json.NewDecoder(buf).Decode(&manifest); err != nil {
+			return err
+		}
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		for _, d := range append([]oci.Descriptor{manifest.Config}, manifest.Layers...) {
+			if err := cacheRemoteBlob(ctx, c, owner, image, string(d.Digest)); err != nil {
+				return err
+			}
+		}
+	}
+
+	reference := manifestDigest
+	if tag != "" {
+		reference = tag
+	}
+
+	_, err = processManifest(&manifestCreationInfo{
+		MediaType: mediaType,
+		Owner:     owner,
+		Creator:   owner,
+		Image:     image,
+		Reference: reference,
+		IsTagged:  tag != "",
+		Properties: map[string]string{
+			packages_model.PropertyRemoteOrigin: pr.URL,
+		},
+	}, buf)
+	return err
+}
+
+// cacheRemoteBlob fetches the blob with the digest from the upstream registry if it is not available locally
+func cacheRemoteBlob(ctx context.Context, c *remote_service.Client, owner *user_model.User, image, blobDigest string) error {
+	_, err := workaroundGetContainerBlob(ctx, &container_model.BlobSearchOptions{
+		OwnerID: owner.ID,
+		Image:   image,
+		Digest:  blobDigest,
+	})
+	if err == nil {
+		return nil
+	}
+	if err != container_model.ErrContainerBlobNotExist {
+		return err
+	}
+
+	buf, err := c.Download(ctx, c.URL("v2/"+image+"/blobs/"+blobDigest), nil)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	if digestFromHashSummer(buf) != blobDigest {
+		return remote_service.ErrUpstreamIntegrity
+	}
+
+	_, err = saveAsPackageBlob(
+		buf,
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner: owner,
+				Name:  image,
+			},
+			Creator: owner,
+		},
+	)
+	return err
+}
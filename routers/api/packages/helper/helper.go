@@ -4,12 +4,15 @@
 package helper
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	packages_service "code.gitea.io/gitea/services/packages"
 )
 
 // LogAndProcessError logs an error and calls a custom callback with the processed error message.
@@ -35,3 +38,17 @@ func LogAndProcessError(ctx *context.Context, status int, obj interface{}, cb fu
 		cb(message)
 	}
 }
+
+// RemoteErrorStatus returns the status code for an error which occurred while caching content of an upstream registry
+func RemoteErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, util.ErrNotExist):
+		return http.StatusNotFound
+	case errors.Is(err, packages_service.ErrQuotaTotalCount):
+		return http.StatusForbidden
+	case errors.Is(err, packages_service.ErrQuotaTypeSize), errors.Is(err, packages_service.ErrQuotaTotalSize):
+		return http.StatusRequestEntityTooLarge
+	default:
+		return http.StatusBadGateway
+	}
+}
@@ -26,6 +26,7 @@ import (
 	maven_module "code.gitea.io/gitea/modules/packages/maven"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
 
 	"github.com/minio/sha256-simd"
 )
@@ -86,57 +87,85 @@ func serveMavenMetadata(ctx *context.Context, params parameters) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
-		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
-		return
-	}
 
-	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	pr, err := remote_service.GetEnabledRemote(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	sort.Slice(pds, func(i, j int) bool {
-		// Maven and Gradle order packages by their creation timestamp and not by their version string
-		return pds[i].Version.CreatedUnix < pds[j].Version.CreatedUnix
-	})
+	if len(pvs) == 0 && pr == nil {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
 
-	xmlMetadata, err := xml.Marshal(createMetadataResponse(pds))
+	var resp *MetadataResponse
+	if len(pvs) != 0 {
+		pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		sort.Slice(pds, func(i, j int) bool {
+			// Maven and Gradle order packages by their creation timestamp and not by their version string
+			return pds[i].Version.CreatedUnix < pds[j].Version.CreatedUnix
+		})
+
+		resp = createMetadataResponse(pds)
+
+		latest := pds[len(pds)-1]
+		ctx.Resp.Header().Set("Last-Modified", latest.Version.CreatedUnix.Format(http.TimeFormat))
+	}
+
+	if pr != nil {
+		remoteResp, err := getRemoteMetadataResponse(ctx, pr, params)
+		if err != nil {
+			if resp == nil {
+				apiError(ctx, helper.RemoteErrorStatus(err), err)
+				return
+			}
+			log.Warn("Error fetching Maven package %s from upstream repository: %v", packageName, err)
+		} else {
+			resp = mergeRemoteMetadataResponse(resp, remoteResp)
+		}
+	}
+
+	xmlMetadata, err := xml.Marshal(resp)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	xmlMetadataWithHeader := append([]byte(xml.Header), xmlMetadata...)
 
-	latest := pds[len(pds)-1]
-	ctx.Resp.Header().Set("Last-Modified", latest.Version.CreatedUnix.Format(http.TimeFormat))
+	serveMetadataContent(ctx, params, append([]byte(xml.Header), xmlMetadata...))
+}
 
+func serveMetadataContent(ctx *context.Context, params parameters, content []byte) {
 	ext := strings.ToLower(filepath.Ext(params.Filename))
 	if isChecksumExtension(ext) {
 		var hash []byte
 		switch ext {
 		case extensionMD5:
-			tmp := md5.Sum(xmlMetadataWithHeader)
+			tmp := md5.Sum(content)
 			hash = tmp[:]
 		case extensionSHA1:
-			tmp := sha1.Sum(xmlMetadataWithHeader)
+			tmp := sha1.Sum(content)
 			hash = tmp[:]
 		case extensionSHA256:
-			tmp := sha256.Sum256(xmlMetadataWithHeader)
+			tmp := sha256.Sum256(content)
 			hash = tmp[:]
 		case extensionSHA512:
-			tmp := sha512.Sum512(xmlMetadataWithHeader)
+			tmp := sha512.Sum512(content)
 			hash = tmp[:]
 		}
 		ctx.PlainText(http.StatusOK, hex.EncodeToString(hash))
 		return
 	}
 
-	ctx.Resp.Header().Set("Content-Length", strconv.Itoa(len(xmlMetadataWithHeader)))
+	ctx.Resp.Header().Set("Content-Length", strconv.Itoa(len(content)))
 	ctx.Resp.Header().Set("Content-Type", contentTypeXML)
 
-	if _, err := ctx.Resp.Write(xmlMetadataWithHeader); err != nil {
+	if _, err := ctx.Resp.Write(content); err != nil {
 		log.Error("write bytes failed: %v", err)
 	}
 }
@@ -144,16 +173,6 @@ func serveMavenMetadata(ctx *context.Context, params parameters) {
 func servePackageFile(ctx *context.Context, params parameters, serveContent bool) {
 	packageName := params.GroupID + "-" + params.ArtifactID
 
-	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven, packageName, params.Version)
-	if err != nil {
-		if err == packages_model.ErrPackageNotExist {
-			apiError(ctx, http.StatusNotFound, err)
-		} else {
-			apiError(ctx, http.StatusInternalServerError, err)
-		}
-		return
-	}
-
 	filename := params.Filename
 
 	ext := strings.ToLower(filepath.Ext(filename))
@@ -161,9 +180,39 @@ func servePackageFile(ctx *context.Context, params parameters, serveContent bool
 		filename = filename[:len(filename)-len(ext)]
 	}
 
-	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+	pv, pf, err := getPackageFile(ctx, packageName, params.Version, filename)
+	if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		var pr *packages_model.PackageRemote
+		pr, err = remote_service.GetEnabledRemote(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if pr == nil {
+			apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+			return
+		}
+
+		// snapshot metadata changes with every build and is not cached as package file
+		if params.IsMeta {
+			content, err := getRemoteMetadataContent(ctx, pr, params)
+			if err != nil {
+				apiError(ctx, helper.RemoteErrorStatus(err), err)
+				return
+			}
+			serveMetadataContent(ctx, params, content)
+			return
+		}
+
+		if err := cacheRemotePackageFile(ctx, pr, ctx.Package.Owner, params, filename); err != nil {
+			apiError(ctx, helper.RemoteErrorStatus(err), err)
+			return
+		}
+
+		pv, pf, err = getPackageFile(ctx, packageName, params.Version, filename)
+	}
 	if err != nil {
-		if err == packages_model.ErrPackageFileNotExist {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
 			apiError(ctx, http.StatusNotFound, err)
 		} else {
 			apiError(ctx, http.StatusInternalServerError, err)
@@ -227,6 +276,19 @@ func servePackageFile(ctx *context.Context, params parameters, serveContent bool
 	ctx.ServeContent(s, opts)
 }
 
+func getPackageFile(ctx *context.Context, packageName, packageVersion, filename string) (*packages_model.PackageVersion, *packages_model.PackageFile, error) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeMaven, packageName, packageVersion)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, packages_model.EmptyFileKey)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pv, pf, nil
+}
+
 // UploadPackageFile adds a file to the package. If the package does not exist, it gets created.
 func UploadPackageFile(ctx *context.Context) {
 	params, err := extractPathParameters(ctx)
@@ -363,8 +425,10 @@ func UploadPackageFile(ctx *context.Context) {
 		switch err {
 		case packages_model.ErrDuplicatePackageFile:
 			apiError(ctx, http.StatusBadRequest, err)
-		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+		case packages_service.ErrQuotaTotalCount:
 			apiError(ctx, http.StatusForbidden, err)
+		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
 		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -0,0 +1,173 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package maven
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	maven_module "code.gitea.io/gitea/modules/packages/maven"
+	"code.gitea.io/gitea/modules/util"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+)
+
+func remoteArtifactPath(params parameters) string {
+	return strings.ReplaceAll(params.GroupID, ".", "/") + "/" + params.ArtifactID
+}
+
+// getRemoteMetadataContent returns the content of a maven-metadata.xml file of the upstream repository
+func getRemoteMetadataContent(ctx context.Context, pr *packages_model.PackageRemote, params parameters) ([]byte, error) {
+	c, err := remote_service.NewClient(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := remoteArtifactPath(params)
+	if params.Version != "" {
+		p += "/" + params.Version
+	}
+	p += "/" + mavenMetadataFile
+
+	return remote_service.GetMetadata(ctx, pr, "maven/"+p, c.FetchMetadata(c.URL(p), nil))
+}
+
+// getRemoteMetadataResponse returns the artifact metadata of the upstream repository
+func getRemoteMetadataResponse(ctx context.Context, pr *packages_model.PackageRemote, params parameters) (*MetadataResponse, error) {
+	content, err := getRemoteMetadataContent(ctx, pr, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp MetadataResponse
+	if err := xml.Unmarshal(content, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// mergeRemoteMetadataResponse adds the versions which are only available locally to the upstream metadata
+func mergeRemoteMetadataResponse(local, remote *MetadataResponse) *MetadataResponse {
+	if local == nil {
+		return remote
+	}
+
+	versions := make([]string, 0, len(remote.Version)+len(local.Version))
+	versions = append(versions, remote.Version...)
+	for _, v := range local.Version {
+		if !util.SliceContainsString(remote.Version, v) {
+			versions = append(versions, v)
+		}
+	}
+
+	resp := &MetadataResponse{
+		GroupID:    remote.GroupID,
+		ArtifactID: remote.ArtifactID,
+		Latest:     remote.Latest,
+		Release:    remote.Release,
+		Version:    versions,
+	}
+	if len(versions) > len(remote.Version) {
+		resp.Latest = versions[len(versions)-1]
+		for i := len(versions) - 1; i >= len(remote.Version); i-- {
+			if !strings.HasSuffix(versions[i], "-SNAPSHOT") {
+				resp.Release = versions[i]
+				break
+			}
+		}
+	}
+	return resp
+}
+
+// cacheRemotePackageFile downloads the file from the upstream repository and stores it
+func cacheRemotePackageFile(ctx context.Context, pr *packages_model.PackageRemote, owner *user_model.User, params parameters, filename string) error {
+	c, err := remote_service.NewClient(pr)
+	if err != nil {
+		return err
+	}
+
+	fileURL := c.URL(remoteArtifactPath(params) + "/" + params.Version + "/" + filename)
+
+	buf, err := c.Download(ctx, fileURL, nil)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	// verify the content against the checksum file if the repository provides one
+	resp, err := c.Do(ctx, http.MethodGet, fileURL+extensionSHA1, nil)
+	if err == nil {
+		checksum, err := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		resp.Body.Close()
+		if err != nil {
+			return err
+		}
+
+		_, hashSHA1, _, _ := buf.Sums()
+		if fields := strings.Fields(string(checksum)); len(fields) == 0 || !strings.EqualFold(fields[0], hex.EncodeToString(hashSHA1)) {
+			return remote_service.ErrUpstreamIntegrity
+		}
+	} else if !errors.Is(err, util.ErrNotExist) {
+		return err
+	}
+
+	pvci := &packages_service.PackageCreationInfo{
+		PackageInfo: packages_service.PackageInfo{
+			Owner:       owner,
+			PackageType: packages_model.TypeMaven,
+			Name:        params.GroupID + "-" + params.ArtifactID,
+			Version:     params.Version,
+		},
+		SemverCompatible: false,
+	}
+
+	pfci := &packages_service.PackageFileCreationInfo{
+		PackageFileInfo: packages_service.PackageFileInfo{
+			Filename: filename,
+		},
+		Data: buf,
+	}
+
+	if strings.ToLower(filepath.Ext(filename)) == extensionPom {
+		pfci.IsLead = true
+
+		metadata, err := maven_module.ParsePackageMetaData(buf)
+		if _, err := buf.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+		if err != nil {
+			log.Error("Error parsing package metadata: %v", err)
+		} else {
+			pvci.Metadata = metadata
+
+			// the version may have been created by a file fetched before the pom
+			pv, err := packages_model.GetVersionByNameAndVersion(ctx, owner.ID, pvci.PackageType, pvci.Name, pvci.Version)
+			if err != nil && err != packages_model.ErrPackageNotExist {
+				return err
+			}
+			if pv != nil {
+				raw, err := json.Marshal(metadata)
+				if err != nil {
+					return err
+				}
+				pv.MetadataJSON = string(raw)
+				if err := packages_model.UpdateVersion(ctx, pv); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return remote_service.CachePackageFile(pr, pvci, pfci)
+}
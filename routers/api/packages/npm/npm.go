@@ -17,12 +17,14 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	npm_module "code.gitea.io/gitea/modules/packages/npm"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
 
 	"github.com/hashicorp/go-version"
 )
@@ -58,21 +60,43 @@ func PackageMetadata(ctx *context.Context) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
-		apiError(ctx, http.StatusNotFound, err)
-		return
-	}
 
-	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	pr, err := remote_service.GetEnabledRemote(ctx, ctx.Package.Owner.ID, packages_model.TypeNpm)
 	if err != nil {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
 
-	resp := createPackageMetadataResponse(
-		setting.AppURL+"api/packages/"+ctx.Package.Owner.Name+"/npm",
-		pds,
-	)
+	if len(pvs) == 0 && pr == nil {
+		apiError(ctx, http.StatusNotFound, err)
+		return
+	}
+
+	registryURL := setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/npm"
+
+	var resp *npm_module.PackageMetadata
+	if len(pvs) != 0 {
+		pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		resp = createPackageMetadataResponse(registryURL, pds)
+	}
+
+	if pr != nil {
+		remoteMetadata, err := getRemotePackageMetadata(ctx, pr, packageName)
+		if err != nil {
+			if resp == nil {
+				apiError(ctx, helper.RemoteErrorStatus(err), err)
+				return
+			}
+			log.Warn("Error fetching npm package %s from upstream registry: %v", packageName, err)
+		} else {
+			resp = mergeRemotePackageMetadata(registryURL, resp, remoteMetadata)
+		}
+	}
 
 	ctx.JSON(http.StatusOK, resp)
 }
@@ -83,18 +107,36 @@ func DownloadPackageFile(ctx *context.Context) {
 	packageVersion := ctx.Params("version")
 	filename := ctx.Params("filename")
 
-	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
-		ctx,
-		&packages_service.PackageInfo{
-			Owner:       ctx.Package.Owner,
-			PackageType: packages_model.TypeNpm,
-			Name:        packageName,
-			Version:     packageVersion,
-		},
-		&packages_service.PackageFileInfo{
-			Filename: filename,
-		},
-	)
+	pi := &packages_service.PackageInfo{
+		Owner:       ctx.Package.Owner,
+		PackageType: packages_model.TypeNpm,
+		Name:        packageName,
+		Version:     packageVersion,
+	}
+	pfi := &packages_service.PackageFileInfo{
+		Filename: filename,
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(ctx, pi, pfi)
+	if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		var pr *packages_model.PackageRemote
+		pr, err = remote_service.GetEnabledRemote(ctx, ctx.Package.Owner.ID, packages_model.TypeNpm)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if pr == nil {
+			apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+			return
+		}
+
+		if err := cacheRemotePackageVersion(ctx, pr, ctx.Package.Owner, packageName, packageVersion); err != nil {
+			apiError(ctx, helper.RemoteErrorStatus(err), err)
+			return
+		}
+
+		s, pf, err = packages_service.GetFileStreamByPackageNameAndVersion(ctx, pi, pfi)
+	}
 	if err != nil {
 		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
 			apiError(ctx, http.StatusNotFound, err)
@@ -221,8 +263,10 @@ func UploadPackage(ctx *context.Context) {
 		switch err {
 		case packages_model.ErrDuplicatePackageVersion:
 			apiError(ctx, http.StatusBadRequest, err)
-		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+		case packages_service.ErrQuotaTotalCount:
 			apiError(ctx, http.StatusForbidden, err)
+		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
 		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
@@ -0,0 +1,155 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package npm
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"path"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	npm_module "code.gitea.io/gitea/modules/packages/npm"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+)
+
+// getRemotePackageMetadata returns the package document of the upstream registry
+func getRemotePackageMetadata(ctx context.Context, pr *packages_model.PackageRemote, packageName string) (*npm_module.PackageMetadata, error) {
+	c, err := remote_service.NewClient(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := remote_service.GetMetadata(
+		ctx,
+		pr,
+		"npm/"+packageName,
+		c.FetchMetadata(c.URL(strings.Replace(url.PathEscape(packageName), "%40", "@", 1)), nil),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return npm_module.ParsePackageMetadata(bytes.NewReader(content))
+}
+
+// mergeRemotePackageMetadata adds the versions and tags of the upstream registry which are not available locally.
+// The tarballs of the added versions point to this registry so that they get cached on download.
+func mergeRemotePackageMetadata(registryURL string, local, remote *npm_module.PackageMetadata) *npm_module.PackageMetadata {
+	if local == nil {
+		local = &npm_module.PackageMetadata{
+			ID:          remote.ID,
+			Name:        remote.Name,
+			Description: remote.Description,
+			Versions:    make(map[string]*npm_module.PackageMetadataVersion, len(remote.Versions)),
+		}
+	}
+	if local.DistTags == nil {
+		local.DistTags = make(map[string]string, len(remote.DistTags))
+	}
+
+	for v, pmv := range remote.Versions {
+		if _, ok := local.Versions[v]; ok {
+			continue
+		}
+
+		pmv.Dist.Tarball = fmt.Sprintf("%s/%s/-/%s/%s", registryURL, url.QueryEscape(pmv.Name), url.PathEscape(pmv.Version), url.PathEscape(remoteTarballName(pmv)))
+		local.Versions[v] = pmv
+	}
+
+	for tag, v := range remote.DistTags {
+		if _, ok := local.DistTags[tag]; ok {
+			continue
+		}
+		if _, ok := local.Versions[v]; ok {
+			local.DistTags[tag] = v
+		}
+	}
+
+	return local
+}
+
+func remoteTarballName(pmv *npm_module.PackageMetadataVersion) string {
+	if u, err := url.Parse(pmv.Dist.Tarball); err == nil && path.Base(u.Path) != "" && path.Base(u.Path) != "/" {
+		return strings.ToLower(path.Base(u.Path))
+	}
+	return strings.ToLower(fmt.Sprintf("%s-%s.tgz", path.Base(pmv.Name), pmv.Version))
+}
+
+// cacheRemotePackageVersion downloads the tarball of the package version from the upstream registry and stores it
+func cacheRemotePackageVersion(ctx context.Context, pr *packages_model.PackageRemote, owner *user_model.User, packageName, packageVersion string) error {
+	pm, err := getRemotePackageMetadata(ctx, pr, packageName)
+	if err != nil {
+		return err
+	}
+
+	pmv, ok := pm.Versions[packageVersion]
+	if !ok || pmv.Dist.Tarball == "" {
+		return remote_service.ErrUpstreamNotExist
+	}
+
+	c, err := remote_service.NewClient(pr)
+	if err != nil {
+		return err
+	}
+
+	buf, err := c.Download(ctx, pmv.Dist.Tarball, nil)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	if !verifyRemoteIntegrity(buf, &pmv.Dist) {
+		return remote_service.ErrUpstreamIntegrity
+	}
+
+	return remote_service.CachePackageFile(
+		pr,
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       owner,
+				PackageType: packages_model.TypeNpm,
+				Name:        pmv.Name,
+				Version:     pmv.Version,
+			},
+			SemverCompatible: true,
+			Metadata:         npm_module.NewMetadata(pmv),
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: remoteTarballName(pmv),
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+}
+
+func verifyRemoteIntegrity(buf packages_module.HashSummer, dist *npm_module.PackageDistribution) bool {
+	_, hashSHA1, _, hashSHA512 := buf.Sums()
+
+	if algorithm, hash, ok := strings.Cut(dist.Integrity, "-"); ok {
+		expected, err := base64.StdEncoding.DecodeString(hash)
+		if err != nil {
+			return false
+		}
+		switch algorithm {
+		case "sha512":
+			return bytes.Equal(expected, hashSHA512)
+		case "sha1":
+			return bytes.Equal(expected, hashSHA1)
+		}
+	}
+	if dist.Shasum != "" {
+		return strings.EqualFold(dist.Shasum, hex.EncodeToString(hashSHA1))
+	}
+	return false
+}
@@ -12,13 +12,16 @@ import (
 	"strings"
 
 	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/container"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	packages_module "code.gitea.io/gitea/modules/packages"
 	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/routers/api/packages/helper"
 	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
 )
 
 // https://peps.python.org/pep-0426/#name
@@ -52,7 +55,14 @@ func PackageMetadata(ctx *context.Context) {
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
-	if len(pvs) == 0 {
+
+	pr, err := remote_service.GetEnabledRemote(ctx, ctx.Package.Owner.ID, packages_model.TypePyPI)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if len(pvs) == 0 && pr == nil {
 		apiError(ctx, http.StatusNotFound, err)
 		return
 	}
@@ -68,9 +78,38 @@ func PackageMetadata(ctx *context.Context) {
 		return strings.Compare(pds[i].Version.Version, pds[j].Version.Version) < 0
 	})
 
+	// list the files of the upstream registry which are not available locally
+	var remoteFiles []*pypi_module.IndexFile
+	if pr != nil {
+		files, err := getRemoteIndexFiles(ctx, pr, packageName)
+		if err != nil {
+			if len(pds) == 0 {
+				apiError(ctx, helper.RemoteErrorStatus(err), err)
+				return
+			}
+			log.Warn("Error fetching PyPI package %s from upstream registry: %v", packageName, err)
+		}
+
+		localFiles := make(container.Set[string])
+		for _, pd := range pds {
+			for _, pfd := range pd.Files {
+				localFiles.Add(pfd.File.Name)
+			}
+		}
+		for _, f := range files {
+			if !localFiles.Contains(f.Filename) {
+				remoteFiles = append(remoteFiles, f)
+			}
+		}
+	}
+
 	ctx.Data["RegistryURL"] = setting.AppURL + "api/packages/" + ctx.Package.Owner.Name + "/pypi"
-	ctx.Data["PackageDescriptor"] = pds[0]
+	ctx.Data["PackageName"] = packageName
+	if len(pds) != 0 {
+		ctx.Data["PackageName"] = pds[0].Package.Name
+	}
 	ctx.Data["PackageDescriptors"] = pds
+	ctx.Data["RemoteFiles"] = remoteFiles
 	ctx.HTML(http.StatusOK, "api/packages/pypi/simple")
 }
 
@@ -80,18 +119,36 @@ func DownloadPackageFile(ctx *context.Context) {
 	packageVersion := ctx.Params("version")
 	filename := ctx.Params("filename")
 
-	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
-		ctx,
-		&packages_service.PackageInfo{
-			Owner:       ctx.Package.Owner,
-			PackageType: packages_model.TypePyPI,
-			Name:        packageName,
-			Version:     packageVersion,
-		},
-		&packages_service.PackageFileInfo{
-			Filename: filename,
-		},
-	)
+	pi := &packages_service.PackageInfo{
+		Owner:       ctx.Package.Owner,
+		PackageType: packages_model.TypePyPI,
+		Name:        packageName,
+		Version:     packageVersion,
+	}
+	pfi := &packages_service.PackageFileInfo{
+		Filename: filename,
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(ctx, pi, pfi)
+	if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+		var pr *packages_model.PackageRemote
+		pr, err = remote_service.GetEnabledRemote(ctx, ctx.Package.Owner.ID, packages_model.TypePyPI)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+		if pr == nil || !isValidNameAndVersion(packageName, packageVersion) {
+			apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+			return
+		}
+
+		if err := cacheRemotePackageFile(ctx, pr, ctx.Package.Owner, packageName, packageVersion, filename); err != nil {
+			apiError(ctx, helper.RemoteErrorStatus(err), err)
+			return
+		}
+
+		s, pf, err = packages_service.GetFileStreamByPackageNameAndVersion(ctx, pi, pfi)
+	}
 	if err != nil {
 		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
 			apiError(ctx, http.StatusNotFound, err)
@@ -181,8 +238,10 @@ func UploadPackageFile(ctx *context.Context) {
 		switch err {
 		case packages_model.ErrDuplicatePackageFile:
 			apiError(ctx, http.StatusBadRequest, err)
-		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+		case packages_service.ErrQuotaTotalCount:
 			apiError(ctx, http.StatusForbidden, err)
+		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
 		default:
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
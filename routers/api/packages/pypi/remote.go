@@ -0,0 +1,114 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pypi
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"net/url"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	pypi_module "code.gitea.io/gitea/modules/packages/pypi"
+	packages_service "code.gitea.io/gitea/services/packages"
+	remote_service "code.gitea.io/gitea/services/packages/remote"
+)
+
+// getRemoteIndexFiles returns the files listed on the project page of the upstream simple repository
+func getRemoteIndexFiles(ctx context.Context, pr *packages_model.PackageRemote, packageName string) ([]*pypi_module.IndexFile, error) {
+	c, err := remote_service.NewClient(pr)
+	if err != nil {
+		return nil, err
+	}
+
+	pageURL, err := url.Parse(c.URL(url.PathEscape(strings.ToLower(packageName)) + "/"))
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := remote_service.GetMetadata(
+		ctx,
+		pr,
+		"pypi/"+strings.ToLower(packageName),
+		c.FetchMetadata(pageURL.String(), nil),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := pypi_module.ParseSimpleIndex(bytes.NewReader(content), pageURL)
+	if err != nil {
+		return nil, err
+	}
+
+	valid := make([]*pypi_module.IndexFile, 0, len(files))
+	for _, f := range files {
+		if isValidNameAndVersion(packageName, f.Version) {
+			valid = append(valid, f)
+		}
+	}
+	return valid, nil
+}
+
+// cacheRemotePackageFile downloads the file from the upstream simple repository and stores it
+func cacheRemotePackageFile(ctx context.Context, pr *packages_model.PackageRemote, owner *user_model.User, packageName, packageVersion, filename string) error {
+	files, err := getRemoteIndexFiles(ctx, pr, packageName)
+	if err != nil {
+		return err
+	}
+
+	var file *pypi_module.IndexFile
+	for _, f := range files {
+		if f.Filename == filename && f.Version == packageVersion {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return remote_service.ErrUpstreamNotExist
+	}
+
+	c, err := remote_service.NewClient(pr)
+	if err != nil {
+		return err
+	}
+
+	buf, err := c.Download(ctx, file.URL, nil)
+	if err != nil {
+		return err
+	}
+	defer buf.Close()
+
+	if file.SHA256 != "" {
+		_, _, hashSHA256, _ := buf.Sums()
+		if file.SHA256 != hex.EncodeToString(hashSHA256) {
+			return remote_service.ErrUpstreamIntegrity
+		}
+	}
+
+	return remote_service.CachePackageFile(
+		pr,
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       owner,
+				PackageType: packages_model.TypePyPI,
+				Name:        packageName,
+				Version:     file.Version,
+			},
+			SemverCompatible: false,
+			Metadata: &pypi_module.Metadata{
+				RequiresPython: file.RequiresPython,
+			},
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: file.Filename,
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+}
@@ -0,0 +1,612 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package terraform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	terraform_service "code.gitea.io/gitea/services/packages/terraform"
+)
+
+const signatureSuffix = ".sig"
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.JSON(status, struct {
+			Errors []string `json:"errors"`
+		}{
+			Errors: []string{
+				message,
+			},
+		})
+	})
+}
+
+// ServiceDiscovery tells Terraform where the registry protocols are served
+// https://developer.hashicorp.com/terraform/internals/remote-service-discovery
+func ServiceDiscovery(ctx *context.Context) {
+	baseURL := setting.AppSubURL + "/api/packages/-/terraform"
+
+	ctx.JSON(http.StatusOK, map[string]string{
+		"modules.v1":   baseURL + "/modules/v1/",
+		"providers.v1": baseURL + "/providers/v1/",
+	})
+}
+
+func baseURL(ctx *context.Context) string {
+	return fmt.Sprintf("%sapi/packages/%s/terraform", setting.AppURL, url.PathEscape(ctx.Package.Owner.Name))
+}
+
+func modulePackageName(ctx *context.Context) (string, bool) {
+	name, err := terraform_module.ModulePackageName(ctx.Params("name"), ctx.Params("system"))
+	if err != nil {
+		apiError(ctx, http.StatusNotFound, err)
+		return "", false
+	}
+	return name, true
+}
+
+func providerPackageName(ctx *context.Context) (string, bool) {
+	providerType := ctx.Params("provider")
+	if !terraform_module.IsValidProviderType(providerType) {
+		apiError(ctx, http.StatusNotFound, terraform_module.ErrInvalidName)
+		return "", false
+	}
+	return providerType, true
+}
+
+func getPackageDescriptors(ctx *context.Context, name string) []*packages_model.PackageDescriptor {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, name)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return nil
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return nil
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return nil
+	}
+
+	sort.Slice(pds, func(i, j int) bool {
+		return pds[i].SemVer.LessThan(pds[j].SemVer)
+	})
+
+	return pds
+}
+
+func getPackageDescriptor(ctx *context.Context, name, version string) *packages_model.PackageDescriptor {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, name, version)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return nil
+	}
+
+	pd, err := packages_model.GetPackageDescriptor(ctx, pv)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return nil
+	}
+	return pd
+}
+
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#list-available-versions-for-a-specific-module
+func EnumerateModuleVersions(ctx *context.Context) {
+	name, ok := modulePackageName(ctx)
+	if !ok {
+		return
+	}
+
+	pds := getPackageDescriptors(ctx, name)
+	if pds == nil {
+		return
+	}
+
+	type Version struct {
+		Version string `json:"version"`
+	}
+	type Module struct {
+		Versions []*Version `json:"versions"`
+	}
+
+	versions := make([]*Version, 0, len(pds))
+	for _, pd := range pds {
+		versions = append(versions, &Version{Version: pd.Version.Version})
+	}
+
+	ctx.JSON(http.StatusOK, struct {
+		Modules []*Module `json:"modules"`
+	}{
+		Modules: []*Module{{Versions: versions}},
+	})
+}
+
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#download-source-code-for-a-specific-module-version
+func DownloadModule(ctx *context.Context) {
+	name, ok := modulePackageName(ctx)
+	if !ok {
+		return
+	}
+
+	pd := getPackageDescriptor(ctx, name, ctx.Params("version"))
+	if pd == nil {
+		return
+	}
+
+	// the extension of the file tells Terraform how to unpack the module
+	ctx.Resp.Header().Set("X-Terraform-Get", fmt.Sprintf(
+		"%s/modules/%s/%s/%s/%s",
+		baseURL(ctx),
+		url.PathEscape(ctx.Params("name")),
+		url.PathEscape(ctx.Params("system")),
+		url.PathEscape(pd.Version.Version),
+		url.PathEscape(pd.Files[0].File.Name),
+	))
+	ctx.Status(http.StatusNoContent)
+}
+
+func DownloadModuleFile(ctx *context.Context) {
+	name, ok := modulePackageName(ctx)
+	if !ok {
+		return
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeTerraform,
+			Name:        name,
+			Version:     ctx.Params("version"),
+		},
+		&packages_service.PackageFileInfo{
+			Filename: ctx.Params("filename"),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeContent(s, &context.ServeHeaderOptions{
+		Filename:     pf.Name,
+		LastModified: pf.CreatedUnix.AsLocalTime(),
+	})
+}
+
+func UploadModule(ctx *context.Context) {
+	name, err := terraform_module.ModulePackageName(ctx.Params("name"), ctx.Params("system"))
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	version, err := terraform_module.ParseVersion(ctx.Params("version"))
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	upload, needsClose, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if needsClose {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	metadata, err := terraform_module.ParseModuleArchive(buf)
+	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			apiError(ctx, http.StatusBadRequest, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeTerraform,
+				Name:        name,
+				Version:     version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: strings.ToLower(fmt.Sprintf("%s-%s-%s.tar.gz", ctx.Params("name"), ctx.Params("system"), version)),
+			},
+			Creator: ctx.Doer,
+			Data:    buf,
+			IsLead:  true,
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageVersion:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount:
+			apiError(ctx, http.StatusForbidden, err)
+		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+func DeleteModule(ctx *context.Context) {
+	name, ok := modulePackageName(ctx)
+	if !ok {
+		return
+	}
+
+	deletePackageVersion(ctx, name)
+}
+
+// https://developer.hashicorp.com/terraform/internals/provider-registry-protocol#list-available-versions
+func EnumerateProviderVersions(ctx *context.Context) {
+	providerType, ok := providerPackageName(ctx)
+	if !ok {
+		return
+	}
+
+	pds := getPackageDescriptors(ctx, providerType)
+	if pds == nil {
+		return
+	}
+
+	type Platform struct {
+		OS   string `json:"os"`
+		Arch string `json:"arch"`
+	}
+	type Version struct {
+		Version   string      `json:"version"`
+		Protocols []string    `json:"protocols"`
+		Platforms []*Platform `json:"platforms"`
+	}
+
+	versions := make([]*Version, 0, len(pds))
+	for _, pd := range pds {
+		platforms := make([]*Platform, 0, len(pd.Files))
+		for _, pfd := range pd.Files {
+			platforms = append(platforms, &Platform{
+				OS:   pfd.Properties.GetByName(terraform_module.PropertyOS),
+				Arch: pfd.Properties.GetByName(terraform_module.PropertyArchitecture),
+			})
+		}
+
+		versions = append(versions, &Version{
+			Version:   pd.Version.Version,
+			Protocols: pd.Metadata.(*terraform_module.Metadata).Protocols,
+			Platforms: platforms,
+		})
+	}
+
+	ctx.JSON(http.StatusOK, struct {
+		Versions []*Version `json:"versions"`
+	}{
+		Versions: versions,
+	})
+}
+
+// https://developer.hashicorp.com/terraform/internals/provider-registry-protocol#find-a-provider-package
+func DownloadProvider(ctx *context.Context) {
+	providerType, ok := providerPackageName(ctx)
+	if !ok {
+		return
+	}
+	os, arch := ctx.Params("os"), ctx.Params("arch")
+
+	pd := getPackageDescriptor(ctx, providerType, ctx.Params("version"))
+	if pd == nil {
+		return
+	}
+
+	filename := terraform_service.ProviderFilename(providerType, pd.Version.Version, os, arch)
+
+	var pfd *packages_model.PackageFileDescriptor
+	for _, f := range pd.Files {
+		if f.File.LowerName == strings.ToLower(filename) {
+			pfd = f
+			break
+		}
+	}
+	if pfd == nil {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageFileNotExist)
+		return
+	}
+
+	_, pub, err := terraform_service.GetOrCreateKeyPair(ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	keyID, err := terraform_service.GetKeyID(ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	type GPGPublicKey struct {
+		KeyID      string `json:"key_id"`
+		ASCIIArmor string `json:"ascii_armor"`
+	}
+	type SigningKeys struct {
+		GPGPublicKeys []*GPGPublicKey `json:"gpg_public_keys"`
+	}
+	type Package struct {
+		Protocols           []string     `json:"protocols"`
+		OS                  string       `json:"os"`
+		Arch                string       `json:"arch"`
+		Filename            string       `json:"filename"`
+		DownloadURL         string       `json:"download_url"`
+		ShasumsURL          string       `json:"shasums_url"`
+		ShasumsSignatureURL string       `json:"shasums_signature_url"`
+		Shasum              string       `json:"shasum"`
+		SigningKeys         *SigningKeys `json:"signing_keys"`
+	}
+
+	versionURL := fmt.Sprintf("%s/providers/%s/%s", baseURL(ctx), url.PathEscape(pd.Package.Name), url.PathEscape(pd.Version.Version))
+	checksumsURL := versionURL + "/" + url.PathEscape(terraform_service.ChecksumsFilename(pd.Package.Name, pd.Version.Version))
+
+	ctx.JSON(http.StatusOK, &Package{
+		Protocols:           pd.Metadata.(*terraform_module.Metadata).Protocols,
+		OS:                  pfd.Properties.GetByName(terraform_module.PropertyOS),
+		Arch:                pfd.Properties.GetByName(terraform_module.PropertyArchitecture),
+		Filename:            pfd.File.Name,
+		DownloadURL:         versionURL + "/" + url.PathEscape(pfd.File.Name),
+		ShasumsURL:          checksumsURL,
+		ShasumsSignatureURL: checksumsURL + signatureSuffix,
+		Shasum:              pfd.Blob.HashSHA256,
+		SigningKeys: &SigningKeys{
+			GPGPublicKeys: []*GPGPublicKey{
+				{
+					KeyID:      keyID,
+					ASCIIArmor: pub,
+				},
+			},
+		},
+	})
+}
+
+// DownloadProviderFile serves the archives of a provider version and the signed checksums of them
+func DownloadProviderFile(ctx *context.Context) {
+	providerType, ok := providerPackageName(ctx)
+	if !ok {
+		return
+	}
+	filename := ctx.Params("filename")
+
+	checksumsFilename, isSignature := strings.CutSuffix(filename, signatureSuffix)
+	if strings.HasSuffix(checksumsFilename, "_SHA256SUMS") {
+		pd := getPackageDescriptor(ctx, providerType, ctx.Params("version"))
+		if pd == nil {
+			return
+		}
+
+		if !strings.EqualFold(checksumsFilename, terraform_service.ChecksumsFilename(pd.Package.Name, pd.Version.Version)) {
+			apiError(ctx, http.StatusNotFound, packages_model.ErrPackageFileNotExist)
+			return
+		}
+
+		content := terraform_service.BuildChecksums(pd)
+		if isSignature {
+			content, err := terraform_service.CreateSignature(ctx.Package.Owner.ID, bytes.NewReader(content))
+			if err != nil {
+				apiError(ctx, http.StatusInternalServerError, err)
+				return
+			}
+
+			ctx.ServeContent(bytes.NewReader(content), &context.ServeHeaderOptions{
+				Filename: filename,
+			})
+			return
+		}
+
+		ctx.ServeContent(bytes.NewReader(content), &context.ServeHeaderOptions{
+			ContentType: "text/plain",
+			Filename:    filename,
+		})
+		return
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeTerraform,
+			Name:        providerType,
+			Version:     ctx.Params("version"),
+		},
+		&packages_service.PackageFileInfo{
+			Filename: filename,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeContent(s, &context.ServeHeaderOptions{
+		Filename:     pf.Name,
+		LastModified: pf.CreatedUnix.AsLocalTime(),
+	})
+}
+
+func UploadProvider(ctx *context.Context) {
+	providerType, os, arch := ctx.Params("provider"), ctx.Params("os"), ctx.Params("arch")
+	if !terraform_module.IsValidProviderType(providerType) {
+		apiError(ctx, http.StatusBadRequest, terraform_module.ErrInvalidName)
+		return
+	}
+	if !terraform_module.IsValidPlatform(os, arch) {
+		apiError(ctx, http.StatusBadRequest, terraform_module.ErrInvalidPlatform)
+		return
+	}
+	version, err := terraform_module.ParseVersion(ctx.Params("version"))
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	protocols, err := terraform_module.ParseProtocols(ctx.FormString("protocols"))
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	upload, needsClose, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if needsClose {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	if err := terraform_module.ValidateProviderArchive(buf); err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeTerraform,
+				Name:        providerType,
+				Version:     version,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata: &terraform_module.Metadata{
+				Kind:      terraform_module.KindProvider,
+				Protocols: protocols,
+			},
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: terraform_service.ProviderFilename(providerType, version, os, arch),
+			},
+			Creator: ctx.Doer,
+			Data:    buf,
+			IsLead:  true,
+			Properties: map[string]string{
+				terraform_module.PropertyOS:           os,
+				terraform_module.PropertyArchitecture: arch,
+			},
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageFile:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount:
+			apiError(ctx, http.StatusForbidden, err)
+		case packages_service.ErrQuotaTypeSize, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+func DeleteProvider(ctx *context.Context) {
+	providerType, ok := providerPackageName(ctx)
+	if !ok {
+		return
+	}
+
+	deletePackageVersion(ctx, providerType)
+}
+
+func deletePackageVersion(ctx *context.Context, name string) {
+	err := packages_service.RemovePackageVersionByNameAndVersion(
+		ctx.Doer,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeTerraform,
+			Name:        name,
+			Version:     ctx.Params("version"),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
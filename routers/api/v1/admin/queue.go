@@ -0,0 +1,377 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package admin
+
+import (
+	"errors"
+	"net/http"
+	"sort"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/queue"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+func toAPIQueue(qid int64, mq queue.ManagedWorkerPoolQueue) *api.Queue {
+	return &api.Queue{
+		ID:                  qid,
+		Name:                mq.GetName(),
+		Type:                mq.GetType(),
+		ItemType:            mq.GetItemTypeName(),
+		NumberOfWorkers:     mq.GetWorkerNumber(),
+		MaxNumberOfWorkers:  mq.GetWorkerMaxNumber(),
+		NumberOfItems:       mq.GetQueueItemNumber(),
+		NumberOfDeadLetters: mq.GetDeadLetterNumber(),
+		CanInspectItems:     mq.CanInspectItems(),
+	}
+}
+
+// getManagedQueue returns the queue of the request, or responds with 404 if it doesn't exist
+func getManagedQueue(ctx *context.APIContext) queue.ManagedWorkerPoolQueue {
+	mq := queue.GetManager().GetManagedQueue(ctx.ParamsInt64(":qid"))
+	if mq == nil {
+		ctx.NotFound()
+	}
+	return mq
+}
+
+// ListQueues api for listing the queues
+func ListQueues(ctx *context.APIContext) {
+	// swagger:operation GET /admin/queues admin adminListQueues
+	// ---
+	// summary: List the queues of background tasks
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QueueList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	queues := queue.GetManager().ManagedQueues()
+	qids := make([]int64, 0, len(queues))
+	for qid := range queues {
+		qids = append(qids, qid)
+	}
+	sort.Slice(qids, func(i, j int) bool { return qids[i] < qids[j] })
+
+	res := make([]*api.Queue, 0, len(qids))
+	for _, qid := range qids {
+		res = append(res, toAPIQueue(qid, queues[qid]))
+	}
+	ctx.JSON(http.StatusOK, res)
+}
+
+// GetQueue api for getting a queue
+func GetQueue(ctx *context.APIContext) {
+	// swagger:operation GET /admin/queues/{qid} admin adminGetQueue
+	// ---
+	// summary: Get a queue of background tasks
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Queue"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	mq := getManagedQueue(ctx)
+	if mq == nil {
+		return
+	}
+	ctx.JSON(http.StatusOK, toAPIQueue(ctx.ParamsInt64(":qid"), mq))
+}
+
+func respondQueueItems(ctx *context.APIContext, items []*queue.Item, total int) {
+	res := make([]*api.QueueItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, &api.QueueItem{
+			ID:    item.ID,
+			Data:  item.Data,
+			Valid: item.IsValid,
+		})
+	}
+	ctx.SetTotalCountHeader(int64(total))
+	ctx.JSON(http.StatusOK, res)
+}
+
+// ListQueueItems api for listing the items waiting in a queue
+func ListQueueItems(ctx *context.APIContext) {
+	// swagger:operation GET /admin/queues/{qid}/items admin adminListQueueItems
+	// ---
+	// summary: List the items waiting in a queue, the items which are being handled are not listed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QueueItemList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	mq := getManagedQueue(ctx)
+	if mq == nil {
+		return
+	}
+	if !mq.CanInspectItems() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "the items of this queue type can't be inspected")
+		return
+	}
+	listOpts := utils.GetListOptions(ctx)
+	skip, limit := listOpts.GetSkipTake()
+	items, total, err := mq.ListItems(ctx, skip, limit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListItems", err)
+		return
+	}
+	respondQueueItems(ctx, items, total)
+}
+
+// queueItemAction runs an action on an item of a queue (or of its dead letters) and responds with 204
+func queueItemAction(ctx *context.APIContext, name string, action func(mq queue.ManagedWorkerPoolQueue, id string) error) {
+	mq := getManagedQueue(ctx)
+	if mq == nil {
+		return
+	}
+	if err := action(mq, ctx.Params(":id")); err != nil {
+		if errors.Is(err, util.ErrNotExist) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, name, err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// DeleteQueueItem api for removing an item waiting in a queue
+func DeleteQueueItem(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/queues/{qid}/items/{id} admin adminDeleteQueueItem
+	// ---
+	// summary: Remove an item waiting in a queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the item
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	queueItemAction(ctx, "RemoveItem", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.RemoveItem(ctx, id)
+	})
+}
+
+// MoveQueueItemToDeadLetters api for moving an item waiting in a queue to the dead letters of the queue
+func MoveQueueItemToDeadLetters(ctx *context.APIContext) {
+	// swagger:operation POST /admin/queues/{qid}/items/{id}/dead-letter admin adminMoveQueueItemToDeadLetters
+	// ---
+	// summary: Move an item waiting in a queue to the dead letters of the queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the item
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	queueItemAction(ctx, "MoveItemToDeadLetters", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.MoveItemToDeadLetters(ctx, id)
+	})
+}
+
+// ListQueueDeadLetters api for listing the dead letters of a queue
+func ListQueueDeadLetters(ctx *context.APIContext) {
+	// swagger:operation GET /admin/queues/{qid}/dead-letters admin adminListQueueDeadLetters
+	// ---
+	// summary: List the items which have been moved to the dead letters of a queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/QueueItemList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	mq := getManagedQueue(ctx)
+	if mq == nil {
+		return
+	}
+	if !mq.CanInspectItems() {
+		ctx.Error(http.StatusUnprocessableEntity, "", "this queue type has no dead letters")
+		return
+	}
+	listOpts := utils.GetListOptions(ctx)
+	skip, limit := listOpts.GetSkipTake()
+	items, total, err := mq.ListDeadLetters(ctx, skip, limit)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ListDeadLetters", err)
+		return
+	}
+	respondQueueItems(ctx, items, total)
+}
+
+// DeleteQueueDeadLetter api for removing an item from the dead letters of a queue
+func DeleteQueueDeadLetter(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/queues/{qid}/dead-letters/{id} admin adminDeleteQueueDeadLetter
+	// ---
+	// summary: Remove an item from the dead letters of a queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the item
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	queueItemAction(ctx, "RemoveDeadLetter", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.RemoveDeadLetter(ctx, id)
+	})
+}
+
+// RequeueQueueDeadLetter api for pushing an item of the dead letters of a queue back to the queue
+func RequeueQueueDeadLetter(ctx *context.APIContext) {
+	// swagger:operation POST /admin/queues/{qid}/dead-letters/{id}/requeue admin adminRequeueQueueDeadLetter
+	// ---
+	// summary: Push an item of the dead letters of a queue back to the queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the item
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	queueItemAction(ctx, "RequeueDeadLetter", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.RequeueDeadLetter(ctx, id)
+	})
+}
+
+// RequeueQueueDeadLetters api for pushing all the items of the dead letters of a queue back to the queue
+func RequeueQueueDeadLetters(ctx *context.APIContext) {
+	// swagger:operation POST /admin/queues/{qid}/dead-letters/requeue admin adminRequeueQueueDeadLetters
+	// ---
+	// summary: Push all the items of the dead letters of a queue back to the queue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: qid
+	//   in: path
+	//   description: id of the queue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	mq := getManagedQueue(ctx)
+	if mq == nil {
+		return
+	}
+	if _, err := mq.RequeueAllDeadLetters(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RequeueAllDeadLetters", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -755,6 +755,7 @@ func Routes() *web.Route {
 		m.Group("", func() {
 			m.Get("/version", misc.Version)
 			m.Get("/signing-key.gpg", misc.SigningKey)
+			m.Get("/webhooks/signing-key.pem", misc.WebhookSigningKey)
 			m.Post("/markup", reqToken(), bind(api.MarkupOption{}), misc.Markup)
 			m.Post("/markdown", reqToken(), bind(api.MarkdownOption{}), misc.Markdown)
 			m.Post("/markdown/raw", reqToken(), misc.MarkdownRaw)
@@ -899,6 +900,13 @@ func Routes() *web.Route {
 					Patch(bind(api.EditHookOption{}), user.EditHook).
 					Delete(user.DeleteHook)
 			}, reqWebhooksEnabled())
+			m.Group("/actions/variables", func() {
+				m.Get("", user.ListVariables)
+				m.Combo("/{variablename}").Get(user.GetVariable).
+					Post(bind(api.CreateVariableOption{}), user.CreateVariable).
+					Put(bind(api.UpdateVariableOption{}), user.UpdateVariable).
+					Delete(user.DeleteVariable)
+			})
 		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryUser), reqToken())
 
 		// Repositories (requires repo scope, org scope)
@@ -946,6 +954,15 @@ func Routes() *web.Route {
 						m.Post("/tests", context.ReferencesGitRepo(), context.RepoRefForAPI, repo.TestHook)
 					})
 				}, reqToken(), reqAdmin(), reqWebhooksEnabled())
+				m.Group("/actions/variables", func() {
+					m.Get("", repo.ListVariables)
+					m.Combo("/{variablename}").Get(repo.GetVariable).
+						Post(bind(api.CreateVariableOption{}), repo.CreateVariable).
+						Put(bind(api.UpdateVariableOption{}), repo.UpdateVariable).
+						Delete(repo.DeleteVariable)
+				}, reqToken(), reqAdmin())
+				m.Post("/actions/workflows/{workflowfilename}/dispatches", reqToken(), reqRepoWriter(unit.TypeActions),
+					context.ReferencesGitRepo(), bind(api.CreateActionWorkflowDispatch{}), repo.DispatchWorkflow)
 				m.Group("/collaborators", func() {
 					m.Get("", reqAnyRepoReader(), repo.ListCollaborators)
 					m.Group("/{collaborator}", func() {
@@ -1065,6 +1082,8 @@ func Routes() *web.Route {
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
 							Post(reqToken(), mustNotBeArchived, bind(forms.MergePullRequestForm{}), repo.MergePullRequest).
 							Delete(reqToken(), mustNotBeArchived, repo.CancelScheduledAutoMerge)
+						m.Combo("/merge_queue").Get(repo.GetPullRequestMergeQueueEntry).
+							Delete(reqToken(), mustNotBeArchived, repo.RemovePullRequestFromMergeQueue)
 						m.Group("/reviews", func() {
 							m.Combo("").
 								Get(repo.ListPullReviews).
@@ -1085,6 +1104,7 @@ func Routes() *web.Route {
 							Post(bind(api.PullReviewRequestOptions{}), repo.CreateReviewRequests)
 					})
 				}, mustAllowPulls, reqRepoReader(unit.TypeCode), context.ReferencesGitRepo())
+				m.Get("/merge_queue/*", mustAllowPulls, reqRepoReader(unit.TypeCode), repo.ListMergeQueue)
 				m.Group("/statuses", func() {
 					m.Combo("/{sha}").Get(repo.GetCommitStatuses).
 						Post(reqToken(), reqRepoWriter(unit.TypeCode), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
@@ -1314,6 +1334,13 @@ func Routes() *web.Route {
 					Patch(bind(api.EditHookOption{}), org.EditHook).
 					Delete(org.DeleteHook)
 			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
+			m.Group("/actions/variables", func() {
+				m.Get("", org.ListVariables)
+				m.Combo("/{variablename}").Get(org.GetVariable).
+					Post(bind(api.CreateVariableOption{}), org.CreateVariable).
+					Put(bind(api.UpdateVariableOption{}), org.UpdateVariable).
+					Delete(org.DeleteVariable)
+			}, reqToken(), reqOrgOwnership())
 			m.Get("/activities/feeds", org.ListOrgActivityFeeds)
 		}, tokenRequiresScopes(auth_model.AccessTokenScopeCategoryOrganization), orgAssignment(true))
 		m.Group("/teams/{teamid}", func() {
@@ -1342,6 +1369,19 @@ func Routes() *web.Route {
 				m.Get("", admin.ListCronTasks)
 				m.Post("/{task}", admin.PostCronTask)
 			})
+			m.Group("/queues", func() {
+				m.Get("", admin.ListQueues)
+				m.Group("/{qid}", func() {
+					m.Get("", admin.GetQueue)
+					m.Get("/items", admin.ListQueueItems)
+					m.Delete("/items/{id}", admin.DeleteQueueItem)
+					m.Post("/items/{id}/dead-letter", admin.MoveQueueItemToDeadLetters)
+					m.Get("/dead-letters", admin.ListQueueDeadLetters)
+					m.Post("/dead-letters/requeue", admin.RequeueQueueDeadLetters)
+					m.Delete("/dead-letters/{id}", admin.DeleteQueueDeadLetter)
+					m.Post("/dead-letters/{id}/requeue", admin.RequeueQueueDeadLetter)
+				})
+			})
 			m.Get("/orgs", admin.GetAllOrgs)
 			m.Group("/users", func() {
 				m.Get("", admin.SearchUsers)
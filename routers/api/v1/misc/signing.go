@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/modules/context"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	webhook_service "code.gitea.io/gitea/services/webhook"
 )
 
 // SigningKey returns the public key of the default signing key if it exists
@@ -61,3 +62,27 @@ func SigningKey(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "gpg export", fmt.Errorf("Error writing key content %w", err))
 	}
 }
+
+// WebhookSigningKey returns the public key verifying the HTTP signatures of webhook deliveries
+func WebhookSigningKey(ctx *context.APIContext) {
+	// swagger:operation GET /webhooks/signing-key.pem miscellaneous getWebhookSigningKey
+	// ---
+	// summary: Get the public key verifying the HTTP signatures of webhook deliveries
+	// produces:
+	//     - text/plain
+	// responses:
+	//   "200":
+	//     description: "PEM encoded public key"
+	//     schema:
+	//       type: string
+
+	content, err := webhook_service.GetSigningPublicKey(ctx)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetSigningPublicKey", err)
+		return
+	}
+	_, err = ctx.Write([]byte(content))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "webhook signing key", fmt.Errorf("Error writing key content %w", err))
+	}
+}
@@ -0,0 +1,75 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package repo
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+// DispatchWorkflow creates a run of a workflow with a workflow_dispatch trigger
+func DispatchWorkflow(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/workflows/{workflowfilename}/dispatches repository repoDispatchActionsWorkflow
+	// ---
+	// summary: Run a workflow which has a workflow_dispatch trigger
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: workflowfilename
+	//   in: path
+	//   description: file name of the workflow
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateActionWorkflowDispatch"
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opt := web.GetForm(ctx).(*api.CreateActionWorkflowDispatch)
+
+	_, err := actions_service.DispatchWorkflow(ctx, ctx.Doer, ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.Params("workflowfilename"), opt.Ref, opt.Inputs)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrNotExist):
+			ctx.NotFound(err)
+		case errors.Is(err, util.ErrPermissionDenied):
+			ctx.Error(http.StatusForbidden, "DispatchWorkflow", err)
+		case errors.Is(err, util.ErrInvalidArgument):
+			ctx.Error(http.StatusUnprocessableEntity, "DispatchWorkflow", err)
+		default:
+			ctx.InternalServerError(err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -546,6 +546,10 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		ProtectedFilePatterns:         form.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       form.UnprotectedFilePatterns,
 		BlockOnOutdatedBranch:         form.BlockOnOutdatedBranch,
+		EnableMergeQueue:              form.EnableMergeQueue,
+		RequireCodeOwnerApproval:      form.RequireCodeOwnerApproval,
+		RequireLinearHistory:          form.RequireLinearHistory,
+		RequireConversationResolution: form.RequireConversationResolution,
 	}
 
 	err = git_model.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
@@ -726,6 +730,22 @@ func EditBranchProtection(ctx *context.APIContext) {
 		protectBranch.BlockOnOutdatedBranch = *form.BlockOnOutdatedBranch
 	}
 
+	if form.EnableMergeQueue != nil {
+		protectBranch.EnableMergeQueue = *form.EnableMergeQueue
+	}
+
+	if form.RequireCodeOwnerApproval != nil {
+		protectBranch.RequireCodeOwnerApproval = *form.RequireCodeOwnerApproval
+	}
+
+	if form.RequireLinearHistory != nil {
+		protectBranch.RequireLinearHistory = *form.RequireLinearHistory
+	}
+
+	if form.RequireConversationResolution != nil {
+		protectBranch.RequireConversationResolution = *form.RequireConversationResolution
+	}
+
 	var whitelistUsers []int64
 	if form.PushWhitelistUsernames != nil {
 		whitelistUsers, err = user_model.GetUserIDsByNames(ctx, form.PushWhitelistUsernames, false)
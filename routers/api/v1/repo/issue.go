@@ -29,6 +29,8 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	"code.gitea.io/gitea/services/convert"
 	issue_service "code.gitea.io/gitea/services/issue"
+
+	"xorm.io/builder"
 )
 
 // SearchIssues searches for issues across the repositories that the user has access to
@@ -53,7 +55,7 @@ func SearchIssues(ctx *context.APIContext) {
 	//   type: string
 	// - name: q
 	//   in: query
-	//   description: search string
+	//   description: search string, it may contain qualifiers like is:open, is:pr, label:bug, author:name, assignee:name, mentions:name, milestone:name, repo:owner/name, no:label, created:>2006-01-02 or updated:2006-01-01..2006-12-31, prefixed with - to exclude
 	//   type: string
 	// - name: priority_repo_id
 	//   in: query
@@ -115,6 +117,8 @@ func SearchIssues(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/IssueList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	before, since, err := context.GetQueryBeforeSince(ctx.Base)
 	if err != nil {
@@ -189,16 +193,38 @@ func SearchIssues(ctx *context.APIContext) {
 	var issues []*issues_model.Issue
 	var filteredCount int64
 
-	keyword := ctx.FormTrim("q")
-	if strings.IndexByte(keyword, 0) >= 0 {
-		keyword = ""
+	query := ctx.FormTrim("q")
+	if strings.IndexByte(query, 0) >= 0 {
+		query = ""
+	}
+	// the query may contain qualifiers (is:open, label:bug, author:name, ...) filtering the issues
+	searchOpts, err := issue_indexer.ParseSearchQuery(query)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "ParseSearchQuery", err)
+		return
 	}
+	keyword := searchOpts.Keyword
+	var filterCond builder.Cond
 	var issueIDs []int64
-	if len(keyword) > 0 && len(repoIDs) > 0 {
-		if issueIDs, err = issue_indexer.SearchIssuesByKeyword(ctx, repoIDs, keyword); err != nil {
-			ctx.Error(http.StatusInternalServerError, "SearchIssuesByKeyword", err)
+	if len(keyword) == 0 {
+		// without keyword, the qualifiers are applied by the database
+		if filterCond, err = searchOpts.Cond(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "IssueFilter.Cond", err)
 			return
 		}
+	} else if len(repoIDs) > 0 {
+		// with a keyword, they are searched by the issue indexer together with the keyword
+		filter, err := searchOpts.Resolve(ctx, repoIDs)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IssueFilter.Resolve", err)
+			return
+		}
+		if filter != nil {
+			if issueIDs, err = issue_indexer.SearchIssues(ctx, keyword, filter); err != nil {
+				ctx.Error(http.StatusInternalServerError, "SearchIssues", err)
+				return
+			}
+		}
 	}
 
 	var isPull util.OptionalBool
@@ -211,6 +237,14 @@ func SearchIssues(ctx *context.APIContext) {
 		isPull = util.OptionalBoolNone
 	}
 
+	// the qualifiers of the query take precedence over the state and type parameters
+	if !searchOpts.IsClosed.IsNone() {
+		isClosed = searchOpts.IsClosed
+	}
+	if !searchOpts.IsPull.IsNone() {
+		isPull = searchOpts.IsPull
+	}
+
 	labels := ctx.FormTrim("labels")
 	var includedLabelNames []string
 	if len(labels) > 0 {
@@ -250,6 +284,7 @@ func SearchIssues(ctx *context.APIContext) {
 			IsPull:             isPull,
 			UpdatedBeforeUnix:  before,
 			UpdatedAfterUnix:   since,
+			FilterCond:         filterCond,
 		}
 
 		ctxUserID := int64(0)
@@ -157,6 +157,7 @@ func Migrate(ctx *context.APIContext) {
 		Comments:       form.Issues || form.PullRequests,
 		PullRequests:   form.PullRequests,
 		Releases:       form.Releases,
+		Sync:           form.Sync && gitServiceType.IncrementalSync(),
 		GitServiceType: gitServiceType,
 		MirrorInterval: form.MirrorInterval,
 	}
@@ -167,6 +168,7 @@ func Migrate(ctx *context.APIContext) {
 		opts.Comments = false
 		opts.PullRequests = false
 		opts.Releases = false
+		opts.Sync = false
 	}
 
 	repo, err := repo_module.CreateRepository(ctx.Doer, repoOwner, repo_module.CreateRepoOptions{
@@ -731,6 +731,8 @@ func MergePullRequest(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/empty"
+	//   "202":
+	//     "$ref": "#/responses/empty"
 	//   "405":
 	//     "$ref": "#/responses/empty"
 	//   "409":
@@ -835,6 +837,16 @@ func MergePullRequest(ctx *context.APIContext) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
+	// check the merge style before it is scheduled or queued
+	if err := pull_service.CheckMergeStyleAllowed(ctx, pr, repo_model.MergeStyle(form.Do)); err != nil {
+		if models.IsErrInvalidMergeStyle(err) {
+			ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", repo_model.MergeStyle(form.Do)))
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "CheckMergeStyleAllowed", err)
+		return
+	}
+
 	if form.MergeWhenChecksSucceed {
 		scheduled, err := automerge.ScheduleAutoMerge(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message)
 		if err != nil {
@@ -851,6 +863,23 @@ func MergePullRequest(ctx *context.APIContext) {
 		}
 	}
 
+	// the base branch can only be changed through the merge queue
+	if mergeQueueEnabled, err := pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsMergeQueueEnabled", err)
+		return
+	} else if mergeQueueEnabled {
+		if err := automerge.AddToMergeQueue(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message); err != nil {
+			if pull_model.IsErrAlreadyInMergeQueue(err) {
+				ctx.Error(http.StatusConflict, "AddToMergeQueue", err)
+				return
+			}
+			ctx.Error(http.StatusInternalServerError, "AddToMergeQueue", err)
+			return
+		}
+		ctx.Status(http.StatusAccepted)
+		return
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, message, false); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Error(http.StatusMethodNotAllowed, "Invalid merge style", fmt.Errorf("%s is not allowed an allowed merge style for this repository", repo_model.MergeStyle(form.Do)))
@@ -1231,6 +1260,183 @@ func CancelScheduledAutoMerge(ctx *context.APIContext) {
 	}
 }
 
+// GetPullRequestMergeQueueEntry gets the merge queue entry of a pull request
+func GetPullRequestMergeQueueEntry(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoGetPullRequestMergeQueueEntry
+	// ---
+	// summary: Get the merge queue entry of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MergeQueueEntry"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pull, entry := getPullRequestMergeQueueEntry(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	position, err := pull_model.GetMergeQueuePosition(ctx, entry)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	if err := entry.LoadDoer(ctx); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToMergeQueueEntry(ctx, entry, pull.Index, position, ctx.Doer))
+}
+
+// RemovePullRequestFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemovePullRequestFromMergeQueue(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/merge_queue repository repoRemovePullRequestFromMergeQueue
+	// ---
+	// summary: Remove a pull request from the merge queue of its base branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pull, entry := getPullRequestMergeQueueEntry(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.Doer.ID != entry.DoerID {
+		allowed, err := access_model.IsUserRepoAdmin(ctx, ctx.Repo.Repository, ctx.Doer)
+		if err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		if !allowed {
+			ctx.Error(http.StatusForbidden, "No permission to remove", "user has no permission to remove the pull request from the merge queue")
+			return
+		}
+	}
+
+	if err := automerge.RemoveFromMergeQueue(ctx, ctx.Doer, pull, ""); err != nil {
+		ctx.InternalServerError(err)
+	} else {
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+func getPullRequestMergeQueueEntry(ctx *context.APIContext) (*issues_model.PullRequest, *pull_model.MergeQueueEntry) {
+	pull, err := issues_model.GetPullRequestByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if issues_model.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+			return nil, nil
+		}
+		ctx.InternalServerError(err)
+		return nil, nil
+	}
+
+	exist, entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pull.ID)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return nil, nil
+	}
+	if !exist {
+		ctx.NotFound()
+		return nil, nil
+	}
+	return pull, entry
+}
+
+// ListMergeQueue lists the pull requests in the merge queue of a branch
+func ListMergeQueue(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/merge_queue/{branch} repository repoListMergeQueue
+	// ---
+	// summary: List the pull requests in the merge queue of a branch in processing order
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: branch
+	//   in: path
+	//   description: name of the base branch
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MergeQueueEntryList"
+
+	entries, err := pull_model.GetMergeQueue(ctx, ctx.Repo.Repository.ID, ctx.Params("*"))
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiEntries := make([]*api.MergeQueueEntry, 0, len(entries))
+	for i, entry := range entries {
+		pull, err := issues_model.GetPullRequestByID(ctx, entry.PullID)
+		if err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		if err := entry.LoadDoer(ctx); err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		apiEntries = append(apiEntries, convert.ToMergeQueueEntry(ctx, entry, pull.Index, int64(i+1), ctx.Doer))
+	}
+
+	ctx.JSON(http.StatusOK, apiEntries)
+}
+
 // GetPullRequestCommits gets all commits associated with a given PR
 func GetPullRequestCommits(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/commits repository repoGetPullRequestCommits
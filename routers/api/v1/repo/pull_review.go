@@ -4,6 +4,7 @@
 package repo
 
 import (
+	"errors"
 	"fmt"
 	"net/http"
 	"strings"
@@ -15,6 +16,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	"code.gitea.io/gitea/services/convert"
@@ -348,15 +350,16 @@ func CreatePullReview(ctx *context.APIContext) {
 
 	// create review comments
 	for _, c := range opts.Comments {
-		line := c.NewLineNum
+		line, startLine := c.NewLineNum, c.NewStartLineNum
 		if c.OldLineNum > 0 {
-			line = c.OldLineNum * -1
+			line, startLine = c.OldLineNum*-1, c.OldStartLineNum*-1
 		}
 
 		if _, err := pull_service.CreateCodeComment(ctx,
 			ctx.Doer,
 			ctx.Repo.GitRepo,
 			pr.Issue,
+			startLine,
 			line,
 			c.Body,
 			c.Path,
@@ -364,6 +367,10 @@ func CreatePullReview(ctx *context.APIContext) {
 			0,    // no reply
 			opts.CommitID,
 		); err != nil {
+			if errors.Is(err, util.ErrInvalidArgument) {
+				ctx.Error(http.StatusUnprocessableEntity, "CreateCodeComment", err)
+				return
+			}
 			ctx.Error(http.StatusInternalServerError, "CreateCodeComment", err)
 			return
 		}
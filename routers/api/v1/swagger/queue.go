@@ -0,0 +1,29 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Queue
+// swagger:response Queue
+type swaggerResponseQueue struct {
+	// in:body
+	Body api.Queue `json:"body"`
+}
+
+// QueueList
+// swagger:response QueueList
+type swaggerResponseQueueList struct {
+	// in:body
+	Body []api.Queue `json:"body"`
+}
+
+// QueueItemList
+// swagger:response QueueItemList
+type swaggerResponseQueueItemList struct {
+	// in:body
+	Body []api.QueueItem `json:"body"`
+}
@@ -0,0 +1,140 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package user
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListVariables lists the actions variables of the authenticated user
+func ListVariables(ctx *context.APIContext) {
+	// swagger:operation GET /user/actions/variables user userListActionsVariables
+	// ---
+	// summary: List the actions variables of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionVariableList"
+
+	utils.ListVariables(ctx, ctx.Doer.ID, 0)
+}
+
+// GetVariable gets an actions variable of the authenticated user
+func GetVariable(ctx *context.APIContext) {
+	// swagger:operation GET /user/actions/variables/{variablename} user userGetActionsVariable
+	// ---
+	// summary: Get an actions variable of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: variablename
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionVariable"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.GetVariable(ctx, ctx.Doer.ID, 0)
+}
+
+// CreateVariable creates an actions variable of the authenticated user
+func CreateVariable(ctx *context.APIContext) {
+	// swagger:operation POST /user/actions/variables/{variablename} user userCreateActionsVariable
+	// ---
+	// summary: Create an actions variable of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: variablename
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateVariableOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ActionVariable"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	utils.CreateVariable(ctx, ctx.Doer.ID, 0)
+}
+
+// UpdateVariable updates an actions variable of the authenticated user
+func UpdateVariable(ctx *context.APIContext) {
+	// swagger:operation PUT /user/actions/variables/{variablename} user userUpdateActionsVariable
+	// ---
+	// summary: Update an actions variable of the authenticated user
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: variablename
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/UpdateVariableOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionVariable"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+
+	utils.UpdateVariable(ctx, ctx.Doer.ID, 0)
+}
+
+// DeleteVariable deletes an actions variable of the authenticated user
+func DeleteVariable(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/actions/variables/{variablename} user userDeleteActionsVariable
+	// ---
+	// summary: Delete an actions variable of the authenticated user
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: variablename
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteVariable(ctx, ctx.Doer.ID, 0)
+}
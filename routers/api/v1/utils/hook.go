@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"net/http"
 	"strings"
+	"time"
 
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
@@ -170,12 +171,13 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, ownerID, repoI
 		form.Events = []string{"push"}
 	}
 	w := &webhook.Webhook{
-		OwnerID:     ownerID,
-		RepoID:      repoID,
-		URL:         form.Config["url"],
-		ContentType: webhook.ToHookContentType(form.Config["content_type"]),
-		Secret:      form.Config["secret"],
-		HTTPMethod:  "POST",
+		OwnerID:       ownerID,
+		RepoID:        repoID,
+		URL:           form.Config["url"],
+		ContentType:   webhook.ToHookContentType(form.Config["content_type"]),
+		Secret:        form.Config["secret"],
+		HTTPMethod:    "POST",
+		HTTPSignature: form.Config["http_signature"] == "true",
 		HookEvent: &webhook_module.HookEvent{
 			ChooseEvents: true,
 			HookEvents: webhook_module.HookEvents{
@@ -235,6 +237,14 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, ownerID, repoI
 		}
 		w.Meta = string(meta)
 	}
+	if w.Type == webhook_module.CUSTOM {
+		if method, ok := form.Config["http_method"]; ok {
+			w.HTTPMethod = strings.ToUpper(method)
+		}
+		if !setCustomHookMeta(ctx, form.Config, w, &webhook_service.CustomMeta{}) {
+			return nil, false
+		}
+	}
 
 	if err := w.UpdateEvent(); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateEvent", err)
@@ -246,6 +256,36 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, ownerID, repoI
 	return w, true
 }
 
+// setCustomHookMeta applies the custom webhook options of `config` on top of `meta` and stores
+// the result in `w`. If the options are invalid, write to `ctx` accordingly. Return whether successful
+func setCustomHookMeta(ctx *context.APIContext, config map[string]string, w *webhook.Webhook, meta *webhook_service.CustomMeta) bool {
+	if w.HTTPMethod != http.MethodPost && w.HTTPMethod != http.MethodPut && w.HTTPMethod != http.MethodPatch {
+		ctx.Error(http.StatusUnprocessableEntity, "", "Invalid http method")
+		return false
+	}
+	if contentType, ok := config["custom_content_type"]; ok {
+		meta.ContentType = contentType
+	}
+	if headers, ok := config["headers_template"]; ok {
+		meta.HeadersTemplate = headers
+	}
+	if body, ok := config["body_template"]; ok {
+		meta.BodyTemplate = body
+	}
+	if err := webhook_service.ValidateCustomMeta(meta); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err.Error())
+		return false
+	}
+
+	data, err := json.Marshal(meta)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "custom: JSON marshal failed", err)
+		return false
+	}
+	w.Meta = string(data)
+	return true
+}
+
 // EditSystemHook edit system webhook `w` according to `form`. Writes to `ctx` accordingly
 func EditSystemHook(ctx *context.APIContext, form *api.EditHookOption, hookID int64) {
 	hook, err := webhook.GetSystemOrDefaultWebhook(ctx, hookID)
@@ -318,6 +358,9 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 		if url, ok := form.Config["url"]; ok {
 			w.URL = url
 		}
+		if httpSignature, ok := form.Config["http_signature"]; ok {
+			w.HTTPSignature = httpSignature == "true"
+		}
 		if ct, ok := form.Config["content_type"]; ok {
 			if !webhook.IsValidHookContentType(ct) {
 				ctx.Error(http.StatusUnprocessableEntity, "", "Invalid content type")
@@ -341,6 +384,15 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 				w.Meta = string(meta)
 			}
 		}
+
+		if w.Type == webhook_module.CUSTOM {
+			if method, ok := form.Config["http_method"]; ok {
+				w.HTTPMethod = strings.ToUpper(method)
+			}
+			if !setCustomHookMeta(ctx, form.Config, w, webhook_service.GetCustomHook(w)) {
+				return false
+			}
+		}
 	}
 
 	// Update events
@@ -366,6 +418,20 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 		return false
 	}
 
+	if form.SecretRotationHours < 0 || form.SecretRotationHours > webhook.MaxSecretRotationHours {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("secret_rotation_hours must be between 0 and %d", webhook.MaxSecretRotationHours))
+		return false
+	}
+	if form.RevokePreviousSecrets {
+		w.RevokePreviousSecrets()
+	}
+	if secret, ok := form.Config["secret"]; ok {
+		if err := w.RotateSecret(secret, time.Duration(form.SecretRotationHours)*time.Hour); err != nil {
+			ctx.Error(http.StatusInternalServerError, "RotateSecret", err)
+			return false
+		}
+	}
+
 	// Issues
 	w.Issues = issuesHook(form.Events, "issues_only")
 	w.IssueAssign = issuesHook(form.Events, string(webhook_module.HookEventIssueAssign))
@@ -389,6 +455,10 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 	}
 
 	if form.Active != nil {
+		if *form.Active && !w.IsActive {
+			// give a webhook activated again a fresh start
+			w.FailureCount = 0
+		}
 		w.IsActive = *form.Active
 	}
 
@@ -0,0 +1,116 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package utils
+
+import (
+	"errors"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/convert"
+)
+
+func writeVariableError(ctx *context.APIContext, name string, err error) {
+	switch {
+	case errors.Is(err, util.ErrInvalidArgument):
+		ctx.Error(http.StatusBadRequest, name, err)
+	case errors.Is(err, util.ErrAlreadyExist):
+		ctx.Error(http.StatusConflict, name, err)
+	case errors.Is(err, util.ErrNotExist):
+		ctx.NotFound(err)
+	default:
+		ctx.Error(http.StatusInternalServerError, name, err)
+	}
+}
+
+// ListVariables lists the actions variables of a user/organization (ownerID) or a repository (repoID)
+func ListVariables(ctx *context.APIContext, ownerID, repoID int64) {
+	opts := actions_model.FindVariablesOpts{
+		ListOptions: GetListOptions(ctx),
+		OwnerID:     ownerID,
+		RepoID:      repoID,
+	}
+
+	count, err := actions_model.CountVariables(ctx, opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	variables, err := actions_model.FindVariables(ctx, opts)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	apiVariables := make([]*api.ActionVariable, len(variables))
+	for i, v := range variables {
+		apiVariables[i] = convert.ToActionVariable(v)
+	}
+
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiVariables)
+}
+
+// GetVariable responds with the actions variable given by the "variablename" path parameter
+func GetVariable(ctx *context.APIContext, ownerID, repoID int64) {
+	v, err := actions_model.GetVariable(ctx, ownerID, repoID, ctx.Params("variablename"))
+	if err != nil {
+		writeVariableError(ctx, "GetVariable", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionVariable(v))
+}
+
+// CreateVariable creates the actions variable given by the "variablename" path parameter
+func CreateVariable(ctx *context.APIContext, ownerID, repoID int64) {
+	opt := web.GetForm(ctx).(*api.CreateVariableOption)
+
+	v, err := actions_model.InsertVariable(ctx, ownerID, repoID, ctx.Params("variablename"), opt.Value)
+	if err != nil {
+		writeVariableError(ctx, "InsertVariable", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToActionVariable(v))
+}
+
+// UpdateVariable updates the actions variable given by the "variablename" path parameter
+func UpdateVariable(ctx *context.APIContext, ownerID, repoID int64) {
+	opt := web.GetForm(ctx).(*api.UpdateVariableOption)
+
+	v, err := actions_model.GetVariable(ctx, ownerID, repoID, ctx.Params("variablename"))
+	if err != nil {
+		writeVariableError(ctx, "GetVariable", err)
+		return
+	}
+
+	if opt.Name != "" {
+		v.Name = opt.Name
+	}
+	v.Data = opt.Value
+	if err := actions_model.UpdateVariable(ctx, v); err != nil {
+		writeVariableError(ctx, "UpdateVariable", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionVariable(v))
+}
+
+// DeleteVariable deletes the actions variable given by the "variablename" path parameter
+func DeleteVariable(ctx *context.APIContext, ownerID, repoID int64) {
+	v, err := actions_model.GetVariable(ctx, ownerID, repoID, ctx.Params("variablename"))
+	if err != nil {
+		writeVariableError(ctx, "GetVariable", err)
+		return
+	}
+
+	if err := actions_model.DeleteVariable(ctx, ownerID, repoID, v.ID); err != nil {
+		writeVariableError(ctx, "DeleteVariable", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
@@ -42,6 +42,9 @@ type preReceiveContext struct {
 	protectedTags    []*git_model.ProtectedTag
 	gotProtectedTags bool
 
+	pushRules    []*git_model.PushRule
+	gotPushRules bool
+
 	env []string
 
 	opts *private.HookOptions
@@ -130,6 +133,11 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		if ctx.Written() {
 			return
 		}
+
+		preReceivePushRules(ourCtx, oldCommitID, newCommitID, refFullName)
+		if ctx.Written() {
+			return
+		}
 	}
 
 	ctx.PlainText(http.StatusOK, "ok")
@@ -221,9 +229,28 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID string, r
 		}
 	}
 
+	// 4. Enforce require linear history
+	if protectBranch.RequireLinearHistory {
+		mergeCommit, err := findMergeCommit(oldCommitID, newCommitID, gitRepo, ctx.env)
+		if err != nil {
+			log.Error("Unable to check merge commits from %s to %s in %-v: %v", oldCommitID, newCommitID, repo, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: fmt.Sprintf("Unable to check merge commits from %s to %s: %v", oldCommitID, newCommitID, err),
+			})
+			return
+		}
+		if mergeCommit != "" {
+			log.Warn("Forbidden: Branch: %s in %-v requires linear history but merge commit %s is pushed", branchName, repo, mergeCommit)
+			ctx.JSON(http.StatusForbidden, private.Response{
+				UserMsg: fmt.Sprintf("branch %s requires linear history, merge commit %s is not allowed", branchName, mergeCommit),
+			})
+			return
+		}
+	}
+
 	// Now there are several tests which can be overridden:
 	//
-	// 4. Check protected file patterns - this is overridable from the UI
+	// 5. Check protected file patterns - this is overridable from the UI
 	changedProtectedfiles := false
 	protectedFilePath := ""
 
@@ -244,7 +271,7 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID string, r
 		}
 	}
 
-	// 5. Check if the doer is allowed to push
+	// 6. Check if the doer is allowed to push
 	var canPush bool
 	if ctx.opts.DeployKeyID != 0 {
 		canPush = !changedProtectedfiles && protectBranch.CanPush && (!protectBranch.EnableWhitelist || protectBranch.WhitelistDeployKeys)
@@ -260,11 +287,11 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID string, r
 		canPush = !changedProtectedfiles && protectBranch.CanUserPush(ctx, user)
 	}
 
-	// 6. If we're not allowed to push directly
+	// 7. If we're not allowed to push directly
 	if !canPush {
 		// Is this is a merge from the UI/API?
 		if ctx.opts.PullRequestID == 0 {
-			// 6a. If we're not merging from the UI/API then there are two ways we got here:
+			// 7a. If we're not merging from the UI/API then there are two ways we got here:
 			//
 			// We are changing a protected file and we're not allowed to do that
 			if changedProtectedfiles {
@@ -299,7 +326,7 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID string, r
 			})
 			return
 		}
-		// 6b. Merge (from UI or API)
+		// 7b. Merge (from UI or API)
 
 		// Get the PR, user and permissions for the user in the repository
 		pr, err := issues_model.GetPullRequestByID(ctx, ctx.opts.PullRequestID)
@@ -0,0 +1,242 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/private"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// errPushRuleViolation is returned when a pushed commit doesn't follow a push rule, the message is shown to the pusher
+type errPushRuleViolation struct {
+	message string
+}
+
+func (err *errPushRuleViolation) Error() string {
+	return err.message
+}
+
+// loadPushRules loads the push rules of the repository and of its owner
+func (ctx *preReceiveContext) loadPushRules() bool {
+	if ctx.gotPushRules {
+		return true
+	}
+	var err error
+	ctx.pushRules, err = git_model.GetPushRulesForRepo(ctx, ctx.Repo.Repository)
+	if err != nil {
+		log.Error("Unable to get push rules for %-v Error: %v", ctx.Repo.Repository, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return false
+	}
+	ctx.gotPushRules = true
+	return true
+}
+
+// preReceivePushRules checks that the commits pushed to the ref follow the push rules
+func preReceivePushRules(ctx *preReceiveContext, oldCommitID, newCommitID string, refFullName git.RefName) {
+	if newCommitID == git.EmptySHA {
+		// nothing is pushed when a ref is deleted
+		return
+	}
+	if ctx.opts.PullRequestID != 0 || ctx.opts.IsGiteaPush {
+		// the commits of merged pull requests, the web editor and the suggestions are made by Gitea, not pushed by a user
+		return
+	}
+	if refFullName.IsBranch() {
+		if _, ok := pull_service.MergeQueueBaseBranch(refFullName.BranchName()); ok {
+			// the commits of the merge queue branches have already been checked when they were pushed to the pull requests
+			return
+		}
+	}
+	if !ctx.loadPushRules() || len(ctx.pushRules) == 0 {
+		return
+	}
+
+	repo := ctx.Repo.Repository
+	if err := checkPushRules(ctx, ctx.pushRules, newCommitID, repo.RepoPath(), ctx.env); err != nil {
+		var violation *errPushRuleViolation
+		if errors.As(err, &violation) {
+			log.Warn("Forbidden: %s in %-v violates the push rules: %s", refFullName, repo, violation.message)
+			ctx.JSON(http.StatusForbidden, private.Response{
+				UserMsg: fmt.Sprintf("push rules violated on %s: %s", refFullName.ShortName(), violation.message),
+			})
+			return
+		}
+		log.Error("Unable to check push rules for commits from %s to %s in %-v: %v", oldCommitID, newCommitID, repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: fmt.Sprintf("Unable to check push rules for commits from %s to %s: %v", oldCommitID, newCommitID, err),
+		})
+	}
+}
+
+// addPushedRevisions adds the arguments selecting the commits pushed with newCommitID, they are the ones which are
+// not reachable from the existing refs. It's also correct for force pushes and for commits which are already
+// in another branch, the objects of the push are only referenced after the pre-receive hook succeeded.
+func addPushedRevisions(cmd *git.Command, newCommitID string) *git.Command {
+	return cmd.AddDynamicArguments(newCommitID).AddArguments("--not", "--all")
+}
+
+// checkPushRules returns an errPushRuleViolation if a pushed commit doesn't follow one of the rules
+func checkPushRules(ctx context.Context, rules []*git_model.PushRule, newCommitID, repoPath string, env []string) error {
+	checkCommits, checkFiles := false, false
+	for _, rule := range rules {
+		checkCommits = checkCommits || rule.CommitMessagePattern != "" || rule.AllowedEmailDomains != ""
+		checkFiles = checkFiles || rule.MaxFileSize > 0 || rule.ForbiddenFilePatterns != ""
+	}
+
+	if checkCommits {
+		if err := checkPushedCommits(ctx, rules, newCommitID, repoPath, env); err != nil {
+			return err
+		}
+	}
+	if checkFiles {
+		return checkPushedFiles(ctx, rules, newCommitID, repoPath, env)
+	}
+	return nil
+}
+
+// pushedCommit is a commit parsed from the output of git log by parsePushedCommits
+type pushedCommit struct {
+	ID             string
+	AuthorEmail    string
+	CommitterEmail string
+	Message        string
+}
+
+// pushedCommitsFormat is the git log format of the commits parsed by parsePushedCommits,
+// every commit is made of 4 NUL terminated fields: the commit ID, the author email, the committer email and the message
+const pushedCommitsFormat = "--format=%H%x00%ae%x00%ce%x00%B"
+
+// parsePushedCommits parses the output of git log -z with pushedCommitsFormat
+func parsePushedCommits(stdout string) []*pushedCommit {
+	var commits []*pushedCommit
+	fields := strings.Split(stdout, "\x00")
+	for i := 0; i+3 < len(fields); i += 4 {
+		commits = append(commits, &pushedCommit{
+			ID:             strings.TrimSpace(fields[i]),
+			AuthorEmail:    fields[i+1],
+			CommitterEmail: fields[i+2],
+			Message:        strings.TrimSpace(fields[i+3]),
+		})
+	}
+	return commits
+}
+
+func checkPushedCommits(ctx context.Context, rules []*git_model.PushRule, newCommitID, repoPath string, env []string) error {
+	cmd := addPushedRevisions(git.NewCommand(ctx, "log", "-z", pushedCommitsFormat), newCommitID)
+	stdout, _, err := cmd.RunStdString(&git.RunOpts{Dir: repoPath, Env: env})
+	if err != nil {
+		return err
+	}
+
+	for _, commit := range parsePushedCommits(stdout) {
+		for _, rule := range rules {
+			if !rule.IsCommitMessageAllowed(commit.Message) {
+				return &errPushRuleViolation{message: fmt.Sprintf("the message of commit %s doesn't match the pattern %q", base.ShortSha(commit.ID), rule.CommitMessagePattern)}
+			}
+			if !rule.IsEmailAllowed(commit.AuthorEmail) {
+				return &errPushRuleViolation{message: fmt.Sprintf("the author email %s of commit %s isn't in the allowed domains %q", commit.AuthorEmail, base.ShortSha(commit.ID), rule.AllowedEmailDomains)}
+			}
+			if !rule.IsEmailAllowed(commit.CommitterEmail) {
+				return &errPushRuleViolation{message: fmt.Sprintf("the committer email %s of commit %s isn't in the allowed domains %q", commit.CommitterEmail, base.ShortSha(commit.ID), rule.AllowedEmailDomains)}
+			}
+		}
+	}
+	return nil
+}
+
+// pushedFile is an added or modified file parsed from the output of git log --raw by parsePushedFiles
+type pushedFile struct {
+	Path   string
+	BlobID string
+}
+
+// parsePushedFiles parses the output of git log -z --raw --no-abbrev, every added or modified file is made of
+// 2 NUL terminated fields: ":<old mode> <new mode> <old blob> <new blob> <status>" and the path
+func parsePushedFiles(stdout string) []*pushedFile {
+	var files []*pushedFile
+	fields := strings.Split(stdout, "\x00")
+	for i := 0; i+1 < len(fields); i++ {
+		if !strings.HasPrefix(strings.TrimSpace(fields[i]), ":") {
+			continue
+		}
+		meta, path := strings.Fields(strings.TrimSpace(fields[i])), fields[i+1]
+		i++
+		if len(meta) != 5 || meta[1] == "160000" {
+			// submodules are not files of the repository
+			continue
+		}
+		files = append(files, &pushedFile{Path: path, BlobID: meta[3]})
+	}
+	return files
+}
+
+func checkPushedFiles(ctx context.Context, rules []*git_model.PushRule, newCommitID, repoPath string, env []string) error {
+	cmd := addPushedRevisions(git.NewCommand(ctx, "log", "-z", "--format=", "--raw", "--no-renames", "--no-abbrev", "--diff-filter=d"), newCommitID)
+	stdout, _, err := cmd.RunStdString(&git.RunOpts{Dir: repoPath, Env: env})
+	if err != nil {
+		return err
+	}
+
+	blobPaths := make(map[string]string)
+	var blobIDs []string
+	for _, file := range parsePushedFiles(stdout) {
+		for _, rule := range rules {
+			if rule.IsForbiddenFile(file.Path) {
+				return &errPushRuleViolation{message: fmt.Sprintf("the file %s matches the forbidden file patterns %q", file.Path, rule.ForbiddenFilePatterns)}
+			}
+		}
+		if _, ok := blobPaths[file.BlobID]; !ok {
+			blobPaths[file.BlobID] = file.Path
+			blobIDs = append(blobIDs, file.BlobID)
+		}
+	}
+
+	hasFileSizeLimit := false
+	for _, rule := range rules {
+		hasFileSizeLimit = hasFileSizeLimit || rule.MaxFileSize > 0
+	}
+	if !hasFileSizeLimit || len(blobIDs) == 0 {
+		return nil
+	}
+
+	stdout, _, err = git.NewCommand(ctx, "cat-file", "--batch-check").RunStdString(&git.RunOpts{
+		Dir:   repoPath,
+		Env:   env,
+		Stdin: strings.NewReader(strings.Join(blobIDs, "\n") + "\n"),
+	})
+	if err != nil {
+		return err
+	}
+	for _, line := range strings.Split(strings.TrimSpace(stdout), "\n") {
+		// <blob> <type> <size>
+		fields := strings.Fields(line)
+		if len(fields) != 3 {
+			continue
+		}
+		size, err := strconv.ParseInt(fields[2], 10, 64)
+		if err != nil {
+			return fmt.Errorf("unable to parse the size of %s: %w", fields[0], err)
+		}
+		for _, rule := range rules {
+			if !rule.IsFileSizeAllowed(size) {
+				return &errPushRuleViolation{message: fmt.Sprintf("the file %s (%s) is larger than the limit of %s", blobPaths[fields[0]], base.FileSize(size), base.FileSize(rule.MaxFileSize))}
+			}
+		}
+	}
+	return nil
+}
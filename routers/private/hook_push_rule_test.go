@@ -0,0 +1,156 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package private
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParsePushedCommits(t *testing.T) {
+	stdout := "1111111111111111111111111111111111111111\x00author@example.com\x00committer@example.com\x00feat: first\n\nbody\n\x00" +
+		"2222222222222222222222222222222222222222\x00a@example.org\x00c@example.org\x00fix: second\n"
+	assert.Equal(t, []*pushedCommit{
+		{
+			ID:             "1111111111111111111111111111111111111111",
+			AuthorEmail:    "author@example.com",
+			CommitterEmail: "committer@example.com",
+			Message:        "feat: first\n\nbody",
+		},
+		{
+			ID:             "2222222222222222222222222222222222222222",
+			AuthorEmail:    "a@example.org",
+			CommitterEmail: "c@example.org",
+			Message:        "fix: second",
+		},
+	}, parsePushedCommits(stdout))
+	assert.Empty(t, parsePushedCommits(""))
+}
+
+func TestParsePushedFiles(t *testing.T) {
+	stdout := ":000000 100644 0000000000000000000000000000000000000000 1111111111111111111111111111111111111111 A\x00docs/new file.md\x00" +
+		":100644 100644 2222222222222222222222222222222222222222 3333333333333333333333333333333333333333 M\x00README.md\x00" +
+		":000000 160000 0000000000000000000000000000000000000000 4444444444444444444444444444444444444444 A\x00vendor/module\x00" +
+		"\x00:100644 100755 3333333333333333333333333333333333333333 5555555555555555555555555555555555555555 M\x00build.sh\x00"
+	assert.Equal(t, []*pushedFile{
+		{Path: "docs/new file.md", BlobID: "1111111111111111111111111111111111111111"},
+		{Path: "README.md", BlobID: "3333333333333333333333333333333333333333"},
+		{Path: "build.sh", BlobID: "5555555555555555555555555555555555555555"},
+	}, parsePushedFiles(stdout))
+}
+
+// pushRuleTestRepo creates commits which aren't referenced like the ones received by the pre-receive hook
+type pushRuleTestRepo struct {
+	t    *testing.T
+	path string
+}
+
+func newPushRuleTestRepo(t *testing.T) *pushRuleTestRepo {
+	repo := &pushRuleTestRepo{t: t, path: t.TempDir()}
+	assert.NoError(t, git.InitRepository(git.DefaultContext, repo.path, true))
+	return repo
+}
+
+func (r *pushRuleTestRepo) run(stdin string, env []string, cmd *git.Command) string {
+	stdout, _, err := cmd.RunStdString(&git.RunOpts{
+		Dir:   r.path,
+		Env:   append(os.Environ(), env...),
+		Stdin: strings.NewReader(stdin),
+	})
+	assert.NoError(r.t, err)
+	return strings.TrimSpace(stdout)
+}
+
+// commit creates a commit with the files, the file contents are mapped by their names
+func (r *pushRuleTestRepo) commit(parent, email, message string, files map[string]string) string {
+	var tree strings.Builder
+	for name, content := range files {
+		blobID := r.run(content, nil, git.NewCommand(git.DefaultContext, "hash-object", "-w", "--stdin"))
+		fmt.Fprintf(&tree, "100644 blob %s\t%s\n", blobID, name)
+	}
+	treeID := r.run(tree.String(), nil, git.NewCommand(git.DefaultContext, "mktree"))
+
+	cmd := git.NewCommand(git.DefaultContext, "commit-tree").AddDynamicArguments(treeID).AddOptionValues("-m", message)
+	if parent != "" {
+		cmd.AddOptionValues("-p", parent)
+	}
+	return r.run("", []string{
+		"GIT_AUTHOR_NAME=author",
+		"GIT_AUTHOR_EMAIL=" + email,
+		"GIT_COMMITTER_NAME=committer",
+		"GIT_COMMITTER_EMAIL=" + email,
+	}, cmd)
+}
+
+func (r *pushRuleTestRepo) updateRef(ref, commitID string) {
+	r.run("", nil, git.NewCommand(git.DefaultContext, "update-ref").AddDynamicArguments(ref, commitID))
+}
+
+func TestCheckPushRules(t *testing.T) {
+	repo := newPushRuleTestRepo(t)
+	base := repo.commit("", "user@example.org", "chore: bad message of an existing commit", map[string]string{"README.md": "readme"})
+	repo.updateRef("refs/heads/main", base)
+
+	check := func(rule *git_model.PushRule, newCommitID string) error {
+		return checkPushRules(git.DefaultContext, []*git_model.PushRule{rule}, newCommitID, repo.path, nil)
+	}
+	assertViolation := func(t *testing.T, err error, message string) {
+		var violation *errPushRuleViolation
+		if assert.ErrorAs(t, err, &violation) {
+			assert.Contains(t, violation.message, message)
+		}
+	}
+
+	t.Run("CommitMessage", func(t *testing.T) {
+		rule := &git_model.PushRule{CommitMessagePattern: `^(feat|fix): `}
+
+		good := repo.commit(base, "user@example.org", "feat: add docs", map[string]string{"README.md": "readme", "docs.md": "docs"})
+		// only the new commits are checked, not the existing base
+		assert.NoError(t, check(rule, good))
+
+		bad := repo.commit(good, "user@example.org", "wip", map[string]string{"README.md": "readme"})
+		assertViolation(t, check(rule, bad), "message of commit "+bad[:10])
+
+		// the commit is already in a branch, so pushing it to another branch doesn't push anything
+		repo.updateRef("refs/heads/wip", bad)
+		assert.NoError(t, check(rule, bad))
+	})
+
+	t.Run("Email", func(t *testing.T) {
+		rule := &git_model.PushRule{AllowedEmailDomains: "example.org"}
+
+		assert.NoError(t, check(rule, repo.commit(base, "user@example.org", "ok", nil)))
+		assertViolation(t, check(rule, repo.commit(base, "user@example.com", "not ok", nil)), "the author email user@example.com")
+	})
+
+	t.Run("ForbiddenFiles", func(t *testing.T) {
+		rule := &git_model.PushRule{ForbiddenFilePatterns: "*.pem"}
+
+		assert.NoError(t, check(rule, repo.commit(base, "user@example.org", "ok", map[string]string{"cert.txt": "cert"})))
+		assertViolation(t, check(rule, repo.commit(base, "user@example.org", "key", map[string]string{"key.pem": "key"})), "the file key.pem")
+	})
+
+	t.Run("MaxFileSize", func(t *testing.T) {
+		rule := &git_model.PushRule{MaxFileSize: 10}
+
+		assert.NoError(t, check(rule, repo.commit(base, "user@example.org", "small", map[string]string{"small.txt": "small"})))
+		assertViolation(t, check(rule, repo.commit(base, "user@example.org", "large", map[string]string{"large.txt": strings.Repeat("x", 11)})), "the file large.txt")
+	})
+
+	t.Run("ForcePush", func(t *testing.T) {
+		rule := &git_model.PushRule{CommitMessagePattern: `^(feat|fix): `}
+
+		// a rewritten history is checked from the commits which aren't reachable from any ref
+		rewritten := repo.commit("", "user@example.org", "feat: rewrite", map[string]string{"README.md": "rewritten"})
+		assert.NoError(t, check(rule, rewritten))
+		assertViolation(t, check(rule, repo.commit(rewritten, "user@example.org", "rewrite again", nil)), "doesn't match the pattern")
+	})
+}
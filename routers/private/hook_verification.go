@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strings"
 
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/modules/git"
@@ -51,6 +52,22 @@ func verifyCommits(oldCommitID, newCommitID string, repo *git.Repository, env []
 	return err
 }
 
+// findMergeCommit returns the first merge commit pushed from oldCommitID to newCommitID, or an empty string if there is none
+func findMergeCommit(oldCommitID, newCommitID string, repo *git.Repository, env []string) (string, error) {
+	cmd := git.NewCommand(repo.Ctx, "rev-list", "--min-parents=2", "--max-count=1")
+	if oldCommitID == git.EmptySHA {
+		// the branch is created, only the commits which are not reachable from the existing refs are pushed
+		cmd.AddDynamicArguments(newCommitID).AddArguments("--not", "--all")
+	} else {
+		cmd.AddDynamicArguments(oldCommitID + ".." + newCommitID)
+	}
+	stdout, _, err := cmd.RunStdString(&git.RunOpts{Dir: repo.Path, Env: env})
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(stdout), nil
+}
+
 func readAndVerifyCommitsFromShaReader(input io.ReadCloser, repo *git.Repository, env []string) error {
 	scanner := bufio.NewScanner(input)
 	for scanner.Scan() {
@@ -4,20 +4,30 @@
 package admin
 
 import (
+	"errors"
+	"math"
 	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 
 	"code.gitea.io/gitea/models/db"
 	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
 	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/dustin/go-humanize"
 )
 
 const (
-	tplPackagesList base.TplName = "admin/packages/list"
+	tplPackagesList   base.TplName = "admin/packages/list"
+	tplPackagesQuotas base.TplName = "admin/packages/quotas"
 )
 
 // Packages shows all packages
@@ -101,3 +111,138 @@ func DeletePackageVersion(ctx *context.Context) {
 		"redirect": setting.AppSubURL + "/admin/packages?page=" + url.QueryEscape(ctx.FormString("page")) + "&q=" + url.QueryEscape(ctx.FormString("q")) + "&type=" + url.QueryEscape(ctx.FormString("type")),
 	})
 }
+
+// PackageQuotas shows the per-owner package quotas
+func PackageQuotas(ctx *context.Context) {
+	pqs, err := packages_model.GetQuotas(ctx)
+	if err != nil {
+		ctx.ServerError("GetQuotas", err)
+		return
+	}
+
+	type quotaInfo struct {
+		Quota *packages_model.PackageQuota
+		Owner *user_model.User
+		Usage *packages_service.OwnerUsage
+	}
+
+	quotas := make([]*quotaInfo, 0, len(pqs))
+	for _, pq := range pqs {
+		owner, err := user_model.GetPossibleUserByID(ctx, pq.OwnerID)
+		if err != nil {
+			ctx.ServerError("GetPossibleUserByID", err)
+			return
+		}
+
+		usage, err := packages_service.GetOwnerUsage(ctx, pq.OwnerID)
+		if err != nil {
+			ctx.ServerError("GetOwnerUsage", err)
+			return
+		}
+
+		quotas = append(quotas, &quotaInfo{
+			Quota: pq,
+			Owner: owner,
+			Usage: usage,
+		})
+	}
+
+	ctx.Data["Title"] = ctx.Tr("admin.packages.quotas")
+	ctx.Data["PageIsAdminPackages"] = true
+	ctx.Data["Quotas"] = quotas
+	ctx.Data["DefaultLimitTotalSize"] = setting.Packages.LimitTotalOwnerSize
+	ctx.Data["DefaultLimitTotalCount"] = setting.Packages.LimitTotalOwnerCount
+
+	ctx.HTML(http.StatusOK, tplPackagesQuotas)
+}
+
+// PackageQuotaPost creates or updates the quota of an owner
+func PackageQuotaPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.PackageQuotaForm)
+
+	redirectURL := setting.AppSubURL + "/admin/packages/quotas"
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	owner, err := user_model.GetUserByName(ctx, form.Owner)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("form.user_not_exist"))
+			ctx.Redirect(redirectURL)
+			return
+		}
+		ctx.ServerError("GetUserByName", err)
+		return
+	}
+
+	limitTotalSize, err := parseQuotaLimit(form.LimitTotalSize, func(value string) (int64, error) {
+		bytes, err := humanize.ParseBytes(value)
+		if err != nil || bytes > math.MaxInt64 {
+			return 0, errors.New("invalid size")
+		}
+		return int64(bytes), nil
+	})
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("admin.packages.quotas.invalid_size", form.LimitTotalSize))
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	limitTotalCount, err := parseQuotaLimit(form.LimitTotalCount, func(value string) (int64, error) {
+		return strconv.ParseInt(value, 10, 64)
+	})
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("admin.packages.quotas.invalid_count", form.LimitTotalCount))
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	if err := packages_model.SetQuota(ctx, &packages_model.PackageQuota{
+		OwnerID:         owner.ID,
+		LimitTotalSize:  limitTotalSize,
+		LimitTotalCount: limitTotalCount,
+	}); err != nil {
+		ctx.ServerError("SetQuota", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.packages.quotas.success.update", owner.Name))
+	ctx.Redirect(redirectURL)
+}
+
+// parseQuotaLimit parses a limit value. An empty value uses the global setting, -1 means unlimited.
+func parseQuotaLimit(value string, parse func(string) (int64, error)) (*int64, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	limit := int64(-1)
+	if value != "-1" {
+		var err error
+		if limit, err = parse(value); err != nil {
+			return nil, err
+		}
+		if limit < 0 {
+			return nil, errors.New("negative limit")
+		}
+	}
+	return &limit, nil
+}
+
+// DeletePackageQuota removes the quota of an owner
+func DeletePackageQuota(ctx *context.Context) {
+	if err := packages_model.DeleteQuotaByID(ctx, ctx.FormInt64("id")); err != nil {
+		ctx.ServerError("DeleteQuotaByID", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.packages.quotas.success.delete"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/packages/quotas",
+	})
+}
@@ -4,14 +4,19 @@
 package admin
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 )
 
+// queueItemsPageSize is the number of waiting items and of dead letters shown on the page of a queue
+const queueItemsPageSize = 50
+
 func Queues(ctx *context.Context) {
 	if !setting.IsProd {
 		initTestQueueOnce()
@@ -33,6 +38,24 @@ func QueueManage(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("admin.monitor.queue", mq.GetName())
 	ctx.Data["PageIsAdminMonitor"] = true
 	ctx.Data["Queue"] = mq
+
+	if mq.CanInspectItems() {
+		items, itemsTotal, err := mq.ListItems(ctx, 0, queueItemsPageSize)
+		if err != nil {
+			ctx.ServerError("ListItems", err)
+			return
+		}
+		deadLetters, deadLettersTotal, err := mq.ListDeadLetters(ctx, 0, queueItemsPageSize)
+		if err != nil {
+			ctx.ServerError("ListDeadLetters", err)
+			return
+		}
+		ctx.Data["ItemsTotal"] = itemsTotal
+		ctx.Data["Items"] = items
+		ctx.Data["DeadLettersTotal"] = deadLettersTotal
+		ctx.Data["DeadLetters"] = deadLetters
+		ctx.Data["ItemsPageSize"] = queueItemsPageSize
+	}
 	ctx.HTML(http.StatusOK, tplQueueManage)
 }
 
@@ -87,3 +110,71 @@ func QueueRemoveAllItems(ctx *context.Context) {
 	ctx.Flash.Success(ctx.Tr("admin.monitor.queue.settings.remove_all_items_done"))
 	ctx.Redirect(setting.AppSubURL + "/admin/monitor/queue/" + strconv.FormatInt(qid, 10))
 }
+
+// queueItemAction runs an action on an item of a queue (or of its dead letters) and redirects to the page of the queue
+func queueItemAction(ctx *context.Context, name, successMsg string, action func(mq queue.ManagedWorkerPoolQueue, id string) error) {
+	qid := ctx.ParamsInt64("qid")
+	mq := queue.GetManager().GetManagedQueue(qid)
+	if mq == nil {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	if err := action(mq, ctx.Params("id")); err != nil {
+		if !errors.Is(err, util.ErrNotExist) {
+			ctx.ServerError(name, err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("admin.monitor.queue.items.not_found"))
+	} else {
+		ctx.Flash.Success(ctx.Tr(successMsg))
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/monitor/queue/" + strconv.FormatInt(qid, 10))
+}
+
+// QueueRemoveItem removes an item waiting in a queue
+func QueueRemoveItem(ctx *context.Context) {
+	queueItemAction(ctx, "RemoveItem", "admin.monitor.queue.items.removed", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.RemoveItem(ctx, id)
+	})
+}
+
+// QueueMoveItemToDeadLetters moves an item waiting in a queue to the dead letters of the queue
+func QueueMoveItemToDeadLetters(ctx *context.Context) {
+	queueItemAction(ctx, "MoveItemToDeadLetters", "admin.monitor.queue.items.moved_to_dead_letters", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.MoveItemToDeadLetters(ctx, id)
+	})
+}
+
+// QueueRemoveDeadLetter removes an item from the dead letters of a queue
+func QueueRemoveDeadLetter(ctx *context.Context) {
+	queueItemAction(ctx, "RemoveDeadLetter", "admin.monitor.queue.items.removed", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.RemoveDeadLetter(ctx, id)
+	})
+}
+
+// QueueRequeueDeadLetter pushes an item of the dead letters of a queue back to the queue
+func QueueRequeueDeadLetter(ctx *context.Context) {
+	queueItemAction(ctx, "RequeueDeadLetter", "admin.monitor.queue.dead_letters.requeued", func(mq queue.ManagedWorkerPoolQueue, id string) error {
+		return mq.RequeueDeadLetter(ctx, id)
+	})
+}
+
+// QueueRequeueAllDeadLetters pushes all the items of the dead letters of a queue back to the queue
+func QueueRequeueAllDeadLetters(ctx *context.Context) {
+	qid := ctx.ParamsInt64("qid")
+	mq := queue.GetManager().GetManagedQueue(qid)
+	if mq == nil {
+		ctx.Status(http.StatusNotFound)
+		return
+	}
+
+	requeued, err := mq.RequeueAllDeadLetters(ctx)
+	if err != nil {
+		ctx.ServerError("RequeueAllDeadLetters", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.monitor.queue.dead_letters.requeued_all", requeued))
+	ctx.Redirect(setting.AppSubURL + "/admin/monitor/queue/" + strconv.FormatInt(qid, 10))
+}
@@ -4,6 +4,7 @@
 package explore
 
 import (
+	"errors"
 	"net/http"
 
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -11,6 +12,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 )
 
 const (
@@ -35,11 +37,13 @@ func Code(ctx *context.Context) {
 	keyword := ctx.FormTrim("q")
 
 	queryType := ctx.FormTrim("t")
-	isMatch := queryType == "match"
+	caseSensitive := ctx.FormBool("cs")
 
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Language"] = language
 	ctx.Data["queryType"] = queryType
+	ctx.Data["CaseSensitive"] = caseSensitive
+	ctx.Data["IsAdvancedSearchSupported"] = code_indexer.IsAdvancedSearchSupported()
 	ctx.Data["PageIsViewCode"] = true
 
 	if keyword == "" {
@@ -77,8 +81,18 @@ func Code(ctx *context.Context) {
 	)
 
 	if (len(repoIDs) > 0) || isAdmin {
-		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(ctx, repoIDs, language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-		if err != nil {
+		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(ctx, &code_indexer.SearchOptions{
+			RepoIDs:       repoIDs,
+			Keyword:       keyword,
+			Language:      language,
+			Mode:          code_indexer.ParseSearchMode(queryType),
+			CaseSensitive: caseSensitive,
+			Page:          page,
+			PageSize:      setting.UI.RepoSearchPagingNum,
+		})
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Data["SearchError"] = ctx.Tr("explore.code_search_invalid", err.Error())
+		} else if err != nil {
 			if code_indexer.IsAvailable() {
 				ctx.ServerError("SearchResults", err)
 				return
@@ -129,6 +143,9 @@ func Code(ctx *context.Context) {
 	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "l", "Language")
+	if caseSensitive {
+		pager.AddParamString("cs", "1")
+	}
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(http.StatusOK, tplExploreCode)
@@ -17,6 +17,7 @@ const (
 	tplSettingsPackages            base.TplName = "org/settings/packages"
 	tplSettingsPackagesRuleEdit    base.TplName = "org/settings/packages_cleanup_rules_edit"
 	tplSettingsPackagesRulePreview base.TplName = "org/settings/packages_cleanup_rules_preview"
+	tplSettingsPackagesRemoteEdit  base.TplName = "org/settings/packages_remotes_edit"
 )
 
 func Packages(ctx *context.Context) {
@@ -85,6 +86,52 @@ func PackagesRulePreview(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplSettingsPackagesRulePreview)
 }
 
+func PackagesRemoteAdd(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.SetRemoteAddContext(ctx)
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRemoteEdit)
+}
+
+func PackagesRemoteEdit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.SetRemoteEditContext(ctx, ctx.ContextUser)
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRemoteEdit)
+}
+
+func PackagesRemoteAddPost(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.PerformRemoteAddPost(
+		ctx,
+		ctx.ContextUser,
+		fmt.Sprintf("%s/org/%s/settings/packages", setting.AppSubURL, ctx.ContextUser.Name),
+		tplSettingsPackagesRemoteEdit,
+	)
+}
+
+func PackagesRemoteEditPost(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.PerformRemoteEditPost(
+		ctx,
+		ctx.ContextUser,
+		fmt.Sprintf("%s/org/%s/settings/packages", setting.AppSubURL, ctx.ContextUser.Name),
+		tplSettingsPackagesRemoteEdit,
+	)
+}
+
 func InitializeCargoIndex(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("packages.title")
 	ctx.Data["PageIsOrgSettings"] = true
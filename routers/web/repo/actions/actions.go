@@ -5,7 +5,11 @@ package actions
 
 import (
 	"bytes"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
+	"strings"
 
 	actions_model "code.gitea.io/gitea/models/actions"
 	"code.gitea.io/gitea/models/db"
@@ -16,6 +20,8 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	actions_service "code.gitea.io/gitea/services/actions"
 	"code.gitea.io/gitea/services/convert"
 
 	"github.com/nektos/act/pkg/model"
@@ -55,6 +61,9 @@ func List(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("actions.actions")
 	ctx.Data["PageIsActions"] = true
 
+	curWorkflow := ctx.FormString("workflow")
+	ctx.Data["CurWorkflow"] = curWorkflow
+
 	var workflows []Workflow
 	if empty, err := ctx.Repo.GitRepo.IsEmpty(); err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
@@ -100,6 +109,14 @@ func List(ctx *context.Context) {
 				workflows = append(workflows, workflow)
 				continue
 			}
+			if entry.Name() == curWorkflow && ctx.Repo.CanWrite(unit.TypeActions) {
+				// only users who can write actions are allowed to run workflows manually
+				if dispatch, err := actions.GetWorkflowDispatchFromContent(content); err != nil {
+					workflow.ErrMsg = ctx.Locale.Tr("actions.runs.invalid_workflow_helper", err.Error())
+				} else {
+					ctx.Data["WorkflowDispatch"] = dispatch
+				}
+			}
 			// Check whether have matching runner
 			for _, j := range wf.Jobs {
 				runsOnList := j.RunsOn()
@@ -124,16 +141,13 @@ func List(ctx *context.Context) {
 		page = 1
 	}
 
-	workflow := ctx.FormString("workflow")
-	ctx.Data["CurWorkflow"] = workflow
-
 	opts := actions_model.FindRunOptions{
 		ListOptions: db.ListOptions{
 			Page:     page,
 			PageSize: convert.ToCorrectPageSize(ctx.FormInt("limit")),
 		},
 		RepoID:           ctx.Repo.Repository.ID,
-		WorkflowFileName: workflow,
+		WorkflowFileName: curWorkflow,
 	}
 
 	runs, total, err := actions_model.FindRuns(ctx, opts)
@@ -155,8 +169,39 @@ func List(ctx *context.Context) {
 
 	pager := context.NewPagination(int(total), opts.PageSize, opts.Page, 5)
 	pager.SetDefaultParams(ctx)
-	pager.AddParamString("workflow", workflow)
+	pager.AddParamString("workflow", curWorkflow)
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(http.StatusOK, tplListActions)
 }
+
+// Run creates a run of the workflow given by the "workflow" form value which has to support the `workflow_dispatch` event
+func Run(ctx *context.Context) {
+	workflowID := ctx.FormString("workflow")
+	redirectURL := fmt.Sprintf("%s/actions?workflow=%s", ctx.Repo.RepoLink, url.QueryEscape(workflowID))
+
+	inputs := make(map[string]string)
+	for key, values := range ctx.Req.PostForm {
+		// the values of the inputs are submitted as "inputs[<name>]",
+		// the last value wins which allows the form to send "false" for unchecked checkboxes
+		if name, ok := strings.CutPrefix(key, "inputs["); ok && strings.HasSuffix(name, "]") && len(values) > 0 {
+			inputs[strings.TrimSuffix(name, "]")] = values[len(values)-1]
+		}
+	}
+
+	run, err := actions_service.DispatchWorkflow(ctx, ctx.Doer, ctx.Repo.Repository, ctx.Repo.GitRepo, workflowID, ctx.FormString("ref"), inputs)
+	if err != nil {
+		switch {
+		case errors.Is(err, util.ErrInvalidArgument), errors.Is(err, util.ErrNotExist), errors.Is(err, util.ErrPermissionDenied):
+			ctx.Flash.Error(ctx.Tr("actions.workflow.run_failed", err.Error()))
+		default:
+			ctx.ServerError("DispatchWorkflow", err)
+			return
+		}
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("actions.workflow.run_success", workflowID))
+	ctx.Redirect(run.Link())
+}
@@ -15,9 +15,9 @@ import (
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/actions"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/container"
 	context_module "code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/storage"
-	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	actions_service "code.gitea.io/gitea/services/actions"
@@ -51,15 +51,16 @@ type ViewRequest struct {
 type ViewResponse struct {
 	State struct {
 		Run struct {
-			Link       string     `json:"link"`
-			Title      string     `json:"title"`
-			Status     string     `json:"status"`
-			CanCancel  bool       `json:"canCancel"`
-			CanApprove bool       `json:"canApprove"` // the run needs an approval and the doer has permission to approve
-			CanRerun   bool       `json:"canRerun"`
-			Done       bool       `json:"done"`
-			Jobs       []*ViewJob `json:"jobs"`
-			Commit     ViewCommit `json:"commit"`
+			Link           string     `json:"link"`
+			Title          string     `json:"title"`
+			Status         string     `json:"status"`
+			CanCancel      bool       `json:"canCancel"`
+			CanApprove     bool       `json:"canApprove"` // the run needs an approval and the doer has permission to approve
+			CanRerun       bool       `json:"canRerun"`
+			CanRerunFailed bool       `json:"canRerunFailed"` // the run has failed or cancelled jobs and the doer has permission to rerun them
+			Done           bool       `json:"done"`
+			Jobs           []*ViewJob `json:"jobs"`
+			Commit         ViewCommit `json:"commit"`
 		} `json:"run"`
 		CurrentJob struct {
 			Title  string         `json:"title"`
@@ -144,6 +145,9 @@ func ViewPost(ctx *context_module.Context) {
 	resp.State.Run.Jobs = make([]*ViewJob, 0, len(jobs)) // marshal to '[]' instead fo 'null' in json
 	resp.State.Run.Status = run.Status.String()
 	for _, v := range jobs {
+		if resp.State.Run.CanRerun && isFailedJob(v) {
+			resp.State.Run.CanRerunFailed = true
+		}
 		resp.State.Run.Jobs = append(resp.State.Run.Jobs, &ViewJob{
 			ID:       v.ID,
 			Name:     v.Name,
@@ -257,12 +261,12 @@ func RerunOne(ctx *context_module.Context) {
 	runIndex := ctx.ParamsInt64("run")
 	jobIndex := ctx.ParamsInt64("job")
 
-	job, _ := getRunJobs(ctx, runIndex, jobIndex)
+	job, jobs := getRunJobs(ctx, runIndex, jobIndex)
 	if ctx.Written() {
 		return
 	}
 
-	if err := rerunJob(ctx, job); err != nil {
+	if err := rerunJobs(ctx, jobs, []*actions_model.ActionRunJob{job}); err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
 		return
 	}
@@ -278,17 +282,85 @@ func RerunAll(ctx *context_module.Context) {
 		return
 	}
 
+	if err := rerunJobs(ctx, jobs, jobs); err != nil {
+		ctx.Error(http.StatusInternalServerError, err.Error())
+		return
+	}
+
+	ctx.JSON(http.StatusOK, struct{}{})
+}
+
+// RerunFailed reruns the failed and cancelled jobs of a run together with the jobs depending on them
+func RerunFailed(ctx *context_module.Context) {
+	runIndex := ctx.ParamsInt64("run")
+
+	_, jobs := getRunJobs(ctx, runIndex, 0)
+	if ctx.Written() {
+		return
+	}
+
+	failedJobs := make([]*actions_model.ActionRunJob, 0, len(jobs))
 	for _, j := range jobs {
-		if err := rerunJob(ctx, j); err != nil {
-			ctx.Error(http.StatusInternalServerError, err.Error())
-			return
+		if isFailedJob(j) {
+			failedJobs = append(failedJobs, j)
 		}
 	}
 
+	if err := rerunJobs(ctx, jobs, failedJobs); err != nil {
+		ctx.Error(http.StatusInternalServerError, err.Error())
+		return
+	}
+
 	ctx.JSON(http.StatusOK, struct{}{})
 }
 
-func rerunJob(ctx *context_module.Context, job *actions_model.ActionRunJob) error {
+func isFailedJob(job *actions_model.ActionRunJob) bool {
+	return job.Status == actions_model.StatusFailure || job.Status == actions_model.StatusCancelled
+}
+
+// rerunJobs reruns the selected jobs of a run and all jobs which depend on them directly or indirectly,
+// the outputs of the jobs they need would be stale otherwise
+func rerunJobs(ctx *context_module.Context, jobs, selected []*actions_model.ActionRunJob) error {
+	rerunIDs := make(container.Set[string], len(selected))
+	for _, j := range selected {
+		rerunIDs.Add(j.JobID)
+	}
+	for added := true; added; {
+		added = false
+		for _, j := range jobs {
+			if rerunIDs.Contains(j.JobID) {
+				continue
+			}
+			for _, need := range j.Needs {
+				if rerunIDs.Contains(need) {
+					rerunIDs.Add(j.JobID)
+					added = true
+					break
+				}
+			}
+		}
+	}
+
+	for _, j := range jobs {
+		if !rerunIDs.Contains(j.JobID) {
+			continue
+		}
+		// a job has to wait for the jobs it needs when they are rerun as well
+		shouldBlock := false
+		for _, need := range j.Needs {
+			if rerunIDs.Contains(need) {
+				shouldBlock = true
+				break
+			}
+		}
+		if err := rerunJob(ctx, j, shouldBlock); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rerunJob(ctx *context_module.Context, job *actions_model.ActionRunJob, shouldBlock bool) error {
 	status := job.Status
 	if !status.IsDone() {
 		return nil
@@ -296,6 +368,9 @@ func rerunJob(ctx *context_module.Context, job *actions_model.ActionRunJob) erro
 
 	job.TaskID = 0
 	job.Status = actions_model.StatusWaiting
+	if shouldBlock {
+		job.Status = actions_model.StatusBlocked
+	}
 	job.Started = 0
 	job.Stopped = 0
 
@@ -319,28 +394,7 @@ func Cancel(ctx *context_module.Context) {
 	}
 
 	if err := db.WithTx(ctx, func(ctx context.Context) error {
-		for _, job := range jobs {
-			status := job.Status
-			if status.IsDone() {
-				continue
-			}
-			if job.TaskID == 0 {
-				job.Status = actions_model.StatusCancelled
-				job.Stopped = timeutil.TimeStampNow()
-				n, err := actions_model.UpdateRunJob(ctx, job, builder.Eq{"task_id": 0}, "status", "stopped")
-				if err != nil {
-					return err
-				}
-				if n == 0 {
-					return fmt.Errorf("job has changed, try again")
-				}
-				continue
-			}
-			if err := actions_model.StopTask(ctx, job.TaskID, actions_model.StatusCancelled); err != nil {
-				return err
-			}
-		}
-		return nil
+		return actions_model.CancelJobs(ctx, jobs)
 	}); err != nil {
 		ctx.Error(http.StatusInternalServerError, err.Error())
 		return
@@ -1712,6 +1712,11 @@ func ViewIssue(ctx *context.Context) {
 				ctx.ServerError("CanMarkConversation", err)
 				return
 			}
+
+			if ctx.Data["CanApplySuggestions"], err = pull_service.CanApplySuggestions(ctx, pull, ctx.Doer); err != nil {
+				ctx.ServerError("CanApplySuggestions", err)
+				return
+			}
 		}
 
 		prUnit, err := repo.GetUnit(ctx, unit.TypePullRequests)
@@ -1721,18 +1726,28 @@ func ViewIssue(ctx *context.Context) {
 		}
 		prConfig := prUnit.PullRequestsConfig()
 
+		pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pull.BaseRepoID, pull.BaseBranch)
+		if err != nil {
+			ctx.ServerError("LoadProtectedBranch", err)
+			return
+		}
+		// merge commits are not allowed on a protected branch requiring linear history
+		isMergeStyleAllowed := func(mergeStyle repo_model.MergeStyle) bool {
+			return prConfig.IsMergeStyleAllowed(mergeStyle) && (pb == nil || pb.IsMergeStyleAllowed(mergeStyle))
+		}
+
 		var mergeStyle repo_model.MergeStyle
 		// Check correct values and select default
 		if ms, ok := ctx.Data["MergeStyle"].(repo_model.MergeStyle); !ok ||
-			!prConfig.IsMergeStyleAllowed(ms) {
+			!isMergeStyleAllowed(ms) {
 			defaultMergeStyle := prConfig.GetDefaultMergeStyle()
-			if prConfig.IsMergeStyleAllowed(defaultMergeStyle) && !ok {
+			if isMergeStyleAllowed(defaultMergeStyle) && !ok {
 				mergeStyle = defaultMergeStyle
-			} else if prConfig.AllowMerge {
+			} else if isMergeStyleAllowed(repo_model.MergeStyleMerge) {
 				mergeStyle = repo_model.MergeStyleMerge
 			} else if prConfig.AllowRebase {
 				mergeStyle = repo_model.MergeStyleRebase
-			} else if prConfig.AllowRebaseMerge {
+			} else if isMergeStyleAllowed(repo_model.MergeStyleRebaseMerge) {
 				mergeStyle = repo_model.MergeStyleRebaseMerge
 			} else if prConfig.AllowSquash {
 				mergeStyle = repo_model.MergeStyleSquash
@@ -1759,11 +1774,6 @@ func ViewIssue(ctx *context.Context) {
 		ctx.Data["DefaultSquashMergeMessage"] = defaultSquashMergeMessage
 		ctx.Data["DefaultSquashMergeBody"] = defaultSquashMergeBody
 
-		pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pull.BaseRepoID, pull.BaseBranch)
-		if err != nil {
-			ctx.ServerError("LoadProtectedBranch", err)
-			return
-		}
 		ctx.Data["ShowMergeInstructions"] = true
 		if pb != nil {
 			pb.Repo = pull.BaseRepo
@@ -1782,6 +1792,33 @@ func ViewIssue(ctx *context.Context) {
 			ctx.Data["IsBlockedByChangedProtectedFiles"] = len(pull.ChangedProtectedFiles) != 0
 			ctx.Data["ChangedProtectedFilesNum"] = len(pull.ChangedProtectedFiles)
 			ctx.Data["ShowMergeInstructions"] = showMergeInstructions
+			ctx.Data["IsMergeQueueEnabled"] = pb.EnableMergeQueue
+			if pb.RequireCodeOwnerApproval {
+				codeOwnersApprovals, err := issues_model.GetCodeOwnersApprovals(ctx, pb, pull)
+				if err != nil {
+					ctx.ServerError("GetCodeOwnersApprovals", err)
+					return
+				}
+				approvedFilesNum := 0
+				for _, approval := range codeOwnersApprovals {
+					if approval.IsApproved() {
+						approvedFilesNum++
+					}
+				}
+				ctx.Data["CodeOwnersApprovals"] = codeOwnersApprovals
+				ctx.Data["CodeOwnersApprovedFilesNum"] = approvedFilesNum
+				ctx.Data["IsBlockedByCodeOwners"] = approvedFilesNum != len(codeOwnersApprovals)
+			}
+			if pb.RequireConversationResolution {
+				unresolvedConversationsNum, err := issues_model.CountUnresolvedConversations(ctx, issue.ID)
+				if err != nil {
+					ctx.ServerError("CountUnresolvedConversations", err)
+					return
+				}
+				ctx.Data["UnresolvedConversationsNum"] = unresolvedConversationsNum
+				ctx.Data["IsBlockedByUnresolvedConversations"] = unresolvedConversationsNum > 0
+			}
+			ctx.Data["RequireLinearHistory"] = pb.RequireLinearHistory
 		}
 		ctx.Data["WillSign"] = false
 		if ctx.Doer != nil {
@@ -1838,6 +1875,26 @@ func ViewIssue(ctx *context.Context) {
 			ctx.ServerError("GetScheduledMergeByPullID", err)
 			return
 		}
+
+		// Check if the pr is in the merge queue
+		isInMergeQueue, mergeQueueEntry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pull.ID)
+		if err != nil {
+			ctx.ServerError("GetMergeQueueEntryByPullID", err)
+			return
+		}
+		ctx.Data["IsInMergeQueue"] = isInMergeQueue
+		if isInMergeQueue {
+			if err := mergeQueueEntry.LoadDoer(ctx); err != nil {
+				ctx.ServerError("LoadDoer", err)
+				return
+			}
+			ctx.Data["MergeQueueEntry"] = mergeQueueEntry
+			ctx.Data["MergeQueuePosition"], err = pull_model.GetMergeQueuePosition(ctx, mergeQueueEntry)
+			if err != nil {
+				ctx.ServerError("GetMergeQueuePosition", err)
+				return
+			}
+		}
 	}
 
 	// Get Dependencies
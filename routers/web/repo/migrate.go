@@ -59,6 +59,7 @@ func Migrate(ctx *context.Context) {
 	ctx.Data["issues"] = ctx.FormString("issues") == "1"
 	ctx.Data["pull_requests"] = ctx.FormString("pull_requests") == "1"
 	ctx.Data["releases"] = ctx.FormString("releases") == "1"
+	ctx.Data["sync"] = ctx.FormString("sync") == "1"
 
 	ctxUser := checkContextUser(ctx, ctx.FormInt64("org"))
 	if ctx.Written() {
@@ -222,6 +223,7 @@ func MigratePost(ctx *context.Context) {
 		Comments:       form.Issues || form.PullRequests,
 		PullRequests:   form.PullRequests,
 		Releases:       form.Releases,
+		Sync:           form.Sync && form.Service.IncrementalSync(),
 	}
 	if opts.Mirror {
 		opts.Issues = false
@@ -230,6 +232,7 @@ func MigratePost(ctx *context.Context) {
 		opts.Comments = false
 		opts.PullRequests = false
 		opts.Releases = false
+		opts.Sync = false
 	}
 
 	err = repo_model.CheckCreateRepository(ctx.Doer, ctxUser, opts.RepoName, false)
@@ -458,7 +458,8 @@ func PrepareViewPullInfo(ctx *context.Context, issue *issues_model.Issue) *git.C
 		ctx.ServerError("LoadProtectedBranch", err)
 		return nil
 	}
-	ctx.Data["EnableStatusCheck"] = pb != nil && pb.EnableStatusCheck
+	// with a merge queue the required status checks are run against the queue commit, so they don't block merging the head
+	ctx.Data["EnableStatusCheck"] = pb != nil && pb.EnableStatusCheck && !pb.EnableMergeQueue
 
 	var baseGitRepo *git.Repository
 	if pull.BaseRepoID == ctx.Repo.Repository.ID && ctx.Repo.GitRepo != nil {
@@ -800,6 +801,10 @@ func ViewPullFiles(ctx *context.Context) {
 			ctx.ServerError("CanMarkConversation", err)
 			return
 		}
+		if ctx.Data["CanApplySuggestions"], err = pull_service.CanApplySuggestions(ctx, pull, ctx.Doer); err != nil {
+			ctx.ServerError("CanApplySuggestions", err)
+			return
+		}
 	}
 
 	setCompareContext(ctx, baseCommit, commit, ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
@@ -1018,6 +1023,17 @@ func MergePullRequest(ctx *context.Context) {
 		message += "\n\n" + form.MergeMessageField
 	}
 
+	// check the merge style before it is scheduled or queued
+	if err := pull_service.CheckMergeStyleAllowed(ctx, pr, repo_model.MergeStyle(form.Do)); err != nil {
+		if models.IsErrInvalidMergeStyle(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
+			ctx.Redirect(issue.Link())
+			return
+		}
+		ctx.ServerError("CheckMergeStyleAllowed", err)
+		return
+	}
+
 	if form.MergeWhenChecksSucceed {
 		// delete all scheduled auto merges
 		_ = pull_model.DeleteScheduledAutoMerge(ctx, pr.ID)
@@ -1034,6 +1050,25 @@ func MergePullRequest(ctx *context.Context) {
 		}
 	}
 
+	// the base branch can only be changed through the merge queue
+	if mergeQueueEnabled, err := pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+		ctx.ServerError("IsMergeQueueEnabled", err)
+		return
+	} else if mergeQueueEnabled {
+		if err := automerge.AddToMergeQueue(ctx, ctx.Doer, pr, repo_model.MergeStyle(form.Do), message); err != nil {
+			if pull_model.IsErrAlreadyInMergeQueue(err) {
+				ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue.already_in_queue"))
+				ctx.Redirect(issue.Link())
+				return
+			}
+			ctx.ServerError("AddToMergeQueue", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue.added"))
+		ctx.Redirect(issue.Link())
+		return
+	}
+
 	if err := pull_service.Merge(ctx, pr, ctx.Doer, ctx.Repo.GitRepo, repo_model.MergeStyle(form.Do), form.HeadCommitID, message, false); err != nil {
 		if models.IsErrInvalidMergeStyle(err) {
 			ctx.Flash.Error(ctx.Tr("repo.pulls.invalid_merge_option"))
@@ -1158,6 +1193,41 @@ func CancelAutoMergePullRequest(ctx *context.Context) {
 	ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index))
 }
 
+// RemoveFromMergeQueue removes a pull request from the merge queue of its base branch
+func RemoveFromMergeQueue(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	exist, entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, issue.PullRequest.ID)
+	if err != nil {
+		ctx.ServerError("GetMergeQueueEntryByPullID", err)
+		return
+	}
+	if !exist {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue.not_in_queue"))
+		ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index))
+		return
+	}
+	if entry.DoerID != ctx.Doer.ID && !ctx.Repo.IsAdmin() {
+		ctx.NotFound("RemoveFromMergeQueue", nil)
+		return
+	}
+
+	if err := automerge.RemoveFromMergeQueue(ctx, ctx.Doer, issue.PullRequest, ""); err != nil {
+		if db.IsErrNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.merge_queue.not_in_queue"))
+			ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index))
+			return
+		}
+		ctx.ServerError("RemoveFromMergeQueue", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.pulls.merge_queue.removed"))
+	ctx.Redirect(fmt.Sprintf("%s/pulls/%d", ctx.Repo.RepoLink, issue.Index))
+}
+
 func stopTimerIfAvailable(user *user_model.User, issue *issues_model.Issue) error {
 	if issues_model.StopwatchExists(user.ID, issue.ID) {
 		if err := issues_model.CreateOrStopIssueStopwatch(user, issue); err != nil {
@@ -8,16 +8,20 @@ import (
 	"fmt"
 	"net/http"
 
+	"code.gitea.io/gitea/models"
 	issues_model "code.gitea.io/gitea/models/issues"
 	pull_model "code.gitea.io/gitea/models/pull"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
+	files_service "code.gitea.io/gitea/services/repository/files"
 )
 
 const (
@@ -65,15 +69,17 @@ func CreateCodeComment(ctx *context.Context) {
 		return
 	}
 
-	signedLine := form.Line
+	signedLine, signedStartLine := form.Line, form.StartLine
 	if form.Side == "previous" {
 		signedLine *= -1
+		signedStartLine *= -1
 	}
 
 	comment, err := pull_service.CreateCodeComment(ctx,
 		ctx.Doer,
 		ctx.Repo.GitRepo,
 		issue,
+		signedStartLine,
 		signedLine,
 		form.Content,
 		form.TreePath,
@@ -82,6 +88,10 @@ func CreateCodeComment(ctx *context.Context) {
 		form.LatestCommitID,
 	)
 	if err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Error(http.StatusBadRequest, err.Error())
+			return
+		}
 		ctx.ServerError("CreateCodeComment", err)
 		return
 	}
@@ -101,6 +111,67 @@ func CreateCodeComment(ctx *context.Context) {
 	ctx.Redirect(comment.Link())
 }
 
+// ApplySuggestions commits the suggestions of the code comments to the head branch of the pull request
+func ApplySuggestions(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.ApplySuggestionsForm)
+	issue := GetActionIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !issue.IsPull {
+		ctx.NotFound("ApplySuggestions", nil)
+		return
+	}
+	redirectURL := fmt.Sprintf("%s/pulls/%d/files", ctx.Repo.RepoLink, issue.Index)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(redirectURL)
+		return
+	}
+	if err := issue.LoadPullRequest(ctx); err != nil {
+		ctx.ServerError("LoadPullRequest", err)
+		return
+	}
+
+	comments := make([]*issues_model.Comment, 0, len(form.CommentIDs))
+	for _, id := range form.CommentIDs {
+		comment, err := issues_model.GetCommentByID(ctx, id)
+		if err != nil {
+			if issues_model.IsErrCommentNotExist(err) {
+				ctx.NotFound("GetCommentByID", err)
+			} else {
+				ctx.ServerError("GetCommentByID", err)
+			}
+			return
+		}
+		if comment.IssueID != issue.ID {
+			ctx.NotFound("comment's issueID is incorrect", errors.New("comment's issueID is incorrect"))
+			return
+		}
+		comments = append(comments, comment)
+	}
+
+	if err := files_service.ApplySuggestions(ctx, ctx.Doer, issue.PullRequest, comments, form.Message); err != nil {
+		switch {
+		case errors.Is(err, util.ErrPermissionDenied):
+			ctx.Error(http.StatusForbidden)
+			return
+		case errors.Is(err, util.ErrInvalidArgument), models.IsErrSHADoesNotMatch(err), git.IsErrPushOutOfDate(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.apply_suggestions_failed", err.Error()))
+		case models.IsErrUserCannotCommit(err), models.IsErrFilePathProtected(err), git.IsErrPushRejected(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.apply_suggestions_rejected"))
+		default:
+			ctx.ServerError("ApplySuggestions", err)
+			return
+		}
+		ctx.Redirect(redirectURL)
+		return
+	}
+
+	ctx.Flash.Success(ctx.TrN(len(comments), "repo.pulls.apply_suggestion_success", "repo.pulls.apply_suggestions_success", len(comments)))
+	ctx.Redirect(redirectURL)
+}
+
 // UpdateResolveConversation add or remove an Conversation resolved mark
 func UpdateResolveConversation(ctx *context.Context) {
 	origin := ctx.FormString("origin")
@@ -178,6 +249,10 @@ func renderConversation(ctx *context.Context, comment *issues_model.Comment) {
 		return
 	}
 	ctx.Data["AfterCommitID"] = pullHeadCommitID
+	if ctx.Data["CanApplySuggestions"], err = pull_service.CanApplySuggestions(ctx, comment.Issue.PullRequest, ctx.Doer); err != nil {
+		ctx.ServerError("CanApplySuggestions", err)
+		return
+	}
 	ctx.HTML(http.StatusOK, tplConversation)
 }
 
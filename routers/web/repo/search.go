@@ -4,12 +4,14 @@
 package repo
 
 import (
+	"errors"
 	"net/http"
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 )
 
 const tplSearch base.TplName = "repo/search"
@@ -25,11 +27,13 @@ func Search(ctx *context.Context) {
 	keyword := ctx.FormTrim("q")
 
 	queryType := ctx.FormTrim("t")
-	isMatch := queryType == "match"
+	caseSensitive := ctx.FormBool("cs")
 
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Language"] = language
 	ctx.Data["queryType"] = queryType
+	ctx.Data["CaseSensitive"] = caseSensitive
+	ctx.Data["IsAdvancedSearchSupported"] = code_indexer.IsAdvancedSearchSupported()
 	ctx.Data["PageIsViewCode"] = true
 
 	if keyword == "" {
@@ -42,9 +46,18 @@ func Search(ctx *context.Context) {
 		page = 1
 	}
 
-	total, searchResults, searchResultLanguages, err := code_indexer.PerformSearch(ctx, []int64{ctx.Repo.Repository.ID},
-		language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-	if err != nil {
+	total, searchResults, searchResultLanguages, err := code_indexer.PerformSearch(ctx, &code_indexer.SearchOptions{
+		RepoIDs:       []int64{ctx.Repo.Repository.ID},
+		Keyword:       keyword,
+		Language:      language,
+		Mode:          code_indexer.ParseSearchMode(queryType),
+		CaseSensitive: caseSensitive,
+		Page:          page,
+		PageSize:      setting.UI.RepoSearchPagingNum,
+	})
+	if errors.Is(err, util.ErrInvalidArgument) {
+		ctx.Data["SearchError"] = ctx.Tr("repo.search.code_search_invalid", err.Error())
+	} else if err != nil {
 		if code_indexer.IsAvailable() {
 			ctx.ServerError("SearchResults", err)
 			return
@@ -61,6 +74,9 @@ func Search(ctx *context.Context) {
 	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "l", "Language")
+	if caseSensitive {
+		pager.AddParamString("cs", "1")
+	}
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(http.StatusOK, tplSearch)
@@ -14,6 +14,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	actions_model "code.gitea.io/gitea/models/actions"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
@@ -32,11 +33,13 @@ import (
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/typesniffer"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
+	actions_service "code.gitea.io/gitea/services/actions"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
@@ -96,6 +99,14 @@ func SettingsCtxData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["PushMirrors"] = pushMirrors
+
+	migrationSync, err := repo_model.GetMigrationSyncByRepoID(ctx, ctx.Repo.Repository.ID)
+	if err != nil && err != repo_model.ErrMigrationSyncNotExist {
+		ctx.ServerError("GetMigrationSyncByRepoID", err)
+		return
+	}
+	ctx.Data["MigrationSync"] = migrationSync
+	ctx.Data["MinimumMigrationSyncInterval"] = setting.Migrations.MinSyncInterval
 }
 
 // Settings show a repository's settings page
@@ -291,6 +302,62 @@ func SettingsPost(ctx *context.Context) {
 		ctx.Flash.Info(ctx.Tr("repo.settings.mirror_sync_in_progress"))
 		ctx.Redirect(repo.Link() + "/settings")
 
+	case "migration-sync":
+		migrationSync, err := repo_model.GetMigrationSyncByRepoID(ctx, repo.ID)
+		if err == repo_model.ErrMigrationSyncNotExist {
+			ctx.NotFound("", nil)
+			return
+		} else if err != nil {
+			ctx.ServerError("GetMigrationSyncByRepoID", err)
+			return
+		}
+		ctx.Data["Err_RepoName"] = nil
+
+		interval, err := time.ParseDuration(form.Interval)
+		if err != nil || (interval != 0 && interval < setting.Migrations.MinSyncInterval) {
+			ctx.Data["Err_Interval"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.mirror_interval_invalid"), tplSettingsOptions, &form)
+			return
+		}
+
+		migrationSync.Interval = interval
+		migrationSync.ScheduleNextSync()
+		if err := repo_model.UpdateMigrationSyncCols(ctx, migrationSync, "interval", "next_sync_unix"); err != nil {
+			ctx.ServerError("UpdateMigrationSyncCols", err)
+			return
+		}
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "migration-sync-now":
+		migrationSync, err := repo_model.GetMigrationSyncByRepoID(ctx, repo.ID)
+		if err == repo_model.ErrMigrationSyncNotExist {
+			ctx.NotFound("", nil)
+			return
+		} else if err != nil {
+			ctx.ServerError("GetMigrationSyncByRepoID", err)
+			return
+		}
+
+		migrationSync.NextSyncUnix = timeutil.TimeStampNow()
+		if err := repo_model.UpdateMigrationSyncCols(ctx, migrationSync, "next_sync_unix"); err != nil {
+			ctx.ServerError("UpdateMigrationSyncCols", err)
+			return
+		}
+
+		ctx.Flash.Info(ctx.Tr("repo.settings.migration_sync_in_progress"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "migration-sync-stop":
+		if err := repo_model.DeleteMigrationSyncByRepoID(ctx, repo.ID); err != nil {
+			ctx.ServerError("DeleteMigrationSyncByRepoID", err)
+			return
+		}
+
+		ctx.Flash.Success(ctx.Tr("repo.settings.migration_sync_stopped"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "push-mirror-sync":
 		if !setting.Mirror.Enabled {
 			ctx.NotFound("", nil)
@@ -843,6 +910,10 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		if err := actions_model.DeleteScheduleTaskByRepo(ctx, repo.ID); err != nil {
+			log.Error("DeleteScheduleTaskByRepo for archived repo %s/%s: %v", ctx.Repo.Owner.Name, repo.Name, err)
+		}
+
 		ctx.Flash.Success(ctx.Tr("repo.settings.archive.success"))
 
 		log.Trace("Repository was archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
@@ -861,6 +932,10 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		if err := actions_service.DetectAndHandleSchedules(ctx, ctx.Doer, repo); err != nil {
+			log.Error("DetectAndHandleSchedules for un-archived repo %s/%s: %v", ctx.Repo.Owner.Name, repo.Name, err)
+		}
+
 		ctx.Flash.Success(ctx.Tr("repo.settings.unarchive.success"))
 
 		log.Trace("Repository was un-archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
@@ -0,0 +1,107 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package setting
+
+import (
+	"errors"
+	"net/http"
+
+	git_model "code.gitea.io/gitea/models/git"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplRepoPushRules base.TplName = "repo/settings/push_rules"
+	tplOrgPushRules  base.TplName = "org/settings/push_rules"
+)
+
+type pushRulesCtx struct {
+	OwnerID           int64
+	RepoID            int64
+	PushRulesTemplate base.TplName
+	RedirectLink      string
+}
+
+func getPushRulesCtx(ctx *context.Context) (*pushRulesCtx, error) {
+	if ctx.Data["PageIsRepoSettings"] == true {
+		return &pushRulesCtx{
+			RepoID:            ctx.Repo.Repository.ID,
+			PushRulesTemplate: tplRepoPushRules,
+			RedirectLink:      ctx.Repo.RepoLink + "/settings/push_rules",
+		}, nil
+	}
+
+	if ctx.Data["PageIsOrgSettings"] == true {
+		return &pushRulesCtx{
+			OwnerID:           ctx.ContextUser.ID,
+			PushRulesTemplate: tplOrgPushRules,
+			RedirectLink:      ctx.Org.OrgLink + "/settings/push_rules",
+		}, nil
+	}
+
+	return nil, errors.New("unable to set PushRules context")
+}
+
+// PushRules renders the push rules of a repository or an organization
+func PushRules(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.push_rules")
+	ctx.Data["PageIsSettingsPushRules"] = true
+
+	prCtx, err := getPushRulesCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getPushRulesCtx", err)
+		return
+	}
+
+	rule, err := git_model.GetPushRule(ctx, prCtx.OwnerID, prCtx.RepoID)
+	if err != nil {
+		ctx.ServerError("GetPushRule", err)
+		return
+	}
+	ctx.Data["PushRule"] = rule
+	ctx.Data["MaxFileSizeMiB"] = rule.MaxFileSize / 1024 / 1024
+
+	ctx.HTML(http.StatusOK, prCtx.PushRulesTemplate)
+}
+
+// PushRulesPost updates the push rules of a repository or an organization
+func PushRulesPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.PushRuleForm)
+
+	prCtx, err := getPushRulesCtx(ctx)
+	if err != nil {
+		ctx.ServerError("getPushRulesCtx", err)
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(prCtx.RedirectLink)
+		return
+	}
+
+	if err := git_model.SavePushRule(ctx, &git_model.PushRule{
+		OwnerID:               prCtx.OwnerID,
+		RepoID:                prCtx.RepoID,
+		CommitMessagePattern:  form.CommitMessagePattern,
+		AllowedEmailDomains:   form.AllowedEmailDomains,
+		MaxFileSize:           form.MaxFileSize * 1024 * 1024,
+		ForbiddenFilePatterns: form.ForbiddenFilePatterns,
+	}); err != nil {
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.push_rules.update_failed", err.Error()))
+			ctx.Redirect(prCtx.RedirectLink)
+			return
+		}
+		ctx.ServerError("SavePushRule", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.push_rules.update_success"))
+	ctx.Redirect(prCtx.RedirectLink)
+}
@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
 	"code.gitea.io/gitea/services/forms"
 	pull_service "code.gitea.io/gitea/services/pull"
 	"code.gitea.io/gitea/services/repository"
@@ -77,6 +78,10 @@ func SetDefaultBranchPost(ctx *context.Context) {
 				ctx.ServerError("SetDefaultBranch", err)
 				return
 			}
+			// the schedules always follow the workflows of the default branch
+			if err := actions_service.DetectAndHandleSchedules(ctx, ctx.Doer, repo); err != nil {
+				log.Error("DetectAndHandleSchedules for new default branch of repo %s/%s: %v", ctx.Repo.Owner.Name, repo.Name, err)
+			}
 		}
 
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
@@ -271,6 +276,10 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 	protectBranch.ProtectedFilePatterns = f.ProtectedFilePatterns
 	protectBranch.UnprotectedFilePatterns = f.UnprotectedFilePatterns
 	protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
+	protectBranch.EnableMergeQueue = f.EnableMergeQueue
+	protectBranch.RequireCodeOwnerApproval = f.RequireCodeOwnerApproval
+	protectBranch.RequireLinearHistory = f.RequireLinearHistory
+	protectBranch.RequireConversationResolution = f.RequireConversationResolution
 
 	err = git_model.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, git_model.WhitelistOptions{
 		UserIDs:          whitelistUsers,
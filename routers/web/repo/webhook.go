@@ -10,7 +10,9 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"sort"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models/perm"
 	user_model "code.gitea.io/gitea/models/user"
@@ -147,8 +149,13 @@ func WebhooksNew(ctx *context.Context) {
 		ctx.Data["DiscordHook"] = map[string]interface{}{
 			"Username": "Gitea",
 		}
+	} else if hookType == "custom" {
+		ctx.Data["CustomHook"] = &webhook_service.CustomMeta{
+			ContentType: webhook_service.CustomDefaultContentType,
+		}
 	}
 	ctx.Data["BaseLink"] = orCtx.LinkNew
+	ctx.Data["CustomHookPreviewLink"] = orCtx.Link + "/custom/preview"
 
 	ctx.HTML(http.StatusOK, orCtx.NewTemplate)
 }
@@ -211,6 +218,7 @@ func createWebhook(ctx *context.Context, params webhookParams) {
 		return
 	}
 	ctx.Data["BaseLink"] = orCtx.LinkNew
+	ctx.Data["CustomHookPreviewLink"] = orCtx.Link + "/custom/preview"
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, orCtx.NewTemplate)
@@ -232,6 +240,7 @@ func createWebhook(ctx *context.Context, params webhookParams) {
 		HTTPMethod:      params.HTTPMethod,
 		ContentType:     params.ContentType,
 		Secret:          params.Secret,
+		HTTPSignature:   params.WebhookForm.HTTPSignature,
 		HookEvent:       ParseHookEvent(params.WebhookForm),
 		IsActive:        params.WebhookForm.Active,
 		Type:            params.Type,
@@ -284,8 +293,19 @@ func editWebhook(ctx *context.Context, params webhookParams) {
 
 	w.URL = params.URL
 	w.ContentType = params.ContentType
-	w.Secret = params.Secret
+	if params.WebhookForm.RevokePreviousSecrets {
+		w.RevokePreviousSecrets()
+	}
+	if err := w.RotateSecret(params.Secret, time.Duration(params.WebhookForm.SecretRotationHours)*time.Hour); err != nil {
+		ctx.ServerError("RotateSecret", err)
+		return
+	}
+	w.HTTPSignature = params.WebhookForm.HTTPSignature
 	w.HookEvent = ParseHookEvent(params.WebhookForm)
+	if params.WebhookForm.Active && !w.IsActive {
+		// give a webhook activated again a fresh start
+		w.FailureCount = 0
+	}
 	w.IsActive = params.WebhookForm.Active
 	w.HTTPMethod = params.HTTPMethod
 	w.Meta = string(meta)
@@ -577,6 +597,60 @@ func packagistHookParams(ctx *context.Context) webhookParams {
 	}
 }
 
+// CustomHooksNewPost response for creating custom webhook
+func CustomHooksNewPost(ctx *context.Context) {
+	createWebhook(ctx, customHookParams(ctx))
+}
+
+// CustomHooksEditPost response for editing custom webhook
+func CustomHooksEditPost(ctx *context.Context) {
+	editWebhook(ctx, customHookParams(ctx))
+}
+
+func customHookParams(ctx *context.Context) webhookParams {
+	form := web.GetForm(ctx).(*forms.NewCustomHookForm)
+	ctx.Data["CustomHook"] = form.Meta()
+
+	return webhookParams{
+		Type:        webhook_module.CUSTOM,
+		URL:         form.PayloadURL,
+		ContentType: webhook.ContentTypeJSON,
+		Secret:      form.Secret,
+		HTTPMethod:  form.HTTPMethod,
+		WebhookForm: form.WebhookForm,
+		Meta:        form.Meta(),
+	}
+}
+
+// CustomHookPreview renders the templates of a custom webhook against a sample payload
+func CustomHookPreview(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CustomHookPreviewForm)
+	meta := &webhook_service.CustomMeta{
+		ContentType:     strings.TrimSpace(form.ContentType),
+		HeadersTemplate: form.HeadersTemplate,
+		BodyTemplate:    form.BodyTemplate,
+	}
+
+	payload, err := webhook_service.PreviewCustomPayload(meta)
+	if err != nil {
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"error": err.Error(),
+		})
+		return
+	}
+
+	headers := make([]string, 0, len(payload.Headers)+1)
+	headers = append(headers, "Content-Type: "+payload.ContentType)
+	for name, value := range payload.Headers {
+		headers = append(headers, name+": "+value)
+	}
+	sort.Strings(headers[1:])
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"headers": strings.Join(headers, "\n"),
+		"body":    payload.Body,
+	})
+}
+
 func checkWebhook(ctx *context.Context) (*ownerRepoCtx, *webhook.Webhook) {
 	orCtx, err := getOwnerRepoCtx(ctx)
 	if err != nil {
@@ -584,6 +658,7 @@ func checkWebhook(ctx *context.Context) (*ownerRepoCtx, *webhook.Webhook) {
 		return nil, nil
 	}
 	ctx.Data["BaseLink"] = orCtx.Link
+	ctx.Data["CustomHookPreviewLink"] = orCtx.Link + "/custom/preview"
 
 	var w *webhook.Webhook
 	if orCtx.RepoID > 0 {
@@ -614,12 +689,26 @@ func checkWebhook(ctx *context.Context) (*ownerRepoCtx, *webhook.Webhook) {
 		ctx.Data["MatrixHook"] = webhook_service.GetMatrixHook(w)
 	case webhook_module.PACKAGIST:
 		ctx.Data["PackagistHook"] = webhook_service.GetPackagistHook(w)
+	case webhook_module.CUSTOM:
+		ctx.Data["CustomHook"] = webhook_service.GetCustomHook(w)
 	}
 
 	ctx.Data["History"], err = w.History(1)
 	if err != nil {
 		ctx.ServerError("History", err)
+		return nil, nil
+	}
+	ctx.Data["FailedDeliveries"], err = webhook.FailedHookTasks(ctx, w.ID, 1)
+	if err != nil {
+		ctx.ServerError("FailedHookTasks", err)
+		return nil, nil
 	}
+	ctx.Data["FailedDeliveriesCount"], err = webhook.CountFailedHookTasks(ctx, w.ID)
+	if err != nil {
+		ctx.ServerError("CountFailedHookTasks", err)
+		return nil, nil
+	}
+	ctx.Data["AutoDisableAfterFailures"] = setting.Webhook.AutoDisableAfterFailures
 	return orCtx, w
 }
 
@@ -720,6 +809,33 @@ func ReplayWebhook(ctx *context.Context) {
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
+// RedeliverFailedWebhookTasks delivers again the selected failed deliveries of a webhook, or all of them
+func RedeliverFailedWebhookTasks(ctx *context.Context) {
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	var uuids []string
+	if !ctx.FormBool("all") {
+		uuids = ctx.FormStrings("uuids")
+		if len(uuids) == 0 {
+			ctx.Flash.Error(ctx.Tr("repo.settings.webhook.redeliver.none_selected"))
+			ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+			return
+		}
+	}
+
+	count, err := webhook_service.RedeliverFailedHookTasks(ctx, w, uuids)
+	if err != nil {
+		ctx.ServerError("RedeliverFailedHookTasks", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.TrN(count, "repo.settings.webhook.redeliver.success_1", "repo.settings.webhook.redeliver.success_n", count))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
 // DeleteWebhook delete a webhook
 func DeleteWebhook(ctx *context.Context) {
 	if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.FormInt64("id")); err != nil {
@@ -0,0 +1,101 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+// SetVariablesContext prepares data for the variables list
+func SetVariablesContext(ctx *context.Context, ownerID, repoID int64) {
+	variables, err := actions_model.FindVariables(ctx, actions_model.FindVariablesOpts{OwnerID: ownerID, RepoID: repoID})
+	if err != nil {
+		ctx.ServerError("FindVariables", err)
+		return
+	}
+	ctx.Data["Variables"] = variables
+}
+
+// normalizeVariableData converts the \r\n line endings of the textarea to \n like what GitHub does,
+// other than this the original content is respected, even leading or trailing spaces.
+func normalizeVariableData(data string) string {
+	return strings.ReplaceAll(data, "\r\n", "\n")
+}
+
+func flashVariableError(ctx *context.Context, key string, err error) {
+	switch {
+	case errors.Is(err, util.ErrInvalidArgument):
+		ctx.Flash.Error(ctx.Tr("actions.variables."+key+".failed") + " " + ctx.Tr("actions.variables.invalid_name"))
+	case errors.Is(err, util.ErrAlreadyExist):
+		ctx.Flash.Error(ctx.Tr("actions.variables."+key+".failed") + " " + ctx.Tr("actions.variables.name_already_exists"))
+	default:
+		ctx.Flash.Error(ctx.Tr("actions.variables." + key + ".failed"))
+	}
+}
+
+// CreateVariable response for creating a variable
+func CreateVariable(ctx *context.Context, ownerID, repoID int64, redirectURL string) {
+	form := web.GetForm(ctx).(*forms.EditVariableForm)
+
+	v, err := actions_model.InsertVariable(ctx, ownerID, repoID, form.Name, normalizeVariableData(form.Data))
+	if err != nil {
+		log.Error("InsertVariable: %v", err)
+		flashVariableError(ctx, "creation", err)
+	} else {
+		ctx.Flash.Success(ctx.Tr("actions.variables.creation.success", v.Name))
+	}
+
+	ctx.Redirect(redirectURL)
+}
+
+// UpdateVariable response for updating a variable
+func UpdateVariable(ctx *context.Context, ownerID, repoID int64, redirectURL string) {
+	id := ctx.ParamsInt64(":variable_id")
+	form := web.GetForm(ctx).(*forms.EditVariableForm)
+
+	v, err := actions_model.GetVariableByID(ctx, ownerID, repoID, id)
+	if errors.Is(err, util.ErrNotExist) {
+		ctx.NotFound("GetVariableByID", err)
+		return
+	} else if err != nil {
+		ctx.ServerError("GetVariableByID", err)
+		return
+	}
+
+	v.Name = form.Name
+	v.Data = normalizeVariableData(form.Data)
+	if err := actions_model.UpdateVariable(ctx, v); err != nil {
+		log.Error("UpdateVariable: %v", err)
+		flashVariableError(ctx, "update", err)
+	} else {
+		ctx.Flash.Success(ctx.Tr("actions.variables.update.success", v.Name))
+	}
+
+	ctx.Redirect(redirectURL)
+}
+
+// DeleteVariable response for deleting a variable
+func DeleteVariable(ctx *context.Context, ownerID, repoID int64, redirectURL string) {
+	id := ctx.ParamsInt64(":variable_id")
+
+	if err := actions_model.DeleteVariable(ctx, ownerID, repoID, id); err != nil {
+		log.Error("Delete variable %d failed: %v", id, err)
+		ctx.Flash.Error(ctx.Tr("actions.variables.deletion.failed"))
+	} else {
+		ctx.Flash.Success(ctx.Tr("actions.variables.deletion.success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": redirectURL,
+	})
+}
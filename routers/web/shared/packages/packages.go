@@ -6,6 +6,7 @@ package packages
 import (
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
@@ -14,9 +15,11 @@ import (
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
+	packages_service "code.gitea.io/gitea/services/packages"
 	cargo_service "code.gitea.io/gitea/services/packages/cargo"
 	container_service "code.gitea.io/gitea/services/packages/container"
 )
@@ -29,6 +32,23 @@ func SetPackagesContext(ctx *context.Context, owner *user_model.User) {
 	}
 
 	ctx.Data["CleanupRules"] = pcrs
+
+	prs, err := packages_model.GetRemotesByOwner(ctx, owner.ID)
+	if err != nil {
+		ctx.ServerError("GetRemotesByOwner", err)
+		return
+	}
+
+	ctx.Data["Remotes"] = prs
+
+	usage, err := packages_service.GetOwnerUsage(ctx, owner.ID)
+	if err != nil {
+		ctx.ServerError("GetOwnerUsage", err)
+		return
+	}
+
+	ctx.Data["Usage"] = usage
+	ctx.Data["LFSStartServer"] = setting.LFS.StartServer
 }
 
 func SetRuleAddContext(ctx *context.Context) {
@@ -226,6 +246,148 @@ func getCleanupRuleByContext(ctx *context.Context, owner *user_model.User) *pack
 	return nil
 }
 
+func SetRemoteAddContext(ctx *context.Context) {
+	setRemoteEditContext(ctx, nil)
+}
+
+func SetRemoteEditContext(ctx *context.Context, owner *user_model.User) {
+	pr := getRemoteByContext(ctx, owner)
+	if pr == nil {
+		return
+	}
+
+	setRemoteEditContext(ctx, pr)
+}
+
+func setRemoteEditContext(ctx *context.Context, pr *packages_model.PackageRemote) {
+	ctx.Data["IsEditRemote"] = pr != nil
+
+	if pr == nil {
+		pr = &packages_model.PackageRemote{}
+	}
+	ctx.Data["Remote"] = pr
+	ctx.Data["AvailableTypes"] = packages_model.RemoteTypeList
+}
+
+func PerformRemoteAddPost(ctx *context.Context, owner *user_model.User, redirectURL string, template base.TplName) {
+	performRemoteEditPost(ctx, owner, nil, redirectURL, template)
+}
+
+func PerformRemoteEditPost(ctx *context.Context, owner *user_model.User, redirectURL string, template base.TplName) {
+	pr := getRemoteByContext(ctx, owner)
+	if pr == nil {
+		return
+	}
+
+	form := web.GetForm(ctx).(*forms.PackageRemoteForm)
+
+	if form.Action == "remove" {
+		if err := packages_model.DeleteRemoteByID(ctx, pr.ID); err != nil {
+			ctx.ServerError("DeleteRemoteByID", err)
+			return
+		}
+
+		ctx.Flash.Success(ctx.Tr("packages.owner.settings.remotes.success.delete"))
+		ctx.Redirect(redirectURL)
+	} else {
+		performRemoteEditPost(ctx, owner, pr, redirectURL, template)
+	}
+}
+
+func performRemoteEditPost(ctx *context.Context, owner *user_model.User, pr *packages_model.PackageRemote, redirectURL string, template base.TplName) {
+	isEditRemote := pr != nil
+
+	if pr == nil {
+		pr = &packages_model.PackageRemote{}
+	}
+
+	form := web.GetForm(ctx).(*forms.PackageRemoteForm)
+
+	oldURL := pr.URL
+
+	pr.Enabled = form.Enabled
+	pr.OwnerID = owner.ID
+	pr.URL = strings.TrimSuffix(form.URL, "/")
+	pr.Username = form.Username
+	pr.MetadataTTLMinutes = form.MetadataTTLMinutes
+
+	ctx.Data["IsEditRemote"] = isEditRemote
+	ctx.Data["Remote"] = pr
+	ctx.Data["AvailableTypes"] = packages_model.RemoteTypeList
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, template)
+		return
+	}
+
+	// an empty password keeps the stored one unless the username got removed
+	if form.Password != "" || pr.Username == "" {
+		if err := pr.SetPassword(form.Password); err != nil {
+			ctx.ServerError("SetPassword", err)
+			return
+		}
+	}
+
+	if isEditRemote {
+		if err := packages_model.UpdateRemote(ctx, pr); err != nil {
+			ctx.ServerError("UpdateRemote", err)
+			return
+		}
+		// metadata of the previous upstream registry must not be served for the new one
+		if oldURL != pr.URL {
+			if err := packages_model.DeleteRemoteMetadataByRemoteID(ctx, pr.ID); err != nil {
+				ctx.ServerError("DeleteRemoteMetadataByRemoteID", err)
+				return
+			}
+		}
+	} else {
+		pr.Type = packages_model.Type(form.Type)
+
+		if has, err := packages_model.HasOwnerRemoteForPackageType(ctx, owner.ID, pr.Type); err != nil {
+			ctx.ServerError("HasOwnerRemoteForPackageType", err)
+			return
+		} else if has {
+			ctx.Data["Err_Type"] = true
+			ctx.HTML(http.StatusOK, template)
+			return
+		}
+
+		var err error
+		if pr, err = packages_model.InsertRemote(ctx, pr); err != nil {
+			ctx.ServerError("InsertRemote", err)
+			return
+		}
+	}
+
+	ctx.Flash.Success(ctx.Tr("packages.owner.settings.remotes.success.update"))
+	ctx.Redirect(fmt.Sprintf("%s/remotes/%d", redirectURL, pr.ID))
+}
+
+func getRemoteByContext(ctx *context.Context, owner *user_model.User) *packages_model.PackageRemote {
+	id := ctx.FormInt64("id")
+	if id == 0 {
+		id = ctx.ParamsInt64("id")
+	}
+
+	pr, err := packages_model.GetRemoteByID(ctx, id)
+	if err != nil {
+		if err == packages_model.ErrPackageRemoteNotExist {
+			ctx.NotFound("", err)
+		} else {
+			ctx.ServerError("GetRemoteByID", err)
+		}
+		return nil
+	}
+
+	if pr != nil && pr.OwnerID == owner.ID {
+		return pr
+	}
+
+	ctx.NotFound("", fmt.Errorf("PackageRemote[%v] not associated to owner %v", id, owner))
+
+	return nil
+}
+
 func InitializeCargoIndex(ctx *context.Context, owner *user_model.User) {
 	err := cargo_service.InitializeIndexRepository(ctx, owner, owner)
 	if err != nil {
@@ -4,6 +4,7 @@
 package user
 
 import (
+	"errors"
 	"net/http"
 
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -11,6 +12,7 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
 )
 
 const (
@@ -34,11 +36,13 @@ func CodeSearch(ctx *context.Context) {
 	keyword := ctx.FormTrim("q")
 
 	queryType := ctx.FormTrim("t")
-	isMatch := queryType == "match"
+	caseSensitive := ctx.FormBool("cs")
 
 	ctx.Data["Keyword"] = keyword
 	ctx.Data["Language"] = language
 	ctx.Data["queryType"] = queryType
+	ctx.Data["CaseSensitive"] = caseSensitive
+	ctx.Data["IsAdvancedSearchSupported"] = code_indexer.IsAdvancedSearchSupported()
 	ctx.Data["IsCodePage"] = true
 
 	if keyword == "" {
@@ -69,8 +73,18 @@ func CodeSearch(ctx *context.Context) {
 	)
 
 	if len(repoIDs) > 0 {
-		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(ctx, repoIDs, language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
-		if err != nil {
+		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(ctx, &code_indexer.SearchOptions{
+			RepoIDs:       repoIDs,
+			Keyword:       keyword,
+			Language:      language,
+			Mode:          code_indexer.ParseSearchMode(queryType),
+			CaseSensitive: caseSensitive,
+			Page:          page,
+			PageSize:      setting.UI.RepoSearchPagingNum,
+		})
+		if errors.Is(err, util.ErrInvalidArgument) {
+			ctx.Data["SearchError"] = ctx.Tr("explore.code_search_invalid", err.Error())
+		} else if err != nil {
 			if code_indexer.IsAvailable() {
 				ctx.ServerError("SearchResults", err)
 				return
@@ -108,6 +122,9 @@ func CodeSearch(ctx *context.Context) {
 	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "l", "Language")
+	if caseSensitive {
+		pager.AddParamString("cs", "1")
+	}
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(http.StatusOK, tplUserCode)
@@ -6,6 +6,7 @@ package user
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"net/http"
 	"regexp"
@@ -466,25 +467,62 @@ func buildIssueOverview(ctx *context.Context, unitType unit.Type) {
 	keyword := strings.Trim(ctx.FormString("q"), " ")
 	ctx.Data["Keyword"] = keyword
 
+	// Ensure no issues are returned if a keyword was provided that didn't match any issues.
+	var forceEmpty bool
+
+	// The search term may contain qualifiers (is:open, label:bug, author:name, ...) filtering the issues.
+	searchOpts, err := issue_indexer.ParseSearchQuery(keyword)
+	if err != nil {
+		if !errors.Is(err, util.ErrInvalidArgument) {
+			ctx.ServerError("ParseSearchQuery", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("home.issues.invalid_search_query", err.Error()), true)
+		searchOpts = &issue_indexer.SearchOptions{}
+		forceEmpty = true
+	}
+
+	// An is:pr or is:issue qualifier of the search term takes precedence over the type of the overview page.
+	if !searchOpts.IsPull.IsNone() {
+		isPullList = searchOpts.IsPull.IsTrue()
+		opts.IsPull = searchOpts.IsPull
+		if isPullList {
+			unitType = unit.TypePullRequests
+		} else {
+			unitType = unit.TypeIssues
+		}
+	}
+
+	// Without keyword, the qualifiers are applied by the database.
+	// With a keyword, they are searched by the issue indexer together with the keyword.
+	if len(searchOpts.Keyword) == 0 {
+		opts.FilterCond, err = searchOpts.Cond(ctx)
+		if err != nil {
+			ctx.ServerError("IssueFilter.Cond", err)
+			return
+		}
+	}
+
 	// Execute keyword search for issues.
 	// USING NON-FINAL STATE OF opts FOR A QUERY.
-	issueIDsFromSearch, err := issueIDsFromSearch(ctx, ctxUser, keyword, opts)
+	issueIDsFromSearch, err := issueIDsFromSearch(ctx, ctxUser, searchOpts, opts)
 	if err != nil {
 		ctx.ServerError("issueIDsFromSearch", err)
 		return
 	}
 
-	// Ensure no issues are returned if a keyword was provided that didn't match any issues.
-	var forceEmpty bool
-
 	if len(issueIDsFromSearch) > 0 {
 		opts.IssueIDs = issueIDsFromSearch
-	} else if len(keyword) > 0 {
+	} else if len(searchOpts.Keyword) > 0 {
 		forceEmpty = true
 	}
 
 	// Educated guess: Do or don't show closed issues.
+	// An is:open or is:closed qualifier of the search term takes precedence over the state.
 	isShowClosed := ctx.FormString("state") == "closed"
+	if !searchOpts.IsClosed.IsNone() {
+		isShowClosed = searchOpts.IsClosed.IsTrue()
+	}
 	opts.IsClosed = util.OptionalBoolOf(isShowClosed)
 
 	// Filter repos and count issues in them. Count will be used later.
@@ -587,6 +625,7 @@ func buildIssueOverview(ctx *context.Context, unitType unit.Type) {
 			Org:        org,
 			Team:       team,
 			RepoCond:   opts.RepoCond,
+			FilterCond: opts.FilterCond,
 		}
 
 		issueStats, err = issues_model.GetUserIssueStats(filterMode, statsOpts)
@@ -716,8 +755,8 @@ func getRepoIDs(reposQuery string) []int64 {
 	return repoIDs
 }
 
-func issueIDsFromSearch(ctx *context.Context, ctxUser *user_model.User, keyword string, opts *issues_model.IssuesOptions) ([]int64, error) {
-	if len(keyword) == 0 {
+func issueIDsFromSearch(ctx *context.Context, ctxUser *user_model.User, searchOpts *issue_indexer.SearchOptions, opts *issues_model.IssuesOptions) ([]int64, error) {
+	if len(searchOpts.Keyword) == 0 {
 		return []int64{}, nil
 	}
 
@@ -725,9 +764,16 @@ func issueIDsFromSearch(ctx *context.Context, ctxUser *user_model.User, keyword
 	if err != nil {
 		return nil, fmt.Errorf("GetRepoIDsForIssuesOptions: %w", err)
 	}
-	issueIDsFromSearch, err := issue_indexer.SearchIssuesByKeyword(ctx, searchRepoIDs, keyword)
+	filter, err := searchOpts.Resolve(ctx, searchRepoIDs)
+	if err != nil {
+		return nil, fmt.Errorf("IssueFilter.Resolve: %w", err)
+	}
+	if filter == nil {
+		return []int64{}, nil
+	}
+	issueIDsFromSearch, err := issue_indexer.SearchIssues(ctx, searchOpts.Keyword, filter)
 	if err != nil {
-		return nil, fmt.Errorf("SearchIssuesByKeyword: %w", err)
+		return nil, fmt.Errorf("SearchIssues: %w", err)
 	}
 
 	return issueIDsFromSearch, nil
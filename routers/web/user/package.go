@@ -5,6 +5,8 @@ package user
 
 import (
 	"net/http"
+	"net/url"
+	"strings"
 
 	"code.gitea.io/gitea/models/db"
 	org_model "code.gitea.io/gitea/models/organization"
@@ -18,13 +20,18 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	alpine_module "code.gitea.io/gitea/modules/packages/alpine"
+	arch_module "code.gitea.io/gitea/modules/packages/arch"
+	container_module "code.gitea.io/gitea/modules/packages/container"
 	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	shared_user "code.gitea.io/gitea/routers/web/shared/user"
 	"code.gitea.io/gitea/services/forms"
 	packages_service "code.gitea.io/gitea/services/packages"
+	arch_service "code.gitea.io/gitea/services/packages/arch"
+	container_service "code.gitea.io/gitea/services/packages/container"
 )
 
 const (
@@ -165,10 +172,31 @@ func ViewPackageVersion(ctx *context.Context) {
 	ctx.Data["Title"] = pd.Package.Name
 	ctx.Data["IsPackagesPage"] = true
 	ctx.Data["PackageDescriptor"] = pd
+	ctx.Data["RemoteOrigin"] = pd.VersionProperties.GetByName(packages_model.PropertyRemoteOrigin)
 
 	switch pd.Package.Type {
 	case packages_model.TypeContainer:
 		ctx.Data["RegistryHost"] = setting.Packages.RegistryHost
+
+		referrers, err := container_service.GetVersionReferrers(ctx, pd)
+		if err != nil {
+			ctx.ServerError("GetVersionReferrers", err)
+			return
+		}
+		ctx.Data["Referrers"] = referrers
+
+		if subject := pd.VersionProperties.GetByName(container_module.PropertyManifestSubject); subject != "" {
+			ctx.Data["Subject"] = subject
+
+			pv, err := container_service.GetSubjectVersion(ctx, pd)
+			if err != nil && err != packages_model.ErrPackageNotExist {
+				ctx.ServerError("GetSubjectVersion", err)
+				return
+			}
+			if pv != nil {
+				ctx.Data["SubjectLink"] = pd.PackageWebLink() + "/" + url.PathEscape(pv.LowerVersion)
+			}
+		}
 	case packages_model.TypeAlpine:
 		branches := make(container.Set[string])
 		repositories := make(container.Set[string])
@@ -190,6 +218,30 @@ func ViewPackageVersion(ctx *context.Context) {
 		ctx.Data["Branches"] = branches.Values()
 		ctx.Data["Repositories"] = repositories.Values()
 		ctx.Data["Architectures"] = architectures.Values()
+	case packages_model.TypeArch:
+		repositories := make(container.Set[string])
+		architectures := make(container.Set[string])
+
+		for _, f := range pd.Files {
+			for _, pp := range f.Properties {
+				switch pp.Name {
+				case arch_module.PropertyRepository:
+					repositories.Add(pp.Value)
+				case arch_module.PropertyArchitecture:
+					architectures.Add(pp.Value)
+				}
+			}
+		}
+
+		fingerprint, err := arch_service.GetKeyFingerprint(pd.Owner.ID)
+		if err != nil {
+			ctx.ServerError("GetKeyFingerprint", err)
+			return
+		}
+
+		ctx.Data["Repositories"] = repositories.Values()
+		ctx.Data["Architectures"] = architectures.Values()
+		ctx.Data["KeyFingerprint"] = fingerprint
 	case packages_model.TypeDebian:
 		distributions := make(container.Set[string])
 		components := make(container.Set[string])
@@ -211,6 +263,23 @@ func ViewPackageVersion(ctx *context.Context) {
 		ctx.Data["Distributions"] = distributions.Values()
 		ctx.Data["Components"] = components.Values()
 		ctx.Data["Architectures"] = architectures.Values()
+	case packages_model.TypeTerraform:
+		ctx.Data["RegistryHost"] = setting.Packages.RegistryHost
+
+		// modules are named "name/system", the name is used as module label in the configuration
+		if name, _, ok := strings.Cut(pd.Package.Name, "/"); ok {
+			ctx.Data["ModuleName"] = name
+		}
+
+		platforms := make([]string, 0, len(pd.Files))
+		for _, f := range pd.Files {
+			os := f.Properties.GetByName(terraform_module.PropertyOS)
+			arch := f.Properties.GetByName(terraform_module.PropertyArchitecture)
+			if os != "" && arch != "" {
+				platforms = append(platforms, os+"_"+arch)
+			}
+		}
+		ctx.Data["Platforms"] = platforms
 	}
 
 	var (
@@ -20,6 +20,7 @@ const (
 	tplSettingsPackages            base.TplName = "user/settings/packages"
 	tplSettingsPackagesRuleEdit    base.TplName = "user/settings/packages_cleanup_rules_edit"
 	tplSettingsPackagesRulePreview base.TplName = "user/settings/packages_cleanup_rules_preview"
+	tplSettingsPackagesRemoteEdit  base.TplName = "user/settings/packages_remotes_edit"
 )
 
 func Packages(ctx *context.Context) {
@@ -82,6 +83,48 @@ func PackagesRulePreview(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplSettingsPackagesRulePreview)
 }
 
+func PackagesRemoteAdd(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.SetRemoteAddContext(ctx)
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRemoteEdit)
+}
+
+func PackagesRemoteEdit(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.SetRemoteEditContext(ctx, ctx.Doer)
+
+	ctx.HTML(http.StatusOK, tplSettingsPackagesRemoteEdit)
+}
+
+func PackagesRemoteAddPost(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.PerformRemoteAddPost(
+		ctx,
+		ctx.Doer,
+		setting.AppSubURL+"/user/settings/packages",
+		tplSettingsPackagesRemoteEdit,
+	)
+}
+
+func PackagesRemoteEditPost(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("packages.title")
+	ctx.Data["PageIsSettingsPackages"] = true
+
+	shared.PerformRemoteEditPost(
+		ctx,
+		ctx.Doer,
+		setting.AppSubURL+"/user/settings/packages",
+		tplSettingsPackagesRemoteEdit,
+	)
+}
+
 func InitializeCargoIndex(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("packages.title")
 	ctx.Data["PageIsSettingsPackages"] = true
@@ -20,6 +20,7 @@ import (
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/routing"
+	"code.gitea.io/gitea/routers/api/packages/terraform"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/web/admin"
 	"code.gitea.io/gitea/routers/web/auth"
@@ -291,6 +292,7 @@ func registerRoutes(m *web.Route) {
 		m.Post("/feishu/new", web.Bind(forms.NewFeishuHookForm{}), repo.FeishuHooksNewPost)
 		m.Post("/wechatwork/new", web.Bind(forms.NewWechatWorkHookForm{}), repo.WechatworkHooksNewPost)
 		m.Post("/packagist/new", web.Bind(forms.NewPackagistHookForm{}), repo.PackagistHooksNewPost)
+		m.Post("/custom/new", web.Bind(forms.NewCustomHookForm{}), repo.CustomHooksNewPost)
 	}
 
 	addWebhookEditRoutes := func() {
@@ -305,6 +307,8 @@ func registerRoutes(m *web.Route) {
 		m.Post("/feishu/{id}", web.Bind(forms.NewFeishuHookForm{}), repo.FeishuHooksEditPost)
 		m.Post("/wechatwork/{id}", web.Bind(forms.NewWechatWorkHookForm{}), repo.WechatworkHooksEditPost)
 		m.Post("/packagist/{id}", web.Bind(forms.NewPackagistHookForm{}), repo.PackagistHooksEditPost)
+		m.Post("/custom/preview", web.Bind(forms.CustomHookPreviewForm{}), repo.CustomHookPreview)
+		m.Post("/custom/{id}", web.Bind(forms.NewCustomHookForm{}), repo.CustomHooksEditPost)
 	}
 
 	addSettingsSecretsRoutes := func() {
@@ -315,6 +319,15 @@ func registerRoutes(m *web.Route) {
 		})
 	}
 
+	addSettingsVariablesRoutes := func() {
+		m.Group("/variables", func() {
+			m.Get("", repo_setting.Variables)
+			m.Post("", web.Bind(forms.EditVariableForm{}), repo_setting.VariableCreate)
+			m.Post("/{variable_id}/edit", web.Bind(forms.EditVariableForm{}), repo_setting.VariableUpdate)
+			m.Post("/{variable_id}/delete", repo_setting.VariableDelete)
+		})
+	}
+
 	addSettingsRunnersRoutes := func() {
 		m.Group("/runners", func() {
 			m.Get("", repo_setting.Runners)
@@ -340,6 +353,7 @@ func registerRoutes(m *web.Route) {
 		m.Get("/change-password", func(ctx *context.Context) {
 			ctx.Redirect(setting.AppSubURL + "/user/settings/account")
 		})
+		m.Get("/terraform.json", packagesEnabled, terraform.ServiceDiscovery)
 	})
 
 	m.Group("/explore", func() {
@@ -483,6 +497,16 @@ func registerRoutes(m *web.Route) {
 					m.Get("/preview", user_setting.PackagesRulePreview)
 				})
 			})
+			m.Group("/remotes", func() {
+				m.Group("/add", func() {
+					m.Get("", user_setting.PackagesRemoteAdd)
+					m.Post("", web.Bind(forms.PackageRemoteForm{}), user_setting.PackagesRemoteAddPost)
+				})
+				m.Group("/{id}", func() {
+					m.Get("", user_setting.PackagesRemoteEdit)
+					m.Post("", web.Bind(forms.PackageRemoteForm{}), user_setting.PackagesRemoteEditPost)
+				})
+			})
 			m.Group("/cargo", func() {
 				m.Post("/initialize", user_setting.InitializeCargoIndex)
 				m.Post("/rebuild", user_setting.RebuildCargoIndex)
@@ -494,6 +518,7 @@ func registerRoutes(m *web.Route) {
 			m.Get("", user_setting.RedirectToDefaultSetting)
 			addSettingsRunnersRoutes()
 			addSettingsSecretsRoutes()
+			addSettingsVariablesRoutes()
 		}, actions.MustEnableActions)
 
 		m.Get("/organization", user_setting.Organization)
@@ -507,6 +532,7 @@ func registerRoutes(m *web.Route) {
 			m.Group("/{id}", func() {
 				m.Get("", repo.WebHooksEdit)
 				m.Post("/replay/{uuid}", repo.ReplayWebhook)
+				m.Post("/redeliver", repo.RedeliverFailedWebhookTasks)
 			})
 			addWebhookEditRoutes()
 		}, webhooksEnabled)
@@ -557,6 +583,11 @@ func registerRoutes(m *web.Route) {
 				m.Get("", admin.QueueManage)
 				m.Post("/set", admin.QueueSet)
 				m.Post("/remove-all-items", admin.QueueRemoveAllItems)
+				m.Post("/items/{id}/remove", admin.QueueRemoveItem)
+				m.Post("/items/{id}/dead-letter", admin.QueueMoveItemToDeadLetters)
+				m.Post("/dead-letters/requeue-all", admin.QueueRequeueAllDeadLetters)
+				m.Post("/dead-letters/{id}/requeue", admin.QueueRequeueDeadLetter)
+				m.Post("/dead-letters/{id}/remove", admin.QueueRemoveDeadLetter)
 			})
 			m.Get("/diagnosis", admin.MonitorDiagnosis)
 		})
@@ -588,6 +619,10 @@ func registerRoutes(m *web.Route) {
 		m.Group("/packages", func() {
 			m.Get("", admin.Packages)
 			m.Post("/delete", admin.DeletePackageVersion)
+			m.Group("/quotas", func() {
+				m.Combo("").Get(admin.PackageQuotas).Post(web.Bind(forms.PackageQuotaForm{}), admin.PackageQuotaPost)
+				m.Post("/delete", admin.DeletePackageQuota)
+			})
 		}, packagesEnabled)
 
 		m.Group("/hooks", func() {
@@ -596,6 +631,7 @@ func registerRoutes(m *web.Route) {
 			m.Group("/{id}", func() {
 				m.Get("", repo.WebHooksEdit)
 				m.Post("/replay/{uuid}", repo.ReplayWebhook)
+				m.Post("/redeliver", repo.RedeliverFailedWebhookTasks)
 			})
 			addWebhookEditRoutes()
 		}, webhooksEnabled)
@@ -744,6 +780,7 @@ func registerRoutes(m *web.Route) {
 					m.Group("/{id}", func() {
 						m.Get("", repo.WebHooksEdit)
 						m.Post("/replay/{uuid}", repo.ReplayWebhook)
+						m.Post("/redeliver", repo.RedeliverFailedWebhookTasks)
 					})
 					addWebhookEditRoutes()
 				}, webhooksEnabled)
@@ -760,8 +797,12 @@ func registerRoutes(m *web.Route) {
 					m.Get("", org_setting.RedirectToDefaultSetting)
 					addSettingsRunnersRoutes()
 					addSettingsSecretsRoutes()
+					addSettingsVariablesRoutes()
 				}, actions.MustEnableActions)
 
+				m.Combo("/push_rules").Get(repo_setting.PushRules).
+					Post(web.Bind(forms.PushRuleForm{}), repo_setting.PushRulesPost)
+
 				m.RouteMethods("/delete", "GET,POST", org.SettingsDelete)
 
 				m.Group("/packages", func() {
@@ -777,6 +818,16 @@ func registerRoutes(m *web.Route) {
 							m.Get("/preview", org.PackagesRulePreview)
 						})
 					})
+					m.Group("/remotes", func() {
+						m.Group("/add", func() {
+							m.Get("", org.PackagesRemoteAdd)
+							m.Post("", web.Bind(forms.PackageRemoteForm{}), org.PackagesRemoteAddPost)
+						})
+						m.Group("/{id}", func() {
+							m.Get("", org.PackagesRemoteEdit)
+							m.Post("", web.Bind(forms.PackageRemoteForm{}), org.PackagesRemoteEditPost)
+						})
+					})
 					m.Group("/cargo", func() {
 						m.Post("/initialize", org.InitializeCargoIndex)
 						m.Post("/rebuild", org.RebuildCargoIndex)
@@ -892,6 +943,9 @@ func registerRoutes(m *web.Route) {
 
 			m.Post("/rename_branch", web.Bind(forms.RenameBranchForm{}), context.RepoMustNotBeArchived(), repo.RenameBranchPost)
 
+			m.Combo("/push_rules").Get(repo_setting.PushRules).
+				Post(web.Bind(forms.PushRuleForm{}), context.RepoMustNotBeArchived(), repo_setting.PushRulesPost)
+
 			m.Group("/tags", func() {
 				m.Get("", repo.Tags)
 				m.Post("", web.Bind(forms.ProtectTagForm{}), context.RepoMustNotBeArchived(), repo.NewProtectedTagPost)
@@ -914,6 +968,7 @@ func registerRoutes(m *web.Route) {
 					m.Get("", repo.WebHooksEdit)
 					m.Post("/test", repo.TestWebhook)
 					m.Post("/replay/{uuid}", repo.ReplayWebhook)
+					m.Post("/redeliver", repo.RedeliverFailedWebhookTasks)
 				})
 				addWebhookEditRoutes()
 			}, webhooksEnabled)
@@ -941,6 +996,7 @@ func registerRoutes(m *web.Route) {
 				m.Get("", repo_setting.RedirectToDefaultSetting)
 				addSettingsRunnersRoutes()
 				addSettingsSecretsRoutes()
+				addSettingsVariablesRoutes()
 			}, actions.MustEnableActions)
 			m.Post("/migrate/cancel", repo.MigrateCancelPost) // this handler must be under "settings", otherwise this incomplete repo can't be accessed
 		}, ctxDataSet("PageIsRepoSettings", true, "LFSStartServer", setting.LFS.StartServer))
@@ -1185,6 +1241,7 @@ func registerRoutes(m *web.Route) {
 
 		m.Group("/actions", func() {
 			m.Get("", actions.List)
+			m.Post("/run", reqRepoActionsWriter, actions.Run)
 
 			m.Group("/runs/{run}", func() {
 				m.Combo("").
@@ -1201,6 +1258,7 @@ func registerRoutes(m *web.Route) {
 				m.Post("/artifacts", actions.ArtifactsView)
 				m.Get("/artifacts/{id}", actions.ArtifactsDownloadView)
 				m.Post("/rerun", reqRepoActionsWriter, actions.RerunAll)
+				m.Post("/rerun-failed", reqRepoActionsWriter, actions.RerunFailed)
 			})
 		}, reqRepoActionsReader, actions.MustEnableActions)
 
@@ -1269,6 +1327,7 @@ func registerRoutes(m *web.Route) {
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), web.Bind(forms.MergePullRequestForm{}), repo.MergePullRequest)
 			m.Post("/cancel_auto_merge", context.RepoMustNotBeArchived(), repo.CancelAutoMergePullRequest)
+			m.Post("/remove_from_merge_queue", context.RepoMustNotBeArchived(), repo.RemoveFromMergeQueue)
 			m.Post("/update", repo.UpdatePullRequest)
 			m.Post("/set_allow_maintainer_edit", web.Bind(forms.UpdateAllowEditsForm{}), repo.SetAllowEdits)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
@@ -1279,6 +1338,7 @@ func registerRoutes(m *web.Route) {
 					m.Post("/comments", web.Bind(forms.CodeCommentForm{}), repo.CreateCodeComment)
 					m.Post("/submit", web.Bind(forms.SubmitReviewForm{}), repo.SubmitReview)
 				}, context.RepoMustNotBeArchived())
+				m.Post("/suggestions/apply", context.RepoMustNotBeArchived(), web.Bind(forms.ApplySuggestionsForm{}), repo.ApplySuggestions)
 			})
 		}, repo.MustAllowPulls)
 
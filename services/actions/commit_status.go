@@ -15,6 +15,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/automerge"
 
 	"github.com/nektos/act/pkg/jobparser"
 )
@@ -130,6 +131,10 @@ func createCommitStatus(ctx context.Context, job *actions_model.ActionRunJob) er
 		return fmt.Errorf("NewCommitStatus: %w", err)
 	}
 
+	if err := automerge.ProcessMergeQueuesByCommitID(ctx, sha, repo); err != nil {
+		return fmt.Errorf("ProcessMergeQueuesByCommitID: %w", err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,216 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/nektos/act/pkg/model"
+)
+
+// insertRun inserts the run and its jobs with the evaluated concurrency groups of the workflow content,
+// the other runs and jobs of the same groups are cancelled if they are pending or if cancel-in-progress is set.
+// The Repo and the TriggerUser of the run have to be loaded.
+func insertRun(ctx context.Context, run *actions_model.ActionRun, content []byte, jobs []*jobparser.SingleWorkflow) error {
+	runConcurrency, jobConcurrencies, err := actions_module.GetConcurrencyFromContent(content)
+	if err != nil {
+		return fmt.Errorf("GetConcurrencyFromContent: %w", err)
+	}
+
+	gitCtx, inputs := generateConcurrencyContext(run)
+	if runConcurrency != nil {
+		group, cancel, err := runConcurrency.Evaluate(gitCtx, inputs, nil)
+		if err != nil {
+			return err
+		}
+		run.ConcurrencyGroup, _ = util.SplitStringAtByteN(group, 255)
+		run.ConcurrencyCancel = cancel
+	}
+
+	var runJobs, cancelledJobs actions_model.ActionJobList
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := actions_model.InsertRun(ctx, run, jobs); err != nil {
+			return fmt.Errorf("InsertRun: %w", err)
+		}
+
+		runJobs, _, err = actions_model.FindRunJobs(ctx, actions_model.FindRunJobOptions{RunID: run.ID})
+		if err != nil {
+			return fmt.Errorf("FindRunJobs: %w", err)
+		}
+		for _, job := range runJobs {
+			concurrency, ok := jobConcurrencies[job.JobID]
+			if !ok {
+				continue
+			}
+			group, cancel, err := concurrency.Evaluate(gitCtx, inputs, getJobMatrix(job))
+			if err != nil {
+				return fmt.Errorf("job %q: %w", job.JobID, err)
+			}
+			job.ConcurrencyGroup, _ = util.SplitStringAtByteN(group, 255)
+			job.ConcurrencyCancel = cancel
+			// don't update the status of the job, the run would be treated as running otherwise
+			if _, err := actions_model.UpdateRunJob(ctx, &actions_model.ActionRunJob{
+				ID:                job.ID,
+				ConcurrencyGroup:  job.ConcurrencyGroup,
+				ConcurrencyCancel: job.ConcurrencyCancel,
+			}, nil, "concurrency_group", "concurrency_cancel"); err != nil {
+				return err
+			}
+		}
+
+		cancelledJobs, err = cancelConcurrentRuns(ctx, run)
+		if err != nil {
+			return err
+		}
+		for _, job := range runJobs {
+			if job.Status == actions_model.StatusWaiting {
+				cancelled, err := cancelConcurrentJobs(ctx, job)
+				if err != nil {
+					return err
+				}
+				cancelledJobs = append(cancelledJobs, cancelled...)
+			}
+		}
+		return nil
+	}); err != nil {
+		return err
+	}
+
+	CreateCommitStatus(ctx, cancelledJobs...)
+	CreateCommitStatus(ctx, runJobs...)
+	return nil
+}
+
+// cancelConcurrentRuns cancels the other pending runs of the concurrency group of the run,
+// the ones in progress are also cancelled if cancel-in-progress is set, otherwise the jobs of the run won't be picked until they are done.
+// It returns the cancelled jobs, their commit statuses have to be created after the transaction has been committed.
+func cancelConcurrentRuns(ctx context.Context, run *actions_model.ActionRun) (actions_model.ActionJobList, error) {
+	if run.ConcurrencyGroup == "" {
+		return nil, nil
+	}
+
+	runs, _, err := actions_model.FindRuns(ctx, actions_model.FindRunOptions{
+		RepoID:           run.RepoID,
+		ConcurrencyGroup: run.ConcurrencyGroup,
+		Statuses:         []actions_model.Status{actions_model.StatusWaiting, actions_model.StatusBlocked, actions_model.StatusRunning},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FindRuns: %w", err)
+	}
+	var cancelled actions_model.ActionJobList
+	for _, other := range runs {
+		if other.ID == run.ID || (other.Status == actions_model.StatusRunning && !run.ConcurrencyCancel) {
+			continue
+		}
+		jobs, err := actions_model.GetRunJobsByRunID(ctx, other.ID)
+		if err != nil {
+			return nil, fmt.Errorf("GetRunJobsByRunID: %w", err)
+		}
+		if err := actions_model.CancelJobs(ctx, jobs); err != nil {
+			return nil, fmt.Errorf("CancelJobs: %w", err)
+		}
+		cancelled = append(cancelled, jobs...)
+	}
+	return cancelled, nil
+}
+
+// cancelConcurrentJobs cancels the other waiting jobs of the concurrency group of the job which has just become waiting,
+// the ones in progress are also cancelled if cancel-in-progress is set, otherwise the job won't be picked until they are done.
+// It returns the cancelled jobs, their commit statuses have to be created after the transaction has been committed.
+func cancelConcurrentJobs(ctx context.Context, job *actions_model.ActionRunJob) (actions_model.ActionJobList, error) {
+	if job.ConcurrencyGroup == "" {
+		return nil, nil
+	}
+
+	statuses := []actions_model.Status{actions_model.StatusWaiting}
+	if job.ConcurrencyCancel {
+		statuses = append(statuses, actions_model.StatusRunning)
+	}
+	jobs, _, err := actions_model.FindRunJobs(ctx, actions_model.FindRunJobOptions{
+		RepoID:           job.RepoID,
+		ConcurrencyGroup: job.ConcurrencyGroup,
+		Statuses:         statuses,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("FindRunJobs: %w", err)
+	}
+	others := make(actions_model.ActionJobList, 0, len(jobs))
+	for _, other := range jobs {
+		if other.ID != job.ID {
+			others = append(others, other)
+		}
+	}
+	if err := actions_model.CancelJobs(ctx, others); err != nil {
+		return nil, fmt.Errorf("CancelJobs: %w", err)
+	}
+	return others, nil
+}
+
+// generateConcurrencyContext returns the github and inputs contexts which are available when the run is created
+func generateConcurrencyContext(run *actions_model.ActionRun) (*model.GithubContext, map[string]interface{}) {
+	event := map[string]interface{}{}
+	_ = json.Unmarshal([]byte(run.EventPayload), &event)
+
+	baseRef := ""
+	headRef := ""
+	if pullPayload, err := run.GetPullRequestEventPayload(); err == nil && pullPayload.PullRequest != nil && pullPayload.PullRequest.Base != nil && pullPayload.PullRequest.Head != nil {
+		baseRef = pullPayload.PullRequest.Base.Ref
+		headRef = pullPayload.PullRequest.Head.Ref
+	}
+
+	var inputs map[string]interface{}
+	if run.Event == webhook_module.HookEventWorkflowDispatch {
+		inputs, _ = event["inputs"].(map[string]interface{})
+	}
+
+	refName := git.RefName(run.Ref)
+	return &model.GithubContext{
+		Event:           event,
+		Workflow:        run.WorkflowID,
+		Actor:           run.TriggerUser.Name,
+		Repository:      run.Repo.OwnerName + "/" + run.Repo.Name,
+		EventName:       run.Event.Event(),
+		Sha:             run.CommitSHA,
+		Ref:             run.Ref,
+		RefName:         refName.ShortName(),
+		RefType:         refName.RefType(),
+		HeadRef:         headRef,
+		BaseRef:         baseRef,
+		RepositoryOwner: run.Repo.OwnerName,
+	}, inputs
+}
+
+// getJobMatrix returns the matrix values of the job, every job of a run has a single combination of the matrix
+func getJobMatrix(job *actions_model.ActionRunJob) map[string]interface{} {
+	workflows, err := jobparser.Parse(job.WorkflowPayload)
+	if err != nil || len(workflows) != 1 {
+		return nil
+	}
+	_, workflowJob := workflows[0].Job()
+	if workflowJob == nil {
+		return nil
+	}
+
+	var values map[string][]interface{}
+	if err := workflowJob.Strategy.RawMatrix.Decode(&values); err != nil {
+		return nil
+	}
+	matrix := make(map[string]interface{}, len(values))
+	for k, v := range values {
+		if len(v) > 0 {
+			matrix[k] = v[0]
+		}
+	}
+	return matrix
+}
@@ -0,0 +1,147 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"fmt"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/nektos/act/pkg/jobparser"
+	"github.com/stretchr/testify/assert"
+)
+
+const testConcurrencySHA = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+func insertTestRun(t *testing.T, content string) *actions_model.ActionRunJob {
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	payload, err := json.Marshal(&api.PushPayload{
+		Ref:        "refs/heads/master",
+		HeadCommit: &api.PayloadCommit{ID: testConcurrencySHA},
+	})
+	assert.NoError(t, err)
+	jobs, err := jobparser.Parse([]byte(content))
+	assert.NoError(t, err)
+
+	run := &actions_model.ActionRun{
+		Title:         "ci",
+		RepoID:        repo.ID,
+		Repo:          repo,
+		OwnerID:       repo.OwnerID,
+		WorkflowID:    "ci.yml",
+		TriggerUserID: doer.ID,
+		TriggerUser:   doer,
+		Ref:           "refs/heads/master",
+		CommitSHA:     testConcurrencySHA,
+		Event:         webhook_module.HookEventPush,
+		EventPayload:  string(payload),
+		Status:        actions_model.StatusWaiting,
+	}
+	assert.NoError(t, insertRun(db.DefaultContext, run, []byte(content), jobs))
+
+	runJobs, err := actions_model.GetRunJobsByRunID(db.DefaultContext, run.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, runJobs, 1) {
+		return runJobs[0]
+	}
+	return nil
+}
+
+func startTestJob(t *testing.T, job *actions_model.ActionRunJob) {
+	job.Status = actions_model.StatusRunning
+	_, err := actions_model.UpdateRunJob(db.DefaultContext, job, nil, "status")
+	assert.NoError(t, err)
+}
+
+func assertJobStatus(t *testing.T, job *actions_model.ActionRunJob, status actions_model.Status) {
+	assert.Equal(t, status, unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{ID: job.ID}).Status)
+}
+
+func TestInsertRunCancelConcurrentRuns(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	const workflow = `name: ci
+on: push
+concurrency:
+  group: ci-${{ github.ref }}
+  cancel-in-progress: %s
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo build
+`
+	withCancel := func(cancel string) string {
+		return fmt.Sprintf(workflow, cancel)
+	}
+
+	running := insertTestRun(t, withCancel("false"))
+	startTestJob(t, running)
+
+	// the pending run of the group is replaced, the one in progress is kept
+	waiting := insertTestRun(t, withCancel("false"))
+	latest := insertTestRun(t, withCancel("false"))
+	assertJobStatus(t, running, actions_model.StatusRunning)
+	assertJobStatus(t, waiting, actions_model.StatusCancelled)
+	assertJobStatus(t, latest, actions_model.StatusWaiting)
+
+	// the commit status of the cancelled job has been created after the transaction
+	unittest.AssertExistsAndLoadBean(t, &git_model.CommitStatus{
+		RepoID:      1,
+		SHA:         testConcurrencySHA,
+		State:       api.CommitStatusWarning,
+		Description: "Has been cancelled",
+	})
+
+	// cancel-in-progress also cancels the running job
+	cancelling := insertTestRun(t, withCancel("true"))
+	assertJobStatus(t, running, actions_model.StatusCancelled)
+	assertJobStatus(t, latest, actions_model.StatusCancelled)
+	assertJobStatus(t, cancelling, actions_model.StatusWaiting)
+	// the status isn't repeated for the second cancelled job, all the runs share the same context
+	assert.EqualValues(t, 2, unittest.GetCount(t, &git_model.CommitStatus{
+		RepoID:      1,
+		SHA:         testConcurrencySHA,
+		Description: "Has been cancelled",
+	}))
+}
+
+func TestInsertRunCancelConcurrentJobs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	const workflow = `name: deploy
+on: push
+jobs:
+  deploy:
+    runs-on: ubuntu-latest
+    concurrency:
+      group: deploy-production
+      cancel-in-progress: true
+    steps:
+      - run: echo deploy
+`
+	running := insertTestRun(t, workflow)
+	startTestJob(t, running)
+
+	latest := insertTestRun(t, workflow)
+	assertJobStatus(t, running, actions_model.StatusCancelled)
+	assertJobStatus(t, latest, actions_model.StatusWaiting)
+	unittest.AssertExistsAndLoadBean(t, &git_model.CommitStatus{
+		RepoID:      1,
+		SHA:         testConcurrencySHA,
+		State:       api.CommitStatusWarning,
+		Description: "Has been cancelled",
+	})
+}
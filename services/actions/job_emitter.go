@@ -48,6 +48,7 @@ func checkJobsOfRun(ctx context.Context, runID int64) error {
 	if err != nil {
 		return err
 	}
+	var cancelledJobs actions_model.ActionJobList
 	if err := db.WithTx(ctx, func(ctx context.Context) error {
 		idToJobs := make(map[string][]*actions_model.ActionRunJob, len(jobs))
 		for _, job := range jobs {
@@ -63,12 +64,20 @@ func checkJobsOfRun(ctx context.Context, runID int64) error {
 				} else if n != 1 {
 					return fmt.Errorf("no affected for updating blocked job %v", job.ID)
 				}
+				if job.Status == actions_model.StatusWaiting {
+					cancelled, err := cancelConcurrentJobs(ctx, job)
+					if err != nil {
+						return err
+					}
+					cancelledJobs = append(cancelledJobs, cancelled...)
+				}
 			}
 		}
 		return nil
 	}); err != nil {
 		return err
 	}
+	CreateCommitStatus(ctx, cancelledJobs...)
 	CreateCommitStatus(ctx, jobs...)
 	return nil
 }
@@ -22,6 +22,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	webhook_module "code.gitea.io/gitea/modules/webhook"
 	"code.gitea.io/gitea/services/convert"
+	pull_service "code.gitea.io/gitea/services/pull"
 
 	"github.com/nektos/act/pkg/jobparser"
 )
@@ -142,7 +143,14 @@ func notify(ctx context.Context, input *notifyInput) error {
 		return fmt.Errorf("gitRepo.GetCommit: %w", err)
 	}
 
-	workflows, err := actions_module.DetectWorkflows(commit, input.Event, input.Payload)
+	if input.Event == webhook_module.HookEventPush && git.RefName(input.Ref).BranchName() == input.Repo.DefaultBranch {
+		// the schedules always follow the workflows of the default branch
+		if err := handleSchedules(ctx, input.Doer, input.Repo, commit); err != nil {
+			log.Error("handleSchedules: %v", err)
+		}
+	}
+
+	workflows, err := actions_module.DetectWorkflows(commit, input.Event, detectPayload(input))
 	if err != nil {
 		return fmt.Errorf("DetectWorkflows: %w", err)
 	}
@@ -176,9 +184,11 @@ func notify(ctx context.Context, input *notifyInput) error {
 		run := &actions_model.ActionRun{
 			Title:             strings.SplitN(commit.CommitMessage, "\n", 2)[0],
 			RepoID:            input.Repo.ID,
+			Repo:              input.Repo,
 			OwnerID:           input.Repo.OwnerID,
 			WorkflowID:        id,
 			TriggerUserID:     input.Doer.ID,
+			TriggerUser:       input.Doer,
 			Ref:               ref,
 			CommitSHA:         commit.ID.String(),
 			IsForkPullRequest: isForkPullRequest,
@@ -198,20 +208,31 @@ func notify(ctx context.Context, input *notifyInput) error {
 			log.Error("jobparser.Parse: %v", err)
 			continue
 		}
-		if err := actions_model.InsertRun(ctx, run, jobs); err != nil {
-			log.Error("InsertRun: %v", err)
+		if err := insertRun(ctx, run, content, jobs); err != nil {
+			log.Error("insertRun: %v", err)
 			continue
 		}
-		if jobs, _, err := actions_model.FindRunJobs(ctx, actions_model.FindRunJobOptions{RunID: run.ID}); err != nil {
-			log.Error("FindRunJobs: %v", err)
-		} else {
-			CreateCommitStatus(ctx, jobs...)
-		}
-
 	}
 	return nil
 }
 
+// detectPayload returns the payload the workflows get matched against.
+// The queue commits of a merge queue are tested like a push to the base branch,
+// otherwise the required status checks of the base branch would never report on them.
+func detectPayload(input *notifyInput) api.Payloader {
+	pushPayload, ok := input.Payload.(*api.PushPayload)
+	if !ok || input.Event != webhook_module.HookEventPush {
+		return input.Payload
+	}
+	baseBranch, ok := pull_service.MergeQueueBaseBranch(git.RefName(pushPayload.Ref).BranchName())
+	if !ok {
+		return input.Payload
+	}
+	payload := *pushPayload
+	payload.Ref = git.RefNameFromBranch(baseBranch).String()
+	return &payload
+}
+
 func newNotifyInputFromIssue(issue *issues_model.Issue, event webhook_module.HookEventType) *notifyInput {
 	return newNotifyInput(issue.Repo, issue.Poster, event)
 }
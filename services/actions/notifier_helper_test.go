@@ -0,0 +1,29 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+
+	api "code.gitea.io/gitea/modules/structs"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetectPayload(t *testing.T) {
+	input := &notifyInput{
+		Event:   webhook_module.HookEventPush,
+		Payload: &api.PushPayload{Ref: "refs/heads/gitea-merge-queue/release/v1.21/pr-3"},
+	}
+	assert.Equal(t, "refs/heads/release/v1.21", detectPayload(input).(*api.PushPayload).Ref)
+	// the payload of the run keeps the queue branch
+	assert.Equal(t, "refs/heads/gitea-merge-queue/release/v1.21/pr-3", input.Payload.(*api.PushPayload).Ref)
+
+	input.Payload = &api.PushPayload{Ref: "refs/heads/main"}
+	assert.Same(t, input.Payload, detectPayload(input))
+
+	input.Payload = &api.PushPayload{Ref: "refs/tags/gitea-merge-queue/main/pr-3"}
+	assert.Same(t, input.Payload, detectPayload(input))
+}
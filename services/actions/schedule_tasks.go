@@ -0,0 +1,221 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	perm_model "code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/convert"
+
+	"github.com/nektos/act/pkg/jobparser"
+)
+
+// DetectAndHandleSchedules replaces the schedules of the repository with the ones of the workflows of its default branch
+func DetectAndHandleSchedules(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) error {
+	if unit_model.TypeActions.UnitGlobalDisabled() || repo.IsEmpty {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("git.OpenRepository: %w", err)
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		return fmt.Errorf("gitRepo.GetBranchCommit: %w", err)
+	}
+
+	return handleSchedules(ctx, doer, repo, commit)
+}
+
+// handleSchedules replaces the schedules of the repository with the ones of the workflows of the commit,
+// archived and mirror repositories don't have any schedules
+func handleSchedules(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, commit *git.Commit) error {
+	if err := actions_model.DeleteScheduleTaskByRepo(ctx, repo.ID); err != nil {
+		return fmt.Errorf("DeleteScheduleTaskByRepo: %w", err)
+	}
+	if repo.IsArchived || repo.IsMirror {
+		return nil
+	}
+
+	workflows, err := actions_module.DetectScheduledWorkflows(commit)
+	if err != nil {
+		return fmt.Errorf("DetectScheduledWorkflows: %w", err)
+	}
+
+	now := time.Now().UTC()
+	rows := make([]*actions_model.ActionSchedule, 0, len(workflows))
+	for id, workflow := range workflows {
+		specs := make([]string, 0, len(workflow.Specs))
+		for _, spec := range workflow.Specs {
+			schedule, err := (&actions_model.ActionScheduleSpec{Spec: spec}).Parse()
+			if err != nil {
+				log.Warn("ignore invalid cron expression %q of workflow %q in repo %d: %v", spec, id, repo.ID, err)
+				continue
+			}
+			if schedule.Next(now).IsZero() {
+				log.Warn("ignore cron expression %q of workflow %q in repo %d which never fires", spec, id, repo.ID)
+				continue
+			}
+			specs = append(specs, spec)
+		}
+		if len(specs) == 0 {
+			continue
+		}
+		rows = append(rows, &actions_model.ActionSchedule{
+			Title:         strings.SplitN(commit.CommitMessage, "\n", 2)[0],
+			Specs:         specs,
+			RepoID:        repo.ID,
+			OwnerID:       repo.OwnerID,
+			WorkflowID:    id,
+			TriggerUserID: doer.ID,
+			Ref:           git.RefNameFromBranch(repo.DefaultBranch).String(),
+			CommitSHA:     commit.ID.String(),
+			Content:       workflow.Content,
+		})
+	}
+
+	return actions_model.CreateScheduleTask(ctx, rows)
+}
+
+// StartScheduleTasks creates the runs of all the schedule specs which are due
+func StartScheduleTasks(ctx context.Context) error {
+	// cron expressions of workflows are always evaluated in UTC like GitHub does
+	now := time.Now().UTC()
+	for {
+		// the next time of every handled spec is moved to the future, so always fetch the first page
+		specs, _, err := actions_model.FindSpecs(ctx, actions_model.FindSpecOptions{
+			ListOptions: db.ListOptions{
+				Page:     1,
+				PageSize: 50,
+			},
+			Next: now.Unix(),
+		})
+		if err != nil {
+			return fmt.Errorf("FindSpecs: %w", err)
+		}
+		if len(specs) == 0 {
+			return nil
+		}
+
+		for _, spec := range specs {
+			select {
+			case <-ctx.Done():
+				return db.ErrCancelledf("While starting schedule tasks")
+			default:
+			}
+			if err := startScheduleTask(ctx, spec, now); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func startScheduleTask(ctx context.Context, spec *actions_model.ActionScheduleSpec, now time.Time) error {
+	schedule, err := spec.Parse()
+	if err != nil || spec.Schedule == nil {
+		// the spec can't be triggered anymore, so it's safe to remove the whole schedule of the repository
+		log.Warn("remove the schedules of repo %d because of an invalid spec %d: %v", spec.RepoID, spec.ID, err)
+		return actions_model.DeleteScheduleTaskByRepo(ctx, spec.RepoID)
+	}
+
+	repo, err := repo_model.GetRepositoryByID(ctx, spec.RepoID)
+	if repo_model.IsErrRepoNotExist(err) {
+		return actions_model.DeleteScheduleTaskByRepo(ctx, spec.RepoID)
+	} else if err != nil {
+		return fmt.Errorf("GetRepositoryByID: %w", err)
+	}
+	if repo.IsArchived || repo.IsMirror {
+		log.Trace("remove the schedules of repo %d because it's an archived or a mirror repository", repo.ID)
+		return actions_model.DeleteScheduleTaskByRepo(ctx, repo.ID)
+	}
+
+	next := schedule.Next(now)
+	if next.IsZero() {
+		// the spec would stay due forever and be triggered on every pass
+		log.Warn("remove the schedules of repo %d because spec %d never fires", spec.RepoID, spec.ID)
+		return actions_model.DeleteScheduleTaskByRepo(ctx, spec.RepoID)
+	}
+
+	spec.Prev = spec.Next
+	spec.Next = timeutil.TimeStamp(next.Unix())
+	if err := actions_model.UpdateScheduleSpec(ctx, spec, "prev", "next"); err != nil {
+		return fmt.Errorf("UpdateScheduleSpec: %w", err)
+	}
+
+	if unit_model.TypeActions.UnitGlobalDisabled() {
+		return nil
+	}
+	if err := repo.LoadUnits(ctx); err != nil {
+		return fmt.Errorf("repo.LoadUnits: %w", err)
+	} else if !repo.UnitEnabled(ctx, unit_model.TypeActions) {
+		return nil
+	}
+
+	// a failed run of one schedule shouldn't block the other ones
+	if err := createScheduleRun(ctx, repo, spec); err != nil {
+		log.Error("create run of schedule %d of repo %d: %v", spec.ScheduleID, repo.ID, err)
+	}
+	return nil
+}
+
+func createScheduleRun(ctx context.Context, repo *repo_model.Repository, spec *actions_model.ActionScheduleSpec) error {
+	schedule := spec.Schedule
+
+	sender, err := user_model.GetPossibleUserByID(ctx, schedule.TriggerUserID)
+	if user_model.IsErrUserNotExist(err) {
+		// the user who pushed the workflow has been deleted in the meantime
+		sender = user_model.NewGhostUser()
+	} else if err != nil {
+		return fmt.Errorf("GetPossibleUserByID: %w", err)
+	}
+
+	p, err := json.Marshal(&api.SchedulePayload{
+		Schedule:   spec.Spec,
+		Repository: convert.ToRepo(ctx, repo, perm_model.AccessModeNone),
+		Sender:     convert.ToUser(ctx, sender, nil),
+	})
+	if err != nil {
+		return fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	jobs, err := jobparser.Parse(schedule.Content)
+	if err != nil {
+		return fmt.Errorf("jobparser.Parse: %w", err)
+	}
+
+	run := &actions_model.ActionRun{
+		Title:         schedule.Title,
+		RepoID:        repo.ID,
+		Repo:          repo,
+		OwnerID:       repo.OwnerID,
+		WorkflowID:    schedule.WorkflowID,
+		TriggerUserID: sender.ID,
+		TriggerUser:   sender,
+		Ref:           schedule.Ref,
+		CommitSHA:     schedule.CommitSHA,
+		Event:         webhook_module.HookEventSchedule,
+		EventPayload:  string(p),
+		Status:        actions_model.StatusWaiting,
+	}
+	return insertRun(ctx, run, schedule.Content, jobs)
+}
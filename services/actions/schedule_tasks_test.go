@@ -0,0 +1,161 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"testing"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testScheduleWorkflow = `name: nightly
+on:
+  schedule:
+    - cron: '0 0 * * *'
+jobs:
+  build:
+    runs-on: ubuntu-latest
+    steps:
+      - run: echo nightly
+`
+
+func createTestSchedule(t *testing.T, repoID, triggerUserID int64) *actions_model.ActionScheduleSpec {
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: repoID})
+	assert.NoError(t, actions_model.CreateScheduleTask(db.DefaultContext, []*actions_model.ActionSchedule{
+		{
+			Title:         "nightly",
+			Specs:         []string{"0 0 * * *"},
+			RepoID:        repo.ID,
+			OwnerID:       repo.OwnerID,
+			WorkflowID:    "nightly.yml",
+			TriggerUserID: triggerUserID,
+			Ref:           "refs/heads/" + repo.DefaultBranch,
+			CommitSHA:     "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+			Content:       []byte(testScheduleWorkflow),
+		},
+	}))
+
+	specs, _, err := actions_model.FindSpecs(db.DefaultContext, actions_model.FindSpecOptions{RepoID: repo.ID})
+	assert.NoError(t, err)
+	if assert.Len(t, specs, 1) {
+		return specs[0]
+	}
+	return nil
+}
+
+func enableActionsUnit(t *testing.T, repoID int64) {
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.RepoUnit{
+		RepoID: repoID,
+		Type:   unit_model.TypeActions,
+		Config: new(repo_model.UnitConfig),
+	}))
+}
+
+func TestStartScheduleTask(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	enableActionsUnit(t, 1)
+
+	spec := createTestSchedule(t, 1, 2)
+	next := spec.Next
+	now := next.AsTime().UTC().Add(time.Minute)
+	assert.NoError(t, startScheduleTask(db.DefaultContext, spec, now))
+
+	spec = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionScheduleSpec{ID: spec.ID})
+	assert.Equal(t, next, spec.Prev)
+	assert.Equal(t, next.Add(24*60*60), spec.Next)
+
+	run := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{RepoID: 1, Event: webhook_module.HookEventSchedule})
+	assert.Equal(t, "nightly", run.Title)
+	assert.Equal(t, "nightly.yml", run.WorkflowID)
+	assert.EqualValues(t, 2, run.TriggerUserID)
+	assert.Equal(t, "refs/heads/master", run.Ref)
+	assert.Equal(t, actions_model.StatusWaiting, run.Status)
+	unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRunJob{RunID: run.ID, JobID: "build"})
+}
+
+func TestStartScheduleTaskActionsDisabled(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	spec := createTestSchedule(t, 1, 2)
+	next := spec.Next
+	assert.NoError(t, startScheduleTask(db.DefaultContext, spec, next.AsTime().UTC().Add(time.Minute)))
+
+	// the spec is moved forward even though no run is created
+	spec = unittest.AssertExistsAndLoadBean(t, &actions_model.ActionScheduleSpec{ID: spec.ID})
+	assert.Equal(t, next, spec.Prev)
+	unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: 1, Event: webhook_module.HookEventSchedule})
+}
+
+func TestStartScheduleTaskDeletesSchedules(t *testing.T) {
+	t.Run("InvalidSpec", func(t *testing.T) {
+		assert.NoError(t, unittest.PrepareTestDatabase())
+
+		spec := createTestSchedule(t, 1, 2)
+		spec.Spec = "invalid"
+		assert.NoError(t, startScheduleTask(db.DefaultContext, spec, time.Now().UTC()))
+		unittest.AssertNotExistsBean(t, &actions_model.ActionSchedule{RepoID: 1})
+		unittest.AssertNotExistsBean(t, &actions_model.ActionScheduleSpec{RepoID: 1})
+	})
+
+	t.Run("NeverFiringSpec", func(t *testing.T) {
+		assert.NoError(t, unittest.PrepareTestDatabase())
+
+		spec := createTestSchedule(t, 1, 2)
+		spec.Spec = "0 0 30 2 *"
+		assert.NoError(t, startScheduleTask(db.DefaultContext, spec, time.Now().UTC()))
+		unittest.AssertNotExistsBean(t, &actions_model.ActionSchedule{RepoID: 1})
+		unittest.AssertNotExistsBean(t, &actions_model.ActionScheduleSpec{RepoID: 1})
+		unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: 1})
+	})
+
+	t.Run("ArchivedRepository", func(t *testing.T) {
+		assert.NoError(t, unittest.PrepareTestDatabase())
+
+		spec := createTestSchedule(t, 51, 2)
+		assert.NoError(t, startScheduleTask(db.DefaultContext, spec, time.Now().UTC()))
+		unittest.AssertNotExistsBean(t, &actions_model.ActionSchedule{RepoID: 51})
+		unittest.AssertNotExistsBean(t, &actions_model.ActionScheduleSpec{RepoID: 51})
+		unittest.AssertNotExistsBean(t, &actions_model.ActionRun{RepoID: 51})
+	})
+}
+
+func TestCreateScheduleTaskNeverFiringSpec(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	err := actions_model.CreateScheduleTask(db.DefaultContext, []*actions_model.ActionSchedule{
+		{
+			Title:      "never",
+			Specs:      []string{"0 0 30 2 *"},
+			RepoID:     1,
+			OwnerID:    2,
+			WorkflowID: "never.yml",
+			Content:    []byte(testScheduleWorkflow),
+		},
+	})
+	assert.ErrorIs(t, err, util.ErrInvalidArgument)
+	unittest.AssertNotExistsBean(t, &actions_model.ActionSchedule{RepoID: 1})
+	unittest.AssertNotExistsBean(t, &actions_model.ActionScheduleSpec{RepoID: 1})
+}
+
+func TestCreateScheduleRunDeletedTriggerUser(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	spec := createTestSchedule(t, repo.ID, 9999)
+	assert.NoError(t, createScheduleRun(db.DefaultContext, repo, spec))
+
+	run := unittest.AssertExistsAndLoadBean(t, &actions_model.ActionRun{RepoID: repo.ID, Event: webhook_module.HookEventSchedule})
+	assert.Equal(t, user_model.NewGhostUser().ID, run.TriggerUserID)
+	assert.Contains(t, run.EventPayload, `"schedule":"0 0 * * *"`)
+}
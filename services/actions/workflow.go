@@ -0,0 +1,139 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	actions_module "code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+	"code.gitea.io/gitea/services/convert"
+
+	"github.com/nektos/act/pkg/jobparser"
+)
+
+// resolveDispatchRef returns the full reference name of a branch or a tag given by its short or full name
+func resolveDispatchRef(gitRepo *git.Repository, ref string) (git.RefName, error) {
+	refName := git.RefName(ref)
+	switch {
+	case refName.IsBranch() && gitRepo.IsBranchExist(refName.BranchName()),
+		refName.IsTag() && gitRepo.IsTagExist(refName.TagName()):
+		return refName, nil
+	case gitRepo.IsBranchExist(ref):
+		return git.RefNameFromBranch(ref), nil
+	case gitRepo.IsTagExist(ref):
+		return git.RefNameFromTag(ref), nil
+	}
+	return "", util.NewNotExistErrorf("branch or tag %q does not exist", ref)
+}
+
+// GetWorkflowDispatch returns the content and the `workflow_dispatch` trigger of a workflow at the given commit,
+// the trigger is nil if the workflow can't be run manually
+func GetWorkflowDispatch(commit *git.Commit, workflowID string) ([]byte, *actions_module.WorkflowDispatch, error) {
+	entries, err := actions_module.ListWorkflows(commit)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, entry := range entries {
+		if entry.Name() != workflowID {
+			continue
+		}
+		content, err := actions_module.GetContentFromEntry(entry)
+		if err != nil {
+			return nil, nil, err
+		}
+		dispatch, err := actions_module.GetWorkflowDispatchFromContent(content)
+		if err != nil {
+			return nil, nil, util.NewInvalidArgumentErrorf("invalid workflow %q: %v", workflowID, err)
+		}
+		return content, dispatch, nil
+	}
+	return nil, nil, util.NewNotExistErrorf("workflow %q does not exist", workflowID)
+}
+
+// DispatchWorkflow creates a run of a workflow supporting the `workflow_dispatch` event at the given branch or tag,
+// the inputs are validated against the ones declared by the workflow
+func DispatchWorkflow(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, gitRepo *git.Repository, workflowID, ref string, inputs map[string]string) (*actions_model.ActionRun, error) {
+	if unit_model.TypeActions.UnitGlobalDisabled() {
+		return nil, util.NewPermissionDeniedErrorf("actions are disabled")
+	}
+	if err := repo.LoadUnits(ctx); err != nil {
+		return nil, fmt.Errorf("repo.LoadUnits: %w", err)
+	} else if !repo.UnitEnabled(ctx, unit_model.TypeActions) {
+		return nil, util.NewPermissionDeniedErrorf("actions are disabled for the repository")
+	}
+
+	if ref == "" {
+		ref = repo.DefaultBranch
+	}
+	refName, err := resolveDispatchRef(gitRepo, ref)
+	if err != nil {
+		return nil, err
+	}
+	commit, err := gitRepo.GetCommit(refName.String())
+	if err != nil {
+		return nil, fmt.Errorf("gitRepo.GetCommit: %w", err)
+	}
+
+	content, dispatch, err := GetWorkflowDispatch(commit, workflowID)
+	if err != nil {
+		return nil, err
+	}
+	if dispatch == nil {
+		return nil, util.NewInvalidArgumentErrorf("workflow %q does not have a workflow_dispatch trigger", workflowID)
+	}
+	inputs, err = dispatch.ValidateInputs(inputs)
+	if err != nil {
+		return nil, err
+	}
+
+	mode, _ := access_model.AccessLevel(ctx, doer, repo)
+	p, err := json.Marshal(&api.WorkflowDispatchPayload{
+		Workflow:   workflowID,
+		Ref:        refName.String(),
+		Inputs:     inputs,
+		Repository: convert.ToRepo(ctx, repo, mode),
+		Sender:     convert.ToUser(ctx, doer, nil),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("json.Marshal: %w", err)
+	}
+
+	jobs, err := jobparser.Parse(content)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid workflow %q: %v", workflowID, err)
+	}
+
+	run := &actions_model.ActionRun{
+		Title:         strings.SplitN(commit.CommitMessage, "\n", 2)[0],
+		RepoID:        repo.ID,
+		Repo:          repo,
+		OwnerID:       repo.OwnerID,
+		WorkflowID:    workflowID,
+		TriggerUserID: doer.ID,
+		TriggerUser:   doer,
+		Ref:           refName.String(),
+		CommitSHA:     commit.ID.String(),
+		Event:         webhook_module.HookEventWorkflowDispatch,
+		EventPayload:  string(p),
+		Status:        actions_model.StatusWaiting,
+	}
+	if err := insertRun(ctx, run, content, jobs); err != nil {
+		return nil, err
+	}
+
+	return run, nil
+}
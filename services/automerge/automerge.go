@@ -19,6 +19,7 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/process"
 	"code.gitea.io/gitea/modules/queue"
 	pull_service "code.gitea.io/gitea/services/pull"
@@ -34,6 +35,12 @@ func Init() error {
 		return fmt.Errorf("unable to create pr_auto_merge queue")
 	}
 	go graceful.GetManager().RunWithCancel(prAutoMergeQueue)
+
+	if err := initMergeQueue(); err != nil {
+		return err
+	}
+
+	notification.RegisterNotifier(NewNotifier())
 	return nil
 }
 
@@ -237,6 +244,17 @@ func handlePull(pullID int64, sha string) {
 		return
 	}
 
+	// The scheduled merge has to go through the merge queue if it has been enabled for the base branch in the meantime
+	if mergeQueueEnabled, err := pull_service.IsMergeQueueEnabled(ctx, pr); err != nil {
+		log.Error("%-v IsMergeQueueEnabled: %v", pr, err)
+		return
+	} else if mergeQueueEnabled {
+		if err := AddToMergeQueue(ctx, doer, pr, scheduledPRM.MergeStyle, scheduledPRM.Message); err != nil {
+			log.Error("%-v AddToMergeQueue: %v", pr, err)
+		}
+		return
+	}
+
 	var baseGitRepo *git.Repository
 	if pr.BaseRepoID == pr.HeadRepoID {
 		baseGitRepo = headGitRepo
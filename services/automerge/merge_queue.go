@@ -0,0 +1,282 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package automerge
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/sync"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// prMergeQueue is the task queue processing the merge queues of the branches, its items are "<repo_id>:<branch>"
+var prMergeQueue *queue.WorkerPoolQueue[string]
+
+// mergeQueueWorkingPool makes sure a merge queue of a branch is only processed by one worker at a time
+var mergeQueueWorkingPool = sync.NewExclusivePool()
+
+func initMergeQueue() error {
+	prMergeQueue = queue.CreateUniqueQueue(graceful.GetManager().ShutdownContext(), "pr_merge_queue", mergeQueueHandler)
+	if prMergeQueue == nil {
+		return fmt.Errorf("unable to create pr_merge_queue queue")
+	}
+	go graceful.GetManager().RunWithCancel(prMergeQueue)
+	return nil
+}
+
+func mergeQueueHandler(items ...string) []string {
+	for _, s := range items {
+		var repoID int64
+		idStr, branch, ok := strings.Cut(s, ":")
+		if _, err := fmt.Sscanf(idStr, "%d", &repoID); err != nil || !ok {
+			log.Error("could not parse data from pr_merge_queue queue (%v): %v", s, err)
+			continue
+		}
+		handleMergeQueue(repoID, branch)
+	}
+	return nil
+}
+
+func addMergeQueueToQueue(repoID int64, branch string) {
+	log.Trace("Adding merge queue of branch %s in repo %d to the merge queue processing queue", branch, repoID)
+	if err := prMergeQueue.Push(fmt.Sprintf("%d:%s", repoID, branch)); err != nil {
+		log.Error("Error adding merge queue of branch %s in repo %d to the merge queue processing queue: %v", branch, repoID, err)
+	}
+}
+
+// AddToMergeQueue adds a pull request to the merge queue of its base branch.
+// The caller should check that the pull request is ready to be merged (reviews and branch protections).
+func AddToMergeQueue(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, style repo_model.MergeStyle, message string) error {
+	// reject the merge style now instead of ejecting the pull request when the queue commit gets pushed
+	if err := pull_service.CheckMergeStyleAllowed(ctx, pr, style); err != nil {
+		return err
+	}
+
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := pull_model.DeleteScheduledAutoMerge(ctx, pr.ID); err != nil && !db.IsErrNotExist(err) {
+			return err
+		}
+
+		if _, err := pull_model.AddToMergeQueue(ctx, doer, pr.BaseRepoID, pr.BaseBranch, pr.ID, style, message); err != nil {
+			return err
+		}
+
+		_, err := issues_model.CreateMergeQueueComment(ctx, issues_model.CommentTypePRAddedToMergeQueue, pr, doer, "")
+		return err
+	}); err != nil {
+		return err
+	}
+
+	addMergeQueueToQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+// RemoveFromMergeQueue removes a pull request from the merge queue of its base branch,
+// the queue commits of the following pull requests will be rebuilt.
+func RemoveFromMergeQueue(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, reason string) error {
+	if err := removeFromMergeQueue(ctx, doer, pr, reason); err != nil {
+		return err
+	}
+
+	addMergeQueueToQueue(pr.BaseRepoID, pr.BaseBranch)
+	return nil
+}
+
+func removeFromMergeQueue(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, reason string) error {
+	if err := db.WithTx(ctx, func(ctx context.Context) error {
+		if err := pull_model.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+			return err
+		}
+
+		_, err := issues_model.CreateMergeQueueComment(ctx, issues_model.CommentTypePRRemovedFromMergeQueue, pr, doer, reason)
+		return err
+	}); err != nil {
+		return err
+	}
+
+	if err := pull_service.DeleteMergeQueueBranch(ctx, pr, doer); err != nil {
+		log.Error("DeleteMergeQueueBranch %-v: %v", pr, err)
+	}
+	return nil
+}
+
+// ProcessMergeQueuesByCommitID triggers the processing of all merge queues
+// containing a pull request whose queue commit is the given commit
+func ProcessMergeQueuesByCommitID(ctx context.Context, sha string, repo *repo_model.Repository) error {
+	branches, err := pull_model.GetMergeQueueBranchesByCommitID(ctx, repo.ID, sha)
+	if err != nil {
+		return err
+	}
+
+	for _, branch := range branches {
+		addMergeQueueToQueue(repo.ID, branch)
+	}
+	return nil
+}
+
+// handleMergeQueue (re)builds the queue commits of a merge queue and fast-forwards the base branch
+// to the queue commits of the head entries as long as their required status checks succeeded.
+func handleMergeQueue(repoID int64, branch string) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(),
+		fmt.Sprintf("Handle merge queue of branch %s in repo[%d]", branch, repoID))
+	defer finished()
+
+	poolKey := fmt.Sprintf("%d:%s", repoID, branch)
+	mergeQueueWorkingPool.CheckIn(poolKey)
+	defer mergeQueueWorkingPool.CheckOut(poolKey)
+
+	entries, err := pull_model.GetMergeQueue(ctx, repoID, branch)
+	if err != nil {
+		log.Error("GetMergeQueue[%d:%s]: %v", repoID, branch, err)
+		return
+	} else if len(entries) == 0 {
+		return
+	}
+
+	repo, err := repo_model.GetRepositoryByID(ctx, repoID)
+	if err != nil {
+		log.Error("GetRepositoryByID[%d]: %v", repoID, err)
+		return
+	}
+
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, repoID, branch)
+	if err != nil {
+		log.Error("GetFirstMatchProtectedBranchRule[%d:%s]: %v", repoID, branch, err)
+		return
+	}
+
+	baseCommitID, err := git.GetFullCommitID(ctx, repo.RepoPath(), git.BranchPrefix+branch)
+	if err != nil {
+		log.Error("GetFullCommitID of branch %s in %-v: %v", branch, repo, err)
+		return
+	}
+
+	// every entry is built on top of the base branch plus all entries before it
+	parentBranch, parentCommitID := branch, baseCommitID
+	isHead := true
+	for _, entry := range entries {
+		pr, err := issues_model.GetPullRequestByID(ctx, entry.PullID)
+		if err != nil {
+			log.Error("GetPullRequestByID[%d]: %v", entry.PullID, err)
+			return
+		}
+		if err := pr.LoadIssue(ctx); err != nil {
+			log.Error("LoadIssue %-v: %v", pr, err)
+			return
+		}
+		if err := entry.LoadDoer(ctx); err != nil {
+			log.Error("Unable to get user[%d] of merge queue entry of %-v: %v", entry.DoerID, pr, err)
+			return
+		}
+
+		eject := func(reason string) {
+			log.Info("Removing %-v from the merge queue of branch %s: %s", pr, branch, reason)
+			if err := removeFromMergeQueue(ctx, entry.Doer, pr, reason); err != nil {
+				log.Error("removeFromMergeQueue %-v: %v", pr, err)
+			}
+		}
+
+		if pr.HasMerged || pr.Issue.IsClosed {
+			if err := pull_model.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+				log.Error("DeleteMergeQueueEntry %-v: %v", pr, err)
+			}
+			if err := pull_service.DeleteMergeQueueBranch(ctx, pr, entry.Doer); err != nil {
+				log.Error("DeleteMergeQueueBranch %-v: %v", pr, err)
+			}
+			continue
+		}
+		if pb == nil || !pb.EnableMergeQueue {
+			eject("The merge queue has been disabled for the base branch.")
+			continue
+		}
+		if !pb.IsMergeStyleAllowed(entry.MergeStyle) {
+			eject(fmt.Sprintf("The merge style %s is not allowed for the base branch.", entry.MergeStyle))
+			continue
+		}
+
+		if entry.QueueCommitID == "" || entry.BaseCommitID != parentCommitID {
+			entry.BaseCommitID, entry.QueueCommitID, err = pull_service.BuildMergeQueueCommit(ctx, pr, entry.Doer, entry.MergeStyle, entry.Message, parentBranch)
+			if err != nil {
+				if !models.IsErrMergeConflicts(err) && !models.IsErrRebaseConflicts(err) && !models.IsErrMergeUnrelatedHistories(err) {
+					log.Error("BuildMergeQueueCommit %-v: %v", pr, err)
+				}
+				eject(fmt.Sprintf("Unable to merge the pull request on top of %s: %s", parentBranch, mergeQueueErrorReason(err)))
+				continue
+			}
+			if err := pull_model.UpdateMergeQueueEntryCols(ctx, entry, "base_commit_id", "queue_commit_id"); err != nil {
+				log.Error("UpdateMergeQueueEntryCols %-v: %v", pr, err)
+				return
+			}
+		}
+
+		if isHead {
+			state, err := pull_service.GetMergeQueueCommitStatusState(ctx, pr, entry.QueueCommitID)
+			if err != nil {
+				log.Error("GetMergeQueueCommitStatusState %-v: %v", pr, err)
+				return
+			}
+
+			switch {
+			case state.IsSuccess():
+				if err := pull_service.MergeQueueFastForward(ctx, pr, entry.Doer, entry.QueueCommitID); err != nil {
+					if git.IsErrPushOutOfDate(err) {
+						// the base branch has moved on, the queue has to be rebuilt
+						addMergeQueueToQueue(repoID, branch)
+						return
+					}
+					eject(fmt.Sprintf("Unable to fast-forward %s: %s", branch, mergeQueueErrorReason(err)))
+					continue
+				}
+				if err := pull_model.DeleteMergeQueueEntry(ctx, pr.ID); err != nil {
+					log.Error("DeleteMergeQueueEntry %-v: %v", pr, err)
+				}
+				if err := pull_service.DeleteMergeQueueBranch(ctx, pr, entry.Doer); err != nil {
+					log.Error("DeleteMergeQueueBranch %-v: %v", pr, err)
+				}
+				// the next entry has been built on top of this one and is the new head of the queue
+				parentBranch, parentCommitID = branch, entry.QueueCommitID
+				continue
+			case state.IsFailure() || state.IsError():
+				eject(fmt.Sprintf("The required status checks failed for the merge queue commit %s.", entry.QueueCommitID))
+				continue
+			}
+
+			// the head is still pending, the following entries have to wait for it
+			isHead = false
+		}
+
+		parentBranch, parentCommitID = pull_service.MergeQueueBranchName(pr), entry.QueueCommitID
+	}
+}
+
+// mergeQueueErrorReason returns a short, user facing description of why a merge queue operation failed
+func mergeQueueErrorReason(err error) string {
+	switch {
+	case models.IsErrMergeConflicts(err), models.IsErrRebaseConflicts(err):
+		return "there are merge conflicts."
+	case models.IsErrMergeUnrelatedHistories(err):
+		return "the histories are unrelated."
+	case git.IsErrPushRejected(err):
+		if msg := err.(*git.ErrPushRejected).Message; msg != "" {
+			return "the push was rejected: " + msg
+		}
+		return "the push was rejected."
+	default:
+		return "an internal error occurred."
+	}
+}
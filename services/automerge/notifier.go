@@ -0,0 +1,97 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package automerge
+
+import (
+	"context"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/repository"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+type mergeQueueNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &mergeQueueNotifier{}
+
+// NewNotifier create a new mergeQueueNotifier notifier which keeps the merge queues up to date
+func NewNotifier() base.Notifier {
+	return &mergeQueueNotifier{}
+}
+
+func (n *mergeQueueNotifier) removeFromMergeQueue(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, reason string) {
+	exist, _, err := pull_model.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		log.Error("GetMergeQueueEntryByPullID %-v: %v", pr, err)
+		return
+	} else if !exist {
+		return
+	}
+
+	if err := RemoveFromMergeQueue(ctx, doer, pr, reason); err != nil {
+		log.Error("RemoveFromMergeQueue %-v: %v", pr, err)
+	}
+}
+
+// NotifyPullRequestSynchronized removes the pull request from the merge queue as the tested changes are outdated
+func (n *mergeQueueNotifier) NotifyPullRequestSynchronized(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest) {
+	n.removeFromMergeQueue(ctx, doer, pr, "New commits have been pushed to the head branch.")
+}
+
+// NotifyPullRequestChangeTargetBranch removes the pull request from the merge queue of its old base branch
+func (n *mergeQueueNotifier) NotifyPullRequestChangeTargetBranch(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, oldBranch string) {
+	exist, entry, err := pull_model.GetMergeQueueEntryByPullID(ctx, pr.ID)
+	if err != nil {
+		log.Error("GetMergeQueueEntryByPullID %-v: %v", pr, err)
+		return
+	} else if !exist {
+		return
+	}
+
+	// the queue branch and the processing belong to the old base branch
+	oldPR := *pr
+	oldPR.BaseBranch = entry.BaseBranch
+	if err := RemoveFromMergeQueue(ctx, doer, &oldPR, "The target branch has been changed."); err != nil {
+		log.Error("RemoveFromMergeQueue %-v: %v", pr, err)
+	}
+}
+
+// NotifyIssueChangeStatus removes closed pull requests from the merge queue
+func (n *mergeQueueNotifier) NotifyIssueChangeStatus(ctx context.Context, doer *user_model.User, commitID string, issue *issues_model.Issue, actionComment *issues_model.Comment, isClosed bool) {
+	if !issue.IsPull || !isClosed {
+		return
+	}
+	if err := issue.LoadPullRequest(ctx); err != nil {
+		log.Error("LoadPullRequest: %v", err)
+		return
+	}
+	n.removeFromMergeQueue(ctx, doer, issue.PullRequest, "The pull request has been closed.")
+}
+
+// NotifyPushCommits triggers a rebuild of the merge queue if its base branch has been pushed to
+func (n *mergeQueueNotifier) NotifyPushCommits(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if !opts.RefFullName.IsBranch() {
+		return
+	}
+	branch := opts.RefFullName.BranchName()
+	if pull_service.IsMergeQueueBranch(branch) {
+		return
+	}
+
+	entries, err := pull_model.GetMergeQueue(ctx, repo.ID, branch)
+	if err != nil {
+		log.Error("GetMergeQueue: %v", err)
+		return
+	}
+	if len(entries) > 0 {
+		addMergeQueueToQueue(repo.ID, branch)
+	}
+}
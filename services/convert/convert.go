@@ -11,6 +11,7 @@ import (
 	"strings"
 	"time"
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
@@ -162,6 +163,10 @@ func ToBranchProtection(bp *git_model.ProtectedBranch) *api.BranchProtection {
 		RequireSignedCommits:          bp.RequireSignedCommits,
 		ProtectedFilePatterns:         bp.ProtectedFilePatterns,
 		UnprotectedFilePatterns:       bp.UnprotectedFilePatterns,
+		EnableMergeQueue:              bp.EnableMergeQueue,
+		RequireCodeOwnerApproval:      bp.RequireCodeOwnerApproval,
+		RequireLinearHistory:          bp.RequireLinearHistory,
+		RequireConversationResolution: bp.RequireConversationResolution,
 		Created:                       bp.CreatedUnix.AsTime(),
 		Updated:                       bp.UpdatedUnix.AsTime(),
 	}
@@ -440,3 +445,15 @@ func ToChangedFile(f *gitdiff.DiffFile, repo *repo_model.Repository, commit stri
 
 	return file
 }
+
+// ToActionVariable converts an actions variable to its API format
+func ToActionVariable(v *actions_model.ActionVariable) *api.ActionVariable {
+	return &api.ActionVariable{
+		OwnerID: v.OwnerID,
+		RepoID:  v.RepoID,
+		Name:    v.Name,
+		Data:    v.Data,
+		Created: v.CreatedUnix.AsTime(),
+		Updated: v.UpdatedUnix.AsTime(),
+	}
+}
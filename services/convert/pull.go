@@ -10,6 +10,7 @@ import (
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
+	pull_model "code.gitea.io/gitea/models/pull"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
@@ -215,3 +216,16 @@ func ToAPIPullRequest(ctx context.Context, pr *issues_model.PullRequest, doer *u
 
 	return apiPullRequest
 }
+
+// ToMergeQueueEntry converts a merge queue entry to its API format, the doer of the entry has to be loaded
+func ToMergeQueueEntry(ctx context.Context, entry *pull_model.MergeQueueEntry, index, position int64, doer *user_model.User) *api.MergeQueueEntry {
+	return &api.MergeQueueEntry{
+		Index:         index,
+		BaseBranch:    entry.BaseBranch,
+		Position:      position,
+		MergeStyle:    string(entry.MergeStyle),
+		QueueCommitID: entry.QueueCommitID,
+		Doer:          ToUser(ctx, entry.Doer, doer),
+		Created:       entry.CreatedUnix.AsTime(),
+	}
+}
@@ -108,8 +108,14 @@ func ToPullReviewCommentList(ctx context.Context, review *issues_model.Review, d
 
 				if comment.Line < 0 {
 					apiComment.OldLineNum = comment.UnsignedLine()
+					if comment.IsMultiLine() {
+						apiComment.OldStartLineNum = comment.UnsignedStartLine()
+					}
 				} else {
 					apiComment.LineNum = comment.UnsignedLine()
+					if comment.IsMultiLine() {
+						apiComment.StartLineNum = comment.UnsignedStartLine()
+					}
 				}
 				apiComments = append(apiComments, apiComment)
 			}
@@ -36,6 +36,10 @@ func ToGitServiceType(value string) structs.GitServiceType {
 		return structs.OneDevService
 	case "gitbucket":
 		return structs.GitBucketService
+	case "bitbucket":
+		return structs.BitbucketService
+	case "azuredevops":
+		return structs.AzureDevOpsService
 	default:
 		return structs.PlainGitService
 	}
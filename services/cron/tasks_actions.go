@@ -18,6 +18,7 @@ func initActionsTasks() {
 	registerStopZombieTasks()
 	registerStopEndlessTasks()
 	registerCancelAbandonedJobs()
+	registerScheduleTasks()
 }
 
 func registerStopZombieTasks() {
@@ -49,3 +50,13 @@ func registerCancelAbandonedJobs() {
 		return actions_service.CancelAbandonedJobs(ctx)
 	})
 }
+
+func registerScheduleTasks() {
+	RegisterTaskFatal("start_schedule_tasks", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1m",
+	}, func(ctx context.Context, _ *user_model.User, cfg Config) error {
+		return actions_service.StartScheduleTasks(ctx)
+	})
+}
@@ -126,6 +126,24 @@ func registerUpdateMigrationPosterID() {
 	})
 }
 
+func registerSyncMigratedRepositories() {
+	type SyncMigratedRepositoriesConfig struct {
+		BaseConfig
+		Limit int
+	}
+
+	RegisterTaskFatal("sync_migrated_repositories", &SyncMigratedRepositoriesConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 10m",
+		},
+		Limit: 50,
+	}, func(ctx context.Context, _ *user_model.User, cfg Config) error {
+		return migrations.SyncMigratedRepositories(ctx, cfg.(*SyncMigratedRepositoriesConfig).Limit)
+	})
+}
+
 func registerCleanupHookTaskTable() {
 	RegisterTaskFatal("cleanup_hook_task_table", &CleanupHookTaskConfig{
 		BaseConfig: BaseConfig{
@@ -167,6 +185,7 @@ func initBasicTasks() {
 	registerDeletedBranchesCleanup()
 	if !setting.Repository.DisableMigrations {
 		registerUpdateMigrationPosterID()
+		registerSyncMigratedRepositories()
 	}
 	registerCleanupHookTaskTable()
 	if setting.Packages.Enabled {
@@ -15,7 +15,7 @@ import (
 type PackageCleanupRuleForm struct {
 	ID            int64
 	Enabled       bool
-	Type          string `binding:"Required;In(alpine,cargo,chef,composer,conan,conda,container,cran,debian,generic,go,helm,maven,npm,nuget,pub,pypi,rpm,rubygems,swift,vagrant)"`
+	Type          string `binding:"Required;In(alpine,arch,cargo,chef,composer,conan,conda,container,cran,debian,generic,go,helm,maven,npm,nuget,pub,pypi,rpm,rubygems,swift,terraform,vagrant)"`
 	KeepCount     int    `binding:"In(0,1,5,10,25,50,100)"`
 	KeepPattern   string `binding:"RegexPattern"`
 	RemoveDays    int    `binding:"In(0,7,14,30,60,90,180)"`
@@ -28,3 +28,30 @@ func (f *PackageCleanupRuleForm) Validate(req *http.Request, errs binding.Errors
 	ctx := context.GetValidateContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+type PackageRemoteForm struct {
+	ID                 int64
+	Enabled            bool
+	Type               string `binding:"Required;In(container,maven,npm,pypi)"`
+	URL                string `binding:"Required;ValidUrl"`
+	Username           string
+	Password           string
+	MetadataTTLMinutes int    `binding:"Range(0,10080)"`
+	Action             string `binding:"Required;In(save,remove)"`
+}
+
+func (f *PackageRemoteForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+type PackageQuotaForm struct {
+	Owner           string `binding:"Required"`
+	LimitTotalSize  string
+	LimitTotalCount string
+}
+
+func (f *PackageQuotaForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
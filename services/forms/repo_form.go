@@ -83,6 +83,7 @@ type MigrateRepoForm struct {
 	PullRequests   bool   `json:"pull_requests"`
 	Releases       bool   `json:"releases"`
 	MirrorInterval string `json:"mirror_interval"`
+	Sync           bool   `json:"sync"`
 }
 
 // Validate validates the fields
@@ -211,6 +212,10 @@ type ProtectBranchForm struct {
 	RequireSignedCommits          bool
 	ProtectedFilePatterns         string
 	UnprotectedFilePatterns       string
+	EnableMergeQueue              bool
+	RequireCodeOwnerApproval      bool
+	RequireLinearHistory          bool
+	RequireConversationResolution bool
 }
 
 // Validate validates the fields
@@ -219,6 +224,20 @@ func (f *ProtectBranchForm) Validate(req *http.Request, errs binding.Errors) bin
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// PushRuleForm form for changing the push rules of a repository or an organization
+type PushRuleForm struct {
+	CommitMessagePattern  string
+	AllowedEmailDomains   string
+	MaxFileSize           int64 `binding:"Range(0,1048576)"` // in MiB
+	ForbiddenFilePatterns string
+}
+
+// Validate validates the fields
+func (f *PushRuleForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //  __      __      ___.   .__                   __
 // /  \    /  \ ____\_ |__ |  |__   ____   ____ |  | __
 // \   \/\/   // __ \| __ \|  |  \ /  _ \ /  _ \|  |/ /
@@ -253,6 +272,9 @@ type WebhookForm struct {
 	Active                   bool
 	BranchFilter             string `binding:"GlobPattern"`
 	AuthorizationHeader      string
+	HTTPSignature            bool
+	SecretRotationHours      int `binding:"Range(0,720)"`
+	RevokePreviousSecrets    bool
 }
 
 // PushOnly if the hook will be triggered when push
@@ -425,6 +447,52 @@ func (f *NewPackagistHookForm) Validate(req *http.Request, errs binding.Errors)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewCustomHookForm form for creating custom hook
+type NewCustomHookForm struct {
+	PayloadURL      string `binding:"Required;ValidUrl"`
+	HTTPMethod      string `binding:"Required;In(POST,PUT,PATCH)"`
+	ContentType     string
+	HeadersTemplate string
+	BodyTemplate    string
+	Secret          string
+	WebhookForm
+}
+
+// Validate validates the fields
+func (f *NewCustomHookForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	if err := webhook.ValidateCustomMeta(f.Meta()); err != nil {
+		errs = append(errs, binding.Error{
+			FieldNames:     []string{"BodyTemplate"},
+			Classification: "",
+			Message:        err.Error(),
+		})
+	}
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// Meta returns the templates of the custom hook
+func (f *NewCustomHookForm) Meta() *webhook.CustomMeta {
+	return &webhook.CustomMeta{
+		ContentType:     strings.TrimSpace(f.ContentType),
+		HeadersTemplate: f.HeadersTemplate,
+		BodyTemplate:    f.BodyTemplate,
+	}
+}
+
+// CustomHookPreviewForm form for previewing the templates of a custom hook
+type CustomHookPreviewForm struct {
+	ContentType     string
+	HeadersTemplate string
+	BodyTemplate    string
+}
+
+// Validate validates the fields
+func (f *CustomHookPreviewForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -622,6 +690,7 @@ type CodeCommentForm struct {
 	Content        string `binding:"Required"`
 	Side           string `binding:"Required;In(previous,proposed)"`
 	Line           int64
+	StartLine      int64
 	TreePath       string `form:"path" binding:"Required"`
 	SingleReview   bool   `form:"single_review"`
 	Reply          int64  `form:"reply"`
@@ -634,6 +703,18 @@ func (f *CodeCommentForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// ApplySuggestionsForm form for committing the suggestions of code comments to the head branch of a PR
+type ApplySuggestionsForm struct {
+	CommentIDs []int64 `form:"comment_ids" binding:"Required"`
+	Message    string
+}
+
+// Validate validates the fields
+func (f *ApplySuggestionsForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetValidateContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // SubmitReviewForm for submitting a finished code review
 type SubmitReviewForm struct {
 	Content  string
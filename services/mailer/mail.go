@@ -41,7 +41,8 @@ const (
 	mailAuthResetPassword  base.TplName = "auth/reset_passwd"
 	mailAuthRegisterNotify base.TplName = "auth/register_notify"
 
-	mailNotifyCollaborator base.TplName = "notify/collaborator"
+	mailNotifyCollaborator       base.TplName = "notify/collaborator"
+	mailNotifyWebhookDeactivated base.TplName = "notify/webhook_deactivated"
 
 	mailRepoTransferNotify base.TplName = "notify/repo_transfer"
 
@@ -0,0 +1,56 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package mailer
+
+import (
+	"bytes"
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/templates"
+	"code.gitea.io/gitea/modules/translation"
+)
+
+// SendWebhookDeactivatedMail notifies the administrators of a webhook that it has been deactivated
+// after too many consecutive failed deliveries
+func SendWebhookDeactivatedMail(recipients []*user_model.User, host, link string, failures int) {
+	if setting.MailService == nil {
+		// No mail service configured
+		return
+	}
+
+	for _, u := range recipients {
+		if !u.IsActive {
+			// don't send emails to inactive users
+			continue
+		}
+
+		locale := translation.NewLocale(u.Language)
+		subject := locale.Tr("mail.webhook.deactivated.subject", host)
+		data := map[string]interface{}{
+			"Subject":  subject,
+			"Host":     host,
+			"Failures": failures,
+			"Link":     link,
+			"Language": locale.Language(),
+			// helper
+			"locale":    locale,
+			"Str2html":  templates.Str2html,
+			"DotEscape": templates.DotEscape,
+		}
+
+		var content bytes.Buffer
+		if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyWebhookDeactivated), data); err != nil {
+			log.Error("Template: %v", err)
+			return
+		}
+
+		msg := NewMessage(u.Email, subject, content.String())
+		msg.Info = fmt.Sprintf("UID: %d, webhook deactivated", u.ID)
+
+		SendAsync(msg)
+	}
+}
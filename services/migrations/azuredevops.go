@@ -0,0 +1,871 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &AzureDevOpsDownloader{}
+	_ base.DownloaderFactory = &AzureDevOpsDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&AzureDevOpsDownloaderFactory{})
+}
+
+// AzureDevOpsDownloaderFactory defines an azure devops downloader factory
+type AzureDevOpsDownloaderFactory struct{}
+
+// New returns a Downloader related to this factory according MigrateOptions
+func (f *AzureDevOpsDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	collectionURL, project, repoName, err := parseAzureDevOpsURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	token := opts.AuthToken
+	if token == "" {
+		token = opts.AuthPassword
+	}
+
+	log.Trace("Create Azure DevOps downloader. CollectionURL: %s Project: %s RepoName: %s", collectionURL, project, repoName)
+
+	return NewAzureDevOpsDownloader(ctx, collectionURL, project, repoName, token), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *AzureDevOpsDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.AzureDevOpsService
+}
+
+// parseAzureDevOpsURL returns the URL of the organization (or of the collection for Azure DevOps Server), the project and the repository
+// of the URL of a repository: https://dev.azure.com/{organization}/{project}/_git/{repository},
+// https://{organization}.visualstudio.com/{project}/_git/{repository} or https://{server}/{collection}/{project}/_git/{repository}.
+// The project can be omitted when the repository has the name of its project.
+func parseAzureDevOpsURL(u *url.URL) (collectionURL, project, repoName string, err error) {
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	i := 0
+	for i < len(fields) && fields[i] != "_git" {
+		i++
+	}
+	if i+1 >= len(fields) {
+		return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+	}
+	repoName = strings.TrimSuffix(fields[i+1], ".git")
+
+	// on dev.azure.com the first element of the path is always the organization
+	minCollectionFields := 0
+	if strings.EqualFold(u.Hostname(), "dev.azure.com") {
+		minCollectionFields = 1
+	}
+	collectionFields := fields[:i]
+	project = repoName
+	if i > minCollectionFields {
+		collectionFields, project = fields[:i-1], fields[i-1]
+	}
+	if len(collectionFields) < minCollectionFields {
+		return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+	}
+
+	if project, err = url.PathUnescape(project); err != nil {
+		return "", "", "", err
+	}
+	if repoName, err = url.PathUnescape(repoName); err != nil {
+		return "", "", "", err
+	}
+
+	collectionURL = u.Scheme + "://" + u.Host
+	if len(collectionFields) > 0 {
+		collectionURL += "/" + strings.Join(collectionFields, "/")
+	}
+	return collectionURL, project, repoName, nil
+}
+
+type azureDevOpsIdentity struct {
+	DisplayName string `json:"displayName"`
+	UniqueName  string `json:"uniqueName"`
+}
+
+func (i *azureDevOpsIdentity) name() string {
+	if i == nil {
+		return "Ghost"
+	}
+	if i.DisplayName != "" {
+		return i.DisplayName
+	}
+	return i.UniqueName
+}
+
+func (i *azureDevOpsIdentity) email() string {
+	if i == nil || !strings.Contains(i.UniqueName, "@") {
+		return ""
+	}
+	return i.UniqueName
+}
+
+type azureDevOpsReviewer struct {
+	azureDevOpsIdentity
+	Vote        int  `json:"vote"`
+	IsContainer bool `json:"isContainer"`
+}
+
+type azureDevOpsIssueContext struct {
+	IsPullRequest bool
+	Reviewers     []azureDevOpsReviewer
+}
+
+// AzureDevOpsDownloader implements a Downloader interface to get repository information
+// from Azure DevOps Services and Azure DevOps Server.
+// The work items of the project are migrated as issues.
+type AzureDevOpsDownloader struct {
+	base.NullDownloader
+	ctx             context.Context
+	client          *http.Client
+	collectionURL   *url.URL
+	project         string
+	repoName        string
+	token           string
+	repoID          string
+	workItemIDs     []int64
+	gotWorkItemIDs  bool
+	wiqlPageSize    int
+	completedStates map[string]map[string]bool
+	maxIssueIndex   int64
+}
+
+// NewAzureDevOpsDownloader creates an Azure DevOps downloader
+func NewAzureDevOpsDownloader(ctx context.Context, collectionURL, project, repoName, token string) *AzureDevOpsDownloader {
+	u, _ := url.Parse(strings.TrimSuffix(collectionURL, "/"))
+
+	return &AzureDevOpsDownloader{
+		ctx:             ctx,
+		client:          NewMigrationHTTPClient(),
+		collectionURL:   u,
+		project:         project,
+		repoName:        repoName,
+		token:           token,
+		wiqlPageSize:    azureDevOpsMaxWiqlResults,
+		completedStates: make(map[string]map[string]bool),
+	}
+}
+
+// SetContext set context
+func (d *AzureDevOpsDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// String implements Stringer
+func (d *AzureDevOpsDownloader) String() string {
+	return fmt.Sprintf("migration from azure devops %s %s/%s", d.collectionURL, d.project, d.repoName)
+}
+
+func (d *AzureDevOpsDownloader) LogString() string {
+	if d == nil {
+		return "<AzureDevOpsDownloader nil>"
+	}
+	return fmt.Sprintf("<AzureDevOpsDownloader %s %s/%s>", d.collectionURL, d.project, d.repoName)
+}
+
+// callAPI calls an endpoint of the project, the body is sent as JSON if it isn't nil
+func (d *AzureDevOpsDownloader) callAPI(method, endpoint, apiVersion string, parameter url.Values, body, result interface{}) error {
+	u, err := d.collectionURL.Parse(d.collectionURL.Path + "/" + url.PathEscape(d.project) + "/_apis/" + endpoint)
+	if err != nil {
+		return err
+	}
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	parameter.Set("api-version", apiVersion)
+	u.RawQuery = parameter.Encode()
+
+	var reqBody io.Reader
+	if body != nil {
+		bs, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(bs)
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, method, u.String(), reqBody)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	if body != nil {
+		req.Header.Add("Content-Type", "application/json")
+	}
+	if len(d.token) > 0 {
+		// a personal access token is sent as the password of the basic authentication
+		req.SetBasicAuth("", d.token)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from %s: %s", u.Path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+func (d *AzureDevOpsDownloader) repoEndpoint(endpoint string) string {
+	repo := d.repoID
+	if repo == "" {
+		repo = url.PathEscape(d.repoName)
+	}
+	return "git/repositories/" + repo + endpoint
+}
+
+// GetRepoInfo returns repository information
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/git/repositories/get-repository
+func (d *AzureDevOpsDownloader) GetRepoInfo() (*base.Repository, error) {
+	var rawRepo struct {
+		ID            string `json:"id"`
+		Name          string `json:"name"`
+		DefaultBranch string `json:"defaultBranch"`
+		RemoteURL     string `json:"remoteUrl"`
+		WebURL        string `json:"webUrl"`
+		Project       struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Visibility  string `json:"visibility"`
+		} `json:"project"`
+	}
+	if err := d.callAPI("GET", d.repoEndpoint(""), "6.0", nil, nil, &rawRepo); err != nil {
+		return nil, err
+	}
+	d.repoID = rawRepo.ID
+
+	cloneURL, err := url.Parse(rawRepo.RemoteURL)
+	if err != nil {
+		return nil, err
+	}
+	cloneURL.User = nil
+
+	return &base.Repository{
+		Name:          rawRepo.Name,
+		Owner:         rawRepo.Project.Name,
+		IsPrivate:     rawRepo.Project.Visibility != "public",
+		Description:   rawRepo.Project.Description,
+		CloneURL:      cloneURL.String(),
+		OriginalURL:   rawRepo.WebURL,
+		DefaultBranch: strings.TrimPrefix(rawRepo.DefaultBranch, "refs/heads/"),
+	}, nil
+}
+
+type azureDevOpsIteration struct {
+	Name       string `json:"name"`
+	Attributes *struct {
+		StartDate  *time.Time `json:"startDate"`
+		FinishDate *time.Time `json:"finishDate"`
+	} `json:"attributes"`
+	Children []*azureDevOpsIteration `json:"children"`
+}
+
+// iterationMilestone returns the title of the milestone of an iteration path, the root iteration is the project which has no milestone
+func (d *AzureDevOpsDownloader) iterationMilestone(iterationPath string) string {
+	_, path, _ := strings.Cut(iterationPath, `\`)
+	return strings.ReplaceAll(path, `\`, "/")
+}
+
+// GetMilestones returns the iterations as milestones
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/classification-nodes/get
+func (d *AzureDevOpsDownloader) GetMilestones() ([]*base.Milestone, error) {
+	var root azureDevOpsIteration
+	if err := d.callAPI("GET", "wit/classificationnodes/Iterations", "6.0", url.Values{"$depth": {"10"}}, nil, &root); err != nil {
+		return nil, err
+	}
+
+	milestones := make([]*base.Milestone, 0, 10)
+	var walk func(path string, iterations []*azureDevOpsIteration)
+	walk = func(path string, iterations []*azureDevOpsIteration) {
+		for _, iteration := range iterations {
+			title := iteration.Name
+			if path != "" {
+				title = path + "/" + iteration.Name
+			}
+			milestone := &base.Milestone{
+				Title: title,
+				State: "open",
+			}
+			if iteration.Attributes != nil {
+				if iteration.Attributes.StartDate != nil {
+					milestone.Created = *iteration.Attributes.StartDate
+				}
+				milestone.Deadline = iteration.Attributes.FinishDate
+				// the past iterations are closed
+				if milestone.Deadline != nil && milestone.Deadline.Before(time.Now()) {
+					milestone.State = "closed"
+					milestone.Closed = milestone.Deadline
+				}
+			}
+			milestones = append(milestones, milestone)
+			walk(title, iteration.Children)
+		}
+	}
+	walk("", root.Children)
+
+	return milestones, nil
+}
+
+// GetLabels returns the work item types as exclusive scoped labels and the tags as labels
+func (d *AzureDevOpsDownloader) GetLabels() ([]*base.Label, error) {
+	var types struct {
+		Value []struct {
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Color       string `json:"color"`
+			IsDisabled  bool   `json:"isDisabled"`
+		} `json:"value"`
+	}
+	if err := d.callAPI("GET", "wit/workitemtypes", "6.0", nil, nil, &types); err != nil {
+		return nil, err
+	}
+
+	var tags struct {
+		Value []struct {
+			Name string `json:"name"`
+		} `json:"value"`
+	}
+	if err := d.callAPI("GET", "wit/tags", "6.0-preview.1", nil, nil, &tags); err != nil {
+		return nil, err
+	}
+
+	labels := make([]*base.Label, 0, len(types.Value)+len(tags.Value))
+	for _, t := range types.Value {
+		if t.IsDisabled {
+			continue
+		}
+		color := strings.ToLower(t.Color)
+		if len(color) == 8 {
+			// the color may start with the alpha channel
+			color = color[2:]
+		}
+		labels = append(labels, &base.Label{
+			Name:        "type/" + t.Name,
+			Color:       color,
+			Description: t.Description,
+			Exclusive:   true,
+		})
+	}
+	for _, tag := range tags.Value {
+		labels = append(labels, &base.Label{
+			Name:  tag.Name,
+			Color: "bfd4f2",
+		})
+	}
+	return labels, nil
+}
+
+// isCompletedState returns true if the state of the work item type belongs to the completed or the removed category
+func (d *AzureDevOpsDownloader) isCompletedState(workItemType, state string) (bool, error) {
+	states, ok := d.completedStates[workItemType]
+	if !ok {
+		var rawStates struct {
+			Value []struct {
+				Name     string `json:"name"`
+				Category string `json:"category"`
+			} `json:"value"`
+		}
+		if err := d.callAPI("GET", "wit/workitemtypes/"+url.PathEscape(workItemType)+"/states", "6.0", nil, nil, &rawStates); err != nil {
+			return false, err
+		}
+		states = make(map[string]bool, len(rawStates.Value))
+		for _, s := range rawStates.Value {
+			states[s.Name] = s.Category == "Completed" || s.Category == "Removed"
+		}
+		d.completedStates[workItemType] = states
+	}
+	return states[state], nil
+}
+
+// azureDevOpsMaxWiqlResults is the maximum number of work items returned by a WIQL query, larger results fail with VS402337
+const azureDevOpsMaxWiqlResults = 20000
+
+// loadWorkItemIDs queries the IDs of all the work items of the project, they are paged by their IDs
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/wiql/query-by-wiql
+func (d *AzureDevOpsDownloader) loadWorkItemIDs() error {
+	d.workItemIDs = d.workItemIDs[:0]
+	var lastID int64
+	for {
+		var result struct {
+			WorkItems []struct {
+				ID int64 `json:"id"`
+			} `json:"workItems"`
+		}
+		err := d.callAPI("POST", "wit/wiql", "6.0", url.Values{
+			"$top": {strconv.Itoa(d.wiqlPageSize)},
+		}, map[string]string{
+			"query": fmt.Sprintf("SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project AND [System.Id] > %d ORDER BY [System.Id]", lastID),
+		}, &result)
+		if err != nil {
+			return err
+		}
+		for _, workItem := range result.WorkItems {
+			d.workItemIDs = append(d.workItemIDs, workItem.ID)
+			lastID = workItem.ID
+		}
+		if len(result.WorkItems) < d.wiqlPageSize {
+			return nil
+		}
+	}
+}
+
+// GetIssues returns the work items of the project as issues
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/wit/work-items/list
+func (d *AzureDevOpsDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	if !d.gotWorkItemIDs {
+		if err := d.loadWorkItemIDs(); err != nil {
+			return nil, false, err
+		}
+		d.gotWorkItemIDs = true
+	}
+
+	// at most 200 work items can be requested at once
+	if perPage <= 0 || perPage > 200 {
+		perPage = 200
+	}
+	start := (page - 1) * perPage
+	if start >= len(d.workItemIDs) {
+		return []*base.Issue{}, true, nil
+	}
+	end := start + perPage
+	if end > len(d.workItemIDs) {
+		end = len(d.workItemIDs)
+	}
+	ids := make([]string, 0, end-start)
+	for _, id := range d.workItemIDs[start:end] {
+		ids = append(ids, strconv.FormatInt(id, 10))
+	}
+
+	var rawWorkItems struct {
+		Value []*struct {
+			ID     int64 `json:"id"`
+			Fields struct {
+				WorkItemType  string               `json:"System.WorkItemType"`
+				State         string               `json:"System.State"`
+				Title         string               `json:"System.Title"`
+				Description   string               `json:"System.Description"`
+				ReproSteps    string               `json:"Microsoft.VSTS.TCM.ReproSteps"`
+				IterationPath string               `json:"System.IterationPath"`
+				Tags          string               `json:"System.Tags"`
+				CreatedBy     *azureDevOpsIdentity `json:"System.CreatedBy"`
+				AssignedTo    *azureDevOpsIdentity `json:"System.AssignedTo"`
+				CreatedDate   time.Time            `json:"System.CreatedDate"`
+				ChangedDate   time.Time            `json:"System.ChangedDate"`
+				ClosedDate    *time.Time           `json:"Microsoft.VSTS.Common.ClosedDate"`
+			} `json:"fields"`
+		} `json:"value"`
+	}
+	err := d.callAPI("GET", "wit/workitems", "6.0", url.Values{
+		"ids":         {strings.Join(ids, ",")},
+		"errorPolicy": {"omit"},
+	}, nil, &rawWorkItems)
+	if err != nil {
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(rawWorkItems.Value))
+	for _, workItem := range rawWorkItems.Value {
+		// the work items which have been deleted since the query are omitted
+		if workItem == nil {
+			continue
+		}
+		fields := &workItem.Fields
+
+		content := fields.Description
+		if content == "" {
+			content = fields.ReproSteps
+		}
+
+		labels := []*base.Label{{Name: "type/" + fields.WorkItemType}}
+		for _, tag := range strings.Split(fields.Tags, ";") {
+			if tag = strings.TrimSpace(tag); tag != "" {
+				labels = append(labels, &base.Label{Name: tag})
+			}
+		}
+
+		completed, err := d.isCompletedState(fields.WorkItemType, fields.State)
+		if err != nil {
+			return nil, false, err
+		}
+		state := "open"
+		var closed *time.Time
+		if completed {
+			state = "closed"
+			closed = fields.ClosedDate
+			if closed == nil {
+				closed = &fields.ChangedDate
+			}
+		}
+
+		var assignees []string
+		if fields.AssignedTo != nil {
+			assignees = append(assignees, fields.AssignedTo.UniqueName)
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:       workItem.ID,
+			Title:        fields.Title,
+			Content:      content,
+			PosterName:   fields.CreatedBy.name(),
+			PosterEmail:  fields.CreatedBy.email(),
+			Milestone:    d.iterationMilestone(fields.IterationPath),
+			State:        state,
+			Created:      fields.CreatedDate,
+			Updated:      fields.ChangedDate,
+			Closed:       closed,
+			Labels:       labels,
+			Assignees:    assignees,
+			ForeignIndex: workItem.ID,
+			Context:      azureDevOpsIssueContext{IsPullRequest: false},
+		})
+
+		if d.maxIssueIndex < workItem.ID {
+			d.maxIssueIndex = workItem.ID
+		}
+	}
+
+	return issues, end == len(d.workItemIDs), nil
+}
+
+type azureDevOpsThread struct {
+	ID            int64 `json:"id"`
+	IsDeleted     bool  `json:"isDeleted"`
+	ThreadContext *struct {
+		FilePath      string `json:"filePath"`
+		LeftFileStart *struct {
+			Line int `json:"line"`
+		} `json:"leftFileStart"`
+		RightFileStart *struct {
+			Line int `json:"line"`
+		} `json:"rightFileStart"`
+	} `json:"threadContext"`
+	Comments []struct {
+		Content         string               `json:"content"`
+		CommentType     string               `json:"commentType"`
+		Author          *azureDevOpsIdentity `json:"author"`
+		PublishedDate   time.Time            `json:"publishedDate"`
+		LastUpdatedDate time.Time            `json:"lastUpdatedDate"`
+		IsDeleted       bool                 `json:"isDeleted"`
+	} `json:"comments"`
+}
+
+func (d *AzureDevOpsDownloader) getThreads(index int64) ([]*azureDevOpsThread, error) {
+	var threads struct {
+		Value []*azureDevOpsThread `json:"value"`
+	}
+	if err := d.callAPI("GET", d.repoEndpoint(fmt.Sprintf("/pullRequests/%d/threads", index)), "6.0", nil, nil, &threads); err != nil {
+		return nil, err
+	}
+	return threads.Value, nil
+}
+
+// GetComments returns the comments of a work item or the general comments of a pull request, the comments on the code are returned by GetReviews
+func (d *AzureDevOpsDownloader) GetComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	context, ok := commentable.GetContext().(azureDevOpsIssueContext)
+	if !ok {
+		return nil, false, fmt.Errorf("unexpected context: %+v", commentable.GetContext())
+	}
+
+	if context.IsPullRequest {
+		threads, err := d.getThreads(commentable.GetForeignIndex())
+		if err != nil {
+			return nil, false, err
+		}
+		comments := make([]*base.Comment, 0, len(threads))
+		for _, thread := range threads {
+			if thread.IsDeleted || thread.ThreadContext != nil {
+				continue
+			}
+			for _, comment := range thread.Comments {
+				// the votes and the updates of the pull request are system comments
+				if comment.IsDeleted || comment.CommentType == "system" || len(comment.Content) == 0 {
+					continue
+				}
+				comments = append(comments, &base.Comment{
+					IssueIndex:  commentable.GetLocalIndex(),
+					PosterName:  comment.Author.name(),
+					PosterEmail: comment.Author.email(),
+					Content:     comment.Content,
+					Created:     comment.PublishedDate,
+					Updated:     comment.LastUpdatedDate,
+				})
+			}
+		}
+		return comments, true, nil
+	}
+
+	comments := make([]*base.Comment, 0, 10)
+	parameter := url.Values{"$top": {"200"}, "order": {"asc"}}
+	for {
+		var rawComments struct {
+			Comments []struct {
+				ID           int64                `json:"id"`
+				Text         string               `json:"text"`
+				CreatedBy    *azureDevOpsIdentity `json:"createdBy"`
+				CreatedDate  time.Time            `json:"createdDate"`
+				ModifiedDate time.Time            `json:"modifiedDate"`
+				IsDeleted    bool                 `json:"isDeleted"`
+			} `json:"comments"`
+			ContinuationToken string `json:"continuationToken"`
+		}
+		endpoint := fmt.Sprintf("wit/workItems/%d/comments", commentable.GetForeignIndex())
+		if err := d.callAPI("GET", endpoint, "6.0-preview.3", parameter, nil, &rawComments); err != nil {
+			return nil, false, err
+		}
+		for _, comment := range rawComments.Comments {
+			if comment.IsDeleted || len(comment.Text) == 0 {
+				continue
+			}
+			comments = append(comments, &base.Comment{
+				IssueIndex:  commentable.GetLocalIndex(),
+				Index:       comment.ID,
+				PosterName:  comment.CreatedBy.name(),
+				PosterEmail: comment.CreatedBy.email(),
+				Content:     comment.Text,
+				Created:     comment.CreatedDate,
+				Updated:     comment.ModifiedDate,
+			})
+		}
+		if rawComments.ContinuationToken == "" {
+			return comments, true, nil
+		}
+		parameter.Set("continuationToken", rawComments.ContinuationToken)
+	}
+}
+
+type azureDevOpsRepository struct {
+	Name      string `json:"name"`
+	RemoteURL string `json:"remoteUrl"`
+	Project   struct {
+		Name string `json:"name"`
+	} `json:"project"`
+}
+
+// GetPullRequests returns pull requests, they are numbered after the work items
+// https://learn.microsoft.com/en-us/rest/api/azure/devops/git/pull-requests/get-pull-requests
+func (d *AzureDevOpsDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	var rawPullRequests struct {
+		Value []struct {
+			PullRequestID int64                 `json:"pullRequestId"`
+			Status        string                `json:"status"`
+			CreatedBy     *azureDevOpsIdentity  `json:"createdBy"`
+			CreationDate  time.Time             `json:"creationDate"`
+			ClosedDate    *time.Time            `json:"closedDate"`
+			Title         string                `json:"title"`
+			Description   string                `json:"description"`
+			SourceRefName string                `json:"sourceRefName"`
+			TargetRefName string                `json:"targetRefName"`
+			Reviewers     []azureDevOpsReviewer `json:"reviewers"`
+			Labels        []struct {
+				Name   string `json:"name"`
+				Active bool   `json:"active"`
+			} `json:"labels"`
+			LastMergeSourceCommit *struct {
+				CommitID string `json:"commitId"`
+			} `json:"lastMergeSourceCommit"`
+			LastMergeTargetCommit *struct {
+				CommitID string `json:"commitId"`
+			} `json:"lastMergeTargetCommit"`
+			LastMergeCommit *struct {
+				CommitID string `json:"commitId"`
+			} `json:"lastMergeCommit"`
+			ForkSource *struct {
+				Repository azureDevOpsRepository `json:"repository"`
+			} `json:"forkSource"`
+		} `json:"value"`
+	}
+	err := d.callAPI("GET", d.repoEndpoint("/pullrequests"), "6.0", url.Values{
+		"searchCriteria.status": {"all"},
+		"$skip":                 {strconv.Itoa((page - 1) * perPage)},
+		"$top":                  {strconv.Itoa(perPage)},
+	}, nil, &rawPullRequests)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests.Value))
+	for _, pr := range rawPullRequests.Value {
+		state := "open"
+		merged := false
+		var closeTime, mergedTime *time.Time
+		var mergeCommitSHA string
+		if pr.Status != "active" {
+			state = "closed"
+			closeTime = pr.ClosedDate
+			if pr.Status == "completed" {
+				merged = true
+				mergedTime = closeTime
+				if pr.LastMergeCommit != nil {
+					mergeCommitSHA = pr.LastMergeCommit.CommitID
+				}
+			}
+		}
+
+		var labels []*base.Label
+		for _, label := range pr.Labels {
+			if label.Active {
+				labels = append(labels, &base.Label{Name: label.Name})
+			}
+		}
+
+		head := base.PullRequestBranch{
+			Ref:       strings.TrimPrefix(pr.SourceRefName, "refs/heads/"),
+			OwnerName: d.project,
+			RepoName:  d.repoName,
+		}
+		if pr.LastMergeSourceCommit != nil {
+			head.SHA = pr.LastMergeSourceCommit.CommitID
+		}
+		if pr.ForkSource != nil {
+			head.OwnerName = pr.ForkSource.Repository.Project.Name
+			head.RepoName = pr.ForkSource.Repository.Name
+			if u, err := url.Parse(pr.ForkSource.Repository.RemoteURL); err == nil {
+				u.User = nil
+				head.CloneURL = u.String()
+			}
+		}
+		prBase := base.PullRequestBranch{
+			Ref:       strings.TrimPrefix(pr.TargetRefName, "refs/heads/"),
+			OwnerName: d.project,
+			RepoName:  d.repoName,
+		}
+		if pr.LastMergeTargetCommit != nil {
+			prBase.SHA = pr.LastMergeTargetCommit.CommitID
+		}
+
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         pr.PullRequestID + d.maxIssueIndex,
+			Title:          pr.Title,
+			PosterName:     pr.CreatedBy.name(),
+			PosterEmail:    pr.CreatedBy.email(),
+			Content:        pr.Description,
+			State:          state,
+			Created:        pr.CreationDate,
+			Updated:        pr.CreationDate,
+			Closed:         closeTime,
+			Labels:         labels,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head:           head,
+			Base:           prBase,
+			ForeignIndex:   pr.PullRequestID,
+			Context:        azureDevOpsIssueContext{IsPullRequest: true, Reviewers: pr.Reviewers},
+		})
+		if closeTime != nil {
+			pullRequests[len(pullRequests)-1].Updated = *closeTime
+		}
+
+		// SECURITY: Ensure that the PR is safe
+		_ = CheckAndEnsureSafePR(pullRequests[len(pullRequests)-1], d.collectionURL.String(), d)
+	}
+
+	return pullRequests, len(rawPullRequests.Value) < perPage, nil
+}
+
+// GetReviews returns the votes of the reviewers and the comments on the code of a pull request
+func (d *AzureDevOpsDownloader) GetReviews(reviewable base.Reviewable) ([]*base.Review, error) {
+	reviews := make([]*base.Review, 0, 10)
+
+	if commentable, ok := reviewable.(base.Commentable); ok {
+		if context, ok := commentable.GetContext().(azureDevOpsIssueContext); ok {
+			for _, reviewer := range context.Reviewers {
+				// the votes of the groups are the ones of their members
+				if reviewer.IsContainer {
+					continue
+				}
+				var state string
+				switch {
+				case reviewer.Vote > 0: // 10: approved, 5: approved with suggestions
+					state = base.ReviewStateApproved
+				case reviewer.Vote < 0: // -5: waiting for author, -10: rejected
+					state = base.ReviewStateChangesRequested
+				default:
+					continue
+				}
+				reviews = append(reviews, &base.Review{
+					IssueIndex:   reviewable.GetLocalIndex(),
+					ReviewerName: reviewer.name(),
+					State:        state,
+				})
+			}
+		}
+	}
+
+	threads, err := d.getThreads(reviewable.GetForeignIndex())
+	if err != nil {
+		return nil, err
+	}
+	for _, thread := range threads {
+		if thread.IsDeleted || thread.ThreadContext == nil || thread.ThreadContext.FilePath == "" {
+			continue
+		}
+
+		// a negative line is a line of the old version of the file
+		var line int
+		if thread.ThreadContext.RightFileStart != nil {
+			line = thread.ThreadContext.RightFileStart.Line
+		} else if thread.ThreadContext.LeftFileStart != nil {
+			line = -thread.ThreadContext.LeftFileStart.Line
+		}
+
+		for _, comment := range thread.Comments {
+			if comment.IsDeleted || comment.CommentType == "system" || len(comment.Content) == 0 {
+				continue
+			}
+			reviews = append(reviews, &base.Review{
+				IssueIndex:   reviewable.GetLocalIndex(),
+				ReviewerName: comment.Author.name(),
+				CreatedAt:    comment.PublishedDate,
+				State:        base.ReviewStateCommented,
+				Comments: []*base.ReviewComment{
+					{
+						Content:   comment.Content,
+						TreePath:  strings.TrimPrefix(thread.ThreadContext.FilePath, "/"),
+						Line:      line,
+						CreatedAt: comment.PublishedDate,
+						UpdatedAt: comment.LastUpdatedDate,
+					},
+				},
+			})
+		}
+	}
+
+	return reviews, nil
+}
+
+// GetTopics return repository topics, Azure DevOps has no topics
+func (d *AzureDevOpsDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
@@ -0,0 +1,407 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	base "code.gitea.io/gitea/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// azureDevOpsFixtureServer replays the recorded responses, they are looked up by the path and the query of the requests,
+// followed by a space and the body for the POST requests, and "{{server}}" is replaced by the URL of the server
+func azureDevOpsFixtureServer(t *testing.T, fixtures map[string]string) *httptest.Server {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key := r.URL.Path
+		if r.URL.RawQuery != "" {
+			key += "?" + r.URL.RawQuery
+		}
+		if r.Method == http.MethodPost {
+			body, _ := io.ReadAll(r.Body)
+			key += " " + string(body)
+		}
+		fixture, ok := fixtures[key]
+		if !ok {
+			t.Errorf("unexpected request: %s", key)
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(strings.ReplaceAll(fixture, "{{server}}", server.URL)))
+	}))
+	return server
+}
+
+func TestAzureDevOpsDownloaderFactory(t *testing.T) {
+	for _, testCase := range []struct {
+		cloneAddr     string
+		collectionURL string
+		project, repo string
+	}{
+		{cloneAddr: "https://dev.azure.com/gitea/Test%20Project/_git/test_repo", collectionURL: "https://dev.azure.com/gitea", project: "Test Project", repo: "test_repo"},
+		{cloneAddr: "https://gitea@dev.azure.com/gitea/test_repo/_git/test_repo", collectionURL: "https://dev.azure.com/gitea", project: "test_repo", repo: "test_repo"},
+		{cloneAddr: "https://dev.azure.com/gitea/_git/test_repo", collectionURL: "https://dev.azure.com/gitea", project: "test_repo", repo: "test_repo"},
+		{cloneAddr: "https://gitea.visualstudio.com/Test/_git/test_repo", collectionURL: "https://gitea.visualstudio.com", project: "Test", repo: "test_repo"},
+		{cloneAddr: "https://tfs.example.com/tfs/DefaultCollection/Test/_git/test_repo", collectionURL: "https://tfs.example.com/tfs/DefaultCollection", project: "Test", repo: "test_repo"},
+	} {
+		downloader, err := (&AzureDevOpsDownloaderFactory{}).New(context.Background(), base.MigrateOptions{CloneAddr: testCase.cloneAddr})
+		if assert.NoError(t, err, testCase.cloneAddr) && assert.IsType(t, &AzureDevOpsDownloader{}, downloader, testCase.cloneAddr) {
+			d := downloader.(*AzureDevOpsDownloader)
+			assert.Equal(t, testCase.collectionURL, d.collectionURL.String())
+			assert.Equal(t, testCase.project, d.project)
+			assert.Equal(t, testCase.repo, d.repoName)
+		}
+	}
+
+	_, err := (&AzureDevOpsDownloaderFactory{}).New(context.Background(), base.MigrateOptions{CloneAddr: "https://dev.azure.com/gitea/test_repo"})
+	assert.Error(t, err)
+}
+
+func TestAzureDevOpsDownloadRepo(t *testing.T) {
+	server := azureDevOpsFixtureServer(t, map[string]string{
+		"/gitea/Test/_apis/git/repositories/test_repo?api-version=6.0": `{
+			"id": "5febef5a-833d-4e14-b9c0-14cb638f91e6", "name": "test_repo", "defaultBranch": "refs/heads/master",
+			"remoteUrl": "https://gitea@dev.azure.com/gitea/Test/_git/test_repo", "webUrl": "https://dev.azure.com/gitea/Test/_git/test_repo",
+			"project": {"name": "Test", "description": "Test repository for testing migration from Azure DevOps to Gitea", "visibility": "public"}
+		}`,
+		"/gitea/Test/_apis/wit/classificationnodes/Iterations?%24depth=10&api-version=6.0": `{
+			"name": "Test",
+			"children": [
+				{"name": "Sprint 1", "attributes": {"startDate": "2020-01-06T00:00:00Z", "finishDate": "2020-01-17T00:00:00Z"}},
+				{"name": "Release 2", "children": [{"name": "Sprint 2", "attributes": {"startDate": "2020-01-20T00:00:00Z", "finishDate": "2999-01-31T00:00:00Z"}}]}
+			]
+		}`,
+		"/gitea/Test/_apis/wit/workitemtypes?api-version=6.0": `{"value": [
+			{"name": "Bug", "description": "Describes a divergence between required and actual behavior.", "color": "FFCC293D"},
+			{"name": "Task", "description": "Tracks work that needs to be done.", "color": "F2CB1D"},
+			{"name": "Code Review Request", "color": "FF9D00", "isDisabled": true}
+		]}`,
+		"/gitea/Test/_apis/wit/tags?api-version=6.0-preview.1": `{"value": [{"name": "ui"}]}`,
+		// the work items are queried in pages of 2
+		`/gitea/Test/_apis/wit/wiql?%24top=2&api-version=6.0 {"query":"SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project AND [System.Id] \u003e 0 ORDER BY [System.Id]"}`: `{"workItems": [{"id": 1}, {"id": 2}]}`,
+		`/gitea/Test/_apis/wit/wiql?%24top=2&api-version=6.0 {"query":"SELECT [System.Id] FROM WorkItems WHERE [System.TeamProject] = @project AND [System.Id] \u003e 2 ORDER BY [System.Id]"}`: `{"workItems": [{"id": 3}]}`,
+		"/gitea/Test/_apis/wit/workitemtypes/Bug/states?api-version=6.0":  `{"value": [{"name": "New", "category": "Proposed"}, {"name": "Done", "category": "Completed"}]}`,
+		"/gitea/Test/_apis/wit/workitemtypes/Task/states?api-version=6.0": `{"value": [{"name": "To Do", "category": "Proposed"}, {"name": "Done", "category": "Completed"}]}`,
+		"/gitea/Test/_apis/wit/workitems?api-version=6.0&errorPolicy=omit&ids=1%2C2": `{"value": [
+			{
+				"id": 1,
+				"fields": {
+					"System.WorkItemType": "Bug", "System.State": "Done", "System.Title": "The merge button is broken",
+					"Microsoft.VSTS.TCM.ReproSteps": "<div>Click on the merge button.</div>", "System.IterationPath": "Test\\Sprint 1", "System.Tags": "ui",
+					"System.CreatedBy": {"displayName": "Test User", "uniqueName": "test@example.com"},
+					"System.CreatedDate": "2020-01-07T10:00:00Z", "System.ChangedDate": "2020-01-10T10:00:00Z", "Microsoft.VSTS.Common.ClosedDate": "2020-01-09T10:00:00Z"
+				}
+			},
+			{
+				"id": 2,
+				"fields": {
+					"System.WorkItemType": "Task", "System.State": "To Do", "System.Title": "Add an animated gif to the merge button",
+					"System.Description": "<div>It should hurt the eyes a little.</div>", "System.IterationPath": "Test\\Release 2\\Sprint 2",
+					"System.CreatedBy": {"displayName": "Test User", "uniqueName": "test@example.com"},
+					"System.AssignedTo": {"displayName": "Other User", "uniqueName": "other@example.com"},
+					"System.CreatedDate": "2020-01-21T10:00:00Z", "System.ChangedDate": "2020-01-21T10:00:00Z"
+				}
+			}
+		]}`,
+		"/gitea/Test/_apis/wit/workitems?api-version=6.0&errorPolicy=omit&ids=3": `{"value": [null]}`,
+		"/gitea/Test/_apis/wit/workItems/1/comments?%24top=200&api-version=6.0-preview.3&order=asc": `{
+			"comments": [{"id": 10, "text": "<div>I can reproduce it.</div>", "createdBy": {"displayName": "Other User", "uniqueName": "other@example.com"}, "createdDate": "2020-01-08T10:00:00Z", "modifiedDate": "2020-01-08T10:00:00Z"}],
+			"continuationToken": "next"
+		}`,
+		"/gitea/Test/_apis/wit/workItems/1/comments?%24top=200&api-version=6.0-preview.3&continuationToken=next&order=asc": `{
+			"comments": [
+				{"id": 11, "text": "", "isDeleted": true, "createdDate": "2020-01-08T11:00:00Z", "modifiedDate": "2020-01-08T11:00:00Z"},
+				{"id": 12, "text": "<div>Fixed.</div>", "createdBy": {"displayName": "Test User", "uniqueName": "test@example.com"}, "createdDate": "2020-01-09T10:00:00Z", "modifiedDate": "2020-01-09T10:00:00Z"}
+			]
+		}`,
+		"/gitea/Test/_apis/git/repositories/5febef5a-833d-4e14-b9c0-14cb638f91e6/pullrequests?%24skip=0&%24top=10&api-version=6.0&searchCriteria.status=all": `{"value": [
+			{
+				"pullRequestId": 5, "status": "completed", "title": "Fix the merge button", "description": "Fixes AB#1",
+				"createdBy": {"displayName": "Test User", "uniqueName": "test@example.com"},
+				"creationDate": "2020-01-08T12:00:00Z", "closedDate": "2020-01-09T09:00:00Z",
+				"sourceRefName": "refs/heads/fix-merge", "targetRefName": "refs/heads/master",
+				"lastMergeSourceCommit": {"commitId": "1a2b3c"}, "lastMergeTargetCommit": {"commitId": "4d5e6f"}, "lastMergeCommit": {"commitId": "7a8b9c"},
+				"labels": [{"name": "ui", "active": true}, {"name": "old", "active": false}],
+				"reviewers": [
+					{"displayName": "Other User", "uniqueName": "other@example.com", "vote": 10},
+					{"displayName": "[Test]\\Test Team", "vote": 10, "isContainer": true},
+					{"displayName": "Third User", "uniqueName": "third@example.com", "vote": 0}
+				]
+			},
+			{
+				"pullRequestId": 6, "status": "active", "title": "Animated gif", "description": "",
+				"createdBy": {"displayName": "Other User", "uniqueName": "other@example.com"},
+				"creationDate": "2020-01-22T12:00:00Z",
+				"sourceRefName": "refs/heads/gif", "targetRefName": "refs/heads/master",
+				"lastMergeSourceCommit": {"commitId": "aaaaaa"}, "lastMergeTargetCommit": {"commitId": "bbbbbb"},
+				"forkSource": {"repository": {"name": "test_repo", "remoteUrl": "{{server}}/gitea/Fork/_git/test_repo", "project": {"name": "Fork"}}},
+				"reviewers": [{"displayName": "Test User", "uniqueName": "test@example.com", "vote": -5}]
+			}
+		]}`,
+		"/gitea/Test/_apis/git/repositories/5febef5a-833d-4e14-b9c0-14cb638f91e6/pullRequests/5/threads?api-version=6.0": `{"value": [
+			{
+				"id": 1,
+				"comments": [{"content": "Other User voted 10", "commentType": "system", "author": {"displayName": "Other User"}, "publishedDate": "2020-01-08T13:00:00Z", "lastUpdatedDate": "2020-01-08T13:00:00Z"}]
+			},
+			{
+				"id": 2,
+				"comments": [{"content": "Looks good", "commentType": "text", "author": {"displayName": "Other User", "uniqueName": "other@example.com"}, "publishedDate": "2020-01-08T13:00:00Z", "lastUpdatedDate": "2020-01-08T13:00:00Z"}]
+			},
+			{
+				"id": 3,
+				"threadContext": {"filePath": "/web_src/js/index.js", "rightFileStart": {"line": 4, "offset": 1}, "rightFileEnd": {"line": 4, "offset": 10}},
+				"comments": [
+					{"content": "Typo", "commentType": "text", "author": {"displayName": "Other User", "uniqueName": "other@example.com"}, "publishedDate": "2020-01-08T12:30:00Z", "lastUpdatedDate": "2020-01-08T12:30:00Z"},
+					{"content": "Fixed", "commentType": "text", "author": {"displayName": "Test User", "uniqueName": "test@example.com"}, "publishedDate": "2020-01-08T12:40:00Z", "lastUpdatedDate": "2020-01-08T12:40:00Z"}
+				]
+			},
+			{
+				"id": 4,
+				"threadContext": {"filePath": "/README.md", "leftFileStart": {"line": 2, "offset": 1}, "leftFileEnd": {"line": 2, "offset": 5}},
+				"comments": [{"content": "Why is it removed?", "commentType": "text", "author": {"displayName": "Other User", "uniqueName": "other@example.com"}, "publishedDate": "2020-01-08T12:35:00Z", "lastUpdatedDate": "2020-01-08T12:35:00Z"}]
+			},
+			{
+				"id": 5, "isDeleted": true,
+				"comments": [{"content": "Deleted", "commentType": "text", "author": {"displayName": "Other User"}, "publishedDate": "2020-01-08T12:35:00Z", "lastUpdatedDate": "2020-01-08T12:35:00Z"}]
+			}
+		]}`,
+	})
+	defer server.Close()
+
+	downloader := NewAzureDevOpsDownloader(context.Background(), server.URL+"/gitea", "Test", "test_repo", "")
+	downloader.client = server.Client()
+	downloader.wiqlPageSize = 2
+
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "Test",
+		Description:   "Test repository for testing migration from Azure DevOps to Gitea",
+		CloneURL:      "https://dev.azure.com/gitea/Test/_git/test_repo",
+		OriginalURL:   "https://dev.azure.com/gitea/Test/_git/test_repo",
+		DefaultBranch: "master",
+	}, repo)
+
+	milestones, err := downloader.GetMilestones()
+	assert.NoError(t, err)
+	assertMilestonesEqual(t, []*base.Milestone{
+		{
+			Title:    "Sprint 1",
+			Created:  time.Date(2020, 1, 6, 0, 0, 0, 0, time.UTC),
+			Deadline: timePtr(time.Date(2020, 1, 17, 0, 0, 0, 0, time.UTC)),
+			Closed:   timePtr(time.Date(2020, 1, 17, 0, 0, 0, 0, time.UTC)),
+			State:    "closed",
+		},
+		{
+			Title: "Release 2",
+			State: "open",
+		},
+		{
+			Title:    "Release 2/Sprint 2",
+			Created:  time.Date(2020, 1, 20, 0, 0, 0, 0, time.UTC),
+			Deadline: timePtr(time.Date(2999, 1, 31, 0, 0, 0, 0, time.UTC)),
+			State:    "open",
+		},
+	}, milestones)
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assertLabelsEqual(t, []*base.Label{
+		{Name: "type/Bug", Color: "cc293d", Description: "Describes a divergence between required and actual behavior.", Exclusive: true},
+		{Name: "type/Task", Color: "f2cb1d", Description: "Tracks work that needs to be done.", Exclusive: true},
+		{Name: "ui", Color: "bfd4f2"},
+	}, labels)
+
+	issues, isEnd, err := downloader.GetIssues(1, 2)
+	assert.NoError(t, err)
+	assert.False(t, isEnd)
+	assertIssuesEqual(t, []*base.Issue{
+		{
+			Number:      1,
+			Title:       "The merge button is broken",
+			Content:     "<div>Click on the merge button.</div>",
+			PosterName:  "Test User",
+			PosterEmail: "test@example.com",
+			Milestone:   "Sprint 1",
+			State:       "closed",
+			Created:     time.Date(2020, 1, 7, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2020, 1, 10, 10, 0, 0, 0, time.UTC),
+			Closed:      timePtr(time.Date(2020, 1, 9, 10, 0, 0, 0, time.UTC)),
+			Labels:      []*base.Label{{Name: "type/Bug"}, {Name: "ui"}},
+		},
+		{
+			Number:      2,
+			Title:       "Add an animated gif to the merge button",
+			Content:     "<div>It should hurt the eyes a little.</div>",
+			PosterName:  "Test User",
+			PosterEmail: "test@example.com",
+			Milestone:   "Release 2/Sprint 2",
+			State:       "open",
+			Created:     time.Date(2020, 1, 21, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2020, 1, 21, 10, 0, 0, 0, time.UTC),
+			Labels:      []*base.Label{{Name: "type/Task"}},
+			Assignees:   []string{"other@example.com"},
+		},
+	}, issues)
+
+	// the third work item has been deleted
+	issues, isEnd, err = downloader.GetIssues(2, 2)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assert.Empty(t, issues)
+
+	comments, _, err := downloader.GetComments(&base.Issue{Number: 1, ForeignIndex: 1, Context: azureDevOpsIssueContext{}})
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			PosterName:  "Other User",
+			PosterEmail: "other@example.com",
+			Content:     "<div>I can reproduce it.</div>",
+			Created:     time.Date(2020, 1, 8, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2020, 1, 8, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			IssueIndex:  1,
+			PosterName:  "Test User",
+			PosterEmail: "test@example.com",
+			Content:     "<div>Fixed.</div>",
+			Created:     time.Date(2020, 1, 9, 10, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2020, 1, 9, 10, 0, 0, 0, time.UTC),
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 10)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:         7,
+			Title:          "Fix the merge button",
+			Content:        "Fixes AB#1",
+			PosterName:     "Test User",
+			PosterEmail:    "test@example.com",
+			State:          "closed",
+			Created:        time.Date(2020, 1, 8, 12, 0, 0, 0, time.UTC),
+			Updated:        time.Date(2020, 1, 9, 9, 0, 0, 0, time.UTC),
+			Closed:         timePtr(time.Date(2020, 1, 9, 9, 0, 0, 0, time.UTC)),
+			Labels:         []*base.Label{{Name: "ui"}},
+			Merged:         true,
+			MergedTime:     timePtr(time.Date(2020, 1, 9, 9, 0, 0, 0, time.UTC)),
+			MergeCommitSHA: "7a8b9c",
+			Head: base.PullRequestBranch{
+				Ref:       "fix-merge",
+				SHA:       "1a2b3c",
+				OwnerName: "Test",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "master",
+				SHA:       "4d5e6f",
+				OwnerName: "Test",
+				RepoName:  "test_repo",
+			},
+		},
+		{
+			Number:      8,
+			Title:       "Animated gif",
+			PosterName:  "Other User",
+			PosterEmail: "other@example.com",
+			State:       "open",
+			Created:     time.Date(2020, 1, 22, 12, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2020, 1, 22, 12, 0, 0, 0, time.UTC),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/gitea/Fork/_git/test_repo",
+				Ref:       "gif",
+				SHA:       "aaaaaa",
+				OwnerName: "Fork",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "master",
+				SHA:       "bbbbbb",
+				OwnerName: "Test",
+				RepoName:  "test_repo",
+			},
+		},
+	}, prs)
+
+	comments, _, err = downloader.GetComments(prs[0])
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  7,
+			PosterName:  "Other User",
+			PosterEmail: "other@example.com",
+			Content:     "Looks good",
+			Created:     time.Date(2020, 1, 8, 13, 0, 0, 0, time.UTC),
+			Updated:     time.Date(2020, 1, 8, 13, 0, 0, 0, time.UTC),
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(prs[0])
+	assert.NoError(t, err)
+	assertReviewsEqual(t, []*base.Review{
+		{
+			IssueIndex:   7,
+			ReviewerName: "Other User",
+			State:        base.ReviewStateApproved,
+		},
+		{
+			IssueIndex:   7,
+			ReviewerName: "Other User",
+			CreatedAt:    time.Date(2020, 1, 8, 12, 30, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					Content:   "Typo",
+					TreePath:  "web_src/js/index.js",
+					Line:      4,
+					CreatedAt: time.Date(2020, 1, 8, 12, 30, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2020, 1, 8, 12, 30, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			IssueIndex:   7,
+			ReviewerName: "Test User",
+			CreatedAt:    time.Date(2020, 1, 8, 12, 40, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					Content:   "Fixed",
+					TreePath:  "web_src/js/index.js",
+					Line:      4,
+					CreatedAt: time.Date(2020, 1, 8, 12, 40, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2020, 1, 8, 12, 40, 0, 0, time.UTC),
+				},
+			},
+		},
+		{
+			IssueIndex:   7,
+			ReviewerName: "Other User",
+			CreatedAt:    time.Date(2020, 1, 8, 12, 35, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					Content:   "Why is it removed?",
+					TreePath:  "README.md",
+					Line:      -2,
+					CreatedAt: time.Date(2020, 1, 8, 12, 35, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2020, 1, 8, 12, 35, 0, 0, time.UTC),
+				},
+			},
+		},
+	}, reviews)
+}
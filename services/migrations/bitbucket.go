@@ -0,0 +1,708 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+var (
+	_ base.Downloader        = &BitbucketDownloader{}
+	_ base.DownloaderFactory = &BitbucketDownloaderFactory{}
+)
+
+func init() {
+	RegisterDownloaderFactory(&BitbucketDownloaderFactory{})
+}
+
+// BitbucketDownloaderFactory defines a bitbucket downloader factory
+type BitbucketDownloaderFactory struct{}
+
+// New returns a Downloader related to this factory according MigrateOptions.
+// Repositories hosted on bitbucket.org are downloaded from Bitbucket Cloud, any other host is expected to be a Bitbucket Server (or Data Center)
+func (f *BitbucketDownloaderFactory) New(ctx context.Context, opts base.MigrateOptions) (base.Downloader, error) {
+	u, err := url.Parse(opts.CloneAddr)
+	if err != nil {
+		return nil, err
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+
+	if host := strings.ToLower(u.Hostname()); host == "bitbucket.org" || host == "www.bitbucket.org" {
+		fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("invalid path: %s", u.Path)
+		}
+		workspace, repoName := fields[0], strings.TrimSuffix(fields[1], ".git")
+
+		log.Trace("Create Bitbucket Cloud downloader. Workspace: %s RepoName: %s", workspace, repoName)
+
+		return NewBitbucketDownloader(ctx, "https://bitbucket.org", "https://api.bitbucket.org/2.0", workspace, repoName, opts.AuthUsername, opts.AuthPassword), nil
+	}
+
+	baseURL, projectKey, repoName, err := parseBitbucketServerURL(u)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Trace("Create Bitbucket Server downloader. BaseURL: %s Project: %s RepoName: %s", baseURL, projectKey, repoName)
+
+	return NewBitbucketServerDownloader(ctx, baseURL, projectKey, repoName, opts.AuthUsername, opts.AuthPassword), nil
+}
+
+// GitServiceType returns the type of git service
+func (f *BitbucketDownloaderFactory) GitServiceType() structs.GitServiceType {
+	return structs.BitbucketService
+}
+
+type bitbucketUser struct {
+	DisplayName string `json:"display_name"`
+	Nickname    string `json:"nickname"`
+}
+
+func (u *bitbucketUser) name() string {
+	if u == nil {
+		return "Ghost"
+	}
+	if u.Nickname != "" {
+		return u.Nickname
+	}
+	return u.DisplayName
+}
+
+type bitbucketContent struct {
+	Raw string `json:"raw"`
+}
+
+type bitbucketIssueContext struct {
+	IsPullRequest bool
+}
+
+// BitbucketDownloader implements a Downloader interface to get repository information
+// from Bitbucket Cloud
+type BitbucketDownloader struct {
+	base.NullDownloader
+	ctx           context.Context
+	client        *http.Client
+	baseURL       *url.URL
+	apiURL        *url.URL
+	workspace     string
+	repoName      string
+	username      string
+	password      string
+	hasIssues     bool
+	maxIssueIndex int64
+}
+
+// NewBitbucketDownloader creates a Bitbucket Cloud downloader, baseURL is the site where the repositories are browsed and cloned and apiURL the root of the 2.0 API
+func NewBitbucketDownloader(ctx context.Context, baseURL, apiURL, workspace, repoName, username, password string) *BitbucketDownloader {
+	u, _ := url.Parse(strings.TrimSuffix(baseURL, "/"))
+	api, _ := url.Parse(strings.TrimSuffix(apiURL, "/") + "/")
+
+	return &BitbucketDownloader{
+		ctx:       ctx,
+		client:    NewMigrationHTTPClient(),
+		baseURL:   u,
+		apiURL:    api,
+		workspace: workspace,
+		repoName:  repoName,
+		username:  username,
+		password:  password,
+	}
+}
+
+// SetContext set context
+func (d *BitbucketDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// String implements Stringer
+func (d *BitbucketDownloader) String() string {
+	return fmt.Sprintf("migration from bitbucket cloud %s %s/%s", d.baseURL, d.workspace, d.repoName)
+}
+
+func (d *BitbucketDownloader) LogString() string {
+	if d == nil {
+		return "<BitbucketDownloader nil>"
+	}
+	return fmt.Sprintf("<BitbucketDownloader %s %s/%s>", d.baseURL, d.workspace, d.repoName)
+}
+
+func (d *BitbucketDownloader) callAPI(endpoint string, parameter url.Values, result interface{}) error {
+	u, err := d.apiURL.Parse(fmt.Sprintf("repositories/%s/%s", url.PathEscape(d.workspace), url.PathEscape(d.repoName)) + endpoint)
+	if err != nil {
+		return err
+	}
+	if parameter != nil {
+		u.RawQuery = parameter.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	if len(d.username) > 0 && len(d.password) > 0 {
+		req.SetBasicAuth(d.username, d.password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from %s: %s", u.Path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// bitbucketPage is the envelope of the paginated responses
+type bitbucketPage[T any] struct {
+	Values []T    `json:"values"`
+	Next   string `json:"next"`
+}
+
+// callBitbucketPagedAPI requests one page of a paginated endpoint, the returned bool is true if it was the last page
+func callBitbucketPagedAPI[T any](d *BitbucketDownloader, endpoint string, parameter url.Values, page, perPage int) ([]T, bool, error) {
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	// the API refuses pages with more than 50 items
+	if perPage <= 0 || perPage > 50 {
+		perPage = 50
+	}
+	parameter.Set("page", strconv.Itoa(page))
+	parameter.Set("pagelen", strconv.Itoa(perPage))
+
+	var result bitbucketPage[T]
+	if err := d.callAPI(endpoint, parameter, &result); err != nil {
+		return nil, false, err
+	}
+	return result.Values, result.Next == "", nil
+}
+
+// callBitbucketAllPages requests all the pages of a paginated endpoint
+func callBitbucketAllPages[T any](d *BitbucketDownloader, endpoint string, parameter url.Values) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		values, isEnd, err := callBitbucketPagedAPI[T](d, endpoint, parameter, page, 50)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, values...)
+		if isEnd || len(values) == 0 {
+			return all, nil
+		}
+	}
+}
+
+// GetRepoInfo returns repository information
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-repositories/#api-repositories-workspace-repo-slug-get
+func (d *BitbucketDownloader) GetRepoInfo() (*base.Repository, error) {
+	var rawRepo struct {
+		FullName    string `json:"full_name"`
+		Description string `json:"description"`
+		IsPrivate   bool   `json:"is_private"`
+		HasIssues   bool   `json:"has_issues"`
+		MainBranch  *struct {
+			Name string `json:"name"`
+		} `json:"mainbranch"`
+		Links struct {
+			HTML struct {
+				Href string `json:"href"`
+			} `json:"html"`
+		} `json:"links"`
+	}
+
+	if err := d.callAPI("", nil, &rawRepo); err != nil {
+		return nil, err
+	}
+	d.hasIssues = rawRepo.HasIssues
+
+	owner, name, _ := strings.Cut(rawRepo.FullName, "/")
+	if name == "" {
+		owner, name = d.workspace, d.repoName
+	}
+
+	cloneURL, err := d.baseURL.Parse(d.baseURL.Path + "/" + owner + "/" + name + ".git")
+	if err != nil {
+		return nil, err
+	}
+	originalURL := rawRepo.Links.HTML.Href
+	if originalURL == "" {
+		originalURL = strings.TrimSuffix(cloneURL.String(), ".git")
+	}
+
+	repo := &base.Repository{
+		Name:        name,
+		Owner:       owner,
+		IsPrivate:   rawRepo.IsPrivate,
+		Description: rawRepo.Description,
+		CloneURL:    cloneURL.String(),
+		OriginalURL: originalURL,
+	}
+	if rawRepo.MainBranch != nil {
+		repo.DefaultBranch = rawRepo.MainBranch.Name
+	}
+	return repo, nil
+}
+
+// GetMilestones returns the milestones of the issue tracker
+func (d *BitbucketDownloader) GetMilestones() ([]*base.Milestone, error) {
+	if !d.hasIssues {
+		return []*base.Milestone{}, nil
+	}
+
+	rawMilestones, err := callBitbucketAllPages[struct {
+		Name string `json:"name"`
+	}](d, "/milestones", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	milestones := make([]*base.Milestone, 0, len(rawMilestones))
+	for _, milestone := range rawMilestones {
+		milestones = append(milestones, &base.Milestone{
+			Title: milestone.Name,
+			State: "open",
+		})
+	}
+	return milestones, nil
+}
+
+var (
+	bitbucketIssueKinds      = []string{"bug", "enhancement", "proposal", "task"}
+	bitbucketIssuePriorities = []string{"trivial", "minor", "major", "critical", "blocker"}
+)
+
+// GetLabels returns labels, the kinds, priorities and components of the issue tracker are converted to exclusive scoped labels
+func (d *BitbucketDownloader) GetLabels() ([]*base.Label, error) {
+	if !d.hasIssues {
+		return []*base.Label{}, nil
+	}
+
+	labels := make([]*base.Label, 0, 20)
+	kindColors := map[string]string{"bug": "ee0701", "enhancement": "84b6eb", "proposal": "cc317c", "task": "009800"}
+	for _, kind := range bitbucketIssueKinds {
+		labels = append(labels, &base.Label{Name: "kind/" + kind, Color: kindColors[kind], Exclusive: true})
+	}
+	priorityColors := map[string]string{"trivial": "c2e0c6", "minor": "fef2c0", "major": "fbca04", "critical": "e99695", "blocker": "b60205"}
+	for _, priority := range bitbucketIssuePriorities {
+		labels = append(labels, &base.Label{Name: "priority/" + priority, Color: priorityColors[priority], Exclusive: true})
+	}
+
+	components, err := callBitbucketAllPages[struct {
+		Name string `json:"name"`
+	}](d, "/components", nil)
+	if err != nil {
+		return nil, err
+	}
+	for _, component := range components {
+		labels = append(labels, &base.Label{Name: "component/" + component.Name, Color: "bfd4f2", Exclusive: true})
+	}
+	return labels, nil
+}
+
+// GetIssues returns issues
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-issue-tracker/#api-repositories-workspace-repo-slug-issues-get
+func (d *BitbucketDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	if !d.hasIssues {
+		return []*base.Issue{}, true, nil
+	}
+
+	type namedObject struct {
+		Name string `json:"name"`
+	}
+	rawIssues, isEnd, err := callBitbucketPagedAPI[struct {
+		ID        int64            `json:"id"`
+		Title     string           `json:"title"`
+		Content   bitbucketContent `json:"content"`
+		Reporter  *bitbucketUser   `json:"reporter"`
+		Assignee  *bitbucketUser   `json:"assignee"`
+		State     string           `json:"state"`
+		Kind      string           `json:"kind"`
+		Priority  string           `json:"priority"`
+		Milestone *namedObject     `json:"milestone"`
+		Component *namedObject     `json:"component"`
+		CreatedOn time.Time        `json:"created_on"`
+		UpdatedOn *time.Time       `json:"updated_on"`
+	}](d, "/issues", url.Values{"sort": {"id"}}, page, perPage)
+	if err != nil {
+		return nil, false, err
+	}
+
+	issues := make([]*base.Issue, 0, len(rawIssues))
+	for _, issue := range rawIssues {
+		labels := make([]*base.Label, 0, 3)
+		if issue.Kind != "" {
+			labels = append(labels, &base.Label{Name: "kind/" + issue.Kind})
+		}
+		if issue.Priority != "" {
+			labels = append(labels, &base.Label{Name: "priority/" + issue.Priority})
+		}
+		if issue.Component != nil {
+			labels = append(labels, &base.Label{Name: "component/" + issue.Component.Name})
+		}
+
+		updated := issue.CreatedOn
+		if issue.UpdatedOn != nil {
+			updated = *issue.UpdatedOn
+		}
+
+		state := "open"
+		var closed *time.Time
+		switch issue.State {
+		case "resolved", "invalid", "duplicate", "wontfix", "closed":
+			state = "closed"
+			closed = &updated
+		}
+
+		var assignees []string
+		if issue.Assignee != nil {
+			assignees = append(assignees, issue.Assignee.name())
+		}
+
+		var milestone string
+		if issue.Milestone != nil {
+			milestone = issue.Milestone.Name
+		}
+
+		issues = append(issues, &base.Issue{
+			Number:       issue.ID,
+			Title:        issue.Title,
+			Content:      issue.Content.Raw,
+			PosterName:   issue.Reporter.name(),
+			Milestone:    milestone,
+			State:        state,
+			Created:      issue.CreatedOn,
+			Updated:      updated,
+			Closed:       closed,
+			Labels:       labels,
+			Assignees:    assignees,
+			ForeignIndex: issue.ID,
+			Context:      bitbucketIssueContext{IsPullRequest: false},
+		})
+
+		if d.maxIssueIndex < issue.ID {
+			d.maxIssueIndex = issue.ID
+		}
+	}
+
+	return issues, isEnd, nil
+}
+
+type bitbucketComment struct {
+	ID        int64            `json:"id"`
+	Content   bitbucketContent `json:"content"`
+	User      *bitbucketUser   `json:"user"`
+	CreatedOn time.Time        `json:"created_on"`
+	UpdatedOn *time.Time       `json:"updated_on"`
+	Deleted   bool             `json:"deleted"`
+	Inline    *struct {
+		Path string `json:"path"`
+		From *int   `json:"from"`
+		To   *int   `json:"to"`
+	} `json:"inline"`
+	Parent *struct {
+		ID int64 `json:"id"`
+	} `json:"parent"`
+}
+
+func (c *bitbucketComment) updated() time.Time {
+	if c.UpdatedOn != nil {
+		return *c.UpdatedOn
+	}
+	return c.CreatedOn
+}
+
+func (d *BitbucketDownloader) getComments(commentable base.Commentable) ([]bitbucketComment, error) {
+	context, ok := commentable.GetContext().(bitbucketIssueContext)
+	if !ok {
+		return nil, fmt.Errorf("unexpected context: %+v", commentable.GetContext())
+	}
+
+	if context.IsPullRequest {
+		return callBitbucketAllPages[bitbucketComment](d, fmt.Sprintf("/pullrequests/%d/comments", commentable.GetForeignIndex()), nil)
+	}
+	return callBitbucketAllPages[bitbucketComment](d, fmt.Sprintf("/issues/%d/comments", commentable.GetForeignIndex()), nil)
+}
+
+// GetComments returns the comments of an issue or the general comments of a pull request, the inline comments of a pull request are returned by GetReviews
+func (d *BitbucketDownloader) GetComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	rawComments, err := d.getComments(commentable)
+	if err != nil {
+		return nil, false, err
+	}
+
+	comments := make([]*base.Comment, 0, len(rawComments))
+	for _, comment := range rawComments {
+		// the changes of the issue state have an empty comment
+		if comment.Deleted || comment.Inline != nil || len(comment.Content.Raw) == 0 {
+			continue
+		}
+		comments = append(comments, &base.Comment{
+			IssueIndex: commentable.GetLocalIndex(),
+			Index:      comment.ID,
+			PosterName: comment.User.name(),
+			Content:    comment.Content.Raw,
+			Created:    comment.CreatedOn,
+			Updated:    comment.updated(),
+		})
+	}
+	return comments, true, nil
+}
+
+type bitbucketPullRequestBranch struct {
+	Branch struct {
+		Name string `json:"name"`
+	} `json:"branch"`
+	Commit *struct {
+		Hash string `json:"hash"`
+	} `json:"commit"`
+	Repository *struct {
+		FullName string `json:"full_name"`
+	} `json:"repository"`
+}
+
+func (d *BitbucketDownloader) convertPullRequestBranch(branch *bitbucketPullRequestBranch) base.PullRequestBranch {
+	ret := base.PullRequestBranch{
+		Ref:       branch.Branch.Name,
+		OwnerName: d.workspace,
+		RepoName:  d.repoName,
+	}
+	if branch.Commit != nil {
+		ret.SHA = branch.Commit.Hash
+	}
+	if branch.Repository != nil {
+		if owner, name, ok := strings.Cut(branch.Repository.FullName, "/"); ok && !(strings.EqualFold(owner, d.workspace) && strings.EqualFold(name, d.repoName)) {
+			ret.OwnerName, ret.RepoName = owner, name
+			ret.CloneURL = d.baseURL.String() + "/" + owner + "/" + name + ".git"
+		}
+	}
+	return ret
+}
+
+// GetPullRequests returns pull requests, as they are numbered independently from the issues their number is offset by the highest issue number
+// https://developer.atlassian.com/cloud/bitbucket/rest/api-group-pullrequests/#api-repositories-workspace-repo-slug-pullrequests-get
+func (d *BitbucketDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	rawPullRequests, isEnd, err := callBitbucketPagedAPI[struct {
+		ID          int64                      `json:"id"`
+		Title       string                     `json:"title"`
+		Description string                     `json:"description"`
+		State       string                     `json:"state"`
+		Author      *bitbucketUser             `json:"author"`
+		Source      bitbucketPullRequestBranch `json:"source"`
+		Destination bitbucketPullRequestBranch `json:"destination"`
+		MergeCommit *struct {
+			Hash string `json:"hash"`
+		} `json:"merge_commit"`
+		CreatedOn time.Time  `json:"created_on"`
+		UpdatedOn *time.Time `json:"updated_on"`
+	}](d, "/pullrequests", url.Values{
+		"state": {"OPEN", "MERGED", "DECLINED", "SUPERSEDED"},
+		"sort":  {"id"},
+	}, page, perPage)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests))
+	for _, pr := range rawPullRequests {
+		updated := pr.CreatedOn
+		if pr.UpdatedOn != nil {
+			updated = *pr.UpdatedOn
+		}
+
+		state := "open"
+		merged := false
+		var closeTime, mergedTime *time.Time
+		var mergeCommitSHA string
+		if pr.State != "OPEN" {
+			state = "closed"
+			closeTime = &updated
+			if pr.State == "MERGED" {
+				merged = true
+				mergedTime = &updated
+				if pr.MergeCommit != nil {
+					mergeCommitSHA = pr.MergeCommit.Hash
+				}
+			}
+		}
+
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         pr.ID + d.maxIssueIndex,
+			Title:          pr.Title,
+			PosterName:     pr.Author.name(),
+			Content:        pr.Description,
+			State:          state,
+			Created:        pr.CreatedOn,
+			Updated:        updated,
+			Closed:         closeTime,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head:           d.convertPullRequestBranch(&pr.Source),
+			Base:           d.convertPullRequestBranch(&pr.Destination),
+			ForeignIndex:   pr.ID,
+			Context:        bitbucketIssueContext{IsPullRequest: true},
+		})
+
+		// SECURITY: Ensure that the PR is safe
+		_ = CheckAndEnsureSafePR(pullRequests[len(pullRequests)-1], d.baseURL.String(), d)
+	}
+
+	return pullRequests, isEnd, nil
+}
+
+// GetReviews returns the approvals, the requested changes and the inline comments of a pull request
+func (d *BitbucketDownloader) GetReviews(reviewable base.Reviewable) ([]*base.Review, error) {
+	var rawPullRequest struct {
+		Participants []struct {
+			User           *bitbucketUser `json:"user"`
+			Approved       bool           `json:"approved"`
+			State          string         `json:"state"`
+			ParticipatedOn *time.Time     `json:"participated_on"`
+		} `json:"participants"`
+	}
+	if err := d.callAPI(fmt.Sprintf("/pullrequests/%d", reviewable.GetForeignIndex()), nil, &rawPullRequest); err != nil {
+		return nil, err
+	}
+
+	reviews := make([]*base.Review, 0, len(rawPullRequest.Participants))
+	for _, participant := range rawPullRequest.Participants {
+		var state string
+		switch {
+		case participant.Approved || participant.State == "approved":
+			state = base.ReviewStateApproved
+		case participant.State == "changes_requested":
+			state = base.ReviewStateChangesRequested
+		default:
+			continue
+		}
+		review := &base.Review{
+			IssueIndex:   reviewable.GetLocalIndex(),
+			ReviewerName: participant.User.name(),
+			State:        state,
+		}
+		if participant.ParticipatedOn != nil {
+			review.CreatedAt = *participant.ParticipatedOn
+		}
+		reviews = append(reviews, review)
+	}
+
+	commentable, ok := reviewable.(base.Commentable)
+	if !ok {
+		return nil, fmt.Errorf("unexpected reviewable: %+v", reviewable)
+	}
+	rawComments, err := d.getComments(commentable)
+	if err != nil {
+		return nil, err
+	}
+	for _, comment := range rawComments {
+		if comment.Deleted || comment.Inline == nil || len(comment.Content.Raw) == 0 {
+			continue
+		}
+
+		// a negative line is a line of the old version of the file
+		var line int
+		if comment.Inline.To != nil {
+			line = *comment.Inline.To
+		} else if comment.Inline.From != nil {
+			line = -*comment.Inline.From
+		}
+
+		var inReplyTo int64
+		if comment.Parent != nil {
+			inReplyTo = comment.Parent.ID
+		}
+
+		reviews = append(reviews, &base.Review{
+			IssueIndex:   reviewable.GetLocalIndex(),
+			ReviewerName: comment.User.name(),
+			CreatedAt:    comment.CreatedOn,
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					ID:        comment.ID,
+					InReplyTo: inReplyTo,
+					Content:   comment.Content.Raw,
+					TreePath:  comment.Inline.Path,
+					Line:      line,
+					CreatedAt: comment.CreatedOn,
+					UpdatedAt: comment.updated(),
+				},
+			},
+		})
+	}
+
+	return reviews, nil
+}
+
+// GetReleases returns the tags of the repository as releases, Bitbucket has no other concept of releases
+func (d *BitbucketDownloader) GetReleases() ([]*base.Release, error) {
+	rawTags, err := callBitbucketAllPages[struct {
+		Name    string     `json:"name"`
+		Message string     `json:"message"`
+		Date    *time.Time `json:"date"`
+		Tagger  *struct {
+			User *bitbucketUser `json:"user"`
+		} `json:"tagger"`
+		Target struct {
+			Hash   string    `json:"hash"`
+			Date   time.Time `json:"date"`
+			Author *struct {
+				User *bitbucketUser `json:"user"`
+			} `json:"author"`
+		} `json:"target"`
+	}](d, "/refs/tags", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]*base.Release, 0, len(rawTags))
+	for _, tag := range rawTags {
+		published := tag.Target.Date
+		if tag.Date != nil {
+			published = *tag.Date
+		}
+
+		var publisher string
+		if tag.Tagger != nil && tag.Tagger.User != nil {
+			publisher = tag.Tagger.User.name()
+		} else if tag.Target.Author != nil && tag.Target.Author.User != nil {
+			publisher = tag.Target.Author.User.name()
+		}
+
+		releases = append(releases, &base.Release{
+			TagName:         tag.Name,
+			TargetCommitish: tag.Target.Hash,
+			Name:            tag.Name,
+			Body:            strings.TrimSpace(tag.Message),
+			PublisherName:   publisher,
+			Created:         published,
+			Published:       published,
+		})
+	}
+	return releases, nil
+}
+
+// GetTopics return repository topics, Bitbucket has no topics
+func (d *BitbucketDownloader) GetTopics() ([]string, error) {
+	return []string{}, nil
+}
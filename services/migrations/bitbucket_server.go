@@ -0,0 +1,567 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	base "code.gitea.io/gitea/modules/migration"
+)
+
+var _ base.Downloader = &BitbucketServerDownloader{}
+
+// parseBitbucketServerURL returns the base URL of the server, the project key and the repository slug from the URLs
+// used to browse (/projects/KEY/repos/slug, /users/name/repos/slug) or to clone (/scm/key/slug.git) a repository
+func parseBitbucketServerURL(u *url.URL) (baseURL, projectKey, repoName string, err error) {
+	fields := strings.Split(strings.Trim(u.Path, "/"), "/")
+	for i := 0; i < len(fields); i++ {
+		switch {
+		case fields[i] == "scm" && i+2 < len(fields):
+			projectKey, repoName = fields[i+1], fields[i+2]
+		case fields[i] == "projects" && i+3 < len(fields) && fields[i+2] == "repos":
+			projectKey, repoName = fields[i+1], fields[i+3]
+		case fields[i] == "users" && i+3 < len(fields) && fields[i+2] == "repos":
+			// the project of a personal repository is the user name prefixed by a tilde
+			projectKey, repoName = "~"+fields[i+1], fields[i+3]
+		default:
+			continue
+		}
+
+		baseURL = u.Scheme + "://" + u.Host
+		if i > 0 {
+			// the server is hosted under a context path
+			baseURL += "/" + strings.Join(fields[:i], "/")
+		}
+		return baseURL, projectKey, strings.TrimSuffix(repoName, ".git"), nil
+	}
+	return "", "", "", fmt.Errorf("invalid path: %s", u.Path)
+}
+
+type bitbucketServerUser struct {
+	ID           int64  `json:"id"`
+	Name         string `json:"name"`
+	EmailAddress string `json:"emailAddress"`
+}
+
+func (u *bitbucketServerUser) name() string {
+	if u == nil {
+		return "Ghost"
+	}
+	return u.Name
+}
+
+// bitbucketServerTime is a timestamp in milliseconds
+type bitbucketServerTime int64
+
+func (t bitbucketServerTime) Time() time.Time {
+	return time.UnixMilli(int64(t))
+}
+
+func (t *bitbucketServerTime) TimePtr() *time.Time {
+	if t == nil || *t == 0 {
+		return nil
+	}
+	tm := t.Time()
+	return &tm
+}
+
+// BitbucketServerDownloader implements a Downloader interface to get repository information
+// from Bitbucket Server and Bitbucket Data Center.
+// The issues are tracked outside of the server (usually in Jira), only the pull requests are migrated.
+type BitbucketServerDownloader struct {
+	base.NullDownloader
+	ctx        context.Context
+	client     *http.Client
+	baseURL    *url.URL
+	projectKey string
+	repoName   string
+	username   string
+	password   string
+}
+
+// NewBitbucketServerDownloader creates a Bitbucket Server downloader
+func NewBitbucketServerDownloader(ctx context.Context, baseURL, projectKey, repoName, username, password string) *BitbucketServerDownloader {
+	u, _ := url.Parse(strings.TrimSuffix(baseURL, "/"))
+
+	return &BitbucketServerDownloader{
+		ctx:        ctx,
+		client:     NewMigrationHTTPClient(),
+		baseURL:    u,
+		projectKey: projectKey,
+		repoName:   repoName,
+		username:   username,
+		password:   password,
+	}
+}
+
+// SetContext set context
+func (d *BitbucketServerDownloader) SetContext(ctx context.Context) {
+	d.ctx = ctx
+}
+
+// String implements Stringer
+func (d *BitbucketServerDownloader) String() string {
+	return fmt.Sprintf("migration from bitbucket server %s %s/%s", d.baseURL, d.projectKey, d.repoName)
+}
+
+func (d *BitbucketServerDownloader) LogString() string {
+	if d == nil {
+		return "<BitbucketServerDownloader nil>"
+	}
+	return fmt.Sprintf("<BitbucketServerDownloader %s %s/%s>", d.baseURL, d.projectKey, d.repoName)
+}
+
+func (d *BitbucketServerDownloader) callAPI(endpoint string, parameter url.Values, result interface{}) error {
+	u, err := d.baseURL.Parse(fmt.Sprintf("%s/rest/api/1.0/projects/%s/repos/%s", d.baseURL.Path, url.PathEscape(d.projectKey), url.PathEscape(d.repoName)) + endpoint)
+	if err != nil {
+		return err
+	}
+	if parameter != nil {
+		u.RawQuery = parameter.Encode()
+	}
+
+	req, err := http.NewRequestWithContext(d.ctx, "GET", u.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Add("Accept", "application/json")
+	if len(d.username) > 0 && len(d.password) > 0 {
+		req.SetBasicAuth(d.username, d.password)
+	} else if len(d.password) > 0 {
+		// a personal access token can be used without user name
+		req.Header.Set("Authorization", "Bearer "+d.password)
+	}
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected response from %s: %s", u.Path, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(result)
+}
+
+// bitbucketServerPage is the envelope of the paginated responses
+type bitbucketServerPage[T any] struct {
+	Values     []T  `json:"values"`
+	IsLastPage bool `json:"isLastPage"`
+}
+
+// callBitbucketServerPagedAPI requests one page of a paginated endpoint, the returned bool is true if it was the last page
+func callBitbucketServerPagedAPI[T any](d *BitbucketServerDownloader, endpoint string, parameter url.Values, page, perPage int) ([]T, bool, error) {
+	if parameter == nil {
+		parameter = url.Values{}
+	}
+	parameter.Set("start", strconv.Itoa((page-1)*perPage))
+	parameter.Set("limit", strconv.Itoa(perPage))
+
+	var result bitbucketServerPage[T]
+	if err := d.callAPI(endpoint, parameter, &result); err != nil {
+		return nil, false, err
+	}
+	return result.Values, result.IsLastPage, nil
+}
+
+// callBitbucketServerAllPages requests all the pages of a paginated endpoint
+func callBitbucketServerAllPages[T any](d *BitbucketServerDownloader, endpoint string, parameter url.Values) ([]T, error) {
+	var all []T
+	for page := 1; ; page++ {
+		values, isEnd, err := callBitbucketServerPagedAPI[T](d, endpoint, parameter, page, 100)
+		if err != nil {
+			return nil, err
+		}
+		all = append(all, values...)
+		if isEnd || len(values) == 0 {
+			return all, nil
+		}
+	}
+}
+
+// GetRepoInfo returns repository information
+// https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-project/#api-api-latest-projects-projectkey-repos-repositoryslug-get
+func (d *BitbucketServerDownloader) GetRepoInfo() (*base.Repository, error) {
+	var rawRepo struct {
+		Slug        string `json:"slug"`
+		Description string `json:"description"`
+		Public      bool   `json:"public"`
+		Links       struct {
+			Clone []struct {
+				Href string `json:"href"`
+				Name string `json:"name"`
+			} `json:"clone"`
+			Self []struct {
+				Href string `json:"href"`
+			} `json:"self"`
+		} `json:"links"`
+	}
+	if err := d.callAPI("", nil, &rawRepo); err != nil {
+		return nil, err
+	}
+
+	repo := &base.Repository{
+		Name:        rawRepo.Slug,
+		Owner:       d.projectKey,
+		IsPrivate:   !rawRepo.Public,
+		Description: rawRepo.Description,
+		CloneURL:    fmt.Sprintf("%s/scm/%s/%s.git", d.baseURL, strings.ToLower(d.projectKey), rawRepo.Slug),
+		OriginalURL: fmt.Sprintf("%s/projects/%s/repos/%s", d.baseURL, d.projectKey, rawRepo.Slug),
+	}
+	for _, clone := range rawRepo.Links.Clone {
+		if clone.Name == "http" || clone.Name == "https" {
+			if u, err := url.Parse(clone.Href); err == nil {
+				u.User = nil
+				repo.CloneURL = u.String()
+			}
+		}
+	}
+	if len(rawRepo.Links.Self) > 0 {
+		repo.OriginalURL = strings.TrimSuffix(rawRepo.Links.Self[0].Href, "/browse")
+	}
+
+	var defaultBranch struct {
+		DisplayID string `json:"displayId"`
+	}
+	if err := d.callAPI("/branches/default", nil, &defaultBranch); err != nil {
+		// an empty repository has no default branch
+		log.Warn("Unable to get the default branch of %s: %v", d, err)
+	}
+	repo.DefaultBranch = defaultBranch.DisplayID
+
+	return repo, nil
+}
+
+type bitbucketServerRef struct {
+	ID           string `json:"id"`
+	DisplayID    string `json:"displayId"`
+	LatestCommit string `json:"latestCommit"`
+	Repository   *struct {
+		Slug    string `json:"slug"`
+		Project struct {
+			Key string `json:"key"`
+		} `json:"project"`
+	} `json:"repository"`
+}
+
+func (d *BitbucketServerDownloader) convertPullRequestBranch(ref *bitbucketServerRef) base.PullRequestBranch {
+	ret := base.PullRequestBranch{
+		Ref:       ref.DisplayID,
+		SHA:       ref.LatestCommit,
+		OwnerName: d.projectKey,
+		RepoName:  d.repoName,
+	}
+	if ref.Repository != nil && !(strings.EqualFold(ref.Repository.Project.Key, d.projectKey) && strings.EqualFold(ref.Repository.Slug, d.repoName)) {
+		ret.OwnerName, ret.RepoName = ref.Repository.Project.Key, ref.Repository.Slug
+		ret.CloneURL = fmt.Sprintf("%s/scm/%s/%s.git", d.baseURL, strings.ToLower(ref.Repository.Project.Key), ref.Repository.Slug)
+	}
+	return ret
+}
+
+type bitbucketServerReviewer struct {
+	User     *bitbucketServerUser `json:"user"`
+	Approved bool                 `json:"approved"`
+	Status   string               `json:"status"`
+}
+
+type bitbucketServerIssueContext struct {
+	Reviewers []bitbucketServerReviewer
+}
+
+// GetPullRequests returns pull requests
+// https://developer.atlassian.com/server/bitbucket/rest/v811/api-group-pull-requests/#api-api-latest-projects-projectkey-repos-repositoryslug-pull-requests-get
+func (d *BitbucketServerDownloader) GetPullRequests(page, perPage int) ([]*base.PullRequest, bool, error) {
+	rawPullRequests, isEnd, err := callBitbucketServerPagedAPI[struct {
+		ID          int64                `json:"id"`
+		Title       string               `json:"title"`
+		Description string               `json:"description"`
+		State       string               `json:"state"`
+		CreatedDate bitbucketServerTime  `json:"createdDate"`
+		UpdatedDate bitbucketServerTime  `json:"updatedDate"`
+		ClosedDate  *bitbucketServerTime `json:"closedDate"`
+		FromRef     bitbucketServerRef   `json:"fromRef"`
+		ToRef       bitbucketServerRef   `json:"toRef"`
+		Author      struct {
+			User *bitbucketServerUser `json:"user"`
+		} `json:"author"`
+		Reviewers  []bitbucketServerReviewer `json:"reviewers"`
+		Properties struct {
+			MergeCommit *struct {
+				ID string `json:"id"`
+			} `json:"mergeCommit"`
+		} `json:"properties"`
+	}](d, "/pull-requests", url.Values{
+		"state": {"ALL"},
+		"order": {"OLDEST"},
+	}, page, perPage)
+	if err != nil {
+		return nil, false, err
+	}
+
+	pullRequests := make([]*base.PullRequest, 0, len(rawPullRequests))
+	for _, pr := range rawPullRequests {
+		state := "open"
+		merged := false
+		var closeTime, mergedTime *time.Time
+		var mergeCommitSHA string
+		if pr.State != "OPEN" {
+			state = "closed"
+			closeTime = pr.ClosedDate.TimePtr()
+			if closeTime == nil {
+				closeTime = pr.UpdatedDate.TimePtr()
+			}
+			if pr.State == "MERGED" {
+				merged = true
+				mergedTime = closeTime
+				if pr.Properties.MergeCommit != nil {
+					mergeCommitSHA = pr.Properties.MergeCommit.ID
+				}
+			}
+		}
+
+		poster := pr.Author.User
+		var posterID int64
+		var posterEmail string
+		if poster != nil {
+			posterID, posterEmail = poster.ID, poster.EmailAddress
+		}
+
+		pullRequests = append(pullRequests, &base.PullRequest{
+			Number:         pr.ID,
+			Title:          pr.Title,
+			PosterID:       posterID,
+			PosterName:     poster.name(),
+			PosterEmail:    posterEmail,
+			Content:        pr.Description,
+			State:          state,
+			Created:        pr.CreatedDate.Time(),
+			Updated:        pr.UpdatedDate.Time(),
+			Closed:         closeTime,
+			Merged:         merged,
+			MergedTime:     mergedTime,
+			MergeCommitSHA: mergeCommitSHA,
+			Head:           d.convertPullRequestBranch(&pr.FromRef),
+			Base:           d.convertPullRequestBranch(&pr.ToRef),
+			ForeignIndex:   pr.ID,
+			Context:        bitbucketServerIssueContext{Reviewers: pr.Reviewers},
+		})
+
+		// SECURITY: Ensure that the PR is safe
+		_ = CheckAndEnsureSafePR(pullRequests[len(pullRequests)-1], d.baseURL.String(), d)
+	}
+
+	return pullRequests, isEnd, nil
+}
+
+type bitbucketServerComment struct {
+	ID          int64                     `json:"id"`
+	Text        string                    `json:"text"`
+	Author      *bitbucketServerUser      `json:"author"`
+	CreatedDate bitbucketServerTime       `json:"createdDate"`
+	UpdatedDate bitbucketServerTime       `json:"updatedDate"`
+	Comments    []*bitbucketServerComment `json:"comments"`
+}
+
+type bitbucketServerActivity struct {
+	Action        string                  `json:"action"`
+	CommentAction string                  `json:"commentAction"`
+	Comment       *bitbucketServerComment `json:"comment"`
+	CommentAnchor *struct {
+		Path     string `json:"path"`
+		Line     int    `json:"line"`
+		LineType string `json:"lineType"`
+		FileType string `json:"fileType"`
+		ToHash   string `json:"toHash"`
+	} `json:"commentAnchor"`
+}
+
+// getCommentActivities returns the activities adding a comment to the pull request, from the oldest to the newest
+func (d *BitbucketServerDownloader) getCommentActivities(index int64) ([]*bitbucketServerActivity, error) {
+	activities, err := callBitbucketServerAllPages[*bitbucketServerActivity](d, fmt.Sprintf("/pull-requests/%d/activities", index), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]*bitbucketServerActivity, 0, len(activities))
+	for i := len(activities) - 1; i >= 0; i-- {
+		if activities[i].Action == "COMMENTED" && activities[i].CommentAction == "ADDED" && activities[i].Comment != nil {
+			comments = append(comments, activities[i])
+		}
+	}
+	return comments, nil
+}
+
+// flattenBitbucketServerComments returns the comment followed by all its replies
+func flattenBitbucketServerComments(comment *bitbucketServerComment, parentID int64, fn func(comment *bitbucketServerComment, parentID int64)) {
+	fn(comment, parentID)
+	for _, reply := range comment.Comments {
+		flattenBitbucketServerComments(reply, comment.ID, fn)
+	}
+}
+
+// GetComments returns the general comments of a pull request and their replies, the comments on the code are returned by GetReviews
+func (d *BitbucketServerDownloader) GetComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	activities, err := d.getCommentActivities(commentable.GetForeignIndex())
+	if err != nil {
+		return nil, false, err
+	}
+
+	comments := make([]*base.Comment, 0, len(activities))
+	for _, activity := range activities {
+		if activity.CommentAnchor != nil {
+			continue
+		}
+		flattenBitbucketServerComments(activity.Comment, 0, func(comment *bitbucketServerComment, _ int64) {
+			if len(comment.Text) == 0 {
+				return
+			}
+			var posterID int64
+			var posterEmail string
+			if comment.Author != nil {
+				posterID, posterEmail = comment.Author.ID, comment.Author.EmailAddress
+			}
+			comments = append(comments, &base.Comment{
+				IssueIndex:  commentable.GetLocalIndex(),
+				Index:       comment.ID,
+				PosterID:    posterID,
+				PosterName:  comment.Author.name(),
+				PosterEmail: posterEmail,
+				Content:     comment.Text,
+				Created:     comment.CreatedDate.Time(),
+				Updated:     comment.UpdatedDate.Time(),
+			})
+		})
+	}
+	return comments, true, nil
+}
+
+// GetReviews returns the approvals, the requested changes and the comments on the code of a pull request
+func (d *BitbucketServerDownloader) GetReviews(reviewable base.Reviewable) ([]*base.Review, error) {
+	reviews := make([]*base.Review, 0, 10)
+
+	if commentable, ok := reviewable.(base.Commentable); ok {
+		if context, ok := commentable.GetContext().(bitbucketServerIssueContext); ok {
+			for _, reviewer := range context.Reviewers {
+				var state string
+				switch {
+				case reviewer.Approved || reviewer.Status == "APPROVED":
+					state = base.ReviewStateApproved
+				case reviewer.Status == "NEEDS_WORK":
+					state = base.ReviewStateChangesRequested
+				default:
+					continue
+				}
+				var reviewerID int64
+				if reviewer.User != nil {
+					reviewerID = reviewer.User.ID
+				}
+				reviews = append(reviews, &base.Review{
+					IssueIndex:   reviewable.GetLocalIndex(),
+					ReviewerID:   reviewerID,
+					ReviewerName: reviewer.User.name(),
+					State:        state,
+				})
+			}
+		}
+	}
+
+	activities, err := d.getCommentActivities(reviewable.GetForeignIndex())
+	if err != nil {
+		return nil, err
+	}
+	for _, activity := range activities {
+		anchor := activity.CommentAnchor
+		if anchor == nil || anchor.Path == "" {
+			continue
+		}
+
+		// a negative line is a line of the old version of the file
+		line := anchor.Line
+		if anchor.LineType == "REMOVED" || anchor.FileType == "FROM" {
+			line = -line
+		}
+
+		flattenBitbucketServerComments(activity.Comment, 0, func(comment *bitbucketServerComment, parentID int64) {
+			if len(comment.Text) == 0 {
+				return
+			}
+			var reviewerID int64
+			if comment.Author != nil {
+				reviewerID = comment.Author.ID
+			}
+			reviews = append(reviews, &base.Review{
+				IssueIndex:   reviewable.GetLocalIndex(),
+				ReviewerID:   reviewerID,
+				ReviewerName: comment.Author.name(),
+				CreatedAt:    comment.CreatedDate.Time(),
+				State:        base.ReviewStateCommented,
+				Comments: []*base.ReviewComment{
+					{
+						ID:        comment.ID,
+						InReplyTo: parentID,
+						Content:   comment.Text,
+						TreePath:  anchor.Path,
+						Line:      line,
+						CommitID:  anchor.ToHash,
+						PosterID:  reviewerID,
+						CreatedAt: comment.CreatedDate.Time(),
+						UpdatedAt: comment.UpdatedDate.Time(),
+					},
+				},
+			})
+		})
+	}
+
+	return reviews, nil
+}
+
+// GetReleases returns the tags of the repository as releases, Bitbucket has no other concept of releases
+func (d *BitbucketServerDownloader) GetReleases() ([]*base.Release, error) {
+	rawTags, err := callBitbucketServerAllPages[struct {
+		DisplayID    string `json:"displayId"`
+		LatestCommit string `json:"latestCommit"`
+	}](d, "/tags", url.Values{"orderBy": {"ALPHABETICAL"}})
+	if err != nil {
+		return nil, err
+	}
+
+	releases := make([]*base.Release, 0, len(rawTags))
+	for _, tag := range rawTags {
+		release := &base.Release{
+			TagName:         tag.DisplayID,
+			TargetCommitish: tag.LatestCommit,
+			Name:            tag.DisplayID,
+		}
+
+		// the tags don't have any date, the one of the tagged commit is used instead
+		var commit struct {
+			Author          *bitbucketServerUser `json:"author"`
+			AuthorTimestamp bitbucketServerTime  `json:"authorTimestamp"`
+		}
+		if err := d.callAPI("/commits/"+url.PathEscape(tag.LatestCommit), nil, &commit); err != nil {
+			log.Warn("Unable to get the commit %s of the tag %s in %s: %v", tag.LatestCommit, tag.DisplayID, d, err)
+		} else {
+			release.Created = commit.AuthorTimestamp.Time()
+			release.Published = release.Created
+			if commit.Author != nil {
+				release.PublisherID = commit.Author.ID
+				release.PublisherName = commit.Author.name()
+				release.PublisherEmail = commit.Author.EmailAddress
+			}
+		}
+
+		releases = append(releases, release)
+	}
+	return releases, nil
+}
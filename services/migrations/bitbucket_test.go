@@ -0,0 +1,458 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"net/url"
+	"os"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/unittest"
+	base "code.gitea.io/gitea/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBitbucketDownloaderFactory(t *testing.T) {
+	for _, testCase := range []struct {
+		cloneAddr   string
+		cloud       bool
+		baseURL     string
+		owner, repo string
+	}{
+		{cloneAddr: "https://bitbucket.org/gitea/test_repo", cloud: true, owner: "gitea", repo: "test_repo"},
+		{cloneAddr: "https://user@bitbucket.org/gitea/test_repo.git", cloud: true, owner: "gitea", repo: "test_repo"},
+		{cloneAddr: "https://bitbucket.example.com/projects/TEST/repos/test_repo/browse", baseURL: "https://bitbucket.example.com", owner: "TEST", repo: "test_repo"},
+		{cloneAddr: "https://example.com/bitbucket/scm/test/test_repo.git", baseURL: "https://example.com/bitbucket", owner: "test", repo: "test_repo"},
+		{cloneAddr: "https://bitbucket.example.com/users/admin/repos/test_repo", baseURL: "https://bitbucket.example.com", owner: "~admin", repo: "test_repo"},
+	} {
+		downloader, err := (&BitbucketDownloaderFactory{}).New(context.Background(), base.MigrateOptions{CloneAddr: testCase.cloneAddr})
+		if !assert.NoError(t, err, testCase.cloneAddr) {
+			continue
+		}
+		if testCase.cloud {
+			if assert.IsType(t, &BitbucketDownloader{}, downloader, testCase.cloneAddr) {
+				d := downloader.(*BitbucketDownloader)
+				assert.Equal(t, testCase.owner, d.workspace)
+				assert.Equal(t, testCase.repo, d.repoName)
+			}
+		} else if assert.IsType(t, &BitbucketServerDownloader{}, downloader, testCase.cloneAddr) {
+			d := downloader.(*BitbucketServerDownloader)
+			assert.Equal(t, testCase.baseURL, d.baseURL.String())
+			assert.Equal(t, testCase.owner, d.projectKey)
+			assert.Equal(t, testCase.repo, d.repoName)
+		}
+	}
+
+	_, err := (&BitbucketDownloaderFactory{}).New(context.Background(), base.MigrateOptions{CloneAddr: "https://bitbucket.example.com/test_repo"})
+	assert.Error(t, err)
+}
+
+func TestBitbucketDownloadRepo(t *testing.T) {
+	// set BITBUCKET_LIVE_MODE to record the responses of the live API again
+	server := unittest.NewMockWebServer(t, "https://api.bitbucket.org", "./testdata/bitbucket/full_download", os.Getenv("BITBUCKET_LIVE_MODE") != "")
+	downloader := NewBitbucketDownloader(context.Background(), "https://bitbucket.org", server.URL+"/2.0", "gitea", "test_repo", "", "")
+	downloader.client = server.Client()
+
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "gitea",
+		Description:   "Test repository for testing migration from Bitbucket to Gitea",
+		CloneURL:      "https://bitbucket.org/gitea/test_repo.git",
+		OriginalURL:   "https://bitbucket.org/gitea/test_repo",
+		DefaultBranch: "master",
+	}, repo)
+
+	milestones, err := downloader.GetMilestones()
+	assert.NoError(t, err)
+	assertMilestonesEqual(t, []*base.Milestone{
+		{Title: "1.0.0", State: "open"},
+		{Title: "1.1.0", State: "open"},
+	}, milestones)
+
+	labels, err := downloader.GetLabels()
+	assert.NoError(t, err)
+	assert.Len(t, labels, 10)
+	assertLabelEqual(t, &base.Label{Name: "component/api", Color: "bfd4f2", Exclusive: true}, labels[9])
+
+	issues, isEnd, err := downloader.GetIssues(1, 2)
+	assert.NoError(t, err)
+	assert.False(t, isEnd)
+	assertIssuesEqual(t, []*base.Issue{
+		{
+			Number:     1,
+			Title:      "Please add an animated gif icon to the merge button",
+			Content:    "I just want the merge button to hurt my eyes a little.",
+			PosterName: "testuser",
+			Milestone:  "1.0.0",
+			State:      "closed",
+			Created:    time.Date(2019, 11, 9, 20, 8, 49, 618893000, time.UTC),
+			Updated:    time.Date(2019, 11, 12, 21, 0, 6, 324071000, time.UTC),
+			Closed:     timePtr(time.Date(2019, 11, 12, 21, 0, 6, 324071000, time.UTC)),
+			Labels: []*base.Label{
+				{Name: "kind/enhancement"},
+				{Name: "priority/minor"},
+			},
+		},
+		{
+			Number:     2,
+			Title:      "Test issue",
+			Content:    "This is test issue 2.",
+			PosterName: "testuser",
+			State:      "open",
+			Created:    time.Date(2019, 11, 12, 21, 21, 43, 0, time.UTC),
+			Updated:    time.Date(2019, 11, 12, 21, 21, 43, 0, time.UTC),
+			Labels: []*base.Label{
+				{Name: "kind/bug"},
+				{Name: "priority/major"},
+				{Name: "component/api"},
+			},
+			Assignees: []string{"other"},
+		},
+	}, issues)
+
+	issues, isEnd, err = downloader.GetIssues(2, 2)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assert.Empty(t, issues)
+
+	comments, _, err := downloader.GetComments(&base.Issue{Number: 1, ForeignIndex: 1, Context: bitbucketIssueContext{IsPullRequest: false}})
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex: 1,
+			Index:      11,
+			PosterName: "other",
+			Created:    time.Date(2019, 11, 10, 8, 0, 0, 0, time.UTC),
+			Updated:    time.Date(2019, 11, 10, 9, 0, 0, 0, time.UTC),
+			Content:    "This is a comment",
+		},
+	}, comments)
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 2)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			// the pull requests are numbered after the issues
+			Number:         3,
+			Title:          "Update README.md",
+			Content:        "add warning to readme",
+			PosterName:     "testuser",
+			State:          "closed",
+			Created:        time.Date(2019, 11, 12, 21, 21, 43, 0, time.UTC),
+			Updated:        time.Date(2019, 11, 12, 21, 39, 28, 0, time.UTC),
+			Closed:         timePtr(time.Date(2019, 11, 12, 21, 39, 28, 0, time.UTC)),
+			Merged:         true,
+			MergedTime:     timePtr(time.Date(2019, 11, 12, 21, 39, 28, 0, time.UTC)),
+			MergeCommitSHA: "6cf5d53b6b8a",
+			Head: base.PullRequestBranch{
+				Ref:       "feature",
+				SHA:       "2be9101c543e",
+				OwnerName: "gitea",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "master",
+				SHA:       "f32b0a9dfd09",
+				OwnerName: "gitea",
+				RepoName:  "test_repo",
+			},
+		},
+		{
+			Number:     4,
+			Title:      "Test branch",
+			Content:    "do not merge this PR",
+			PosterName: "other",
+			State:      "open",
+			Created:    time.Date(2019, 11, 12, 21, 54, 18, 0, time.UTC),
+			Updated:    time.Date(2020, 1, 4, 11, 30, 1, 0, time.UTC),
+			Head: base.PullRequestBranch{
+				CloneURL:  "https://bitbucket.org/other/test_repo.git",
+				Ref:       "test-branch",
+				SHA:       "4c6c7b0b3c8b",
+				OwnerName: "other",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "master",
+				SHA:       "f32b0a9dfd09",
+				OwnerName: "gitea",
+				RepoName:  "test_repo",
+			},
+		},
+	}, prs)
+
+	comments, _, err = downloader.GetComments(prs[0])
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex: 3,
+			Index:      21,
+			PosterName: "other",
+			Created:    time.Date(2019, 11, 12, 21, 32, 0, 0, time.UTC),
+			Updated:    time.Date(2019, 11, 12, 21, 32, 0, 0, time.UTC),
+			Content:    "looks good",
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(prs[0])
+	assert.NoError(t, err)
+	assertReviewsEqual(t, []*base.Review{
+		{
+			IssueIndex:   3,
+			ReviewerName: "other",
+			CreatedAt:    time.Date(2019, 11, 12, 21, 35, 0, 0, time.UTC),
+			State:        base.ReviewStateApproved,
+		},
+		{
+			IssueIndex:   3,
+			ReviewerName: "reviewer",
+			CreatedAt:    time.Date(2019, 11, 12, 21, 30, 0, 0, time.UTC),
+			State:        base.ReviewStateChangesRequested,
+		},
+		{
+			IssueIndex:   3,
+			ReviewerName: "reviewer",
+			CreatedAt:    time.Date(2019, 11, 12, 21, 29, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					ID:        22,
+					Content:   "typo",
+					TreePath:  "README.md",
+					Line:      5,
+					CreatedAt: time.Date(2019, 11, 12, 21, 29, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2019, 11, 12, 21, 29, 30, 0, time.UTC),
+				},
+			},
+		},
+		{
+			IssueIndex:   3,
+			ReviewerName: "other",
+			CreatedAt:    time.Date(2019, 11, 12, 21, 33, 0, 0, time.UTC),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					ID:        23,
+					InReplyTo: 22,
+					Content:   "why was it removed?",
+					TreePath:  "README.md",
+					Line:      -3,
+					CreatedAt: time.Date(2019, 11, 12, 21, 33, 0, 0, time.UTC),
+					UpdatedAt: time.Date(2019, 11, 12, 21, 33, 0, 0, time.UTC),
+				},
+			},
+		},
+	}, reviews)
+
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	assertReleasesEqual(t, []*base.Release{
+		{
+			TagName:         "v1.0.0",
+			TargetCommitish: "6cf5d53b6b8a4f5f4a6f8b0a0e0a5f3b2c1d0e9f",
+			Name:            "v1.0.0",
+			Body:            "First release",
+			PublisherName:   "testuser",
+			Created:         time.Date(2019, 11, 13, 10, 0, 0, 0, time.UTC),
+			Published:       time.Date(2019, 11, 13, 10, 0, 0, 0, time.UTC),
+		},
+		{
+			TagName:         "v0.9.0",
+			TargetCommitish: "f32b0a9dfd09a60f616f29158f772cedd89942d2",
+			Name:            "v0.9.0",
+			PublisherName:   "other",
+			Created:         time.Date(2019, 11, 9, 20, 0, 0, 0, time.UTC),
+			Published:       time.Date(2019, 11, 9, 20, 0, 0, 0, time.UTC),
+		},
+	}, releases)
+}
+
+func TestBitbucketServerDownloadRepo(t *testing.T) {
+	// set BITBUCKET_SERVER_URL to record the responses of a live server again
+	liveServerURL, liveMode := os.Getenv("BITBUCKET_SERVER_URL"), true
+	if liveServerURL == "" {
+		liveServerURL, liveMode = "https://bitbucket.example.com", false
+	}
+	server := unittest.NewMockWebServer(t, liveServerURL, "./testdata/bitbucket_server/full_download", liveMode)
+
+	u, _ := url.Parse(server.URL + "/bitbucket/projects/TEST/repos/test_repo/browse")
+	baseURL, projectKey, repoName, err := parseBitbucketServerURL(u)
+	assert.NoError(t, err)
+	downloader := NewBitbucketServerDownloader(context.Background(), baseURL, projectKey, repoName, "", "")
+	downloader.client = server.Client()
+
+	repo, err := downloader.GetRepoInfo()
+	assert.NoError(t, err)
+	assertRepositoryEqual(t, &base.Repository{
+		Name:          "test_repo",
+		Owner:         "TEST",
+		Description:   "Test repository for testing migration from Bitbucket Server to Gitea",
+		CloneURL:      server.URL + "/bitbucket/scm/test/test_repo.git",
+		OriginalURL:   server.URL + "/bitbucket/projects/TEST/repos/test_repo",
+		DefaultBranch: "main",
+	}, repo)
+
+	// the issues are not tracked by the server
+	_, _, err = downloader.GetIssues(1, 10)
+	assert.True(t, base.IsErrNotSupported(err))
+
+	prs, isEnd, err := downloader.GetPullRequests(1, 2)
+	assert.NoError(t, err)
+	assert.True(t, isEnd)
+	assertPullRequestsEqual(t, []*base.PullRequest{
+		{
+			Number:         1,
+			Title:          "Update README.md",
+			Content:        "add warning to readme",
+			PosterID:       1,
+			PosterName:     "admin",
+			PosterEmail:    "admin@example.com",
+			State:          "closed",
+			Created:        time.UnixMilli(1573593703000),
+			Updated:        time.UnixMilli(1573594768000),
+			Closed:         timePtr(time.UnixMilli(1573594768000)),
+			Merged:         true,
+			MergedTime:     timePtr(time.UnixMilli(1573594768000)),
+			MergeCommitSHA: "6cf5d53b6b8a4f5f4a6f8b0a0e0a5f3b2c1d0e9f",
+			Head: base.PullRequestBranch{
+				Ref:       "feature",
+				SHA:       "2be9101c543e2cd7d3c1d4e3c0a1b5b1b3bb1c5e",
+				OwnerName: "TEST",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "main",
+				SHA:       "f32b0a9dfd09a60f616f29158f772cedd89942d2",
+				OwnerName: "TEST",
+				RepoName:  "test_repo",
+			},
+		},
+		{
+			Number:      2,
+			Title:       "Test branch",
+			Content:     "do not merge this PR",
+			PosterID:    3,
+			PosterName:  "other",
+			PosterEmail: "other@example.com",
+			State:       "open",
+			Created:     time.UnixMilli(1573595658000),
+			Updated:     time.UnixMilli(1578137401000),
+			Head: base.PullRequestBranch{
+				CloneURL:  server.URL + "/bitbucket/scm/fork/test_repo.git",
+				Ref:       "test-branch",
+				SHA:       "4c6c7b0b3c8b4a9a2c1e7bde8c6d9f0a1b2c3d4e",
+				OwnerName: "FORK",
+				RepoName:  "test_repo",
+			},
+			Base: base.PullRequestBranch{
+				Ref:       "main",
+				SHA:       "f32b0a9dfd09a60f616f29158f772cedd89942d2",
+				OwnerName: "TEST",
+				RepoName:  "test_repo",
+			},
+		},
+	}, prs)
+
+	comments, _, err := downloader.GetComments(prs[0])
+	assert.NoError(t, err)
+	assertCommentsEqual(t, []*base.Comment{
+		{
+			IssueIndex:  1,
+			Index:       11,
+			PosterID:    2,
+			PosterName:  "reviewer",
+			PosterEmail: "reviewer@example.com",
+			Created:     time.UnixMilli(1573593800000),
+			Updated:     time.UnixMilli(1573593800000),
+			Content:     "looks good",
+		},
+		{
+			IssueIndex:  1,
+			Index:       12,
+			PosterID:    1,
+			PosterName:  "admin",
+			PosterEmail: "admin@example.com",
+			Created:     time.UnixMilli(1573593850000),
+			Updated:     time.UnixMilli(1573593860000),
+			Content:     "thanks",
+		},
+	}, comments)
+
+	reviews, err := downloader.GetReviews(prs[0])
+	assert.NoError(t, err)
+	assertReviewsEqual(t, []*base.Review{
+		{
+			IssueIndex:   1,
+			ReviewerID:   2,
+			ReviewerName: "reviewer",
+			State:        base.ReviewStateApproved,
+		},
+		{
+			IssueIndex:   1,
+			ReviewerID:   3,
+			ReviewerName: "other",
+			State:        base.ReviewStateChangesRequested,
+		},
+		{
+			IssueIndex:   1,
+			ReviewerID:   3,
+			ReviewerName: "other",
+			CreatedAt:    time.UnixMilli(1573593900000),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					ID:        13,
+					Content:   "typo",
+					TreePath:  "README.md",
+					Line:      5,
+					CommitID:  "2be9101c543e2cd7d3c1d4e3c0a1b5b1b3bb1c5e",
+					PosterID:  3,
+					CreatedAt: time.UnixMilli(1573593900000),
+					UpdatedAt: time.UnixMilli(1573593930000),
+				},
+			},
+		},
+		{
+			IssueIndex:   1,
+			ReviewerID:   1,
+			ReviewerName: "admin",
+			CreatedAt:    time.UnixMilli(1573594000000),
+			State:        base.ReviewStateCommented,
+			Comments: []*base.ReviewComment{
+				{
+					ID:        14,
+					InReplyTo: 13,
+					Content:   "fixed",
+					TreePath:  "README.md",
+					Line:      5,
+					CommitID:  "2be9101c543e2cd7d3c1d4e3c0a1b5b1b3bb1c5e",
+					PosterID:  1,
+					CreatedAt: time.UnixMilli(1573594000000),
+					UpdatedAt: time.UnixMilli(1573594000000),
+				},
+			},
+		},
+	}, reviews)
+
+	releases, err := downloader.GetReleases()
+	assert.NoError(t, err)
+	assertReleasesEqual(t, []*base.Release{
+		{
+			TagName:         "v1.0.0",
+			TargetCommitish: "6cf5d53b6b8a4f5f4a6f8b0a0e0a5f3b2c1d0e9f",
+			Name:            "v1.0.0",
+			PublisherID:     1,
+			PublisherName:   "admin",
+			PublisherEmail:  "admin@example.com",
+			Created:         time.UnixMilli(1573594768000),
+			Published:       time.UnixMilli(1573594768000),
+		},
+	}, releases)
+}
@@ -53,6 +53,7 @@ type GiteaLocalUploader struct {
 	userMap        map[int64]int64 // external user id mapping to user id
 	prCache        map[int64]*issues_model.PullRequest
 	gitServiceType structs.GitServiceType
+	syncReferences bool // record the references of the created objects for the later synchronizations
 }
 
 // NewGiteaLocalUploader creates an gitea Uploader via gitea API v1
@@ -361,84 +362,108 @@ func (g *GiteaLocalUploader) SyncTags() error {
 func (g *GiteaLocalUploader) CreateIssues(issues ...*base.Issue) error {
 	iss := make([]*issues_model.Issue, 0, len(issues))
 	for _, issue := range issues {
-		var labels []*issues_model.Label
-		for _, label := range issue.Labels {
-			lb, ok := g.labels[label.Name]
-			if ok {
-				labels = append(labels, lb)
-			}
+		is, err := g.newIssue(issue)
+		if err != nil {
+			return err
 		}
+		iss = append(iss, is)
+	}
 
-		milestoneID := g.milestones[issue.Milestone]
-
-		if issue.Created.IsZero() {
-			if issue.Closed != nil {
-				issue.Created = *issue.Closed
-			} else {
-				issue.Created = time.Now()
-			}
-		}
-		if issue.Updated.IsZero() {
-			if issue.Closed != nil {
-				issue.Updated = *issue.Closed
-			} else {
-				issue.Updated = time.Now()
-			}
+	if len(iss) > 0 {
+		if err := models.InsertIssues(iss...); err != nil {
+			return err
 		}
 
-		// SECURITY: issue.Ref needs to be a valid reference
-		if !git.IsValidRefPattern(issue.Ref) {
-			log.Warn("Invalid issue.Ref[%s] in issue #%d in %s/%s", issue.Ref, issue.Number, g.repoOwner, g.repoName)
-			issue.Ref = ""
+		for _, is := range iss {
+			g.issues[is.Index] = is
 		}
+	}
 
-		is := issues_model.Issue{
-			RepoID:      g.repo.ID,
-			Repo:        g.repo,
-			Index:       issue.Number,
-			Title:       issue.Title,
-			Content:     issue.Content,
-			Ref:         issue.Ref,
-			IsClosed:    issue.State == "closed",
-			IsLocked:    issue.IsLocked,
-			MilestoneID: milestoneID,
-			Labels:      labels,
-			CreatedUnix: timeutil.TimeStamp(issue.Created.Unix()),
-			UpdatedUnix: timeutil.TimeStamp(issue.Updated.Unix()),
-		}
-
-		if err := g.remapUser(issue, &is); err != nil {
-			return err
+	if !g.syncReferences {
+		return nil
+	}
+	refs := make([]*repo_model.MigrationSyncReference, 0, len(issues))
+	for i, issue := range issues {
+		refs = append(refs, g.newSyncReference(repo_model.MigrationSyncReferenceIssue, strconv.FormatInt(issue.GetForeignIndex(), 10), iss[i].ID))
+	}
+	return repo_model.InsertMigrationSyncReferences(g.ctx, refs)
+}
+
+func (g *GiteaLocalUploader) newSyncReference(tp, foreignID string, localID int64) *repo_model.MigrationSyncReference {
+	return &repo_model.MigrationSyncReference{
+		RepoID:    g.repo.ID,
+		Type:      tp,
+		ForeignID: foreignID,
+		LocalID:   localID,
+	}
+}
+
+func (g *GiteaLocalUploader) newIssue(issue *base.Issue) (*issues_model.Issue, error) {
+	var labels []*issues_model.Label
+	for _, label := range issue.Labels {
+		lb, ok := g.labels[label.Name]
+		if ok {
+			labels = append(labels, lb)
 		}
+	}
+
+	milestoneID := g.milestones[issue.Milestone]
 
+	if issue.Created.IsZero() {
 		if issue.Closed != nil {
-			is.ClosedUnix = timeutil.TimeStamp(issue.Closed.Unix())
+			issue.Created = *issue.Closed
+		} else {
+			issue.Created = time.Now()
 		}
-		// add reactions
-		for _, reaction := range issue.Reactions {
-			res := issues_model.Reaction{
-				Type:        reaction.Content,
-				CreatedUnix: timeutil.TimeStampNow(),
-			}
-			if err := g.remapUser(reaction, &res); err != nil {
-				return err
-			}
-			is.Reactions = append(is.Reactions, &res)
+	}
+	if issue.Updated.IsZero() {
+		if issue.Closed != nil {
+			issue.Updated = *issue.Closed
+		} else {
+			issue.Updated = time.Now()
 		}
-		iss = append(iss, &is)
 	}
 
-	if len(iss) > 0 {
-		if err := models.InsertIssues(iss...); err != nil {
-			return err
-		}
+	// SECURITY: issue.Ref needs to be a valid reference
+	if !git.IsValidRefPattern(issue.Ref) {
+		log.Warn("Invalid issue.Ref[%s] in issue #%d in %s/%s", issue.Ref, issue.Number, g.repoOwner, g.repoName)
+		issue.Ref = ""
+	}
 
-		for _, is := range iss {
-			g.issues[is.Index] = is
-		}
+	is := issues_model.Issue{
+		RepoID:      g.repo.ID,
+		Repo:        g.repo,
+		Index:       issue.Number,
+		Title:       issue.Title,
+		Content:     issue.Content,
+		Ref:         issue.Ref,
+		IsClosed:    issue.State == "closed",
+		IsLocked:    issue.IsLocked,
+		MilestoneID: milestoneID,
+		Labels:      labels,
+		CreatedUnix: timeutil.TimeStamp(issue.Created.Unix()),
+		UpdatedUnix: timeutil.TimeStamp(issue.Updated.Unix()),
 	}
 
-	return nil
+	if err := g.remapUser(issue, &is); err != nil {
+		return nil, err
+	}
+
+	if issue.Closed != nil {
+		is.ClosedUnix = timeutil.TimeStamp(issue.Closed.Unix())
+	}
+	// add reactions
+	for _, reaction := range issue.Reactions {
+		res := issues_model.Reaction{
+			Type:        reaction.Content,
+			CreatedUnix: timeutil.TimeStampNow(),
+		}
+		if err := g.remapUser(reaction, &res); err != nil {
+			return nil, err
+		}
+		is.Reactions = append(is.Reactions, &res)
+	}
+	return &is, nil
 }
 
 // CreateComments creates comments of issues
@@ -451,65 +476,83 @@ func (g *GiteaLocalUploader) CreateComments(comments ...*base.Comment) error {
 			return fmt.Errorf("comment references non existent IssueIndex %d", comment.IssueIndex)
 		}
 
-		if comment.Created.IsZero() {
-			comment.Created = time.Unix(int64(issue.CreatedUnix), 0)
-		}
-		if comment.Updated.IsZero() {
-			comment.Updated = comment.Created
-		}
-		if comment.CommentType == "" {
-			// if type field is missing, then assume a normal comment
-			comment.CommentType = issues_model.CommentTypeComment.String()
-		}
-		cm := issues_model.Comment{
-			IssueID:     issue.ID,
-			Type:        issues_model.AsCommentType(comment.CommentType),
-			Content:     comment.Content,
-			CreatedUnix: timeutil.TimeStamp(comment.Created.Unix()),
-			UpdatedUnix: timeutil.TimeStamp(comment.Updated.Unix()),
+		cm, err := g.newComment(issue, comment)
+		if err != nil {
+			return err
 		}
+		cms = append(cms, cm)
+	}
 
-		switch cm.Type {
-		case issues_model.CommentTypeAssignees:
-			if assigneeID, ok := comment.Meta["AssigneeID"].(int); ok {
-				cm.AssigneeID = int64(assigneeID)
-			}
-			if comment.Meta["RemovedAssigneeID"] != nil {
-				cm.RemovedAssignee = true
-			}
-		case issues_model.CommentTypeChangeTitle:
-			if comment.Meta["OldTitle"] != nil {
-				cm.OldTitle = fmt.Sprintf("%s", comment.Meta["OldTitle"])
-			}
-			if comment.Meta["NewTitle"] != nil {
-				cm.NewTitle = fmt.Sprintf("%s", comment.Meta["NewTitle"])
-			}
-		default:
-		}
+	if len(cms) == 0 {
+		return nil
+	}
+	if err := models.InsertIssueComments(cms); err != nil {
+		return err
+	}
 
-		if err := g.remapUser(comment, &cm); err != nil {
-			return err
-		}
+	if !g.syncReferences {
+		return nil
+	}
+	refs := make([]*repo_model.MigrationSyncReference, 0, len(comments))
+	for i, comment := range comments {
+		refs = append(refs, g.newSyncReference(repo_model.MigrationSyncReferenceComment, commentForeignID(comment), cms[i].ID))
+	}
+	return repo_model.InsertMigrationSyncReferences(g.ctx, refs)
+}
 
-		// add reactions
-		for _, reaction := range comment.Reactions {
-			res := issues_model.Reaction{
-				Type:        reaction.Content,
-				CreatedUnix: timeutil.TimeStampNow(),
-			}
-			if err := g.remapUser(reaction, &res); err != nil {
-				return err
-			}
-			cm.Reactions = append(cm.Reactions, &res)
+func (g *GiteaLocalUploader) newComment(issue *issues_model.Issue, comment *base.Comment) (*issues_model.Comment, error) {
+	if comment.Created.IsZero() {
+		comment.Created = time.Unix(int64(issue.CreatedUnix), 0)
+	}
+	if comment.Updated.IsZero() {
+		comment.Updated = comment.Created
+	}
+	if comment.CommentType == "" {
+		// if type field is missing, then assume a normal comment
+		comment.CommentType = issues_model.CommentTypeComment.String()
+	}
+	cm := issues_model.Comment{
+		IssueID:     issue.ID,
+		Type:        issues_model.AsCommentType(comment.CommentType),
+		Content:     comment.Content,
+		CreatedUnix: timeutil.TimeStamp(comment.Created.Unix()),
+		UpdatedUnix: timeutil.TimeStamp(comment.Updated.Unix()),
+	}
+
+	switch cm.Type {
+	case issues_model.CommentTypeAssignees:
+		if assigneeID, ok := comment.Meta["AssigneeID"].(int); ok {
+			cm.AssigneeID = int64(assigneeID)
+		}
+		if comment.Meta["RemovedAssigneeID"] != nil {
+			cm.RemovedAssignee = true
 		}
+	case issues_model.CommentTypeChangeTitle:
+		if comment.Meta["OldTitle"] != nil {
+			cm.OldTitle = fmt.Sprintf("%s", comment.Meta["OldTitle"])
+		}
+		if comment.Meta["NewTitle"] != nil {
+			cm.NewTitle = fmt.Sprintf("%s", comment.Meta["NewTitle"])
+		}
+	default:
+	}
 
-		cms = append(cms, &cm)
+	if err := g.remapUser(comment, &cm); err != nil {
+		return nil, err
 	}
 
-	if len(cms) == 0 {
-		return nil
+	// add reactions
+	for _, reaction := range comment.Reactions {
+		res := issues_model.Reaction{
+			Type:        reaction.Content,
+			CreatedUnix: timeutil.TimeStampNow(),
+		}
+		if err := g.remapUser(reaction, &res); err != nil {
+			return nil, err
+		}
+		cm.Reactions = append(cm.Reactions, &res)
 	}
-	return models.InsertIssueComments(cms)
+	return &cm, nil
 }
 
 // CreatePullRequests creates pull requests
@@ -534,7 +577,15 @@ func (g *GiteaLocalUploader) CreatePullRequests(prs ...*base.PullRequest) error
 		g.issues[pr.Issue.Index] = pr.Issue
 		pull.AddToTaskQueue(pr)
 	}
-	return nil
+
+	if !g.syncReferences {
+		return nil
+	}
+	refs := make([]*repo_model.MigrationSyncReference, 0, len(prs))
+	for i, pr := range prs {
+		refs = append(refs, g.newSyncReference(repo_model.MigrationSyncReferencePull, strconv.FormatInt(pr.GetForeignIndex(), 10), gprs[i].Issue.ID))
+	}
+	return repo_model.InsertMigrationSyncReferences(g.ctx, refs)
 }
 
 func (g *GiteaLocalUploader) updateGitForPullRequest(pr *base.PullRequest) (head string, err error) {
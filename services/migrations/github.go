@@ -25,8 +25,9 @@ import (
 )
 
 var (
-	_ base.Downloader        = &GithubDownloaderV3{}
-	_ base.DownloaderFactory = &GithubDownloaderV3Factory{}
+	_ base.Downloader            = &GithubDownloaderV3{}
+	_ base.IncrementalDownloader = &GithubDownloaderV3{}
+	_ base.DownloaderFactory     = &GithubDownloaderV3Factory{}
 	// GithubLimitRateRemaining limit to wait for new rate to apply
 	GithubLimitRateRemaining = 0
 )
@@ -76,6 +77,7 @@ type GithubDownloaderV3 struct {
 	maxPerPage    int
 	SkipReactions bool
 	SkipReviews   bool
+	updatedSince  time.Time
 }
 
 // NewGithubDownloaderV3 creates a github Downloader via github v3 API
@@ -120,6 +122,11 @@ func NewGithubDownloaderV3(ctx context.Context, baseURL, userName, password, tok
 	return &downloader
 }
 
+// SetUpdatedSince restricts the issues, pull requests and comments to the ones updated since a time
+func (g *GithubDownloaderV3) SetUpdatedSince(since time.Time) {
+	g.updatedSince = since
+}
+
 // String implements Stringer
 func (g *GithubDownloaderV3) String() string {
 	return fmt.Sprintf("migration from github server %s %s/%s", g.baseURL, g.repoOwner, g.repoName)
@@ -419,6 +426,7 @@ func (g *GithubDownloaderV3) GetIssues(page, perPage int) ([]*base.Issue, bool,
 		Sort:      "created",
 		Direction: "asc",
 		State:     "all",
+		Since:     g.updatedSince,
 		ListOptions: github.ListOptions{
 			PerPage: perPage,
 			Page:    page,
@@ -521,6 +529,9 @@ func (g *GithubDownloaderV3) getComments(commentable base.Commentable) ([]*base.
 			PerPage: g.maxPerPage,
 		},
 	}
+	if !g.updatedSince.IsZero() {
+		opt.Since = &g.updatedSince
+	}
 	for {
 		g.waitAndPickClient()
 		comments, resp, err := g.getClient().Issues.ListComments(g.ctx, g.repoOwner, g.repoName, int(commentable.GetForeignIndex()), opt)
@@ -593,6 +604,9 @@ func (g *GithubDownloaderV3) GetAllComments(page, perPage int) ([]*base.Comment,
 			PerPage: perPage,
 		},
 	}
+	if !g.updatedSince.IsZero() {
+		opt.Since = &g.updatedSince
+	}
 
 	g.waitAndPickClient()
 	comments, resp, err := g.getClient().Issues.ListComments(g.ctx, g.repoOwner, g.repoName, 0, opt)
@@ -661,6 +675,12 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 			Page:    page,
 		},
 	}
+	// the pull requests can't be filtered by their update time, so the most recently updated are listed first
+	// until one hasn't been updated since the time
+	if !g.updatedSince.IsZero() {
+		opt.Sort = "updated"
+		opt.Direction = "desc"
+	}
 	allPRs := make([]*base.PullRequest, 0, perPage)
 	g.waitAndPickClient()
 	prs, resp, err := g.getClient().PullRequests.List(g.ctx, g.repoOwner, g.repoName, opt)
@@ -669,7 +689,13 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 	}
 	log.Trace("Request get pull requests %d/%d, but in fact get %d", perPage, page, len(prs))
 	g.setRate(&resp.Rate)
+	isEnd := len(prs) < perPage
 	for _, pr := range prs {
+		if !g.updatedSince.IsZero() && pr.GetUpdatedAt().Time.Before(g.updatedSince) {
+			isEnd = true
+			break
+		}
+
 		labels := make([]*base.Label, 0, len(pr.Labels))
 		for _, l := range pr.Labels {
 			labels = append(labels, convertGithubLabel(l))
@@ -743,7 +769,7 @@ func (g *GithubDownloaderV3) GetPullRequests(page, perPage int) ([]*base.PullReq
 		_ = CheckAndEnsureSafePR(allPRs[len(allPRs)-1], g.baseURL, g)
 	}
 
-	return allPRs, len(prs) < perPage, nil
+	return allPRs, isEnd, nil
 }
 
 func convertGithubReview(r *github.PullRequestReview) *base.Review {
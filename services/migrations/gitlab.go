@@ -22,8 +22,9 @@ import (
 )
 
 var (
-	_ base.Downloader        = &GitlabDownloader{}
-	_ base.DownloaderFactory = &GitlabDownloaderFactory{}
+	_ base.Downloader            = &GitlabDownloader{}
+	_ base.IncrementalDownloader = &GitlabDownloader{}
+	_ base.DownloaderFactory     = &GitlabDownloaderFactory{}
 )
 
 func init() {
@@ -60,13 +61,14 @@ func (f *GitlabDownloaderFactory) GitServiceType() structs.GitServiceType {
 // because Gitlab has individual Issue and Pull Request numbers.
 type GitlabDownloader struct {
 	base.NullDownloader
-	ctx        context.Context
-	client     *gitlab.Client
-	baseURL    string
-	repoID     int
-	repoName   string
-	issueCount int64
-	maxPerPage int
+	ctx          context.Context
+	client       *gitlab.Client
+	baseURL      string
+	repoID       int
+	repoName     string
+	issueCount   int64
+	maxPerPage   int
+	updatedSince *time.Time
 }
 
 // NewGitlabDownloader creates a gitlab Downloader via gitlab API
@@ -144,6 +146,11 @@ func (g *GitlabDownloader) LogString() string {
 	return fmt.Sprintf("<GitlabDownloader %s [%d]/%s>", g.baseURL, g.repoID, g.repoName)
 }
 
+// SetUpdatedSince restricts the issues and the merge requests to the ones updated since a time
+func (g *GitlabDownloader) SetUpdatedSince(since time.Time) {
+	g.updatedSince = &since
+}
+
 // SetContext set context
 func (g *GitlabDownloader) SetContext(ctx context.Context) {
 	g.ctx = ctx
@@ -385,8 +392,9 @@ func (g *GitlabDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, er
 	}
 
 	opt := &gitlab.ListProjectIssuesOptions{
-		State: &state,
-		Sort:  &sort,
+		State:        &state,
+		Sort:         &sort,
+		UpdatedAfter: g.updatedSince,
 		ListOptions: gitlab.ListOptions{
 			PerPage: perPage,
 			Page:    page,
@@ -528,6 +536,7 @@ func (g *GitlabDownloader) GetPullRequests(page, perPage int) ([]*base.PullReque
 	}
 
 	opt := &gitlab.ListProjectMergeRequestsOptions{
+		UpdatedAfter: g.updatedSince,
 		ListOptions: gitlab.ListOptions{
 			PerPage: perPage,
 			Page:    page,
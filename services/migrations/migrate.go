@@ -11,6 +11,7 @@ import (
 	"net/url"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -109,6 +110,7 @@ func checkByAllowBlockList(hostName string, addrList []net.IP) error {
 
 // MigrateRepository migrate repository according MigrateOptions
 func MigrateRepository(ctx context.Context, doer *user_model.User, ownerName string, opts base.MigrateOptions, messenger base.Messenger) (*repo_model.Repository, error) {
+	migrationStart := time.Now()
 	err := IsMigrateURLAllowed(opts.CloneAddr, doer)
 	if err != nil {
 		return nil, err
@@ -126,6 +128,7 @@ func MigrateRepository(ctx context.Context, doer *user_model.User, ownerName str
 
 	uploader := NewGiteaLocalUploader(ctx, doer, ownerName, opts.RepoName)
 	uploader.gitServiceType = opts.GitServiceType
+	uploader.syncReferences = opts.Sync
 
 	if err := migrateRepository(doer, downloader, uploader, opts, messenger); err != nil {
 		if err1 := uploader.Rollback(); err1 != nil {
@@ -136,6 +139,12 @@ func MigrateRepository(ctx context.Context, doer *user_model.User, ownerName str
 		}
 		return nil, err
 	}
+
+	if opts.Sync {
+		if err := EnableMigrationSync(ctx, doer, uploader.repo, opts, migrationStart); err != nil {
+			log.Error("EnableMigrationSync[%d]: %v", uploader.repo.ID, err)
+		}
+	}
 	return uploader.repo, nil
 }
 
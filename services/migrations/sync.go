@@ -0,0 +1,567 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/label"
+	"code.gitea.io/gitea/modules/log"
+	base "code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/pull"
+)
+
+// EnableMigrationSync starts the periodic synchronization of a repository migrated with the given options,
+// the objects updated on the source since migrationStart are pulled by the first synchronization.
+func EnableMigrationSync(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, opts base.MigrateOptions, migrationStart time.Time) error {
+	if !opts.GitServiceType.IncrementalSync() {
+		return util.NewInvalidArgumentErrorf("%s migrations can't be synchronized", opts.GitServiceType.Title())
+	}
+
+	s := &repo_model.MigrationSync{
+		RepoID:         repo.ID,
+		DoerID:         doer.ID,
+		GitServiceType: opts.GitServiceType,
+		AuthUsername:   opts.AuthUsername,
+		Milestones:     opts.Milestones,
+		Labels:         opts.Labels,
+		Releases:       opts.Releases,
+		Issues:         opts.Issues,
+		PullRequests:   opts.PullRequests,
+		Comments:       opts.Comments,
+		Interval:       setting.Migrations.SyncInterval,
+		LastSyncUnix:   timeutil.TimeStamp(migrationStart.Unix()),
+	}
+	if err := s.SetCredentials(opts.CloneAddr, opts.AuthPassword, opts.AuthToken); err != nil {
+		return err
+	}
+	s.ScheduleNextSync()
+	return repo_model.InsertMigrationSync(ctx, s)
+}
+
+// SyncMigratedRepositories synchronizes the migrated repositories whose synchronization is due
+func SyncMigratedRepositories(ctx context.Context, limit int) error {
+	syncs, err := repo_model.FindDueMigrationSyncs(ctx, limit)
+	if err != nil {
+		return fmt.Errorf("FindDueMigrationSyncs: %w", err)
+	}
+
+	for _, s := range syncs {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before synchronizing migrated repository %d", s.RepoID)
+		default:
+		}
+
+		if err := SyncMigratedRepository(ctx, s); err != nil {
+			log.Error("SyncMigratedRepository[%d]: %v", s.RepoID, err)
+		}
+	}
+	return nil
+}
+
+// SyncMigratedRepository pulls the milestones, labels, releases, issues, pull requests and comments
+// updated on the source since the last synchronization, the error, if any, is kept in the synchronization.
+func SyncMigratedRepository(ctx context.Context, s *repo_model.MigrationSync) error {
+	if err := s.LoadRepo(ctx); err != nil {
+		return err
+	}
+
+	ctx, _, finished := process.GetManager().AddContext(ctx, fmt.Sprintf("Syncing migrated repository %s", s.Repo.FullName()))
+	defer finished()
+
+	syncStart := time.Now()
+	err := syncMigratedRepository(ctx, s)
+	if err != nil {
+		s.LastError = util.SanitizeCredentialURLs(err.Error())
+	} else {
+		s.LastError = ""
+		s.LastSyncUnix = timeutil.TimeStamp(syncStart.Unix())
+	}
+	s.ScheduleNextSync()
+
+	if err := repo_model.UpdateMigrationSyncCols(ctx, s, "last_sync_unix", "next_sync_unix", "last_error"); err != nil {
+		return fmt.Errorf("UpdateMigrationSyncCols: %w", err)
+	}
+	return err
+}
+
+func syncMigratedRepository(ctx context.Context, s *repo_model.MigrationSync) error {
+	doer, err := user_model.GetUserByID(ctx, s.DoerID)
+	if err != nil {
+		return fmt.Errorf("GetUserByID: %w", err)
+	}
+	if err := s.Repo.LoadOwner(ctx); err != nil {
+		return err
+	}
+
+	cloneAddr, authPassword, authToken, err := s.Credentials()
+	if err != nil {
+		return err
+	}
+	if err := IsMigrateURLAllowed(cloneAddr, doer); err != nil {
+		return err
+	}
+
+	opts := base.MigrateOptions{
+		CloneAddr:      cloneAddr,
+		AuthUsername:   s.AuthUsername,
+		AuthPassword:   authPassword,
+		AuthToken:      authToken,
+		RepoName:       s.Repo.Name,
+		OriginalURL:    s.Repo.OriginalURL,
+		GitServiceType: s.GitServiceType,
+		Milestones:     s.Milestones,
+		Labels:         s.Labels,
+		Releases:       s.Releases,
+		Issues:         s.Issues,
+		PullRequests:   s.PullRequests,
+		Comments:       s.Comments,
+	}
+	downloader, err := newDownloader(ctx, s.Repo.OwnerName, opts)
+	if err != nil {
+		return err
+	}
+
+	if s.LastSyncUnix > 0 {
+		inner := downloader
+		if retry, ok := inner.(*base.RetryDownloader); ok {
+			inner = retry.Downloader
+		}
+		if incremental, ok := inner.(base.IncrementalDownloader); ok {
+			incremental.SetUpdatedSince(s.LastSyncUnix.AsTime())
+		}
+	}
+
+	uploader := NewGiteaLocalUploader(ctx, doer, s.Repo.OwnerName, s.Repo.Name)
+	uploader.gitServiceType = s.GitServiceType
+	if err := uploader.openRepo(s.Repo); err != nil {
+		return err
+	}
+	defer uploader.Close()
+
+	return syncRepository(downloader, uploader, opts)
+}
+
+// syncRepository pulls the objects of a migrated repository, the objects which have already been pulled are updated
+func syncRepository(downloader base.Downloader, uploader *GiteaLocalUploader, opts base.MigrateOptions) error {
+	if opts.Milestones {
+		log.Trace("synchronizing milestones")
+		milestones, err := downloader.GetMilestones()
+		if err != nil && !base.IsErrNotSupported(err) {
+			return err
+		}
+		if err := uploader.syncMilestones(milestones...); err != nil {
+			return err
+		}
+	}
+
+	if opts.Labels {
+		log.Trace("synchronizing labels")
+		labels, err := downloader.GetLabels()
+		if err != nil && !base.IsErrNotSupported(err) {
+			return err
+		}
+		if err := uploader.syncLabels(labels...); err != nil {
+			return err
+		}
+	}
+
+	if opts.Releases {
+		log.Trace("synchronizing releases")
+		releases, err := downloader.GetReleases()
+		if err != nil && !base.IsErrNotSupported(err) {
+			return err
+		}
+		if err := uploader.syncReleases(releases...); err != nil {
+			return err
+		}
+	}
+
+	supportAllComments := downloader.SupportGetRepoComments()
+	commentBatchSize := uploader.MaxBatchInsertSize("comment")
+
+	if opts.Issues {
+		log.Trace("synchronizing issues and comments")
+		issueBatchSize := uploader.MaxBatchInsertSize("issue")
+		for i := 1; ; i++ {
+			issues, isEnd, err := downloader.GetIssues(i, issueBatchSize)
+			if err != nil {
+				if !base.IsErrNotSupported(err) {
+					return err
+				}
+				break
+			}
+
+			for _, issue := range issues {
+				if err := uploader.syncIssue(issue); err != nil {
+					return err
+				}
+				if opts.Comments && !supportAllComments {
+					comments, _, err := downloader.GetComments(issue)
+					if err != nil && !base.IsErrNotSupported(err) {
+						return err
+					}
+					if err := uploader.syncComments(comments...); err != nil {
+						return err
+					}
+				}
+			}
+
+			if isEnd {
+				break
+			}
+		}
+	}
+
+	if opts.PullRequests {
+		log.Trace("synchronizing pull requests and comments")
+		prBatchSize := uploader.MaxBatchInsertSize("pullrequest")
+		for i := 1; ; i++ {
+			prs, isEnd, err := downloader.GetPullRequests(i, prBatchSize)
+			if err != nil {
+				if !base.IsErrNotSupported(err) {
+					return err
+				}
+				break
+			}
+
+			for _, pr := range prs {
+				if err := uploader.syncPullRequest(pr); err != nil {
+					return err
+				}
+				if opts.Comments && !supportAllComments {
+					comments, _, err := downloader.GetComments(pr)
+					if err != nil && !base.IsErrNotSupported(err) {
+						return err
+					}
+					if err := uploader.syncComments(comments...); err != nil {
+						return err
+					}
+				}
+			}
+
+			if isEnd {
+				break
+			}
+		}
+	}
+
+	if opts.Comments && supportAllComments {
+		log.Trace("synchronizing comments")
+		for i := 1; ; i++ {
+			comments, isEnd, err := downloader.GetAllComments(i, commentBatchSize)
+			if err != nil {
+				return err
+			}
+			if err := uploader.syncComments(comments...); err != nil {
+				return err
+			}
+			if isEnd {
+				break
+			}
+		}
+	}
+
+	if err := issues_model.RecalculateIssueIndexForRepo(uploader.repo.ID); err != nil {
+		return err
+	}
+	return models.UpdateRepoStats(uploader.ctx, uploader.repo.ID)
+}
+
+// openRepo prepares the uploader to synchronize an existing repository
+func (g *GiteaLocalUploader) openRepo(repo *repo_model.Repository) (err error) {
+	g.repo = repo
+	g.sameApp = strings.HasPrefix(repo.OriginalURL, setting.AppURL)
+
+	labels, err := issues_model.GetLabelsByRepoID(g.ctx, repo.ID, "", db.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, lb := range labels {
+		g.labels[lb.Name] = lb
+	}
+
+	milestones, _, err := issues_model.GetMilestones(issues_model.GetMilestonesOption{
+		RepoID: repo.ID,
+		State:  api.StateAll,
+	})
+	if err != nil {
+		return err
+	}
+	for _, ms := range milestones {
+		g.milestones[ms.Name] = ms.ID
+	}
+
+	g.gitRepo, err = git.OpenRepository(g.ctx, repo.RepoPath())
+	return err
+}
+
+func (g *GiteaLocalUploader) syncMilestones(milestones ...*base.Milestone) error {
+	newMilestones := make([]*base.Milestone, 0, len(milestones))
+	for _, milestone := range milestones {
+		id, ok := g.milestones[milestone.Title]
+		if !ok {
+			newMilestones = append(newMilestones, milestone)
+			continue
+		}
+
+		ms, err := issues_model.GetMilestoneByRepoID(g.ctx, g.repo.ID, id)
+		if err != nil {
+			return err
+		}
+		oldIsClosed := ms.IsClosed
+		ms.Content = milestone.Description
+		ms.IsClosed = milestone.State == "closed"
+		if milestone.Deadline != nil {
+			ms.DeadlineUnix = timeutil.TimeStamp(milestone.Deadline.Unix())
+		}
+		if err := issues_model.UpdateMilestone(ms, oldIsClosed); err != nil {
+			return err
+		}
+	}
+
+	if len(newMilestones) == 0 {
+		return nil
+	}
+	return g.CreateMilestones(newMilestones...)
+}
+
+func (g *GiteaLocalUploader) syncLabels(labels ...*base.Label) error {
+	newLabels := make([]*base.Label, 0, len(labels))
+	for _, l := range labels {
+		lb, ok := g.labels[l.Name]
+		if !ok {
+			newLabels = append(newLabels, l)
+			continue
+		}
+
+		if color, err := label.NormalizeColor(l.Color); err == nil {
+			lb.Color = color
+		}
+		lb.Exclusive = l.Exclusive
+		lb.Description = l.Description
+		if err := issues_model.UpdateLabel(lb); err != nil {
+			return err
+		}
+	}
+
+	if len(newLabels) == 0 {
+		return nil
+	}
+	return g.CreateLabels(newLabels...)
+}
+
+func (g *GiteaLocalUploader) syncReleases(releases ...*base.Release) error {
+	newReleases := make([]*base.Release, 0, len(releases))
+	for _, release := range releases {
+		// a release without a tag can't be matched with the one pulled by a previous synchronization
+		if release.TagName == "" {
+			continue
+		}
+
+		rel, err := repo_model.GetRelease(g.repo.ID, release.TagName)
+		if repo_model.IsErrReleaseNotExist(err) {
+			newReleases = append(newReleases, release)
+			continue
+		} else if err != nil {
+			return err
+		}
+
+		rel.Title = release.Name
+		rel.Note = release.Body
+		rel.IsDraft = release.Draft
+		rel.IsPrerelease = release.Prerelease
+		rel.IsTag = false
+		if err := repo_model.UpdateRelease(g.ctx, rel); err != nil {
+			return err
+		}
+	}
+
+	if len(newReleases) == 0 {
+		return nil
+	}
+	return g.CreateReleases(newReleases...)
+}
+
+// getSyncedIssue returns the local issue or pull request an object of the source has been pulled into, or nil
+func (g *GiteaLocalUploader) getSyncedIssue(tp string, foreignIndex int64) (*issues_model.Issue, error) {
+	id, err := repo_model.GetMigrationSyncReference(g.ctx, g.repo.ID, tp, strconv.FormatInt(foreignIndex, 10))
+	if err != nil || id == 0 {
+		return nil, err
+	}
+	issue, err := issues_model.GetIssueByID(g.ctx, id)
+	if issues_model.IsErrIssueNotExist(err) {
+		// it has been deleted locally, pull it again
+		return nil, nil
+	}
+	return issue, err
+}
+
+// syncIndex returns the index of a new issue or pull request, the index on the source is kept if it's still free
+func (g *GiteaLocalUploader) syncIndex(number int64) (int64, error) {
+	if _, err := issues_model.GetIssueByIndex(g.repo.ID, number); issues_model.IsErrIssueNotExist(err) {
+		return number, nil
+	} else if err != nil {
+		return 0, err
+	}
+	return db.GetNextResourceIndex(g.ctx, "issue_index", g.repo.ID)
+}
+
+func (g *GiteaLocalUploader) syncIssue(issue *base.Issue) error {
+	is, err := g.newIssue(issue)
+	if err != nil {
+		return err
+	}
+
+	existing, err := g.getSyncedIssue(repo_model.MigrationSyncReferenceIssue, issue.GetForeignIndex())
+	if err != nil {
+		return err
+	}
+	if existing != nil {
+		is.ID = existing.ID
+		is.Index = existing.Index
+		if err := models.UpdateMigratedIssue(is); err != nil {
+			return err
+		}
+	} else {
+		if is.Index, err = g.syncIndex(issue.Number); err != nil {
+			return err
+		}
+		if err := models.InsertIssues(is); err != nil {
+			return err
+		}
+		if err := repo_model.SetMigrationSyncReference(g.ctx, g.repo.ID, repo_model.MigrationSyncReferenceIssue,
+			strconv.FormatInt(issue.GetForeignIndex(), 10), is.ID); err != nil {
+			return err
+		}
+	}
+
+	g.issues[issue.Number] = is
+	return nil
+}
+
+func (g *GiteaLocalUploader) syncPullRequest(pr *base.PullRequest) error {
+	existing, err := g.getSyncedIssue(repo_model.MigrationSyncReferencePull, pr.GetForeignIndex())
+	if err != nil {
+		return err
+	}
+
+	// the git refs of the pull request are named after its local index
+	number := pr.Number
+	if existing != nil {
+		pr.Number = existing.Index
+	} else if pr.Number, err = g.syncIndex(number); err != nil {
+		return err
+	}
+	gpr, err := g.newPullRequest(pr)
+	pr.Number = number
+	if err != nil {
+		return err
+	}
+
+	if existing != nil {
+		local, err := issues_model.GetPullRequestByIssueID(g.ctx, existing.ID)
+		if err != nil {
+			return err
+		}
+		gpr.ID = local.ID
+		gpr.IssueID = existing.ID
+		gpr.Issue.ID = existing.ID
+		if err := models.UpdateMigratedPullRequest(gpr); err != nil {
+			return err
+		}
+	} else {
+		if err := models.InsertPullRequests(gpr); err != nil {
+			return err
+		}
+		if err := repo_model.SetMigrationSyncReference(g.ctx, g.repo.ID, repo_model.MigrationSyncReferencePull,
+			strconv.FormatInt(pr.GetForeignIndex(), 10), gpr.Issue.ID); err != nil {
+			return err
+		}
+	}
+	pull.AddToTaskQueue(gpr)
+
+	g.issues[number] = gpr.Issue
+	return nil
+}
+
+// syncCommentIssue returns the local issue or pull request a comment of the source belongs to, or nil
+func (g *GiteaLocalUploader) syncCommentIssue(issueIndex int64) (*issues_model.Issue, error) {
+	if issue, ok := g.issues[issueIndex]; ok {
+		return issue, nil
+	}
+	issue, err := g.getSyncedIssue(repo_model.MigrationSyncReferenceIssue, issueIndex)
+	if err != nil || issue != nil {
+		return issue, err
+	}
+	return g.getSyncedIssue(repo_model.MigrationSyncReferencePull, issueIndex)
+}
+
+// commentForeignID returns the identifier of a comment on the source,
+// not every source identifies its comments, so it falls back to what is unlikely to change
+func commentForeignID(comment *base.Comment) string {
+	if comment.Index == 0 {
+		return fmt.Sprintf("%d/%d/%s", comment.IssueIndex, comment.Created.Unix(), comment.PosterName)
+	}
+	return strconv.FormatInt(comment.Index, 10)
+}
+
+func (g *GiteaLocalUploader) syncComments(comments ...*base.Comment) error {
+	for _, comment := range comments {
+		issue, err := g.syncCommentIssue(comment.IssueIndex)
+		if err != nil {
+			return err
+		}
+		if issue == nil {
+			log.Warn("comment references the issue %d which hasn't been synchronized in %s/%s", comment.IssueIndex, g.repoOwner, g.repoName)
+			continue
+		}
+
+		foreignID := commentForeignID(comment)
+		cm, err := g.newComment(issue, comment)
+		if err != nil {
+			return err
+		}
+
+		id, err := repo_model.GetMigrationSyncReference(g.ctx, g.repo.ID, repo_model.MigrationSyncReferenceComment, foreignID)
+		if err != nil {
+			return err
+		}
+		if id > 0 {
+			if _, err := issues_model.GetCommentByID(g.ctx, id); err == nil {
+				cm.ID = id
+				if err := models.UpdateMigratedComment(cm); err != nil {
+					return err
+				}
+				continue
+			} else if !issues_model.IsErrCommentNotExist(err) {
+				return err
+			}
+		}
+
+		if err := models.InsertIssueComments([]*issues_model.Comment{cm}); err != nil {
+			return err
+		}
+		if err := repo_model.SetMigrationSyncReference(g.ctx, g.repo.ID, repo_model.MigrationSyncReferenceComment, foreignID, cm.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
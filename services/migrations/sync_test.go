@@ -0,0 +1,156 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package migrations
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	base "code.gitea.io/gitea/modules/migration"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type syncTestDownloader struct {
+	base.NullDownloader
+	labels   []*base.Label
+	issues   []*base.Issue
+	comments []*base.Comment
+}
+
+func (d *syncTestDownloader) GetLabels() ([]*base.Label, error) {
+	return d.labels, nil
+}
+
+func (d *syncTestDownloader) GetIssues(page, perPage int) ([]*base.Issue, bool, error) {
+	return d.issues, true, nil
+}
+
+func (d *syncTestDownloader) GetComments(commentable base.Commentable) ([]*base.Comment, bool, error) {
+	var comments []*base.Comment
+	for _, comment := range d.comments {
+		if comment.IssueIndex == commentable.GetLocalIndex() {
+			comments = append(comments, comment)
+		}
+	}
+	return comments, true, nil
+}
+
+func TestSyncRepository(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	downloader := &syncTestDownloader{
+		labels: []*base.Label{{Name: "synced", Color: "ff0000"}},
+		issues: []*base.Issue{
+			{Number: 1, Title: "index used locally", State: "open", Created: created, Labels: []*base.Label{{Name: "synced"}}},
+			{Number: 100, Title: "free index", State: "open", Created: created},
+		},
+		comments: []*base.Comment{
+			{IssueIndex: 1, Index: 10, Content: "first comment", Created: created},
+		},
+	}
+	opts := base.MigrateOptions{Labels: true, Issues: true, Comments: true}
+
+	sync := func() {
+		uploader := NewGiteaLocalUploader(context.Background(), doer, repo.OwnerName, repo.Name)
+		assert.NoError(t, uploader.openRepo(repo))
+		defer uploader.Close()
+		assert.NoError(t, syncRepository(downloader, uploader, opts))
+	}
+
+	sync()
+
+	label := unittest.AssertExistsAndLoadBean(t, &issues_model.Label{RepoID: repo.ID, Name: "synced"})
+	assert.Equal(t, "#ff0000", label.Color)
+
+	// the index 1 is already used by an issue which has been created locally, so the pulled issue gets another one
+	conflicting := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: repo.ID, Title: "index used locally"})
+	assert.NotEqualValues(t, 1, conflicting.Index)
+	unittest.AssertExistsAndLoadBean(t, &issues_model.IssueLabel{IssueID: conflicting.ID, LabelID: label.ID})
+	free := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: repo.ID, Title: "free index"})
+	assert.EqualValues(t, 100, free.Index)
+	comment := unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: conflicting.ID, Content: "first comment"})
+
+	// a second synchronization updates what has already been pulled
+	downloader.labels[0].Color = "00ff00"
+	downloader.issues[0].Title = "index used locally edited"
+	downloader.issues[0].State = "closed"
+	downloader.issues[0].Labels = nil
+	downloader.comments[0].Content = "first comment edited"
+	sync()
+
+	label = unittest.AssertExistsAndLoadBean(t, &issues_model.Label{ID: label.ID})
+	assert.Equal(t, "#00ff00", label.Color)
+	edited := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: conflicting.ID})
+	assert.Equal(t, "index used locally edited", edited.Title)
+	assert.True(t, edited.IsClosed)
+	assert.Equal(t, conflicting.Index, edited.Index)
+	unittest.AssertNotExistsBean(t, &issues_model.IssueLabel{IssueID: conflicting.ID, LabelID: label.ID})
+	unittest.AssertCount(t, &issues_model.Issue{RepoID: repo.ID, Title: "free index"}, 1)
+	comment = unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{ID: comment.ID})
+	assert.Equal(t, "first comment edited", comment.Content)
+	unittest.AssertCount(t, &issues_model.Comment{IssueID: conflicting.ID}, 1)
+}
+
+func TestSyncMigratedRepository(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4})
+	created := time.Date(2023, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	downloader := &syncTestDownloader{
+		labels: []*base.Label{{Name: "migrated", Color: "ff0000"}},
+		issues: []*base.Issue{
+			{Number: 1, Title: "migrated issue", State: "open", Created: created, Labels: []*base.Label{{Name: "migrated"}}},
+		},
+		comments: []*base.Comment{
+			{IssueIndex: 1, Index: 10, Content: "identified comment", Created: created},
+			{IssueIndex: 1, Content: "anonymous comment", Created: created.Add(time.Hour), PosterName: "someone"},
+		},
+	}
+	opts := base.MigrateOptions{Labels: true, Issues: true, Comments: true}
+
+	newUploader := func() *GiteaLocalUploader {
+		uploader := NewGiteaLocalUploader(context.Background(), doer, repo.OwnerName, repo.Name)
+		assert.NoError(t, uploader.openRepo(repo))
+		return uploader
+	}
+
+	// the first migration records what it creates
+	uploader := newUploader()
+	uploader.syncReferences = true
+	assert.NoError(t, uploader.CreateLabels(downloader.labels...))
+	assert.NoError(t, uploader.CreateIssues(downloader.issues...))
+	assert.NoError(t, uploader.CreateComments(downloader.comments...))
+	uploader.Close()
+
+	issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: repo.ID, Index: 1})
+	assert.Equal(t, "migrated issue", issue.Title)
+	unittest.AssertCount(t, &issues_model.Comment{IssueID: issue.ID}, 2)
+
+	downloader.issues[0].Title = "migrated issue edited"
+	downloader.comments[0].Content = "identified comment edited"
+	downloader.comments[1].Content = "anonymous comment edited"
+	uploader = newUploader()
+	assert.NoError(t, syncRepository(downloader, uploader, opts))
+	uploader.Close()
+
+	unittest.AssertCount(t, &issues_model.Issue{RepoID: repo.ID}, 1)
+	issue = unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{ID: issue.ID})
+	assert.Equal(t, "migrated issue edited", issue.Title)
+	assert.EqualValues(t, 1, issue.Index)
+	unittest.AssertCount(t, &issues_model.Comment{IssueID: issue.ID}, 2)
+	unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: issue.ID, Content: "identified comment edited"})
+	unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: issue.ID, Content: "anonymous comment edited"})
+}
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
 	org_model "code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -45,6 +46,14 @@ func DeleteOrganization(org *org_model.Organization) error {
 		return fmt.Errorf("DeleteOrganization: %w", err)
 	}
 
+	if _, err := db.DeleteByBean(ctx, &git_model.PushRule{OwnerID: org.ID}); err != nil {
+		return fmt.Errorf("DeletePushRule: %w", err)
+	}
+
+	if err := packages_model.DeleteQuotaByOwner(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteQuotaByOwner: %w", err)
+	}
+
 	if err := commiter.Commit(); err != nil {
 		return err
 	}
@@ -0,0 +1,431 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package arch
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	arch_model "code.gitea.io/gitea/models/packages/arch"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/json"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	arch_module "code.gitea.io/gitea/modules/packages/arch"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/keybase/go-crypto/openpgp/packet"
+)
+
+const (
+	DatabaseExtension = ".db.tar.gz"
+	FilesExtension    = ".files.tar.gz"
+	SignatureSuffix   = ".sig"
+)
+
+// GetOrCreateRepositoryVersion gets or creates the internal repository package
+// The Arch registry needs multiple database files which are stored in this package.
+func GetOrCreateRepositoryVersion(ownerID int64) (*packages_model.PackageVersion, error) {
+	return packages_service.GetOrCreateInternalPackageVersion(ownerID, packages_model.TypeArch, arch_module.RepositoryPackage, arch_module.RepositoryVersion)
+}
+
+// GetOrCreateKeyPair gets or creates the PGP keys used to sign packages and repository databases
+func GetOrCreateKeyPair(ownerID int64) (string, string, error) {
+	priv, err := user_model.GetSetting(ownerID, arch_module.SettingKeyPrivate)
+	if err != nil && !errors.Is(err, util.ErrNotExist) {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetSetting(ownerID, arch_module.SettingKeyPublic)
+	if err != nil && !errors.Is(err, util.ErrNotExist) {
+		return "", "", err
+	}
+
+	if priv == "" || pub == "" {
+		priv, pub, err = generateKeypair()
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, arch_module.SettingKeyPrivate, priv); err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, arch_module.SettingKeyPublic, pub); err != nil {
+			return "", "", err
+		}
+	}
+
+	return priv, pub, nil
+}
+
+func generateKeypair() (string, string, error) {
+	e, err := openpgp.NewEntity(setting.AppName, "Arch Registry", "", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	var priv strings.Builder
+	var pub strings.Builder
+
+	w, err := armor.Encode(&priv, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.SerializePrivate(w, nil); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	w, err = armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.Serialize(w); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	return priv.String(), pub.String(), nil
+}
+
+func getSigningEntity(ownerID int64) (*openpgp.Entity, error) {
+	priv, _, err := GetOrCreateKeyPair(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := armor.Decode(strings.NewReader(priv))
+	if err != nil {
+		return nil, err
+	}
+
+	return openpgp.ReadEntity(packet.NewReader(block.Body))
+}
+
+// GetKeyFingerprint gets the fingerprint of the PGP key, pacman-key needs it to trust the key
+func GetKeyFingerprint(ownerID int64) (string, error) {
+	e, err := getSigningEntity(ownerID)
+	if err != nil {
+		return "", err
+	}
+	return strings.ToUpper(hex.EncodeToString(e.PrimaryKey.Fingerprint[:])), nil
+}
+
+// CreateSignature creates the binary detached signature of the content, pacman verifies packages and databases with it
+func CreateSignature(ownerID int64, r io.Reader) ([]byte, error) {
+	e, err := getSigningEntity(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := openpgp.DetachSign(&buf, e, r, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// BuildAllRepositoryFiles (re)builds all repository files for every available repositories and architectures
+func BuildAllRepositoryFiles(ctx context.Context, ownerID int64) error {
+	pv, err := GetOrCreateRepositoryVersion(ownerID)
+	if err != nil {
+		return err
+	}
+
+	// 1. Delete all existing repository files
+	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, pf := range pfs {
+		if err := packages_model.DeleteAllProperties(ctx, packages_model.PropertyTypeFile, pf.ID); err != nil {
+			return err
+		}
+		if err := packages_model.DeleteFileByID(ctx, pf.ID); err != nil {
+			return err
+		}
+	}
+
+	// 2. (Re)Build repository files for existing packages
+	repositories, err := arch_model.GetRepositories(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	for _, repository := range repositories {
+		architectures, err := arch_model.GetArchitectures(ctx, ownerID, repository)
+		if err != nil {
+			return err
+		}
+		for _, architecture := range architectures {
+			if err := buildPackagesDatabases(ctx, ownerID, pv, repository, architecture); err != nil {
+				return fmt.Errorf("failed to build repository files [%s/%s]: %w", repository, architecture, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// BuildSpecificRepositoryFiles builds the databases of the repository for the architecture.
+// The packages of the "any" architecture are in the databases of every architecture, so they are all rebuilt.
+func BuildSpecificRepositoryFiles(ctx context.Context, ownerID int64, repository, architecture string) error {
+	pv, err := GetOrCreateRepositoryVersion(ownerID)
+	if err != nil {
+		return err
+	}
+
+	architectures := container.SetOf(architecture)
+	if architecture == arch_module.AnyArchitecture {
+		existing, err := arch_model.GetArchitectures(ctx, ownerID, repository)
+		if err != nil {
+			return err
+		}
+		architectures.AddMultiple(existing...)
+
+		// the databases of the architectures without packages of their own must be removed too
+		pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+		if err != nil {
+			return err
+		}
+		for _, pf := range pfs {
+			if r, a, ok := strings.Cut(pf.CompositeKey, "|"); ok && r == repository {
+				architectures.Add(a)
+			}
+		}
+	}
+
+	for _, architecture := range architectures.Values() {
+		if err := buildPackagesDatabases(ctx, ownerID, pv, repository, architecture); err != nil {
+			return fmt.Errorf("failed to build repository files [%s/%s]: %w", repository, architecture, err)
+		}
+	}
+	return nil
+}
+
+// https://man.archlinux.org/man/repo-add.8
+func buildPackagesDatabases(ctx context.Context, ownerID int64, repoVersion *packages_model.PackageVersion, repository, architecture string) error {
+	pfds, err := arch_model.SearchLatestPackages(ctx, ownerID, repository, architecture)
+	if err != nil {
+		return err
+	}
+
+	compositeKey := fmt.Sprintf("%s|%s", repository, architecture)
+	filenames := []string{
+		repository + DatabaseExtension,
+		repository + DatabaseExtension + SignatureSuffix,
+		repository + FilesExtension,
+		repository + FilesExtension + SignatureSuffix,
+	}
+
+	// Delete the databases if there are no packages
+	if len(pfds) == 0 {
+		for _, filename := range filenames {
+			pf, err := packages_model.GetFileForVersionByName(ctx, repoVersion.ID, filename, compositeKey)
+			if err != nil {
+				if errors.Is(err, util.ErrNotExist) {
+					continue
+				}
+				return err
+			}
+
+			if err := packages_model.DeleteAllProperties(ctx, packages_model.PropertyTypeFile, pf.ID); err != nil {
+				return err
+			}
+			if err := packages_model.DeleteFileByID(ctx, pf.ID); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	var dbBuf, filesBuf bytes.Buffer
+	dbWriter := newDatabaseWriter(&dbBuf)
+	filesWriter := newDatabaseWriter(&filesBuf)
+
+	for _, pfd := range pfds {
+		var versionMetadata arch_module.VersionMetadata
+		if err := json.Unmarshal([]byte(pfd.Version.MetadataJSON), &versionMetadata); err != nil {
+			return err
+		}
+		var fileMetadata arch_module.FileMetadata
+		if err := json.Unmarshal([]byte(pfd.Properties.GetByName(arch_module.PropertyMetadata)), &fileMetadata); err != nil {
+			return err
+		}
+
+		entry := fmt.Sprintf("%s-%s/", pfd.Package.Name, pfd.Version.Version)
+		modTime := pfd.File.CreatedUnix.AsTime()
+		desc := buildDescription(pfd, &versionMetadata, &fileMetadata)
+
+		var files bytes.Buffer
+		if list := pfd.Properties.GetByName(arch_module.PropertyFiles); list != "" {
+			writeDescriptionField(&files, "FILES", strings.Split(list, "\n")...)
+		}
+
+		if err := dbWriter.addEntry(entry, modTime, map[string][]byte{"desc": desc}); err != nil {
+			return err
+		}
+		if err := filesWriter.addEntry(entry, modTime, map[string][]byte{"desc": desc, "files": files.Bytes()}); err != nil {
+			return err
+		}
+	}
+
+	if err := dbWriter.Close(); err != nil {
+		return err
+	}
+	if err := filesWriter.Close(); err != nil {
+		return err
+	}
+
+	dbSignature, err := CreateSignature(ownerID, bytes.NewReader(dbBuf.Bytes()))
+	if err != nil {
+		return err
+	}
+	filesSignature, err := CreateSignature(ownerID, bytes.NewReader(filesBuf.Bytes()))
+	if err != nil {
+		return err
+	}
+
+	for i, data := range [][]byte{dbBuf.Bytes(), dbSignature, filesBuf.Bytes(), filesSignature} {
+		content, err := packages_module.CreateHashedBufferFromReader(bytes.NewReader(data))
+		if err != nil {
+			return err
+		}
+
+		_, err = packages_service.AddFileToPackageVersionInternal(
+			repoVersion,
+			&packages_service.PackageFileCreationInfo{
+				PackageFileInfo: packages_service.PackageFileInfo{
+					Filename:     filenames[i],
+					CompositeKey: compositeKey,
+				},
+				Creator:           user_model.NewGhostUser(),
+				Data:              content,
+				IsLead:            false,
+				OverwriteExisting: true,
+			},
+		)
+		content.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// buildDescription builds the "desc" file of a package in the databases
+func buildDescription(pfd *arch_model.PackageFileDescriptor, versionMetadata *arch_module.VersionMetadata, fileMetadata *arch_module.FileMetadata) []byte {
+	var buf bytes.Buffer
+
+	writeDescriptionField(&buf, "FILENAME", pfd.File.Name)
+	writeDescriptionField(&buf, "NAME", pfd.Package.Name)
+	writeDescriptionField(&buf, "BASE", versionMetadata.Base)
+	writeDescriptionField(&buf, "VERSION", pfd.Version.Version)
+	writeDescriptionField(&buf, "DESC", versionMetadata.Description)
+	writeDescriptionField(&buf, "GROUPS", versionMetadata.Groups...)
+	writeDescriptionField(&buf, "CSIZE", strconv.FormatInt(pfd.Blob.Size, 10))
+	writeDescriptionField(&buf, "ISIZE", strconv.FormatInt(fileMetadata.InstalledSize, 10))
+	writeDescriptionField(&buf, "MD5SUM", pfd.Blob.HashMD5)
+	writeDescriptionField(&buf, "SHA256SUM", pfd.Blob.HashSHA256)
+	writeDescriptionField(&buf, "PGPSIG", pfd.Properties.GetByName(arch_module.PropertySignature))
+	writeDescriptionField(&buf, "URL", versionMetadata.ProjectURL)
+	writeDescriptionField(&buf, "LICENSE", versionMetadata.Licenses...)
+	writeDescriptionField(&buf, "ARCH", fileMetadata.Architecture)
+	writeDescriptionField(&buf, "BUILDDATE", strconv.FormatInt(fileMetadata.BuildDate, 10))
+	writeDescriptionField(&buf, "PACKAGER", fileMetadata.Packager)
+	writeDescriptionField(&buf, "REPLACES", fileMetadata.Replaces...)
+	writeDescriptionField(&buf, "CONFLICTS", fileMetadata.Conflicts...)
+	writeDescriptionField(&buf, "PROVIDES", fileMetadata.Provides...)
+	writeDescriptionField(&buf, "DEPENDS", fileMetadata.Dependencies...)
+	writeDescriptionField(&buf, "OPTDEPENDS", fileMetadata.OptDepends...)
+	writeDescriptionField(&buf, "MAKEDEPENDS", fileMetadata.MakeDepends...)
+	writeDescriptionField(&buf, "CHECKDEPENDS", fileMetadata.CheckDepends...)
+
+	return buf.Bytes()
+}
+
+// writeDescriptionField writes a field with its values, the fields without values are omitted
+func writeDescriptionField(w io.Writer, name string, values ...string) {
+	if len(values) == 0 || (len(values) == 1 && values[0] == "") {
+		return
+	}
+	fmt.Fprintf(w, "%%%s%%\n", name)
+	for _, value := range values {
+		fmt.Fprintln(w, value)
+	}
+	fmt.Fprintln(w)
+}
+
+// databaseWriter writes a gzip compressed tar archive with a directory per package
+type databaseWriter struct {
+	zw *gzip.Writer
+	tw *tar.Writer
+}
+
+func newDatabaseWriter(w io.Writer) *databaseWriter {
+	zw := gzip.NewWriter(w)
+	return &databaseWriter{zw: zw, tw: tar.NewWriter(zw)}
+}
+
+func (dw *databaseWriter) addEntry(dir string, modTime time.Time, files map[string][]byte) error {
+	if err := dw.tw.WriteHeader(&tar.Header{
+		Typeflag: tar.TypeDir,
+		Name:     dir,
+		Mode:     0o755,
+		ModTime:  modTime,
+	}); err != nil {
+		return err
+	}
+
+	// the order of the files must be stable
+	for _, name := range []string{"desc", "files"} {
+		content, ok := files[name]
+		if !ok {
+			continue
+		}
+		if err := dw.tw.WriteHeader(&tar.Header{
+			Typeflag: tar.TypeReg,
+			Name:     dir + name,
+			Mode:     0o644,
+			Size:     int64(len(content)),
+			ModTime:  modTime,
+		}); err != nil {
+			return err
+		}
+		if _, err := dw.tw.Write(content); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (dw *databaseWriter) Close() error {
+	if err := dw.tw.Close(); err != nil {
+		return err
+	}
+	return dw.zw.Close()
+}
+
+// EncodeSignature encodes a signature to be stored as a property and written in the databases
+func EncodeSignature(signature []byte) string {
+	return base64.StdEncoding.EncodeToString(signature)
+}
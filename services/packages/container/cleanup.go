@@ -104,6 +104,26 @@ func ShouldBeSkipped(ctx context.Context, pcr *packages_model.PackageCleanupRule
 		if has {
 			return true, nil
 		}
+
+		// Skip it if the version is attached to a manifest (signature, SBOM, ...) which still exists
+		pps, err := packages_model.GetPropertiesByName(ctx, packages_model.PropertyTypeVersion, pv.ID, container_module.PropertyManifestSubject)
+		if err != nil {
+			return false, err
+		}
+		for _, pp := range pps {
+			_, err := container_model.GetContainerBlob(ctx, &container_model.BlobSearchOptions{
+				OwnerID:    p.OwnerID,
+				Image:      p.LowerName,
+				Digest:     pp.Value,
+				IsManifest: true,
+			})
+			if err == nil {
+				return true, nil
+			}
+			if err != container_model.ErrContainerBlobNotExist {
+				return false, err
+			}
+		}
 	}
 
 	return false, nil
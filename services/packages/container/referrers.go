@@ -0,0 +1,164 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package container
+
+import (
+	"context"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	container_model "code.gitea.io/gitea/models/packages/container"
+	"code.gitea.io/gitea/modules/container"
+	container_module "code.gitea.io/gitea/modules/packages/container"
+
+	digest "github.com/opencontainers/go-digest"
+	oci "github.com/opencontainers/image-spec/specs-go/v1"
+)
+
+// Referrer is a manifest attached to another manifest like a signature or an SBOM
+type Referrer struct {
+	Descriptor        oci.Descriptor
+	Kind              container_module.ArtifactKind
+	PackageDescriptor *packages_model.PackageDescriptor
+}
+
+// cosign stores artifacts in tags derived from the digest of the subject if the registry does not support referrers
+var cosignTagSuffixes = []struct {
+	Suffix string
+	Kind   container_module.ArtifactKind
+}{
+	{".sig", container_module.ArtifactKindSignature},
+	{".att", container_module.ArtifactKindAttestation},
+	{".sbom", container_module.ArtifactKindSBOM},
+}
+
+// GetReferrers gets all manifests of the image which reference the subject digest
+// If artifactType is not empty only referrers with this artifact type are returned.
+func GetReferrers(ctx context.Context, ownerID int64, image, subject, artifactType string) ([]*Referrer, error) {
+	pvs, err := container_model.GetReferrerVersions(ctx, ownerID, image, subject, artifactType)
+	if err != nil {
+		return nil, err
+	}
+
+	pds, err := packages_model.GetPackageDescriptors(ctx, pvs)
+	if err != nil {
+		return nil, err
+	}
+
+	referrers := make([]*Referrer, 0, len(pds))
+	seen := make(container.Set[string])
+	for _, pd := range pds {
+		r := newReferrer(pd)
+		if r == nil || !seen.Add(string(r.Descriptor.Digest)) {
+			continue
+		}
+
+		r.Descriptor.ArtifactType = pd.VersionProperties.GetByName(container_module.PropertyArtifactType)
+		r.Kind = container_module.GetArtifactKind(r.Descriptor.ArtifactType)
+
+		referrers = append(referrers, r)
+	}
+	return referrers, nil
+}
+
+// GetVersionReferrers gets all manifests attached to the package version.
+// In addition to the referrers the artifacts stored with the cosign tag scheme are returned.
+func GetVersionReferrers(ctx context.Context, pd *packages_model.PackageDescriptor) ([]*Referrer, error) {
+	subject := getManifestDigest(pd)
+	if subject == "" {
+		return nil, nil
+	}
+
+	referrers, err := GetReferrers(ctx, pd.Owner.ID, pd.Package.LowerName, subject, "")
+	if err != nil {
+		return nil, err
+	}
+
+	d := digest.Digest(subject)
+	if d.Validate() != nil {
+		return referrers, nil
+	}
+
+	for _, cts := range cosignTagSuffixes {
+		pv, err := packages_model.GetVersionByNameAndVersion(ctx, pd.Owner.ID, packages_model.TypeContainer, pd.Package.LowerName, d.Algorithm().String()+"-"+d.Encoded()+cts.Suffix)
+		if err != nil {
+			if err == packages_model.ErrPackageNotExist {
+				continue
+			}
+			return nil, err
+		}
+
+		tpd, err := packages_model.GetPackageDescriptor(ctx, pv)
+		if err != nil {
+			return nil, err
+		}
+
+		if r := newReferrer(tpd); r != nil {
+			r.Kind = cts.Kind
+			referrers = append(referrers, r)
+		}
+	}
+
+	return referrers, nil
+}
+
+// GetSubjectVersion gets the package version of the manifest the package version is attached to
+func GetSubjectVersion(ctx context.Context, pd *packages_model.PackageDescriptor) (*packages_model.PackageVersion, error) {
+	subject := pd.VersionProperties.GetByName(container_module.PropertyManifestSubject)
+	if subject == "" {
+		return nil, packages_model.ErrPackageNotExist
+	}
+
+	pvs, err := container_model.GetManifestVersions(ctx, &container_model.BlobSearchOptions{
+		OwnerID:    pd.Owner.ID,
+		Image:      pd.Package.LowerName,
+		Digest:     subject,
+		IsManifest: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(pvs) == 0 {
+		return nil, packages_model.ErrPackageNotExist
+	}
+
+	// prefer the digest version over tags
+	for _, pv := range pvs {
+		if strings.EqualFold(pv.LowerVersion, subject) {
+			return pv, nil
+		}
+	}
+	return pvs[0], nil
+}
+
+func getManifestDigest(pd *packages_model.PackageDescriptor) string {
+	for _, pf := range pd.Files {
+		if pf.File.LowerName == container_model.ManifestFilename {
+			return pf.Properties.GetByName(container_module.PropertyDigest)
+		}
+	}
+	return ""
+}
+
+func newReferrer(pd *packages_model.PackageDescriptor) *Referrer {
+	for _, pf := range pd.Files {
+		if pf.File.LowerName != container_model.ManifestFilename {
+			continue
+		}
+
+		r := &Referrer{
+			Descriptor: oci.Descriptor{
+				MediaType: pf.Properties.GetByName(container_module.PropertyMediaType),
+				Digest:    digest.Digest(pf.Properties.GetByName(container_module.PropertyDigest)),
+				Size:      pf.Blob.Size,
+			},
+			PackageDescriptor: pd,
+		}
+		if metadata, ok := pd.Metadata.(*container_module.Metadata); ok {
+			r.Descriptor.Annotations = metadata.Annotations
+		}
+		return r
+	}
+	return nil
+}
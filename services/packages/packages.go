@@ -325,7 +325,12 @@ func CheckCountQuotaExceeded(ctx context.Context, doer, owner *user_model.User)
 		return nil
 	}
 
-	if setting.Packages.LimitTotalOwnerCount > -1 {
+	_, limitTotalCount, err := GetOwnerLimits(ctx, owner.ID)
+	if err != nil {
+		return err
+	}
+
+	if limitTotalCount > -1 {
 		totalCount, err := packages_model.CountVersions(ctx, &packages_model.PackageSearchOptions{
 			OwnerID:    owner.ID,
 			IsInternal: util.OptionalBoolFalse,
@@ -334,7 +339,7 @@ func CheckCountQuotaExceeded(ctx context.Context, doer, owner *user_model.User)
 			log.Error("CountVersions failed: %v", err)
 			return err
 		}
-		if totalCount > setting.Packages.LimitTotalOwnerCount {
+		if totalCount > limitTotalCount {
 			return ErrQuotaTotalCount
 		}
 	}
@@ -353,6 +358,8 @@ func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, p
 	switch packageType {
 	case packages_model.TypeAlpine:
 		typeSpecificSize = setting.Packages.LimitSizeAlpine
+	case packages_model.TypeArch:
+		typeSpecificSize = setting.Packages.LimitSizeArch
 	case packages_model.TypeCargo:
 		typeSpecificSize = setting.Packages.LimitSizeCargo
 	case packages_model.TypeChef:
@@ -391,6 +398,8 @@ func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, p
 		typeSpecificSize = setting.Packages.LimitSizeRubyGems
 	case packages_model.TypeSwift:
 		typeSpecificSize = setting.Packages.LimitSizeSwift
+	case packages_model.TypeTerraform:
+		typeSpecificSize = setting.Packages.LimitSizeTerraform
 	case packages_model.TypeVagrant:
 		typeSpecificSize = setting.Packages.LimitSizeVagrant
 	}
@@ -398,7 +407,12 @@ func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, p
 		return ErrQuotaTypeSize
 	}
 
-	if setting.Packages.LimitTotalOwnerSize > -1 {
+	limitTotalSize, _, err := GetOwnerLimits(ctx, owner.ID)
+	if err != nil {
+		return err
+	}
+
+	if limitTotalSize > -1 {
 		totalSize, err := packages_model.CalculateFileSize(ctx, &packages_model.PackageFileSearchOptions{
 			OwnerID: owner.ID,
 		})
@@ -406,7 +420,7 @@ func CheckSizeQuotaExceeded(ctx context.Context, doer, owner *user_model.User, p
 			log.Error("CalculateFileSize failed: %v", err)
 			return err
 		}
-		if totalSize+uploadSize > setting.Packages.LimitTotalOwnerSize {
+		if totalSize+uploadSize > limitTotalSize {
 			return ErrQuotaTotalSize
 		}
 	}
@@ -0,0 +1,105 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package packages
+
+import (
+	"context"
+
+	git_model "code.gitea.io/gitea/models/git"
+	packages_model "code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// OwnerUsage describes the storage used by an owner
+type OwnerUsage struct {
+	Types           []*packages_model.TypeUsage
+	TotalSize       int64
+	TotalCount      int64
+	LimitTotalSize  int64
+	LimitTotalCount int64
+	LFSSize         int64
+	AttachmentsSize int64
+}
+
+// SizePercent returns the percentage of the size limit in use or -1 if there is no limit
+func (u *OwnerUsage) SizePercent() int {
+	return percent(u.TotalSize, u.LimitTotalSize)
+}
+
+// CountPercent returns the percentage of the count limit in use or -1 if there is no limit
+func (u *OwnerUsage) CountPercent() int {
+	return percent(u.TotalCount, u.LimitTotalCount)
+}
+
+func percent(value, limit int64) int {
+	if limit < 0 {
+		return -1
+	}
+	if limit == 0 || value >= limit {
+		return 100
+	}
+	return int(value * 100 / limit)
+}
+
+// GetOwnerLimits returns the total size and count limits of the owner.
+// The per-owner quota takes precedence over the global settings. -1 means unlimited.
+func GetOwnerLimits(ctx context.Context, ownerID int64) (int64, int64, error) {
+	limitTotalSize := setting.Packages.LimitTotalOwnerSize
+	limitTotalCount := setting.Packages.LimitTotalOwnerCount
+
+	pq, err := packages_model.GetQuotaByOwner(ctx, ownerID)
+	if err != nil {
+		if err == packages_model.ErrPackageQuotaNotExist {
+			return limitTotalSize, limitTotalCount, nil
+		}
+		log.Error("GetQuotaByOwner failed: %v", err)
+		return 0, 0, err
+	}
+
+	if pq.LimitTotalSize != nil {
+		limitTotalSize = *pq.LimitTotalSize
+	}
+	if pq.LimitTotalCount != nil {
+		limitTotalCount = *pq.LimitTotalCount
+	}
+	return limitTotalSize, limitTotalCount, nil
+}
+
+// GetOwnerUsage returns the storage used by the owner.
+// LFS and attachment sizes are informational and not counted against the package quota.
+func GetOwnerUsage(ctx context.Context, ownerID int64) (*OwnerUsage, error) {
+	types, err := packages_model.GetOwnerUsage(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	u := &OwnerUsage{
+		Types: types,
+	}
+	for _, t := range types {
+		u.TotalSize += t.Size
+		u.TotalCount += t.VersionCount
+	}
+
+	u.LimitTotalSize, u.LimitTotalCount, err = GetOwnerLimits(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if setting.LFS.StartServer {
+		u.LFSSize, err = git_model.GetOwnerLFSSize(ctx, ownerID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	u.AttachmentsSize, err = repo_model.GetOwnerAttachmentsSize(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
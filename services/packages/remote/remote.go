@@ -0,0 +1,291 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package remote
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/hostmatcher"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+var (
+	// ErrUpstreamNotExist indicates that the upstream registry does not know the requested content
+	ErrUpstreamNotExist = util.NewNotExistErrorf("content does not exist in the upstream registry")
+	// ErrUpstreamIntegrity indicates that content fetched from the upstream registry does not match its checksum
+	ErrUpstreamIntegrity = errors.New("content fetched from the upstream registry does not match its checksum")
+)
+
+var (
+	httpClient     *http.Client
+	httpClientOnce sync.Once
+
+	challengeParamPattern = regexp.MustCompile(`(\w+)="([^"]*)"`)
+)
+
+func getHTTPClient() *http.Client {
+	httpClientOnce.Do(func() {
+		allowedHostListValue := setting.Packages.RemoteAllowedHostList
+		if allowedHostListValue == "" {
+			allowedHostListValue = hostmatcher.MatchBuiltinExternal
+		}
+		allowedHostMatcher := hostmatcher.ParseHostMatchList("packages.REMOTE_ALLOWED_HOST_LIST", allowedHostListValue)
+
+		httpClient = &http.Client{
+			Transport: &http.Transport{
+				Proxy:                 proxy.Proxy(),
+				DialContext:           hostmatcher.NewDialContext("package remote", allowedHostMatcher, nil),
+				ResponseHeaderTimeout: time.Minute,
+			},
+		}
+	})
+	return httpClient
+}
+
+// GetEnabledRemote returns the enabled remote of the owner for the package type or nil if there is none
+func GetEnabledRemote(ctx context.Context, ownerID int64, packageType packages_model.Type) (*packages_model.PackageRemote, error) {
+	if !packageType.IsRemoteSupported() {
+		return nil, nil
+	}
+
+	pr, err := packages_model.GetEnabledRemoteByOwnerAndType(ctx, ownerID, packageType)
+	if err != nil {
+		if err == packages_model.ErrPackageRemoteNotExist {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return pr, nil
+}
+
+// Client fetches content from the upstream registry of a remote
+type Client struct {
+	remote   *packages_model.PackageRemote
+	baseURL  *url.URL
+	password string
+	token    string
+}
+
+// NewClient creates a client for the upstream registry of the remote
+func NewClient(pr *packages_model.PackageRemote) (*Client, error) {
+	baseURL, err := url.Parse(pr.URL)
+	if err != nil {
+		return nil, err
+	}
+
+	password, err := pr.Password()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Client{
+		remote:   pr,
+		baseURL:  baseURL,
+		password: password,
+	}, nil
+}
+
+// URL returns the upstream url of the (already escaped) path
+func (c *Client) URL(p string) string {
+	return strings.TrimSuffix(c.remote.URL, "/") + "/" + strings.TrimPrefix(p, "/")
+}
+
+// Do sends a request to the upstream registry. Requests to the host of the remote are authenticated with
+// the credentials of the remote and a bearer token challenge (used by container registries) is answered once.
+// ErrUpstreamNotExist is returned if the upstream registry responds with 404.
+func (c *Client) Do(ctx context.Context, method, rawURL string, header http.Header) (*http.Response, error) {
+	resp, err := c.do(ctx, method, rawURL, header)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode == http.StatusUnauthorized && c.token == "" {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+
+		if !strings.HasPrefix(strings.ToLower(challenge), "bearer ") {
+			return nil, fmt.Errorf("upstream registry %s rejected the credentials", c.remote.URL)
+		}
+		if err := c.requestToken(ctx, challenge); err != nil {
+			return nil, err
+		}
+
+		if resp, err = c.do(ctx, method, rawURL, header); err != nil {
+			return nil, err
+		}
+	}
+
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, ErrUpstreamNotExist
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		resp.Body.Close()
+		return nil, fmt.Errorf("upstream registry responded to %s %s with status %d", method, rawURL, resp.StatusCode)
+	}
+	return resp, nil
+}
+
+func (c *Client) do(ctx context.Context, method, rawURL string, header http.Header) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, rawURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	req.Header.Set("User-Agent", "Gitea "+setting.AppVer)
+
+	if req.URL.Host == c.baseURL.Host {
+		if c.token != "" {
+			req.Header.Set("Authorization", "Bearer "+c.token)
+		} else if c.remote.Username != "" {
+			req.SetBasicAuth(c.remote.Username, c.password)
+		}
+	}
+
+	return getHTTPClient().Do(req)
+}
+
+// https://distribution.github.io/distribution/spec/auth/token/
+func (c *Client) requestToken(ctx context.Context, challenge string) error {
+	params := make(map[string]string)
+	for _, m := range challengeParamPattern.FindAllStringSubmatch(challenge, -1) {
+		params[strings.ToLower(m[1])] = m[2]
+	}
+
+	realm, err := url.Parse(params["realm"])
+	if err != nil || realm.Host == "" {
+		return fmt.Errorf("upstream registry %s sent an invalid authentication challenge", c.remote.URL)
+	}
+	q := realm.Query()
+	for _, k := range []string{"service", "scope"} {
+		if v, ok := params[k]; ok {
+			q.Set(k, v)
+		}
+	}
+	realm.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("User-Agent", "Gitea "+setting.AppVer)
+	if c.remote.Username != "" {
+		req.SetBasicAuth(c.remote.Username, c.password)
+	}
+
+	resp, err := getHTTPClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("token endpoint of upstream registry %s responded with status %d", c.remote.URL, resp.StatusCode)
+	}
+
+	var tokenResponse struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tokenResponse); err != nil {
+		return err
+	}
+
+	c.token = tokenResponse.Token
+	if c.token == "" {
+		c.token = tokenResponse.AccessToken
+	}
+	if c.token == "" {
+		return fmt.Errorf("token endpoint of upstream registry %s did not return a token", c.remote.URL)
+	}
+	return nil
+}
+
+// Download fetches the content at the url into a hashed buffer
+func (c *Client) Download(ctx context.Context, rawURL string, header http.Header) (*packages_module.HashedBuffer, error) {
+	resp, err := c.Do(ctx, http.MethodGet, rawURL, header)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	return packages_module.CreateHashedBufferFromReader(resp.Body)
+}
+
+// GetMetadata returns the upstream metadata stored under the name. Metadata older than the TTL of the remote gets
+// refreshed with fetch. Stale metadata is returned if the upstream registry is not reachable.
+func GetMetadata(ctx context.Context, pr *packages_model.PackageRemote, name string, fetch func(context.Context) ([]byte, error)) ([]byte, error) {
+	prm, err := packages_model.GetRemoteMetadata(ctx, pr.ID, name)
+	if err != nil && err != packages_model.ErrPackageRemoteMetadataNotExist {
+		return nil, err
+	}
+	if prm != nil && time.Since(prm.FetchedUnix.AsTime()) < pr.MetadataTTL() {
+		return []byte(prm.Content), nil
+	}
+
+	content, err := fetch(ctx)
+	if err != nil {
+		if prm != nil && !errors.Is(err, util.ErrNotExist) {
+			log.Warn("Using stale metadata %s of package remote %d: %v", name, pr.ID, err)
+			return []byte(prm.Content), nil
+		}
+		return nil, err
+	}
+
+	if err := packages_model.SetRemoteMetadata(ctx, pr.ID, name, string(content)); err != nil {
+		return nil, err
+	}
+	return content, nil
+}
+
+// FetchMetadata is a GetMetadata fetch function which reads the content at the url
+func (c *Client) FetchMetadata(rawURL string, header http.Header) func(context.Context) ([]byte, error) {
+	return func(ctx context.Context) ([]byte, error) {
+		resp, err := c.Do(ctx, http.MethodGet, rawURL, header)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+
+		return io.ReadAll(resp.Body)
+	}
+}
+
+// CachePackageFile stores a file fetched from the upstream registry of the remote.
+// The created package version is owned and created by the owner of the remote and marked as cached.
+// Files which got cached concurrently by another request are ignored.
+func CachePackageFile(pr *packages_model.PackageRemote, pvci *packages_service.PackageCreationInfo, pfci *packages_service.PackageFileCreationInfo) error {
+	pvci.Creator = pvci.Owner
+	pfci.Creator = pvci.Owner
+	if pvci.VersionProperties == nil {
+		pvci.VersionProperties = make(map[string]string, 1)
+	}
+	pvci.VersionProperties[packages_model.PropertyRemoteOrigin] = pr.URL
+
+	_, _, err := packages_service.CreatePackageOrAddFileToExisting(pvci, pfci)
+	if err == packages_model.ErrDuplicatePackageFile {
+		return nil
+	}
+	return err
+}
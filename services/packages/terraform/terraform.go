@@ -0,0 +1,145 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package terraform
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/keybase/go-crypto/openpgp/packet"
+)
+
+// GetOrCreateKeyPair gets or creates the PGP keys used to sign the checksums of the providers
+func GetOrCreateKeyPair(ownerID int64) (string, string, error) {
+	priv, err := user_model.GetSetting(ownerID, terraform_module.SettingKeyPrivate)
+	if err != nil && !errors.Is(err, util.ErrNotExist) {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetSetting(ownerID, terraform_module.SettingKeyPublic)
+	if err != nil && !errors.Is(err, util.ErrNotExist) {
+		return "", "", err
+	}
+
+	if priv == "" || pub == "" {
+		priv, pub, err = generateKeypair()
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, terraform_module.SettingKeyPrivate, priv); err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, terraform_module.SettingKeyPublic, pub); err != nil {
+			return "", "", err
+		}
+	}
+
+	return priv, pub, nil
+}
+
+func generateKeypair() (string, string, error) {
+	e, err := openpgp.NewEntity(setting.AppName, "Terraform Registry", "", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	var priv strings.Builder
+	var pub strings.Builder
+
+	w, err := armor.Encode(&priv, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.SerializePrivate(w, nil); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	w, err = armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.Serialize(w); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	return priv.String(), pub.String(), nil
+}
+
+func getSigningEntity(ownerID int64) (*openpgp.Entity, error) {
+	priv, _, err := GetOrCreateKeyPair(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	block, err := armor.Decode(strings.NewReader(priv))
+	if err != nil {
+		return nil, err
+	}
+
+	return openpgp.ReadEntity(packet.NewReader(block.Body))
+}
+
+// GetKeyID gets the id of the PGP key, Terraform lists it in the signing keys of a provider
+func GetKeyID(ownerID int64) (string, error) {
+	e, err := getSigningEntity(ownerID)
+	if err != nil {
+		return "", err
+	}
+	return e.PrimaryKey.KeyIdString(), nil
+}
+
+// ProviderFilename gets the name of the archive of a provider for the platform
+func ProviderFilename(providerType, version, os, arch string) string {
+	return fmt.Sprintf("terraform-provider-%s_%s_%s_%s.zip", providerType, version, os, arch)
+}
+
+// ChecksumsFilename gets the name of the checksums file of a provider version
+func ChecksumsFilename(providerType, version string) string {
+	return fmt.Sprintf("terraform-provider-%s_%s_SHA256SUMS", providerType, version)
+}
+
+// BuildChecksums builds the SHA256SUMS file which lists the checksums of all archives of the provider version
+func BuildChecksums(pd *packages_model.PackageDescriptor) []byte {
+	pfds := make([]*packages_model.PackageFileDescriptor, len(pd.Files))
+	copy(pfds, pd.Files)
+	sort.Slice(pfds, func(i, j int) bool {
+		return pfds[i].File.Name < pfds[j].File.Name
+	})
+
+	var buf bytes.Buffer
+	for _, pfd := range pfds {
+		fmt.Fprintf(&buf, "%s  %s\n", pfd.Blob.HashSHA256, pfd.File.Name)
+	}
+	return buf.Bytes()
+}
+
+// CreateSignature creates the binary detached signature of the content, Terraform verifies the checksums with it
+func CreateSignature(ownerID int64, r io.Reader) ([]byte, error) {
+	e, err := getSigningEntity(ownerID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := openpgp.DetachSign(&buf, e, r, nil); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
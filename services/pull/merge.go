@@ -141,6 +141,32 @@ func GetDefaultMergeMessage(ctx context.Context, baseGitRepo *git.Repository, pr
 	return getMergeMessage(ctx, baseGitRepo, pr, mergeStyle, nil)
 }
 
+// CheckMergeStyleAllowed returns ErrInvalidMergeStyle if the merge style is not allowed by the settings of the base repository,
+// or if it would create a merge commit on a protected base branch requiring linear history
+func CheckMergeStyleAllowed(ctx context.Context, pr *issues_model.PullRequest, mergeStyle repo_model.MergeStyle) error {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return fmt.Errorf("LoadBaseRepo: %w", err)
+	}
+
+	prUnit, err := pr.BaseRepo.GetUnit(ctx, unit.TypePullRequests)
+	if err != nil {
+		log.Error("pr.BaseRepo.GetUnit(unit.TypePullRequests): %v", err)
+		return err
+	}
+	if !prUnit.PullRequestsConfig().IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return fmt.Errorf("LoadProtectedBranch: %w", err)
+	}
+	if pb != nil && !pb.IsMergeStyleAllowed(mergeStyle) {
+		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	}
+	return nil
+}
+
 // Merge merges pull request to base repository.
 // Caller should check PR is ready to be merged (review and status checks)
 func Merge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, baseGitRepo *git.Repository, mergeStyle repo_model.MergeStyle, expectedHeadCommitID, message string, wasAutoMerged bool) error {
@@ -161,16 +187,9 @@ func Merge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.U
 		return err
 	}
 
-	prUnit, err := pr.BaseRepo.GetUnit(ctx, unit.TypePullRequests)
-	if err != nil {
-		log.Error("pr.BaseRepo.GetUnit(unit.TypePullRequests): %v", err)
-		return err
-	}
-	prConfig := prUnit.PullRequestsConfig()
-
 	// Check if merge style is correct and allowed
-	if !prConfig.IsMergeStyleAllowed(mergeStyle) {
-		return models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	if err := CheckMergeStyleAllowed(ctx, pr, mergeStyle); err != nil {
+		return err
 	}
 
 	defer func() {
@@ -180,11 +199,18 @@ func Merge(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.U
 	// Run the merge in the hammer context to prevent cancellation
 	hammerCtx := graceful.GetManager().HammerContext()
 
+	var err error
 	pr.MergedCommitID, err = doMergeAndPush(hammerCtx, pr, doer, mergeStyle, expectedHeadCommitID, message)
 	if err != nil {
 		return err
 	}
 
+	return handleMergedPullRequest(hammerCtx, pr, doer, wasAutoMerged)
+}
+
+// handleMergedPullRequest marks the pull request as merged once its merge commit has been pushed to the base branch,
+// sends the notifications and resolves the cross references
+func handleMergedPullRequest(hammerCtx context.Context, pr *issues_model.PullRequest, doer *user_model.User, wasAutoMerged bool) error {
 	pr.MergedUnix = timeutil.TimeStampNow()
 	pr.Merger = doer
 	pr.MergerID = doer.ID
@@ -250,21 +276,8 @@ func doMergeAndPush(ctx context.Context, pr *issues_model.PullRequest, doer *use
 	defer cancel()
 
 	// Merge commits.
-	switch mergeStyle {
-	case repo_model.MergeStyleMerge:
-		if err := doMergeStyleMerge(mergeCtx, message); err != nil {
-			return "", err
-		}
-	case repo_model.MergeStyleRebase, repo_model.MergeStyleRebaseMerge:
-		if err := doMergeStyleRebase(mergeCtx, mergeStyle, message); err != nil {
-			return "", err
-		}
-	case repo_model.MergeStyleSquash:
-		if err := doMergeStyleSquash(mergeCtx, message); err != nil {
-			return "", err
-		}
-	default:
-		return "", models.ErrInvalidMergeStyle{ID: pr.BaseRepo.ID, Style: mergeStyle}
+	if err := doMergeStyle(mergeCtx, mergeStyle, message); err != nil {
+		return "", err
 	}
 
 	// OK we should cache our current head and origin/headbranch
@@ -339,6 +352,20 @@ func doMergeAndPush(ctx context.Context, pr *issues_model.PullRequest, doer *use
 	return mergeCommitID, nil
 }
 
+// doMergeStyle merges the tracking branch into the base branch of the temporary repository using the given merge style
+func doMergeStyle(mergeCtx *mergeContext, mergeStyle repo_model.MergeStyle, message string) error {
+	switch mergeStyle {
+	case repo_model.MergeStyleMerge:
+		return doMergeStyleMerge(mergeCtx, message)
+	case repo_model.MergeStyleRebase, repo_model.MergeStyleRebaseMerge:
+		return doMergeStyleRebase(mergeCtx, mergeStyle, message)
+	case repo_model.MergeStyleSquash:
+		return doMergeStyleSquash(mergeCtx, message)
+	default:
+		return models.ErrInvalidMergeStyle{ID: mergeCtx.pr.BaseRepo.ID, Style: mergeStyle}
+	}
+}
+
 func commitAndSignNoAuthor(ctx *mergeContext, message string) error {
 	cmdCommit := git.NewCommand(ctx, "commit").AddOptionFormat("--message=%s", message)
 	if ctx.signKeyID == "" {
@@ -417,13 +444,16 @@ func CheckPullBranchProtections(ctx context.Context, pr *issues_model.PullReques
 		return nil
 	}
 
-	isPass, err := IsPullCommitStatusPass(ctx, pr)
-	if err != nil {
-		return err
-	}
-	if !isPass {
-		return models.ErrDisallowedToMerge{
-			Reason: "Not all required status checks successful",
+	// With a merge queue the required status checks are run against the queue commit instead of the head of the pull request
+	if !pb.EnableMergeQueue {
+		isPass, err := IsPullCommitStatusPass(ctx, pr)
+		if err != nil {
+			return err
+		}
+		if !isPass {
+			return models.ErrDisallowedToMerge{
+				Reason: "Not all required status checks successful",
+			}
 		}
 	}
 
@@ -442,6 +472,17 @@ func CheckPullBranchProtections(ctx context.Context, pr *issues_model.PullReques
 			Reason: "There are official review requests",
 		}
 	}
+	if issues_model.MergeBlockedByCodeOwners(ctx, pb, pr) {
+		return models.ErrDisallowedToMerge{
+			Reason: "Not all changed files have been approved by their code owners",
+		}
+	}
+
+	if issues_model.MergeBlockedByUnresolvedConversations(ctx, pb, pr) {
+		return models.ErrDisallowedToMerge{
+			Reason: "Not all code review conversations have been resolved",
+		}
+	}
 
 	if issues_model.MergeBlockedByOutdatedBranch(pb, pr) {
 		return models.ErrDisallowedToMerge{
@@ -0,0 +1,212 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/gobwas/glob"
+)
+
+// MergeQueueBranchPrefix is the prefix of the temporary branches the merge queue runs the status checks on
+const MergeQueueBranchPrefix = "gitea-merge-queue/"
+
+// MergeQueueBranchName returns the name of the temporary branch used for the pull request while it is in the merge queue
+func MergeQueueBranchName(pr *issues_model.PullRequest) string {
+	return fmt.Sprintf("%s%s/pr-%d", MergeQueueBranchPrefix, pr.BaseBranch, pr.Index)
+}
+
+// IsMergeQueueBranch returns true if the branch is a temporary branch of the merge queue
+func IsMergeQueueBranch(branchName string) bool {
+	return strings.HasPrefix(branchName, MergeQueueBranchPrefix)
+}
+
+// MergeQueueBaseBranch returns the base branch of a temporary merge queue branch
+func MergeQueueBaseBranch(branchName string) (string, bool) {
+	if !IsMergeQueueBranch(branchName) {
+		return "", false
+	}
+	idx := strings.LastIndex(branchName, "/pr-")
+	if idx <= len(MergeQueueBranchPrefix) {
+		return "", false
+	}
+	return branchName[len(MergeQueueBranchPrefix):idx], true
+}
+
+// IsMergeQueueEnabled returns true if the pull requests to the base branch of pr have to go through the merge queue
+func IsMergeQueueEnabled(ctx context.Context, pr *issues_model.PullRequest) (bool, error) {
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return false, err
+	}
+	return pb != nil && pb.EnableMergeQueue, nil
+}
+
+// BuildMergeQueueCommit merges the pull request with the given merge style on top of parentBranch
+// (the base branch or the queue branch of the previous entry) and force pushes the result to the queue branch of the pull request.
+// It returns the commit the result has been built on and the resulting commit.
+func BuildMergeQueueCommit(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, mergeStyle repo_model.MergeStyle, message, parentBranch string) (baseCommitID, queueCommitID string, err error) {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return "", "", fmt.Errorf("unable to load base repo: %w", err)
+	}
+
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	// The temporary repository uses the base branch of the pull request as merge target,
+	// so work on a copy of the pull request whose base is the parent of the queue commit.
+	queuePR := *pr
+	queuePR.BaseBranch = parentBranch
+
+	mergeCtx, cancel, err := createTemporaryRepoForMerge(ctx, &queuePR, doer, "")
+	if err != nil {
+		return "", "", err
+	}
+	defer cancel()
+
+	if err := doMergeStyle(mergeCtx, mergeStyle, message); err != nil {
+		return "", "", err
+	}
+
+	mergeHeadSHA, err := git.GetFullCommitID(ctx, mergeCtx.tmpBasePath, "HEAD")
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to get full commit id for HEAD: %w", err)
+	}
+	baseCommitID, err = git.GetFullCommitID(ctx, mergeCtx.tmpBasePath, "original_"+baseBranch)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to get full commit id for origin/%s: %w", parentBranch, err)
+	}
+	queueCommitID, err = git.GetFullCommitID(ctx, mergeCtx.tmpBasePath, baseBranch)
+	if err != nil {
+		return "", "", fmt.Errorf("Failed to get full commit id for the new merge: %w", err)
+	}
+
+	if setting.LFS.StartServer {
+		if err := LFSPush(ctx, mergeCtx.tmpBasePath, mergeHeadSHA, baseCommitID, pr); err != nil {
+			return "", "", err
+		}
+	}
+
+	// Pushing the queue branch runs the hooks, so the configured status checks (e.g. actions) get triggered
+	mergeCtx.env = repo_module.FullPushingEnvironment(doer, doer, pr.BaseRepo, pr.BaseRepo.Name, pr.ID)
+	pushCmd := git.NewCommand(ctx, "push", "--force", "origin").AddDynamicArguments(baseBranch + ":" + git.BranchPrefix + MergeQueueBranchName(pr))
+	if err := pushCmd.Run(mergeCtx.RunOpts()); err != nil {
+		if strings.Contains(mergeCtx.errbuf.String(), "! [remote rejected]") {
+			err := &git.ErrPushRejected{
+				StdOut: mergeCtx.outbuf.String(),
+				StdErr: mergeCtx.errbuf.String(),
+				Err:    err,
+			}
+			err.GenerateMessage()
+			return "", "", err
+		}
+		return "", "", fmt.Errorf("git push: %s", mergeCtx.errbuf.String())
+	}
+	mergeCtx.outbuf.Reset()
+	mergeCtx.errbuf.Reset()
+
+	return baseCommitID, queueCommitID, nil
+}
+
+// GetMergeQueueCommitStatusState returns the state of the required status checks of a queue commit.
+// Other than for the head of a pull request, a missing required status is always considered as pending.
+func GetMergeQueueCommitStatusState(ctx context.Context, pr *issues_model.PullRequest, queueCommitID string) (structs.CommitStatusState, error) {
+	pb, err := git_model.GetFirstMatchProtectedBranchRule(ctx, pr.BaseRepoID, pr.BaseBranch)
+	if err != nil {
+		return "", err
+	}
+	if pb == nil || !pb.EnableStatusCheck {
+		return structs.CommitStatusSuccess, nil
+	}
+
+	commitStatuses, _, err := git_model.GetLatestCommitStatus(ctx, pr.BaseRepoID, queueCommitID, db.ListOptions{})
+	if err != nil {
+		return "", err
+	}
+	if len(commitStatuses) == 0 {
+		return structs.CommitStatusPending, nil
+	}
+
+	for _, requiredContext := range pb.StatusCheckContexts {
+		gp, err := glob.Compile(requiredContext)
+		if err != nil {
+			log.Error("glob.Compile %s failed. Error: %v", requiredContext, err)
+			continue
+		}
+		found := false
+		for _, commitStatus := range commitStatuses {
+			if gp.Match(commitStatus.Context) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return structs.CommitStatusPending, nil
+		}
+	}
+
+	return MergeRequiredContextsCommitStatus(commitStatuses, pb.StatusCheckContexts), nil
+}
+
+// MergeQueueFastForward fast-forwards the base branch of the pull request to its tested queue commit
+// and marks the pull request as merged.
+func MergeQueueFastForward(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User, queueCommitID string) error {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return fmt.Errorf("unable to load base repo: %w", err)
+	}
+
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	defer func() {
+		go AddTestPullRequestTask(doer, pr.BaseRepo.ID, pr.BaseBranch, false, "", "")
+	}()
+
+	// Run the merge in the hammer context to prevent cancellation
+	hammerCtx := graceful.GetManager().HammerContext()
+
+	// Without force the push is rejected if the base branch has moved on in the meantime
+	if err := git.Push(hammerCtx, pr.BaseRepo.RepoPath(), git.PushOptions{
+		Remote: pr.BaseRepo.RepoPath(),
+		Branch: queueCommitID + ":" + git.BranchPrefix + pr.BaseBranch,
+		Env:    repo_module.FullPushingEnvironment(doer, doer, pr.BaseRepo, pr.BaseRepo.Name, pr.ID),
+	}); err != nil {
+		return err
+	}
+
+	pr.MergedCommitID = queueCommitID
+	return handleMergedPullRequest(hammerCtx, pr, doer, true)
+}
+
+// DeleteMergeQueueBranch deletes the temporary merge queue branch of the pull request if it exists
+func DeleteMergeQueueBranch(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User) error {
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return err
+	}
+
+	branchName := MergeQueueBranchName(pr)
+	if !git.IsBranchExist(ctx, pr.BaseRepo.RepoPath(), branchName) {
+		return nil
+	}
+
+	return git.Push(ctx, pr.BaseRepo.RepoPath(), git.PushOptions{
+		Remote: pr.BaseRepo.RepoPath(),
+		Branch: ":" + git.BranchPrefix + branchName,
+		Env:    repo_module.PushingEnvironment(doer, pr.BaseRepo),
+	})
+}
@@ -0,0 +1,53 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package pull
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMergeQueueBranchName(t *testing.T) {
+	pr := &issues_model.PullRequest{Index: 3, BaseBranch: "release/v1.21"}
+	name := MergeQueueBranchName(pr)
+	assert.Equal(t, "gitea-merge-queue/release/v1.21/pr-3", name)
+	assert.True(t, IsMergeQueueBranch(name))
+	assert.False(t, IsMergeQueueBranch("release/v1.21"))
+
+	base, ok := MergeQueueBaseBranch(name)
+	assert.True(t, ok)
+	assert.Equal(t, "release/v1.21", base)
+
+	_, ok = MergeQueueBaseBranch("release/v1.21")
+	assert.False(t, ok)
+	_, ok = MergeQueueBaseBranch(MergeQueueBranchPrefix + "pr-3")
+	assert.False(t, ok)
+}
+
+func TestIsMergeQueueEnabled(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	pr := unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: 2})
+	assert.NoError(t, pr.LoadBaseRepo(db.DefaultContext))
+
+	enabled, err := IsMergeQueueEnabled(db.DefaultContext, pr)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+
+	assert.NoError(t, git_model.UpdateProtectBranch(db.DefaultContext, pr.BaseRepo, &git_model.ProtectedBranch{
+		RepoID:           pr.BaseRepoID,
+		RuleName:         pr.BaseBranch,
+		EnableMergeQueue: true,
+	}, git_model.WhitelistOptions{}))
+
+	enabled, err = IsMergeQueueEnabled(db.DefaultContext, pr)
+	assert.NoError(t, err)
+	assert.True(t, enabled)
+}
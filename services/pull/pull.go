@@ -322,6 +322,10 @@ func AddTestPullRequestTask(doer *user_model.User, repoID int64, branch string,
 								log.Error("UpdateCommitDivergence: %v", err)
 							}
 						}
+						// The new commits might touch files owned by other code owners
+						if err := issues_model.PullRequestCodeOwnersReview(ctx, pr.Issue, pr); err != nil {
+							log.Error("PullRequestCodeOwnersReview: %v", err)
+						}
 					}
 
 					notification.NotifyPullRequestSynchronized(ctx, doer, pr)
@@ -13,6 +13,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	issues_model "code.gitea.io/gitea/models/issues"
+	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
@@ -70,13 +71,24 @@ func InvalidateCodeComments(ctx context.Context, prs issues_model.PullRequestLis
 	return nil
 }
 
-// CreateCodeComment creates a comment on the code line
-func CreateCodeComment(ctx context.Context, doer *user_model.User, gitRepo *git.Repository, issue *issues_model.Issue, line int64, content, treePath string, pendingReview bool, replyReviewID int64, latestCommitID string) (*issues_model.Comment, error) {
+// CreateCodeComment creates a comment on the code line, or on the lines from startLine to line if startLine is not 0
+func CreateCodeComment(ctx context.Context, doer *user_model.User, gitRepo *git.Repository, issue *issues_model.Issue, startLine, line int64, content, treePath string, pendingReview bool, replyReviewID int64, latestCommitID string) (*issues_model.Comment, error) {
 	var (
 		existsReview bool
 		err          error
 	)
 
+	if startLine == line {
+		startLine = 0
+	}
+	if startLine != 0 {
+		// both lines have to be on the same side of the diff
+		lines := &issues_model.Comment{StartLine: startLine, Line: line}
+		if (startLine < 0) != (line < 0) || lines.UnsignedStartLine() > lines.UnsignedLine() {
+			return nil, util.NewInvalidArgumentErrorf("invalid line range %d-%d of a code comment", startLine, line)
+		}
+	}
+
 	// CreateCodeComment() is used for:
 	// - Single comments
 	// - Comments that are part of a review
@@ -102,6 +114,7 @@ func CreateCodeComment(ctx context.Context, doer *user_model.User, gitRepo *git.
 			issue,
 			content,
 			treePath,
+			startLine,
 			line,
 			replyReviewID,
 		)
@@ -142,6 +155,7 @@ func CreateCodeComment(ctx context.Context, doer *user_model.User, gitRepo *git.
 		issue,
 		content,
 		treePath,
+		startLine,
 		line,
 		review.ID,
 	)
@@ -162,7 +176,7 @@ func CreateCodeComment(ctx context.Context, doer *user_model.User, gitRepo *git.
 }
 
 // createCodeComment creates a plain code comment at the specified line / path
-func createCodeComment(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, issue *issues_model.Issue, content, treePath string, line, reviewID int64) (*issues_model.Comment, error) {
+func createCodeComment(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, issue *issues_model.Issue, content, treePath string, startLine, line, reviewID int64) (*issues_model.Comment, error) {
 	var commitID, patch string
 	if err := issue.LoadPullRequest(ctx); err != nil {
 		return nil, fmt.Errorf("LoadPullRequest: %w", err)
@@ -191,7 +205,7 @@ func createCodeComment(ctx context.Context, doer *user_model.User, repo *repo_mo
 					Page:     1,
 				},
 			})
-			if err == nil && len(first) > 0 {
+			if err == nil && len(first) > 0 && first[0].StartLine == startLine {
 				commitID = first[0].CommitSHA
 				invalidated = first[0].Invalidated
 				patch = first[0].Patch
@@ -242,24 +256,31 @@ func createCodeComment(ctx context.Context, doer *user_model.User, repo *repo_mo
 			_ = writer.Close()
 		}()
 
-		patch, err = git.CutDiffAroundLine(reader, int64((&issues_model.Comment{Line: line}).UnsignedLine()), line < 0, setting.UI.CodeCommentLines)
+		// the patch of a multi-line comment should contain all the commented lines
+		codeComment := &issues_model.Comment{Line: line, StartLine: startLine}
+		numberOfLines := setting.UI.CodeCommentLines
+		if rangeLines := int(codeComment.UnsignedLine()-codeComment.UnsignedStartLine()) + 1; rangeLines > numberOfLines {
+			numberOfLines = rangeLines
+		}
+		patch, err = git.CutDiffAroundLine(reader, int64(codeComment.UnsignedLine()), line < 0, numberOfLines)
 		if err != nil {
 			log.Error("Error whilst generating patch: %v", err)
 			return nil, err
 		}
 	}
 	return issue_service.CreateComment(ctx, &issues_model.CreateCommentOptions{
-		Type:        issues_model.CommentTypeCode,
-		Doer:        doer,
-		Repo:        repo,
-		Issue:       issue,
-		Content:     content,
-		LineNum:     line,
-		TreePath:    treePath,
-		CommitSHA:   commitID,
-		ReviewID:    reviewID,
-		Patch:       patch,
-		Invalidated: invalidated,
+		Type:         issues_model.CommentTypeCode,
+		Doer:         doer,
+		Repo:         repo,
+		Issue:        issue,
+		Content:      content,
+		LineNum:      line,
+		StartLineNum: startLine,
+		TreePath:     treePath,
+		CommitSHA:    commitID,
+		ReviewID:     reviewID,
+		Patch:        patch,
+		Invalidated:  invalidated,
 	})
 }
 
@@ -388,3 +409,27 @@ func DismissReview(ctx context.Context, reviewID, repoID int64, message string,
 
 	return comment, err
 }
+
+// CanApplySuggestions returns true if the doer could commit the suggestions of the code comments to the head branch of the pull request
+func CanApplySuggestions(ctx context.Context, pr *issues_model.PullRequest, doer *user_model.User) (bool, error) {
+	if doer == nil || pr.HasMerged || pr.Flow != issues_model.PullRequestFlowGithub {
+		return false, nil
+	}
+	if err := pr.LoadIssue(ctx); err != nil {
+		return false, err
+	}
+	if pr.Issue.IsClosed {
+		return false, nil
+	}
+	if err := pr.LoadHeadRepo(ctx); err != nil {
+		return false, err
+	}
+	if pr.HeadRepo == nil || pr.HeadRepo.IsArchived {
+		return false, nil
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, pr.HeadRepo, doer)
+	if err != nil {
+		return false, err
+	}
+	return issues_model.CanMaintainerWriteToBranch(perm, pr.HeadBranch, doer), nil
+}
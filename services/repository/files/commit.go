@@ -53,6 +53,10 @@ func CreateCommitStatus(ctx context.Context, repo *repo_model.Repository, creato
 		}
 	}
 
+	if err := automerge.ProcessMergeQueuesByCommitID(ctx, sha, repo); err != nil {
+		return fmt.Errorf("ProcessMergeQueuesByCommitID[repo_id: %d, user_id: %d, sha: %s]: %w", repo.ID, creator.ID, sha, err)
+	}
+
 	return nil
 }
 
@@ -0,0 +1,248 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package files
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// suggestion represents the lines proposed by a code comment to replace the lines from startLine to endLine
+type suggestion struct {
+	commentID int64
+	startLine int
+	endLine   int
+	lines     []string
+}
+
+// ApplySuggestions applies the suggestions of the code comments to the head branch of the pull request in one commit
+func ApplySuggestions(ctx context.Context, doer *user_model.User, pr *issues_model.PullRequest, comments []*issues_model.Comment, message string) error {
+	if len(comments) == 0 {
+		return util.NewInvalidArgumentErrorf("no suggestions to apply")
+	}
+	if ok, err := pull_service.CanApplySuggestions(ctx, pr, doer); err != nil {
+		return err
+	} else if !ok {
+		return util.NewPermissionDeniedErrorf("not allowed to apply suggestions to the head branch %s", pr.HeadBranch)
+	}
+	if err := pr.LoadBaseRepo(ctx); err != nil {
+		return err
+	}
+
+	baseGitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer closer.Close()
+	headGitRepo := baseGitRepo
+	if pr.HeadRepoID != pr.BaseRepoID {
+		headGitRepo, err = git.OpenRepository(ctx, pr.HeadRepo.RepoPath())
+		if err != nil {
+			return err
+		}
+		defer headGitRepo.Close()
+	}
+	headCommit, err := headGitRepo.GetBranchCommit(pr.HeadBranch)
+	if err != nil {
+		return err
+	}
+
+	var treePaths []string
+	pathToSuggestions := make(map[string][]*suggestion)
+	for _, comment := range comments {
+		if comment.Type != issues_model.CommentTypeCode || comment.IssueID != pr.IssueID {
+			return util.NewInvalidArgumentErrorf("comment %d is not a code comment of the pull request", comment.ID)
+		}
+		if comment.Line <= 0 {
+			return util.NewInvalidArgumentErrorf("comment %d is not on the proposed changes", comment.ID)
+		}
+		if comment.Invalidated {
+			return util.NewInvalidArgumentErrorf("comment %d is outdated", comment.ID)
+		}
+		if err := comment.LoadReview(); err != nil {
+			return err
+		}
+		if comment.Review.Type == issues_model.ReviewTypePending {
+			return util.NewInvalidArgumentErrorf("comment %d belongs to a pending review", comment.ID)
+		}
+		lines, ok := comment.Suggestion()
+		if !ok {
+			return util.NewInvalidArgumentErrorf("comment %d has no suggestion", comment.ID)
+		}
+
+		s := &suggestion{
+			commentID: comment.ID,
+			startLine: int(comment.UnsignedStartLine()),
+			endLine:   int(comment.UnsignedLine()),
+			lines:     lines,
+		}
+		// the commented lines have to be unchanged since the review, the suggestion would be applied to the wrong lines otherwise
+		if comment.Review.CommitID != "" {
+			original, err := readFileLines(baseGitRepo, comment.Review.CommitID, comment.TreePath)
+			if err != nil {
+				return err
+			}
+			current, err := readFileLinesFromCommit(headCommit, comment.TreePath)
+			if err != nil {
+				return err
+			}
+			if !s.matches(original, current) {
+				return util.NewInvalidArgumentErrorf("comment %d is outdated", comment.ID)
+			}
+		}
+
+		if _, ok := pathToSuggestions[comment.TreePath]; !ok {
+			treePaths = append(treePaths, comment.TreePath)
+		}
+		pathToSuggestions[comment.TreePath] = append(pathToSuggestions[comment.TreePath], s)
+	}
+
+	files := make([]*ChangeRepoFile, 0, len(treePaths))
+	for _, treePath := range treePaths {
+		entry, err := headCommit.GetTreeEntryByPath(treePath)
+		if err != nil {
+			return err
+		}
+		content, err := readBlobContent(entry.Blob())
+		if err != nil {
+			return err
+		}
+		newContent, err := applySuggestionsToContent(content, pathToSuggestions[treePath])
+		if err != nil {
+			return fmt.Errorf("%s: %w", treePath, err)
+		}
+		files = append(files, &ChangeRepoFile{
+			Operation: "update",
+			TreePath:  treePath,
+			Content:   newContent,
+			SHA:       entry.ID.String(),
+		})
+	}
+
+	if message == "" {
+		if len(comments) == 1 {
+			message = "Apply suggestion from code review"
+		} else {
+			message = "Apply suggestions from code review"
+		}
+	}
+	_, err = ChangeRepoFiles(ctx, pr.HeadRepo, doer, &ChangeRepoFilesOptions{
+		LastCommitID: headCommit.ID.String(),
+		OldBranch:    pr.HeadBranch,
+		NewBranch:    pr.HeadBranch,
+		Message:      message,
+		Files:        files,
+	})
+	return err
+}
+
+func readBlobContent(blob *git.Blob) (string, error) {
+	reader, err := blob.DataAsync()
+	if err != nil {
+		return "", err
+	}
+	defer reader.Close()
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		return "", err
+	}
+	return string(content), nil
+}
+
+func readFileLines(gitRepo *git.Repository, commitID, treePath string) ([]string, error) {
+	commit, err := gitRepo.GetCommit(commitID)
+	if err != nil {
+		return nil, err
+	}
+	return readFileLinesFromCommit(commit, treePath)
+}
+
+func readFileLinesFromCommit(commit *git.Commit, treePath string) ([]string, error) {
+	entry, err := commit.GetTreeEntryByPath(treePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	content, err := readBlobContent(entry.Blob())
+	if err != nil {
+		return nil, err
+	}
+	lines, _ := splitLines(content)
+	return lines, nil
+}
+
+// matches returns true if the lines of the suggestion are the same in both the original and the current file
+func (s *suggestion) matches(original, current []string) bool {
+	if s.endLine > len(original) || s.endLine > len(current) {
+		return false
+	}
+	for i := s.startLine - 1; i < s.endLine; i++ {
+		if original[i] != current[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// splitLines splits the content into lines, the line feeds are removed but the carriage returns are kept
+func splitLines(content string) (lines []string, hasTrailingNewline bool) {
+	if content == "" {
+		return nil, false
+	}
+	hasTrailingNewline = strings.HasSuffix(content, "\n")
+	return strings.Split(strings.TrimSuffix(content, "\n"), "\n"), hasTrailingNewline
+}
+
+// applySuggestionsToContent replaces the lines of the content by the suggestions, which must not overlap
+func applySuggestionsToContent(content string, suggestions []*suggestion) (string, error) {
+	lines, hasTrailingNewline := splitLines(content)
+
+	sorted := make([]*suggestion, len(suggestions))
+	copy(sorted, suggestions)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].startLine < sorted[j].startLine
+	})
+	for i, s := range sorted {
+		if s.startLine < 1 || s.startLine > s.endLine || s.endLine > len(lines) {
+			return "", util.NewInvalidArgumentErrorf("lines %d-%d of comment %d are out of range", s.startLine, s.endLine, s.commentID)
+		}
+		if i > 0 && s.startLine <= sorted[i-1].endLine {
+			return "", util.NewInvalidArgumentErrorf("suggestions of comment %d and comment %d overlap", sorted[i-1].commentID, s.commentID)
+		}
+	}
+
+	// replace from the last one, so the line numbers of the previous ones are not affected
+	for i := len(sorted) - 1; i >= 0; i-- {
+		s := sorted[i]
+		replacement := make([]string, 0, len(s.lines))
+		keepCR := strings.HasSuffix(lines[s.startLine-1], "\r")
+		for _, line := range s.lines {
+			if keepCR {
+				line += "\r"
+			}
+			replacement = append(replacement, line)
+		}
+		lines = append(lines[:s.startLine-1], append(replacement, lines[s.endLine:]...)...)
+	}
+
+	if len(lines) == 0 {
+		return "", nil
+	}
+	newContent := strings.Join(lines, "\n")
+	if hasTrailingNewline {
+		newContent += "\n"
+	}
+	return newContent, nil
+}
@@ -0,0 +1,52 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package files
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplySuggestionsToContent(t *testing.T) {
+	content := "line1\nline2\nline3\nline4\nline5\n"
+
+	newContent, err := applySuggestionsToContent(content, []*suggestion{
+		{commentID: 1, startLine: 4, endLine: 5, lines: []string{"new4"}},
+		{commentID: 2, startLine: 1, endLine: 1, lines: []string{"new1", "new1.5"}},
+		{commentID: 3, startLine: 2, endLine: 3, lines: []string{}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "new1\nnew1.5\nnew4\n", newContent)
+
+	newContent, err = applySuggestionsToContent("line1\r\nline2", []*suggestion{
+		{commentID: 1, startLine: 2, endLine: 2, lines: []string{"new2", "new3"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "line1\r\nnew2\nnew3", newContent)
+
+	newContent, err = applySuggestionsToContent("line1\r\nline2\r\n", []*suggestion{
+		{commentID: 1, startLine: 1, endLine: 1, lines: []string{"new1"}},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "new1\r\nline2\r\n", newContent)
+
+	_, err = applySuggestionsToContent(content, []*suggestion{
+		{commentID: 1, startLine: 1, endLine: 3, lines: []string{"new"}},
+		{commentID: 2, startLine: 3, endLine: 4, lines: []string{"new"}},
+	})
+	assert.Error(t, err)
+
+	_, err = applySuggestionsToContent(content, []*suggestion{
+		{commentID: 1, startLine: 5, endLine: 6, lines: []string{"new"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestSuggestionMatches(t *testing.T) {
+	s := &suggestion{startLine: 2, endLine: 3}
+	assert.True(t, s.matches([]string{"a", "b", "c"}, []string{"x", "b", "c", "d"}))
+	assert.False(t, s.matches([]string{"a", "b", "c"}, []string{"a", "b", "x"}))
+	assert.False(t, s.matches([]string{"a", "b", "c"}, []string{"a", "b"}))
+}
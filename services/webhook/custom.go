@@ -0,0 +1,421 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/textproto"
+	"reflect"
+	"strings"
+	"text/template"
+	"text/template/parse"
+	"time"
+
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+)
+
+// customPayloadMaxSize is the maximum size of the body or of the headers rendered for a custom webhook
+const customPayloadMaxSize = 1 << 20
+
+const (
+	// customTemplateTimeout is the longest a template of a custom webhook may take to render
+	customTemplateTimeout = 5 * time.Second
+	// customTemplateMaxIterations is the maximum number of range iterations, nested ones included, of a rendering
+	customTemplateMaxIterations = 100000
+	// customTemplateTickFunc is called at every range iteration to stop renderings which exceed their budget
+	customTemplateTickFunc = "customTemplateTick"
+)
+
+// CustomDefaultContentType is the content type of the requests of a custom webhook which doesn't define one
+const CustomDefaultContentType = "application/json"
+
+type (
+	// CustomMeta contains the metadata for the webhook
+	CustomMeta struct {
+		ContentType     string `json:"content_type"`
+		HeadersTemplate string `json:"headers_template"`
+		BodyTemplate    string `json:"body_template"`
+	}
+
+	// CustomPayload is the request rendered from the templates of a custom webhook
+	CustomPayload struct {
+		ContentType string            `json:"content_type"`
+		Headers     map[string]string `json:"headers,omitempty"`
+		Body        string            `json:"body"`
+	}
+)
+
+// GetCustomHook returns custom metadata
+func GetCustomHook(w *webhook_model.Webhook) *CustomMeta {
+	s := &CustomMeta{}
+	if err := json.Unmarshal([]byte(w.Meta), s); err != nil {
+		log.Error("webhook.GetCustomHook(%d): %v", w.ID, err)
+	}
+	return s
+}
+
+// JSONPayload Marshals the CustomPayload to json, the body and the headers are sent as they are when the hook task is delivered
+func (c *CustomPayload) JSONPayload() ([]byte, error) {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return []byte{}, err
+	}
+	return data, nil
+}
+
+// GetCustomPayload renders the templates of a custom webhook against the payload of an event
+func GetCustomPayload(p api.Payloader, event webhook_module.HookEventType, meta string) (api.Payloader, error) {
+	custom := &CustomMeta{}
+	if err := json.Unmarshal([]byte(meta), custom); err != nil {
+		return nil, errors.New("GetCustomPayload meta json:" + err.Error())
+	}
+	return renderCustomPayload(custom, p, event)
+}
+
+// ValidateCustomMeta checks that the templates and the content type of a custom webhook can be used
+func ValidateCustomMeta(meta *CustomMeta) error {
+	if meta.ContentType != "" {
+		if _, _, err := mime.ParseMediaType(meta.ContentType); err != nil {
+			return util.NewInvalidArgumentErrorf("invalid content type %q: %v", meta.ContentType, err)
+		}
+	}
+	if _, err := parseCustomTemplate("headers", meta.HeadersTemplate, webhook_module.HookEventPush); err != nil {
+		return err
+	}
+	_, err := parseCustomTemplate("body", meta.BodyTemplate, webhook_module.HookEventPush)
+	return err
+}
+
+// PreviewCustomPayload renders the templates of a custom webhook against a sample push payload
+func PreviewCustomPayload(meta *CustomMeta) (*CustomPayload, error) {
+	return renderCustomPayload(meta, customSamplePayload(), webhook_module.HookEventPush)
+}
+
+func renderCustomPayload(meta *CustomMeta, p api.Payloader, event webhook_module.HookEventType) (*CustomPayload, error) {
+	payload := &CustomPayload{
+		ContentType: meta.ContentType,
+		Headers:     map[string]string{},
+	}
+	if payload.ContentType == "" {
+		payload.ContentType = CustomDefaultContentType
+	}
+
+	headers, err := renderCustomTemplate("headers", meta.HeadersTemplate, p, event)
+	if err != nil {
+		return nil, err
+	}
+	scanner := bufio.NewScanner(strings.NewReader(headers))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(line, ":")
+		name = strings.TrimSpace(name)
+		if !ok || name == "" || strings.ContainsAny(name, " \t") {
+			return nil, util.NewInvalidArgumentErrorf("invalid header line %q, headers must be written as \"Name: value\"", line)
+		}
+		payload.Headers[textproto.CanonicalMIMEHeaderKey(name)] = strings.TrimSpace(value)
+	}
+
+	payload.Body, err = renderCustomTemplate("body", meta.BodyTemplate, p, event)
+	if err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+func renderCustomTemplate(name, text string, data any, event webhook_module.HookEventType) (string, error) {
+	tmpl, err := parseCustomTemplate(name, text, event)
+	if err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), customTemplateTimeout)
+	defer cancel()
+	budget := &customTemplateBudget{ctx: ctx}
+	funcs := template.FuncMap{customTemplateTickFunc: budget.tick}
+	tick, err := template.New("tick").Funcs(funcs).Parse("{{" + customTemplateTickFunc + "}}")
+	if err != nil {
+		return "", err
+	}
+	tmpl.Funcs(funcs)
+	if tmpl.Tree != nil {
+		addCustomTemplateTicks(tmpl.Tree.Root, tick.Tree.Root.Nodes[0])
+	}
+
+	w := &customTemplateWriter{}
+	if err := tmpl.Execute(w, data); err != nil {
+		return "", util.NewInvalidArgumentErrorf("unable to render the %s template: %v", name, err)
+	}
+	return w.String(), nil
+}
+
+// customTemplateBudget bounds the time and the number of range iterations of a rendering, as a template whose
+// ranges don't write anything isn't stopped by the size limit of its output
+type customTemplateBudget struct {
+	ctx        context.Context
+	iterations int
+}
+
+func (b *customTemplateBudget) tick() (string, error) {
+	if b.ctx.Err() != nil {
+		return "", fmt.Errorf("the rendering takes longer than %v", customTemplateTimeout)
+	}
+	b.iterations++
+	if b.iterations > customTemplateMaxIterations {
+		return "", fmt.Errorf("the rendering iterates more than %d times", customTemplateMaxIterations)
+	}
+	return "", nil
+}
+
+// addCustomTemplateTicks makes every range of a template call the tick function at the start of each iteration
+func addCustomTemplateTicks(node parse.Node, tick parse.Node) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			addCustomTemplateTicks(child, tick)
+		}
+	case *parse.IfNode:
+		addCustomTemplateTicks(n.List, tick)
+		addCustomTemplateTicks(n.ElseList, tick)
+	case *parse.WithNode:
+		addCustomTemplateTicks(n.List, tick)
+		addCustomTemplateTicks(n.ElseList, tick)
+	case *parse.RangeNode:
+		addCustomTemplateTicks(n.List, tick)
+		addCustomTemplateTicks(n.ElseList, tick)
+		n.List.Nodes = append([]parse.Node{tick}, n.List.Nodes...)
+	}
+}
+
+// customTemplateWriter stops the rendering of a template whose output gets too large
+type customTemplateWriter struct {
+	strings.Builder
+}
+
+func (w *customTemplateWriter) Write(p []byte) (int, error) {
+	if w.Len()+len(p) > customPayloadMaxSize {
+		return 0, fmt.Errorf("the output is larger than %d bytes", customPayloadMaxSize)
+	}
+	return w.Builder.Write(p)
+}
+
+// parseCustomTemplate parses a user supplied template, only the built-in functions of text/template (the ones
+// which build strings being replaced by customTemplateBuiltins) and the customTemplateFuncs are available, and
+// the constructs whose execution time is not bounded by the size of the payload (calling other templates,
+// ranging over numbers) are rejected.
+func parseCustomTemplate(name, text string, event webhook_module.HookEventType) (*template.Template, error) {
+	tmpl, err := template.New(name).Option("missingkey=zero").Funcs(customTemplateBuiltins()).Funcs(customTemplateFuncs(event)).Parse(text)
+	if err != nil {
+		return nil, util.NewInvalidArgumentErrorf("invalid %s template: %v", name, err)
+	}
+	if len(tmpl.Templates()) > 1 {
+		return nil, util.NewInvalidArgumentErrorf("invalid %s template: defining templates is not allowed", name)
+	}
+	if tmpl.Tree != nil {
+		if err := checkCustomTemplateNode(tmpl.Tree.Root); err != nil {
+			return nil, util.NewInvalidArgumentErrorf("invalid %s template: %v", name, err)
+		}
+	}
+	return tmpl, nil
+}
+
+func checkCustomTemplateNode(node parse.Node) error {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return nil
+		}
+		for _, child := range n.Nodes {
+			if err := checkCustomTemplateNode(child); err != nil {
+				return err
+			}
+		}
+	case *parse.ActionNode:
+		return checkCustomTemplateNode(n.Pipe)
+	case *parse.IfNode:
+		return checkCustomTemplateBranch(&n.BranchNode)
+	case *parse.WithNode:
+		return checkCustomTemplateBranch(&n.BranchNode)
+	case *parse.RangeNode:
+		if isNumberPipe(n.Pipe) {
+			return errors.New("ranging over a number is not allowed")
+		}
+		return checkCustomTemplateBranch(&n.BranchNode)
+	case *parse.TemplateNode:
+		return errors.New("calling templates is not allowed")
+	case *parse.PipeNode:
+		if n == nil {
+			return nil
+		}
+		if len(n.Decl) > 0 && isNumberPipe(n) {
+			return errors.New("assigning a number to a variable is not allowed")
+		}
+		for _, cmd := range n.Cmds {
+			for _, arg := range cmd.Args {
+				if err := checkCustomTemplateNode(arg); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func checkCustomTemplateBranch(n *parse.BranchNode) error {
+	if err := checkCustomTemplateNode(n.Pipe); err != nil {
+		return err
+	}
+	if err := checkCustomTemplateNode(n.List); err != nil {
+		return err
+	}
+	return checkCustomTemplateNode(n.ElseList)
+}
+
+func isNumberPipe(pipe *parse.PipeNode) bool {
+	if pipe == nil || len(pipe.Cmds) == 0 {
+		return false
+	}
+	args := pipe.Cmds[len(pipe.Cmds)-1].Args
+	if len(args) != 1 {
+		return false
+	}
+	switch arg := args[0].(type) {
+	case *parse.NumberNode:
+		return true
+	case *parse.PipeNode:
+		return isNumberPipe(arg)
+	}
+	return false
+}
+
+// customTemplateFuncs returns the functions available in the templates of a custom webhook,
+// the value they operate on is their last argument so that they can be used in pipelines.
+func customTemplateFuncs(event webhook_module.HookEventType) template.FuncMap {
+	return template.FuncMap{
+		"event": func() string {
+			return string(event)
+		},
+		"toJSON": customToJSON,
+		"upper":  customCaseFunc("upper", strings.ToUpper),
+		"lower":  customCaseFunc("lower", strings.ToLower),
+		"trim":   strings.TrimSpace,
+		"trimPrefix": func(prefix, s string) string {
+			return strings.TrimPrefix(s, prefix)
+		},
+		"trimSuffix": func(suffix, s string) string {
+			return strings.TrimSuffix(s, suffix)
+		},
+		"replace": customReplace,
+		"contains": func(substr, s string) bool {
+			return strings.Contains(s, substr)
+		},
+		"hasPrefix": func(prefix, s string) bool {
+			return strings.HasPrefix(s, prefix)
+		},
+		"hasSuffix": func(suffix, s string) bool {
+			return strings.HasSuffix(s, suffix)
+		},
+		"split": customSplit,
+		"join":  customJoin,
+		"firstLine": func(s string) string {
+			first, _, _ := strings.Cut(s, "\n")
+			return first
+		},
+		"truncate": func(length int, s string) string {
+			return base.TruncateString(s, length)
+		},
+		"shortSHA": base.ShortSha,
+		"default": func(def, v any) any {
+			if rv := reflect.ValueOf(v); !rv.IsValid() || rv.IsZero() {
+				return def
+			}
+			return v
+		},
+		"date": func(layout string, t time.Time) string {
+			return t.UTC().Format(layout)
+		},
+	}
+}
+
+// customSamplePayload returns the push payload the templates of a custom webhook are previewed against
+func customSamplePayload() *api.PushPayload {
+	when := time.Date(2023, 1, 2, 15, 4, 5, 0, time.UTC)
+	user := &api.User{
+		ID:        1,
+		UserName:  "gitea",
+		FullName:  "Gitea",
+		Email:     "gitea@example.com",
+		AvatarURL: setting.AppURL + "assets/img/avatar_default.png",
+	}
+	repo := &api.Repository{
+		ID:            1,
+		Owner:         user,
+		Name:          "example",
+		FullName:      "gitea/example",
+		HTMLURL:       setting.AppURL + "gitea/example",
+		CloneURL:      setting.AppURL + "gitea/example.git",
+		DefaultBranch: "main",
+	}
+	commit := &api.PayloadCommit{
+		ID:        "2020558fe2e34debb818a514715839cabd25e778",
+		Message:   "Fix the documentation\n\nThe installation steps were outdated.",
+		URL:       setting.AppURL + "gitea/example/commit/2020558fe2e34debb818a514715839cabd25e778",
+		Author:    &api.PayloadUser{Name: user.FullName, Email: user.Email, UserName: user.UserName},
+		Committer: &api.PayloadUser{Name: user.FullName, Email: user.Email, UserName: user.UserName},
+		Timestamp: when,
+		Modified:  []string{"README.md"},
+	}
+	return &api.PushPayload{
+		Ref:          git.BranchPrefix + "main",
+		Before:       "2020558fe2e34debb818a514715839cabd25e777",
+		After:        commit.ID,
+		CompareURL:   setting.AppURL + "gitea/example/compare/2020558fe2e34debb818a514715839cabd25e777...2020558fe2e34debb818a514715839cabd25e778",
+		Commits:      []*api.PayloadCommit{commit},
+		TotalCommits: 1,
+		HeadCommit:   commit,
+		Repo:         repo,
+		Pusher:       user,
+		Sender:       user,
+	}
+}
+
+// newCustomRequest creates the request of a hook task of a custom webhook, it returns the request and its body
+func newCustomRequest(w *webhook_model.Webhook, t *webhook_model.HookTask) (*http.Request, string, error) {
+	payload := &CustomPayload{}
+	if err := json.Unmarshal([]byte(t.PayloadContent), payload); err != nil {
+		return nil, "", fmt.Errorf("unable to deliver webhook task[%d] as its custom payload is invalid: %w", t.ID, err)
+	}
+
+	method := w.HTTPMethod
+	if method == "" {
+		method = http.MethodPost
+	}
+	req, err := http.NewRequest(method, w.URL, strings.NewReader(payload.Body))
+	if err != nil {
+		return nil, "", fmt.Errorf("unable to deliver webhook task[%d] as unable to create HTTP request for webhook url %s: %w", t.ID, w.URL, err)
+	}
+	req.Header.Set("Content-Type", payload.ContentType)
+	for name, value := range payload.Headers {
+		req.Header.Set(name, value)
+	}
+	return req, payload.Body, nil
+}
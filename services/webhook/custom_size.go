@@ -0,0 +1,355 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"fmt"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+// The functions of the templates of a custom webhook check the length of their result before building it:
+// a single call could otherwise allocate far more memory than the size limit of the output, as the output
+// writer only sees what is eventually written.
+
+const (
+	// customNumberMaxSize is the longest a number can be printed (a float64 with %f)
+	customNumberMaxSize = 330
+	// customFormatMaxWidth is the largest width or precision fmt accepts in a format
+	customFormatMaxWidth = 1e6
+	// customValueMaxDepth is the deepest a value is walked to estimate its size
+	customValueMaxDepth = 32
+)
+
+func checkCustomTemplateSize(name string, size int64) error {
+	if size > customPayloadMaxSize {
+		return fmt.Errorf("the result of %s would be larger than %d bytes", name, customPayloadMaxSize)
+	}
+	return nil
+}
+
+func customReplace(old, replacement, s string) (string, error) {
+	var count int64
+	if old == "" {
+		count = int64(utf8.RuneCountInString(s)) + 1
+	} else {
+		count = int64(strings.Count(s, old))
+	}
+	if err := checkCustomTemplateSize("replace", int64(len(s))+count*(int64(len(replacement))-int64(len(old)))); err != nil {
+		return "", err
+	}
+	return strings.ReplaceAll(s, old, replacement), nil
+}
+
+func customSplit(sep, s string) ([]string, error) {
+	// the elements share the memory of s, the slice holds at most one element per byte of s
+	if err := checkCustomTemplateSize("split", int64(len(s))); err != nil {
+		return nil, err
+	}
+	return strings.Split(s, sep), nil
+}
+
+func customJoin(sep string, elems []string) (string, error) {
+	if len(elems) == 0 {
+		return "", nil
+	}
+	size := int64(len(sep)) * int64(len(elems)-1)
+	for _, elem := range elems {
+		size += int64(len(elem))
+	}
+	if err := checkCustomTemplateSize("join", size); err != nil {
+		return "", err
+	}
+	return strings.Join(elems, sep), nil
+}
+
+func customCaseFunc(name string, fn func(string) string) func(string) (string, error) {
+	return func(s string) (string, error) {
+		// changing the case of a rune makes its encoding at most one byte longer than its two bytes
+		if err := checkCustomTemplateSize(name, int64(len(s))*3/2); err != nil {
+			return "", err
+		}
+		return fn(s), nil
+	}
+}
+
+func customToJSON(v any) (string, error) {
+	if err := checkCustomTemplateSize("toJSON", customValueSize(reflect.ValueOf(v), 0)); err != nil {
+		return "", err
+	}
+	data, err := json.Marshal(v)
+	return string(data), err
+}
+
+func customPrint(args ...any) (string, error) {
+	if err := checkCustomTemplateSize("print", customArgsSize(args)); err != nil {
+		return "", err
+	}
+	return fmt.Sprint(args...), nil
+}
+
+func customPrintln(args ...any) (string, error) {
+	if err := checkCustomTemplateSize("println", customArgsSize(args)+1); err != nil {
+		return "", err
+	}
+	return fmt.Sprintln(args...), nil
+}
+
+func customPrintf(format string, args ...any) (string, error) {
+	if err := checkCustomTemplateSize("printf", customFormatSize(format, args)); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(format, args...), nil
+}
+
+// customEscapeFunc replaces a built-in escaping function of text/template, escapedSize returns how long
+// a rune of its input gets once escaped
+func customEscapeFunc(name string, escape func(string) string, escapedSize func(r rune) int) func(...any) (string, error) {
+	return func(args ...any) (string, error) {
+		if err := checkCustomTemplateSize(name, customArgsSize(args)); err != nil {
+			return "", err
+		}
+		s, ok := "", false
+		if len(args) == 1 {
+			s, ok = args[0].(string)
+		}
+		if !ok {
+			s = fmt.Sprint(args...)
+		}
+		var size int64
+		for _, r := range s {
+			size += int64(escapedSize(r))
+		}
+		if err := checkCustomTemplateSize(name, size); err != nil {
+			return "", err
+		}
+		return escape(s), nil
+	}
+}
+
+func htmlEscapedSize(r rune) int {
+	switch r {
+	case '"', '\'', '&', '<', '>':
+		return 5 // &#34; &#39; &amp; &lt; &gt;
+	}
+	// an invalid byte is counted as the replacement rune
+	return utf8.RuneLen(r)
+}
+
+func jsEscapedSize(r rune) int {
+	switch {
+	case r == '\\' || r == '\'' || r == '"':
+		return 2
+	case r < ' ' || r == '<' || r == '>' || r == '&' || r == '=':
+		return 6 // \u00XX
+	case r >= utf8.RuneSelf && !unicode.IsPrint(r):
+		return 6
+	}
+	return utf8.RuneLen(r)
+}
+
+func urlQueryEscapedSize(r rune) int {
+	return utf8.RuneLen(r) * 3 // %XX
+}
+
+func customArgsSize(args []any) int64 {
+	var size int64
+	for _, arg := range args {
+		size += customValueSize(reflect.ValueOf(arg), 0) + 1
+		if size > customPayloadMaxSize {
+			break
+		}
+	}
+	return size
+}
+
+// customFormatSize returns an upper bound of the length of a formatted string: every verb prints at most
+// its width, its precision and the value of its argument
+func customFormatSize(format string, args []any) int64 {
+	used := make([]bool, len(args))
+	argSize := func(i int) int64 {
+		if i < 0 || i >= len(args) {
+			return 32 // %!d(BADINDEX) or %!d(MISSING)
+		}
+		used[i] = true
+		return customValueSize(reflect.ValueOf(args[i]), 0)
+	}
+
+	size := int64(len(format))
+	argNum, i := 0, 0
+	// an explicit argument index [n] selects the argument of the next width, precision or verb
+	argIndex := func() {
+		if i+2 < len(format) && format[i] == '[' {
+			if end := strings.IndexByte(format[i:], ']'); end > 0 {
+				if n, err := strconv.Atoi(format[i+1 : i+end]); err == nil {
+					argNum = n - 1
+				}
+				i += end + 1
+			}
+		}
+	}
+	// a width or a precision is either a number or taken from an argument
+	number := func() int64 {
+		argIndex()
+		if i < len(format) && format[i] == '*' {
+			i++
+			if argNum >= 0 && argNum < len(args) {
+				used[argNum] = true
+			}
+			argNum++
+			return customFormatMaxWidth
+		}
+		start := i
+		for i < len(format) && format[i] >= '0' && format[i] <= '9' {
+			i++
+		}
+		if start == i {
+			return 0
+		}
+		if n, err := strconv.ParseInt(format[start:i], 10, 64); err == nil && n < customFormatMaxWidth {
+			return n
+		}
+		return customFormatMaxWidth
+	}
+
+	for ; i < len(format) && size <= customPayloadMaxSize; i++ {
+		if format[i] != '%' {
+			continue
+		}
+		i++
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+		size += number()
+		if i < len(format) && format[i] == '.' {
+			i++
+			size += number()
+		}
+		argIndex()
+		if i >= len(format) {
+			size += 16 // %!(NOVERB)
+			break
+		}
+		verb, n := utf8.DecodeRuneInString(format[i:])
+		i += n - 1
+		if verb == '%' {
+			continue
+		}
+		value := argSize(argNum)
+		if verb == 'x' || verb == 'X' {
+			value *= 3 // "% x" prints every byte as two digits and a space
+		}
+		// a bad verb prints the type of the argument next to its value
+		size += value + 64
+		argNum++
+	}
+	for j, arg := range args {
+		if !used[j] && size <= customPayloadMaxSize {
+			size += customValueSize(reflect.ValueOf(arg), 0) + 64 // %!(EXTRA type=value)
+		}
+	}
+	return size
+}
+
+// customValueSize returns an upper bound of the length of a value once printed with fmt or encoded in JSON,
+// values which are too large or too deep to be walked are reported as larger than the size limit
+func customValueSize(v reflect.Value, depth int) int64 {
+	if depth > customValueMaxDepth {
+		return customPayloadMaxSize + 1
+	}
+	if !v.IsValid() {
+		return 5 // <nil>
+	}
+	if v.Type() == reflect.TypeOf(time.Time{}) {
+		return 64
+	}
+	if v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return 5
+		}
+		return int64(len(v.Type().String())) + 24 + customValueSize(v.Elem(), depth+1)
+	}
+	if v.CanInterface() {
+		switch s := v.Interface().(type) {
+		case fmt.Stringer:
+			return customStringSize(s.String())
+		case error:
+			return customStringSize(s.Error())
+		}
+	}
+
+	size := int64(len(v.Type().String())) + 2
+	switch v.Kind() {
+	case reflect.String:
+		return customStringSize(v.String())
+	case reflect.Bool:
+		return 5
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return customNumberMaxSize
+	case reflect.Complex64, reflect.Complex128:
+		return 2*customNumberMaxSize + 3
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			// bytes are printed as a list of numbers, as a string or in base64
+			return size + int64(v.Len())*4
+		}
+		for i := 0; i < v.Len() && size <= customPayloadMaxSize; i++ {
+			size += customValueSize(v.Index(i), depth+1) + 1
+		}
+	case reflect.Map:
+		iter := v.MapRange()
+		for iter.Next() && size <= customPayloadMaxSize {
+			size += customValueSize(iter.Key(), depth+1) + customValueSize(iter.Value(), depth+1) + 4
+		}
+	case reflect.Struct:
+		t := v.Type()
+		for i := 0; i < v.NumField() && size <= customPayloadMaxSize; i++ {
+			field := t.Field(i)
+			size += int64(len(field.Name)+len(field.Tag)) + 4 + customValueSize(v.Field(i), depth+1)
+		}
+	default:
+		size += 32 // the address of a channel or of a function
+	}
+	return size
+}
+
+// customStringSize returns an upper bound of the length of a string once quoted by fmt or by JSON
+func customStringSize(s string) int64 {
+	size := int64(2)
+	for _, r := range s {
+		switch {
+		case r == utf8.RuneError || r < ' ' || r == '<' || r == '>' || r == '&':
+			size += 6
+		case r == '"' || r == '\\':
+			size += 2
+		case r >= utf8.RuneSelf && !unicode.IsPrint(r):
+			size += 10
+		default:
+			size += int64(utf8.RuneLen(r))
+		}
+	}
+	return size
+}
+
+// customTemplateBuiltins replaces the built-in functions of text/template which can build large strings
+func customTemplateBuiltins() template.FuncMap {
+	return template.FuncMap{
+		"print":    customPrint,
+		"printf":   customPrintf,
+		"println":  customPrintln,
+		"html":     customEscapeFunc("html", template.HTMLEscapeString, htmlEscapedSize),
+		"js":       customEscapeFunc("js", template.JSEscapeString, jsEscapedSize),
+		"urlquery": customEscapeFunc("urlquery", url.QueryEscape, urlQueryEscapedSize),
+	}
+}
@@ -0,0 +1,141 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/json"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCustomPayload(t *testing.T) {
+	meta := &CustomMeta{
+		ContentType:     "text/plain",
+		HeadersTemplate: "x-event: {{event}}\n\nX-Repo: {{.Repo.FullName | upper}}",
+		BodyTemplate:    `{{.Pusher.UserName}} pushed {{len .Commits}} commits: {{range .Commits}}{{shortSHA .ID}} {{firstLine .Message | toJSON}} {{end}}{{.CompareURL | default "none"}}`,
+	}
+	data, err := json.Marshal(meta)
+	require.NoError(t, err)
+
+	pl, err := GetCustomPayload(pushTestPayload(), webhook_module.HookEventPush, string(data))
+	require.NoError(t, err)
+	require.IsType(t, &CustomPayload{}, pl)
+
+	payload := pl.(*CustomPayload)
+	assert.Equal(t, "text/plain", payload.ContentType)
+	assert.Equal(t, map[string]string{"X-Event": "push", "X-Repo": "TEST/REPO"}, payload.Headers)
+	assert.Equal(t, `user1 pushed 2 commits: 2020558fe2 "commit message" 2020558fe2 "commit message" none`, payload.Body)
+
+	preview, err := PreviewCustomPayload(&CustomMeta{BodyTemplate: "{{.Repo.FullName}}"})
+	require.NoError(t, err)
+	assert.Equal(t, CustomDefaultContentType, preview.ContentType)
+	assert.Equal(t, "gitea/example", preview.Body)
+}
+
+func TestValidateCustomMeta(t *testing.T) {
+	assert.NoError(t, ValidateCustomMeta(&CustomMeta{BodyTemplate: `{{if contains "x" .Ref}}{{.Ref}}{{end}}`}))
+
+	for _, meta := range []*CustomMeta{
+		{ContentType: "not a mime type"},
+		{BodyTemplate: "{{.Ref"},
+		{BodyTemplate: "{{unknownFunc .Ref}}"},
+		{BodyTemplate: `{{define "x"}}{{end}}`},
+		{BodyTemplate: `{{template "x"}}`},
+		{BodyTemplate: "{{range 1000000000}}x{{end}}"},
+		{HeadersTemplate: "{{$n := 1000000000}}"},
+	} {
+		assert.Error(t, ValidateCustomMeta(meta), "%#v", meta)
+	}
+
+	_, err := PreviewCustomPayload(&CustomMeta{HeadersTemplate: "no header"})
+	assert.Error(t, err)
+	_, err = PreviewCustomPayload(&CustomMeta{BodyTemplate: "{{.Unknown}}"})
+	assert.Error(t, err)
+
+	// nested ranges which write nothing are stopped by the iteration budget
+	_, err = PreviewCustomPayload(&CustomMeta{BodyTemplate: `{{$s := split "" .CompareURL}}{{range $s}}{{range $s}}{{range $s}}{{end}}{{end}}{{end}}`})
+	assert.ErrorContains(t, err, "iterates more than")
+}
+
+func TestCustomTemplateSizeLimits(t *testing.T) {
+	preview, err := PreviewCustomPayload(&CustomMeta{BodyTemplate: `{{printf "%s-%05d" .Repo.Name 42}} {{html "<a>"}} {{replace "e" "E" .Repo.Name}} {{join "," (split "/" .Repo.FullName)}}`})
+	require.NoError(t, err)
+	assert.Equal(t, "example-00042 &lt;a&gt; ExamplE gitea,example", preview.Body)
+
+	quadratic := `{{$x := "ab"}}` + strings.Repeat(`{{$x = replace "" $x $x}}`, 20) + `{{len $x}}`
+	for _, body := range []string{
+		`{{$x := printf "%01000000d" 0}}{{$y := replace "0" $x $x}}`,
+		quadratic,
+		`{{$x := printf "%0500000d" 0}}{{printf "%[1]s%[1]s%[1]s" $x}}`,
+		`{{printf "%01000000d%01000000d" 0 0}}`,
+		`{{$x := printf "%0500000d" 0}}{{join $x (split "" "abcd")}}`,
+		`{{$x := printf "%01000000d" 0}}{{toJSON (split "" $x)}}`,
+		`{{$x := printf "%0500000d" 0}}{{html (replace "0" "<" $x)}}`,
+		`{{$x := printf "%0500000d" 0}}{{print $x $x $x}}`,
+	} {
+		_, err := PreviewCustomPayload(&CustomMeta{BodyTemplate: body})
+		assert.ErrorContains(t, err, "would be larger than", body)
+	}
+}
+
+func TestWebhookDeliverCustom(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	done := make(chan struct{}, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPut, r.Method)
+		assert.Equal(t, "text/plain", r.Header.Get("Content-Type"))
+		assert.Equal(t, "test/repo", r.Header.Get("X-Repo"))
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+		assert.Equal(t, "push to refs/heads/test", string(body))
+		w.WriteHeader(http.StatusOK)
+		done <- struct{}{}
+	}))
+	t.Cleanup(s.Close)
+
+	meta, err := json.Marshal(&CustomMeta{
+		ContentType:     "text/plain",
+		HeadersTemplate: "X-Repo: {{.Repo.FullName}}",
+		BodyTemplate:    "{{event}} to {{.Ref}}",
+	})
+	require.NoError(t, err)
+	hook := &webhook_model.Webhook{
+		RepoID:      3,
+		URL:         s.URL + "/webhook",
+		HTTPMethod:  http.MethodPut,
+		ContentType: webhook_model.ContentTypeJSON,
+		IsActive:    true,
+		Type:        webhook_module.CUSTOM,
+		Meta:        string(meta),
+	}
+	assert.NoError(t, webhook_model.CreateWebhook(db.DefaultContext, hook))
+
+	payload, err := GetCustomPayload(pushTestPayload(), webhook_module.HookEventPush, hook.Meta)
+	require.NoError(t, err)
+	hookTask, err := webhook_model.CreateHookTask(db.DefaultContext, &webhook_model.HookTask{HookID: hook.ID, EventType: webhook_module.HookEventPush, Payloader: payload})
+	require.NoError(t, err)
+
+	assert.NoError(t, Deliver(context.Background(), hookTask))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waited to long for request to happen")
+	}
+
+	assert.True(t, hookTask.IsSucceed)
+}
@@ -13,6 +13,7 @@ import (
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -50,6 +51,14 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 
 	t.IsDelivered = true
 
+	if w.Type == webhook_module.CUSTOM {
+		req, payloadContent, err := newCustomRequest(w, t)
+		if err != nil {
+			return err
+		}
+		return deliverRequest(ctx, w, t, req, payloadContent)
+	}
+
 	var req *http.Request
 
 	switch w.HTTPMethod {
@@ -108,12 +117,17 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		return fmt.Errorf("invalid http method for webhook task[%d] in webhook %s: %v", t.ID, w.URL, w.HTTPMethod)
 	}
 
+	return deliverRequest(ctx, w, t, req, t.PayloadContent)
+}
+
+// deliverRequest signs and sends the request of a hook task, the payload content is the body the signatures are computed over
+func deliverRequest(ctx context.Context, w *webhook_model.Webhook, t *webhook_model.HookTask, req *http.Request, payloadContent string) (err error) {
 	var signatureSHA1 string
 	var signatureSHA256 string
 	if len(w.Secret) > 0 {
 		sig1 := hmac.New(sha1.New, []byte(w.Secret))
 		sig256 := hmac.New(sha256.New, []byte(w.Secret))
-		_, err = io.MultiWriter(sig1, sig256).Write([]byte(t.PayloadContent))
+		_, err = io.MultiWriter(sig1, sig256).Write([]byte(payloadContent))
 		if err != nil {
 			log.Error("prepareWebhooks.sigWrite: %v", err)
 		}
@@ -137,6 +151,13 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	req.Header["X-GitHub-Event"] = []string{event}
 	req.Header["X-GitHub-Event-Type"] = []string{eventType}
 
+	// Signatures covering a timestamp, made with every secret active during a rotation
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Add("X-Gitea-Timestamp", timestamp)
+	if secrets := w.SigningSecrets(); len(secrets) > 0 {
+		req.Header.Add("X-Gitea-Signature-Timestamped", timestampedSignatures(secrets, timestamp, payloadContent))
+	}
+
 	// Add Authorization Header
 	authorization, err := w.HeaderAuthorization()
 	if err != nil {
@@ -147,6 +168,12 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		req.Header["Authorization"] = []string{authorization}
 	}
 
+	if w.HTTPSignature {
+		if err := signHTTPRequest(ctx, req); err != nil {
+			return fmt.Errorf("unable to deliver webhook task[%d] as unable to sign the request: %w", t.ID, err)
+		}
+	}
+
 	// Record delivery information.
 	t.RequestInfo = &webhook_model.HookRequest{
 		URL:        req.URL.String(),
@@ -174,9 +201,14 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		return nil
 	}
 
+	// A failed delivery is retried unless the receiver answered with a status which will not change
+	retryable := true
+	var resp *http.Response
+
 	// All code from this point will update the hook task
 	defer func() {
 		t.Delivered = timeutil.TimeStampNanoNow()
+		attempted := w.IsActive && !setting.DisableWebhooks
 		if t.IsSucceed {
 			log.Trace("Hook delivered: %s", t.UUID)
 		} else if !w.IsActive {
@@ -185,6 +217,17 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 			log.Trace("Hook delivery failed: %s", t.UUID)
 		}
 
+		if !t.IsSucceed && attempted {
+			if retryable && t.Attempt <= setting.Webhook.MaxRetries {
+				if err := scheduleHookTaskRetry(ctx, t, retryDelay(t.Attempt, resp)); err != nil {
+					log.Error("Unable to schedule the retry of webhook task[%d]: %v", t.ID, err)
+					t.IsDeadLetter = true
+				}
+			} else {
+				t.IsDeadLetter = true
+			}
+		}
+
 		if err := webhook_model.UpdateHookTask(t); err != nil {
 			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
 		}
@@ -199,6 +242,12 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 			log.Error("UpdateWebhookLastStatus: %v", err)
 			return
 		}
+
+		if attempted {
+			if err := updateWebhookFailureCount(ctx, w, t); err != nil {
+				log.Error("updateWebhookFailureCount: %v", err)
+			}
+		}
 	}()
 
 	if setting.DisableWebhooks {
@@ -210,7 +259,7 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		return nil
 	}
 
-	resp, err := webhookHTTPClient.Do(req.WithContext(ctx))
+	resp, err = webhookHTTPClient.Do(req.WithContext(ctx))
 	if err != nil {
 		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
 		return fmt.Errorf("unable to deliver webhook task[%d] in %s due to error in http client: %w", t.ID, w.URL, err)
@@ -219,6 +268,7 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 
 	// Status code is 20x can be seen as succeed.
 	t.IsSucceed = resp.StatusCode/100 == 2
+	retryable = isRetryableStatus(resp.StatusCode)
 	t.ResponseInfo.Status = resp.StatusCode
 	for k, vals := range resp.Header {
 		t.ResponseInfo.Headers[k] = strings.Join(vals, ",")
@@ -289,7 +339,12 @@ func Init() error {
 	}
 	go graceful.GetManager().RunWithCancel(hookQueue)
 
+	// retries becoming due from now on are pushed by the poller, the others by populateWebhookSendingQueue
+	startTime := timeutil.TimeStampNow()
 	go graceful.GetManager().RunWithShutdownContext(populateWebhookSendingQueue)
+	go graceful.GetManager().RunWithShutdownContext(func(ctx context.Context) {
+		pollWebhookRetries(ctx, startTime)
+	})
 
 	return nil
 }
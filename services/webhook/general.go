@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"html"
 	"net/url"
+	"strconv"
 	"strings"
 
 	webhook_model "code.gitea.io/gitea/models/webhook"
@@ -230,8 +231,9 @@ func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFo
 // This function is not part of the convert package to prevent an import cycle
 func ToHook(repoLink string, w *webhook_model.Webhook) (*api.Hook, error) {
 	config := map[string]string{
-		"url":          w.URL,
-		"content_type": w.ContentType.Name(),
+		"url":            w.URL,
+		"content_type":   w.ContentType.Name(),
+		"http_signature": strconv.FormatBool(w.HTTPSignature),
 	}
 	if w.Type == webhook_module.SLACK {
 		s := GetSlackHook(w)
@@ -240,6 +242,13 @@ func ToHook(repoLink string, w *webhook_model.Webhook) (*api.Hook, error) {
 		config["icon_url"] = s.IconURL
 		config["color"] = s.Color
 	}
+	if w.Type == webhook_module.CUSTOM {
+		c := GetCustomHook(w)
+		config["http_method"] = w.HTTPMethod
+		config["custom_content_type"] = c.ContentType
+		config["headers_template"] = c.HeadersTemplate
+		config["body_template"] = c.BodyTemplate
+	}
 
 	authorizationHeader, err := w.HeaderAuthorization()
 	if err != nil {
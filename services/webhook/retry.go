@@ -0,0 +1,198 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// retryPollInterval is the interval at which the retries which became due are pushed to the sending queue
+const retryPollInterval = 10 * time.Second
+
+// isRetryableStatus returns whether a delivery which got a response with this status could succeed later
+func isRetryableStatus(status int) bool {
+	return status == http.StatusRequestTimeout || status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryDelay returns the delay before retrying a delivery which failed at the given attempt,
+// the delay doubles on each attempt and half of it is random jitter
+func retryDelay(attempt int, resp *http.Response) time.Duration {
+	delay := setting.Webhook.RetryInitialInterval
+	for i := 1; i < attempt && delay < setting.Webhook.RetryMaxInterval; i++ {
+		delay *= 2
+	}
+	if delay > setting.Webhook.RetryMaxInterval {
+		delay = setting.Webhook.RetryMaxInterval
+	}
+	if delay > 0 {
+		delay = delay/2 + time.Duration(rand.Int63n(int64(delay/2)+1))
+	}
+
+	// honor the delay asked by the receiver as long as it is not longer than the maximum interval
+	if resp != nil {
+		if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil {
+			if retryAfter := time.Duration(seconds) * time.Second; retryAfter > delay {
+				delay = retryAfter
+				if delay > setting.Webhook.RetryMaxInterval {
+					delay = setting.Webhook.RetryMaxInterval
+				}
+			}
+		}
+	}
+	return delay
+}
+
+// scheduleHookTaskRetry creates the next attempt of a failed hook task, it is delivered once the delay elapsed
+func scheduleHookTaskRetry(ctx context.Context, t *webhook_model.HookTask, delay time.Duration) error {
+	retry, err := webhook_model.CreateHookTask(ctx, &webhook_model.HookTask{
+		HookID:         t.HookID,
+		PayloadContent: t.PayloadContent,
+		EventType:      t.EventType,
+		Attempt:        t.Attempt + 1,
+		RetryUnix:      timeutil.TimeStamp(time.Now().Add(delay).Unix()),
+	})
+	if err != nil {
+		return err
+	}
+	log.Trace("Webhook Task[%d] failed, attempt %d scheduled as Webhook Task[%d] in %v", t.ID, retry.Attempt, retry.ID, delay)
+	return nil
+}
+
+// updateWebhookFailureCount keeps track of the consecutive failed deliveries of a webhook
+// and deactivates it once there are too many of them
+func updateWebhookFailureCount(ctx context.Context, w *webhook_model.Webhook, t *webhook_model.HookTask) error {
+	if t.IsSucceed {
+		return webhook_model.ResetWebhookFailureCount(ctx, w)
+	}
+	if !t.IsDeadLetter {
+		// the delivery will be retried
+		return nil
+	}
+
+	if err := webhook_model.IncreaseWebhookFailureCount(ctx, w); err != nil {
+		return err
+	}
+	threshold := setting.Webhook.AutoDisableAfterFailures
+	if threshold <= 0 || w.FailureCount < threshold {
+		return nil
+	}
+
+	log.Info("Deactivating webhook[%d] after %d consecutive failed deliveries", w.ID, w.FailureCount)
+	if err := webhook_model.DeactivateWebhook(ctx, w); err != nil {
+		return err
+	}
+
+	recipients, link, err := webhookAdministrators(ctx, w)
+	if err != nil {
+		return fmt.Errorf("unable to notify the deactivation of webhook[%d]: %w", w.ID, err)
+	}
+	host := w.URL
+	if u, err := url.Parse(w.URL); err == nil {
+		// the rest of the url may contain credentials
+		host = u.Host
+	}
+	mailer.SendWebhookDeactivatedMail(recipients, host, link, w.FailureCount)
+	return nil
+}
+
+// webhookAdministrators returns the users who manage a webhook and the link to its settings
+func webhookAdministrators(ctx context.Context, w *webhook_model.Webhook) ([]*user_model.User, string, error) {
+	switch {
+	case w.RepoID > 0:
+		repo, err := repo_model.GetRepositoryByID(ctx, w.RepoID)
+		if err != nil {
+			return nil, "", err
+		}
+		users, err := access_model.GetRepoAdmins(ctx, repo)
+		return users, fmt.Sprintf("%s/settings/hooks/%d", repo.HTMLURL(), w.ID), err
+	case w.OwnerID > 0:
+		owner, err := user_model.GetUserByID(ctx, w.OwnerID)
+		if err != nil {
+			return nil, "", err
+		}
+		if !owner.IsOrganization() {
+			return []*user_model.User{owner}, fmt.Sprintf("%suser/settings/hooks/%d", setting.AppURL, w.ID), nil
+		}
+		team, err := organization.OrgFromUser(owner).GetOwnerTeam(ctx)
+		if err != nil {
+			return nil, "", err
+		}
+		if err := team.LoadMembers(ctx); err != nil {
+			return nil, "", err
+		}
+		return team.Members, fmt.Sprintf("%sorg/%s/settings/hooks/%d", setting.AppURL, url.PathEscape(owner.Name), w.ID), nil
+	default:
+		users, _, err := user_model.SearchUsers(&user_model.SearchUserOptions{
+			Type:    user_model.UserTypeIndividual,
+			IsAdmin: util.OptionalBoolTrue,
+		})
+		return users, fmt.Sprintf("%sadmin/hooks/%d", setting.AppURL, w.ID), err
+	}
+}
+
+// pollWebhookRetries pushes the retries which became due to the sending queue,
+// the retries which were due before startTime are pushed by populateWebhookSendingQueue
+func pollWebhookRetries(ctx context.Context, startTime timeutil.TimeStamp) {
+	ctx, _, finished := process.GetManager().AddContext(ctx, "Webhook: Poll delivery retries")
+	defer finished()
+
+	after := startTime
+	ticker := time.NewTicker(retryPollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		now := timeutil.TimeStampNow()
+		taskIDs, err := webhook_model.FindDueRetryHookTaskIDs(ctx, after, now)
+		if err != nil {
+			log.Error("Unable to poll webhook retries as FindDueRetryHookTaskIDs failed: %v", err)
+			continue
+		}
+		after = now
+
+		for _, taskID := range taskIDs {
+			if err := enqueueHookTask(taskID); err != nil {
+				log.Error("Unable to push HookTask[%d] to the Webhook Sending queue: %v", taskID, err)
+			}
+		}
+	}
+}
+
+// RedeliverFailedHookTasks delivers again the failed deliveries of a webhook, all of them if uuids is empty.
+// It returns the number of deliveries which have been queued.
+func RedeliverFailedHookTasks(ctx context.Context, w *webhook_model.Webhook, uuids []string) (int, error) {
+	tasks, err := webhook_model.RedeliverFailedHookTasks(ctx, w.ID, uuids)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, task := range tasks {
+		if err := enqueueHookTask(task.ID); err != nil {
+			return 0, err
+		}
+	}
+	return len(tasks), nil
+}
@@ -0,0 +1,126 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRetryDelay(t *testing.T) {
+	defer func(initial, max time.Duration) {
+		setting.Webhook.RetryInitialInterval = initial
+		setting.Webhook.RetryMaxInterval = max
+	}(setting.Webhook.RetryInitialInterval, setting.Webhook.RetryMaxInterval)
+	setting.Webhook.RetryInitialInterval = 10 * time.Second
+	setting.Webhook.RetryMaxInterval = time.Minute
+
+	for attempt, expected := range map[int]time.Duration{1: 10 * time.Second, 2: 20 * time.Second, 3: 40 * time.Second, 4: time.Minute, 100: time.Minute} {
+		delay := retryDelay(attempt, nil)
+		assert.GreaterOrEqual(t, delay, expected/2, "attempt %d", attempt)
+		assert.LessOrEqual(t, delay, expected, "attempt %d", attempt)
+	}
+
+	resp := &http.Response{Header: http.Header{"Retry-After": []string{"50"}}}
+	assert.Equal(t, 50*time.Second, retryDelay(1, resp))
+	resp.Header.Set("Retry-After", "3600")
+	assert.Equal(t, time.Minute, retryDelay(1, resp))
+}
+
+func TestWebhookDeliverRetries(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer func(maxRetries, autoDisable int) {
+		setting.Webhook.MaxRetries = maxRetries
+		setting.Webhook.AutoDisableAfterFailures = autoDisable
+	}(setting.Webhook.MaxRetries, setting.Webhook.AutoDisableAfterFailures)
+	setting.Webhook.MaxRetries = 1
+	setting.Webhook.AutoDisableAfterFailures = 2
+
+	status := http.StatusBadGateway
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(s.Close)
+
+	hook := &webhook_model.Webhook{
+		RepoID:      1,
+		URL:         s.URL + "/webhook",
+		ContentType: webhook_model.ContentTypeJSON,
+		IsActive:    true,
+		Type:        webhook_module.GITEA,
+	}
+	assert.NoError(t, webhook_model.CreateWebhook(db.DefaultContext, hook))
+
+	deliver := func(task *webhook_model.HookTask) *webhook_model.HookTask {
+		if task == nil {
+			var err error
+			task, err = webhook_model.CreateHookTask(db.DefaultContext, &webhook_model.HookTask{HookID: hook.ID, EventType: webhook_module.HookEventPush, Payloader: &api.PushPayload{}})
+			require.NoError(t, err)
+		}
+		assert.NoError(t, Deliver(context.Background(), task))
+		return unittest.AssertExistsAndLoadBean(t, &webhook_model.HookTask{ID: task.ID})
+	}
+
+	// a transient failure is retried later
+	first := deliver(nil)
+	assert.False(t, first.IsSucceed)
+	assert.False(t, first.IsDeadLetter)
+	retry := unittest.AssertExistsAndLoadBean(t, &webhook_model.HookTask{HookID: hook.ID, Attempt: 2})
+	assert.False(t, retry.IsDelivered)
+	assert.Greater(t, retry.RetryUnix, timeutil.TimeStampNow())
+	assert.Equal(t, first.PayloadContent, retry.PayloadContent)
+
+	// the last attempt becomes a failed delivery
+	retry = deliver(retry)
+	assert.True(t, retry.IsDeadLetter)
+	unittest.AssertNotExistsBean(t, &webhook_model.HookTask{HookID: hook.ID, Attempt: 3})
+	hook = unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{ID: hook.ID})
+	assert.Equal(t, 1, hook.FailureCount)
+	assert.True(t, hook.IsActive)
+
+	// a client error is not retried and the webhook is deactivated after too many failures
+	status = http.StatusNotFound
+	failed := deliver(nil)
+	assert.True(t, failed.IsDeadLetter)
+	hook = unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{ID: hook.ID})
+	assert.Equal(t, 2, hook.FailureCount)
+	assert.False(t, hook.IsActive)
+
+	count, err := webhook_model.CountFailedHookTasks(db.DefaultContext, hook.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+
+	// a failed delivery leaves the failed deliveries once redelivered
+	tasks, err := webhook_model.RedeliverFailedHookTasks(db.DefaultContext, hook.ID, []string{failed.UUID})
+	assert.NoError(t, err)
+	if assert.Len(t, tasks, 1) {
+		assert.Equal(t, failed.PayloadContent, tasks[0].PayloadContent)
+		assert.Equal(t, 1, tasks[0].Attempt)
+	}
+	count, err = webhook_model.CountFailedHookTasks(db.DefaultContext, hook.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	// a successful delivery resets the failures
+	status = http.StatusOK
+	hook.IsActive = true
+	assert.NoError(t, webhook_model.UpdateWebhook(hook))
+	assert.True(t, deliver(nil).IsSucceed)
+	hook = unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{ID: hook.ID})
+	assert.Equal(t, 0, hook.FailureCount)
+}
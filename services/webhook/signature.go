@@ -0,0 +1,142 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-fed/httpsig"
+	"github.com/minio/sha256-simd"
+)
+
+const (
+	signingKeyBits = 2048
+	// SigningKeyPath is the path, relative to the application URL, of the public key verifying the HTTP signatures of deliveries
+	SigningKeyPath = "api/v1/webhooks/signing-key.pem"
+	// httpSignatureExpiration is the validity of an HTTP signature, in seconds
+	httpSignatureExpiration = 300
+)
+
+var signingKey struct {
+	sync.Mutex
+	priv *rsa.PrivateKey
+	pub  string
+}
+
+// GetSigningPublicKey returns the PEM encoded public key verifying the HTTP signatures of deliveries,
+// the key pair is generated on first use
+func GetSigningPublicKey(ctx context.Context) (string, error) {
+	if err := loadSigningKey(ctx); err != nil {
+		return "", err
+	}
+	return signingKey.pub, nil
+}
+
+func loadSigningKey(ctx context.Context) error {
+	signingKey.Lock()
+	defer signingKey.Unlock()
+	if signingKey.priv != nil {
+		return nil
+	}
+
+	keys := []string{system_model.KeyWebhookSigningPrivateKey, system_model.KeyWebhookSigningPublicKey}
+	settings, err := system_model.GetSettings(ctx, keys)
+	if err != nil {
+		return err
+	}
+	if len(settings) != 2 {
+		priv, pub, err := util.GenerateKeyPair(signingKeyBits)
+		if err != nil {
+			return err
+		}
+		// another node may store its key pair first, the pair which has been stored is read back
+		insertErr := system_model.InsertSettingsIfNotExist(ctx, map[string]string{
+			system_model.KeyWebhookSigningPrivateKey: priv,
+			system_model.KeyWebhookSigningPublicKey:  pub,
+		})
+		if settings, err = system_model.GetSettings(ctx, keys); err != nil {
+			return err
+		}
+		if len(settings) != 2 {
+			if insertErr != nil {
+				return insertErr
+			}
+			return errors.New("the webhook signing key pair is incomplete")
+		}
+	}
+	priv := settings[system_model.KeyWebhookSigningPrivateKey].SettingValue
+	pub := settings[system_model.KeyWebhookSigningPublicKey].SettingValue
+
+	block, _ := pem.Decode([]byte(priv))
+	if block == nil {
+		return errors.New("the webhook signing private key is not PEM encoded")
+	}
+	signingKey.priv, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	if err != nil {
+		return err
+	}
+	signingKey.pub = pub
+	return nil
+}
+
+// signHTTPRequest adds an HTTP signature of the request made with the instance signing key,
+// the id of the key is the url of the public key
+func signHTTPRequest(ctx context.Context, req *http.Request) error {
+	if err := loadSigningKey(ctx); err != nil {
+		return err
+	}
+
+	var body []byte
+	if req.GetBody != nil {
+		rc, err := req.GetBody()
+		if err != nil {
+			return err
+		}
+		defer rc.Close()
+		if body, err = io.ReadAll(rc); err != nil {
+			return err
+		}
+	}
+
+	headers := []string{httpsig.RequestTarget, "host", "date", "x-gitea-delivery"}
+	if req.Method != http.MethodGet {
+		headers = append(headers, "digest")
+	}
+	req.Header.Set("Date", strings.ReplaceAll(time.Now().UTC().Format(time.RFC1123), "UTC", "GMT"))
+	// the signer only reads the signed headers from the header map
+	req.Header.Set("Host", req.Host)
+
+	signer, _, err := httpsig.NewSigner([]httpsig.Algorithm{httpsig.RSA_SHA256}, httpsig.DigestSha256, headers, httpsig.Signature, httpSignatureExpiration)
+	if err != nil {
+		return err
+	}
+	return signer.SignRequest(signingKey.priv, setting.AppURL+SigningKeyPath, req, body)
+}
+
+// timestampedSignatures returns the HMAC-SHA256 signatures of "<timestamp>.<payload>" made with each secret,
+// formatted as "v1=<hex>,v1=<hex>"
+func timestampedSignatures(secrets []string, timestamp, payload string) string {
+	signatures := make([]string, 0, len(secrets))
+	for _, secret := range secrets {
+		sig := hmac.New(sha256.New, []byte(secret))
+		_, _ = sig.Write([]byte(timestamp + "." + payload))
+		signatures = append(signatures, "v1="+hex.EncodeToString(sig.Sum(nil)))
+	}
+	return strings.Join(signatures, ",")
+}
@@ -0,0 +1,114 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package webhook
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	system_model "code.gitea.io/gitea/models/system"
+	"code.gitea.io/gitea/models/unittest"
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	webhook_module "code.gitea.io/gitea/modules/webhook"
+
+	"github.com/go-fed/httpsig"
+	"github.com/minio/sha256-simd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWebhookDeliverSignatures(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	pubPem, err := GetSigningPublicKey(db.DefaultContext)
+	require.NoError(t, err)
+	block, _ := pem.Decode([]byte(pubPem))
+	require.NotNil(t, block)
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	require.NoError(t, err)
+
+	sign := func(secret, timestamp string, body []byte) string {
+		sig := hmac.New(sha256.New, []byte(secret))
+		_, _ = sig.Write([]byte(timestamp + "."))
+		_, _ = sig.Write(body)
+		return "v1=" + hex.EncodeToString(sig.Sum(nil))
+	}
+
+	done := make(chan struct{}, 1)
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		defer func() { done <- struct{}{} }()
+		body, err := io.ReadAll(r.Body)
+		assert.NoError(t, err)
+
+		timestamp := r.Header.Get("X-Gitea-Timestamp")
+		assert.NotEmpty(t, timestamp)
+		assert.Equal(t, sign("new", timestamp, body)+","+sign("old", timestamp, body), r.Header.Get("X-Gitea-Signature-Timestamped"))
+
+		verifier, err := httpsig.NewVerifier(r)
+		if assert.NoError(t, err) {
+			assert.Equal(t, setting.AppURL+SigningKeyPath, verifier.KeyId())
+			assert.NoError(t, verifier.Verify(pub, httpsig.RSA_SHA256))
+		}
+		assert.True(t, strings.HasPrefix(r.Header.Get("Digest"), "SHA-256="))
+		w.WriteHeader(http.StatusOK)
+	}))
+	t.Cleanup(s.Close)
+
+	hook := &webhook_model.Webhook{
+		RepoID:        3,
+		URL:           s.URL + "/webhook",
+		ContentType:   webhook_model.ContentTypeJSON,
+		Secret:        "old",
+		HTTPSignature: true,
+		IsActive:      true,
+		Type:          webhook_module.GITEA,
+	}
+	require.NoError(t, hook.RotateSecret("new", time.Hour))
+	assert.NoError(t, webhook_model.CreateWebhook(db.DefaultContext, hook))
+
+	hookTask, err := webhook_model.CreateHookTask(db.DefaultContext, &webhook_model.HookTask{HookID: hook.ID, EventType: webhook_module.HookEventPush, Payloader: &api.PushPayload{}})
+	require.NoError(t, err)
+
+	assert.NoError(t, Deliver(context.Background(), hookTask))
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("waited to long for request to happen")
+	}
+
+	assert.True(t, hookTask.IsSucceed)
+}
+
+func TestLoadSigningKeyStored(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// the key pair another node has stored first is used
+	priv, pub, err := util.GenerateKeyPair(signingKeyBits)
+	require.NoError(t, err)
+	require.NoError(t, system_model.InsertSettingsIfNotExist(db.DefaultContext, map[string]string{
+		system_model.KeyWebhookSigningPrivateKey: priv,
+		system_model.KeyWebhookSigningPublicKey:  pub,
+	}))
+
+	signingKey.Lock()
+	signingKey.priv, signingKey.pub = nil, ""
+	signingKey.Unlock()
+
+	loaded, err := GetSigningPublicKey(db.DefaultContext)
+	require.NoError(t, err)
+	assert.Equal(t, pub, loaded)
+}
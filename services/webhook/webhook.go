@@ -65,6 +65,10 @@ var webhooks = map[webhook_module.HookType]*webhook{
 		name:           webhook_module.PACKAGIST,
 		payloadCreator: GetPackagistPayload,
 	},
+	webhook_module.CUSTOM: {
+		name:           webhook_module.CUSTOM,
+		payloadCreator: GetCustomPayload,
+	},
 }
 
 // IsValidHookTaskType returns true if a webhook registered
@@ -0,0 +1,120 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/http"
+	"testing"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIUserVariables(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	token := getUserToken(t, "user2", auth_model.AccessTokenScopeWriteUser)
+	testAPIActionsVariables(t, "/api/v1/user/actions/variables", token, 2, 0)
+}
+
+func TestAPIOrgVariables(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	token := getUserToken(t, "user2", auth_model.AccessTokenScopeWriteOrganization)
+	testAPIActionsVariables(t, "/api/v1/orgs/user3/actions/variables", token, 3, 0)
+
+	// only the owners of the organization can manage its variables
+	token = getUserToken(t, "user4", auth_model.AccessTokenScopeWriteOrganization)
+	req := NewRequest(t, "GET", "/api/v1/orgs/user3/actions/variables?token="+token)
+	MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIRepoVariables(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	token := getUserToken(t, "user2", auth_model.AccessTokenScopeWriteRepository)
+	testAPIActionsVariables(t, "/api/v1/repos/user2/repo1/actions/variables", token, 0, 1)
+
+	// only the admins of the repository can manage its variables
+	token = getUserToken(t, "user4", auth_model.AccessTokenScopeWriteRepository)
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/actions/variables?token="+token)
+	MakeRequest(t, req, http.StatusForbidden)
+}
+
+func testAPIActionsVariables(t *testing.T, url, token string, ownerID, repoID int64) {
+	variableURL := func(name string) string {
+		return url + "/" + name + "?token=" + token
+	}
+
+	req := NewRequestWithJSON(t, "POST", variableURL("first_var"), api.CreateVariableOption{Value: "first"})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var variable api.ActionVariable
+	DecodeJSON(t, resp, &variable)
+	assert.Equal(t, "FIRST_VAR", variable.Name)
+	assert.Equal(t, "first", variable.Data)
+	assert.EqualValues(t, ownerID, variable.OwnerID)
+	assert.EqualValues(t, repoID, variable.RepoID)
+
+	req = NewRequestWithJSON(t, "POST", variableURL("SECOND_VAR"), api.CreateVariableOption{Value: "second"})
+	MakeRequest(t, req, http.StatusCreated)
+
+	// the names are case insensitive
+	req = NewRequestWithJSON(t, "POST", variableURL("First_Var"), api.CreateVariableOption{Value: "duplicate"})
+	MakeRequest(t, req, http.StatusConflict)
+	req = NewRequestWithJSON(t, "POST", variableURL("GITEA_VAR"), api.CreateVariableOption{Value: "forbidden"})
+	MakeRequest(t, req, http.StatusBadRequest)
+	req = NewRequestWithJSON(t, "POST", variableURL("1VAR"), api.CreateVariableOption{Value: "invalid"})
+	MakeRequest(t, req, http.StatusBadRequest)
+
+	req = NewRequest(t, "GET", variableURL("first_var"))
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &variable)
+	assert.Equal(t, "FIRST_VAR", variable.Name)
+	assert.Equal(t, "first", variable.Data)
+
+	req = NewRequest(t, "GET", url+"?token="+token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "2", resp.Header().Get("X-Total-Count"))
+	var variables []*api.ActionVariable
+	DecodeJSON(t, resp, &variables)
+	if assert.Len(t, variables, 2) {
+		assert.Equal(t, "FIRST_VAR", variables[0].Name)
+		assert.Equal(t, "SECOND_VAR", variables[1].Name)
+	}
+
+	req = NewRequestWithJSON(t, "PUT", variableURL("FIRST_VAR"), api.UpdateVariableOption{Value: "updated"})
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &variable)
+	assert.Equal(t, "FIRST_VAR", variable.Name)
+	assert.Equal(t, "updated", variable.Data)
+
+	req = NewRequestWithJSON(t, "PUT", variableURL("FIRST_VAR"), api.UpdateVariableOption{Name: "second_var", Value: "duplicate"})
+	MakeRequest(t, req, http.StatusConflict)
+	req = NewRequestWithJSON(t, "PUT", variableURL("FIRST_VAR"), api.UpdateVariableOption{Name: "GITHUB_VAR", Value: "forbidden"})
+	MakeRequest(t, req, http.StatusBadRequest)
+	req = NewRequestWithJSON(t, "PUT", variableURL("MISSING_VAR"), api.UpdateVariableOption{Value: "missing"})
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestWithJSON(t, "PUT", variableURL("FIRST_VAR"), api.UpdateVariableOption{Name: "renamed_var", Value: "renamed"})
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &variable)
+	assert.Equal(t, "RENAMED_VAR", variable.Name)
+	assert.Equal(t, "renamed", variable.Data)
+	req = NewRequest(t, "GET", variableURL("FIRST_VAR"))
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", variableURL("RENAMED_VAR"))
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequest(t, "DELETE", variableURL("RENAMED_VAR"))
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", variableURL("RENAMED_VAR"))
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// a deleted name can be used again
+	req = NewRequestWithJSON(t, "POST", variableURL("RENAMED_VAR"), api.CreateVariableOption{Value: "again"})
+	MakeRequest(t, req, http.StatusCreated)
+}
@@ -0,0 +1,286 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	arch_module "code.gitea.io/gitea/modules/packages/arch"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/klauspost/compress/zstd"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageArch(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	repository := "core"
+
+	createPackage := func(name, version, architecture string) []byte {
+		pkginfo := fmt.Sprintf(`pkgname = %s
+pkgbase = %s
+pkgver = %s
+pkgdesc = Gitea Test Package
+url = https://gitea.io/
+builddate = 1678834800
+packager = KN4CK3R <kn4ck3r@gitea.io>
+size = 4096
+arch = %s
+license = MIT
+depend = glibc
+`, name, name, version, architecture)
+
+		var tarBuf bytes.Buffer
+		tw := tar.NewWriter(&tarBuf)
+		for _, file := range []struct {
+			Name    string
+			Content string
+		}{
+			{".PKGINFO", pkginfo},
+			{"usr/bin/" + name, "#!/bin/sh\n"},
+		} {
+			tw.WriteHeader(&tar.Header{
+				Name: file.Name,
+				Mode: 0o755,
+				Size: int64(len(file.Content)),
+			})
+			tw.Write([]byte(file.Content))
+		}
+		tw.Close()
+
+		var buf bytes.Buffer
+		zw, _ := zstd.NewWriter(&buf)
+		zw.Write(tarBuf.Bytes())
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	content := createPackage("gitea-test", "1.0.0-1", "x86_64")
+	contentNewer := createPackage("gitea-test", "1.0.1-1", "x86_64")
+	contentAny := createPackage("gitea-any", "1.0.0-1", "any")
+
+	rootURL := fmt.Sprintf("/api/packages/%s/arch", user.Name)
+
+	var keyring openpgp.EntityList
+
+	readDatabase := func(t *testing.T, url string) map[string]string {
+		req := NewRequest(t, "GET", url)
+		resp := MakeRequest(t, req, http.StatusOK)
+		data := resp.Body.Bytes()
+
+		req = NewRequest(t, "GET", url+".sig")
+		resp = MakeRequest(t, req, http.StatusOK)
+		_, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(data), resp.Body)
+		assert.NoError(t, err)
+
+		gzr, err := gzip.NewReader(bytes.NewReader(data))
+		assert.NoError(t, err)
+
+		files := make(map[string]string)
+		tr := tar.NewReader(gzr)
+		for {
+			hd, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			assert.NoError(t, err)
+
+			if hd.Typeflag == tar.TypeReg {
+				buf, err := io.ReadAll(tr)
+				assert.NoError(t, err)
+				files[hd.Name] = string(buf)
+			}
+		}
+		return files
+	}
+
+	t.Run("RepositoryKey", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", rootURL+"/repository.key")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, "application/pgp-keys", resp.Header().Get("Content-Type"))
+		assert.Contains(t, resp.Body.String(), "-----BEGIN PGP PUBLIC KEY BLOCK-----")
+
+		var err error
+		keyring, err = openpgp.ReadArmoredKeyRing(resp.Body)
+		assert.NoError(t, err)
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		uploadURL := fmt.Sprintf("%s/%s", rootURL, repository)
+
+		req := NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader([]byte{}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeArch)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.Nil(t, pd.SemVer)
+		assert.IsType(t, &arch_module.VersionMetadata{}, pd.Metadata)
+		assert.Equal(t, "gitea-test", pd.Package.Name)
+		assert.Equal(t, "1.0.0-1", pd.Version.Version)
+
+		if assert.Len(t, pd.Files, 1) {
+			pf := pd.Files[0]
+			assert.Equal(t, "gitea-test-1.0.0-1-x86_64.pkg.tar.zst", pf.File.Name)
+			assert.Equal(t, repository+"|x86_64", pf.File.CompositeKey)
+			assert.True(t, pf.File.IsLead)
+			assert.Equal(t, repository, pf.Properties.GetByName(arch_module.PropertyRepository))
+			assert.Equal(t, "x86_64", pf.Properties.GetByName(arch_module.PropertyArchitecture))
+			assert.Equal(t, "usr/bin/gitea-test", pf.Properties.GetByName(arch_module.PropertyFiles))
+			assert.NotEmpty(t, pf.Properties.GetByName(arch_module.PropertySignature))
+		}
+
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(contentAny))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+	})
+
+	t.Run("Database", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		for _, filename := range []string{repository + ".db", repository + ".db.tar.gz"} {
+			files := readDatabase(t, fmt.Sprintf("%s/%s/x86_64/%s", rootURL, repository, filename))
+			assert.Len(t, files, 2)
+
+			desc := files["gitea-test-1.0.0-1/desc"]
+			assert.Contains(t, desc, "%FILENAME%\ngitea-test-1.0.0-1-x86_64.pkg.tar.zst\n\n")
+			assert.Contains(t, desc, "%NAME%\ngitea-test\n\n")
+			assert.Contains(t, desc, "%VERSION%\n1.0.0-1\n\n")
+			assert.Contains(t, desc, "%DESC%\nGitea Test Package\n\n")
+			assert.Contains(t, desc, fmt.Sprintf("%%CSIZE%%\n%d\n\n", len(content)))
+			assert.Contains(t, desc, "%ISIZE%\n4096\n\n")
+			assert.Contains(t, desc, "%PGPSIG%\n")
+			assert.Contains(t, desc, "%URL%\nhttps://gitea.io/\n\n")
+			assert.Contains(t, desc, "%LICENSE%\nMIT\n\n")
+			assert.Contains(t, desc, "%ARCH%\nx86_64\n\n")
+			assert.Contains(t, desc, "%BUILDDATE%\n1678834800\n\n")
+			assert.Contains(t, desc, "%PACKAGER%\nKN4CK3R <kn4ck3r@gitea.io>\n\n")
+			assert.Contains(t, desc, "%DEPENDS%\nglibc\n\n")
+
+			assert.Contains(t, files["gitea-any-1.0.0-1/desc"], "%ARCH%\nany\n\n")
+		}
+
+		files := readDatabase(t, fmt.Sprintf("%s/%s/x86_64/%s.files", rootURL, repository, repository))
+		assert.Len(t, files, 4)
+		assert.Equal(t, "%FILES%\nusr/bin/gitea-test\n\n", files["gitea-test-1.0.0-1/files"])
+
+		// the packages of the "any" architecture can be installed on the other architectures
+		files = readDatabase(t, fmt.Sprintf("%s/%s/aarch64/%s.db", rootURL, repository, repository))
+		assert.Len(t, files, 1)
+		assert.Contains(t, files, "gitea-any-1.0.0-1/desc")
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/unknown/x86_64/unknown.db", rootURL))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		for _, c := range []struct {
+			Filename string
+			Content  []byte
+		}{
+			{"gitea-test-1.0.0-1-x86_64.pkg.tar.zst", content},
+			{"gitea-any-1.0.0-1-any.pkg.tar.zst", contentAny},
+		} {
+			url := fmt.Sprintf("%s/%s/x86_64/%s", rootURL, repository, c.Filename)
+
+			req := NewRequest(t, "GET", url)
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, c.Content, resp.Body.Bytes())
+
+			req = NewRequest(t, "GET", url+".sig")
+			resp = MakeRequest(t, req, http.StatusOK)
+			_, err := openpgp.CheckDetachedSignature(keyring, bytes.NewReader(c.Content), resp.Body)
+			assert.NoError(t, err)
+		}
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/aarch64/gitea-test-1.0.0-1-x86_64.pkg.tar.zst", rootURL, repository))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("LatestVersion", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/%s", rootURL, repository), bytes.NewReader(contentNewer))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		files := readDatabase(t, fmt.Sprintf("%s/%s/x86_64/%s.db", rootURL, repository, repository))
+		assert.Len(t, files, 2)
+		assert.Contains(t, files, "gitea-test-1.0.1-1/desc")
+		assert.NotContains(t, files, "gitea-test-1.0.0-1/desc")
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		for _, c := range []struct {
+			Architecture string
+			Filename     string
+		}{
+			{"x86_64", "gitea-test-1.0.1-1-x86_64.pkg.tar.zst"},
+			{"x86_64", "gitea-test-1.0.0-1-x86_64.pkg.tar.zst"},
+			{"any", "gitea-any-1.0.0-1-any.pkg.tar.zst"},
+		} {
+			url := fmt.Sprintf("%s/%s/%s/%s", rootURL, repository, c.Architecture, c.Filename)
+
+			req := NewRequest(t, "DELETE", url)
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequest(t, "DELETE", url)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequest(t, "DELETE", url)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNotFound)
+		}
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeArch)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+
+		// deleting the last package of a repository removes its databases
+		for _, architecture := range []string{"x86_64", "any"} {
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s/%s.db", rootURL, repository, architecture, repository))
+			MakeRequest(t, req, http.StatusNotFound)
+		}
+	})
+
+}
@@ -8,6 +8,7 @@ import (
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"net/http/httptest"
 	"strings"
 	"sync"
 	"testing"
@@ -21,6 +22,8 @@ import (
 	container_module "code.gitea.io/gitea/modules/packages/container"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	packages_service "code.gitea.io/gitea/services/packages"
+	container_service "code.gitea.io/gitea/services/packages/container"
 	"code.gitea.io/gitea/tests"
 
 	"github.com/minio/sha256-simd"
@@ -699,3 +702,151 @@ func TestPackageContainer(t *testing.T) {
 		session.MakeRequest(t, req, http.StatusSeeOther)
 	})
 }
+
+func TestPackageContainerReferrers(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4})
+
+	defer func() {
+		_, err := packages_service.RemoveAllPackages(db.DefaultContext, user.ID)
+		assert.NoError(t, err)
+	}()
+
+	image := "referrers-test"
+	url := fmt.Sprintf("%sv2/%s/%s", setting.AppURL, user.Name, image)
+
+	uploadBlob := func(t *testing.T, content string) string {
+		digest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(content)))
+		req := NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", url, digest), strings.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+		return digest
+	}
+
+	uploadManifest := func(t *testing.T, reference, content string) *httptest.ResponseRecorder {
+		req := NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/manifests/%s", url, reference), strings.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		req.Header.Set("Content-Type", oci.MediaTypeImageManifest)
+		return MakeRequest(t, req, http.StatusCreated)
+	}
+
+	configDigest := uploadBlob(t, `{"architecture":"amd64","os":"linux"}`)
+	emptyDigest := uploadBlob(t, `{}`)
+	payloadDigest := uploadBlob(t, `signature payload`)
+
+	subjectContent := `{"schemaVersion":2,"mediaType":"` + oci.MediaTypeImageManifest + `","config":{"mediaType":"` + oci.MediaTypeImageConfig + `","digest":"` + configDigest + `","size":37},"layers":[]}`
+	subjectDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(subjectContent)))
+	subjectDescriptor := `{"mediaType":"` + oci.MediaTypeImageManifest + `","digest":"` + subjectDigest + `","size":` + fmt.Sprint(len(subjectContent)) + `}`
+
+	getReferrers := func(t *testing.T, query string) (*oci.Index, *httptest.ResponseRecorder) {
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/referrers/%s%s", url, subjectDigest, query))
+		AddBasicAuthHeader(req, user.Name)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, oci.MediaTypeImageIndex, resp.Header().Get("Content-Type"))
+
+		var index *oci.Index
+		DecodeJSON(t, resp, &index)
+		return index, resp
+	}
+
+	signatureType := "application/vnd.dev.cosign.artifact.sig.v1+json"
+	signatureContent := `{"schemaVersion":2,"mediaType":"` + oci.MediaTypeImageManifest + `","artifactType":"` + signatureType + `","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"` + emptyDigest + `","size":2},"layers":[{"mediaType":"application/vnd.dev.cosign.simplesigning.v1+json","digest":"` + payloadDigest + `","size":17}],"subject":` + subjectDescriptor + `,"annotations":{"dev.cosignproject.cosign/signature":"abc"}}`
+	signatureDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(signatureContent)))
+
+	// artifactType falls back to the config media type
+	sbomType := "application/spdx+json"
+	sbomContent := `{"schemaVersion":2,"mediaType":"` + oci.MediaTypeImageManifest + `","config":{"mediaType":"` + sbomType + `","digest":"` + emptyDigest + `","size":2},"layers":[],"subject":` + subjectDescriptor + `}`
+	sbomDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(sbomContent)))
+
+	t.Run("Empty", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		uploadManifest(t, "latest", subjectContent)
+
+		index, _ := getReferrers(t, "")
+		assert.EqualValues(t, 2, index.SchemaVersion)
+		assert.Equal(t, oci.MediaTypeImageIndex, index.MediaType)
+		assert.NotNil(t, index.Manifests)
+		assert.Empty(t, index.Manifests)
+	})
+
+	t.Run("InvalidDigest", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/referrers/invalid", url))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+	})
+
+	t.Run("UploadReferrers", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		resp := uploadManifest(t, signatureDigest, signatureContent)
+		assert.Equal(t, subjectDigest, resp.Header().Get("OCI-Subject"))
+
+		resp = uploadManifest(t, sbomDigest, sbomContent)
+		assert.Equal(t, subjectDigest, resp.Header().Get("OCI-Subject"))
+
+		pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages_model.TypeContainer, image, signatureDigest)
+		assert.NoError(t, err)
+
+		pd, err := packages_model.GetPackageDescriptor(db.DefaultContext, pv)
+		assert.NoError(t, err)
+		assert.Equal(t, subjectDigest, pd.VersionProperties.GetByName(container_module.PropertyManifestSubject))
+		assert.Equal(t, signatureType, pd.VersionProperties.GetByName(container_module.PropertyArtifactType))
+
+		// referrers are kept by cleanup rules as long as the subject exists
+		skip, err := container_service.ShouldBeSkipped(db.DefaultContext, nil, pd.Package, pv)
+		assert.NoError(t, err)
+		assert.True(t, skip)
+	})
+
+	t.Run("GetReferrers", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		index, resp := getReferrers(t, "")
+		assert.Empty(t, resp.Header().Get("OCI-Filters-Applied"))
+		assert.Len(t, index.Manifests, 2)
+
+		assert.Equal(t, oci.MediaTypeImageManifest, index.Manifests[0].MediaType)
+		assert.EqualValues(t, signatureDigest, index.Manifests[0].Digest)
+		assert.EqualValues(t, len(signatureContent), index.Manifests[0].Size)
+		assert.Equal(t, signatureType, index.Manifests[0].ArtifactType)
+		assert.Equal(t, map[string]string{"dev.cosignproject.cosign/signature": "abc"}, index.Manifests[0].Annotations)
+
+		assert.EqualValues(t, sbomDigest, index.Manifests[1].Digest)
+		assert.Equal(t, sbomType, index.Manifests[1].ArtifactType)
+
+		index, resp = getReferrers(t, "?artifactType="+strings.ReplaceAll(sbomType, "+", "%2B"))
+		assert.Equal(t, "artifactType", resp.Header().Get("OCI-Filters-Applied"))
+		assert.Len(t, index.Manifests, 1)
+		assert.EqualValues(t, sbomDigest, index.Manifests[0].Digest)
+
+		index, _ = getReferrers(t, "?artifactType=application/unknown")
+		assert.Empty(t, index.Manifests)
+	})
+
+	t.Run("View", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		// cosign tag scheme
+		uploadManifest(t, strings.Replace(subjectDigest, ":", "-", 1)+".att", `{"schemaVersion":2,"mediaType":"`+oci.MediaTypeImageManifest+`","config":{"mediaType":"`+oci.MediaTypeImageConfig+`","digest":"`+configDigest+`","size":37},"layers":[{"mediaType":"application/vnd.dsse.envelope.v1+json","digest":"`+payloadDigest+`","size":17}]}`)
+
+		session := loginUser(t, user.Name)
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/%s/-/packages/container/%s/latest", user.Name, image))
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		body := resp.Body.String()
+		assert.Contains(t, body, signatureDigest)
+		assert.Contains(t, body, sbomDigest)
+		assert.Contains(t, body, "Attestation")
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/%s/-/packages/container/%s/%s", user.Name, image, signatureDigest))
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		href, _ := htmlDoc.doc.Find(fmt.Sprintf(`a[href$="/%s/latest"]`, image)).Attr("href")
+		assert.NotEmpty(t, href)
+	})
+}
@@ -0,0 +1,306 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	packages_service "code.gitea.io/gitea/services/packages"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageRemote(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	oldAllowedHostList := setting.Packages.RemoteAllowedHostList
+	setting.Packages.RemoteAllowedHostList = "loopback"
+	defer func() {
+		setting.Packages.RemoteAllowedHostList = oldAllowedHostList
+	}()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	// remotes and cached packages are not covered by the fixtures
+	defer func() {
+		prs, err := packages_model.GetRemotesByOwner(db.DefaultContext, user.ID)
+		assert.NoError(t, err)
+		for _, pr := range prs {
+			assert.NoError(t, packages_model.DeleteRemoteByID(db.DefaultContext, pr.ID))
+		}
+		_, err = packages_service.RemoveAllPackages(db.DefaultContext, user.ID)
+		assert.NoError(t, err)
+	}()
+
+	content := "remote package content"
+	sum1 := sha1.Sum([]byte(content))
+	sum256 := sha256.Sum256([]byte(content))
+	sum512 := sha512.Sum512([]byte(content))
+
+	mux := http.NewServeMux()
+	upstream := httptest.NewServer(mux)
+	defer upstream.Close()
+
+	var upstreamRequests atomic.Int64
+	serve := func(p, contentType, body string) {
+		mux.HandleFunc(p, func(w http.ResponseWriter, r *http.Request) {
+			upstreamRequests.Add(1)
+			if r.URL.Path != p {
+				http.NotFound(w, r)
+				return
+			}
+			w.Header().Set("Content-Type", contentType)
+			fmt.Fprint(w, body)
+		})
+	}
+
+	addRemote := func(t *testing.T, packageType packages_model.Type, url string) {
+		_, err := packages_model.InsertRemote(db.DefaultContext, &packages_model.PackageRemote{
+			Enabled: true,
+			OwnerID: user.ID,
+			Type:    packageType,
+			URL:     url,
+		})
+		assert.NoError(t, err)
+	}
+
+	assertCachedVersion := func(t *testing.T, packageType packages_model.Type, packageName, packageVersion string) {
+		pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packageType, packageName, packageVersion)
+		assert.NoError(t, err)
+
+		pd, err := packages_model.GetPackageDescriptor(db.DefaultContext, pv)
+		assert.NoError(t, err)
+		assert.Equal(t, user.ID, pd.Creator.ID)
+		assert.NotEmpty(t, pd.VersionProperties.GetByName(packages_model.PropertyRemoteOrigin))
+	}
+
+	t.Run("Npm", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		packageName := "@scope/test-package"
+		packageVersion := "1.0.1"
+		filename := "test-package-1.0.1.tgz"
+
+		serve("/npm/@scope/test-package", "application/json", `{
+			"_id": "`+packageName+`",
+			"name": "`+packageName+`",
+			"dist-tags": {"latest": "`+packageVersion+`"},
+			"versions": {
+				"`+packageVersion+`": {
+					"name": "`+packageName+`",
+					"version": "`+packageVersion+`",
+					"description": "Test Description",
+					"dist": {
+						"tarball": "`+upstream.URL+`/npm/@scope/test-package/-/`+filename+`",
+						"integrity": "sha512-`+base64.StdEncoding.EncodeToString(sum512[:])+`"
+					}
+				},
+				"invalid": {"name": "`+packageName+`", "version": "invalid"}
+			}
+		}`)
+		serve("/npm/@scope/test-package/-/"+filename, "application/octet-stream", content)
+
+		root := fmt.Sprintf("/api/packages/%s/npm/%s", user.Name, strings.Replace(packageName, "/", "%2f", 1))
+
+		req := NewRequest(t, "GET", root)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		addRemote(t, packages_model.TypeNpm, upstream.URL+"/npm")
+
+		req = NewRequest(t, "GET", root)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result map[string]any
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, map[string]any{"latest": packageVersion}, result["dist-tags"])
+		versions := result["versions"].(map[string]any)
+		assert.Len(t, versions, 1)
+		tarball := versions[packageVersion].(map[string]any)["dist"].(map[string]any)["tarball"].(string)
+		assert.Equal(t, fmt.Sprintf("%sapi/packages/%s/npm/%s/-/%s/%s", setting.AppURL, user.Name, "%40scope%2Ftest-package", packageVersion, filename), tarball)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/-/%s/%s", root, packageVersion, filename))
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.String())
+
+		assertCachedVersion(t, packages_model.TypeNpm, packageName, packageVersion)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/-/%s/%s", root, "2.0.0", "test-package-2.0.0.tgz"))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("PyPI", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		packageName := "test-package"
+		packageVersion := "1.0.1"
+		filename := "test_package-1.0.1-py3-none-any.whl"
+
+		serve("/pypi/simple/test-package/", "text/html", `<!DOCTYPE html>
+<html>
+	<body>
+		<a href="../../files/`+filename+`#sha256=`+hex.EncodeToString(sum256[:])+`" data-requires-python="&gt;=3.7">`+filename+`</a><br>
+	</body>
+</html>`)
+		serve("/pypi/files/"+filename, "application/octet-stream", content)
+
+		root := fmt.Sprintf("/api/packages/%s/pypi", user.Name)
+
+		addRemote(t, packages_model.TypePyPI, upstream.URL+"/pypi/simple")
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/simple/%s", root, packageName))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		nodes := htmlDoc.doc.Find("a")
+		assert.Equal(t, 1, nodes.Length())
+		href, _ := nodes.Attr("href")
+		assert.Equal(t, fmt.Sprintf("%sapi/packages/%s/pypi/files/%s/%s/%s#sha256-%s", setting.AppURL, user.Name, packageName, packageVersion, filename, hex.EncodeToString(sum256[:])), href)
+		requiresPython, _ := nodes.Attr("data-requires-python")
+		assert.Equal(t, ">=3.7", requiresPython)
+
+		req = NewRequest(t, "GET", href)
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.String())
+
+		assertCachedVersion(t, packages_model.TypePyPI, packageName, packageVersion)
+	})
+
+	t.Run("Maven", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		groupID := "com.gitea"
+		artifactID := "test-project"
+		packageVersion := "1.0.1"
+		filename := fmt.Sprintf("%s-%s.jar", artifactID, packageVersion)
+
+		serve("/maven2/com/gitea/test-project/maven-metadata.xml", "text/xml", `<?xml version="1.0" encoding="UTF-8"?>
+<metadata>
+	<groupId>`+groupID+`</groupId>
+	<artifactId>`+artifactID+`</artifactId>
+	<versioning>
+		<latest>`+packageVersion+`</latest>
+		<release>`+packageVersion+`</release>
+		<versions>
+			<version>`+packageVersion+`</version>
+		</versions>
+	</versioning>
+</metadata>`)
+		serve("/maven2/com/gitea/test-project/1.0.1/"+filename, "application/java-archive", content)
+		serve("/maven2/com/gitea/test-project/1.0.1/"+filename+".sha1", "text/plain", hex.EncodeToString(sum1[:]))
+
+		root := fmt.Sprintf("/api/packages/%s/maven/%s/%s", user.Name, strings.ReplaceAll(groupID, ".", "/"), artifactID)
+
+		addRemote(t, packages_model.TypeMaven, upstream.URL+"/maven2")
+
+		req := NewRequest(t, "GET", root+"/maven-metadata.xml")
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "<version>"+packageVersion+"</version>")
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s", root, packageVersion, filename))
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.String())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s.sha256", root, packageVersion, filename))
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, hex.EncodeToString(sum256[:]), resp.Body.String())
+
+		assertCachedVersion(t, packages_model.TypeMaven, groupID+"-"+artifactID, packageVersion)
+	})
+
+	t.Run("Container", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		image := "library/test"
+
+		blobDigest := "sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4"
+		blobContent, _ := base64.StdEncoding.DecodeString(`H4sIAAAJbogA/2IYBaNgFIxYAAgAAP//Lq+17wAEAAA=`)
+
+		configDigest := "sha256:4607e093bec406eaadb6f3a340f63400c9d3a7038680744c406903766b938f0d"
+		configContent := `{"architecture":"amd64","config":{"Env":["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],"Cmd":["/true"],"ArgsEscaped":true,"Image":"sha256:9bd8b88dc68b80cffe126cc820e4b52c6e558eb3b37680bfee8e5f3ed7b8c257"},"container":"b89fe92a887d55c0961f02bdfbfd8ac3ddf66167db374770d2d9e9fab3311510","container_config":{"Hostname":"b89fe92a887d","Env":["PATH=/usr/local/sbin:/usr/local/bin:/usr/sbin:/usr/bin:/sbin:/bin"],"Cmd":["/bin/sh","-c","#(nop) ","CMD [\"/true\"]"],"ArgsEscaped":true,"Image":"sha256:9bd8b88dc68b80cffe126cc820e4b52c6e558eb3b37680bfee8e5f3ed7b8c257"},"created":"2022-01-01T00:00:00.000000000Z","docker_version":"20.10.12","history":[{"created":"2022-01-01T00:00:00.000000000Z","created_by":"/bin/sh -c #(nop) COPY file:0e7589b0c800daaf6fa460d2677101e4676dd9491980210cb345480e513f3602 in /true "},{"created":"2022-01-01T00:00:00.000000001Z","created_by":"/bin/sh -c #(nop)  CMD [\"/true\"]","empty_layer":true}],"os":"linux","rootfs":{"type":"layers","diff_ids":["sha256:0ff3b91bdf21ecdf2f2f3d4372c2098a14dbe06cd678e8f0a85fd4902d00e2e2"]}}`
+
+		manifestMediaType := "application/vnd.docker.distribution.manifest.v2+json"
+		manifestDigest := "sha256:4f10484d1c1bb13e3956b4de1cd42db8e0f14a75be1617b60f2de3cd59c803c6"
+		manifestContent := `{"schemaVersion":2,"mediaType":"application/vnd.docker.distribution.manifest.v2+json","config":{"mediaType":"application/vnd.docker.container.image.v1+json","digest":"sha256:4607e093bec406eaadb6f3a340f63400c9d3a7038680744c406903766b938f0d","size":1069},"layers":[{"mediaType":"application/vnd.docker.image.rootfs.diff.tar.gzip","digest":"sha256:a3ed95caeb02ffe68cdd9fd84406680ae93d633cb16422d00e8a7c22955b46d4","size":32}]}`
+
+		serveManifest := func(w http.ResponseWriter, r *http.Request) {
+			upstreamRequests.Add(1)
+			w.Header().Set("Content-Type", manifestMediaType)
+			w.Header().Set("Docker-Content-Digest", manifestDigest)
+			if r.Method == http.MethodHead {
+				return
+			}
+			fmt.Fprint(w, manifestContent)
+		}
+		mux.HandleFunc("/v2/"+image+"/manifests/latest", serveManifest)
+		mux.HandleFunc("/v2/"+image+"/manifests/"+manifestDigest, serveManifest)
+		serve("/v2/"+image+"/blobs/"+configDigest, "application/octet-stream", configContent)
+		serve("/v2/"+image+"/blobs/"+blobDigest, "application/octet-stream", string(blobContent))
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%sv2/token", setting.AppURL))
+		req = AddBasicAuthHeader(req, user.Name)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		tokenResponse := &struct {
+			Token string `json:"token"`
+		}{}
+		DecodeJSON(t, resp, &tokenResponse)
+		token := "Bearer " + tokenResponse.Token
+
+		root := fmt.Sprintf("/v2/%s/%s", user.Name, image)
+
+		addRemote(t, packages_model.TypeContainer, upstream.URL)
+
+		req = NewRequest(t, "GET", root+"/manifests/latest")
+		addTokenAuthHeader(req, token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, manifestDigest, resp.Header().Get("Docker-Content-Digest"))
+		assert.Equal(t, manifestContent, resp.Body.String())
+
+		req = NewRequest(t, "GET", root+"/blobs/"+blobDigest)
+		addTokenAuthHeader(req, token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, blobContent, resp.Body.Bytes())
+
+		assertCachedVersion(t, packages_model.TypeContainer, image, "latest")
+
+		// the tag digest is cached, so the manifest is served without contacting the upstream registry
+		before := upstreamRequests.Load()
+		req = NewRequest(t, "GET", root+"/manifests/latest")
+		addTokenAuthHeader(req, token)
+		MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, before, upstreamRequests.Load())
+	})
+
+	t.Run("UpstreamUnavailable", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		upstream.Close()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/npm/%s/-/%s/%s", user.Name, "%40scope%2Ftest-package", "1.0.1", "test-package-1.0.1.tgz"))
+		resp := MakeRequest(t, req, http.StatusOK)
+		assert.Equal(t, content, resp.Body.String())
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/npm/%s/-/%s/%s", user.Name, "%40scope%2Ftest-package", "2.0.0", "test-package-2.0.0.tgz"))
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/pypi/files/%s/%s/%s", user.Name, "test-package", "2.0.0", "test_package-2.0.0-py3-none-any.whl"))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
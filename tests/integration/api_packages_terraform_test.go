@@ -0,0 +1,372 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/tests"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageTerraform(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+	rootURL := fmt.Sprintf("/api/packages/%s/terraform", user.Name)
+
+	t.Run("ServiceDiscovery", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", "/.well-known/terraform.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result map[string]string
+		DecodeJSON(t, resp, &result)
+
+		assert.Equal(t, "/api/packages/-/terraform/modules/v1/", result["modules.v1"])
+		assert.Equal(t, "/api/packages/-/terraform/providers/v1/", result["providers.v1"])
+	})
+
+	t.Run("Module", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		moduleName := "vpc"
+		moduleSystem := "aws"
+		moduleVersion := "1.0.0"
+		readme := "# Gitea Test Module"
+
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		tw := tar.NewWriter(zw)
+		for name, content := range map[string]string{
+			"main.tf":   `resource "null_resource" "gitea" {}`,
+			"README.md": readme,
+		} {
+			tw.WriteHeader(&tar.Header{
+				Name: name,
+				Mode: 0o600,
+				Size: int64(len(content)),
+			})
+			tw.Write([]byte(content))
+		}
+		tw.Close()
+		zw.Close()
+		content := buf.Bytes()
+
+		url := fmt.Sprintf("%s/modules/%s/%s/%s", rootURL, moduleName, moduleSystem, moduleVersion)
+		protocolURL := fmt.Sprintf("/api/packages/-/terraform/modules/v1/%s/%s/%s", user.Name, moduleName, moduleSystem)
+
+		t.Run("Upload", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequestWithBody(t, "PUT", url, strings.NewReader("invalid"))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/modules/-vpc/%s/%s", rootURL, moduleSystem, moduleVersion), bytes.NewReader(content))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusCreated)
+
+			pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeTerraform)
+			assert.NoError(t, err)
+			assert.Len(t, pvs, 1)
+
+			pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+			assert.NoError(t, err)
+			assert.NotNil(t, pd.SemVer)
+			assert.IsType(t, &terraform_module.Metadata{}, pd.Metadata)
+			metadata := pd.Metadata.(*terraform_module.Metadata)
+			assert.Equal(t, terraform_module.KindModule, metadata.Kind)
+			assert.Equal(t, readme, metadata.Readme)
+			assert.Equal(t, moduleName+"/"+moduleSystem, pd.Package.Name)
+			assert.Equal(t, moduleVersion, pd.Version.Version)
+
+			pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+			assert.NoError(t, err)
+			assert.Len(t, pfs, 1)
+			assert.Equal(t, "vpc-aws-1.0.0.tar.gz", pfs[0].Name)
+			assert.True(t, pfs[0].IsLead)
+
+			req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusConflict)
+		})
+
+		t.Run("EnumerateVersions", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", protocolURL+"/versions")
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			type Version struct {
+				Version string `json:"version"`
+			}
+			var result struct {
+				Modules []struct {
+					Versions []*Version `json:"versions"`
+				} `json:"modules"`
+			}
+			DecodeJSON(t, resp, &result)
+
+			assert.Len(t, result.Modules, 1)
+			assert.Equal(t, []*Version{{Version: moduleVersion}}, result.Modules[0].Versions)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("/api/packages/-/terraform/modules/v1/%s/%s/%s/versions", user.Name, moduleName, "azure"))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/download", protocolURL, moduleVersion))
+			resp := MakeRequest(t, req, http.StatusNoContent)
+
+			downloadURL := resp.Header().Get("X-Terraform-Get")
+			assert.Equal(t, fmt.Sprintf("%sapi/packages/%s/terraform/modules/%s/%s/%s/vpc-aws-1.0.0.tar.gz", setting.AppURL, user.Name, moduleName, moduleSystem, moduleVersion), downloadURL)
+
+			req = NewRequest(t, "GET", strings.TrimPrefix(downloadURL, setting.AppURL[:len(setting.AppURL)-1]))
+			resp = MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, content, resp.Body.Bytes())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/download", protocolURL, "2.0.0"))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("Delete", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "DELETE", url)
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequest(t, "DELETE", url)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequest(t, "DELETE", url)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNotFound)
+
+			req = NewRequest(t, "GET", protocolURL+"/versions")
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+	})
+
+	t.Run("Provider", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		providerType := "dns"
+		providerVersion := "1.2.0"
+
+		createArchive := func(platform string) []byte {
+			var buf bytes.Buffer
+			zw := zip.NewWriter(&buf)
+			w, _ := zw.Create(fmt.Sprintf("terraform-provider-%s_v%s", providerType, providerVersion))
+			w.Write([]byte(platform))
+			zw.Close()
+			return buf.Bytes()
+		}
+
+		platforms := []struct {
+			OS      string
+			Arch    string
+			Content []byte
+		}{
+			{"linux", "amd64", createArchive("linux_amd64")},
+			{"darwin", "arm64", createArchive("darwin_arm64")},
+		}
+
+		versionURL := fmt.Sprintf("%s/providers/%s/%s", rootURL, providerType, providerVersion)
+		protocolURL := fmt.Sprintf("/api/packages/-/terraform/providers/v1/%s/%s", user.Name, providerType)
+
+		t.Run("Upload", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			url := fmt.Sprintf("%s/%s/%s?protocols=5.0,6.0", versionURL, platforms[0].OS, platforms[0].Arch)
+
+			req := NewRequestWithBody(t, "PUT", url, bytes.NewReader(platforms[0].Content))
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequestWithBody(t, "PUT", url, strings.NewReader("invalid"))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/%s/%s?protocols=6", versionURL, platforms[0].OS, platforms[0].Arch), bytes.NewReader(platforms[0].Content))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusBadRequest)
+
+			for _, p := range platforms {
+				req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/%s/%s?protocols=5.0,6.0", versionURL, p.OS, p.Arch), bytes.NewReader(p.Content))
+				AddBasicAuthHeader(req, user.Name)
+				MakeRequest(t, req, http.StatusCreated)
+			}
+
+			pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeTerraform)
+			assert.NoError(t, err)
+			assert.Len(t, pvs, 1)
+
+			pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+			assert.NoError(t, err)
+			assert.IsType(t, &terraform_module.Metadata{}, pd.Metadata)
+			metadata := pd.Metadata.(*terraform_module.Metadata)
+			assert.Equal(t, terraform_module.KindProvider, metadata.Kind)
+			assert.Equal(t, []string{"5.0", "6.0"}, metadata.Protocols)
+			assert.Equal(t, providerType, pd.Package.Name)
+			assert.Equal(t, providerVersion, pd.Version.Version)
+			assert.Len(t, pd.Files, 2)
+
+			req = NewRequestWithBody(t, "PUT", url, bytes.NewReader(platforms[0].Content))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusConflict)
+		})
+
+		t.Run("EnumerateVersions", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "GET", protocolURL+"/versions")
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			type Platform struct {
+				OS   string `json:"os"`
+				Arch string `json:"arch"`
+			}
+			var result struct {
+				Versions []struct {
+					Version   string      `json:"version"`
+					Protocols []string    `json:"protocols"`
+					Platforms []*Platform `json:"platforms"`
+				} `json:"versions"`
+			}
+			DecodeJSON(t, resp, &result)
+
+			assert.Len(t, result.Versions, 1)
+			assert.Equal(t, providerVersion, result.Versions[0].Version)
+			assert.Equal(t, []string{"5.0", "6.0"}, result.Versions[0].Protocols)
+			assert.ElementsMatch(t, []*Platform{{"linux", "amd64"}, {"darwin", "arm64"}}, result.Versions[0].Platforms)
+		})
+
+		t.Run("Download", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			type GPGPublicKey struct {
+				KeyID      string `json:"key_id"`
+				ASCIIArmor string `json:"ascii_armor"`
+			}
+			type Package struct {
+				Protocols           []string `json:"protocols"`
+				OS                  string   `json:"os"`
+				Arch                string   `json:"arch"`
+				Filename            string   `json:"filename"`
+				DownloadURL         string   `json:"download_url"`
+				ShasumsURL          string   `json:"shasums_url"`
+				ShasumsSignatureURL string   `json:"shasums_signature_url"`
+				Shasum              string   `json:"shasum"`
+				SigningKeys         struct {
+					GPGPublicKeys []*GPGPublicKey `json:"gpg_public_keys"`
+				} `json:"signing_keys"`
+			}
+
+			localPath := func(url string) string {
+				return strings.TrimPrefix(url, setting.AppURL[:len(setting.AppURL)-1])
+			}
+
+			var checksums []string
+			for _, p := range platforms {
+				req := NewRequest(t, "GET", fmt.Sprintf("%s/%s/download/%s/%s", protocolURL, providerVersion, p.OS, p.Arch))
+				resp := MakeRequest(t, req, http.StatusOK)
+
+				var result Package
+				DecodeJSON(t, resp, &result)
+
+				filename := fmt.Sprintf("terraform-provider-%s_%s_%s_%s.zip", providerType, providerVersion, p.OS, p.Arch)
+				sum := sha256.Sum256(p.Content)
+
+				assert.Equal(t, []string{"5.0", "6.0"}, result.Protocols)
+				assert.Equal(t, p.OS, result.OS)
+				assert.Equal(t, p.Arch, result.Arch)
+				assert.Equal(t, filename, result.Filename)
+				assert.Equal(t, hex.EncodeToString(sum[:]), result.Shasum)
+				assert.Equal(t, fmt.Sprintf("%s%s/terraform-provider-%s_%s_SHA256SUMS", setting.AppURL, strings.TrimPrefix(versionURL, "/"), providerType, providerVersion), result.ShasumsURL)
+				assert.Equal(t, result.ShasumsURL+".sig", result.ShasumsSignatureURL)
+				if assert.Len(t, result.SigningKeys.GPGPublicKeys, 1) {
+					key := result.SigningKeys.GPGPublicKeys[0]
+					assert.Len(t, key.KeyID, 16)
+					assert.Contains(t, key.ASCIIArmor, "-----BEGIN PGP PUBLIC KEY BLOCK-----")
+				}
+
+				checksums = append(checksums, fmt.Sprintf("%s  %s\n", result.Shasum, result.Filename))
+
+				req = NewRequest(t, "GET", localPath(result.DownloadURL))
+				resp = MakeRequest(t, req, http.StatusOK)
+				assert.Equal(t, p.Content, resp.Body.Bytes())
+
+				req = NewRequest(t, "GET", localPath(result.ShasumsURL))
+				resp = MakeRequest(t, req, http.StatusOK)
+				shasums := resp.Body.Bytes()
+
+				req = NewRequest(t, "GET", localPath(result.ShasumsSignatureURL))
+				resp = MakeRequest(t, req, http.StatusOK)
+
+				keyring, err := openpgp.ReadArmoredKeyRing(strings.NewReader(result.SigningKeys.GPGPublicKeys[0].ASCIIArmor))
+				assert.NoError(t, err)
+				_, err = openpgp.CheckDetachedSignature(keyring, bytes.NewReader(shasums), resp.Body)
+				assert.NoError(t, err)
+			}
+
+			// the checksums are sorted by the filenames of the archives
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/terraform-provider-%s_%s_SHA256SUMS", versionURL, providerType, providerVersion))
+			resp := MakeRequest(t, req, http.StatusOK)
+			assert.Equal(t, checksums[1]+checksums[0], resp.Body.String())
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/download/%s/%s", protocolURL, providerVersion, "windows", "amd64"))
+			MakeRequest(t, req, http.StatusNotFound)
+
+			req = NewRequest(t, "GET", fmt.Sprintf("%s/terraform-provider-%s_%s_SHA256SUMS", versionURL, providerType, "1.0.0"))
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+
+		t.Run("Delete", func(t *testing.T) {
+			defer tests.PrintCurrentTest(t)()
+
+			req := NewRequest(t, "DELETE", versionURL)
+			MakeRequest(t, req, http.StatusUnauthorized)
+
+			req = NewRequest(t, "DELETE", versionURL)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequest(t, "DELETE", versionURL)
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, http.StatusNotFound)
+
+			req = NewRequest(t, "GET", protocolURL+"/versions")
+			MakeRequest(t, req, http.StatusNotFound)
+		})
+	})
+}
@@ -187,7 +187,7 @@ func TestPackageQuota(t *testing.T) {
 
 	limitTotalOwnerCount, limitTotalOwnerSize := setting.Packages.LimitTotalOwnerCount, setting.Packages.LimitTotalOwnerSize
 
-	// Exceeded quota result in StatusForbidden for normal users but admins are always allowed to upload.
+	// Exceeded quota result in StatusForbidden or StatusRequestEntityTooLarge for normal users but admins are always allowed to upload.
 	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1})
 	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 10})
 
@@ -209,16 +209,75 @@ func TestPackageQuota(t *testing.T) {
 		setting.Packages.LimitTotalOwnerCount = limitTotalOwnerCount
 
 		setting.Packages.LimitTotalOwnerSize = 0
-		uploadPackage(user, "1.1", http.StatusForbidden)
+		uploadPackage(user, "1.1", http.StatusRequestEntityTooLarge)
 		uploadPackage(admin, "1.1", http.StatusCreated)
 		setting.Packages.LimitTotalOwnerSize = limitTotalOwnerSize
 
 		setting.Packages.LimitSizeGeneric = 0
-		uploadPackage(user, "1.2", http.StatusForbidden)
+		uploadPackage(user, "1.2", http.StatusRequestEntityTooLarge)
 		uploadPackage(admin, "1.2", http.StatusCreated)
 		setting.Packages.LimitSizeGeneric = limitSizeGeneric
 	})
 
+	t.Run("Owner", func(t *testing.T) {
+		defer tests.PrintCurrentTest(t)()
+
+		uploadPackage := func(version string, expectedStatus int) {
+			url := fmt.Sprintf("/api/packages/%s/generic/test-package/%s/file.bin", user.Name, version)
+			req := NewRequestWithBody(t, "PUT", url, bytes.NewReader([]byte{1}))
+			AddBasicAuthHeader(req, user.Name)
+			MakeRequest(t, req, expectedStatus)
+		}
+
+		session := loginUser(t, admin.Name)
+
+		setQuota := func(limitTotalSize, limitTotalCount string) {
+			req := NewRequestWithValues(t, "POST", "/admin/packages/quotas", map[string]string{
+				"_csrf":             GetCSRF(t, session, "/admin/packages/quotas"),
+				"owner":             user.Name,
+				"limit_total_size":  limitTotalSize,
+				"limit_total_count": limitTotalCount,
+			})
+			session.MakeRequest(t, req, http.StatusSeeOther)
+		}
+
+		setQuota("0", "")
+		uploadPackage("2.0", http.StatusRequestEntityTooLarge)
+
+		pq, err := packages_model.GetQuotaByOwner(db.DefaultContext, user.ID)
+		assert.NoError(t, err)
+		assert.EqualValues(t, 0, *pq.LimitTotalSize)
+		assert.Nil(t, pq.LimitTotalCount)
+
+		// the owner quota takes precedence over the global setting
+		setting.Packages.LimitTotalOwnerSize = 0
+		setQuota("-1", "")
+		uploadPackage("2.1", http.StatusCreated)
+		setting.Packages.LimitTotalOwnerSize = limitTotalOwnerSize
+
+		setQuota("", "0")
+		uploadPackage("2.2", http.StatusForbidden)
+
+		resp := session.MakeRequest(t, NewRequest(t, "GET", "/admin/packages/quotas"), http.StatusOK)
+		assert.Contains(t, resp.Body.String(), user.Name)
+
+		userSession := loginUser(t, user.Name)
+		resp = userSession.MakeRequest(t, NewRequest(t, "GET", "/user/settings/packages"), http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		assert.Contains(t, htmlDoc.doc.Find(".user-setting-content table").Text(), "Generic")
+
+		req := NewRequestWithValues(t, "POST", "/admin/packages/quotas/delete", map[string]string{
+			"_csrf": GetCSRF(t, session, "/admin/packages/quotas"),
+			"id":    fmt.Sprint(pq.ID),
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+
+		_, err = packages_model.GetQuotaByOwner(db.DefaultContext, user.ID)
+		assert.ErrorIs(t, err, packages_model.ErrPackageQuotaNotExist)
+
+		uploadPackage("2.2", http.StatusCreated)
+	})
+
 	t.Run("Container", func(t *testing.T) {
 		defer tests.PrintCurrentTest(t)()
 
@@ -232,12 +291,12 @@ func TestPackageQuota(t *testing.T) {
 		}
 
 		setting.Packages.LimitTotalOwnerSize = 0
-		uploadBlob(user, "2", http.StatusForbidden)
+		uploadBlob(user, "2", http.StatusRequestEntityTooLarge)
 		uploadBlob(admin, "2", http.StatusCreated)
 		setting.Packages.LimitTotalOwnerSize = limitTotalOwnerSize
 
 		setting.Packages.LimitSizeContainer = 0
-		uploadBlob(user, "3", http.StatusForbidden)
+		uploadBlob(user, "3", http.StatusRequestEntityTooLarge)
 		uploadBlob(admin, "3", http.StatusCreated)
 		setting.Packages.LimitSizeContainer = limitSizeContainer
 	})
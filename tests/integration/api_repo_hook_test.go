@@ -12,6 +12,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
+	webhook_model "code.gitea.io/gitea/models/webhook"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/tests"
 
@@ -45,3 +46,53 @@ func TestAPICreateHook(t *testing.T) {
 	assert.Equal(t, "http://example.com/", apiHook.Config["url"])
 	assert.Equal(t, "Bearer s3cr3t", apiHook.AuthorizationHeader)
 }
+
+func TestAPIEditHookRotateSecret(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 37})
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID})
+
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session, auth_model.AccessTokenScopeWriteRepository)
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/hooks?token=%s", owner.Name, repo.Name, token), api.CreateHookOption{
+		Type: "gitea",
+		Config: api.CreateHookOptionConfig{
+			"content_type": "json",
+			"url":          "http://example.com/",
+			"secret":       "old",
+		},
+	})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var apiHook *api.Hook
+	DecodeJSON(t, resp, &apiHook)
+	hookURL := fmt.Sprintf("/api/v1/repos/%s/%s/hooks/%d?token=%s", owner.Name, repo.Name, apiHook.ID, token)
+
+	// the replaced secret keeps signing the deliveries during the rotation window
+	req = NewRequestWithJSON(t, "PATCH", hookURL, api.EditHookOption{
+		Config:              map[string]string{"secret": "new"},
+		SecretRotationHours: 24,
+	})
+	MakeRequest(t, req, http.StatusOK)
+	hook := unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{ID: apiHook.ID})
+	assert.Equal(t, []string{"new", "old"}, hook.SigningSecrets())
+
+	// an edit which doesn't change the secret keeps the previous ones
+	req = NewRequestWithJSON(t, "PATCH", hookURL, api.EditHookOption{BranchFilter: "main"})
+	MakeRequest(t, req, http.StatusOK)
+	hook = unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{ID: apiHook.ID})
+	assert.Equal(t, []string{"new", "old"}, hook.SigningSecrets())
+
+	req = NewRequestWithJSON(t, "PATCH", hookURL, api.EditHookOption{RevokePreviousSecrets: true})
+	MakeRequest(t, req, http.StatusOK)
+	hook = unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{ID: apiHook.ID})
+	assert.Equal(t, []string{"new"}, hook.SigningSecrets())
+
+	req = NewRequestWithJSON(t, "PATCH", hookURL, api.EditHookOption{
+		Config:              map[string]string{"secret": "newer"},
+		SecretRotationHours: webhook_model.MaxSecretRotationHours + 1,
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	hook = unittest.AssertExistsAndLoadBean(t, &webhook_model.Webhook{ID: apiHook.ID})
+	assert.Equal(t, "new", hook.Secret)
+}
@@ -99,7 +99,7 @@ func TestViewIssuesKeyword(t *testing.T) {
 		RepoID: repo.ID,
 		Index:  1,
 	})
-	issues.UpdateIssueIndexer(issue)
+	issues.UpdateIssueIndexer(db.DefaultContext, issue)
 	time.Sleep(time.Second * 1)
 	const keyword = "first"
 	req := NewRequestf(t, "GET", "%s/issues?q=%s", repo.Link(), keyword)
@@ -116,6 +116,37 @@ func TestViewIssuesKeyword(t *testing.T) {
 	})
 }
 
+func TestViewDashboardIssuesQualifiers(t *testing.T) {
+	defer tests.PrepareTestEnv(t)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1})
+	for _, index := range []int64{1, 2, 3, 4, 5} {
+		issue := unittest.AssertExistsAndLoadBean(t, &issues_model.Issue{RepoID: repo.ID, Index: index})
+		assert.NoError(t, issue.LoadDiscussComments(db.DefaultContext))
+		issues.UpdateIssueIndexer(db.DefaultContext, issue)
+	}
+	assert.Eventually(t, func() bool {
+		ids, err := issues.SearchIssuesByKeyword(db.DefaultContext, []int64{repo.ID}, "for")
+		return err == nil && len(ids) == 5
+	}, 10*time.Second, 100*time.Millisecond)
+
+	session := loginUser(t, "user2")
+	// the is:pr qualifier takes precedence over the type of the dashboard, with or without keyword
+	for _, query := range []string{"is:pr repo:user2/repo1", "for is:pr repo:user2/repo1"} {
+		req := NewRequestf(t, "GET", "/issues?q=%s", url.QueryEscape(query))
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		issuesSelection := getIssuesSelection(t, htmlDoc)
+		assert.EqualValues(t, 3, issuesSelection.Length(), "query: %s", query)
+		issuesSelection.Each(func(_ int, selection *goquery.Selection) {
+			issue := getIssue(t, repo.ID, selection)
+			assert.True(t, issue.IsPull)
+			assert.False(t, issue.IsClosed)
+		})
+	}
+}
+
 func TestNoLoginViewIssue(t *testing.T) {
 	defer tests.PrepareTestEnv(t)()
 
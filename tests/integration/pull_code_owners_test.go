@@ -0,0 +1,184 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/services/pull"
+	repo_service "code.gitea.io/gitea/services/repository"
+	files_service "code.gitea.io/gitea/services/repository/files"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullCodeOwnersApproval(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+		user4 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4})
+		user5 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5})
+
+		repo, err := repo_service.CreateRepository(db.DefaultContext, user2, user2, repo_module.CreateRepoOptions{
+			Name:          "code-owners-approval",
+			AutoInit:      true,
+			Readme:        "Default",
+			DefaultBranch: "main",
+		})
+		assert.NoError(t, err)
+
+		// docs are owned by user5, sources by team1 of org user3 (user2 and user4)
+		_, err = files_service.ChangeRepoFiles(git.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+			Files: []*files_service.ChangeRepoFile{
+				{
+					Operation: "create",
+					TreePath:  "CODEOWNERS",
+					Content:   "docs/.* @user5\nsrc/.* @user3/team1\n",
+				},
+			},
+			Message:   "Add CODEOWNERS",
+			OldBranch: "main",
+			NewBranch: "main",
+		})
+		assert.NoError(t, err)
+
+		_, err = files_service.ChangeRepoFiles(git.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+			Files: []*files_service.ChangeRepoFile{
+				{Operation: "create", TreePath: "docs/index.md", Content: "# Docs"},
+				{Operation: "create", TreePath: "src/main.go", Content: "package main"},
+				{Operation: "create", TreePath: "unowned.txt", Content: "unowned"},
+			},
+			Message:   "Add docs and sources",
+			OldBranch: "main",
+			NewBranch: "feature",
+		})
+		assert.NoError(t, err)
+
+		issue := &issues_model.Issue{
+			RepoID:   repo.ID,
+			Title:    "Add docs and sources",
+			PosterID: user2.ID,
+			Poster:   user2,
+			IsPull:   true,
+		}
+		pr := &issues_model.PullRequest{
+			HeadRepoID: repo.ID,
+			BaseRepoID: repo.ID,
+			HeadBranch: "feature",
+			BaseBranch: "main",
+			HeadRepo:   repo,
+			BaseRepo:   repo,
+			Type:       issues_model.PullRequestGitea,
+		}
+		assert.NoError(t, pull.NewPullRequest(git.DefaultContext, repo, issue, nil, nil, pr, nil))
+
+		pb := &git_model.ProtectedBranch{
+			RepoID:                   repo.ID,
+			RuleName:                 "main",
+			RequireCodeOwnerApproval: true,
+		}
+
+		review := func(t *testing.T, reviewer *user_model.User, reviewType issues_model.ReviewType) {
+			_, err := issues_model.CreateReview(db.DefaultContext, issues_model.CreateReviewOptions{
+				Type:     reviewType,
+				Issue:    issue,
+				Reviewer: reviewer,
+				Official: true,
+			})
+			assert.NoError(t, err)
+		}
+
+		approvedPaths := func(t *testing.T) map[string][]string {
+			approvals, err := issues_model.GetCodeOwnersApprovals(db.DefaultContext, pb, pr)
+			assert.NoError(t, err)
+
+			paths := make(map[string][]string, len(approvals))
+			for _, approval := range approvals {
+				paths[approval.Path] = approval.ApproverNames()
+			}
+			return paths
+		}
+
+		t.Run("NotApproved", func(t *testing.T) {
+			approvals, err := issues_model.GetCodeOwnersApprovals(db.DefaultContext, pb, pr)
+			assert.NoError(t, err)
+			// files without code owners are not listed
+			assert.Len(t, approvals, 2)
+			assert.Equal(t, "docs/index.md", approvals[0].Path)
+			assert.Equal(t, []string{"user5"}, approvals[0].OwnerNames())
+			assert.False(t, approvals[0].IsApproved())
+			assert.Equal(t, "src/main.go", approvals[1].Path)
+			assert.Equal(t, []string{"team1"}, approvals[1].OwnerNames())
+			assert.False(t, approvals[1].IsApproved())
+
+			assert.True(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, pb, pr))
+
+			// the check is only done if the branch requires it
+			assert.False(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, &git_model.ProtectedBranch{RepoID: repo.ID, RuleName: "main"}, pr))
+		})
+
+		t.Run("ApprovedByTeamMember", func(t *testing.T) {
+			review(t, user4, issues_model.ReviewTypeApprove)
+
+			assert.Equal(t, map[string][]string{
+				"docs/index.md": {},
+				"src/main.go":   {"user4"},
+			}, approvedPaths(t))
+			assert.True(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, pb, pr))
+		})
+
+		t.Run("ApprovedByAllOwners", func(t *testing.T) {
+			review(t, user5, issues_model.ReviewTypeApprove)
+
+			assert.Equal(t, map[string][]string{
+				"docs/index.md": {"user5"},
+				"src/main.go":   {"user4"},
+			}, approvedPaths(t))
+			assert.False(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, pb, pr))
+		})
+
+		t.Run("ChangesRequested", func(t *testing.T) {
+			// only the latest review of a code owner counts
+			review(t, user5, issues_model.ReviewTypeReject)
+
+			assert.Equal(t, map[string][]string{
+				"docs/index.md": {},
+				"src/main.go":   {"user4"},
+			}, approvedPaths(t))
+			assert.True(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, pb, pr))
+		})
+
+		t.Run("NewCodeOwners", func(t *testing.T) {
+			review(t, user5, issues_model.ReviewTypeApprove)
+			assert.False(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, pb, pr))
+
+			// a changed CODEOWNERS file of the default branch applies immediately
+			_, err := files_service.ChangeRepoFiles(git.DefaultContext, repo, user2, &files_service.ChangeRepoFilesOptions{
+				Files: []*files_service.ChangeRepoFile{
+					{
+						Operation: "update",
+						TreePath:  "CODEOWNERS",
+						Content:   "docs/.* @user5\nsrc/.* @user3/team1\n.*\\.txt @user2\n",
+					},
+				},
+				Message:   "Add owner of text files",
+				OldBranch: "main",
+				NewBranch: "main",
+			})
+			assert.NoError(t, err)
+
+			paths := approvedPaths(t)
+			assert.Len(t, paths, 3)
+			assert.Empty(t, paths["unowned.txt"])
+			assert.True(t, issues_model.MergeBlockedByCodeOwners(db.DefaultContext, pb, pr))
+		})
+	})
+}
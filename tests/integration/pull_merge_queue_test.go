@@ -0,0 +1,177 @@
+// Copyright 2023 The Gitea Authors. All rights reserved.
+// SPDX-License-Identifier: MIT
+
+package integration
+
+import (
+	"net/url"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	git_model "code.gitea.io/gitea/models/git"
+	issues_model "code.gitea.io/gitea/models/issues"
+	pull_model "code.gitea.io/gitea/models/pull"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/automerge"
+	"code.gitea.io/gitea/services/pull"
+	repo_service "code.gitea.io/gitea/services/repository"
+	files_service "code.gitea.io/gitea/services/repository/files"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPullMergeQueue(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, giteaURL *url.URL) {
+		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2})
+
+		repo, err := repo_service.CreateRepository(db.DefaultContext, user, user, repo_module.CreateRepoOptions{
+			Name:          "merge-queue",
+			AutoInit:      true,
+			Readme:        "Default",
+			DefaultBranch: "main",
+		})
+		assert.NoError(t, err)
+
+		assert.NoError(t, git_model.UpdateProtectBranch(db.DefaultContext, repo, &git_model.ProtectedBranch{
+			RepoID:               repo.ID,
+			RuleName:             "main",
+			EnableMergeQueue:     true,
+			EnableStatusCheck:    true,
+			StatusCheckContexts:  []string{"ci"},
+			RequireLinearHistory: true,
+		}, git_model.WhitelistOptions{}))
+
+		createPullRequest := func(t *testing.T, branch string) *issues_model.PullRequest {
+			_, err := files_service.ChangeRepoFiles(git.DefaultContext, repo, user, &files_service.ChangeRepoFilesOptions{
+				Files: []*files_service.ChangeRepoFile{
+					{
+						Operation: "create",
+						TreePath:  branch + ".txt",
+						Content:   branch,
+					},
+				},
+				Message:   "Add " + branch,
+				OldBranch: "main",
+				NewBranch: branch,
+			})
+			assert.NoError(t, err)
+
+			issue := &issues_model.Issue{
+				RepoID:   repo.ID,
+				Title:    "Merge " + branch,
+				PosterID: user.ID,
+				Poster:   user,
+				IsPull:   true,
+			}
+			pr := &issues_model.PullRequest{
+				HeadRepoID: repo.ID,
+				BaseRepoID: repo.ID,
+				HeadBranch: branch,
+				BaseBranch: "main",
+				HeadRepo:   repo,
+				BaseRepo:   repo,
+				Type:       issues_model.PullRequestGitea,
+			}
+			assert.NoError(t, pull.NewPullRequest(git.DefaultContext, repo, issue, nil, nil, pr, nil))
+			return pr
+		}
+
+		waitForQueueCommit := func(t *testing.T, pr *issues_model.PullRequest) *pull_model.MergeQueueEntry {
+			var entry *pull_model.MergeQueueEntry
+			assert.Eventually(t, func() bool {
+				exist, e, err := pull_model.GetMergeQueueEntryByPullID(db.DefaultContext, pr.ID)
+				assert.NoError(t, err)
+				entry = e
+				return exist && e.QueueCommitID != ""
+			}, 10*time.Second, 100*time.Millisecond)
+			return entry
+		}
+
+		branchCommitID := func(t *testing.T, branch string) string {
+			commitID, err := git.GetFullCommitID(git.DefaultContext, repo.RepoPath(), git.BranchPrefix+branch)
+			assert.NoError(t, err)
+			return commitID
+		}
+
+		pr1 := createPullRequest(t, "feature-1")
+		pr2 := createPullRequest(t, "feature-2")
+		mainCommitID := branchCommitID(t, "main")
+
+		var entry1, entry2 *pull_model.MergeQueueEntry
+
+		t.Run("MergeStyleNotAllowed", func(t *testing.T) {
+			err := automerge.AddToMergeQueue(db.DefaultContext, user, pr1, repo_model.MergeStyleMerge, "")
+			assert.True(t, models.IsErrInvalidMergeStyle(err))
+			unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: pr1.ID})
+		})
+
+		t.Run("Enqueue", func(t *testing.T) {
+			assert.NoError(t, automerge.AddToMergeQueue(db.DefaultContext, user, pr1, repo_model.MergeStyleRebase, ""))
+			assert.NoError(t, automerge.AddToMergeQueue(db.DefaultContext, user, pr2, repo_model.MergeStyleRebase, ""))
+
+			err := automerge.AddToMergeQueue(db.DefaultContext, user, pr1, repo_model.MergeStyleRebase, "")
+			assert.True(t, pull_model.IsErrAlreadyInMergeQueue(err))
+
+			entry1 = waitForQueueCommit(t, pr1)
+			entry2 = waitForQueueCommit(t, pr2)
+
+			// every entry is built on top of the previous one
+			assert.Equal(t, mainCommitID, entry1.BaseCommitID)
+			assert.Equal(t, entry1.QueueCommitID, entry2.BaseCommitID)
+			assert.Equal(t, entry1.QueueCommitID, branchCommitID(t, pull.MergeQueueBranchName(pr1)))
+			assert.Equal(t, entry2.QueueCommitID, branchCommitID(t, pull.MergeQueueBranchName(pr2)))
+
+			// nothing gets merged before the required status checks succeeded
+			assert.Equal(t, mainCommitID, branchCommitID(t, "main"))
+		})
+
+		t.Run("FastForward", func(t *testing.T) {
+			assert.NoError(t, files_service.CreateCommitStatus(db.DefaultContext, repo, user, entry1.QueueCommitID, &git_model.CommitStatus{
+				State:   api.CommitStatusSuccess,
+				Context: "ci",
+			}))
+
+			assert.Eventually(t, func() bool {
+				return unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: pr1.ID}).HasMerged
+			}, 10*time.Second, 100*time.Millisecond)
+
+			assert.Equal(t, entry1.QueueCommitID, branchCommitID(t, "main"))
+			unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{PullID: pr1.ID})
+			assert.False(t, git.IsBranchExist(git.DefaultContext, repo.RepoPath(), pull.MergeQueueBranchName(pr1)))
+
+			// the queue commit of the second entry is still valid
+			unittest.AssertExistsAndLoadBean(t, &pull_model.MergeQueueEntry{PullID: pr2.ID, QueueCommitID: entry2.QueueCommitID})
+		})
+
+		t.Run("StatusCheckFailure", func(t *testing.T) {
+			assert.NoError(t, files_service.CreateCommitStatus(db.DefaultContext, repo, user, entry2.QueueCommitID, &git_model.CommitStatus{
+				State:   api.CommitStatusFailure,
+				Context: "ci",
+			}))
+
+			assert.Eventually(t, func() bool {
+				return unittest.GetCount(t, &pull_model.MergeQueueEntry{PullID: pr2.ID}) == 0
+			}, 10*time.Second, 100*time.Millisecond)
+
+			assert.False(t, unittest.AssertExistsAndLoadBean(t, &issues_model.PullRequest{ID: pr2.ID}).HasMerged)
+			assert.Equal(t, entry1.QueueCommitID, branchCommitID(t, "main"))
+			unittest.AssertExistsAndLoadBean(t, &issues_model.Comment{IssueID: pr2.IssueID, Type: issues_model.CommentTypePRRemovedFromMergeQueue})
+			assert.False(t, git.IsBranchExist(git.DefaultContext, repo.RepoPath(), pull.MergeQueueBranchName(pr2)))
+		})
+
+		t.Run("DeleteRepository", func(t *testing.T) {
+			assert.NoError(t, automerge.AddToMergeQueue(db.DefaultContext, user, pr2, repo_model.MergeStyleRebase, ""))
+			unittest.AssertExistsAndLoadBean(t, &pull_model.MergeQueueEntry{RepoID: repo.ID})
+
+			assert.NoError(t, repo_service.DeleteRepository(db.DefaultContext, user, repo, false))
+			unittest.AssertNotExistsBean(t, &pull_model.MergeQueueEntry{RepoID: repo.ID})
+		})
+	})
+}
@@ -28,6 +28,31 @@ export function initCompWebHookEditor() {
     updateContentType();
   });
 
+  // Custom webhook preview
+  const $customPreview = $('#custom-preview');
+  if ($customPreview.length) {
+    let previewTimer;
+    const updateCustomPreview = function () {
+      $.post($customPreview.data('link'), {
+        _csrf: csrfToken,
+        content_type: $('#custom_content_type').val(),
+        headers_template: $('#headers_template').val(),
+        body_template: $('#body_template').val(),
+      }).done((data) => {
+        const $error = $customPreview.find('.error.message');
+        $error.text(data.error || '');
+        toggleElem($error, Boolean(data.error));
+        $customPreview.find('.custom-preview-headers').text(data.headers || '');
+        $customPreview.find('.custom-preview-body').text(data.body || '');
+      });
+    };
+    updateCustomPreview();
+    $('#custom_content_type, #headers_template, #body_template').on('input', () => {
+      clearTimeout(previewTimer);
+      previewTimer = setTimeout(updateCustomPreview, 500);
+    });
+  }
+
   // Test delivery
   $('#test-delivery').on('click', function () {
     const $this = $(this);
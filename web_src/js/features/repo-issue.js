@@ -481,6 +481,9 @@ export function initRepoPullRequestReview() {
     });
   }
 
+  // the last line clicked to add a code comment, a range of lines could be commented by shift-clicking a following line
+  let lastCodeCommentLine = null;
+
   $(document).on('click', '.add-code-comment', async function (e) {
     if ($(e.target).hasClass('btn-add-single')) return; // https://github.com/go-gitea/gitea/issues/4745
     e.preventDefault();
@@ -492,6 +495,13 @@ export function initRepoPullRequestReview() {
     const tr = $(this).closest('tr');
     const lineType = tr.data('line-type');
 
+    let startIdx = 0;
+    if (e.shiftKey && lastCodeCommentLine && lastCodeCommentLine.path === path &&
+      lastCodeCommentLine.side === side && lastCodeCommentLine.idx < idx) {
+      startIdx = lastCodeCommentLine.idx;
+    }
+    lastCodeCommentLine = {path, side, idx};
+
     let ntr = tr.next();
     if (!ntr.hasClass('add-comment')) {
       ntr = $(`
@@ -514,6 +524,12 @@ export function initRepoPullRequestReview() {
       td.find("input[name='line']").val(idx);
       td.find("input[name='side']").val(side === 'left' ? 'previous' : 'proposed');
       td.find("input[name='path']").val(path);
+      if (startIdx) {
+        td.find("input[name='start_line']").val(startIdx);
+        const range = td.find('.code-comment-range');
+        range.text(range.attr('data-text-template').replace('%[1]d', startIdx).replace('%[2]d', idx));
+        range.removeClass('gt-hidden');
+      }
 
       const editor = await initComboMarkdownEditor(td.find('.combo-markdown-editor'));
       editor.focus();